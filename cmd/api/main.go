@@ -5,27 +5,37 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
 
+	"github.com/aiox-platform/aiox/internal/account"
 	"github.com/aiox-platform/aiox/internal/agents"
 	"github.com/aiox-platform/aiox/internal/api"
 	"github.com/aiox-platform/aiox/internal/auth"
 	"github.com/aiox-platform/aiox/internal/config"
 	"github.com/aiox-platform/aiox/internal/database"
+	"github.com/aiox-platform/aiox/internal/export"
 	"github.com/aiox-platform/aiox/internal/governance"
 	"github.com/aiox-platform/aiox/internal/governance/audit"
+	"github.com/aiox-platform/aiox/internal/governance/moderation"
 	"github.com/aiox-platform/aiox/internal/governance/quota"
+	"github.com/aiox-platform/aiox/internal/governance/redaction"
 	"github.com/aiox-platform/aiox/internal/memory"
 	"github.com/aiox-platform/aiox/internal/middleware"
 	inats "github.com/aiox-platform/aiox/internal/nats"
 	"github.com/aiox-platform/aiox/internal/orchestrator"
 	iredis "github.com/aiox-platform/aiox/internal/redis"
+	"github.com/aiox-platform/aiox/internal/responsecache"
+	"github.com/aiox-platform/aiox/internal/rooms"
 	"github.com/aiox-platform/aiox/internal/server"
+	"github.com/aiox-platform/aiox/internal/tracing"
 	"github.com/aiox-platform/aiox/internal/users"
+	"github.com/aiox-platform/aiox/internal/webhooks"
 	"github.com/aiox-platform/aiox/internal/worker"
 	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
 	ixmpp "github.com/aiox-platform/aiox/internal/xmpp"
@@ -38,7 +48,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLogger(cfg.Log)
+	logLevel := new(slog.LevelVar)
+	setupLogger(cfg.Log, logLevel)
 
 	if err := cfg.Validate(); err != nil {
 		slog.Error("config validation failed", "error", err)
@@ -48,6 +59,19 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
+	if err != nil {
+		slog.Error("initializing tracing", "error", err)
+		os.Exit(1)
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Warn("flushing traces", "error", err)
+		}
+	}()
+
 	// Auto-migrate if enabled
 	if cfg.DB.AutoMigrate {
 		slog.Info("running database migrations", "path", cfg.DB.MigrationsPath)
@@ -63,6 +87,12 @@ func main() {
 		slog.Error("connecting to postgres", "error", err)
 		os.Exit(1)
 	}
+	// Retries idempotent reads on transient errors; writes are never retried.
+	var dbBreaker *database.Breaker
+	if cfg.DB.BreakerThreshold > 0 {
+		dbBreaker = database.NewBreaker(cfg.DB.BreakerThreshold, cfg.DB.BreakerCooldown)
+	}
+	dbRetry := database.NewRetrier(cfg.DB.RetryAttempts, cfg.DB.RetryBaseDelay, cfg.DB.RetryMaxDelay, dbBreaker)
 
 	// Redis
 	redisClient, err := iredis.NewClient(ctx, cfg.Redis)
@@ -72,11 +102,15 @@ func main() {
 	}
 
 	// NATS
-	natsClient, err := inats.NewClient(ctx, cfg.NATS)
+	natsClient, err := inats.NewClient(cfg.NATS)
 	if err != nil {
 		slog.Error("connecting to nats", "error", err)
 		os.Exit(1)
 	}
+	if err := inats.EnsureStreams(ctx, natsClient.JetStream(), cfg.NATS.Streams); err != nil {
+		slog.Error("ensuring NATS JetStream streams", "error", err)
+		os.Exit(1)
+	}
 
 	// Auth
 	jwtManager := auth.NewJWTManager(
@@ -86,19 +120,59 @@ func main() {
 		cfg.JWT.RefreshExpiry,
 	)
 	authSvc := auth.NewService(jwtManager, redisClient)
+	authSvc.SetPasswordPolicy(auth.PasswordPolicy{
+		MinLength:     cfg.Password.MinLength,
+		RequireUpper:  cfg.Password.RequireUpper,
+		RequireLower:  cfg.Password.RequireLower,
+		RequireDigit:  cfg.Password.RequireDigit,
+		RequireSymbol: cfg.Password.RequireSymbol,
+		RejectCommon:  cfg.Password.RejectCommon,
+	})
+	authSvc.SetLockoutPolicy(auth.LockoutPolicy{
+		MaxAttempts: cfg.Lockout.MaxAttempts,
+		Window:      cfg.Lockout.Window,
+		Duration:    cfg.Lockout.Duration,
+	})
 	userRepo := users.NewRepository(pool)
 	userSvc := users.NewService(userRepo)
+	userSvc.SetAdminEmails(cfg.Admin.Emails)
+	if promoted, err := userSvc.PromoteAdmins(ctx); err != nil {
+		slog.Error("promoting admins", "error", err)
+	} else if promoted > 0 {
+		slog.Info("promoted admins from ADMIN_EMAILS", "count", promoted)
+	}
 	authHandler := auth.NewHandler(authSvc, userSvc)
+	apiKeySvc := auth.NewAPIKeyService(auth.NewAPIKeyRepository(pool))
+	apiKeyHandler := auth.NewAPIKeyHandler(apiKeySvc)
+	encryptor, err := auth.NewEncryptor(cfg.Encryption.Key, cfg.Encryption.PreviousKeys...)
+	if err != nil {
+		slog.Error("creating encryptor", "error", err)
+		os.Exit(1)
+	}
+	twoFactorHandler := auth.NewTwoFactorHandler(authSvc, userSvc, encryptor, "AIOX")
 
 	// Agents
-	agentRepo := agents.NewRepository(pool)
-	agentSvc := agents.NewService(agentRepo, cfg.Encryption.Key, cfg.XMPP.Domain)
+	agentRepo := agents.WithRetry(agents.NewRepository(pool), dbRetry)
+	templateRepo := agents.NewTemplateRepository(pool)
+	agentSvc := agents.NewService(agentRepo, templateRepo, encryptor, cfg.XMPP.Domain)
+	agentSvc.SetAllowedProviders(cfg.Governance.AllowedProvidersGlobal)
+	agentSvc.SetPromptLimits(cfg.Agents.MaxSystemPromptLength, cfg.Agents.SystemPromptExternalThreshold)
 	agentHandler := agents.NewHandler(agentSvc)
+	templateHandler := agents.NewTemplateHandler(agents.NewTemplateService(templateRepo))
 
 	// Memory (Phase 4)
-	memoryRepo := memory.NewPostgresRepository(pool)
+	memoryRepo := memory.WithRetry(memory.NewPostgresRepository(pool), dbRetry)
 	shortTermStore := memory.NewShortTermStore(redisClient)
 	memorySvc := memory.NewService(memoryRepo, shortTermStore)
+	// Shared by memory and the response cache
+	var embedder memory.Embedder
+	if cfg.Embedder.URL != "" {
+		embedder = memory.NewHTTPEmbedder(cfg.Embedder.URL, cfg.Embedder.APIKey, cfg.Embedder.Timeout)
+		if cfg.Embedder.CacheTTL > 0 {
+			embedder = memory.NewCachedEmbedder(embedder, redisClient, cfg.Embedder.CacheTTL)
+		}
+		memorySvc.SetEmbedder(embedder)
+	}
 	memoryHandler := memory.NewHandler(memorySvc)
 
 	// Governance (Phase 5)
@@ -106,34 +180,94 @@ func main() {
 	rateLimiter := quota.NewRateLimiter(redisClient)
 	quotaSvc := quota.NewService(quotaRepo, rateLimiter, cfg.Governance)
 	auditRepo := audit.NewRepository(pool)
+	auditRepo.SetRetrier(dbRetry)
 	govHandler := governance.NewHandler(quotaSvc, auditRepo)
 
 	// NATS publisher and consumer manager
 	publisher := inats.NewPublisher(natsClient.JetStream())
+	codec, err := inats.CodecByName(cfg.NATS.Codec)
+	if err != nil {
+		slog.Error("configuring NATS codec", "error", err)
+		os.Exit(1)
+	}
+	publisher.SetCodec(codec)
+	if cfg.NATS.PublishBufferSize > 0 {
+		publisher.EnableBuffer(cfg.NATS.PublishBufferSize, natsClient)
+	}
+	authSvc.SetAuditPublisher(publisher)
+	quotaSvc.SetAuditPublisher(publisher)
+	memorySvc.SetAuditPublisher(publisher)
+	passwordResetHandler := auth.NewPasswordResetHandler(authSvc, userSvc, auth.LogMailer{}, publisher)
 	consumerMgr := inats.NewConsumerManager(natsClient.JetStream())
+	consumerMgr.SetPolicy(inats.ConsumerPolicy{
+		AckWait:     cfg.NATS.Consumers.AckWait,
+		MaxDeliver:  cfg.NATS.Consumers.MaxDeliver,
+		BackoffBase: cfg.NATS.Consumers.BackoffBase,
+		BackoffMax:  cfg.NATS.Consumers.BackoffMax,
+	})
+	deadLetterHandler := governance.NewDeadLetterHandler(inats.NewDeadLetterStore(natsClient.JetStream(), publisher))
+	auditStreamHandler := governance.NewAuditStreamHandler(inats.NewAuditFeed(natsClient.JetStream()))
 
 	// Audit consumer: NATS → audit_logs table
 	auditConsumer := audit.NewConsumer(auditRepo, consumerMgr)
 
+	// Webhooks: NATS events → user endpoints
+	webhookSvc := webhooks.NewService(webhooks.NewRepository(pool), encryptor, cfg.Webhook.Timeout)
+	if cfg.Webhook.AllowPrivateNetworks {
+		slog.Warn("webhooks may target private network addresses")
+		webhookSvc.AllowPrivateNetworks()
+	}
+	webhookHandler := webhooks.NewHandler(webhookSvc)
+	webhookDispatcher := webhooks.NewDispatcher(webhookSvc, consumerMgr, cfg.Webhook.MaxAttempts, cfg.Webhook.DisableAfter)
+	webhookDispatcher.SetAuditPublisher(publisher)
+
 	// Orchestrator
 	validator := orchestrator.NewValidator()
 	orchRouter := orchestrator.NewRouter(agentRepo)
 	orch := orchestrator.NewOrchestrator(publisher, consumerMgr, validator, orchRouter, quotaSvc)
+	orch.SetSenderLimiter(orchestrator.NewSenderLimiter(rateLimiter))
+	orch.SetRoutingFailureMode(orchestrator.RoutingFailureMode(cfg.XMPP.RoutingFailure))
+	moderator, err := moderation.NewEngine(cfg.Moderation)
+	if err != nil {
+		slog.Error("creating moderation engine", "error", err)
+		os.Exit(1)
+	}
+	orch.SetModerator(moderator)
 
 	// XMPP handler and component
+	roomSvc := rooms.NewService(rooms.NewRepository(pool))
+	roomHandler := rooms.NewHandler(roomSvc)
 	xmppHandler := ixmpp.NewHandler(publisher)
+	xmppHandler.SetRooms(roomSvc)
+	if cfg.XMPP.DedupWindowSec > 0 {
+		xmppHandler.SetDeduplicator(ixmpp.NewDeduplicator(redisClient, time.Duration(cfg.XMPP.DedupWindowSec)*time.Second))
+	}
 	xmppComp, err := ixmpp.NewComponent(cfg.XMPP, xmppHandler)
 	if err != nil {
 		slog.Error("creating XMPP component", "error", err)
 		os.Exit(1)
 	}
+	// Agents rejoin their rooms each time the component (re)connects
+	roomSvc.SetJoiner(ixmpp.NewRoomJoiner(xmppComp.Sender()))
+	xmppComp.OnConnect(roomSvc.JoinAll)
 
-	// Outbound relay: NATS → XMPP
-	outboundRelay := ixmpp.NewOutboundRelay(xmppHandler, xmppComp.Sender(), consumerMgr)
+	// Outbound relay: NATS → XMPP, retrying pending messages on reconnect
+	outboundRelay := ixmpp.NewOutboundRelay(xmppHandler, xmppComp.Sender(), consumerMgr, ixmpp.NewOutboundStore(pool), publisher)
+	outboundRelay.SetMaxAttempts(cfg.XMPP.OutboundMaxAttempts)
+	xmppComp.OnConnect(outboundRelay.Reconnected)
 
 	// Worker pool + gRPC server
 	workerPool := worker.NewPool()
+	selector, err := worker.SelectorByName(cfg.GRPC.LoadBalancing)
+	if err != nil {
+		slog.Error("configuring worker load balancing", "error", err)
+		os.Exit(1)
+	}
+	workerPool.SetSelector(selector)
 	workerRepo := worker.NewRepository(pool)
+	executionHandler := worker.NewExecutionHandler(workerRepo, agentSvc)
+	exportHandler := export.NewHandler(export.NewExporter(agentSvc, memoryRepo, workerRepo, auditRepo), publisher)
+	accountHandler := account.NewHandler(account.NewService(account.NewRepository(pool), authSvc, shortTermStore), userSvc, authSvc)
 	grpcWorkerServer := worker.NewServer(workerPool, workerRepo)
 
 	var grpcServerOpts []grpc.ServerOption
@@ -146,51 +280,198 @@ func main() {
 	grpcSrv := grpc.NewServer(grpcServerOpts...)
 	pb.RegisterWorkerServiceServer(grpcSrv, grpcWorkerServer)
 
+	// Set once the gRPC listener is bound; reported by the readiness probe
+	var grpcServing atomic.Bool
+	// Set on SIGINT/SIGTERM so readiness fails while HTTP drains
+	var shuttingDown atomic.Bool
+
+	// PII redaction applied to executions, memory, and (optionally) replies
+	redactor, err := redaction.NewEngine(cfg.Redaction)
+	if err != nil {
+		slog.Error("creating redaction engine", "error", err)
+		os.Exit(1)
+	}
+	if cfg.Redaction.Audit {
+		auditConsumer.SetRedactor(redactor.Deployment())
+	}
+
 	// Task dispatcher: NATS tasks → gRPC workers → outbound messages
 	dispatcher := worker.NewDispatcher(
 		workerPool, publisher, consumerMgr,
-		agentSvc, workerRepo, memorySvc, quotaSvc, grpcWorkerServer.ResultChannel(),
+		agentSvc, workerRepo, memorySvc, quotaSvc, redactor, grpcWorkerServer.ResultChannel(),
 		cfg.GRPC.TaskTimeoutSec,
 	)
+	dispatcher.SetMaxTaskTimeout(time.Duration(cfg.GRPC.MaxTaskTimeoutSec) * time.Second)
+	dispatcher.SetLLMCaps(llmCaps(cfg.Governance))
+	dispatcher.SetDebugContext(cfg.GRPC.DebugContext)
+	// The same checks as the readiness probe, so tasks wait while it fails
+	healthGate := worker.NewHealthGate(api.DatabaseCheck(pool, dbBreaker.State), api.RedisCheck(redisClient))
+	healthGate.SetFailOpen(cfg.GRPC.HealthGate == "open")
+	dispatcher.SetHealthGate(healthGate)
+	dispatcher.SetMaxBufferedChunks(cfg.GRPC.MaxBufferedChunks)
+	dispatcher.SetMaxDeliveries(cfg.NATS.MaxDeliveries)
+	dispatcher.SetPricing(quota.NewPricing(cfg.Pricing.Models))
+	var cacheEmbedder responsecache.Embedder
+	if embedder != nil {
+		// The default model embeds into the cache's 384-dim space; the cache
+		// skips similarity matching for embeddings of any other dimension.
+		cacheEmbedder = responsecache.EmbedderFunc(func(ctx context.Context, text string) ([]float32, error) {
+			return embedder.Embed(ctx, memory.DefaultEmbeddingModel, text)
+		})
+	}
+	dispatcher.SetResponseCache(responsecache.NewService(responsecache.NewPostgresRepository(pool), cacheEmbedder))
+	dispatcher.SetSummaryChannel(grpcWorkerServer.SummaryChannel())
+	memorySvc.SetSummarizer(dispatcher)
+	invokeHandler := worker.NewInvokeHandler(dispatcher, quotaSvc)
+	poolHandler := worker.NewPoolHandler(workerPool)
+
+	// WebSocket chat: authenticated users talk to their own agents over the NATS flow
+	chatHandler := api.NewChatHandler(publisher, natsClient.Conn(), agents.ChatTarget, cfg.XMPP.Domain, cfg.Server.CORSAllowedOrigins)
 
 	// Auth rate limiter
 	authRateLimiter := middleware.NewRateLimiter(redisClient, 20, 60)
 
+	// Idempotency keys for create endpoints, scoped per user
+	idempotency := middleware.NewIdempotency(redisClient, func(r *http.Request) string {
+		if claims := auth.GetUserClaims(r.Context()); claims != nil {
+			return claims.UserID
+		}
+		return ""
+	})
+
 	// Router
-	router := api.NewRouter(pool, natsClient, api.RouterConfig{
-		CORSAllowedOrigins: cfg.Server.CORSAllowedOrigins,
-		AuthRateLimiter:    authRateLimiter.Middleware,
+	router := api.NewRouter(pool, natsClient, redisClient, api.RouterConfig{
+		CORS: middleware.CORSConfig{
+			AllowedOrigins:   cfg.Server.CORSAllowedOrigins,
+			AllowedMethods:   cfg.Server.CORSAllowedMethods,
+			AllowedHeaders:   cfg.Server.CORSAllowedHeaders,
+			ExposedHeaders:   cfg.Server.CORSExposedHeaders,
+			AllowCredentials: cfg.Server.CORSAllowCredentials,
+			MaxAge:           cfg.Server.CORSMaxAge,
+		},
+		Logging: middleware.LoggingConfig{
+			Fields:        cfg.Log.Requests.Fields,
+			Exclude:       cfg.Log.Requests.ExcludeFields,
+			RedactHeaders: cfg.Log.Requests.RedactHeaders,
+			SampleRate:    cfg.Log.Requests.SampleRate,
+		},
+		Metrics: middleware.MetricsConfig{
+			AgentLabel: cfg.Server.MetricsAgentLabel,
+			MaxAgents:  cfg.Server.MetricsMaxAgentLabels,
+		},
+		AuthRateLimiter:  authRateLimiter.Middleware,
+		Idempotency:      idempotency.Middleware,
+		MaxBodyBytes:     cfg.Server.MaxBodyBytes,
+		AuthMaxBodyBytes: cfg.Server.AuthMaxBodyBytes,
+		BulkMaxBodyBytes: cfg.Server.BulkMaxBodyBytes,
+		HandlerTimeout:   cfg.Server.HandlerTimeout,
 	}, api.HandlerSet{
 		Register: authHandler.Register,
 		Login:    authHandler.Login,
 		Refresh:  authHandler.Refresh,
 		Logout:   authHandler.Logout,
 
+		ListSessions:  authHandler.ListSessions,
+		RevokeSession: authHandler.RevokeSession,
+
+		EnrollTwoFactor:  twoFactorHandler.Enroll,
+		VerifyTwoFactor:  twoFactorHandler.Verify,
+		DisableTwoFactor: twoFactorHandler.Disable,
+		TwoFactorLogin:   twoFactorHandler.Login,
+
+		RequestPasswordReset: passwordResetHandler.Request,
+		ConfirmPasswordReset: passwordResetHandler.Confirm,
+
+		CreateAPIKey: apiKeyHandler.Create,
+		ListAPIKeys:  apiKeyHandler.List,
+		RevokeAPIKey: apiKeyHandler.Revoke,
+
 		CreateAgent:         agentHandler.Create,
 		ListAgents:          agentHandler.List,
+		ListPublicAgents:    agentHandler.ListPublic,
 		GetAgent:            agentHandler.Get,
 		UpdateAgent:         agentHandler.Update,
 		DeleteAgent:         agentHandler.Delete,
+		ListAgentVersions:   agentHandler.ListVersions,
+		RollbackAgent:       agentHandler.Rollback,
+		PauseAgent:          agentHandler.Pause,
+		ResumeAgent:         agentHandler.Resume,
+		ListAgentExecutions: executionHandler.List,
+		GetAgentExecution:   executionHandler.Get,
+		GetExecutionContext: executionHandler.Context,
+		InvokeAgent:         invokeHandler.Invoke,
 		OwnershipMiddleware: agentHandler.OwnershipMiddleware,
 
-		ListMemories:      memoryHandler.List,
-		CreateMemory:      memoryHandler.Create,
-		SearchMemories:    memoryHandler.Search,
-		DeleteMemory:      memoryHandler.Delete,
-		DeleteAllMemories: memoryHandler.DeleteAll,
+		CreateRoomBinding: roomHandler.Create,
+		ListRoomBindings:  roomHandler.List,
+		DeleteRoomBinding: roomHandler.Delete,
+
+		CreatePromptTemplate: templateHandler.Create,
+		ListPromptTemplates:  templateHandler.List,
+		GetPromptTemplate:    templateHandler.Get,
+		UpdatePromptTemplate: templateHandler.Update,
+		DeletePromptTemplate: templateHandler.Delete,
+
+		AgentChat: chatHandler,
+
+		ListMemories:       memoryHandler.List,
+		CreateMemory:       memoryHandler.Create,
+		BulkCreateMemories: memoryHandler.BulkCreate,
+		SearchMemories:     memoryHandler.Search,
+		DeleteMemory:       memoryHandler.Delete,
+		DeleteAllMemories:  memoryHandler.DeleteAll,
+		RestoreMemory:      memoryHandler.Restore,
+		ListConversations:  memoryHandler.ListConversations,
+		GetConversation:    memoryHandler.GetConversation,
+		ClearConversation:  memoryHandler.ClearConversation,
 
 		GetUserQuota:       govHandler.GetQuota,
 		ListAuditLogs:      govHandler.ListAuditLogs,
 		ListAgentAuditLogs: govHandler.ListAgentAuditLogs,
-
-		AuthMiddleware: auth.Middleware(authSvc),
-
-		WorkerPoolHealthy: func() bool { return workerPool.ConnectedCount() > 0 },
+		GetAgentQuota:      govHandler.GetAgentQuota,
+		GetUserCost:        govHandler.GetCost,
+		ListDeadLetters:    deadLetterHandler.List,
+		RetryDeadLetter:    deadLetterHandler.Retry,
+		StreamAuditLogs:    auditStreamHandler.Stream,
+
+		ExportAccount: exportHandler.Export,
+		DeleteAccount: accountHandler.Delete,
+
+		CreateWebhook: webhookHandler.Create,
+		ListWebhooks:  webhookHandler.List,
+		GetWebhook:    webhookHandler.Get,
+		UpdateWebhook: webhookHandler.Update,
+		DeleteWebhook: webhookHandler.Delete,
+		TestWebhook:   webhookHandler.Test,
+
+		ListWorkers:     poolHandler.List,
+		ListUserQuotas:  govHandler.ListUserQuotas,
+		RevokeSessions:  authHandler.RevokeSessions,
+		SetAgentLLMCaps: agentHandler.SetLLMCaps,
+
+		AuthMiddleware: auth.Middleware(authSvc, apiKeySvc),
+		RequireSession: auth.RequireSession,
+		RequireScope:   auth.RequireScope,
+		RequireAdmin:   auth.RequireAdmin,
+
+		WorkerStats: func() api.WorkerStats {
+			capacity, active := workerPool.Capacity()
+			return api.WorkerStats{Connected: workerPool.ConnectedCount(), Capacity: capacity, Active: active}
+		},
+		GRPCServing:  grpcServing.Load,
+		ShuttingDown: shuttingDown.Load,
+		DBCircuit:    dbBreaker.State,
 	})
 
 	// Start background goroutines
 	var wg sync.WaitGroup
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		database.MonitorPool(ctx, pool)
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -228,6 +509,8 @@ func main() {
 			return
 		}
 		slog.Info("starting gRPC server", "addr", addr)
+		grpcServing.Store(true)
+		defer grpcServing.Store(false)
 		if err := grpcSrv.Serve(lis); err != nil {
 			slog.Error("gRPC server error", "error", err)
 		}
@@ -251,19 +534,85 @@ func main() {
 		}
 	}()
 
-	// Start HTTP server (blocks until shutdown signal)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("starting webhook dispatcher")
+		if err := webhookDispatcher.Start(ctx); err != nil {
+			slog.Error("webhook dispatcher error", "error", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("starting memory purger")
+		if err := memorySvc.RunPurge(ctx); err != nil {
+			slog.Error("memory purger error", "error", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("starting memory access recorder")
+		if err := memorySvc.RunAccessFlush(ctx); err != nil {
+			slog.Error("memory access recorder error", "error", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("starting memory pruner")
+		if err := memorySvc.RunPrune(ctx); err != nil {
+			slog.Error("memory pruner error", "error", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("starting worker heartbeat reaper")
+		reaper := worker.NewReaper(workerPool, workerRepo, time.Duration(cfg.GRPC.HeartbeatTimeoutSec)*time.Second)
+		if err := reaper.Run(ctx); err != nil {
+			slog.Error("worker reaper error", "error", err)
+		}
+	}()
+
+	// SIGHUP: re-read config and apply the hot-reloadable subset
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reloadOnSIGHUP(ctx, cfg, func(next *config.Config) {
+			logLevel.Set(next.Log.SlogLevel())
+			quotaSvc.SetConfig(next.Governance)
+			agentSvc.SetAllowedProviders(next.Governance.AllowedProvidersGlobal)
+			agentSvc.SetPromptLimits(next.Agents.MaxSystemPromptLength, next.Agents.SystemPromptExternalThreshold)
+			dispatcher.SetTaskTimeout(time.Duration(next.GRPC.TaskTimeoutSec) * time.Second)
+			dispatcher.SetMaxTaskTimeout(time.Duration(next.GRPC.MaxTaskTimeoutSec) * time.Second)
+			dispatcher.SetLLMCaps(llmCaps(next.Governance))
+			dispatcher.SetDebugContext(next.GRPC.DebugContext)
+			healthGate.SetFailOpen(next.GRPC.HealthGate == "open")
+		})
+	}()
+
+	// Start HTTP server (blocks until shutdown signal and in-flight requests drain)
 	srv := server.New(cfg.Server, router)
+	srv.OnShutdown(func() { shuttingDown.Store(true) })
 	if err := srv.Start(); err != nil {
 		slog.Error("server error", "error", err)
 	}
 
-	// Ordered shutdown
+	// Ordered shutdown: HTTP has drained above, so nothing is still using the
+	// NATS, Redis, or Postgres clients closed below.
 	slog.Info("initiating shutdown")
 	cancel()
 
 	grpcSrv.GracefulStop()
 
-	// Wait for goroutines with timeout
+	// Wait for goroutines with timeout. The dispatcher answers its pending
+	// tasks before returning, within worker.ShutdownDrainTimeout.
 	done := make(chan struct{})
 	go func() {
 		wg.Wait()
@@ -283,22 +632,23 @@ func main() {
 	slog.Info("shutdown complete")
 }
 
-func setupLogger(cfg config.LogConfig) {
+// llmCaps converts the deployment's governance settings into the LLM caps
+// enforced at dispatch.
+func llmCaps(gov config.GovernanceCfg) agents.LLMCaps {
+	return agents.LLMCaps{
+		MaxTokens:      gov.MaxTokensPerRequest,
+		MinTemperature: &gov.MinTemperature,
+		MaxTemperature: &gov.MaxTemperature,
+		AllowedModels:  gov.AllowedModels,
+	}
+}
+
+func setupLogger(cfg config.LogConfig, level *slog.LevelVar) {
 	var handler slog.Handler
 
-	opts := &slog.HandlerOptions{}
-	switch cfg.Level {
-	case "debug":
-		opts.Level = slog.LevelDebug
-	case "info":
-		opts.Level = slog.LevelInfo
-	case "warn":
-		opts.Level = slog.LevelWarn
-	case "error":
-		opts.Level = slog.LevelError
-	default:
-		opts.Level = slog.LevelInfo
-	}
+	// The handler reads the level through the LevelVar, so a SIGHUP reload can change it in place.
+	level.Set(cfg.SlogLevel())
+	opts := &slog.HandlerOptions{Level: level}
 
 	if cfg.Format == "json" {
 		handler = slog.NewJSONHandler(os.Stdout, opts)
@@ -306,5 +656,6 @@ func setupLogger(cfg config.LogConfig) {
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
 
-	slog.SetDefault(slog.New(handler))
+	// Records logged with a request context carry its request and user IDs.
+	slog.SetDefault(slog.New(middleware.NewContextHandler(handler)))
 }
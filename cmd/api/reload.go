@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/aiox-platform/aiox/internal/config"
+)
+
+// reloadOnSIGHUP re-reads configuration on every SIGHUP and hands the merged
+// config to apply. Only log level, governance limits, and the task timeout are
+// hot-reloadable; other changes are logged as needing a restart and ignored.
+func reloadOnSIGHUP(ctx context.Context, current *config.Config, apply func(*config.Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		}
+
+		slog.Info("config reload: SIGHUP received")
+
+		next, err := config.Load()
+		if err != nil {
+			slog.Error("config reload: loading config, keeping current settings", "error", err)
+			continue
+		}
+		if err := next.Validate(); err != nil {
+			slog.Error("config reload: validation failed, keeping current settings", "error", err)
+			continue
+		}
+
+		plan := config.Diff(current, next)
+		for _, section := range plan.RestartRequired {
+			slog.Warn("config reload: change requires a restart, ignoring", "section", section)
+		}
+		if len(plan.Apply) == 0 {
+			slog.Info("config reload: no hot-reloadable changes")
+			continue
+		}
+
+		current = current.MergeReloadable(next)
+		apply(current)
+		for _, c := range plan.Apply {
+			slog.Info("config reload: applied", "field", c.Field, "old", c.Old, "new", c.New)
+		}
+	}
+}
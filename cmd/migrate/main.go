@@ -0,0 +1,144 @@
+// Command aiox-migrate applies, rolls back, and inspects database migrations
+// using the same configuration as the API (DB_* and DB_MIGRATIONS_PATH).
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/database"
+)
+
+const usage = `Usage: aiox-migrate <command> [--yes]
+
+Commands:
+  up          Apply all pending migrations
+  down N      Roll back the last N migrations (requires --yes)
+  force V     Mark version V as applied and clean, without running SQL (requires --yes)
+  version     Print the current version and dirty state
+`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var positional []string
+	yes := false
+	for _, a := range args {
+		switch a {
+		case "--yes", "-yes", "-y":
+			yes = true
+		case "-h", "--help", "help":
+			fmt.Print(usage)
+			return nil
+		default:
+			positional = append(positional, a)
+		}
+	}
+	if len(positional) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errors.New("missing command")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	m, err := database.NewMigrator(cfg.DB.DSN(), cfg.DB.MigrationsPath)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	cmd, rest := positional[0], positional[1:]
+	switch cmd {
+	case "version":
+		if len(rest) != 0 {
+			return errors.New("version takes no arguments")
+		}
+		return printVersion(m)
+
+	case "up":
+		if len(rest) != 0 {
+			return errors.New("up takes no arguments")
+		}
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			printVersion(m)
+			return fmt.Errorf("applying migrations: %w", err)
+		}
+		return printVersion(m)
+
+	case "down":
+		n, err := intArg(cmd, rest)
+		if err != nil {
+			return err
+		}
+		if n < 1 {
+			return errors.New("down N requires N >= 1")
+		}
+		if !yes {
+			return fmt.Errorf("down %d drops schema and data; re-run with --yes to confirm", n)
+		}
+		if err := m.Steps(-n); err != nil {
+			printVersion(m)
+			return fmt.Errorf("rolling back %d migration(s): %w", n, err)
+		}
+		return printVersion(m)
+
+	case "force":
+		v, err := intArg(cmd, rest)
+		if err != nil {
+			return err
+		}
+		if !yes {
+			return fmt.Errorf("force %d overwrites the recorded version without running SQL; re-run with --yes to confirm", v)
+		}
+		if err := m.Force(v); err != nil {
+			return fmt.Errorf("forcing version %d: %w", v, err)
+		}
+		return printVersion(m)
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func intArg(cmd string, rest []string) (int, error) {
+	if len(rest) != 1 {
+		return 0, fmt.Errorf("%s requires exactly one numeric argument", cmd)
+	}
+	n, err := strconv.Atoi(rest[0])
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %q", cmd, rest[0])
+	}
+	return n, nil
+}
+
+func printVersion(m *migrate.Migrate) error {
+	version, dirty, ok, err := database.MigrationVersion(m)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !ok:
+		fmt.Println("version: none (no migrations applied)")
+	case dirty:
+		fmt.Printf("version: %d (DIRTY)\n", version)
+		fmt.Printf("migration %d failed partway. Repair the schema by hand, then run\n", version)
+		fmt.Printf("`force %d --yes` if it is fully applied or `force %d --yes` if it is not.\n", version, version-1)
+	default:
+		fmt.Printf("version: %d (clean)\n", version)
+	}
+	return nil
+}
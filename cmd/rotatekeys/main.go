@@ -0,0 +1,86 @@
+// Command aiox-rotate-keys re-encrypts stored agent system prompts with the
+// current ENCRYPTION_KEY, reading prompts sealed with any of
+// ENCRYPTION_PREVIOUS_KEYS. It uses the same configuration as the API.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/auth"
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/database"
+)
+
+const usage = `Usage: aiox-rotate-keys [--batch N]
+
+Re-encrypts every agent system prompt, including version history, with
+ENCRYPTION_KEY. Prompts sealed with a key in ENCRYPTION_PREVIOUS_KEYS are
+rewritten; prompts already using ENCRYPTION_KEY are left alone, so the
+command is safe to re-run.
+
+Options:
+  --batch N   Rows read per query (default 100)
+`
+
+const defaultBatch = 100
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	batch := defaultBatch
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--batch", "-batch":
+			if i+1 >= len(args) {
+				return errors.New("--batch requires a number")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 1 {
+				return fmt.Errorf("--batch: invalid size %q", args[i])
+			}
+			batch = n
+		case "-h", "--help", "help":
+			fmt.Print(usage)
+			return nil
+		default:
+			fmt.Fprint(os.Stderr, usage)
+			return fmt.Errorf("unknown argument %q", args[i])
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+
+	enc, err := auth.NewEncryptor(cfg.Encryption.Key, cfg.Encryption.PreviousKeys...)
+	if err != nil {
+		return fmt.Errorf("creating encryptor: %w", err)
+	}
+
+	ctx := context.Background()
+	pool, err := database.NewPostgresPool(ctx, cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	svc := agents.NewService(agents.NewRepository(pool), agents.NewTemplateRepository(pool), enc, cfg.XMPP.Domain)
+	res, err := svc.RotateEncryption(ctx, batch)
+	fmt.Printf("rotated to key %s: %d agent(s), %d version(s), %d stored prompt(s)\n", enc.PrimaryKeyID(), res.Agents, res.Versions, res.Prompts)
+	if err != nil {
+		return fmt.Errorf("rotating system prompts: %w", err)
+	}
+	return nil
+}
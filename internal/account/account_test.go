@@ -0,0 +1,114 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/auth"
+	"github.com/aiox-platform/aiox/internal/users"
+)
+
+// fakeStore holds one user and their agents until Delete is called.
+type fakeStore struct {
+	users     map[uuid.UUID]*users.User
+	agents    []uuid.UUID
+	deletes   int
+	loggedOut []string
+	cleared   []uuid.UUID
+	started   time.Time
+}
+
+func (f *fakeStore) Delete(_ context.Context, userID uuid.UUID, _ string) (*Deletion, error) {
+	f.deletes++
+	if f.users[userID] == nil {
+		return nil, nil
+	}
+	delete(f.users, userID)
+	return &Deletion{UserID: userID, AgentIDs: f.agents, Counts: map[string]int64{"agents": int64(len(f.agents))}}, nil
+}
+
+func (f *fakeStore) GetByID(_ context.Context, id uuid.UUID) (*users.User, error) {
+	return f.users[id], nil
+}
+
+func (f *fakeStore) Logout(userID string) error {
+	f.loggedOut = append(f.loggedOut, userID)
+	return nil
+}
+
+func (f *fakeStore) ClearAgent(_ context.Context, agentID uuid.UUID) error {
+	f.cleared = append(f.cleared, agentID)
+	return nil
+}
+
+func (f *fakeStore) SessionStartedAt(context.Context, string, string) (time.Time, error) {
+	return f.started, nil
+}
+
+func newTestHandler(t *testing.T) (*Handler, *fakeStore, uuid.UUID) {
+	t.Helper()
+	hash, err := auth.HashPassword("correct horse battery")
+	require.NoError(t, err)
+	userID := uuid.New()
+	store := &fakeStore{
+		users:  map[uuid.UUID]*users.User{userID: {ID: userID, PasswordHash: hash}},
+		agents: []uuid.UUID{uuid.New(), uuid.New()},
+	}
+	return NewHandler(NewService(store, store, store), store, store), store, userID
+}
+
+func deleteAccount(h *Handler, claims *auth.AccessClaims, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/account", strings.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), auth.UserClaimsKey, claims))
+	rec := httptest.NewRecorder()
+	h.Delete(rec, req)
+	return rec
+}
+
+func TestDelete_RequiresReauthentication(t *testing.T) {
+	h, store, userID := newTestHandler(t)
+	claims := &auth.AccessClaims{UserID: userID.String(), SessionID: "s1"}
+
+	store.started = time.Now().Add(-time.Hour)
+	assert.Equal(t, http.StatusPreconditionRequired, deleteAccount(h, claims, "").Code, "stale session without password")
+	assert.Equal(t, http.StatusUnauthorized, deleteAccount(h, claims, `{"password":"wrong"}`).Code)
+
+	apiKey := &auth.AccessClaims{UserID: userID.String(), Scopes: auth.AllScopes}
+	assert.Equal(t, http.StatusForbidden, deleteAccount(h, apiKey, `{"password":"correct horse battery"}`).Code)
+
+	assert.Zero(t, store.deletes)
+}
+
+func TestDelete_WithPasswordIsIdempotent(t *testing.T) {
+	h, store, userID := newTestHandler(t)
+	claims := &auth.AccessClaims{UserID: userID.String(), SessionID: "s1"}
+
+	rec := deleteAccount(h, claims, `{"password":"correct horse battery"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, store.users)
+	assert.Equal(t, store.agents, store.cleared, "every agent's conversations are cleared")
+	assert.Equal(t, []string{userID.String()}, store.loggedOut)
+
+	// The access token outlives the account; repeating the call succeeds.
+	rec = deleteAccount(h, claims, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, store.deletes)
+	assert.Len(t, store.cleared, 2)
+}
+
+func TestDelete_RecentLoginNeedsNoPassword(t *testing.T) {
+	h, store, userID := newTestHandler(t)
+	store.started = time.Now().Add(-time.Minute)
+
+	rec := deleteAccount(h, &auth.AccessClaims{UserID: userID.String(), SessionID: "s1"}, "")
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, store.users)
+}
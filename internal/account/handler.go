@@ -0,0 +1,121 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+	mw "github.com/aiox-platform/aiox/internal/middleware"
+	"github.com/aiox-platform/aiox/internal/users"
+)
+
+// RecentLoginWindow is how long after logging in a session may delete the
+// account without presenting the password again.
+const RecentLoginWindow = 5 * time.Minute
+
+// DeleteAccountRequest is the optional body of DELETE /account.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// UserGetter looks up users; satisfied by *users.Service.
+type UserGetter interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*users.User, error)
+}
+
+// SessionChecker reports when a login session started; satisfied by
+// *auth.Service.
+type SessionChecker interface {
+	SessionStartedAt(ctx context.Context, userID, sessionID string) (time.Time, error)
+}
+
+// Handler serves account deletion.
+type Handler struct {
+	svc      *Service
+	users    UserGetter
+	sessions SessionChecker
+}
+
+// NewHandler creates a new Handler.
+func NewHandler(svc *Service, users UserGetter, sessions SessionChecker) *Handler {
+	return &Handler{svc: svc, users: users, sessions: sessions}
+}
+
+// Delete permanently deletes the caller's account. The caller must
+// re-authenticate, either with their password in the body or with a session
+// that logged in within RecentLoginWindow; API keys cannot delete accounts.
+// Deleting an account that is already gone succeeds.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+	if claims.SessionID == "" {
+		api.HandleError(w, api.NewForbiddenError("accounts can only be deleted from a login session"))
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := h.users.GetByID(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "getting user for account deletion", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if user != nil {
+		if appErr := h.reauthenticate(ctx, user, claims.SessionID, req.Password); appErr != nil {
+			api.HandleError(w, appErr)
+			return
+		}
+	}
+
+	// Runs for a missing user too, revoking any refresh tokens left behind.
+	if _, err := h.svc.Delete(ctx, userID, mw.ClientIP(r)); err != nil {
+		slog.ErrorContext(r.Context(), "deleting account", "error", err, "user_id", userID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "account deleted")
+}
+
+// reauthenticate checks password when given, and otherwise that the session
+// logged in recently.
+func (h *Handler) reauthenticate(ctx context.Context, user *users.User, sessionID, password string) *api.AppError {
+	if password != "" {
+		if auth.ComparePassword(user.PasswordHash, password) != nil {
+			return api.ErrInvalidCredentials
+		}
+		return nil
+	}
+
+	started, err := h.sessions.SessionStartedAt(ctx, user.ID.String(), sessionID)
+	if err != nil {
+		slog.Error("reading session for account deletion", "error", err)
+		return api.ErrInternalServer
+	}
+	if started.IsZero() || time.Since(started) > RecentLoginWindow {
+		return api.NewError(api.CodePreconditionRequired, "re-authentication required: send your password or log in again")
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package account deletes a user's account and everything it owns.
+package account
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Deletion describes a deleted account.
+type Deletion struct {
+	UserID uuid.UUID
+	// AgentIDs lists every agent the user owned, soft-deleted ones included,
+	// so their Redis state can be cleared after the commit.
+	AgentIDs []uuid.UUID
+	// Counts holds the number of rows removed per resource.
+	Counts    map[string]int64
+	DeletedAt time.Time
+}
+
+// Repository deletes accounts in PostgreSQL.
+type Repository interface {
+	// Delete removes the user and all of their rows in one transaction and
+	// records an account_deleted event in retained_audit_logs. It returns
+	// nil when the user does not exist.
+	Delete(ctx context.Context, userID uuid.UUID, ipAddress string) (*Deletion, error)
+}
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new account Repository.
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+// ownedRows are deleted explicitly, in order, so their counts can be
+// recorded; deleting the user afterwards cascades to everything else.
+var ownedRows = []struct {
+	resource string
+	query    string
+}{
+	{"memories", `DELETE FROM agent_memories WHERE owner_user_id = $1`},
+	{"executions", `DELETE FROM executions WHERE owner_user_id = $1`},
+	{"agent_quotas", `DELETE FROM agent_quotas WHERE owner_user_id = $1`},
+	{"user_quotas", `DELETE FROM user_quotas WHERE user_id = $1`},
+	{"audit_logs", `DELETE FROM audit_logs WHERE owner_user_id = $1`},
+	{"agents", `DELETE FROM agents WHERE owner_user_id = $1`},
+}
+
+func (r *postgresRepository) Delete(ctx context.Context, userID uuid.UUID, ipAddress string) (*Deletion, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning account deletion: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var email string
+	err = tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("locking user: %w", err)
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id FROM agents WHERE owner_user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listing agents: %w", err)
+	}
+	var agentIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning agent id: %w", err)
+		}
+		agentIDs = append(agentIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating agent ids: %w", err)
+	}
+
+	// xmpp_sessions is the one table that does not cascade.
+	_, err = tx.Exec(ctx,
+		`DELETE FROM xmpp_sessions WHERE owner_user_id = $1 OR agent_id = ANY($2)`, userID, agentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("deleting xmpp sessions: %w", err)
+	}
+
+	d := &Deletion{
+		UserID:    userID,
+		AgentIDs:  agentIDs,
+		Counts:    make(map[string]int64, len(ownedRows)),
+		DeletedAt: time.Now().UTC(),
+	}
+	for _, owned := range ownedRows {
+		tag, err := tx.Exec(ctx, owned.query, userID)
+		if err != nil {
+			return nil, fmt.Errorf("deleting %s: %w", owned.resource, err)
+		}
+		d.Counts[owned.resource] = tag.RowsAffected()
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return nil, fmt.Errorf("deleting user: %w", err)
+	}
+
+	details, err := deletionDetails(email, d.Counts)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO retained_audit_logs (owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address, created_at)
+		 VALUES ($1, 'account_deleted', 'warn', 'user', $1, $2, $3, $4)`,
+		userID, details, ipAddress, d.DeletedAt)
+	if err != nil {
+		return nil, fmt.Errorf("recording account deletion: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing account deletion: %w", err)
+	}
+	return d, nil
+}
+
+// deletionDetails is the retained event's details. The email is kept only as
+// a SHA-256 hash, enough to answer "was this address's account deleted?".
+func deletionDetails(email string, counts map[string]int64) (json.RawMessage, error) {
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	data, err := json.Marshal(map[string]any{
+		"message":      "Account deleted by its owner",
+		"email_sha256": hex.EncodeToString(sum[:]),
+		"deleted":      counts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding deletion details: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,61 @@
+package account
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// SessionRevoker revokes all of a user's refresh tokens; satisfied by
+// *auth.Service.
+type SessionRevoker interface {
+	Logout(userID string) error
+}
+
+// ConversationStore clears an agent's short-term memory; satisfied by
+// *memory.ShortTermStore.
+type ConversationStore interface {
+	ClearAgent(ctx context.Context, agentID uuid.UUID) error
+}
+
+// Service deletes accounts.
+type Service struct {
+	repo          Repository
+	sessions      SessionRevoker
+	conversations ConversationStore
+}
+
+// NewService creates a new account Service.
+func NewService(repo Repository, sessions SessionRevoker, conversations ConversationStore) *Service {
+	return &Service{repo: repo, sessions: sessions, conversations: conversations}
+}
+
+// Delete removes the user's account, then revokes their refresh tokens and
+// clears their agents' conversations in Redis. It returns nil for a user
+// that is already gone, after revoking any refresh tokens left behind, so it
+// is safe to retry. API keys live in PostgreSQL and go with the account;
+// access tokens already issued stay valid until they expire.
+func (s *Service) Delete(ctx context.Context, userID uuid.UUID, ipAddress string) (*Deletion, error) {
+	d, err := s.repo.Delete(ctx, userID, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rows are gone either way; Redis cleanup failures only leave state
+	// that expires on its own.
+	if err := s.sessions.Logout(userID.String()); err != nil {
+		slog.Error("revoking sessions of deleted account", "error", err, "user_id", userID)
+	}
+	if d == nil {
+		return nil, nil
+	}
+	for _, agentID := range d.AgentIDs {
+		if err := s.conversations.ClearAgent(ctx, agentID); err != nil {
+			slog.Error("clearing conversations of deleted agent", "error", err, "agent_id", agentID)
+		}
+	}
+
+	slog.Info("account deleted", "user_id", userID, "deleted", d.Counts)
+	return d, nil
+}
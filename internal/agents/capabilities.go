@@ -0,0 +1,174 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aiox-platform/aiox/internal/jsonschema"
+)
+
+// ChatFeatures are the XMPP conveniences an agent offers its contacts, set
+// under "xmpp" in capabilities:
+//
+//	"capabilities": { "xmpp": { "delivery_receipts": true, "chat_states": false } }
+type ChatFeatures struct {
+	// DeliveryReceipts acknowledges messages that request a receipt (XEP-0184).
+	DeliveryReceipts bool `json:"delivery_receipts"`
+	// ChatStates shows the agent as composing while a reply is pending (XEP-0085).
+	ChatStates bool `json:"chat_states"`
+}
+
+// DefaultChatFeatures enables both receipts and chat states.
+func DefaultChatFeatures() ChatFeatures {
+	return ChatFeatures{DeliveryReceipts: true, ChatStates: true}
+}
+
+// ParseChatFeatures extracts the chat features from agent capabilities.
+// Returns defaults on nil, empty, or invalid input.
+func ParseChatFeatures(capabilities []byte) ChatFeatures {
+	f := DefaultChatFeatures()
+	if len(capabilities) == 0 {
+		return f
+	}
+
+	var caps struct {
+		XMPP json.RawMessage `json:"xmpp"`
+	}
+	if err := json.Unmarshal(capabilities, &caps); err != nil || len(caps.XMPP) == 0 {
+		return f
+	}
+
+	if err := json.Unmarshal(caps.XMPP, &f); err != nil {
+		return DefaultChatFeatures()
+	}
+	return f
+}
+
+// Tools is the registry of tools a worker can expose to an agent's LLM for
+// function calling, keyed by name.
+var Tools = map[string]string{
+	"calculator":    "Evaluate arithmetic expressions",
+	"current_time":  "Report the current date and time in a given time zone",
+	"http_fetch":    "Fetch the text content of a web page",
+	"memory_search": "Search the agent's long-term memory",
+	"web_search":    "Search the web",
+}
+
+// Capabilities is the typed form of the tool and task settings in an agent's
+// capabilities:
+//
+//	"capabilities": {
+//	  "enabled_tools": ["web_search", "calculator"],
+//	  "tools": { "web_search": { "max_results": 5 } },
+//	  "task_timeout_sec": 300,
+//	  "response_schema": { "type": "object", "required": ["answer"] }
+//	}
+//
+// Other keys, such as "xmpp" and "response_cache", are parsed by their own
+// features.
+type Capabilities struct {
+	// EnabledTools lists the registry tools the LLM may call.
+	EnabledTools []string `json:"enabled_tools"`
+	// Tools holds optional per-tool configuration, passed to the worker as is.
+	Tools map[string]json.RawMessage `json:"tools"`
+	// TaskTimeoutSec overrides the deployment's task timeout for the agent,
+	// up to GRPC_MAX_TASK_TIMEOUT_SEC. Zero uses the deployment's.
+	TaskTimeoutSec int `json:"task_timeout_sec"`
+	// ResponseSchema is a JSON Schema the agent's structured reply must
+	// match. Empty means the agent replies in plain text.
+	ResponseSchema json.RawMessage `json:"response_schema"`
+	// DebugContext stores what each task sends to the worker with its
+	// execution, when GRPC_DEBUG_CONTEXT allows it.
+	DebugContext bool `json:"debug_context"`
+}
+
+// ToolConfig is an enabled tool as sent to the worker.
+type ToolConfig struct {
+	Name   string          `json:"name"`
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// InvalidCapabilitiesError is returned when an agent's capabilities are
+// malformed or name tools missing from the registry.
+type InvalidCapabilitiesError struct {
+	Reason string
+}
+
+func (e *InvalidCapabilitiesError) Error() string {
+	return "invalid capabilities: " + e.Reason
+}
+
+// ParseCapabilities decodes the tool settings of agent capabilities. Nil or
+// empty input yields no tools.
+func ParseCapabilities(capabilities []byte) (Capabilities, error) {
+	var c Capabilities
+	if len(capabilities) == 0 {
+		return c, nil
+	}
+	if err := json.Unmarshal(capabilities, &c); err != nil {
+		return Capabilities{}, err
+	}
+	return c, nil
+}
+
+// Validate checks that every enabled or configured tool is in the registry,
+// that the task timeout is not negative, and that the response schema
+// compiles.
+func (c Capabilities) Validate() error {
+	if c.TaskTimeoutSec < 0 {
+		return &InvalidCapabilitiesError{Reason: "task_timeout_sec must not be negative"}
+	}
+	if _, err := c.Schema(); err != nil {
+		return &InvalidCapabilitiesError{Reason: "response_schema: " + err.Error()}
+	}
+
+	var unknown []string
+	for _, name := range c.EnabledTools {
+		if _, ok := Tools[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range c.Tools {
+		if _, ok := Tools[name]; !ok && !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	slices.Sort(unknown)
+	return &InvalidCapabilitiesError{Reason: fmt.Sprintf("unknown tools %s; available tools: %s",
+		strings.Join(unknown, ", "), strings.Join(slices.Sorted(maps.Keys(Tools)), ", "))}
+}
+
+// Schema compiles the response schema. It returns nil when none is set.
+func (c Capabilities) Schema() (*jsonschema.Schema, error) {
+	if len(c.ResponseSchema) == 0 || string(c.ResponseSchema) == "null" {
+		return nil, nil
+	}
+	return jsonschema.Compile(c.ResponseSchema)
+}
+
+// EnabledToolConfigs returns the enabled tools with their configuration, in
+// the order they were listed.
+func (c Capabilities) EnabledToolConfigs() []ToolConfig {
+	tools := make([]ToolConfig, 0, len(c.EnabledTools))
+	for _, name := range c.EnabledTools {
+		tools = append(tools, ToolConfig{Name: name, Config: c.Tools[name]})
+	}
+	return tools
+}
+
+// validateCapabilities rejects capabilities that are not a JSON object or
+// that name unknown tools.
+func validateCapabilities(capabilities json.RawMessage) error {
+	c, err := ParseCapabilities(capabilities)
+	if err != nil {
+		return &InvalidCapabilitiesError{Reason: "expected an object with enabled_tools as a list of tool names"}
+	}
+	return c.Validate()
+}
@@ -0,0 +1,54 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChatFeatures(t *testing.T) {
+	assert.Equal(t, DefaultChatFeatures(), ParseChatFeatures(nil))
+	assert.Equal(t, DefaultChatFeatures(), ParseChatFeatures([]byte(`{"response_cache":{"enabled":true}}`)))
+	assert.Equal(t, DefaultChatFeatures(), ParseChatFeatures([]byte(`{"xmpp":"yes"}`)))
+
+	got := ParseChatFeatures([]byte(`{"xmpp":{"chat_states":false}}`))
+	assert.Equal(t, ChatFeatures{DeliveryReceipts: true, ChatStates: false}, got)
+}
+
+func TestParseCapabilities(t *testing.T) {
+	caps, err := ParseCapabilities(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, caps.EnabledTools)
+
+	caps, err = ParseCapabilities([]byte(`{"enabled_tools":["web_search","calculator"],"tools":{"web_search":{"max_results":5}}}`))
+	assert.NoError(t, err)
+	assert.NoError(t, caps.Validate())
+	assert.Equal(t, []ToolConfig{
+		{Name: "web_search", Config: []byte(`{"max_results":5}`)},
+		{Name: "calculator"},
+	}, caps.EnabledToolConfigs())
+
+	_, err = ParseCapabilities([]byte(`{"enabled_tools":"web_search"}`))
+	assert.Error(t, err)
+}
+
+func TestValidateCapabilities(t *testing.T) {
+	assert.NoError(t, validateCapabilities(nil))
+	assert.NoError(t, validateCapabilities([]byte(`{"xmpp":{"chat_states":false}}`)))
+
+	err := validateCapabilities([]byte(`{"enabled_tools":["web_search","shell"],"tools":{"ftp":{}}}`))
+	var invalid *InvalidCapabilitiesError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Contains(t, err.Error(), "unknown tools ftp, shell")
+	assert.Contains(t, err.Error(), "available tools: calculator, current_time")
+
+	assert.ErrorAs(t, validateCapabilities([]byte(`["web_search"]`)), &invalid)
+
+	assert.NoError(t, validateCapabilities([]byte(`{"task_timeout_sec":300}`)))
+	assert.ErrorAs(t, validateCapabilities([]byte(`{"task_timeout_sec":-1}`)), &invalid)
+
+	assert.NoError(t, validateCapabilities([]byte(`{"response_schema":{"type":"object","required":["answer"]}}`)))
+	err = validateCapabilities([]byte(`{"response_schema":{"type":"map"}}`))
+	assert.ErrorAs(t, err, &invalid)
+	assert.Contains(t, err.Error(), "response_schema: $.type")
+}
@@ -0,0 +1,151 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// chatBus stands in for NATS: it passes on published inbound messages and
+// hands outbound ones to the chat's subscription.
+type chatBus struct {
+	mu        sync.Mutex
+	published chan inats.InboundMessage
+	handler   nats.MsgHandler
+}
+
+func newChatBus() *chatBus {
+	return &chatBus{published: make(chan inats.InboundMessage, 10)}
+}
+
+func (b *chatBus) PublishInboundMessage(_ context.Context, msg inats.InboundMessage) error {
+	b.published <- msg
+	return nil
+}
+
+func (b *chatBus) Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error) {
+	if subject != inats.SubjectOutboundMessage {
+		return nil, nats.ErrBadSubject
+	}
+	b.mu.Lock()
+	b.handler = cb
+	b.mu.Unlock()
+	return &nats.Subscription{}, nil
+}
+
+func (b *chatBus) reply(t *testing.T, msg inats.OutboundMessage) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	b.mu.Lock()
+	handler := b.handler
+	b.mu.Unlock()
+	require.NotNil(t, handler)
+	handler(&nats.Msg{Subject: inats.SubjectOutboundMessage, Data: data})
+}
+
+type chatFrame struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	InReplyTo string `json:"in_reply_to"`
+	Body      string `json:"body"`
+}
+
+func readFrame(t *testing.T, ctx context.Context, ws *websocket.Conn) chatFrame {
+	t.Helper()
+	_, data, err := ws.Read(ctx)
+	require.NoError(t, err)
+	var f chatFrame
+	require.NoError(t, json.Unmarshal(data, &f))
+	return f
+}
+
+func TestAgentChat(t *testing.T) {
+	svc, _ := newPromptService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	owner, other := uuid.New(), uuid.New()
+	agent, err := svc.Create(ctx, owner, &CreateAgentRequest{Name: "helper"})
+	require.NoError(t, err)
+
+	jwtMgr := auth.NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", 15*time.Minute, time.Hour)
+	token := func(userID uuid.UUID) string {
+		pair, _, err := jwtMgr.GenerateTokenPair(userID.String(), "u@example.com", false, "")
+		require.NoError(t, err)
+		return pair.AccessToken
+	}
+
+	bus := newChatBus()
+	h := NewHandler(svc)
+	r := chi.NewRouter()
+	r.With(auth.Middleware(auth.NewService(jwtMgr, nil), nil), h.OwnershipMiddleware).
+		Handle("/agents/{agentID}/chat", api.NewChatHandler(bus, bus, ChatTarget, "example.com", nil))
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/agents/" + agent.ID.String() + "/chat"
+	dial := func(bearer string) (*websocket.Conn, *http.Response, error) {
+		opts := &websocket.DialOptions{HTTPHeader: http.Header{}}
+		if bearer != "" {
+			opts.HTTPHeader.Set("Authorization", "Bearer "+bearer)
+		}
+		return websocket.Dial(ctx, url, opts)
+	}
+
+	t.Run("requires authentication", func(t *testing.T) {
+		_, resp, err := dial("")
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("rejects another owner's agent", func(t *testing.T) {
+		_, resp, err := dial(token(other))
+		require.Error(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("routes messages to the agent and relays its reply", func(t *testing.T) {
+		ws, _, err := dial(token(owner))
+		require.NoError(t, err)
+		defer ws.Close(websocket.StatusNormalClosure, "")
+
+		require.NoError(t, ws.Write(ctx, websocket.MessageText, []byte("hello")))
+		var inbound inats.InboundMessage
+		select {
+		case inbound = <-bus.published:
+		case <-ctx.Done():
+			t.Fatal("message was not published")
+		}
+		assert.Equal(t, agent.JID, inbound.ToJID)
+		assert.Equal(t, "user-"+owner.String()+"@ws.example.com", inbound.FromJID)
+		assert.Equal(t, "hello", inbound.Body)
+		assert.Equal(t, "websocket", inbound.StanzaType)
+
+		ack := readFrame(t, ctx, ws)
+		assert.Equal(t, chatFrame{Type: "ack", ID: inbound.ID}, ack)
+
+		// Replies to other connections' messages are not relayed.
+		bus.reply(t, inats.OutboundMessage{ID: "o0", InReplyTo: uuid.NewString(), Body: "not yours"})
+		bus.reply(t, inats.OutboundMessage{ID: "o1", InReplyTo: inbound.ID, Body: "hi there"})
+		assert.Equal(t, chatFrame{Type: "message", ID: "o1", InReplyTo: inbound.ID, Body: "hi there"}, readFrame(t, ctx, ws))
+	})
+}
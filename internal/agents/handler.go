@@ -2,9 +2,12 @@ package agents
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
@@ -12,6 +15,7 @@ import (
 
 	"github.com/aiox-platform/aiox/internal/api"
 	"github.com/aiox-platform/aiox/internal/auth"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 )
 
 type Handler struct {
@@ -52,7 +56,11 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	agent, err := h.svc.Create(r.Context(), ownerID, &req)
 	if err != nil {
-		slog.Error("creating agent", "error", err)
+		if appErr := requestError(err); appErr != nil {
+			api.HandleError(w, appErr)
+			return
+		}
+		slog.ErrorContext(r.Context(), "creating agent", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -60,6 +68,9 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	api.JSON(w, http.StatusCreated, agent)
 }
 
+// List returns the caller's agents. Accepts ?q= to match a case-insensitive
+// substring of the agent name or description, ?status= (active or paused),
+// and repeated ?tag= with ?tag_match=any (the default) or all.
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	claims := auth.GetUserClaims(r.Context())
 	if claims == nil {
@@ -73,21 +84,44 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	params := DefaultListParams()
-	if p := r.URL.Query().Get("page"); p != "" {
-		if page, err := strconv.Atoi(p); err == nil && page > 0 {
-			params.Page = page
-		}
+	params := parseListParams(r)
+	params.Status = r.URL.Query().Get("status")
+	if params.Status != "" && params.Status != StatusActive && params.Status != StatusPaused {
+		api.HandleError(w, api.NewValidationError("status must be active or paused"))
+		return
 	}
-	if ps := r.URL.Query().Get("page_size"); ps != "" {
-		if pageSize, err := strconv.Atoi(ps); err == nil && pageSize > 0 && pageSize <= 100 {
-			params.PageSize = pageSize
+	for _, tag := range r.URL.Query()["tag"] {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			params.Tags = append(params.Tags, tag)
 		}
 	}
+	params.TagMatch = r.URL.Query().Get("tag_match")
+	if params.TagMatch != "" && params.TagMatch != TagMatchAny && params.TagMatch != TagMatchAll {
+		api.HandleError(w, api.NewValidationError("tag_match must be any or all"))
+		return
+	}
+
+	list := h.svc.ListByOwner
+	if params.Filtered() {
+		list = h.svc.SearchByOwner
+	}
+	agents, totalCount, err := list(r.Context(), ownerID, params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing agents", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
 
-	agents, totalCount, err := h.svc.ListByOwner(r.Context(), ownerID, params)
+	api.JSONPaginated(w, http.StatusOK, agents, totalCount, params.Page, params.PageSize)
+}
+
+// ListPublic lists public agents of all owners. Accepts ?q= to filter by name.
+func (h *Handler) ListPublic(w http.ResponseWriter, r *http.Request) {
+	params := parseListParams(r)
+
+	agents, totalCount, err := h.svc.ListPublic(r.Context(), params)
 	if err != nil {
-		slog.Error("listing agents", "error", err)
+		slog.ErrorContext(r.Context(), "listing public agents", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -98,17 +132,21 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	agent := GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
+	setETag(w, agent.Version)
 	api.JSON(w, http.StatusOK, agent)
 }
 
+// Update applies a partial update. The client must say which version it is
+// updating with If-Match (or the version field); a stale version gets 409
+// with the current version in the message and the ETag header.
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	agent := GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
@@ -123,25 +161,43 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		version, ok := parseETag(ifMatch)
+		if !ok {
+			api.HandleError(w, api.NewBadRequestError("invalid If-Match header"))
+			return
+		}
+		req.Version = &version
+	}
+
 	updated, err := h.svc.Update(r.Context(), agent, &req)
 	if err != nil {
-		slog.Error("updating agent", "error", err)
+		if appErr := versionError(w, err); appErr != nil {
+			api.HandleError(w, appErr)
+			return
+		}
+		if appErr := requestError(err); appErr != nil {
+			api.HandleError(w, appErr)
+			return
+		}
+		slog.ErrorContext(r.Context(), "updating agent", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
 
+	setETag(w, updated.Version)
 	api.JSON(w, http.StatusOK, updated)
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	agent := GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
 	if err := h.svc.Delete(r.Context(), agent.ID); err != nil {
-		slog.Error("deleting agent", "error", err)
+		slog.ErrorContext(r.Context(), "deleting agent", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -149,6 +205,128 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	api.JSONMessage(w, http.StatusOK, "agent deleted successfully")
 }
 
+// Pause stops the agent from receiving messages until it is resumed.
+func (h *Handler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, StatusPaused)
+}
+
+// Resume lets a paused agent receive messages again.
+func (h *Handler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.setStatus(w, r, StatusActive)
+}
+
+func (h *Handler) setStatus(w http.ResponseWriter, r *http.Request, status string) {
+	agent := GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	updated, err := h.svc.SetStatus(r.Context(), agent, status)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "setting agent status", "error", err, "status", status)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, updated)
+}
+
+// SetLLMCaps lets an admin override the deployment's LLM caps for any
+// agent. An empty object or null removes the override.
+func (h *Handler) SetLLMCaps(w http.ResponseWriter, r *http.Request) {
+	agentID, err := uuid.Parse(chi.URLParam(r, "agentID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid agent ID"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+	caps, err := DecodeLLMCaps(body)
+	if err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	agent, err := h.svc.GetByID(r.Context(), agentID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "fetching agent for LLM caps", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	updated, err := h.svc.SetLLMCaps(r.Context(), agent, caps)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "setting agent LLM caps", "error", err, "agent_id", agent.ID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "agent LLM caps set by admin", "agent_id", agent.ID, "admin_id", auth.GetUserClaims(r.Context()).UserID)
+	api.JSON(w, http.StatusOK, updated)
+}
+
+// ListVersions returns the agent's configuration history, newest first.
+func (h *Handler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	agent := GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	params := parseListParams(r)
+
+	versions, totalCount, err := h.svc.ListVersions(r.Context(), agent.ID, params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing agent versions", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONPaginated(w, http.StatusOK, versions, totalCount, params.Page, params.PageSize)
+}
+
+// Rollback restores the configuration from a previous version.
+func (h *Handler) Rollback(w http.ResponseWriter, r *http.Request) {
+	agent := GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	versionID, err := uuid.Parse(chi.URLParam(r, "versionID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid version ID"))
+		return
+	}
+
+	updated, err := h.svc.Rollback(r.Context(), agent, versionID)
+	if err != nil {
+		if errors.Is(err, ErrVersionNotFound) {
+			api.HandleError(w, api.NewNotFoundError(err.Error()))
+			return
+		}
+		if appErr := versionError(w, err); appErr != nil {
+			api.HandleError(w, appErr)
+			return
+		}
+		slog.ErrorContext(r.Context(), "rolling back agent", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	setETag(w, updated.Version)
+	api.JSON(w, http.StatusOK, updated)
+}
+
 // OwnershipMiddleware verifies agent ownership before allowing access.
 func (h *Handler) OwnershipMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -172,7 +350,7 @@ func (h *Handler) OwnershipMiddleware(next http.Handler) http.Handler {
 			return
 		}
 		if agent == nil {
-			api.HandleError(w, api.NewNotFoundError("agent not found"))
+			api.HandleError(w, api.ErrAgentNotFound)
 			return
 		}
 
@@ -193,3 +371,101 @@ func (h *Handler) OwnershipMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// ChatTarget binds a WebSocket chat to the caller and the agent loaded by
+// OwnershipMiddleware. It is the api.ChatTargetResolver for agent chats.
+func ChatTarget(r *http.Request) (api.ChatTarget, bool) {
+	claims := auth.GetUserClaims(r.Context())
+	agent := GetAgentFromContext(r.Context())
+	if claims == nil || agent == nil {
+		return api.ChatTarget{}, false
+	}
+	return api.ChatTarget{UserID: claims.UserID, AgentJID: agent.JID}, true
+}
+
+func parseListParams(r *http.Request) ListAgentsParams {
+	params := DefaultListParams()
+	if p := r.URL.Query().Get("page"); p != "" {
+		if page, err := strconv.Atoi(p); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if ps := r.URL.Query().Get("page_size"); ps != "" {
+		if pageSize, err := strconv.Atoi(ps); err == nil && pageSize > 0 && pageSize <= 100 {
+			params.PageSize = pageSize
+		}
+	}
+	params.Query = strings.TrimSpace(r.URL.Query().Get("q"))
+	return params
+}
+
+// requestError maps prompt template, provider allow-list, LLM config,
+// capabilities, tag, prompt length, and governance failures from the service
+// to client errors.
+func requestError(err error) *api.AppError {
+	var missing *MissingTemplateVarsError
+	if errors.As(err, &missing) {
+		return api.NewValidationError(missing.Error())
+	}
+	if errors.Is(err, ErrTemplateNotFound) {
+		return api.NewBadRequestError("system prompt template not found")
+	}
+	var provider *ProviderNotAllowedError
+	if errors.As(err, &provider) {
+		return api.NewBadRequestError(provider.Error())
+	}
+	var llm *InvalidLLMConfigError
+	if errors.As(err, &llm) {
+		return api.NewValidationError(llm.Error())
+	}
+	var caps *InvalidCapabilitiesError
+	if errors.As(err, &caps) {
+		return api.NewValidationError(caps.Error())
+	}
+	var tags *InvalidTagsError
+	if errors.As(err, &tags) {
+		return api.NewValidationError(tags.Error())
+	}
+	var tooLong *PromptTooLongError
+	if errors.As(err, &tooLong) {
+		return api.NewValidationError(tooLong.Error())
+	}
+	var gov *policy.InvalidPolicyError
+	if errors.As(err, &gov) {
+		return api.NewValidationError(gov.Error())
+	}
+	return nil
+}
+
+// versionError maps optimistic concurrency failures to client errors. On a
+// conflict it also sets the ETag header to the current version.
+func versionError(w http.ResponseWriter, err error) *api.AppError {
+	if errors.Is(err, ErrVersionRequired) {
+		return api.NewError(api.CodePreconditionRequired, err.Error())
+	}
+	var conflict *VersionConflictError
+	if errors.As(err, &conflict) {
+		setETag(w, conflict.Current)
+		return api.NewError(api.CodeVersionConflict, conflict.Error())
+	}
+	return nil
+}
+
+// setETag exposes the agent version as a strong entity tag.
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// parseETag reads an agent version from an If-Match value. Both the quoted
+// ETag form ("3") and a bare number are accepted.
+func parseETag(v string) (int, bool) {
+	v = strings.TrimSpace(v)
+	if unquoted, err := strconv.Unquote(v); err == nil {
+		v = unquoted
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
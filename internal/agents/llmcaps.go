@@ -0,0 +1,176 @@
+package agents
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LLMCaps are server-enforced limits on an agent's LLM config, applied when
+// a task is dispatched. The deployment sets them with the GOVERNANCE_* caps;
+// an admin may override them for a single agent. Unset fields are uncapped.
+type LLMCaps struct {
+	// MaxTokens caps max_tokens per request.
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// MinTemperature and MaxTemperature bound temperature.
+	MinTemperature *float64 `json:"min_temperature,omitempty"`
+	MaxTemperature *float64 `json:"max_temperature,omitempty"`
+	// AllowedModels lists the models agents may use, case-insensitively.
+	AllowedModels []string `json:"allowed_models,omitempty"`
+}
+
+// Merge returns c with the fields set in override replacing its own.
+func (c LLMCaps) Merge(override *LLMCaps) LLMCaps {
+	if override == nil {
+		return c
+	}
+	if override.MaxTokens > 0 {
+		c.MaxTokens = override.MaxTokens
+	}
+	if override.MinTemperature != nil {
+		c.MinTemperature = override.MinTemperature
+	}
+	if override.MaxTemperature != nil {
+		c.MaxTemperature = override.MaxTemperature
+	}
+	if len(override.AllowedModels) > 0 {
+		c.AllowedModels = override.AllowedModels
+	}
+	return c
+}
+
+// Clamp records a value of an agent's LLM config lowered or raised to fit
+// its caps.
+type Clamp struct {
+	Field string
+	From  string
+	To    string
+}
+
+func (c Clamp) String() string {
+	return c.Field + " " + c.From + " -> " + c.To
+}
+
+// ModelNotAllowedError is returned by Apply when the agent's model is not in
+// the allowed models.
+type ModelNotAllowedError struct {
+	Model   string
+	Allowed []string
+}
+
+func (e *ModelNotAllowedError) Error() string {
+	return fmt.Sprintf("LLM model %q is not allowed; allowed models: %s", e.Model, strings.Join(e.Allowed, ", "))
+}
+
+// Apply returns llmConfig clamped to the caps: max_tokens is lowered to
+// MaxTokens, or to maxTokensPerRequest from the agent's governance when that
+// is lower, and temperature is moved into range. Settings the config leaves
+// unset keep the worker's defaults. A model outside AllowedModels, including
+// none at all, is rejected with a *ModelNotAllowedError. Other fields are
+// kept verbatim.
+func (c LLMCaps) Apply(llmConfig json.RawMessage, maxTokensPerRequest int) (json.RawMessage, []Clamp, error) {
+	cfg := ParseLLMConfig(llmConfig)
+	if len(c.AllowedModels) > 0 && !containsFold(c.AllowedModels, cfg.Model) {
+		return nil, nil, &ModelNotAllowedError{Model: cfg.Model, Allowed: c.AllowedModels}
+	}
+
+	var clamps []Clamp
+	maxTokens := c.MaxTokens
+	if maxTokensPerRequest > 0 && (maxTokens <= 0 || maxTokensPerRequest < maxTokens) {
+		maxTokens = maxTokensPerRequest
+	}
+	if maxTokens > 0 && cfg.MaxTokens > maxTokens {
+		clamps = append(clamps, Clamp{Field: "max_tokens", From: strconv.Itoa(cfg.MaxTokens), To: strconv.Itoa(maxTokens)})
+	}
+	if t := cfg.Temperature; t != nil {
+		to := *t
+		if c.MinTemperature != nil && to < *c.MinTemperature {
+			to = *c.MinTemperature
+		}
+		if c.MaxTemperature != nil && to > *c.MaxTemperature {
+			to = *c.MaxTemperature
+		}
+		if to != *t {
+			clamps = append(clamps, Clamp{Field: "temperature", From: formatFloat(*t), To: formatFloat(to)})
+		}
+	}
+	if len(clamps) == 0 {
+		return llmConfig, nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(llmConfig, &fields); err != nil {
+		return nil, nil, fmt.Errorf("unmarshaling llm_config: %w", err)
+	}
+	for _, clamp := range clamps {
+		fields[clamp.Field] = json.RawMessage(clamp.To)
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshaling llm_config: %w", err)
+	}
+	return out, clamps, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// InvalidLLMCapsError is returned by DecodeLLMCaps for caps with unknown
+// fields or values out of range.
+type InvalidLLMCapsError struct {
+	Reason string
+}
+
+func (e *InvalidLLMCapsError) Error() string {
+	return "invalid llm_caps: " + e.Reason
+}
+
+// DecodeLLMCaps strictly parses per-agent caps submitted by an admin. Nil,
+// empty, or null input, and an empty object, yield nil: no override.
+func DecodeLLMCaps(data []byte) (*LLMCaps, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var c *LLMCaps
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, &InvalidLLMCapsError{Reason: strings.TrimPrefix(err.Error(), "json: ")}
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return nil, &InvalidLLMCapsError{Reason: "unexpected data after the llm_caps object"}
+	}
+	if c == nil || (c.MaxTokens == 0 && c.MinTemperature == nil && c.MaxTemperature == nil && len(c.AllowedModels) == 0) {
+		return nil, nil
+	}
+	if err := c.validate(); err != nil {
+		return nil, &InvalidLLMCapsError{Reason: err.Error()}
+	}
+	return c, nil
+}
+
+func (c LLMCaps) validate() error {
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative, got %d", c.MaxTokens)
+	}
+	for name, t := range map[string]*float64{"min_temperature": c.MinTemperature, "max_temperature": c.MaxTemperature} {
+		if t != nil && (*t < 0 || *t > 2) {
+			return fmt.Errorf("%s must be between 0 and 2, got %g", name, *t)
+		}
+	}
+	if c.MinTemperature != nil && c.MaxTemperature != nil && *c.MinTemperature > *c.MaxTemperature {
+		return errors.New("min_temperature must not exceed max_temperature")
+	}
+	for _, m := range c.AllowedModels {
+		if strings.TrimSpace(m) == "" {
+			return errors.New("allowed_models must not contain empty entries")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package agents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestLLMCaps_Merge(t *testing.T) {
+	global := LLMCaps{MaxTokens: 4096, MinTemperature: floatPtr(0), MaxTemperature: floatPtr(1), AllowedModels: []string{"gpt-4o"}}
+
+	assert.Equal(t, global, global.Merge(nil))
+
+	merged := global.Merge(&LLMCaps{MaxTokens: 16000, AllowedModels: []string{"gpt-4o", "o1"}})
+	assert.Equal(t, 16000, merged.MaxTokens, "an override may raise the global cap")
+	assert.Equal(t, []string{"gpt-4o", "o1"}, merged.AllowedModels)
+	assert.Equal(t, 1.0, *merged.MaxTemperature, "unset fields keep the global cap")
+}
+
+func TestLLMCaps_Apply(t *testing.T) {
+	caps := LLMCaps{MaxTokens: 1000, MinTemperature: floatPtr(0.2), MaxTemperature: floatPtr(1)}
+
+	in := json.RawMessage(`{"model":"gpt-4o","max_tokens":500,"temperature":0.7}`)
+	out, clamps, err := caps.Apply(in, 0)
+	require.NoError(t, err)
+	assert.Empty(t, clamps)
+	assert.Equal(t, string(in), string(out), "configs within the caps are passed through verbatim")
+
+	out, clamps, err = caps.Apply(json.RawMessage(`{"model":"gpt-4o","max_tokens":100000,"temperature":1.8,"extra":{"seed":7}}`), 0)
+	require.NoError(t, err)
+	require.Len(t, clamps, 2)
+	assert.Equal(t, "max_tokens 100000 -> 1000", clamps[0].String())
+	assert.Equal(t, "temperature 1.8 -> 1", clamps[1].String())
+	assert.JSONEq(t, `{"model":"gpt-4o","max_tokens":1000,"temperature":1,"extra":{"seed":7}}`, string(out))
+
+	out, clamps, err = caps.Apply(json.RawMessage(`{"temperature":0}`), 0)
+	require.NoError(t, err)
+	require.Len(t, clamps, 1)
+	assert.JSONEq(t, `{"temperature":0.2}`, string(out))
+
+	_, clamps, err = caps.Apply(json.RawMessage(`{"max_tokens":800}`), 500)
+	require.NoError(t, err)
+	require.Len(t, clamps, 1)
+	assert.Equal(t, "500", clamps[0].To, "governance max_tokens_per_request tightens the cap")
+
+	_, clamps, err = LLMCaps{}.Apply(json.RawMessage(`{"max_tokens":800}`), 500)
+	require.NoError(t, err)
+	assert.Len(t, clamps, 1, "governance applies without a global cap")
+
+	_, clamps, err = caps.Apply(nil, 0)
+	require.NoError(t, err)
+	assert.Empty(t, clamps, "unset values are left to the worker's defaults")
+}
+
+func TestLLMCaps_ApplyRejectsModel(t *testing.T) {
+	caps := LLMCaps{AllowedModels: []string{"gpt-4o-mini", "claude-3-5-haiku"}}
+
+	_, _, err := caps.Apply(json.RawMessage(`{"model":"GPT-4o-mini"}`), 0)
+	assert.NoError(t, err, "models match case-insensitively")
+
+	for _, cfg := range []string{`{"model":"gpt-4o"}`, `{}`} {
+		_, _, err = caps.Apply(json.RawMessage(cfg), 0)
+		var notAllowed *ModelNotAllowedError
+		assert.ErrorAs(t, err, &notAllowed, cfg)
+	}
+}
+
+func TestDecodeLLMCaps(t *testing.T) {
+	for _, data := range []string{``, `null`, `{}`} {
+		c, err := DecodeLLMCaps([]byte(data))
+		require.NoError(t, err, data)
+		assert.Nil(t, c, data)
+	}
+
+	c, err := DecodeLLMCaps([]byte(`{"max_tokens": 2048, "max_temperature": 0.5, "allowed_models": ["gpt-4o"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2048, c.MaxTokens)
+	assert.Equal(t, 0.5, *c.MaxTemperature)
+
+	for name, data := range map[string]string{
+		"unknown field":     `{"max_token": 10}`,
+		"negative tokens":   `{"max_tokens": -1}`,
+		"temperature range": `{"max_temperature": 3}`,
+		"inverted range":    `{"min_temperature": 1, "max_temperature": 0.5}`,
+		"empty model":       `{"allowed_models": [" "]}`,
+		"trailing data":     `{} {}`,
+	} {
+		_, err := DecodeLLMCaps([]byte(data))
+		var invalid *InvalidLLMCapsError
+		assert.ErrorAs(t, err, &invalid, name)
+	}
+}
@@ -0,0 +1,105 @@
+package agents
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LLMConfig is the typed form of an agent's llm_config:
+//
+//	"llm_config": {
+//	  "provider": "openai",
+//	  "model": "gpt-4o-mini",
+//	  "temperature": 0.7,
+//	  "top_p": 0.9,
+//	  "max_tokens": 1024,
+//	  "stop": ["\n\nUser:"],
+//	  "extra": { "presence_penalty": 0.5 }
+//	}
+//
+// The config is stored and passed to the worker as submitted; the struct
+// only validates and reads it.
+type LLMConfig struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	// Temperature and TopP are nil when unset, leaving the worker's default.
+	Temperature *float64 `json:"temperature"`
+	TopP        *float64 `json:"top_p"`
+	// MaxTokens caps the reply length; zero uses the worker's default.
+	MaxTokens int      `json:"max_tokens"`
+	Stop      []string `json:"stop"`
+	// Extra holds provider-specific options, passed to the worker as is.
+	Extra map[string]json.RawMessage `json:"extra"`
+}
+
+// InvalidLLMConfigError is returned by DecodeLLMConfig for an llm_config
+// that has unknown fields or values out of range.
+type InvalidLLMConfigError struct {
+	Reason string
+}
+
+func (e *InvalidLLMConfigError) Error() string {
+	return "invalid llm_config: " + e.Reason
+}
+
+// ParseLLMConfig parses a stored llm_config. Returns the zero config on nil,
+// empty, or invalid input; unknown fields, which configs saved before
+// validation may have, are ignored.
+func ParseLLMConfig(data []byte) LLMConfig {
+	var c LLMConfig
+	if len(data) == 0 {
+		return c
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return LLMConfig{}
+	}
+	return c
+}
+
+// DecodeLLMConfig strictly parses an llm_config submitted for an agent,
+// rejecting unknown fields and out-of-range values with an
+// *InvalidLLMConfigError. Nil, empty, or null input yields the zero config.
+func DecodeLLMConfig(data []byte) (LLMConfig, error) {
+	var c LLMConfig
+	if len(bytes.TrimSpace(data)) == 0 {
+		return c, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		reason := strings.TrimPrefix(err.Error(), "json: ")
+		if strings.HasPrefix(reason, "unknown field") {
+			reason += "; put provider-specific options under extra"
+		}
+		return LLMConfig{}, &InvalidLLMConfigError{Reason: reason}
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return LLMConfig{}, &InvalidLLMConfigError{Reason: "unexpected data after the llm_config object"}
+	}
+	if err := c.validate(); err != nil {
+		return LLMConfig{}, &InvalidLLMConfigError{Reason: err.Error()}
+	}
+	return c, nil
+}
+
+func (c LLMConfig) validate() error {
+	if t := c.Temperature; t != nil && (*t < 0 || *t > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2, got %g", *t)
+	}
+	if p := c.TopP; p != nil && (*p < 0 || *p > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1, got %g", *p)
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative, got %d", c.MaxTokens)
+	}
+	for _, s := range c.Stop {
+		if s == "" {
+			return fmt.Errorf("stop sequences must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeLLMConfig(t *testing.T) {
+	c, err := DecodeLLMConfig(nil)
+	require.NoError(t, err)
+	assert.Nil(t, c.Temperature)
+
+	c, err = DecodeLLMConfig([]byte(`{"provider": "openai", "model": "gpt-4o", "temperature": 0, "top_p": 1,
+		"max_tokens": 512, "stop": ["END"], "extra": {"presence_penalty": 0.5}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", c.Model)
+	require.NotNil(t, c.Temperature)
+	assert.Zero(t, *c.Temperature, "an explicit zero temperature is kept")
+	assert.JSONEq(t, `0.5`, string(c.Extra["presence_penalty"]))
+
+	for name, data := range map[string]string{
+		"unknown field":     `{"temprature": 0.7}`,
+		"wrong type":        `{"max_tokens": "1024"}`,
+		"temperature range": `{"temperature": 2.5}`,
+		"top_p range":       `{"top_p": -0.1}`,
+		"negative tokens":   `{"max_tokens": -1}`,
+		"empty stop":        `{"stop": [""]}`,
+		"trailing data":     `{} {}`,
+	} {
+		_, err := DecodeLLMConfig([]byte(data))
+		var invalid *InvalidLLMConfigError
+		assert.ErrorAs(t, err, &invalid, name)
+	}
+
+	_, err = DecodeLLMConfig([]byte(`{"temprature": 0.7}`))
+	assert.ErrorContains(t, err, `unknown field "temprature"`)
+	assert.ErrorContains(t, err, "extra")
+}
+
+func TestParseLLMConfig(t *testing.T) {
+	assert.Equal(t, "anthropic", ParseLLMConfig([]byte(`{"provider": "anthropic", "legacy_option": true}`)).Provider,
+		"stored configs with unknown fields still parse")
+	assert.Equal(t, LLMConfig{}, ParseLLMConfig([]byte(`not json`)))
+}
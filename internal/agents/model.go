@@ -2,24 +2,35 @@ package agents
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Agent struct {
-	ID           uuid.UUID        `json:"id"`
-	OwnerUserID  uuid.UUID        `json:"owner_user_id"`
-	JID          string           `json:"jid"`
-	Profile      AgentProfile     `json:"profile"`
-	LLMConfig    json.RawMessage  `json:"llm_config"`
-	Capabilities json.RawMessage  `json:"capabilities"`
-	MemoryConfig json.RawMessage  `json:"memory_config"`
-	Governance   json.RawMessage  `json:"governance"`
-	Visibility   string           `json:"visibility"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
-	DeletedAt    *time.Time       `json:"deleted_at,omitempty"`
+	ID           uuid.UUID       `json:"id"`
+	OwnerUserID  uuid.UUID       `json:"owner_user_id"`
+	JID          string          `json:"jid"`
+	Profile      AgentProfile    `json:"profile"`
+	LLMConfig    json.RawMessage `json:"llm_config"`
+	Capabilities json.RawMessage `json:"capabilities"`
+	MemoryConfig json.RawMessage `json:"memory_config"`
+	Governance   json.RawMessage `json:"governance"`
+	Visibility   string          `json:"visibility"`
+	// Status is StatusActive or StatusPaused.
+	Status string   `json:"status"`
+	Tags   []string `json:"tags"`
+	// Version increases on every update; send it back as If-Match (or the
+	// version field) when updating.
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// LLMCaps overrides the deployment's LLM caps for this agent. Only
+	// admins can set it.
+	LLMCaps *LLMCaps `json:"llm_caps,omitempty"`
 }
 
 type AgentProfile struct {
@@ -28,6 +39,11 @@ type AgentProfile struct {
 	SystemPrompt      string   `json:"system_prompt"`
 	PersonalityTraits []string `json:"personality_traits,omitempty"`
 	Encrypted         bool     `json:"encrypted"`
+	// SystemPromptTemplateID records the template the system prompt was rendered from, if any.
+	SystemPromptTemplateID *uuid.UUID `json:"system_prompt_template_id,omitempty"`
+	// SystemPromptRef names the agent_prompts row holding a prompt too large
+	// to store inline. Listings leave SystemPrompt empty for such agents.
+	SystemPromptRef *uuid.UUID `json:"system_prompt_ref,omitempty"`
 }
 
 // AgentRow is the database representation with JSONB fields as raw bytes.
@@ -41,33 +57,111 @@ type AgentRow struct {
 	MemoryConfig []byte
 	Governance   []byte
 	Visibility   string
+	Status       string
+	Tags         []string
+	Version      int
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    *time.Time
+	// LLMCaps is null unless an admin overrode the caps.
+	LLMCaps []byte
+	// SystemPrompt is the agent_prompts row the profile references, if any.
+	// Create and Update insert it; GetByID loads it, but listings do not.
+	SystemPrompt *StoredPrompt
+}
+
+// StoredPrompt is an encrypted system prompt kept in agent_prompts.
+type StoredPrompt struct {
+	ID      uuid.UUID
+	AgentID uuid.UUID
+	Prompt  string
+}
+
+// Agent statuses. A paused agent is not sent messages until it is resumed.
+const (
+	StatusActive = "active"
+	StatusPaused = "paused"
+)
+
+// ErrVersionNotFound is returned when a version does not exist or belongs to
+// another agent.
+var ErrVersionNotFound = errors.New("agent version not found")
+
+// ErrVersionRequired is returned when an update does not say which version of
+// the agent it was based on.
+var ErrVersionRequired = errors.New("agent version is required: send If-Match or version")
+
+// VersionConflictError is returned when an update was based on a version of
+// the agent that has since been replaced.
+type VersionConflictError struct {
+	Current int
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("agent was modified concurrently: current version is %d", e.Current)
+}
+
+// AgentVersion is a snapshot of an agent's configuration taken on every
+// create, update, and rollback.
+type AgentVersion struct {
+	ID           uuid.UUID       `json:"id"`
+	AgentID      uuid.UUID       `json:"agent_id"`
+	Version      int             `json:"version"`
+	Profile      AgentProfile    `json:"profile"`
+	LLMConfig    json.RawMessage `json:"llm_config"`
+	Capabilities json.RawMessage `json:"capabilities"`
+	MemoryConfig json.RawMessage `json:"memory_config"`
+	Governance   json.RawMessage `json:"governance"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// AgentVersionRow is the database representation of an AgentVersion.
+type AgentVersionRow struct {
+	ID           uuid.UUID
+	AgentID      uuid.UUID
+	Version      int
+	Profile      []byte
+	LLMConfig    []byte
+	Capabilities []byte
+	MemoryConfig []byte
+	Governance   []byte
+	CreatedAt    time.Time
 }
 
 type CreateAgentRequest struct {
-	Name              string          `json:"name" validate:"required,min=1,max=255"`
-	Description       string          `json:"description" validate:"max=1000"`
-	SystemPrompt      string          `json:"system_prompt" validate:"required,min=1"`
-	PersonalityTraits []string        `json:"personality_traits"`
-	LLMConfig         json.RawMessage `json:"llm_config"`
-	Capabilities      json.RawMessage `json:"capabilities"`
-	MemoryConfig      json.RawMessage `json:"memory_config"`
-	Governance        json.RawMessage `json:"governance"`
-	Visibility        string          `json:"visibility" validate:"omitempty,oneof=private public"`
+	Name              string   `json:"name" validate:"required,min=1,max=255"`
+	Description       string   `json:"description" validate:"max=1000"`
+	SystemPrompt      string   `json:"system_prompt" validate:"required_without=SystemPromptTemplateID,excluded_with=SystemPromptTemplateID"`
+	PersonalityTraits []string `json:"personality_traits"`
+	// SystemPromptTemplateID renders the system prompt from a prompt template
+	// using TemplateVars instead of taking it verbatim.
+	SystemPromptTemplateID *uuid.UUID        `json:"system_prompt_template_id"`
+	TemplateVars           map[string]string `json:"template_vars"`
+	LLMConfig              json.RawMessage   `json:"llm_config"`
+	Capabilities           json.RawMessage   `json:"capabilities"`
+	MemoryConfig           json.RawMessage   `json:"memory_config"`
+	Governance             json.RawMessage   `json:"governance"`
+	Visibility             string            `json:"visibility" validate:"omitempty,oneof=private public"`
+	Tags                   []string          `json:"tags"`
 }
 
 type UpdateAgentRequest struct {
-	Name              *string          `json:"name" validate:"omitempty,min=1,max=255"`
-	Description       *string          `json:"description" validate:"omitempty,max=1000"`
-	SystemPrompt      *string          `json:"system_prompt" validate:"omitempty,min=1"`
-	PersonalityTraits *[]string        `json:"personality_traits"`
-	LLMConfig         *json.RawMessage `json:"llm_config"`
-	Capabilities      *json.RawMessage `json:"capabilities"`
-	MemoryConfig      *json.RawMessage `json:"memory_config"`
-	Governance        *json.RawMessage `json:"governance"`
-	Visibility        *string          `json:"visibility" validate:"omitempty,oneof=private public"`
+	Name                   *string           `json:"name" validate:"omitempty,min=1,max=255"`
+	Description            *string           `json:"description" validate:"omitempty,max=1000"`
+	SystemPrompt           *string           `json:"system_prompt" validate:"omitempty,min=1,excluded_with=SystemPromptTemplateID"`
+	PersonalityTraits      *[]string         `json:"personality_traits"`
+	SystemPromptTemplateID *uuid.UUID        `json:"system_prompt_template_id"`
+	TemplateVars           map[string]string `json:"template_vars"`
+	LLMConfig              *json.RawMessage  `json:"llm_config"`
+	Capabilities           *json.RawMessage  `json:"capabilities"`
+	MemoryConfig           *json.RawMessage  `json:"memory_config"`
+	Governance             *json.RawMessage  `json:"governance"`
+	Visibility             *string           `json:"visibility" validate:"omitempty,oneof=private public"`
+	// Tags replaces all of the agent's tags.
+	Tags *[]string `json:"tags"`
+	// Version is the agent version the update is based on. The If-Match
+	// header takes precedence when both are sent.
+	Version *int `json:"version" validate:"omitempty,min=1"`
 }
 
 // ParseProfile unmarshals a raw JSONB profile byte slice into an AgentProfile.
@@ -82,6 +176,56 @@ func ParseProfile(data []byte) (*AgentProfile, error) {
 type ListAgentsParams struct {
 	Page     int
 	PageSize int
+	// Query filters agents by a case-insensitive name substring.
+	Query string
+	// Status filters agents by status; empty matches any.
+	Status string
+	// Tags filters agents by tag, matching agents with any of them, or all
+	// of them when TagMatch is TagMatchAll.
+	Tags     []string
+	TagMatch string
+}
+
+// Tag match modes for ListAgentsParams.TagMatch.
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// AgentFilter narrows the list of an owner's agents. The zero value matches
+// every agent.
+type AgentFilter struct {
+	// Query matches a case-insensitive substring of the name or description.
+	Query  string
+	Status string
+	Tags   []string
+	// AllTags requires every tag in Tags instead of any of them.
+	AllTags bool
+}
+
+// Filter returns the filter the params select.
+func (p ListAgentsParams) Filter() AgentFilter {
+	return AgentFilter{
+		Query:   p.Query,
+		Status:  p.Status,
+		Tags:    p.Tags,
+		AllTags: p.TagMatch == TagMatchAll,
+	}
+}
+
+// Filtered reports whether the params narrow the list at all.
+func (p ListAgentsParams) Filtered() bool {
+	return p.Query != "" || p.Status != "" || len(p.Tags) > 0
+}
+
+// PublicAgent is the view of a public agent shown to users other than its
+// owner. It deliberately omits the system prompt and all configuration.
+type PublicAgent struct {
+	ID                uuid.UUID `json:"id"`
+	JID               string    `json:"jid"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description"`
+	PersonalityTraits []string  `json:"personality_traits,omitempty"`
 }
 
 func DefaultListParams() ListAgentsParams {
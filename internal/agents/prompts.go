@@ -0,0 +1,106 @@
+package agents
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultMaxSystemPromptLength is the longest system prompt accepted
+	// unless SetPromptLimits says otherwise, in characters.
+	DefaultMaxSystemPromptLength = 100000
+	// DefaultSystemPromptExternalThreshold is the prompt length above which
+	// the prompt is stored in agent_prompts, in characters.
+	DefaultSystemPromptExternalThreshold = 8192
+)
+
+// PromptTooLongError is returned when a system prompt exceeds the
+// configured maximum length.
+type PromptTooLongError struct {
+	Length int
+	Max    int
+}
+
+func (e *PromptTooLongError) Error() string {
+	return fmt.Sprintf("system prompt is %d characters long, the maximum is %d", e.Length, e.Max)
+}
+
+// SetPromptLimits sets the longest system prompt agents may be given and the
+// length above which a prompt is stored outside the profile; a threshold of
+// -1 keeps every prompt inline. Lengths are in characters. It is safe to call
+// at runtime (e.g. on SIGHUP reload).
+func (s *Service) SetPromptLimits(maxLength, externalThreshold int) {
+	s.maxPromptLength.Store(int64(maxLength))
+	s.externalThreshold.Store(int64(externalThreshold))
+}
+
+// checkPromptLength rejects prompts longer than the configured maximum.
+func (s *Service) checkPromptLength(prompt string) error {
+	limit := int(s.maxPromptLength.Load())
+	if n := utf8.RuneCountInString(prompt); limit > 0 && n > limit {
+		return &PromptTooLongError{Length: n, Max: limit}
+	}
+	return nil
+}
+
+// sealPrompt encrypts prompt into profile. A prompt longer than the external
+// threshold is left out of the profile, which references the returned
+// agent_prompts row instead; the repository inserts it with the agent.
+func (s *Service) sealPrompt(agentID uuid.UUID, profile *AgentProfile, prompt string) (*StoredPrompt, error) {
+	encrypted, err := s.encryptor.Encrypt(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting system prompt: %w", err)
+	}
+	profile.Encrypted = true
+
+	threshold := int(s.externalThreshold.Load())
+	if threshold <= 0 || utf8.RuneCountInString(prompt) <= threshold {
+		profile.SystemPrompt = encrypted
+		profile.SystemPromptRef = nil
+		return nil, nil
+	}
+	stored := &StoredPrompt{ID: uuid.New(), AgentID: agentID, Prompt: encrypted}
+	profile.SystemPrompt = ""
+	profile.SystemPromptRef = &stored.ID
+	return stored, nil
+}
+
+// SnapshotPrompt returns the ID of an agent_prompts row holding the agent's
+// current system prompt, for records that must show the prompt as it was
+// later on: the agent's own stored prompt when it has one, or else a new
+// encrypted copy. Rows are never changed, so the ID keeps naming this prompt
+// after the agent is edited. An agent without a prompt yields uuid.Nil.
+func (s *Service) SnapshotPrompt(ctx context.Context, agent *Agent) (uuid.UUID, error) {
+	if ref := agent.Profile.SystemPromptRef; ref != nil {
+		return *ref, nil
+	}
+	if agent.Profile.SystemPrompt == "" {
+		return uuid.Nil, nil
+	}
+	encrypted, err := s.encryptor.Encrypt(agent.Profile.SystemPrompt)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("encrypting system prompt: %w", err)
+	}
+	stored := &StoredPrompt{ID: uuid.New(), AgentID: agent.ID, Prompt: encrypted}
+	if err := s.repo.CreatePrompt(ctx, stored); err != nil {
+		return uuid.Nil, err
+	}
+	return stored.ID, nil
+}
+
+// LoadPrompt returns the decrypted system prompt stored under id, and false
+// when there is none.
+func (s *Service) LoadPrompt(ctx context.Context, id uuid.UUID) (string, bool, error) {
+	stored, err := s.repo.GetPrompt(ctx, id)
+	if err != nil || stored == nil {
+		return "", false, err
+	}
+	prompt, err := s.encryptor.Decrypt(stored.Prompt)
+	if err != nil {
+		return "", false, fmt.Errorf("decrypting system prompt: %w", err)
+	}
+	return prompt, true, nil
+}
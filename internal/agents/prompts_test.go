@@ -0,0 +1,217 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/auth"
+)
+
+// promptRepo keeps agents and agent_prompts rows in memory. Like the
+// Postgres repository, only GetByID loads stored prompts.
+type promptRepo struct {
+	Repository
+	agents  map[uuid.UUID]AgentRow
+	prompts map[uuid.UUID]StoredPrompt
+}
+
+func newPromptRepo() *promptRepo {
+	return &promptRepo{agents: map[uuid.UUID]AgentRow{}, prompts: map[uuid.UUID]StoredPrompt{}}
+}
+
+func (r *promptRepo) save(row *AgentRow) {
+	if row.SystemPrompt != nil {
+		r.prompts[row.SystemPrompt.ID] = *row.SystemPrompt
+	}
+	stored := *row
+	stored.SystemPrompt = nil
+	r.agents[row.ID] = stored
+}
+
+func (r *promptRepo) Create(_ context.Context, row *AgentRow) error {
+	r.save(row)
+	return nil
+}
+
+func (r *promptRepo) Update(_ context.Context, row *AgentRow) error {
+	row.Version++
+	r.save(row)
+	return nil
+}
+
+func (r *promptRepo) GetByID(_ context.Context, id uuid.UUID) (*AgentRow, error) {
+	row, ok := r.agents[id]
+	if !ok {
+		return nil, nil
+	}
+	profile, err := ParseProfile(row.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if ref := profile.SystemPromptRef; ref != nil {
+		if p, ok := r.prompts[*ref]; ok {
+			row.SystemPrompt = &p
+		}
+	}
+	return &row, nil
+}
+
+func (r *promptRepo) GetPrompt(_ context.Context, id uuid.UUID) (*StoredPrompt, error) {
+	if p, ok := r.prompts[id]; ok {
+		return &p, nil
+	}
+	return nil, nil
+}
+
+func (r *promptRepo) CreatePrompt(_ context.Context, prompt *StoredPrompt) error {
+	r.prompts[prompt.ID] = *prompt
+	return nil
+}
+
+func (r *promptRepo) ListByOwner(_ context.Context, _ uuid.UUID, _, _ int) ([]*AgentRow, error) {
+	var rows []*AgentRow
+	for _, row := range r.agents {
+		rows = append(rows, &row)
+	}
+	return rows, nil
+}
+
+func (r *promptRepo) CountByOwner(_ context.Context, _ uuid.UUID) (int64, error) {
+	return int64(len(r.agents)), nil
+}
+
+func newPromptService(t *testing.T) (*Service, *promptRepo) {
+	t.Helper()
+	enc, err := auth.NewEncryptor(newTestKey)
+	require.NoError(t, err)
+	repo := newPromptRepo()
+	svc := NewService(repo, nil, enc, "example.com")
+	svc.SetPromptLimits(50, 10)
+	return svc, repo
+}
+
+func TestCreate_StoresLongPromptsSeparately(t *testing.T) {
+	svc, repo := newPromptService(t)
+	ctx := context.Background()
+	owner := uuid.New()
+	long := strings.Repeat("é", 20)
+
+	short, err := svc.Create(ctx, owner, &CreateAgentRequest{Name: "short", SystemPrompt: "be brief"})
+	require.NoError(t, err)
+	assert.Nil(t, short.Profile.SystemPromptRef)
+	assert.Equal(t, "be brief", short.Profile.SystemPrompt)
+
+	agent, err := svc.Create(ctx, owner, &CreateAgentRequest{Name: "long", SystemPrompt: long})
+	require.NoError(t, err)
+	require.NotNil(t, agent.Profile.SystemPromptRef)
+	assert.Equal(t, long, agent.Profile.SystemPrompt)
+
+	stored := repo.prompts[*agent.Profile.SystemPromptRef]
+	assert.Equal(t, agent.ID, stored.AgentID)
+	assert.NotContains(t, stored.Prompt, "é", "the stored prompt is encrypted")
+	profile, err := ParseProfile(repo.agents[agent.ID].Profile)
+	require.NoError(t, err)
+	assert.Empty(t, profile.SystemPrompt, "the profile only references the prompt")
+	assert.True(t, profile.Encrypted)
+
+	loaded, err := svc.GetByID(ctx, agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, long, loaded.Profile.SystemPrompt)
+
+	listed, _, err := svc.ListByOwner(ctx, owner, DefaultListParams())
+	require.NoError(t, err)
+	for _, a := range listed {
+		if a.ID == agent.ID {
+			assert.Empty(t, a.Profile.SystemPrompt, "listings skip stored prompts")
+		} else {
+			assert.Equal(t, "be brief", a.Profile.SystemPrompt)
+		}
+	}
+}
+
+func TestCreate_RejectsOverlongPrompt(t *testing.T) {
+	svc, _ := newPromptService(t)
+
+	_, err := svc.Create(context.Background(), uuid.New(), &CreateAgentRequest{Name: "a", SystemPrompt: strings.Repeat("x", 51)})
+	var tooLong *PromptTooLongError
+	require.ErrorAs(t, err, &tooLong)
+	assert.Equal(t, PromptTooLongError{Length: 51, Max: 50}, *tooLong)
+	assert.NotNil(t, requestError(err), "reported as a validation error")
+}
+
+func TestUpdate_KeepsStoredPrompt(t *testing.T) {
+	svc, repo := newPromptService(t)
+	ctx := context.Background()
+	long := strings.Repeat("x", 20)
+
+	created, err := svc.Create(ctx, uuid.New(), &CreateAgentRequest{Name: "long", SystemPrompt: long})
+	require.NoError(t, err)
+	agent, err := svc.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+
+	// Lowering the limit does not block edits that leave the prompt alone.
+	svc.SetPromptLimits(5, 10)
+	name, version := "renamed", agent.Version
+	updated, err := svc.Update(ctx, agent, &UpdateAgentRequest{Name: &name, Version: &version})
+	require.NoError(t, err)
+	assert.Equal(t, long, updated.Profile.SystemPrompt)
+	assert.Equal(t, agent.Profile.SystemPromptRef, updated.Profile.SystemPromptRef, "the stored prompt is reused")
+	assert.Len(t, repo.prompts, 1)
+
+	prompt := strings.Repeat("y", 6)
+	version = updated.Version
+	_, err = svc.Update(ctx, updated, &UpdateAgentRequest{SystemPrompt: &prompt, Version: &version})
+	assert.ErrorAs(t, err, new(*PromptTooLongError))
+
+	svc.SetPromptLimits(50, 10)
+	updated, err = svc.Update(ctx, updated, &UpdateAgentRequest{SystemPrompt: &prompt, Version: &version})
+	require.NoError(t, err)
+	assert.Nil(t, updated.Profile.SystemPromptRef, "a short prompt moves back inline")
+
+	version = updated.Version
+	updated, err = svc.Update(ctx, updated, &UpdateAgentRequest{Name: &name, Version: &version})
+	require.NoError(t, err)
+	reloaded, err := svc.GetByID(ctx, updated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, prompt, reloaded.Profile.SystemPrompt, "an unchanged inline prompt stays encrypted")
+}
+
+func TestSnapshotPrompt(t *testing.T) {
+	svc, repo := newPromptService(t)
+	ctx := context.Background()
+	owner := uuid.New()
+
+	long, err := svc.Create(ctx, owner, &CreateAgentRequest{Name: "long", SystemPrompt: strings.Repeat("x", 20)})
+	require.NoError(t, err)
+	id, err := svc.SnapshotPrompt(ctx, long)
+	require.NoError(t, err)
+	assert.Equal(t, *long.Profile.SystemPromptRef, id, "a stored prompt is shared")
+	assert.Len(t, repo.prompts, 1)
+
+	short, err := svc.Create(ctx, owner, &CreateAgentRequest{Name: "short", SystemPrompt: "be brief"})
+	require.NoError(t, err)
+	id, err = svc.SnapshotPrompt(ctx, short)
+	require.NoError(t, err)
+	assert.Equal(t, short.ID, repo.prompts[id].AgentID)
+	assert.NotContains(t, repo.prompts[id].Prompt, "brief", "the copy is encrypted")
+
+	prompt, ok, err := svc.LoadPrompt(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "be brief", prompt)
+
+	_, ok, err = svc.LoadPrompt(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	none, err := svc.Create(ctx, owner, &CreateAgentRequest{Name: "none"})
+	require.NoError(t, err)
+	id, err = svc.SnapshotPrompt(ctx, none)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, id)
+}
@@ -0,0 +1,49 @@
+package agents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+)
+
+// ProviderNotAllowedError is returned when an agent's LLM provider is not in
+// the platform-wide or agent governance allow-list.
+type ProviderNotAllowedError struct {
+	Provider string
+	Allowed  []string
+}
+
+func (e *ProviderNotAllowedError) Error() string {
+	return fmt.Sprintf("LLM provider %q is not allowed; allowed providers: %s", e.Provider, strings.Join(e.Allowed, ", "))
+}
+
+// validateProvider checks the provider in llmConfig against the global
+// allow-list and the allowed_providers of the agent's governance policy.
+// An empty list allows any provider, as does a config with no provider.
+func (s *Service) validateProvider(llmConfig, governance json.RawMessage) error {
+	llm := ParseLLMConfig(llmConfig)
+	if llm.Provider == "" {
+		return nil
+	}
+
+	if global := s.allowedProviders.Load(); global != nil && len(*global) > 0 && !containsFold(*global, llm.Provider) {
+		return &ProviderNotAllowedError{Provider: llm.Provider, Allowed: *global}
+	}
+
+	gov := policy.Parse(governance)
+	if len(gov.AllowedProviders) > 0 && !containsFold(gov.AllowedProviders, llm.Provider) {
+		return &ProviderNotAllowedError{Provider: llm.Provider, Allowed: gov.AllowedProviders}
+	}
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package agents
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateProvider(t *testing.T) {
+	svc := &Service{}
+	llm := json.RawMessage(`{"provider":"OpenAI","model":"gpt-4o"}`)
+
+	assert.NoError(t, svc.validateProvider(llm, nil), "no allow-list allows any provider")
+	assert.NoError(t, svc.validateProvider(nil, json.RawMessage(`{"allowed_providers":["anthropic"]}`)),
+		"a config without a provider is not checked")
+
+	err := svc.validateProvider(llm, json.RawMessage(`{"allowed_providers":["anthropic"]}`))
+	var notAllowed *ProviderNotAllowedError
+	require.ErrorAs(t, err, &notAllowed)
+	assert.Equal(t, "OpenAI", notAllowed.Provider)
+	assert.Equal(t, []string{"anthropic"}, notAllowed.Allowed)
+
+	svc.SetAllowedProviders([]string{"openai", "ollama"})
+	assert.NoError(t, svc.validateProvider(llm, json.RawMessage(`{"allowed_providers":["openai"]}`)))
+
+	err = svc.validateProvider(json.RawMessage(`{"provider":"anthropic"}`), json.RawMessage(`{"allowed_providers":["anthropic"]}`))
+	require.ErrorAs(t, err, &notAllowed, "the global list applies regardless of agent governance")
+	assert.Equal(t, []string{"openai", "ollama"}, notAllowed.Allowed)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,8 +17,58 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*AgentRow, error)
 	ListByOwner(ctx context.Context, ownerID uuid.UUID, limit, offset int) ([]*AgentRow, error)
 	CountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	// SearchByOwner lists the owner's agents that match filter.
+	SearchByOwner(ctx context.Context, ownerID uuid.UUID, filter AgentFilter, limit, offset int) ([]*AgentRow, error)
+	CountSearchByOwner(ctx context.Context, ownerID uuid.UUID, filter AgentFilter) (int64, error)
+	// ListPublic lists public agents of all owners whose name contains query
+	// (case-insensitive); an empty query matches every public agent.
+	ListPublic(ctx context.Context, query string, limit, offset int) ([]*AgentRow, error)
+	CountPublic(ctx context.Context, query string) (int64, error)
 	Update(ctx context.Context, row *AgentRow) error
 	SoftDelete(ctx context.Context, id uuid.UUID) error
+	// SetStatus changes the agent's status without writing a new version and
+	// returns the new updated_at.
+	SetStatus(ctx context.Context, id uuid.UUID, status string) (time.Time, error)
+	// SetLLMCaps replaces the agent's LLM caps override, nil clearing it,
+	// without writing a new version, and returns the new updated_at.
+	SetLLMCaps(ctx context.Context, id uuid.UUID, caps []byte) (time.Time, error)
+	// GetOwnerID returns the owner of an agent, including a deleted one, or
+	// uuid.Nil when no agent has the ID.
+	GetOwnerID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+
+	// Versions are written by Create and Update in the same transaction as the agent row.
+	ListVersions(ctx context.Context, agentID uuid.UUID, limit, offset int) ([]*AgentVersionRow, error)
+	CountVersions(ctx context.Context, agentID uuid.UUID) (int64, error)
+	GetVersion(ctx context.Context, agentID, versionID uuid.UUID) (*AgentVersionRow, error)
+
+	// GetPrompt returns a system prompt kept in agent_prompts, or nil.
+	GetPrompt(ctx context.Context, id uuid.UUID) (*StoredPrompt, error)
+	// CreatePrompt stores a system prompt in agent_prompts on its own, for
+	// records other than the agent that keep a copy of its prompt.
+	CreatePrompt(ctx context.Context, prompt *StoredPrompt) error
+
+	// Key rotation rewrites stored profiles in place, without writing a new version.
+	ListProfiles(ctx context.Context, table ProfileTable, afterID uuid.UUID, limit int) ([]ProfileRow, error)
+	// UpdateProfile replaces a profile only if it still equals old, and
+	// reports whether it did.
+	UpdateProfile(ctx context.Context, table ProfileTable, id uuid.UUID, old, profile []byte) (bool, error)
+	// ListPrompts and UpdatePrompt do the same for agent_prompts.
+	ListPrompts(ctx context.Context, afterID uuid.UUID, limit int) ([]StoredPrompt, error)
+	UpdatePrompt(ctx context.Context, id uuid.UUID, old, prompt string) (bool, error)
+}
+
+// ProfileTable names a table holding encrypted agent profiles.
+type ProfileTable string
+
+const (
+	AgentsTable        ProfileTable = "agents"
+	AgentVersionsTable ProfileTable = "agent_versions"
+)
+
+// ProfileRow is a stored profile, keyed by its row ID.
+type ProfileRow struct {
+	ID      uuid.UUID
+	Profile []byte
 }
 
 type postgresRepository struct {
@@ -29,44 +81,66 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 
 func (r *postgresRepository) Create(ctx context.Context, row *AgentRow) error {
 	query := `
-		INSERT INTO agents (id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+		INSERT INTO agents (id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, status, tags, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning agent insert: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		row.ID, row.OwnerUserID, row.JID,
 		row.Profile, row.LLMConfig, row.Capabilities,
-		row.MemoryConfig, row.Governance, row.Visibility,
+		row.MemoryConfig, row.Governance, row.Visibility, row.Status, row.Tags, row.Version,
 		row.CreatedAt, row.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("inserting agent: %w", err)
 	}
-	return nil
+	if err := insertPrompt(ctx, tx, row.SystemPrompt); err != nil {
+		return err
+	}
+	if err := insertVersion(ctx, tx, row); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
+// GetByID also loads the system prompt from agent_prompts when the profile
+// references one, as the agent is about to be used.
 func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*AgentRow, error) {
 	query := `
-		SELECT id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, created_at, updated_at, deleted_at
-		FROM agents
-		WHERE id = $1 AND deleted_at IS NULL`
+		SELECT a.id, a.owner_user_id, a.jid, a.profile, a.llm_config, a.capabilities, a.memory_config, a.governance, a.visibility, a.status, a.tags, a.version, a.created_at, a.updated_at, a.deleted_at, a.llm_caps,
+		       p.id, p.prompt
+		FROM agents a
+		LEFT JOIN agent_prompts p ON p.id = (a.profile->>'system_prompt_ref')::uuid
+		WHERE a.id = $1 AND a.deleted_at IS NULL`
 
 	row := &AgentRow{}
+	var promptID *uuid.UUID
+	var prompt *string
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&row.ID, &row.OwnerUserID, &row.JID,
 		&row.Profile, &row.LLMConfig, &row.Capabilities,
-		&row.MemoryConfig, &row.Governance, &row.Visibility,
-		&row.CreatedAt, &row.UpdatedAt, &row.DeletedAt)
+		&row.MemoryConfig, &row.Governance, &row.Visibility, &row.Status, &row.Tags, &row.Version,
+		&row.CreatedAt, &row.UpdatedAt, &row.DeletedAt, &row.LLMCaps,
+		&promptID, &prompt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("querying agent by id: %w", err)
 	}
+	if promptID != nil && prompt != nil {
+		row.SystemPrompt = &StoredPrompt{ID: *promptID, AgentID: row.ID, Prompt: *prompt}
+	}
 	return row, nil
 }
 
 func (r *postgresRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID, limit, offset int) ([]*AgentRow, error) {
 	query := `
-		SELECT id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, created_at, updated_at, deleted_at
+		SELECT id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, status, tags, version, created_at, updated_at, deleted_at, llm_caps
 		FROM agents
 		WHERE owner_user_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -84,8 +158,8 @@ func (r *postgresRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID,
 		err := rows.Scan(
 			&row.ID, &row.OwnerUserID, &row.JID,
 			&row.Profile, &row.LLMConfig, &row.Capabilities,
-			&row.MemoryConfig, &row.Governance, &row.Visibility,
-			&row.CreatedAt, &row.UpdatedAt, &row.DeletedAt)
+			&row.MemoryConfig, &row.Governance, &row.Visibility, &row.Status, &row.Tags, &row.Version,
+			&row.CreatedAt, &row.UpdatedAt, &row.DeletedAt, &row.LLMCaps)
 		if err != nil {
 			return nil, fmt.Errorf("scanning agent row: %w", err)
 		}
@@ -105,24 +179,301 @@ func (r *postgresRepository) CountByOwner(ctx context.Context, ownerID uuid.UUID
 	return count, nil
 }
 
+// SearchByOwner matches only the plaintext name and description; the
+// encrypted system prompt is never searched.
+func (r *postgresRepository) SearchByOwner(ctx context.Context, ownerID uuid.UUID, filter AgentFilter, limit, offset int) ([]*AgentRow, error) {
+	where, args := ownerFilterClause(ownerID, filter)
+	sql := fmt.Sprintf(`
+		SELECT id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, status, tags, version, created_at, updated_at, deleted_at, llm_caps
+		FROM agents
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.pool.Query(ctx, sql, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("searching agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*AgentRow
+	for rows.Next() {
+		row := &AgentRow{}
+		err := rows.Scan(
+			&row.ID, &row.OwnerUserID, &row.JID,
+			&row.Profile, &row.LLMConfig, &row.Capabilities,
+			&row.MemoryConfig, &row.Governance, &row.Visibility, &row.Status, &row.Tags, &row.Version,
+			&row.CreatedAt, &row.UpdatedAt, &row.DeletedAt, &row.LLMCaps)
+		if err != nil {
+			return nil, fmt.Errorf("scanning agent row: %w", err)
+		}
+		agents = append(agents, row)
+	}
+	return agents, rows.Err()
+}
+
+func (r *postgresRepository) CountSearchByOwner(ctx context.Context, ownerID uuid.UUID, filter AgentFilter) (int64, error) {
+	where, args := ownerFilterClause(ownerID, filter)
+	sql := `SELECT COUNT(*) FROM agents WHERE ` + where
+
+	var count int64
+	err := r.pool.QueryRow(ctx, sql, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting agent search results: %w", err)
+	}
+	return count, nil
+}
+
+func (r *postgresRepository) ListPublic(ctx context.Context, query string, limit, offset int) ([]*AgentRow, error) {
+	sql := `
+		SELECT id, owner_user_id, jid, profile, llm_config, capabilities, memory_config, governance, visibility, status, tags, version, created_at, updated_at, deleted_at, llm_caps
+		FROM agents
+		WHERE visibility = 'public' AND deleted_at IS NULL
+		  AND COALESCE(profile->>'name', '') ILIKE $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pool.Query(ctx, sql, containsPattern(query), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("listing public agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*AgentRow
+	for rows.Next() {
+		row := &AgentRow{}
+		err := rows.Scan(
+			&row.ID, &row.OwnerUserID, &row.JID,
+			&row.Profile, &row.LLMConfig, &row.Capabilities,
+			&row.MemoryConfig, &row.Governance, &row.Visibility, &row.Status, &row.Tags, &row.Version,
+			&row.CreatedAt, &row.UpdatedAt, &row.DeletedAt, &row.LLMCaps)
+		if err != nil {
+			return nil, fmt.Errorf("scanning agent row: %w", err)
+		}
+		agents = append(agents, row)
+	}
+	return agents, rows.Err()
+}
+
+func (r *postgresRepository) CountPublic(ctx context.Context, query string) (int64, error) {
+	sql := `
+		SELECT COUNT(*) FROM agents
+		WHERE visibility = 'public' AND deleted_at IS NULL
+		  AND COALESCE(profile->>'name', '') ILIKE $1`
+
+	var count int64
+	err := r.pool.QueryRow(ctx, sql, containsPattern(query)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting public agents: %w", err)
+	}
+	return count, nil
+}
+
+// ownerFilterClause builds the WHERE clause selecting the owner's agents that
+// match filter, with its arguments. Tags are matched with && (any) or @>
+// (all) so the GIN index on tags applies.
+func ownerFilterClause(ownerID uuid.UUID, filter AgentFilter) (string, []any) {
+	where := `owner_user_id = $1 AND deleted_at IS NULL
+		  AND (COALESCE(profile->>'name', '') ILIKE $2 OR COALESCE(profile->>'description', '') ILIKE $2)
+		  AND ($3 = '' OR status = $3)`
+	args := []any{ownerID, containsPattern(filter.Query), filter.Status}
+	if len(filter.Tags) > 0 {
+		op := "&&"
+		if filter.AllTags {
+			op = "@>"
+		}
+		args = append(args, filter.Tags)
+		where += fmt.Sprintf(" AND tags %s $%d", op, len(args))
+	}
+	return where, args
+}
+
+// containsPattern builds an ILIKE pattern matching values that contain s,
+// escaping LIKE wildcards so they match literally.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Update overwrites the agent only if it is still at row.Version, then bumps
+// row.Version. A stale version yields a *VersionConflictError carrying the
+// current one.
 func (r *postgresRepository) Update(ctx context.Context, row *AgentRow) error {
 	query := `
 		UPDATE agents
-		SET profile = $2, llm_config = $3, capabilities = $4, memory_config = $5, governance = $6, visibility = $7, updated_at = $8
-		WHERE id = $1 AND deleted_at IS NULL`
+		SET profile = $2, llm_config = $3, capabilities = $4, memory_config = $5, governance = $6, visibility = $7, updated_at = $8,
+		    tags = $10, version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND version = $9
+		RETURNING version`
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning agent update: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	result, err := r.pool.Exec(ctx, query,
+	var version int
+	err = tx.QueryRow(ctx, query,
 		row.ID, row.Profile, row.LLMConfig, row.Capabilities,
-		row.MemoryConfig, row.Governance, row.Visibility, row.UpdatedAt)
+		row.MemoryConfig, row.Governance, row.Visibility, row.UpdatedAt,
+		row.Version, row.Tags).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		var current int
+		err = tx.QueryRow(ctx, `SELECT version FROM agents WHERE id = $1 AND deleted_at IS NULL`, row.ID).Scan(&current)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("agent not found or already deleted")
+		}
+		if err != nil {
+			return fmt.Errorf("querying agent version: %w", err)
+		}
+		return &VersionConflictError{Current: current}
+	}
 	if err != nil {
 		return fmt.Errorf("updating agent: %w", err)
 	}
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found or already deleted")
+	if err := insertPrompt(ctx, tx, row.SystemPrompt); err != nil {
+		return err
+	}
+	if err := insertVersion(ctx, tx, row); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	row.Version = version
+	return nil
+}
+
+// insertVersion snapshots row as the agent's next version. The caller's
+// write to the agents row holds its lock, so concurrent updates are numbered
+// in commit order.
+func insertVersion(ctx context.Context, tx pgx.Tx, row *AgentRow) error {
+	query := `
+		INSERT INTO agent_versions (agent_id, version, profile, llm_config, capabilities, memory_config, governance, created_at)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5, $6, $7
+		FROM agent_versions
+		WHERE agent_id = $1`
+
+	_, err := tx.Exec(ctx, query,
+		row.ID, row.Profile, row.LLMConfig, row.Capabilities,
+		row.MemoryConfig, row.Governance, row.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting agent version: %w", err)
+	}
+	return nil
+}
+
+// insertPrompt stores a system prompt kept outside the profile. A rollback
+// passes the prompt row its version already shares, which is left as is.
+func insertPrompt(ctx context.Context, tx pgx.Tx, prompt *StoredPrompt) error {
+	if prompt == nil {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, insertPromptQuery, prompt.ID, prompt.AgentID, prompt.Prompt); err != nil {
+		return fmt.Errorf("inserting agent prompt: %w", err)
+	}
+	return nil
+}
+
+const insertPromptQuery = `
+	INSERT INTO agent_prompts (id, agent_id, prompt)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (id) DO NOTHING`
+
+func (r *postgresRepository) ListVersions(ctx context.Context, agentID uuid.UUID, limit, offset int) ([]*AgentVersionRow, error) {
+	query := `
+		SELECT id, agent_id, version, profile, llm_config, capabilities, memory_config, governance, created_at
+		FROM agent_versions
+		WHERE agent_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pool.Query(ctx, query, agentID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("listing agent versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*AgentVersionRow
+	for rows.Next() {
+		v := &AgentVersionRow{}
+		err := rows.Scan(
+			&v.ID, &v.AgentID, &v.Version,
+			&v.Profile, &v.LLMConfig, &v.Capabilities,
+			&v.MemoryConfig, &v.Governance, &v.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("scanning agent version row: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *postgresRepository) CountVersions(ctx context.Context, agentID uuid.UUID) (int64, error) {
+	query := `SELECT COUNT(*) FROM agent_versions WHERE agent_id = $1`
+
+	var count int64
+	err := r.pool.QueryRow(ctx, query, agentID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting agent versions: %w", err)
+	}
+	return count, nil
+}
+
+func (r *postgresRepository) GetVersion(ctx context.Context, agentID, versionID uuid.UUID) (*AgentVersionRow, error) {
+	query := `
+		SELECT id, agent_id, version, profile, llm_config, capabilities, memory_config, governance, created_at
+		FROM agent_versions
+		WHERE id = $1 AND agent_id = $2`
+
+	v := &AgentVersionRow{}
+	err := r.pool.QueryRow(ctx, query, versionID, agentID).Scan(
+		&v.ID, &v.AgentID, &v.Version,
+		&v.Profile, &v.LLMConfig, &v.Capabilities,
+		&v.MemoryConfig, &v.Governance, &v.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying agent version: %w", err)
+	}
+	return v, nil
+}
+
+func (r *postgresRepository) GetPrompt(ctx context.Context, id uuid.UUID) (*StoredPrompt, error) {
+	query := `SELECT id, agent_id, prompt FROM agent_prompts WHERE id = $1`
+
+	p := &StoredPrompt{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(&p.ID, &p.AgentID, &p.Prompt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying agent prompt: %w", err)
+	}
+	return p, nil
+}
+
+func (r *postgresRepository) CreatePrompt(ctx context.Context, prompt *StoredPrompt) error {
+	if _, err := r.pool.Exec(ctx, insertPromptQuery, prompt.ID, prompt.AgentID, prompt.Prompt); err != nil {
+		return fmt.Errorf("inserting agent prompt: %w", err)
 	}
 	return nil
 }
 
+func (r *postgresRepository) GetOwnerID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	var ownerID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT owner_user_id FROM agents WHERE id = $1`, id).Scan(&ownerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, nil
+		}
+		return uuid.Nil, fmt.Errorf("querying agent owner: %w", err)
+	}
+	return ownerID, nil
+}
+
 func (r *postgresRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	query := `UPDATE agents SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
@@ -135,3 +486,94 @@ func (r *postgresRepository) SoftDelete(ctx context.Context, id uuid.UUID) error
 	}
 	return nil
 }
+
+func (r *postgresRepository) SetStatus(ctx context.Context, id uuid.UUID, status string) (time.Time, error) {
+	query := `UPDATE agents SET status = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING updated_at`
+
+	var updatedAt time.Time
+	err := r.pool.QueryRow(ctx, query, id, status).Scan(&updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("agent not found or already deleted")
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("setting agent status: %w", err)
+	}
+	return updatedAt, nil
+}
+
+func (r *postgresRepository) SetLLMCaps(ctx context.Context, id uuid.UUID, caps []byte) (time.Time, error) {
+	query := `UPDATE agents SET llm_caps = $2, updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL RETURNING updated_at`
+
+	var updatedAt time.Time
+	err := r.pool.QueryRow(ctx, query, id, caps).Scan(&updatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("agent not found or already deleted")
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("setting agent LLM caps: %w", err)
+	}
+	return updatedAt, nil
+}
+
+// ListProfiles returns up to limit profiles from table with IDs after afterID,
+// in ID order. Soft-deleted agents are included.
+func (r *postgresRepository) ListProfiles(ctx context.Context, table ProfileTable, afterID uuid.UUID, limit int) ([]ProfileRow, error) {
+	query := fmt.Sprintf(`SELECT id, profile FROM %s WHERE id > $1 ORDER BY id LIMIT $2`, table)
+	rows, err := r.pool.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s profiles: %w", table, err)
+	}
+	defer rows.Close()
+
+	var profiles []ProfileRow
+	for rows.Next() {
+		var p ProfileRow
+		if err := rows.Scan(&p.ID, &p.Profile); err != nil {
+			return nil, fmt.Errorf("scanning %s profile: %w", table, err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// UpdateProfile swaps old for profile on the row, leaving it untouched if it
+// changed since it was read.
+func (r *postgresRepository) UpdateProfile(ctx context.Context, table ProfileTable, id uuid.UUID, old, profile []byte) (bool, error) {
+	query := fmt.Sprintf(`UPDATE %s SET profile = $3 WHERE id = $1 AND profile = $2::jsonb`, table)
+	tag, err := r.pool.Exec(ctx, query, id, old, profile)
+	if err != nil {
+		return false, fmt.Errorf("updating %s profile: %w", table, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListPrompts returns up to limit prompts from agent_prompts with IDs after
+// afterID, in ID order.
+func (r *postgresRepository) ListPrompts(ctx context.Context, afterID uuid.UUID, limit int) ([]StoredPrompt, error) {
+	query := `SELECT id, agent_id, prompt FROM agent_prompts WHERE id > $1 ORDER BY id LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing agent prompts: %w", err)
+	}
+	defer rows.Close()
+
+	var prompts []StoredPrompt
+	for rows.Next() {
+		var p StoredPrompt
+		if err := rows.Scan(&p.ID, &p.AgentID, &p.Prompt); err != nil {
+			return nil, fmt.Errorf("scanning agent prompt: %w", err)
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts, rows.Err()
+}
+
+// UpdatePrompt swaps old for prompt, leaving the row untouched if it changed
+// since it was read.
+func (r *postgresRepository) UpdatePrompt(ctx context.Context, id uuid.UUID, old, prompt string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `UPDATE agent_prompts SET prompt = $3 WHERE id = $1 AND prompt = $2`, id, old, prompt)
+	if err != nil {
+		return false, fmt.Errorf("updating agent prompt: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
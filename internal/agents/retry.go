@@ -0,0 +1,118 @@
+package agents
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/database"
+)
+
+// retryingRepository retries the reads of a Repository on transient Postgres
+// errors. Writes pass through untouched: a write that failed after reaching
+// the server may have been applied.
+type retryingRepository struct {
+	Repository
+	retry *database.Retrier
+}
+
+// WithRetry wraps repo so that its reads are retried by retry.
+func WithRetry(repo Repository, retry *database.Retrier) Repository {
+	return &retryingRepository{Repository: repo, retry: retry}
+}
+
+func (r *retryingRepository) GetByID(ctx context.Context, id uuid.UUID) (row *AgentRow, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		row, err = r.Repository.GetByID(ctx, id)
+		return err
+	})
+	return row, err
+}
+
+func (r *retryingRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID, limit, offset int) (rows []*AgentRow, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		rows, err = r.Repository.ListByOwner(ctx, ownerID, limit, offset)
+		return err
+	})
+	return rows, err
+}
+
+func (r *retryingRepository) CountByOwner(ctx context.Context, ownerID uuid.UUID) (n int64, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		n, err = r.Repository.CountByOwner(ctx, ownerID)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryingRepository) SearchByOwner(ctx context.Context, ownerID uuid.UUID, filter AgentFilter, limit, offset int) (rows []*AgentRow, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		rows, err = r.Repository.SearchByOwner(ctx, ownerID, filter, limit, offset)
+		return err
+	})
+	return rows, err
+}
+
+func (r *retryingRepository) CountSearchByOwner(ctx context.Context, ownerID uuid.UUID, filter AgentFilter) (n int64, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		n, err = r.Repository.CountSearchByOwner(ctx, ownerID, filter)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryingRepository) ListPublic(ctx context.Context, query string, limit, offset int) (rows []*AgentRow, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		rows, err = r.Repository.ListPublic(ctx, query, limit, offset)
+		return err
+	})
+	return rows, err
+}
+
+func (r *retryingRepository) CountPublic(ctx context.Context, query string) (n int64, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		n, err = r.Repository.CountPublic(ctx, query)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryingRepository) GetOwnerID(ctx context.Context, id uuid.UUID) (owner uuid.UUID, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		owner, err = r.Repository.GetOwnerID(ctx, id)
+		return err
+	})
+	return owner, err
+}
+
+func (r *retryingRepository) ListVersions(ctx context.Context, agentID uuid.UUID, limit, offset int) (rows []*AgentVersionRow, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		rows, err = r.Repository.ListVersions(ctx, agentID, limit, offset)
+		return err
+	})
+	return rows, err
+}
+
+func (r *retryingRepository) CountVersions(ctx context.Context, agentID uuid.UUID) (n int64, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		n, err = r.Repository.CountVersions(ctx, agentID)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryingRepository) GetVersion(ctx context.Context, agentID, versionID uuid.UUID) (row *AgentVersionRow, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		row, err = r.Repository.GetVersion(ctx, agentID, versionID)
+		return err
+	})
+	return row, err
+}
+
+func (r *retryingRepository) GetPrompt(ctx context.Context, id uuid.UUID) (p *StoredPrompt, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		p, err = r.Repository.GetPrompt(ctx, id)
+		return err
+	})
+	return p, err
+}
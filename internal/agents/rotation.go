@@ -0,0 +1,140 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// RotationResult counts the system prompts rewritten by RotateEncryption.
+type RotationResult struct {
+	Agents   int `json:"agents"`
+	Versions int `json:"versions"`
+	// Prompts counts those stored in agent_prompts.
+	Prompts int `json:"prompts"`
+}
+
+// RotateEncryption re-encrypts every stored system prompt not already sealed
+// with the primary key, in agents, their version history, and agent_prompts,
+// batchSize rows at a time. Rows changed concurrently are skipped; they were
+// written with the primary key. It is safe to re-run after a failure.
+func (s *Service) RotateEncryption(ctx context.Context, batchSize int) (RotationResult, error) {
+	var res RotationResult
+	var err error
+	if res.Agents, err = s.rotateTable(ctx, AgentsTable, batchSize); err != nil {
+		return res, err
+	}
+	if res.Versions, err = s.rotateTable(ctx, AgentVersionsTable, batchSize); err != nil {
+		return res, err
+	}
+	res.Prompts, err = s.rotatePrompts(ctx, batchSize)
+	return res, err
+}
+
+func (s *Service) rotateTable(ctx context.Context, table ProfileTable, batchSize int) (int, error) {
+	rotated := 0
+	after := uuid.Nil
+	for {
+		rows, err := s.repo.ListProfiles(ctx, table, after, batchSize)
+		if err != nil {
+			return rotated, err
+		}
+		for _, row := range rows {
+			profile, changed, err := s.reencryptProfile(row.Profile)
+			if err != nil {
+				return rotated, fmt.Errorf("%s %s: %w", table, row.ID, err)
+			}
+			if !changed {
+				continue
+			}
+			ok, err := s.repo.UpdateProfile(ctx, table, row.ID, row.Profile, profile)
+			if err != nil {
+				return rotated, err
+			}
+			if ok {
+				rotated++
+			}
+		}
+		if len(rows) < batchSize {
+			return rotated, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+func (s *Service) rotatePrompts(ctx context.Context, batchSize int) (int, error) {
+	rotated := 0
+	after := uuid.Nil
+	for {
+		rows, err := s.repo.ListPrompts(ctx, after, batchSize)
+		if err != nil {
+			return rotated, err
+		}
+		for _, row := range rows {
+			if !s.encryptor.NeedsRotation(row.Prompt) {
+				continue
+			}
+			sealed, err := s.reencrypt(row.Prompt)
+			if err != nil {
+				return rotated, fmt.Errorf("agent_prompts %s: %w", row.ID, err)
+			}
+			ok, err := s.repo.UpdatePrompt(ctx, row.ID, row.Prompt, sealed)
+			if err != nil {
+				return rotated, err
+			}
+			if ok {
+				rotated++
+			}
+		}
+		if len(rows) < batchSize {
+			return rotated, nil
+		}
+		after = rows[len(rows)-1].ID
+	}
+}
+
+// reencryptProfile returns data with its system prompt sealed under the
+// primary key. Other profile fields are kept verbatim.
+func (s *Service) reencryptProfile(data []byte) ([]byte, bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, false, fmt.Errorf("unmarshaling profile: %w", err)
+	}
+
+	var encrypted bool
+	var prompt string
+	_ = json.Unmarshal(fields["encrypted"], &encrypted)
+	_ = json.Unmarshal(fields["system_prompt"], &prompt)
+	if !encrypted || prompt == "" || !s.encryptor.NeedsRotation(prompt) {
+		return data, false, nil
+	}
+
+	sealed, err := s.reencrypt(prompt)
+	if err != nil {
+		return nil, false, err
+	}
+	if fields["system_prompt"], err = json.Marshal(sealed); err != nil {
+		return nil, false, err
+	}
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshaling profile: %w", err)
+	}
+	return out, true, nil
+}
+
+// reencrypt seals a stored system prompt again under the primary key.
+func (s *Service) reencrypt(prompt string) (string, error) {
+	plaintext, err := s.encryptor.Decrypt(prompt)
+	if err != nil {
+		return "", fmt.Errorf("decrypting system prompt: %w", err)
+	}
+	sealed, err := s.encryptor.Encrypt(plaintext)
+	if err != nil {
+		return "", fmt.Errorf("encrypting system prompt: %w", err)
+	}
+	return sealed, nil
+}
@@ -0,0 +1,128 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/auth"
+)
+
+const (
+	oldTestKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	newTestKey = "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+// profileRepo stores profiles and prompts in memory for rotation tests.
+type profileRepo struct {
+	Repository
+	profiles map[ProfileTable][]ProfileRow
+	prompts  []StoredPrompt
+}
+
+func (r *profileRepo) ListProfiles(_ context.Context, table ProfileTable, afterID uuid.UUID, limit int) ([]ProfileRow, error) {
+	var out []ProfileRow
+	for _, row := range r.profiles[table] {
+		if row.ID.String() > afterID.String() && len(out) < limit {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (r *profileRepo) UpdateProfile(_ context.Context, table ProfileTable, id uuid.UUID, old, profile []byte) (bool, error) {
+	for i, row := range r.profiles[table] {
+		if row.ID == id && string(row.Profile) == string(old) {
+			r.profiles[table][i].Profile = profile
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *profileRepo) ListPrompts(_ context.Context, afterID uuid.UUID, limit int) ([]StoredPrompt, error) {
+	var out []StoredPrompt
+	for _, p := range r.prompts {
+		if p.ID.String() > afterID.String() && len(out) < limit {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (r *profileRepo) UpdatePrompt(_ context.Context, id uuid.UUID, old, prompt string) (bool, error) {
+	for i, p := range r.prompts {
+		if p.ID == id && p.Prompt == old {
+			r.prompts[i].Prompt = prompt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRotateEncryption(t *testing.T) {
+	oldEnc, err := auth.NewEncryptor(oldTestKey)
+	require.NoError(t, err)
+
+	repo := &profileRepo{profiles: map[ProfileTable][]ProfileRow{}}
+	for i := range 5 {
+		sealed, err := oldEnc.Encrypt("prompt")
+		require.NoError(t, err)
+		profile, _ := json.Marshal(map[string]any{"name": "agent", "system_prompt": sealed, "encrypted": true, "extra": i})
+		repo.profiles[AgentsTable] = append(repo.profiles[AgentsTable], ProfileRow{ID: uuid.New(), Profile: profile})
+	}
+	plain, _ := json.Marshal(map[string]any{"name": "plain", "system_prompt": "not secret", "encrypted": false})
+	repo.profiles[AgentVersionsTable] = []ProfileRow{{ID: uuid.New(), Profile: plain}}
+	for _, rows := range repo.profiles {
+		slices.SortFunc(rows, func(a, b ProfileRow) int { return compareIDs(a.ID, b.ID) })
+	}
+	for range 3 {
+		sealed, err := oldEnc.Encrypt("long prompt")
+		require.NoError(t, err)
+		repo.prompts = append(repo.prompts, StoredPrompt{ID: uuid.New(), Prompt: sealed})
+	}
+	slices.SortFunc(repo.prompts, func(a, b StoredPrompt) int { return compareIDs(a.ID, b.ID) })
+
+	ring, err := auth.NewEncryptor(newTestKey, oldTestKey)
+	require.NoError(t, err)
+	svc := &Service{repo: repo, encryptor: ring}
+
+	res, err := svc.RotateEncryption(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, RotationResult{Agents: 5, Versions: 0, Prompts: 3}, res)
+
+	newOnly, err := auth.NewEncryptor(newTestKey)
+	require.NoError(t, err)
+	newSvc := &Service{encryptor: newOnly}
+	for _, row := range repo.profiles[AgentsTable] {
+		profile, err := newSvc.decodeProfile(row.Profile, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "prompt", profile.SystemPrompt)
+		assert.Contains(t, string(row.Profile), `"extra"`)
+	}
+	assert.JSONEq(t, string(plain), string(repo.profiles[AgentVersionsTable][0].Profile))
+	for _, p := range repo.prompts {
+		plaintext, err := newOnly.Decrypt(p.Prompt)
+		require.NoError(t, err)
+		assert.Equal(t, "long prompt", plaintext)
+	}
+
+	res, err = svc.RotateEncryption(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, RotationResult{}, res, "a second run has nothing to rotate")
+}
+
+func compareIDs(a, b uuid.UUID) int {
+	switch {
+	case a.String() < b.String():
+		return -1
+	case a.String() > b.String():
+		return 1
+	}
+	return 0
+}
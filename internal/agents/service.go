@@ -4,51 +4,90 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/aiox-platform/aiox/internal/auth"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 )
 
 type Service struct {
 	repo       Repository
+	templates  TemplateRepository
 	encryptor  *auth.Encryptor
 	xmppDomain string
+	// allowedProviders restricts LLM providers platform-wide; empty allows any.
+	allowedProviders atomic.Pointer[[]string]
+	// maxPromptLength and externalThreshold are set by SetPromptLimits.
+	maxPromptLength   atomic.Int64
+	externalThreshold atomic.Int64
 }
 
-func NewService(repo Repository, encryptionKey, xmppDomain string) *Service {
-	enc, err := auth.NewEncryptor(encryptionKey)
-	if err != nil {
-		panic(fmt.Sprintf("failed to create encryptor: %v", err))
-	}
-	return &Service{
+func NewService(repo Repository, templates TemplateRepository, enc *auth.Encryptor, xmppDomain string) *Service {
+	s := &Service{
 		repo:       repo,
+		templates:  templates,
 		encryptor:  enc,
 		xmppDomain: xmppDomain,
 	}
+	s.SetPromptLimits(DefaultMaxSystemPromptLength, DefaultSystemPromptExternalThreshold)
+	return s
+}
+
+// SetAllowedProviders restricts the LLM providers agents may be configured
+// with, in addition to any allowed_providers in their own governance policy.
+// It is safe to call at runtime (e.g. on SIGHUP reload).
+func (s *Service) SetAllowedProviders(providers []string) {
+	s.allowedProviders.Store(&providers)
 }
 
 func (s *Service) Create(ctx context.Context, ownerID uuid.UUID, req *CreateAgentRequest) (*Agent, error) {
+	if _, err := DecodeLLMConfig(req.LLMConfig); err != nil {
+		return nil, err
+	}
+	if err := s.validateProvider(req.LLMConfig, req.Governance); err != nil {
+		return nil, err
+	}
+	if err := validateCapabilities(req.Capabilities); err != nil {
+		return nil, err
+	}
+	if _, err := policy.Decode(req.Governance); err != nil {
+		return nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	agentID := uuid.New()
 	now := time.Now()
 
 	// Generate JID: agent-<uuid>@agents.<domain>
 	jid := fmt.Sprintf("agent-%s@agents.%s", agentID.String(), s.xmppDomain)
 
-	// Encrypt system prompt
-	encryptedPrompt, err := s.encryptor.Encrypt(req.SystemPrompt)
-	if err != nil {
-		return nil, fmt.Errorf("encrypting system prompt: %w", err)
+	systemPrompt := req.SystemPrompt
+	if req.SystemPromptTemplateID != nil {
+		rendered, err := s.renderTemplate(ctx, ownerID, *req.SystemPromptTemplateID, req.TemplateVars)
+		if err != nil {
+			return nil, err
+		}
+		systemPrompt = rendered
+	}
+	if err := s.checkPromptLength(systemPrompt); err != nil {
+		return nil, err
 	}
 
 	profile := AgentProfile{
-		Name:              req.Name,
-		Description:       req.Description,
-		SystemPrompt:      encryptedPrompt,
-		PersonalityTraits: req.PersonalityTraits,
-		Encrypted:         true,
+		Name:                   req.Name,
+		Description:            req.Description,
+		PersonalityTraits:      req.PersonalityTraits,
+		SystemPromptTemplateID: req.SystemPromptTemplateID,
+	}
+	storedPrompt, err := s.sealPrompt(agentID, &profile, systemPrompt)
+	if err != nil {
+		return nil, err
 	}
 
 	profileJSON, err := json.Marshal(profile)
@@ -71,8 +110,12 @@ func (s *Service) Create(ctx context.Context, ownerID uuid.UUID, req *CreateAgen
 		MemoryConfig: defaultJSON(req.MemoryConfig),
 		Governance:   defaultJSON(req.Governance),
 		Visibility:   visibility,
+		Status:       StatusActive,
+		Tags:         tags,
+		Version:      1,
 		CreatedAt:    now,
 		UpdatedAt:    now,
+		SystemPrompt: storedPrompt,
 	}
 
 	if err := s.repo.Create(ctx, row); err != nil {
@@ -118,9 +161,78 @@ func (s *Service) ListByOwner(ctx context.Context, ownerID uuid.UUID, params Lis
 	return agents, count, nil
 }
 
+// SearchByOwner returns the owner's agents that match the query, status, and
+// tag filters in params, newest first.
+func (s *Service) SearchByOwner(ctx context.Context, ownerID uuid.UUID, params ListAgentsParams) ([]*Agent, int64, error) {
+	offset := (params.Page - 1) * params.PageSize
+
+	rows, err := s.repo.SearchByOwner(ctx, ownerID, params.Filter(), params.PageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.repo.CountSearchByOwner(ctx, ownerID, params.Filter())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	agents := make([]*Agent, 0, len(rows))
+	for _, row := range rows {
+		agent, err := s.rowToAgent(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		agents = append(agents, agent)
+	}
+
+	return agents, count, nil
+}
+
+// ListPublic returns public agents of all owners, newest first. Only the
+// non-sensitive profile fields are exposed, so the system prompt is never
+// decrypted.
+func (s *Service) ListPublic(ctx context.Context, params ListAgentsParams) ([]*PublicAgent, int64, error) {
+	offset := (params.Page - 1) * params.PageSize
+
+	rows, err := s.repo.ListPublic(ctx, params.Query, params.PageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.repo.CountPublic(ctx, params.Query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	agents := make([]*PublicAgent, 0, len(rows))
+	for _, row := range rows {
+		profile, err := ParseProfile(row.Profile)
+		if err != nil {
+			return nil, 0, fmt.Errorf("unmarshaling profile: %w", err)
+		}
+		agents = append(agents, &PublicAgent{
+			ID:                row.ID,
+			JID:               row.JID,
+			Name:              profile.Name,
+			Description:       profile.Description,
+			PersonalityTraits: profile.PersonalityTraits,
+		})
+	}
+
+	return agents, count, nil
+}
+
+// Update applies req to agent. req.Version must name the version the change
+// was based on; if the agent has moved on since, a *VersionConflictError is
+// returned and nothing is written.
 func (s *Service) Update(ctx context.Context, agent *Agent, req *UpdateAgentRequest) (*Agent, error) {
-	// Parse current profile
+	if req.Version == nil {
+		return nil, ErrVersionRequired
+	}
+
+	// Parse current profile; its system prompt is decrypted
 	profile := agent.Profile
+	prompt, promptChanged := profile.SystemPrompt, false
 
 	if req.Name != nil {
 		profile.Name = *req.Name
@@ -129,17 +241,38 @@ func (s *Service) Update(ctx context.Context, agent *Agent, req *UpdateAgentRequ
 		profile.Description = *req.Description
 	}
 	if req.SystemPrompt != nil {
-		encrypted, err := s.encryptor.Encrypt(*req.SystemPrompt)
+		prompt, promptChanged = *req.SystemPrompt, true
+		profile.SystemPromptTemplateID = nil
+	}
+	if req.SystemPromptTemplateID != nil {
+		rendered, err := s.renderTemplate(ctx, agent.OwnerUserID, *req.SystemPromptTemplateID, req.TemplateVars)
 		if err != nil {
-			return nil, fmt.Errorf("encrypting system prompt: %w", err)
+			return nil, err
 		}
-		profile.SystemPrompt = encrypted
-		profile.Encrypted = true
+		prompt, promptChanged = rendered, true
+		profile.SystemPromptTemplateID = req.SystemPromptTemplateID
 	}
 	if req.PersonalityTraits != nil {
 		profile.PersonalityTraits = *req.PersonalityTraits
 	}
 
+	// An unchanged prompt in agent_prompts keeps its row; any other prompt
+	// is sealed again.
+	var storedPrompt *StoredPrompt
+	if promptChanged || profile.SystemPromptRef == nil {
+		if promptChanged {
+			if err := s.checkPromptLength(prompt); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if storedPrompt, err = s.sealPrompt(agent.ID, &profile, prompt); err != nil {
+			return nil, err
+		}
+	} else {
+		profile.SystemPrompt = ""
+	}
+
 	profileJSON, err := json.Marshal(profile)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling profile: %w", err)
@@ -153,10 +286,16 @@ func (s *Service) Update(ctx context.Context, agent *Agent, req *UpdateAgentRequ
 	llmConfig := agent.LLMConfig
 	if req.LLMConfig != nil {
 		llmConfig = *req.LLMConfig
+		if _, err := DecodeLLMConfig(llmConfig); err != nil {
+			return nil, err
+		}
 	}
 	capabilities := agent.Capabilities
 	if req.Capabilities != nil {
 		capabilities = *req.Capabilities
+		if err := validateCapabilities(capabilities); err != nil {
+			return nil, err
+		}
 	}
 	memoryConfig := agent.MemoryConfig
 	if req.MemoryConfig != nil {
@@ -165,6 +304,20 @@ func (s *Service) Update(ctx context.Context, agent *Agent, req *UpdateAgentRequ
 	governance := agent.Governance
 	if req.Governance != nil {
 		governance = *req.Governance
+		if _, err := policy.Decode(governance); err != nil {
+			return nil, err
+		}
+	}
+	if req.LLMConfig != nil || req.Governance != nil {
+		if err := s.validateProvider(llmConfig, governance); err != nil {
+			return nil, err
+		}
+	}
+	tags := agent.Tags
+	if req.Tags != nil {
+		if tags, err = normalizeTags(*req.Tags); err != nil {
+			return nil, err
+		}
 	}
 
 	row := &AgentRow{
@@ -177,39 +330,180 @@ func (s *Service) Update(ctx context.Context, agent *Agent, req *UpdateAgentRequ
 		MemoryConfig: defaultJSON(memoryConfig),
 		Governance:   defaultJSON(governance),
 		Visibility:   visibility,
+		Status:       agent.Status,
+		Tags:         tags,
+		Version:      *req.Version,
 		CreatedAt:    agent.CreatedAt,
 		UpdatedAt:    time.Now(),
+		SystemPrompt: storedPrompt,
 	}
 
 	if err := s.repo.Update(ctx, row); err != nil {
 		return nil, err
 	}
 
-	return s.rowToAgent(row)
+	updated, err := s.rowToAgent(row)
+	if err != nil {
+		return nil, err
+	}
+	updated.Profile.SystemPrompt = prompt
+	updated.LLMCaps = agent.LLMCaps
+	return updated, nil
 }
 
 func (s *Service) Delete(ctx context.Context, id uuid.UUID) error {
 	return s.repo.SoftDelete(ctx, id)
 }
 
-func (s *Service) rowToAgent(row *AgentRow) (*Agent, error) {
-	var profile AgentProfile
-	if err := json.Unmarshal(row.Profile, &profile); err != nil {
-		return nil, fmt.Errorf("unmarshaling profile: %w", err)
+// SetStatus pauses or resumes the agent. The status is not part of the
+// agent's configuration, so no version is written and If-Match is not
+// required.
+func (s *Service) SetStatus(ctx context.Context, agent *Agent, status string) (*Agent, error) {
+	updatedAt, err := s.repo.SetStatus(ctx, agent.ID, status)
+	if err != nil {
+		return nil, err
 	}
+	updated := *agent
+	updated.Status = status
+	updated.UpdatedAt = updatedAt
+	return &updated, nil
+}
 
-	// Decrypt system prompt for the response
-	if profile.Encrypted && profile.SystemPrompt != "" {
-		decrypted, err := s.encryptor.Decrypt(profile.SystemPrompt)
+// SetLLMCaps replaces the admin override of the deployment's LLM caps for
+// the agent; nil removes it. Like the status, the caps are not part of the
+// agent's configuration, so no version is written.
+func (s *Service) SetLLMCaps(ctx context.Context, agent *Agent, caps *LLMCaps) (*Agent, error) {
+	var data []byte
+	if caps != nil {
+		var err error
+		if data, err = json.Marshal(caps); err != nil {
+			return nil, fmt.Errorf("marshaling LLM caps: %w", err)
+		}
+	}
+	updatedAt, err := s.repo.SetLLMCaps(ctx, agent.ID, data)
+	if err != nil {
+		return nil, err
+	}
+	updated := *agent
+	updated.LLMCaps = caps
+	updated.UpdatedAt = updatedAt
+	return &updated, nil
+}
+
+// renderTemplate loads one of the owner's prompt templates and renders it with vars.
+func (s *Service) renderTemplate(ctx context.Context, ownerID, templateID uuid.UUID, vars map[string]string) (string, error) {
+	if s.templates == nil {
+		return "", ErrTemplateNotFound
+	}
+	tmpl, err := s.templates.GetByID(ctx, templateID)
+	if err != nil {
+		return "", err
+	}
+	if tmpl == nil || tmpl.OwnerUserID != ownerID {
+		return "", ErrTemplateNotFound
+	}
+	return tmpl.Render(vars)
+}
+
+// ListVersions returns an agent's configuration history, newest first.
+func (s *Service) ListVersions(ctx context.Context, agentID uuid.UUID, params ListAgentsParams) ([]*AgentVersion, int64, error) {
+	offset := (params.Page - 1) * params.PageSize
+
+	rows, err := s.repo.ListVersions(ctx, agentID, params.PageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.repo.CountVersions(ctx, agentID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	versions := make([]*AgentVersion, 0, len(rows))
+	for _, row := range rows {
+		profile, err := s.decodeProfile(row.Profile, nil)
 		if err != nil {
-			// If decryption fails, check if it was stored unencrypted
-			if !strings.HasPrefix(profile.SystemPrompt, "0") || len(profile.SystemPrompt) < 30 {
-				// Likely not encrypted, keep as-is
-			} else {
-				return nil, fmt.Errorf("decrypting system prompt: %w", err)
-			}
-		} else {
-			profile.SystemPrompt = decrypted
+			return nil, 0, err
+		}
+		versions = append(versions, &AgentVersion{
+			ID:           row.ID,
+			AgentID:      row.AgentID,
+			Version:      row.Version,
+			Profile:      profile,
+			LLMConfig:    row.LLMConfig,
+			Capabilities: row.Capabilities,
+			MemoryConfig: row.MemoryConfig,
+			Governance:   row.Governance,
+			CreatedAt:    row.CreatedAt,
+		})
+	}
+
+	return versions, count, nil
+}
+
+// Rollback restores a previous version's configuration. It is applied as a
+// regular update, so it is itself recorded as a new version.
+func (s *Service) Rollback(ctx context.Context, agent *Agent, versionID uuid.UUID) (*Agent, error) {
+	version, err := s.repo.GetVersion(ctx, agent.ID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return nil, ErrVersionNotFound
+	}
+
+	// A prompt in agent_prompts is shared with the version; load it for the
+	// response.
+	profile, err := ParseProfile(version.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshaling profile: %w", err)
+	}
+	var storedPrompt *StoredPrompt
+	if profile.SystemPromptRef != nil {
+		if storedPrompt, err = s.repo.GetPrompt(ctx, *profile.SystemPromptRef); err != nil {
+			return nil, err
+		}
+	}
+
+	row := &AgentRow{
+		ID:           agent.ID,
+		OwnerUserID:  agent.OwnerUserID,
+		JID:          agent.JID,
+		Profile:      version.Profile,
+		LLMConfig:    defaultJSON(version.LLMConfig),
+		Capabilities: defaultJSON(version.Capabilities),
+		MemoryConfig: defaultJSON(version.MemoryConfig),
+		Governance:   defaultJSON(version.Governance),
+		Visibility:   agent.Visibility,
+		Status:       agent.Status,
+		Tags:         agent.Tags,
+		Version:      agent.Version,
+		CreatedAt:    agent.CreatedAt,
+		UpdatedAt:    time.Now(),
+		SystemPrompt: storedPrompt,
+	}
+
+	if err := s.repo.Update(ctx, row); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.rowToAgent(row)
+	if err != nil {
+		return nil, err
+	}
+	updated.LLMCaps = agent.LLMCaps
+	return updated, nil
+}
+
+func (s *Service) rowToAgent(row *AgentRow) (*Agent, error) {
+	profile, err := s.decodeProfile(row.Profile, row.SystemPrompt)
+	if err != nil {
+		return nil, err
+	}
+	var caps *LLMCaps
+	if len(row.LLMCaps) > 0 {
+		if err := json.Unmarshal(row.LLMCaps, &caps); err != nil {
+			return nil, fmt.Errorf("unmarshaling LLM caps: %w", err)
 		}
 	}
 
@@ -223,12 +517,40 @@ func (s *Service) rowToAgent(row *AgentRow) (*Agent, error) {
 		MemoryConfig: row.MemoryConfig,
 		Governance:   row.Governance,
 		Visibility:   row.Visibility,
+		Status:       row.Status,
+		Tags:         row.Tags,
+		Version:      row.Version,
 		CreatedAt:    row.CreatedAt,
 		UpdatedAt:    row.UpdatedAt,
 		DeletedAt:    row.DeletedAt,
+		LLMCaps:      caps,
 	}, nil
 }
 
+// decodeProfile unmarshals a stored profile and decrypts its system prompt.
+// A prompt kept in agent_prompts is taken from stored; without it the
+// prompt is left empty.
+func (s *Service) decodeProfile(data []byte, stored *StoredPrompt) (AgentProfile, error) {
+	var profile AgentProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return AgentProfile{}, fmt.Errorf("unmarshaling profile: %w", err)
+	}
+	if ref := profile.SystemPromptRef; ref != nil && stored != nil && stored.ID == *ref {
+		profile.SystemPrompt = stored.Prompt
+	}
+
+	// Decrypt system prompt for the response
+	if profile.Encrypted && profile.SystemPrompt != "" {
+		decrypted, err := s.encryptor.Decrypt(profile.SystemPrompt)
+		if err != nil {
+			return AgentProfile{}, fmt.Errorf("decrypting system prompt: %w", err)
+		}
+		profile.SystemPrompt = decrypted
+	}
+
+	return profile, nil
+}
+
 func defaultJSON(data json.RawMessage) []byte {
 	if len(data) == 0 {
 		return []byte("{}")
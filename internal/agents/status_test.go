@@ -0,0 +1,39 @@
+package agents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusRepo records status changes in memory.
+type statusRepo struct {
+	Repository
+	statuses map[uuid.UUID]string
+}
+
+func (r *statusRepo) SetStatus(_ context.Context, id uuid.UUID, status string) (time.Time, error) {
+	r.statuses[id] = status
+	return time.Now(), nil
+}
+
+func TestSetStatus(t *testing.T) {
+	repo := &statusRepo{statuses: map[uuid.UUID]string{}}
+	svc := &Service{repo: repo}
+	agent := &Agent{ID: uuid.New(), Status: StatusActive, Version: 3}
+
+	paused, err := svc.SetStatus(context.Background(), agent, StatusPaused)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPaused, paused.Status)
+	assert.Equal(t, 3, paused.Version, "pausing does not write a new version")
+	assert.Equal(t, StatusActive, agent.Status, "the caller's agent is not modified")
+	assert.Equal(t, StatusPaused, repo.statuses[agent.ID])
+
+	resumed, err := svc.SetStatus(context.Background(), paused, StatusActive)
+	require.NoError(t, err)
+	assert.Equal(t, StatusActive, resumed.Status)
+}
@@ -0,0 +1,50 @@
+package agents
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxTags is the most tags an agent may have.
+	MaxTags = 20
+	// MaxTagLength is the longest a tag may be, in bytes.
+	MaxTagLength = 32
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// InvalidTagsError is returned when an agent's tags break the naming rules
+// or exceed MaxTags.
+type InvalidTagsError struct {
+	Reason string
+}
+
+func (e *InvalidTagsError) Error() string {
+	return "invalid tags: " + e.Reason
+}
+
+// normalizeTags trims and deduplicates tags, keeping their order, and checks
+// that each is lowercase letters, digits, hyphens, and underscores. The result
+// is never nil.
+func normalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if len(tag) > MaxTagLength {
+			return nil, &InvalidTagsError{Reason: fmt.Sprintf("%q is longer than %d characters", tag, MaxTagLength)}
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, &InvalidTagsError{Reason: fmt.Sprintf("%q must be lowercase letters, digits, hyphens, and underscores", tag)}
+		}
+		if !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	if len(out) > MaxTags {
+		return nil, &InvalidTagsError{Reason: fmt.Sprintf("at most %d tags are allowed, got %d", MaxTags, len(out))}
+	}
+	return out, nil
+}
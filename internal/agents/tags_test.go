@@ -0,0 +1,55 @@
+package agents
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" sales", "support", "sales", "tier_1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sales", "support", "tier_1"}, tags)
+
+	tags, err = normalizeTags(nil)
+	require.NoError(t, err)
+	assert.NotNil(t, tags, "an empty list is stored, not NULL")
+
+	for _, bad := range [][]string{
+		{"Sales"},
+		{"two words"},
+		{""},
+		{"-leading"},
+		{strings.Repeat("a", MaxTagLength+1)},
+	} {
+		_, err := normalizeTags(bad)
+		var tagErr *InvalidTagsError
+		assert.ErrorAs(t, err, &tagErr, bad)
+	}
+
+	tooMany := make([]string, MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+	_, err = normalizeTags(tooMany)
+	assert.Error(t, err)
+}
+
+func TestOwnerFilterClause(t *testing.T) {
+	ownerID := uuid.New()
+
+	where, args := ownerFilterClause(ownerID, AgentFilter{})
+	assert.NotContains(t, where, "tags")
+	assert.Len(t, args, 3)
+
+	where, args = ownerFilterClause(ownerID, AgentFilter{Tags: []string{"sales", "support"}})
+	assert.Contains(t, where, "tags && $4")
+	assert.Equal(t, []string{"sales", "support"}, args[3])
+
+	where, _ = ownerFilterClause(ownerID, AgentFilter{Tags: []string{"sales"}, AllTags: true})
+	assert.Contains(t, where, "tags @> $4")
+}
@@ -0,0 +1,95 @@
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrTemplateNotFound is returned when a referenced prompt template does not
+// exist or belongs to another user.
+var ErrTemplateNotFound = errors.New("prompt template not found")
+
+// PromptTemplate is a reusable system prompt owned by a user.
+type PromptTemplate struct {
+	ID          uuid.UUID          `json:"id"`
+	OwnerUserID uuid.UUID          `json:"owner_user_id"`
+	Name        string             `json:"name"`
+	Body        string             `json:"body"`
+	Variables   []TemplateVariable `json:"variables"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// TemplateVariable declares a {{name}} placeholder used in a template body.
+// Required variables must always be supplied; optional ones fall back to Default.
+type TemplateVariable struct {
+	Name     string `json:"name" validate:"required,max=64"`
+	Required bool   `json:"required"`
+	Default  string `json:"default,omitempty"`
+}
+
+type CreatePromptTemplateRequest struct {
+	Name      string             `json:"name" validate:"required,min=1,max=255"`
+	Body      string             `json:"body" validate:"required,min=1"`
+	Variables []TemplateVariable `json:"variables" validate:"dive"`
+}
+
+type UpdatePromptTemplateRequest struct {
+	Name      *string             `json:"name" validate:"omitempty,min=1,max=255"`
+	Body      *string             `json:"body" validate:"omitempty,min=1"`
+	Variables *[]TemplateVariable `json:"variables" validate:"omitempty,dive"`
+}
+
+// MissingTemplateVarsError lists required template variables that were not supplied.
+type MissingTemplateVarsError struct {
+	Missing []string
+}
+
+func (e *MissingTemplateVarsError) Error() string {
+	return fmt.Sprintf("missing template variables: %s", strings.Join(e.Missing, ", "))
+}
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// Render substitutes {{var}} placeholders in the template body. A placeholder
+// with no supplied value uses its declared default; anything left unresolved,
+// along with any declared required variable that was not supplied, is reported
+// in a *MissingTemplateVarsError.
+func (t *PromptTemplate) Render(vars map[string]string) (string, error) {
+	declared := make(map[string]TemplateVariable, len(t.Variables))
+	missing := make(map[string]struct{})
+	for _, v := range t.Variables {
+		declared[v.Name] = v
+		if _, ok := vars[v.Name]; v.Required && !ok {
+			missing[v.Name] = struct{}{}
+		}
+	}
+
+	rendered := templatePlaceholder.ReplaceAllStringFunc(t.Body, func(match string) string {
+		name := templatePlaceholder.FindStringSubmatch(match)[1]
+		if val, ok := vars[name]; ok {
+			return val
+		}
+		if v, ok := declared[name]; ok && !v.Required {
+			return v.Default
+		}
+		missing[name] = struct{}{}
+		return match
+	})
+
+	if len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", &MissingTemplateVarsError{Missing: names}
+	}
+	return rendered, nil
+}
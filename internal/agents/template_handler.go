@@ -0,0 +1,178 @@
+package agents
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+)
+
+// TemplateHandler handles prompt template HTTP endpoints.
+type TemplateHandler struct {
+	svc      *TemplateService
+	validate *validator.Validate
+}
+
+// NewTemplateHandler creates a new prompt template handler.
+func NewTemplateHandler(svc *TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		svc:      svc,
+		validate: validator.New(),
+	}
+}
+
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreatePromptTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	tmpl, err := h.svc.Create(r.Context(), ownerID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "creating prompt template", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, tmpl)
+}
+
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	params := DefaultListParams()
+	if p := r.URL.Query().Get("page"); p != "" {
+		if page, err := strconv.Atoi(p); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if ps := r.URL.Query().Get("page_size"); ps != "" {
+		if pageSize, err := strconv.Atoi(ps); err == nil && pageSize > 0 && pageSize <= 100 {
+			params.PageSize = pageSize
+		}
+	}
+
+	templates, totalCount, err := h.svc.ListByOwner(r.Context(), ownerID, params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing prompt templates", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONPaginated(w, http.StatusOK, templates, totalCount, params.Page, params.PageSize)
+}
+
+func (h *TemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tmpl, ok := h.templateFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	api.JSON(w, http.StatusOK, tmpl)
+}
+
+func (h *TemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	tmpl, ok := h.templateFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdatePromptTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	updated, err := h.svc.Update(r.Context(), tmpl, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "updating prompt template", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, updated)
+}
+
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	tmpl, ok := h.templateFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), tmpl.ID); err != nil {
+		slog.ErrorContext(r.Context(), "deleting prompt template", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "prompt template deleted successfully")
+}
+
+// templateFromRequest loads the {templateID} URL param, scoped to the caller.
+// Templates owned by other users are reported as not found.
+func (h *TemplateHandler) templateFromRequest(w http.ResponseWriter, r *http.Request) (*PromptTemplate, bool) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	templateID, err := uuid.Parse(chi.URLParam(r, "templateID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid template ID"))
+		return nil, false
+	}
+
+	tmpl, err := h.svc.GetForOwner(r.Context(), templateID, ownerID)
+	if err != nil {
+		if errors.Is(err, ErrTemplateNotFound) {
+			api.HandleError(w, api.NewNotFoundError("prompt template not found"))
+			return nil, false
+		}
+		slog.ErrorContext(r.Context(), "fetching prompt template", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return nil, false
+	}
+	return tmpl, true
+}
+
+func ownerFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+
+	ownerID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+	return ownerID, true
+}
@@ -0,0 +1,154 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TemplateRepository interface {
+	Create(ctx context.Context, tmpl *PromptTemplate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*PromptTemplate, error)
+	ListByOwner(ctx context.Context, ownerID uuid.UUID, limit, offset int) ([]*PromptTemplate, error)
+	CountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	Update(ctx context.Context, tmpl *PromptTemplate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type postgresTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTemplateRepository(pool *pgxpool.Pool) TemplateRepository {
+	return &postgresTemplateRepository{pool: pool}
+}
+
+func (r *postgresTemplateRepository) Create(ctx context.Context, tmpl *PromptTemplate) error {
+	variables, err := marshalVariables(tmpl.Variables)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO prompt_templates (id, owner_user_id, name, body, variables, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err = r.pool.Exec(ctx, query,
+		tmpl.ID, tmpl.OwnerUserID, tmpl.Name, tmpl.Body, variables,
+		tmpl.CreatedAt, tmpl.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting prompt template: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*PromptTemplate, error) {
+	query := `
+		SELECT id, owner_user_id, name, body, variables, created_at, updated_at
+		FROM prompt_templates
+		WHERE id = $1`
+
+	tmpl, err := scanTemplate(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying prompt template by id: %w", err)
+	}
+	return tmpl, nil
+}
+
+func (r *postgresTemplateRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID, limit, offset int) ([]*PromptTemplate, error) {
+	query := `
+		SELECT id, owner_user_id, name, body, variables, created_at, updated_at
+		FROM prompt_templates
+		WHERE owner_user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.pool.Query(ctx, query, ownerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("listing prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*PromptTemplate
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning prompt template row: %w", err)
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, rows.Err()
+}
+
+func (r *postgresTemplateRepository) CountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	query := `SELECT COUNT(*) FROM prompt_templates WHERE owner_user_id = $1`
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, query, ownerID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting prompt templates: %w", err)
+	}
+	return count, nil
+}
+
+func (r *postgresTemplateRepository) Update(ctx context.Context, tmpl *PromptTemplate) error {
+	variables, err := marshalVariables(tmpl.Variables)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE prompt_templates
+		SET name = $2, body = $3, variables = $4, updated_at = $5
+		WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, tmpl.ID, tmpl.Name, tmpl.Body, variables, tmpl.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("updating prompt template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+func (r *postgresTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM prompt_templates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting prompt template: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
+
+func scanTemplate(row pgx.Row) (*PromptTemplate, error) {
+	tmpl := &PromptTemplate{}
+	var variables []byte
+	if err := row.Scan(&tmpl.ID, &tmpl.OwnerUserID, &tmpl.Name, &tmpl.Body, &variables, &tmpl.CreatedAt, &tmpl.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(variables, &tmpl.Variables); err != nil {
+		return nil, fmt.Errorf("unmarshaling template variables: %w", err)
+	}
+	return tmpl, nil
+}
+
+func marshalVariables(vars []TemplateVariable) ([]byte, error) {
+	if vars == nil {
+		vars = []TemplateVariable{}
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling template variables: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,91 @@
+package agents
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TemplateService struct {
+	repo TemplateRepository
+}
+
+func NewTemplateService(repo TemplateRepository) *TemplateService {
+	return &TemplateService{repo: repo}
+}
+
+func (s *TemplateService) Create(ctx context.Context, ownerID uuid.UUID, req *CreatePromptTemplateRequest) (*PromptTemplate, error) {
+	now := time.Now()
+	tmpl := &PromptTemplate{
+		ID:          uuid.New(),
+		OwnerUserID: ownerID,
+		Name:        req.Name,
+		Body:        req.Body,
+		Variables:   req.Variables,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if tmpl.Variables == nil {
+		tmpl.Variables = []TemplateVariable{}
+	}
+
+	if err := s.repo.Create(ctx, tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// GetForOwner returns the template only if it belongs to ownerID.
+func (s *TemplateService) GetForOwner(ctx context.Context, id, ownerID uuid.UUID) (*PromptTemplate, error) {
+	tmpl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil || tmpl.OwnerUserID != ownerID {
+		return nil, ErrTemplateNotFound
+	}
+	return tmpl, nil
+}
+
+func (s *TemplateService) ListByOwner(ctx context.Context, ownerID uuid.UUID, params ListAgentsParams) ([]*PromptTemplate, int64, error) {
+	offset := (params.Page - 1) * params.PageSize
+
+	templates, err := s.repo.ListByOwner(ctx, ownerID, params.PageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.repo.CountByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if templates == nil {
+		templates = []*PromptTemplate{}
+	}
+	return templates, count, nil
+}
+
+func (s *TemplateService) Update(ctx context.Context, tmpl *PromptTemplate, req *UpdatePromptTemplateRequest) (*PromptTemplate, error) {
+	updated := *tmpl
+	if req.Name != nil {
+		updated.Name = *req.Name
+	}
+	if req.Body != nil {
+		updated.Body = *req.Body
+	}
+	if req.Variables != nil {
+		updated.Variables = *req.Variables
+	}
+	updated.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (s *TemplateService) Delete(ctx context.Context, id uuid.UUID) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,36 @@
+package agents
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptTemplate_Render(t *testing.T) {
+	tmpl := &PromptTemplate{
+		Body: "You are {{ role }} at {{company}}. Tone: {{tone}}.",
+		Variables: []TemplateVariable{
+			{Name: "role", Required: true},
+			{Name: "company", Required: true},
+			{Name: "tone", Default: "friendly"},
+		},
+	}
+
+	out, err := tmpl.Render(map[string]string{"role": "a support agent", "company": "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "You are a support agent at Acme. Tone: friendly.", out)
+}
+
+func TestPromptTemplate_RenderMissingVars(t *testing.T) {
+	tmpl := &PromptTemplate{
+		Body:      "Hello {{name}}, welcome to {{place}}.",
+		Variables: []TemplateVariable{{Name: "name", Required: true}, {Name: "lang", Required: true}},
+	}
+
+	_, err := tmpl.Render(map[string]string{})
+	var missing *MissingTemplateVarsError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, []string{"lang", "name", "place"}, missing.Missing)
+	assert.Equal(t, "missing template variables: lang, name, place", err.Error())
+}
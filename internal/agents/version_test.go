@@ -0,0 +1,54 @@
+package agents
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseETag(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{`"3"`, 3, true},
+		{`7`, 7, true},
+		{` "12" `, 12, true},
+		{`"0"`, 0, false},
+		{`W/"3"`, 0, false},
+		{`*`, 0, false},
+		{`"abc"`, 0, false},
+	} {
+		got, ok := parseETag(tc.in)
+		assert.Equal(t, tc.ok, ok, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+}
+
+func TestUpdate_RequiresVersion(t *testing.T) {
+	svc := &Service{}
+	name := "renamed"
+
+	_, err := svc.Update(context.Background(), &Agent{Version: 2}, &UpdateAgentRequest{Name: &name})
+	assert.ErrorIs(t, err, ErrVersionRequired)
+}
+
+func TestVersionError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	appErr := versionError(rec, &VersionConflictError{Current: 5})
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusConflict, appErr.Code)
+	assert.Contains(t, appErr.Message, "current version is 5")
+	assert.Equal(t, `"5"`, rec.Header().Get("ETag"))
+
+	appErr = versionError(httptest.NewRecorder(), ErrVersionRequired)
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusPreconditionRequired, appErr.Code)
+
+	assert.Nil(t, versionError(httptest.NewRecorder(), assert.AnError))
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"nhooyr.io/websocket"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+const (
+	chatPingInterval   = 30 * time.Second
+	chatPingTimeout    = 10 * time.Second
+	chatPublishTimeout = 5 * time.Second
+)
+
+// ChatTarget identifies the authenticated user and the agent a WebSocket chat is bound to.
+type ChatTarget struct {
+	UserID   string
+	AgentJID string
+}
+
+// ChatTargetResolver extracts the ChatTarget from a request that has already passed
+// the auth and ownership middleware. It is injected from main.go to avoid import cycles.
+type ChatTargetResolver func(r *http.Request) (ChatTarget, bool)
+
+// chatEvent is the JSON frame written back to WebSocket clients.
+type chatEvent struct {
+	Type      string `json:"type"` // "ack" or "message"
+	ID        string `json:"id"`
+	InReplyTo string `json:"in_reply_to,omitempty"`
+	Body      string `json:"body,omitempty"`
+	FromCache bool   `json:"from_cache,omitempty"`
+}
+
+// InboundPublisher publishes the messages chat clients send.
+type InboundPublisher interface {
+	PublishInboundMessage(ctx context.Context, msg inats.InboundMessage) error
+}
+
+// OutboundSubscriber delivers agent replies; *nats.Conn satisfies it.
+type OutboundSubscriber interface {
+	Subscribe(subject string, cb nats.MsgHandler) (*nats.Subscription, error)
+}
+
+// ChatHandler bridges WebSocket clients to the NATS inbound/outbound message flow.
+type ChatHandler struct {
+	publisher      InboundPublisher
+	conn           OutboundSubscriber
+	resolve        ChatTargetResolver
+	jidDomain      string
+	allowedOrigins []string
+}
+
+// NewChatHandler creates a new WebSocket chat handler. Synthesized user JIDs
+// take the form user-<uuid>@ws.<xmppDomain>.
+func NewChatHandler(publisher InboundPublisher, conn OutboundSubscriber, resolve ChatTargetResolver, xmppDomain string, allowedOrigins []string) *ChatHandler {
+	return &ChatHandler{
+		publisher:      publisher,
+		conn:           conn,
+		resolve:        resolve,
+		jidDomain:      "ws." + xmppDomain,
+		allowedOrigins: allowedOrigins,
+	}
+}
+
+// ServeHTTP upgrades the request to a WebSocket and relays frames until the client disconnects.
+func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	target, ok := h.resolve(r)
+	if !ok {
+		HandleError(w, ErrUnauthorized)
+		return
+	}
+
+	// The server's read/write timeouts would otherwise carry over to the hijacked connection.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	ws, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		InsecureSkipVerify: h.originAllowed(r.Header.Get("Origin")),
+	})
+	if err != nil {
+		slog.WarnContext(r.Context(), "chat: websocket upgrade failed", "error", err)
+		return
+	}
+	defer ws.Close(websocket.StatusInternalError, "unexpected close")
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	fromJID := fmt.Sprintf("user-%s@%s", target.UserID, h.jidDomain)
+
+	// Request IDs published by this connection; outbound replies are matched on InReplyTo.
+	var mu sync.Mutex
+	pending := make(map[string]struct{})
+
+	sub, err := h.conn.Subscribe(inats.SubjectOutboundMessage, func(m *nats.Msg) {
+		var outbound inats.OutboundMessage
+		// Chat states and receipts carry no body and are XMPP-only
+		if err := inats.Decode(m.Header, m.Data, &outbound); err != nil || outbound.Body == "" {
+			return
+		}
+
+		mu.Lock()
+		_, mine := pending[outbound.InReplyTo]
+		delete(pending, outbound.InReplyTo)
+		mu.Unlock()
+		if !mine {
+			return
+		}
+
+		h.writeEvent(ctx, ws, chatEvent{
+			Type:      "message",
+			ID:        outbound.ID,
+			InReplyTo: outbound.InReplyTo,
+			Body:      outbound.Body,
+			FromCache: outbound.FromCache,
+		})
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "chat: subscribing to outbound messages", "error", err)
+		ws.Close(websocket.StatusInternalError, "subscription failed")
+		return
+	}
+	defer func() {
+		// A closed connection has already dropped the subscription
+		if err := sub.Unsubscribe(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+			slog.WarnContext(r.Context(), "chat: unsubscribing outbound messages", "error", err)
+		}
+	}()
+
+	go h.keepalive(ctx, cancel, ws)
+
+	slog.DebugContext(r.Context(), "chat: websocket connected", "user_id", target.UserID, "agent_jid", target.AgentJID)
+
+	for {
+		typ, data, err := ws.Read(ctx)
+		if err != nil {
+			slog.DebugContext(r.Context(), "chat: websocket closed", "user_id", target.UserID, "error", err)
+			return
+		}
+		if typ != websocket.MessageText || len(data) == 0 {
+			continue
+		}
+
+		inbound := inats.InboundMessage{
+			ID:         uuid.New().String(),
+			FromJID:    fromJID,
+			ToJID:      target.AgentJID,
+			Body:       string(data),
+			StanzaType: "websocket",
+			ReceivedAt: time.Now().UTC(),
+		}
+
+		mu.Lock()
+		pending[inbound.ID] = struct{}{}
+		mu.Unlock()
+
+		pubCtx, pubCancel := context.WithTimeout(ctx, chatPublishTimeout)
+		err = h.publisher.PublishInboundMessage(pubCtx, inbound)
+		pubCancel()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "chat: publishing inbound message", "error", err, "user_id", target.UserID)
+			mu.Lock()
+			delete(pending, inbound.ID)
+			mu.Unlock()
+			ws.Close(websocket.StatusInternalError, "failed to publish message")
+			return
+		}
+
+		h.writeEvent(ctx, ws, chatEvent{Type: "ack", ID: inbound.ID})
+	}
+}
+
+// keepalive pings the client periodically and cancels the connection context when a pong is missed.
+func (h *ChatHandler) keepalive(ctx context.Context, cancel context.CancelFunc, ws *websocket.Conn) {
+	ticker := time.NewTicker(chatPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pingCtx, pingCancel := context.WithTimeout(ctx, chatPingTimeout)
+			err := ws.Ping(pingCtx)
+			pingCancel()
+			if err != nil {
+				slog.Debug("chat: ping failed, closing connection", "error", err)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (h *ChatHandler) writeEvent(ctx context.Context, ws *websocket.Conn, event chatEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if err := ws.Write(ctx, websocket.MessageText, data); err != nil {
+		slog.Debug("chat: writing websocket frame", "error", err)
+	}
+}
+
+// originAllowed reports whether a cross-origin upgrade should be accepted.
+// Same-origin requests are always verified by the websocket library itself.
+func (h *ChatHandler) originAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, o := range h.allowedOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -3,11 +3,71 @@ package api
 import (
 	"errors"
 	"net/http"
+
+	"github.com/aiox-platform/aiox/internal/database"
 )
 
+// ErrorCode is a stable, machine-readable identifier returned in the "code"
+// field of error responses. Clients should branch on it rather than on the
+// human-readable message.
+type ErrorCode string
+
+const (
+	CodeBadRequest           ErrorCode = "BAD_REQUEST"
+	CodeValidationFailed     ErrorCode = "VALIDATION_FAILED"
+	CodeWeakPassword         ErrorCode = "WEAK_PASSWORD"
+	CodeUnauthorized         ErrorCode = "UNAUTHORIZED"
+	CodeInvalidCredentials   ErrorCode = "INVALID_CREDENTIALS"
+	CodeInvalidToken         ErrorCode = "INVALID_TOKEN"
+	CodeInvalidMFACode       ErrorCode = "INVALID_MFA_CODE"
+	CodeForbidden            ErrorCode = "FORBIDDEN"
+	CodeOwnershipViolation   ErrorCode = "OWNERSHIP_VIOLATION"
+	CodeNotFound             ErrorCode = "NOT_FOUND"
+	CodeAgentNotFound        ErrorCode = "AGENT_NOT_FOUND"
+	CodeConflict             ErrorCode = "CONFLICT"
+	CodeEmailAlreadyExists   ErrorCode = "EMAIL_ALREADY_EXISTS"
+	CodeVersionConflict      ErrorCode = "VERSION_CONFLICT"
+	CodeAccountLocked        ErrorCode = "ACCOUNT_LOCKED"
+	CodePreconditionRequired ErrorCode = "PRECONDITION_REQUIRED"
+	CodeQuotaExceeded        ErrorCode = "QUOTA_EXCEEDED"
+	CodeInternal             ErrorCode = "INTERNAL_ERROR"
+	CodeAgentError           ErrorCode = "AGENT_ERROR"
+	CodeInvokeTimeout        ErrorCode = "INVOKE_TIMEOUT"
+	CodeServiceUnavailable   ErrorCode = "SERVICE_UNAVAILABLE"
+)
+
+// ErrorCodes is the registry of every code the API returns, with the HTTP
+// status it is sent with. A code not listed here must not reach clients.
+var ErrorCodes = map[ErrorCode]int{
+	CodeBadRequest:           http.StatusBadRequest,
+	CodeValidationFailed:     http.StatusBadRequest,
+	CodeWeakPassword:         http.StatusBadRequest,
+	CodeUnauthorized:         http.StatusUnauthorized,
+	CodeInvalidCredentials:   http.StatusUnauthorized,
+	CodeInvalidToken:         http.StatusUnauthorized,
+	CodeInvalidMFACode:       http.StatusUnauthorized,
+	CodeForbidden:            http.StatusForbidden,
+	CodeOwnershipViolation:   http.StatusForbidden,
+	CodeNotFound:             http.StatusNotFound,
+	CodeAgentNotFound:        http.StatusNotFound,
+	CodeConflict:             http.StatusConflict,
+	CodeEmailAlreadyExists:   http.StatusConflict,
+	CodeVersionConflict:      http.StatusConflict,
+	CodeAccountLocked:        http.StatusLocked,
+	CodePreconditionRequired: http.StatusPreconditionRequired,
+	CodeQuotaExceeded:        http.StatusTooManyRequests,
+	CodeInternal:             http.StatusInternalServerError,
+	CodeAgentError:           http.StatusBadGateway,
+	CodeInvokeTimeout:        http.StatusGatewayTimeout,
+	CodeServiceUnavailable:   http.StatusServiceUnavailable,
+}
+
 type AppError struct {
-	Code    int    `json:"-"`
-	Message string `json:"error"`
+	Code      int       `json:"-"`
+	ErrorCode ErrorCode `json:"code"`
+	Message   string    `json:"error"`
+	// Details optionally lists individual problems, e.g. unmet password rules.
+	Details []string `json:"details,omitempty"`
 }
 
 func (e *AppError) Error() string {
@@ -15,40 +75,75 @@ func (e *AppError) Error() string {
 }
 
 var (
-	ErrBadRequest          = &AppError{Code: http.StatusBadRequest, Message: "bad request"}
-	ErrUnauthorized        = &AppError{Code: http.StatusUnauthorized, Message: "unauthorized"}
-	ErrForbidden           = &AppError{Code: http.StatusForbidden, Message: "forbidden"}
-	ErrNotFound            = &AppError{Code: http.StatusNotFound, Message: "not found"}
-	ErrConflict            = &AppError{Code: http.StatusConflict, Message: "conflict"}
-	ErrInternalServer      = &AppError{Code: http.StatusInternalServerError, Message: "internal server error"}
-	ErrInvalidCredentials  = &AppError{Code: http.StatusUnauthorized, Message: "invalid email or password"}
-	ErrEmailAlreadyExists  = &AppError{Code: http.StatusConflict, Message: "email already registered"}
-	ErrInvalidToken        = &AppError{Code: http.StatusUnauthorized, Message: "invalid or expired token"}
-	ErrOwnershipViolation  = &AppError{Code: http.StatusForbidden, Message: "access denied: ownership mismatch"}
-	ErrValidation          = &AppError{Code: http.StatusBadRequest, Message: "validation error"}
+	ErrBadRequest         = NewError(CodeBadRequest, "bad request")
+	ErrUnauthorized       = NewError(CodeUnauthorized, "unauthorized")
+	ErrForbidden          = NewError(CodeForbidden, "forbidden")
+	ErrNotFound           = NewError(CodeNotFound, "not found")
+	ErrConflict           = NewError(CodeConflict, "conflict")
+	ErrInternalServer     = NewError(CodeInternal, "internal server error")
+	ErrInvalidCredentials = NewError(CodeInvalidCredentials, "invalid email or password")
+	ErrEmailAlreadyExists = NewError(CodeEmailAlreadyExists, "email already registered")
+	ErrInvalidToken       = NewError(CodeInvalidToken, "invalid or expired token")
+	ErrInvalidMFACode     = NewError(CodeInvalidMFACode, "invalid two-factor code")
+	ErrAccountLocked      = NewError(CodeAccountLocked, "account temporarily locked after repeated failed logins")
+	ErrOwnershipViolation = NewError(CodeOwnershipViolation, "access denied: ownership mismatch")
+	ErrValidation         = NewError(CodeValidationFailed, "validation error")
+	ErrAgentNotFound      = NewError(CodeAgentNotFound, "agent not found")
+	ErrQuotaExceeded      = NewError(CodeQuotaExceeded, "quota exceeded")
+	ErrDBUnavailable      = NewError(CodeServiceUnavailable, "database temporarily unavailable")
 )
 
+// NewError builds an AppError for a registered code, using its HTTP status.
+func NewError(code ErrorCode, msg string) *AppError {
+	status, ok := ErrorCodes[code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return &AppError{Code: status, ErrorCode: code, Message: msg}
+}
+
 func NewBadRequestError(msg string) *AppError {
-	return &AppError{Code: http.StatusBadRequest, Message: msg}
+	return NewError(CodeBadRequest, msg)
+}
+
+func NewForbiddenError(msg string) *AppError {
+	return NewError(CodeForbidden, msg)
 }
 
 func NewNotFoundError(msg string) *AppError {
-	return &AppError{Code: http.StatusNotFound, Message: msg}
+	return NewError(CodeNotFound, msg)
 }
 
 func NewConflictError(msg string) *AppError {
-	return &AppError{Code: http.StatusConflict, Message: msg}
+	return NewError(CodeConflict, msg)
 }
 
 func NewValidationError(msg string) *AppError {
-	return &AppError{Code: http.StatusBadRequest, Message: msg}
+	return NewError(CodeValidationFailed, msg)
+}
+
+// NewQuotaExceededError reports a rate or daily limit hit with 429.
+func NewQuotaExceededError(msg string) *AppError {
+	return NewError(CodeQuotaExceeded, msg)
+}
+
+// NewWeakPasswordError rejects a password, listing the requirements it misses.
+func NewWeakPasswordError(unmet []string) *AppError {
+	err := NewError(CodeWeakPassword, "password does not meet the strength requirements")
+	err.Details = unmet
+	return err
 }
 
 func HandleError(w http.ResponseWriter, err error) {
 	var appErr *AppError
 	if errors.As(err, &appErr) {
-		JSONErrorMessage(w, appErr.Code, appErr.Message)
+		writeError(w, appErr.Code, appErr.ErrorCode, appErr.Message, appErr.Details)
+		return
+	}
+	// Postgres is down or failing over: a retry later can succeed.
+	if errors.Is(err, database.ErrUnavailable) {
+		HandleError(w, ErrDBUnavailable)
 		return
 	}
-	JSONErrorMessage(w, http.StatusInternalServerError, "internal server error")
+	writeError(w, http.StatusInternalServerError, CodeInternal, "internal server error", nil)
 }
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/database"
+)
+
+// declaredErrorCodes parses errors.go for every ErrorCode constant, so a code
+// added without a registry entry fails the test below.
+func declaredErrorCodes(t *testing.T) []ErrorCode {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "errors.go", nil, 0)
+	require.NoError(t, err)
+
+	var codes []ErrorCode
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != "ErrorCode" {
+				continue
+			}
+			for _, v := range vs.Values {
+				value, err := strconv.Unquote(v.(*ast.BasicLit).Value)
+				require.NoError(t, err)
+				codes = append(codes, ErrorCode(value))
+			}
+		}
+	}
+	return codes
+}
+
+func TestErrorCodes_RegistryIsExhaustive(t *testing.T) {
+	declared := declaredErrorCodes(t)
+	require.NotEmpty(t, declared)
+	assert.Len(t, ErrorCodes, len(declared), "every declared code must be registered exactly once")
+	for _, code := range declared {
+		status, ok := ErrorCodes[code]
+		if assert.True(t, ok, "code %s missing from ErrorCodes", code) {
+			assert.GreaterOrEqual(t, status, 400, "code %s", code)
+		}
+	}
+}
+
+func TestErrorCodes_SentinelsAreRegistered(t *testing.T) {
+	sentinels := []*AppError{
+		ErrBadRequest, ErrUnauthorized, ErrForbidden, ErrNotFound, ErrConflict,
+		ErrInternalServer, ErrInvalidCredentials, ErrEmailAlreadyExists, ErrInvalidToken,
+		ErrInvalidMFACode, ErrOwnershipViolation, ErrValidation, ErrAgentNotFound, ErrQuotaExceeded,
+		ErrDBUnavailable,
+	}
+	for _, e := range sentinels {
+		status, ok := ErrorCodes[e.ErrorCode]
+		require.True(t, ok, "%q has unregistered code %q", e.Message, e.ErrorCode)
+		assert.Equal(t, status, e.Code, "%q status must match its code", e.Message)
+	}
+}
+
+func TestHandleError_IncludesCode(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   ErrorCode
+	}{
+		{ErrOwnershipViolation, http.StatusForbidden, CodeOwnershipViolation},
+		{NewQuotaExceededError("daily token limit exceeded"), http.StatusTooManyRequests, CodeQuotaExceeded},
+		{ErrAgentNotFound, http.StatusNotFound, CodeAgentNotFound},
+		{errors.New("boom"), http.StatusInternalServerError, CodeInternal},
+		{fmt.Errorf("listing agents: %w", database.ErrUnavailable), http.StatusServiceUnavailable, CodeServiceUnavailable},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		HandleError(rec, tc.err)
+
+		var body Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, tc.status, rec.Code)
+		assert.Equal(t, string(tc.code), body.Code)
+		assert.NotEmpty(t, body.Error)
+	}
+}
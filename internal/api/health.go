@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/aiox-platform/aiox/internal/database"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// healthCheckTimeout bounds each dependency check so one hung dependency
+// cannot stall the readiness probe.
+const healthCheckTimeout = 2 * time.Second
+
+// redisPingTimeout is shorter than healthCheckTimeout: Redis sits on the auth
+// and rate-limiting path, so a slow PING already means requests are suffering.
+const redisPingTimeout = 500 * time.Millisecond
+
+const (
+	healthHealthy       = "healthy"
+	healthUnhealthy     = "unhealthy"
+	healthDegraded      = "degraded"
+	healthNotConfigured = "not configured"
+)
+
+// DependencyHealth is the readiness result for one dependency.
+type DependencyHealth struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+	// Circuit is the state of the database circuit breaker, when there is one.
+	Circuit string `json:"circuit,omitempty"`
+}
+
+// WorkerStats summarizes the connected worker pool.
+type WorkerStats struct {
+	Connected int `json:"connected"`
+	// Capacity is the total number of tasks the connected workers accept concurrently.
+	Capacity int `json:"capacity"`
+	Active   int `json:"active"`
+}
+
+type workerHealth struct {
+	Status string `json:"status"`
+	WorkerStats
+}
+
+type readiness struct {
+	Status   string            `json:"status"`
+	Database DependencyHealth  `json:"database"`
+	NATS     DependencyHealth  `json:"nats"`
+	Redis    DependencyHealth  `json:"redis"`
+	Workers  workerHealth      `json:"workers"`
+	GRPC     map[string]string `json:"grpc"`
+}
+
+// checkDependency runs check with a timeout and records how long it took.
+func checkDependency(ctx context.Context, check func(ctx context.Context) error) DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := DependencyHealth{
+		Status:    healthHealthy,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = healthUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// DatabaseCheck returns the readiness check for Postgres: a ping, failing
+// without one while circuit, when set, reports the breaker open.
+func DatabaseCheck(pool *pgxpool.Pool, circuit func() string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if circuit != nil && circuit() == database.BreakerOpen {
+			return errors.New("circuit breaker open")
+		}
+		return database.HealthCheck(ctx, pool)
+	}
+}
+
+// RedisCheck returns the readiness check for Redis: a PING bounded by
+// redisPingTimeout.
+func RedisCheck(client *redis.Client) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, redisPingTimeout)
+		defer cancel()
+		return client.Ping(ctx).Err()
+	}
+}
+
+// readinessHandler reports per-dependency health. Postgres, NATS, Redis, and
+// the gRPC listener are required and return 503 when down, as does an open
+// database circuit breaker; having no workers connected only marks the
+// service degraded.
+func readinessHandler(pool *pgxpool.Pool, natsClient *inats.Client, redisClient *redis.Client, h HandlerSet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.ShuttingDown != nil && h.ShuttingDown() {
+			JSON(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting_down"})
+			return
+		}
+
+		ctx := r.Context()
+		health := readiness{Status: healthHealthy}
+		status := http.StatusOK
+		fail := func(dep DependencyHealth) {
+			if dep.Status == healthUnhealthy {
+				health.Status = healthDegraded
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		if pool != nil {
+			health.Database = checkDependency(ctx, func(ctx context.Context) error {
+				return database.HealthCheck(ctx, pool)
+			})
+			fail(health.Database)
+		} else {
+			health.Database = DependencyHealth{Status: healthNotConfigured}
+		}
+		if h.DBCircuit != nil {
+			// Reads fail fast while the breaker is open, whatever a ping says.
+			health.Database.Circuit = h.DBCircuit()
+			if health.Database.Circuit == database.BreakerOpen {
+				health.Database.Status = healthUnhealthy
+				health.Database.Error = "circuit breaker open"
+				fail(health.Database)
+			}
+		}
+
+		if natsClient != nil {
+			health.NATS = checkDependency(ctx, func(context.Context) error {
+				_, err := natsClient.RTT()
+				return err
+			})
+			fail(health.NATS)
+		} else {
+			health.NATS = DependencyHealth{Status: healthNotConfigured}
+		}
+
+		if redisClient != nil {
+			health.Redis = checkDependency(ctx, RedisCheck(redisClient))
+			fail(health.Redis)
+		} else {
+			health.Redis = DependencyHealth{Status: healthNotConfigured}
+		}
+
+		health.Workers.Status = healthNotConfigured
+		if h.WorkerStats != nil {
+			health.Workers.WorkerStats = h.WorkerStats()
+			health.Workers.Status = healthHealthy
+			if health.Workers.Connected == 0 {
+				health.Workers.Status = "no workers connected"
+				health.Status = healthDegraded
+			}
+		}
+
+		health.GRPC = map[string]string{"status": healthNotConfigured}
+		if h.GRPCServing != nil {
+			health.GRPC["status"] = healthHealthy
+			if !h.GRPCServing() {
+				health.GRPC["status"] = "not serving"
+				health.Status = healthDegraded
+				status = http.StatusServiceUnavailable
+			}
+		}
+
+		JSON(w, status, health)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/database"
+)
+
+func getReadiness(t *testing.T, handler http.HandlerFunc) (int, readiness) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	var body struct {
+		Data readiness `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec.Code, body.Data
+}
+
+func TestReadiness_RedisDown(t *testing.T) {
+	s := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: s.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { rdb.Close() })
+
+	handler := readinessHandler(nil, nil, rdb, HandlerSet{})
+
+	code, health := getReadiness(t, handler)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, healthHealthy, health.Status)
+	assert.Equal(t, healthHealthy, health.Redis.Status)
+	assert.Empty(t, health.Redis.Error)
+
+	s.Close()
+
+	code, health = getReadiness(t, handler)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, healthDegraded, health.Status)
+	assert.Equal(t, healthUnhealthy, health.Redis.Status)
+	assert.NotEmpty(t, health.Redis.Error)
+}
+
+func TestReadiness_ShuttingDown(t *testing.T) {
+	handler := readinessHandler(nil, nil, nil, HandlerSet{ShuttingDown: func() bool { return true }})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "shutting_down")
+}
+
+func TestReadiness_DBCircuitOpen(t *testing.T) {
+	state := database.BreakerClosed
+	handler := readinessHandler(nil, nil, nil, HandlerSet{DBCircuit: func() string { return state }})
+
+	code, health := getReadiness(t, handler)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, database.BreakerClosed, health.Database.Circuit)
+
+	state = database.BreakerOpen
+	code, health = getReadiness(t, handler)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, healthUnhealthy, health.Database.Status)
+	assert.Equal(t, database.BreakerOpen, health.Database.Circuit)
+}
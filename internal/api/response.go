@@ -9,13 +9,44 @@ type Response struct {
 	Data    any    `json:"data,omitempty"`
 	Message string `json:"message,omitempty"`
 	Error   string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"`
+	// Details accompanies Error when a request fails for several reasons.
+	Details []string `json:"details,omitempty"`
 }
 
-type PaginatedResponse struct {
-	Data       any   `json:"data"`
+// Pagination describes where a page sits in an offset-paginated list.
+type Pagination struct {
 	TotalCount int64 `json:"total_count"`
 	Page       int   `json:"page"`
 	PageSize   int   `json:"page_size"`
+	TotalPages int   `json:"total_pages"`
+	HasNext    bool  `json:"has_next"`
+	HasPrev    bool  `json:"has_prev"`
+}
+
+// NewPagination computes page metadata for a 1-based page of pageSize items
+// out of totalCount.
+func NewPagination(totalCount int64, page, pageSize int) Pagination {
+	p := Pagination{TotalCount: totalCount, Page: page, PageSize: pageSize}
+	if pageSize > 0 {
+		p.TotalPages = int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+	}
+	p.HasNext = page < p.TotalPages
+	p.HasPrev = page > 1
+	return p
+}
+
+type PaginatedResponse struct {
+	Data any `json:"data"`
+	Pagination
+}
+
+// CursorResponse is the envelope for keyset-paginated lists. NextCursor is
+// omitted on the last page.
+type CursorResponse struct {
+	Data       any    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PageSize   int    `json:"page_size"`
 }
 
 func JSON(w http.ResponseWriter, status int, data any) {
@@ -35,8 +66,16 @@ func JSONPaginated(w http.ResponseWriter, status int, data any, totalCount int64
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(PaginatedResponse{
 		Data:       data,
-		TotalCount: totalCount,
-		Page:       page,
+		Pagination: NewPagination(totalCount, page, pageSize),
+	})
+}
+
+func JSONCursor(w http.ResponseWriter, status int, data any, nextCursor string, pageSize int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(CursorResponse{
+		Data:       data,
+		NextCursor: nextCursor,
 		PageSize:   pageSize,
 	})
 }
@@ -52,3 +91,9 @@ func JSONErrorMessage(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(Response{Error: message})
 }
+
+func writeError(w http.ResponseWriter, status int, code ErrorCode, message string, details []string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response{Error: message, Code: string(code), Details: details})
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPagination(t *testing.T) {
+	cases := []struct {
+		name           string
+		total          int64
+		page, pageSize int
+		want           Pagination
+	}{
+		{
+			name: "first of several pages", total: 45, page: 1, pageSize: 20,
+			want: Pagination{TotalCount: 45, Page: 1, PageSize: 20, TotalPages: 3, HasNext: true, HasPrev: false},
+		},
+		{
+			name: "last partial page", total: 45, page: 3, pageSize: 20,
+			want: Pagination{TotalCount: 45, Page: 3, PageSize: 20, TotalPages: 3, HasNext: false, HasPrev: true},
+		},
+		{
+			name: "exact multiple", total: 40, page: 2, pageSize: 20,
+			want: Pagination{TotalCount: 40, Page: 2, PageSize: 20, TotalPages: 2, HasNext: false, HasPrev: true},
+		},
+		{
+			name: "empty result", total: 0, page: 1, pageSize: 20,
+			want: Pagination{TotalCount: 0, Page: 1, PageSize: 20, TotalPages: 0, HasNext: false, HasPrev: false},
+		},
+		{
+			name: "page past the end", total: 5, page: 4, pageSize: 20,
+			want: Pagination{TotalCount: 5, Page: 4, PageSize: 20, TotalPages: 1, HasNext: false, HasPrev: true},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, NewPagination(tc.total, tc.page, tc.pageSize))
+		})
+	}
+}
+
+func TestJSONPaginated_Envelope(t *testing.T) {
+	rec := httptest.NewRecorder()
+	JSONPaginated(rec, http.StatusOK, []int{1, 2, 3}, 23, 3, 10)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, float64(23), body["total_count"])
+	assert.Equal(t, float64(3), body["page"])
+	assert.Equal(t, float64(10), body["page_size"])
+	assert.Equal(t, float64(3), body["total_pages"])
+	assert.Equal(t, false, body["has_next"])
+	assert.Equal(t, true, body["has_prev"])
+	assert.Len(t, body["data"], 3)
+}
@@ -2,13 +2,15 @@ package api
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 
-	"github.com/aiox-platform/aiox/internal/database"
 	mw "github.com/aiox-platform/aiox/internal/middleware"
 	inats "github.com/aiox-platform/aiox/internal/nats"
 )
@@ -21,94 +23,192 @@ type HandlerSet struct {
 	Refresh  http.HandlerFunc
 	Logout   http.HandlerFunc
 
+	// Session handlers
+	ListSessions  http.HandlerFunc
+	RevokeSession http.HandlerFunc
+
+	// Two-factor authentication handlers
+	EnrollTwoFactor  http.HandlerFunc
+	VerifyTwoFactor  http.HandlerFunc
+	DisableTwoFactor http.HandlerFunc
+	TwoFactorLogin   http.HandlerFunc
+
+	// Password reset handlers
+	RequestPasswordReset http.HandlerFunc
+	ConfirmPasswordReset http.HandlerFunc
+
+	// API key handlers
+	CreateAPIKey http.HandlerFunc
+	ListAPIKeys  http.HandlerFunc
+	RevokeAPIKey http.HandlerFunc
+
 	// Agent handlers
 	CreateAgent         http.HandlerFunc
 	ListAgents          http.HandlerFunc
+	ListPublicAgents    http.HandlerFunc
 	GetAgent            http.HandlerFunc
 	UpdateAgent         http.HandlerFunc
 	DeleteAgent         http.HandlerFunc
+	ListAgentVersions   http.HandlerFunc
+	RollbackAgent       http.HandlerFunc
+	PauseAgent          http.HandlerFunc
+	ResumeAgent         http.HandlerFunc
+	ListAgentExecutions http.HandlerFunc
+	GetAgentExecution   http.HandlerFunc
+	GetExecutionContext http.HandlerFunc
+	InvokeAgent         http.HandlerFunc
 	OwnershipMiddleware func(http.Handler) http.Handler
 
+	// Room binding handlers (nil when multi-user chat is not wired)
+	CreateRoomBinding http.HandlerFunc
+	ListRoomBindings  http.HandlerFunc
+	DeleteRoomBinding http.HandlerFunc
+
+	// Prompt template handlers
+	CreatePromptTemplate http.HandlerFunc
+	ListPromptTemplates  http.HandlerFunc
+	GetPromptTemplate    http.HandlerFunc
+	UpdatePromptTemplate http.HandlerFunc
+	DeletePromptTemplate http.HandlerFunc
+
+	// Real-time agent chat over WebSocket
+	AgentChat http.Handler
+
 	// Memory handlers (Phase 4)
-	ListMemories      http.HandlerFunc
-	CreateMemory      http.HandlerFunc
-	SearchMemories    http.HandlerFunc
-	DeleteMemory      http.HandlerFunc
-	DeleteAllMemories http.HandlerFunc
+	ListMemories       http.HandlerFunc
+	CreateMemory       http.HandlerFunc
+	BulkCreateMemories http.HandlerFunc
+	SearchMemories     http.HandlerFunc
+	DeleteMemory       http.HandlerFunc
+	DeleteAllMemories  http.HandlerFunc
+	RestoreMemory      http.HandlerFunc
+
+	// Short-term conversation handlers
+	ListConversations http.HandlerFunc
+	GetConversation   http.HandlerFunc
+	ClearConversation http.HandlerFunc
 
 	// Governance handlers (Phase 5)
 	GetUserQuota       http.HandlerFunc
 	ListAuditLogs      http.HandlerFunc
 	ListAgentAuditLogs http.HandlerFunc
+	GetAgentQuota      http.HandlerFunc
+	GetUserCost        http.HandlerFunc
+	// Dead-letter handlers (nil when NATS dead-lettering is not wired)
+	ListDeadLetters http.HandlerFunc
+	RetryDeadLetter http.HandlerFunc
+	// StreamAuditLogs serves live audit events over SSE (nil when NATS is not wired)
+	StreamAuditLogs http.HandlerFunc
+
+	// ExportAccount streams all of the caller's data
+	ExportAccount http.HandlerFunc
+	// DeleteAccount permanently deletes the caller's account
+	DeleteAccount http.HandlerFunc
+
+	// Webhook handlers
+	CreateWebhook http.HandlerFunc
+	ListWebhooks  http.HandlerFunc
+	GetWebhook    http.HandlerFunc
+	UpdateWebhook http.HandlerFunc
+	DeleteWebhook http.HandlerFunc
+	TestWebhook   http.HandlerFunc
+
+	// Admin handlers
+	ListWorkers    http.HandlerFunc
+	ListUserQuotas http.HandlerFunc
+	RevokeSessions http.HandlerFunc
+	// SetAgentLLMCaps overrides the deployment's LLM caps for one agent
+	SetAgentLLMCaps http.HandlerFunc
 
 	// Auth middleware
 	AuthMiddleware func(http.Handler) http.Handler
+	// RequireAdmin rejects non-admin callers. Admin routes are not mounted
+	// while it is nil.
+	RequireAdmin func(http.Handler) http.Handler
+	// RequireSession rejects callers without a login session, such as API
+	// keys. The protected auth routes are not mounted while it is nil.
+	RequireSession func(http.Handler) http.Handler
+	// RequireScope returns middleware rejecting requests without the given scope.
+	// When nil, scopes are not enforced.
+	RequireScope func(scope string) func(http.Handler) http.Handler
+
+	// WorkerStats reports connected workers and their capacity (Phase 3)
+	WorkerStats func() WorkerStats
+
+	// GRPCServing reports whether the worker gRPC listener is bound and serving.
+	GRPCServing func() bool
+
+	// ShuttingDown reports whether the process received a shutdown signal;
+	// readiness then fails so load balancers stop routing new traffic.
+	ShuttingDown func() bool
 
-	// Worker pool health (Phase 3)
-	WorkerPoolHealthy func() bool
+	// DBCircuit reports the database circuit breaker state for readiness.
+	DBCircuit func() string
 }
 
 // RouterConfig holds configuration for the router.
 type RouterConfig struct {
-	CORSAllowedOrigins []string
-	AuthRateLimiter    func(http.Handler) http.Handler
+	CORS            mw.CORSConfig
+	Logging         mw.LoggingConfig
+	Metrics         mw.MetricsConfig
+	AuthRateLimiter func(http.Handler) http.Handler
+
+	// Idempotency, when set, guards create endpoints against retried requests
+	// carrying an Idempotency-Key header.
+	Idempotency func(http.Handler) http.Handler
+
+	// Request body limits in bytes; zero disables the limit. Auth endpoints
+	// get AuthMaxBodyBytes and bulk imports BulkMaxBodyBytes.
+	MaxBodyBytes     int64
+	AuthMaxBodyBytes int64
+	BulkMaxBodyBytes int64
+	// HandlerTimeout bounds non-streaming handlers; zero disables it.
+	HandlerTimeout time.Duration
 }
 
-func NewRouter(pool *pgxpool.Pool, natsClient *inats.Client, cfg RouterConfig, h HandlerSet) http.Handler {
+func NewRouter(pool *pgxpool.Pool, natsClient *inats.Client, redisClient *redis.Client, cfg RouterConfig, h HandlerSet) http.Handler {
 	r := chi.NewRouter()
 
+	scope := func(s string) func(http.Handler) http.Handler {
+		if h.RequireScope == nil {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return h.RequireScope(s)
+	}
+
+	idempotent := cfg.Idempotency
+	if idempotent == nil {
+		idempotent = func(next http.Handler) http.Handler { return next }
+	}
+
 	// Global middleware
 	r.Use(mw.RequestID)
 	r.Use(mw.SecurityHeaders)
-	r.Use(mw.Logging)
+	r.Use(mw.Logging(cfg.Logging))
 	r.Use(mw.Recovery)
-	r.Use(mw.Metrics)
-	r.Use(cors.Handler(mw.CORS(cfg.CORSAllowedOrigins)))
+	r.Use(mw.Metrics(cfg.Metrics))
+	r.Use(cors.Handler(mw.CORS(cfg.CORS)))
+	r.Use(mw.MaxBodySize(func(r *http.Request) int64 {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/api/v1/auth/"):
+			return cfg.AuthMaxBodyBytes
+		case strings.HasSuffix(r.URL.Path, "/bulk"):
+			return cfg.BulkMaxBodyBytes
+		default:
+			return cfg.MaxBodyBytes
+		}
+	}))
+	r.Use(mw.Timeout(cfg.HandlerTimeout, isLongLived))
 
 	// Liveness probe — always 200, no dependency checks
 	r.Get("/health/live", func(w http.ResponseWriter, r *http.Request) {
 		JSON(w, http.StatusOK, map[string]string{"status": "alive"})
 	})
 
-	// Readiness probe — checks DB, NATS, workers
-	readinessHandler := func(w http.ResponseWriter, r *http.Request) {
-		health := map[string]string{
-			"status":   "healthy",
-			"database": "healthy",
-			"nats":     "healthy",
-			"workers":  "healthy",
-		}
-
-		status := http.StatusOK
-
-		if err := database.HealthCheck(r.Context(), pool); err != nil {
-			health["database"] = "unhealthy"
-			health["status"] = "degraded"
-			status = http.StatusServiceUnavailable
-		}
-
-		if natsClient != nil && !natsClient.Healthy() {
-			health["nats"] = "unhealthy"
-			health["status"] = "degraded"
-			status = http.StatusServiceUnavailable
-		} else if natsClient == nil {
-			health["nats"] = "not configured"
-		}
-
-		if h.WorkerPoolHealthy != nil {
-			if !h.WorkerPoolHealthy() {
-				health["workers"] = "no workers connected"
-				health["status"] = "degraded"
-			}
-		} else {
-			health["workers"] = "not configured"
-		}
-
-		JSON(w, status, health)
-	}
-
-	r.Get("/health/ready", readinessHandler)
-	r.Get("/health", readinessHandler)
+	// Readiness probe — checks DB, NATS, Redis, workers, gRPC
+	ready := readinessHandler(pool, natsClient, redisClient, h)
+	r.Get("/health/ready", ready)
+	r.Get("/health", ready)
 
 	// Prometheus metrics
 	r.Handle("/metrics", promhttp.Handler())
@@ -123,12 +223,29 @@ func NewRouter(pool *pgxpool.Pool, natsClient *inats.Client, cfg RouterConfig, h
 			r.Post("/register", h.Register)
 			r.Post("/login", h.Login)
 			r.Post("/refresh", h.Refresh)
+			r.Post("/2fa/login", h.TwoFactorLogin)
+			r.Post("/password-reset/request", h.RequestPasswordReset)
+			r.Post("/password-reset/confirm", h.ConfirmPasswordReset)
 
-			// Protected auth routes
-			r.Group(func(r chi.Router) {
-				r.Use(h.AuthMiddleware)
-				r.Post("/logout", h.Logout)
-			})
+			// Protected auth routes. They manage the account's credentials and
+			// sessions, so only a login session may use them.
+			if h.RequireSession != nil {
+				r.Group(func(r chi.Router) {
+					r.Use(h.AuthMiddleware, h.RequireSession)
+					r.Post("/logout", h.Logout)
+					r.Get("/sessions", h.ListSessions)
+					r.Delete("/sessions/{sessionID}", h.RevokeSession)
+					r.Post("/2fa/enroll", h.EnrollTwoFactor)
+					r.Post("/2fa/verify", h.VerifyTwoFactor)
+					r.Post("/2fa/disable", h.DisableTwoFactor)
+
+					r.Route("/api-keys", func(r chi.Router) {
+						r.Post("/", h.CreateAPIKey)
+						r.Get("/", h.ListAPIKeys)
+						r.Delete("/{keyID}", h.RevokeAPIKey)
+					})
+				})
+			}
 		})
 
 		// Protected routes
@@ -137,36 +254,141 @@ func NewRouter(pool *pgxpool.Pool, natsClient *inats.Client, cfg RouterConfig, h
 
 			// Agent routes
 			r.Route("/agents", func(r chi.Router) {
-				r.Post("/", h.CreateAgent)
-				r.Get("/", h.ListAgents)
+				r.With(scope("agents:write"), idempotent).Post("/", h.CreateAgent)
+				r.With(scope("agents:read")).Get("/", h.ListAgents)
+				r.With(scope("agents:read")).Get("/public", h.ListPublicAgents)
 
+				// Scope checks run before ownership so a key lacking the
+				// scope is rejected without touching the agent.
 				r.Route("/{agentID}", func(r chi.Router) {
-					r.Use(h.OwnershipMiddleware)
-					r.Get("/", h.GetAgent)
-					r.Put("/", h.UpdateAgent)
-					r.Delete("/", h.DeleteAgent)
+					owned := func(s string) chi.Router {
+						return r.With(scope(s), h.OwnershipMiddleware)
+					}
+
+					owned("agents:read").Get("/", h.GetAgent)
+					owned("agents:write").Put("/", h.UpdateAgent)
+					owned("agents:write").Delete("/", h.DeleteAgent)
+					owned("agents:read").Get("/versions", h.ListAgentVersions)
+					owned("agents:write").Post("/versions/{versionID}/rollback", h.RollbackAgent)
+					owned("agents:write").Post("/pause", h.PauseAgent)
+					owned("agents:write").Post("/resume", h.ResumeAgent)
+					owned("agents:read").Get("/executions", h.ListAgentExecutions)
+					owned("agents:read").Get("/executions/{execID}", h.GetAgentExecution)
+					owned("agents:read").Get("/executions/{execID}/context", h.GetExecutionContext)
+					if h.InvokeAgent != nil {
+						owned("agents:write").Post("/invoke", h.InvokeAgent)
+					}
+					if h.CreateRoomBinding != nil {
+						owned("agents:write").Post("/rooms", h.CreateRoomBinding)
+						owned("agents:read").Get("/rooms", h.ListRoomBindings)
+						owned("agents:write").Delete("/rooms/{bindingID}", h.DeleteRoomBinding)
+					}
 
 					// Memory routes (Phase 4)
 					r.Route("/memories", func(r chi.Router) {
-						r.Get("/", h.ListMemories)
-						r.Post("/", h.CreateMemory)
-						r.Post("/search", h.SearchMemories)
-						r.Delete("/", h.DeleteAllMemories)
-						r.Delete("/{memoryID}", h.DeleteMemory)
+						read := r.With(scope("memories:read"), h.OwnershipMiddleware)
+						write := r.With(scope("memories:write"), h.OwnershipMiddleware)
+						read.Get("/", h.ListMemories)
+						write.With(idempotent).Post("/", h.CreateMemory)
+						write.Post("/bulk", h.BulkCreateMemories)
+						read.Post("/search", h.SearchMemories)
+						write.Delete("/", h.DeleteAllMemories)
+						write.Delete("/{memoryID}", h.DeleteMemory)
+						write.Post("/{memoryID}/restore", h.RestoreMemory)
+					})
+					r.Route("/conversations", func(r chi.Router) {
+						read := r.With(scope("memories:read"), h.OwnershipMiddleware)
+						write := r.With(scope("memories:write"), h.OwnershipMiddleware)
+						read.Get("/", h.ListConversations)
+						read.Get("/{userJID}/messages", h.GetConversation)
+						write.Delete("/{userJID}/messages", h.ClearConversation)
 					})
 
 					// Agent audit logs (Phase 5)
-					r.Get("/audit", h.ListAgentAuditLogs)
+					owned("governance:read").Get("/audit", h.ListAgentAuditLogs)
+					owned("governance:read").Get("/quota", h.GetAgentQuota)
+
+					// WebSocket chat
+					if h.AgentChat != nil {
+						owned("agents:write").Handle("/chat", h.AgentChat)
+					}
 				})
 			})
 
+			// Prompt template routes
+			r.Route("/prompt-templates", func(r chi.Router) {
+				r.With(scope("agents:write")).Post("/", h.CreatePromptTemplate)
+				r.With(scope("agents:read")).Get("/", h.ListPromptTemplates)
+				r.With(scope("agents:read")).Get("/{templateID}", h.GetPromptTemplate)
+				r.With(scope("agents:write")).Put("/{templateID}", h.UpdatePromptTemplate)
+				r.With(scope("agents:write")).Delete("/{templateID}", h.DeletePromptTemplate)
+			})
+
 			// Governance routes (Phase 5)
 			r.Route("/governance", func(r chi.Router) {
+				r.Use(scope("governance:read"))
 				r.Get("/quota", h.GetUserQuota)
 				r.Get("/audit", h.ListAuditLogs)
+				r.Get("/cost", h.GetUserCost)
+
+				if h.ListDeadLetters != nil {
+					r.Get("/dead-letters", h.ListDeadLetters)
+					r.With(scope("agents:write")).Post("/dead-letters/{deadLetterID}/retry", h.RetryDeadLetter)
+				}
+				if h.StreamAuditLogs != nil {
+					r.Get("/audit/stream", h.StreamAuditLogs)
+				}
 			})
+
+			// Account routes. The export covers agents, memories, and audit
+			// logs, so it needs every read scope. Deletion checks for a login
+			// session itself.
+			if h.ExportAccount != nil {
+				r.With(scope("agents:read"), scope("memories:read"), scope("governance:read")).
+					Get("/account/export", h.ExportAccount)
+			}
+			if h.DeleteAccount != nil {
+				r.Delete("/account", h.DeleteAccount)
+			}
+
+			// Webhook routes (nil when webhooks are not wired)
+			if h.CreateWebhook != nil {
+				r.Route("/webhooks", func(r chi.Router) {
+					r.With(scope("webhooks:write")).Post("/", h.CreateWebhook)
+					r.With(scope("webhooks:read")).Get("/", h.ListWebhooks)
+					r.With(scope("webhooks:read")).Get("/{webhookID}", h.GetWebhook)
+					r.With(scope("webhooks:write")).Put("/{webhookID}", h.UpdateWebhook)
+					r.With(scope("webhooks:write")).Delete("/{webhookID}", h.DeleteWebhook)
+					r.With(scope("webhooks:write")).Post("/{webhookID}/test", h.TestWebhook)
+				})
+			}
+
+			// Admin routes. These are not owner-scoped; regular routes keep
+			// their ownership checks for admins too.
+			if h.RequireAdmin != nil {
+				r.With(h.RequireAdmin).Get("/workers", h.ListWorkers)
+				r.Route("/admin", func(r chi.Router) {
+					r.Use(h.RequireAdmin)
+					r.Get("/quotas", h.ListUserQuotas)
+					r.Post("/users/{userID}/revoke-sessions", h.RevokeSessions)
+					if h.SetAgentLLMCaps != nil {
+						r.Put("/agents/{agentID}/llm-caps", h.SetAgentLLMCaps)
+					}
+				})
+			}
 		})
 	})
 
 	return r
 }
+
+// isLongLived reports whether r is for a WebSocket or SSE endpoint, a
+// synchronous invoke bounded by the task timeout, or an account export, that
+// must not be cut off by the handler timeout.
+func isLongLived(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.HasSuffix(r.URL.Path, "/chat") ||
+		strings.HasSuffix(r.URL.Path, "/audit/stream") ||
+		strings.HasSuffix(r.URL.Path, "/invoke") ||
+		strings.HasSuffix(r.URL.Path, "/account/export")
+}
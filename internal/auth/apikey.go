@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeyPrefix marks every generated key so leaked keys are easy to spot.
+	APIKeyPrefix = "aiox_"
+
+	// apiKeyDisplayLen is how many leading characters are kept in clear for listing.
+	apiKeyDisplayLen = len(APIKeyPrefix) + 8
+)
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// APIKey is a long-lived credential for programmatic access. Only the SHA-256
+// hash of the key is stored; the full key is returned once at creation.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id"`
+	OwnerUserID uuid.UUID  `json:"owner_user_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	Scopes      []string   `json:"scopes"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKey is the creation response; Key is never returned again.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes []string `json:"scopes" validate:"omitempty,dive,oneof=agents:read agents:write memories:read memories:write governance:read webhooks:read webhooks:write"`
+}
+
+// generateAPIKey returns a new random key and its display prefix.
+func generateAPIKey() (key, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generating api key: %w", err)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:apiKeyDisplayLen], nil
+}
+
+// hashAPIKey returns the hex SHA-256 of key. Keys carry 256 bits of entropy,
+// so a fast hash is sufficient and allows lookup by hash.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
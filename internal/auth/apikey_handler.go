@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+)
+
+// APIKeyHandler handles API key management endpoints.
+type APIKeyHandler struct {
+	svc      *APIKeyService
+	validate *validator.Validate
+}
+
+func NewAPIKeyHandler(svc *APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		svc:      svc,
+		validate: validator.New(),
+	}
+}
+
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	// A key can never carry more privilege than the credential that created it.
+	claims := GetUserClaims(r.Context())
+	for _, scope := range req.Scopes {
+		if !claims.HasScope(scope) {
+			api.HandleError(w, api.NewForbiddenError("cannot grant scope not held by caller: "+scope))
+			return
+		}
+	}
+
+	created, err := h.svc.Create(r.Context(), ownerID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "creating api key", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, created)
+}
+
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.svc.List(r.Context(), ownerID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing api keys", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if keys == nil {
+		keys = []*APIKey{}
+	}
+
+	api.JSON(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid api key ID"))
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), keyID, ownerID); err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			api.HandleError(w, api.NewNotFoundError("api key not found"))
+			return
+		}
+		slog.ErrorContext(r.Context(), "revoking api key", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "api key revoked")
+}
+
+func ownerFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+
+	ownerID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+	return ownerID, true
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error)
+	GetActiveByHash(ctx context.Context, hash string) (*APIKey, error)
+	Revoke(ctx context.Context, id, ownerID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+type postgresAPIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) APIKeyRepository {
+	return &postgresAPIKeyRepository{pool: pool}
+}
+
+func (r *postgresAPIKeyRepository) Create(ctx context.Context, key *APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, owner_user_id, name, key_prefix, key_hash, scopes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.pool.Exec(ctx, query,
+		key.ID, key.OwnerUserID, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting api key: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAPIKeyRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error) {
+	query := `
+		SELECT id, owner_user_id, name, key_prefix, key_hash, scopes, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE owner_user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.pool.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("listing api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *postgresAPIKeyRepository) GetActiveByHash(ctx context.Context, hash string) (*APIKey, error) {
+	query := `
+		SELECT id, owner_user_id, name, key_prefix, key_hash, scopes, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL`
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying api key by hash: %w", err)
+	}
+	return key, nil
+}
+
+func (r *postgresAPIKeyRepository) Revoke(ctx context.Context, id, ownerID uuid.UUID) error {
+	query := `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND owner_user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.pool.Exec(ctx, query, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("revoking api key: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *postgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("updating api key last_used_at: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*APIKey, error) {
+	key := &APIKey{}
+	err := row.Scan(
+		&key.ID, &key.OwnerUserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes,
+		&key.LastUsedAt, &key.CreatedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
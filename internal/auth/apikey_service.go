@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const touchTimeout = 5 * time.Second
+
+// APIKeyService manages API keys and authenticates requests that present one.
+type APIKeyService struct {
+	repo APIKeyRepository
+}
+
+func NewAPIKeyService(repo APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create generates a new key for the owner. The returned Key is the only time
+// the plaintext is available.
+func (s *APIKeyService) Create(ctx context.Context, ownerID uuid.UUID, req *CreateAPIKeyRequest) (*CreatedAPIKey, error) {
+	raw, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := req.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	key := &APIKey{
+		ID:          uuid.New(),
+		OwnerUserID: ownerID,
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     hashAPIKey(raw),
+		Scopes:      scopes,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKey: key, Key: raw}, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, ownerID uuid.UUID) ([]*APIKey, error) {
+	return s.repo.ListByOwner(ctx, ownerID)
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id, ownerID uuid.UUID) error {
+	return s.repo.Revoke(ctx, id, ownerID)
+}
+
+// Authenticate resolves a plaintext key to the claims of its owner.
+// last_used_at is updated in the background so lookups stay on the fast path.
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (*AccessClaims, error) {
+	key, err := s.repo.GetActiveByHash(ctx, hashAPIKey(raw))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("unknown or revoked api key")
+	}
+
+	go func(id uuid.UUID) {
+		touchCtx, cancel := context.WithTimeout(context.Background(), touchTimeout)
+		defer cancel()
+		if err := s.repo.TouchLastUsed(touchCtx, id); err != nil {
+			slog.Warn("updating api key last use", "key_id", id, "error", err)
+		}
+	}(key.ID)
+
+	return &AccessClaims{
+		UserID: key.OwnerUserID.String(),
+		Scopes: key.Scopes,
+	}, nil
+}
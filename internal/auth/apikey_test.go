@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAPIKeyRepo struct {
+	APIKeyRepository
+	mu      sync.Mutex
+	keys    map[string]*APIKey
+	touched chan uuid.UUID
+}
+
+func newFakeAPIKeyRepo() *fakeAPIKeyRepo {
+	return &fakeAPIKeyRepo{keys: map[string]*APIKey{}, touched: make(chan uuid.UUID, 1)}
+}
+
+func (f *fakeAPIKeyRepo) Create(_ context.Context, key *APIKey) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[key.KeyHash] = key
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) GetActiveByHash(_ context.Context, hash string) (*APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := f.keys[hash]
+	if key == nil || key.RevokedAt != nil {
+		return nil, nil
+	}
+	return key, nil
+}
+
+func (f *fakeAPIKeyRepo) TouchLastUsed(_ context.Context, id uuid.UUID) error {
+	f.touched <- id
+	return nil
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := generateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, APIKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.Len(t, prefix, apiKeyDisplayLen)
+
+	other, _, err := generateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, hashAPIKey(key), hashAPIKey(other))
+}
+
+func TestMiddleware_APIKey(t *testing.T) {
+	repo := newFakeAPIKeyRepo()
+	keys := NewAPIKeyService(repo)
+	owner := uuid.New()
+
+	created, err := keys.Create(context.Background(), owner, &CreateAPIKeyRequest{Name: "ci", Scopes: []string{"agents:read"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Key, created.KeyHash)
+
+	var got *AccessClaims
+	mgr := NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", 15*time.Minute, time.Hour)
+	handler := Middleware(NewService(mgr, nil), keys)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = GetUserClaims(r.Context())
+	}))
+
+	t.Run("valid key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "ApiKey "+created.Key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, got)
+		assert.Equal(t, owner.String(), got.UserID)
+		assert.Equal(t, []string{"agents:read"}, got.Scopes)
+
+		select {
+		case id := <-repo.touched:
+			assert.Equal(t, created.ID, id)
+		case <-time.After(time.Second):
+			t.Fatal("last_used_at was not updated")
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "ApiKey aiox_nope")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		now := time.Now()
+		created.RevokedAt = &now
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "ApiKey "+created.Key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestMiddleware_APIKeyDisabled(t *testing.T) {
+	mgr := NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", 15*time.Minute, time.Hour)
+	handler := Middleware(NewService(mgr, nil), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "ApiKey aiox_whatever")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -4,60 +4,135 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"strings"
 )
 
+// Encryptor seals secrets with AES-256-GCM under a key ring. Ciphertexts are
+// prefixed with the ID of the key that sealed them ("<key id>:<hex>"), so
+// values written before a rotation stay readable while their key is kept as a
+// previous key.
 type Encryptor struct {
-	gcm cipher.AEAD
+	primary string
+	keys    map[string]cipher.AEAD
+	// order lists key IDs primary first, for ciphertexts without a key ID.
+	order []string
 }
 
-func NewEncryptor(hexKey string) (*Encryptor, error) {
+// NewEncryptor creates an Encryptor that encrypts with hexKey and can also
+// decrypt values sealed with any of previousKeys.
+func NewEncryptor(hexKey string, previousKeys ...string) (*Encryptor, error) {
+	e := &Encryptor{keys: make(map[string]cipher.AEAD)}
+	for i, k := range append([]string{hexKey}, previousKeys...) {
+		id, gcm, err := newKey(k)
+		if err != nil {
+			if i > 0 {
+				return nil, fmt.Errorf("previous key %d: %w", i, err)
+			}
+			return nil, err
+		}
+		if _, dup := e.keys[id]; dup {
+			continue
+		}
+		if i == 0 {
+			e.primary = id
+		}
+		e.keys[id] = gcm
+		e.order = append(e.order, id)
+	}
+	return e, nil
+}
+
+// KeyID derives the short identifier stored with ciphertexts from a hex key.
+func KeyID(hexKey string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(hexKey)))
+	return hex.EncodeToString(sum[:4])
+}
+
+func newKey(hexKey string) (string, cipher.AEAD, error) {
 	key, err := hex.DecodeString(hexKey)
 	if err != nil {
-		return nil, fmt.Errorf("decoding encryption key: %w", err)
+		return "", nil, fmt.Errorf("decoding encryption key: %w", err)
 	}
 	if len(key) != 32 {
-		return nil, fmt.Errorf("encryption key must be 32 bytes (64 hex chars), got %d bytes", len(key))
+		return "", nil, fmt.Errorf("encryption key must be 32 bytes (64 hex chars), got %d bytes", len(key))
 	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return nil, fmt.Errorf("creating AES cipher: %w", err)
+		return "", nil, fmt.Errorf("creating AES cipher: %w", err)
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return nil, fmt.Errorf("creating GCM: %w", err)
+		return "", nil, fmt.Errorf("creating GCM: %w", err)
 	}
 
-	return &Encryptor{gcm: gcm}, nil
+	return KeyID(hexKey), gcm, nil
+}
+
+// PrimaryKeyID returns the ID of the key new values are encrypted with.
+func (e *Encryptor) PrimaryKeyID() string {
+	return e.primary
 }
 
+// Encrypt seals plaintext under the primary key.
 func (e *Encryptor) Encrypt(plaintext string) (string, error) {
-	nonce := make([]byte, e.gcm.NonceSize())
+	gcm := e.keys[e.primary]
+	nonce := make([]byte, gcm.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", fmt.Errorf("generating nonce: %w", err)
 	}
 
-	ciphertext := e.gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return hex.EncodeToString(ciphertext), nil
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return e.primary + ":" + hex.EncodeToString(ciphertext), nil
+}
+
+// Decrypt opens a value sealed with any key in the ring. Values written
+// before key IDs were stored are tried against each key in turn.
+func (e *Encryptor) Decrypt(ciphertext string) (string, error) {
+	id, body, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		var err error
+		for _, id := range e.order {
+			var plaintext string
+			if plaintext, err = open(e.keys[id], ciphertext); err == nil {
+				return plaintext, nil
+			}
+		}
+		return "", err
+	}
+
+	gcm, found := e.keys[id]
+	if !found {
+		return "", fmt.Errorf("decrypting: unknown key ID %q", id)
+	}
+	return open(gcm, body)
+}
+
+// NeedsRotation reports whether ciphertext was sealed with a key other than
+// the primary one.
+func (e *Encryptor) NeedsRotation(ciphertext string) bool {
+	id, _, ok := strings.Cut(ciphertext, ":")
+	return !ok || id != e.primary
 }
 
-func (e *Encryptor) Decrypt(ciphertextHex string) (string, error) {
+func open(gcm cipher.AEAD, ciphertextHex string) (string, error) {
 	ciphertext, err := hex.DecodeString(ciphertextHex)
 	if err != nil {
 		return "", fmt.Errorf("decoding ciphertext: %w", err)
 	}
 
-	nonceSize := e.gcm.NonceSize()
+	nonceSize := gcm.NonceSize()
 	if len(ciphertext) < nonceSize {
 		return "", fmt.Errorf("ciphertext too short")
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := e.gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
 		return "", fmt.Errorf("decrypting: %w", err)
 	}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,3 +50,47 @@ func TestEncryptor(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestEncryptor_KeyRotation(t *testing.T) {
+	oldKey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	newKey := "fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+
+	oldEnc, err := NewEncryptor(oldKey)
+	require.NoError(t, err)
+	sealed, err := oldEnc.Encrypt("prompt")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, KeyID(oldKey)+":"))
+
+	ring, err := NewEncryptor(newKey, oldKey)
+	require.NoError(t, err)
+	assert.Equal(t, KeyID(newKey), ring.PrimaryKeyID())
+
+	t.Run("decrypts values sealed with a previous key", func(t *testing.T) {
+		plaintext, err := ring.Decrypt(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, "prompt", plaintext)
+		assert.True(t, ring.NeedsRotation(sealed))
+	})
+
+	t.Run("encrypts with the primary key", func(t *testing.T) {
+		resealed, err := ring.Encrypt("prompt")
+		require.NoError(t, err)
+		assert.False(t, ring.NeedsRotation(resealed))
+
+		_, err = oldEnc.Decrypt(resealed)
+		assert.ErrorContains(t, err, "unknown key ID")
+	})
+
+	t.Run("decrypts values without a key ID", func(t *testing.T) {
+		_, legacy, _ := strings.Cut(sealed, ":")
+		plaintext, err := ring.Decrypt(legacy)
+		require.NoError(t, err)
+		assert.Equal(t, "prompt", plaintext)
+		assert.True(t, ring.NeedsRotation(legacy))
+	})
+
+	t.Run("invalid previous key", func(t *testing.T) {
+		_, err := NewEncryptor(newKey, "short")
+		assert.ErrorContains(t, err, "previous key 1")
+	})
+}
@@ -3,9 +3,14 @@ package auth
 import (
 	"encoding/json"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 
 	"github.com/aiox-platform/aiox/internal/api"
 	"github.com/aiox-platform/aiox/internal/users"
@@ -27,7 +32,7 @@ func NewHandler(authSvc *Service, userSvc *users.Service) *Handler {
 
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginRequest struct {
@@ -50,11 +55,15 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		api.HandleError(w, api.NewValidationError(err.Error()))
 		return
 	}
+	if unmet := h.authSvc.CheckPasswordStrength(req.Password); len(unmet) > 0 {
+		api.HandleError(w, api.NewWeakPasswordError(unmet))
+		return
+	}
 
 	// Check if email exists
 	exists, err := h.userSvc.ExistsByEmail(r.Context(), req.Email)
 	if err != nil {
-		slog.Error("checking email existence", "error", err)
+		slog.ErrorContext(r.Context(), "checking email existence", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -66,7 +75,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	// Hash password
 	hash, err := HashPassword(req.Password)
 	if err != nil {
-		slog.Error("hashing password", "error", err)
+		slog.ErrorContext(r.Context(), "hashing password", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -74,15 +83,15 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	// Create user
 	user, err := h.userSvc.Create(r.Context(), req.Email, hash)
 	if err != nil {
-		slog.Error("creating user", "error", err)
+		slog.ErrorContext(r.Context(), "creating user", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
 
 	// Generate tokens
-	tokens, err := h.authSvc.GenerateTokens(user.ID.String(), user.Email)
+	tokens, err := h.authSvc.GenerateTokens(user.ID.String(), user.Email, user.IsAdmin, ClientInfoFromRequest(r))
 	if err != nil {
-		slog.Error("generating tokens", "error", err)
+		slog.ErrorContext(r.Context(), "generating tokens", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -102,28 +111,59 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A locked account is refused even with the right password
+	lockedFor, err := h.authSvc.LockedFor(r.Context(), req.Email)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "checking account lock", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if lockedFor > 0 {
+		writeAccountLocked(w, lockedFor)
+		return
+	}
+
 	// Find user
 	user, err := h.userSvc.GetByEmail(r.Context(), req.Email)
 	if err != nil {
-		slog.Error("getting user by email", "error", err)
+		slog.ErrorContext(r.Context(), "getting user by email", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
 	if user == nil {
-		api.HandleError(w, api.ErrInvalidCredentials)
+		h.loginFailed(w, r, req.Email, uuid.Nil)
 		return
 	}
 
 	// Verify password
 	if err := ComparePassword(user.PasswordHash, req.Password); err != nil {
-		api.HandleError(w, api.ErrInvalidCredentials)
+		h.loginFailed(w, r, req.Email, user.ID)
+		return
+	}
+	if err := h.authSvc.ResetLoginFailures(r.Context(), req.Email); err != nil {
+		slog.WarnContext(r.Context(), "resetting failed logins", "error", err, "user_id", user.ID)
+	}
+
+	// With 2FA on, the password only earns an MFA token to exchange at /2fa/login
+	if user.TwoFactorEnabled() {
+		mfaToken, err := h.authSvc.CreateMFAToken(r.Context(), user.ID.String())
+		if err != nil {
+			slog.ErrorContext(r.Context(), "creating mfa token", "error", err)
+			api.HandleError(w, api.ErrInternalServer)
+			return
+		}
+		api.JSON(w, http.StatusOK, MFAChallenge{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(MFATokenTTL.Seconds()),
+		})
 		return
 	}
 
 	// Generate tokens
-	tokens, err := h.authSvc.GenerateTokens(user.ID.String(), user.Email)
+	tokens, err := h.authSvc.GenerateTokens(user.ID.String(), user.Email, user.IsAdmin, ClientInfoFromRequest(r))
 	if err != nil {
-		slog.Error("generating tokens", "error", err)
+		slog.ErrorContext(r.Context(), "generating tokens", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -131,6 +171,25 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	api.JSON(w, http.StatusOK, tokens)
 }
 
+// loginFailed records a failed login and responds 423 if it locked the
+// account, 401 otherwise.
+func (h *Handler) loginFailed(w http.ResponseWriter, r *http.Request, email string, userID uuid.UUID) {
+	lockedFor, err := h.authSvc.RecordLoginFailure(r.Context(), email, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "recording failed login", "error", err)
+	}
+	if lockedFor > 0 {
+		writeAccountLocked(w, lockedFor)
+		return
+	}
+	api.HandleError(w, api.ErrInvalidCredentials)
+}
+
+func writeAccountLocked(w http.ResponseWriter, lockedFor time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedFor.Seconds()))))
+	api.HandleError(w, api.ErrAccountLocked)
+}
+
 func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 	var req RefreshRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -145,7 +204,7 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 
 	tokens, err := h.authSvc.RefreshTokens(req.RefreshToken)
 	if err != nil {
-		slog.Error("refreshing tokens", "error", err)
+		slog.ErrorContext(r.Context(), "refreshing tokens", "error", err)
 		api.HandleError(w, api.ErrInvalidToken)
 		return
 	}
@@ -161,10 +220,40 @@ func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.authSvc.Logout(claims.UserID); err != nil {
-		slog.Error("logging out", "error", err)
+		slog.ErrorContext(r.Context(), "logging out", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
 
 	api.JSONMessage(w, http.StatusOK, "logged out successfully")
 }
+
+// RevokeSessions revokes every refresh token of the user in the path, so
+// they must log in again once their access tokens expire. Admin only.
+func (h *Handler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid user ID"))
+		return
+	}
+
+	user, err := h.userSvc.GetByID(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "fetching user for session revocation", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if user == nil {
+		api.HandleError(w, api.NewNotFoundError("user not found"))
+		return
+	}
+
+	if err := h.authSvc.Logout(user.ID.String()); err != nil {
+		slog.ErrorContext(r.Context(), "revoking sessions", "error", err, "user_id", user.ID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "sessions revoked by admin", "user_id", user.ID, "admin_id", GetUserClaims(r.Context()).UserID)
+	api.JSONMessage(w, http.StatusOK, "sessions revoked")
+}
@@ -15,14 +15,24 @@ type TokenPair struct {
 }
 
 type AccessClaims struct {
-	UserID string `json:"uid"`
-	Email  string `json:"email"`
+	UserID string   `json:"uid"`
+	Email  string   `json:"email"`
+	Scopes []string `json:"scopes,omitempty"`
+	// IsAdmin grants the admin endpoints. It is never set for API keys.
+	IsAdmin bool `json:"is_admin,omitempty"`
+	// SessionID identifies the login session the token was issued for.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type RefreshClaims struct {
 	UserID  string `json:"uid"`
 	TokenID string `json:"tid"`
+	// IsAdmin is carried over to the access token issued on refresh.
+	IsAdmin bool `json:"is_admin,omitempty"`
+	// SessionID stays the same across rotations. Tokens issued before
+	// sessions existed omit it; their TokenID is the session ID.
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -42,13 +52,23 @@ func NewJWTManager(accessSecret, refreshSecret string, accessExpiry, refreshExpi
 	}
 }
 
-func (m *JWTManager) GenerateTokenPair(userID, email string) (*TokenPair, string, error) {
+// GenerateTokenPair issues an access and refresh token for the session and
+// returns the refresh token's ID. An empty sessionID starts a new session
+// whose ID is the refresh token's ID.
+func (m *JWTManager) GenerateTokenPair(userID, email string, isAdmin bool, sessionID string) (*TokenPair, string, error) {
 	now := time.Now()
+	tokenID := uuid.New().String()
+	if sessionID == "" {
+		sessionID = tokenID
+	}
 
 	// Access token
 	accessClaims := AccessClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		Scopes:    AllScopes,
+		IsAdmin:   isAdmin,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -62,10 +82,11 @@ func (m *JWTManager) GenerateTokenPair(userID, email string) (*TokenPair, string
 	}
 
 	// Refresh token
-	tokenID := uuid.New().String()
 	refreshClaims := RefreshClaims{
-		UserID:  userID,
-		TokenID: tokenID,
+		UserID:    userID,
+		TokenID:   tokenID,
+		IsAdmin:   isAdmin,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
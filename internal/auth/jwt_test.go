@@ -12,7 +12,7 @@ func TestJWTManager_GenerateAndValidate(t *testing.T) {
 	mgr := NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", 15*time.Minute, 7*24*time.Hour)
 
 	t.Run("generate and validate access token", func(t *testing.T) {
-		pair, tokenID, err := mgr.GenerateTokenPair("user-123", "test@example.com")
+		pair, tokenID, err := mgr.GenerateTokenPair("user-123", "test@example.com", false, "")
 		require.NoError(t, err)
 		assert.NotEmpty(t, pair.AccessToken)
 		assert.NotEmpty(t, pair.RefreshToken)
@@ -26,7 +26,7 @@ func TestJWTManager_GenerateAndValidate(t *testing.T) {
 	})
 
 	t.Run("generate and validate refresh token", func(t *testing.T) {
-		pair, _, err := mgr.GenerateTokenPair("user-456", "user@example.com")
+		pair, _, err := mgr.GenerateTokenPair("user-456", "user@example.com", false, "")
 		require.NoError(t, err)
 
 		claims, err := mgr.ValidateRefreshToken(pair.RefreshToken)
@@ -35,20 +35,33 @@ func TestJWTManager_GenerateAndValidate(t *testing.T) {
 		assert.NotEmpty(t, claims.TokenID)
 	})
 
+	t.Run("admin flag survives refresh", func(t *testing.T) {
+		pair, _, err := mgr.GenerateTokenPair("user-admin", "admin@example.com", true, "")
+		require.NoError(t, err)
+
+		access, err := mgr.ValidateAccessToken(pair.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, access.IsAdmin)
+
+		refresh, err := mgr.ValidateRefreshToken(pair.RefreshToken)
+		require.NoError(t, err)
+		assert.True(t, refresh.IsAdmin)
+	})
+
 	t.Run("invalid token fails validation", func(t *testing.T) {
 		_, err := mgr.ValidateAccessToken("invalid-token")
 		assert.Error(t, err)
 	})
 
 	t.Run("access token cant validate as refresh", func(t *testing.T) {
-		pair, _, _ := mgr.GenerateTokenPair("user-789", "x@x.com")
+		pair, _, _ := mgr.GenerateTokenPair("user-789", "x@x.com", false, "")
 		_, err := mgr.ValidateRefreshToken(pair.AccessToken)
 		assert.Error(t, err)
 	})
 
 	t.Run("expired token fails", func(t *testing.T) {
 		shortMgr := NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", -1*time.Second, -1*time.Second)
-		pair, _, err := shortMgr.GenerateTokenPair("user-exp", "exp@test.com")
+		pair, _, err := shortMgr.GenerateTokenPair("user-exp", "exp@test.com", false, "")
 		require.NoError(t, err)
 
 		_, err = shortMgr.ValidateAccessToken(pair.AccessToken)
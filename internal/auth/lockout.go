@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// LockoutPolicy locks an account for Duration after MaxAttempts failed logins
+// within Window. MaxAttempts of zero or less disables lockout.
+type LockoutPolicy struct {
+	MaxAttempts int
+	Window      time.Duration
+	Duration    time.Duration
+}
+
+// DefaultLockoutPolicy locks an account for 15 minutes after 5 failed logins
+// within 15 minutes.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{MaxAttempts: 5, Window: 15 * time.Minute, Duration: 15 * time.Minute}
+}
+
+// Failures are tracked per email rather than per user, so unknown emails lock
+// the same way and lockout does not reveal which accounts exist.
+func loginFailuresKey(email string) string {
+	return "login_failures:" + strings.ToLower(email)
+}
+
+func loginLockKey(email string) string {
+	return "login_lock:" + strings.ToLower(email)
+}
+
+// LockedFor returns how much longer logins for email are locked, or zero.
+func (s *Service) LockedFor(ctx context.Context, email string) (time.Duration, error) {
+	if s.lockout.MaxAttempts <= 0 {
+		return 0, nil
+	}
+	ttl, err := s.redisClient.PTTL(ctx, loginLockKey(email)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("checking account lock: %w", err)
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordLoginFailure counts a failed login for email. When the failure
+// reaches the policy's limit the account is locked, an account_locked audit
+// event is emitted for userID (uuid.Nil for unknown emails), and the lock
+// duration is returned; otherwise it returns zero.
+func (s *Service) RecordLoginFailure(ctx context.Context, email string, userID uuid.UUID) (time.Duration, error) {
+	policy := s.lockout
+	if policy.MaxAttempts <= 0 {
+		return 0, nil
+	}
+
+	key := loginFailuresKey(email)
+	failures, err := s.redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("counting failed login: %w", err)
+	}
+	if failures == 1 {
+		if err := s.redisClient.Expire(ctx, key, policy.Window).Err(); err != nil {
+			return 0, fmt.Errorf("counting failed login: %w", err)
+		}
+	}
+	if failures < int64(policy.MaxAttempts) {
+		return 0, nil
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, loginLockKey(email), userID.String(), policy.Duration)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("locking account: %w", err)
+	}
+
+	slog.Warn("account locked after failed logins", "user_id", userID, "failures", failures)
+	if s.audit != nil && userID != uuid.Nil {
+		event := inats.AuditEvent{
+			OwnerUserID:  userID,
+			EventType:    "account_locked",
+			Severity:     "warn",
+			ResourceType: "user",
+			ResourceID:   userID.String(),
+			Details:      fmt.Sprintf("Locked for %s after %d failed logins", policy.Duration, failures),
+			Timestamp:    time.Now().UTC(),
+		}
+		if err := s.audit.PublishAuditEvent(ctx, event); err != nil {
+			slog.Error("publishing audit event", "error", err, "event_type", event.EventType)
+		}
+	}
+	return policy.Duration, nil
+}
+
+// ResetLoginFailures clears the failed-login count for email after a
+// successful login.
+func (s *Service) ResetLoginFailures(ctx context.Context, email string) error {
+	return s.redisClient.Del(ctx, loginFailuresKey(email)).Err()
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockout_LocksAfterMaxAttempts(t *testing.T) {
+	svc, _ := newSessionService(t)
+	pub := &recordingPublisher{}
+	svc.SetAuditPublisher(pub)
+	svc.SetLockoutPolicy(LockoutPolicy{MaxAttempts: 3, Window: time.Minute, Duration: 10 * time.Minute})
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		lockedFor, err := svc.RecordLoginFailure(ctx, "u@example.com", userID)
+		require.NoError(t, err)
+		assert.Zero(t, lockedFor)
+	}
+	lockedFor, err := svc.LockedFor(ctx, "u@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+
+	lockedFor, err = svc.RecordLoginFailure(ctx, "u@example.com", userID)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, lockedFor)
+
+	lockedFor, err = svc.LockedFor(ctx, "U@Example.com")
+	require.NoError(t, err)
+	assert.Positive(t, lockedFor, "lock applies regardless of email case")
+
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "account_locked", pub.events[0].EventType)
+	assert.Equal(t, userID, pub.events[0].OwnerUserID)
+}
+
+func TestLockout_LockExpires(t *testing.T) {
+	svc, mr := newSessionService(t)
+	svc.SetLockoutPolicy(LockoutPolicy{MaxAttempts: 1, Window: time.Minute, Duration: time.Minute})
+	ctx := context.Background()
+
+	_, err := svc.RecordLoginFailure(ctx, "u@example.com", uuid.New())
+	require.NoError(t, err)
+	mr.FastForward(2 * time.Minute)
+
+	lockedFor, err := svc.LockedFor(ctx, "u@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+}
+
+func TestLockout_FailuresOutsideWindowDoNotCount(t *testing.T) {
+	svc, mr := newSessionService(t)
+	svc.SetLockoutPolicy(LockoutPolicy{MaxAttempts: 2, Window: time.Minute, Duration: time.Minute})
+	ctx := context.Background()
+
+	_, err := svc.RecordLoginFailure(ctx, "u@example.com", uuid.Nil)
+	require.NoError(t, err)
+	mr.FastForward(2 * time.Minute)
+
+	lockedFor, err := svc.RecordLoginFailure(ctx, "u@example.com", uuid.Nil)
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+}
+
+func TestLockout_ResetOnSuccess(t *testing.T) {
+	svc, _ := newSessionService(t)
+	svc.SetLockoutPolicy(LockoutPolicy{MaxAttempts: 2, Window: time.Minute, Duration: time.Minute})
+	ctx := context.Background()
+
+	_, err := svc.RecordLoginFailure(ctx, "u@example.com", uuid.Nil)
+	require.NoError(t, err)
+	require.NoError(t, svc.ResetLoginFailures(ctx, "u@example.com"))
+
+	lockedFor, err := svc.RecordLoginFailure(ctx, "u@example.com", uuid.Nil)
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor)
+}
+
+func TestLockout_UnknownEmailLocksWithoutAudit(t *testing.T) {
+	svc, _ := newSessionService(t)
+	pub := &recordingPublisher{}
+	svc.SetAuditPublisher(pub)
+	svc.SetLockoutPolicy(LockoutPolicy{MaxAttempts: 1, Window: time.Minute, Duration: time.Minute})
+
+	lockedFor, err := svc.RecordLoginFailure(context.Background(), "nobody@example.com", uuid.Nil)
+	require.NoError(t, err)
+	assert.Positive(t, lockedFor)
+	assert.Empty(t, pub.events)
+}
+
+func TestLockout_Disabled(t *testing.T) {
+	svc, _ := newSessionService(t)
+	svc.SetLockoutPolicy(LockoutPolicy{MaxAttempts: -1})
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		lockedFor, err := svc.RecordLoginFailure(ctx, "u@example.com", uuid.Nil)
+		require.NoError(t, err)
+		assert.Zero(t, lockedFor)
+	}
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Mailer delivers account emails. Implementations plug in a real transport;
+// LogMailer is the default.
+type Mailer interface {
+	SendPasswordReset(ctx context.Context, email, token string) error
+}
+
+// LogMailer logs outgoing mail instead of sending it. The token is omitted
+// from the log so it cannot be harvested from log aggregation.
+type LogMailer struct{}
+
+func (LogMailer) SendPasswordReset(_ context.Context, email, _ string) error {
+	slog.Info("password reset requested; no mailer configured", "email", email)
+	return nil
+}
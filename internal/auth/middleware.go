@@ -6,13 +6,16 @@ import (
 	"strings"
 
 	"github.com/aiox-platform/aiox/internal/api"
+	mw "github.com/aiox-platform/aiox/internal/middleware"
 )
 
 type contextKey string
 
 const UserClaimsKey contextKey = "user_claims"
 
-func Middleware(svc *Service) func(http.Handler) http.Handler {
+// Middleware authenticates requests with either "Authorization: Bearer <jwt>"
+// or, when apiKeys is non-nil, "Authorization: ApiKey <key>".
+func Middleware(svc *Service, apiKeys *APIKeyService) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -22,23 +25,88 @@ func Middleware(svc *Service) func(http.Handler) http.Handler {
 			}
 
 			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+			if len(parts) != 2 {
 				api.HandleError(w, api.ErrUnauthorized)
 				return
 			}
 
-			claims, err := svc.jwt.ValidateAccessToken(parts[1])
+			var claims *AccessClaims
+			var err error
+			switch {
+			case strings.EqualFold(parts[0], "bearer"):
+				claims, err = svc.jwt.ValidateAccessToken(parts[1])
+			case strings.EqualFold(parts[0], "apikey") && apiKeys != nil:
+				claims, err = apiKeys.Authenticate(r.Context(), parts[1])
+			default:
+				api.HandleError(w, api.ErrUnauthorized)
+				return
+			}
 			if err != nil {
 				api.HandleError(w, api.ErrInvalidToken)
 				return
 			}
 
+			mw.SetLogUser(r.Context(), claims.UserID)
 			ctx := context.WithValue(r.Context(), UserClaimsKey, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// RequireScope rejects requests whose claims do not carry scope with 403.
+// It must run after Middleware.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims := GetUserClaims(r.Context())
+			if claims == nil {
+				api.HandleError(w, api.ErrUnauthorized)
+				return
+			}
+			if !claims.HasScope(scope) {
+				api.HandleError(w, api.NewForbiddenError("missing required scope: "+scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAdmin rejects requests from non-admins with 403. API keys never
+// carry admin rights. It must run after Middleware.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := GetUserClaims(r.Context())
+		if claims == nil {
+			api.HandleError(w, api.ErrUnauthorized)
+			return
+		}
+		if !claims.IsAdmin {
+			api.HandleError(w, api.NewForbiddenError("admin access required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireSession rejects requests that don't come from a login session, such
+// as API keys, with 403. It guards the routes that manage the account's own
+// credentials and sessions. It must run after Middleware.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := GetUserClaims(r.Context())
+		if claims == nil {
+			api.HandleError(w, api.ErrUnauthorized)
+			return
+		}
+		if claims.SessionID == "" {
+			api.HandleError(w, api.NewForbiddenError("a login session is required; API keys are not accepted"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func GetUserClaims(ctx context.Context) *AccessClaims {
 	claims, _ := ctx.Value(UserClaimsKey).(*AccessClaims)
 	return claims
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = parseCommonPasswords(commonPasswordsFile)
+
+func parseCommonPasswords(raw string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
+
+// PasswordPolicy is the set of rules new passwords must satisfy.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	RejectCommon  bool
+}
+
+// DefaultPasswordPolicy requires 8 characters and rejects common passwords.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 8, RejectCommon: true}
+}
+
+// ValidatePasswordStrength returns the requirements of policy that password
+// does not meet, or nil if it satisfies all of them.
+func ValidatePasswordStrength(password string, policy PasswordPolicy) []string {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var unmet []string
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		unmet = append(unmet, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+	if policy.RequireUpper && !upper {
+		unmet = append(unmet, "an uppercase letter")
+	}
+	if policy.RequireLower && !lower {
+		unmet = append(unmet, "a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		unmet = append(unmet, "a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		unmet = append(unmet, "a symbol")
+	}
+	if policy.RejectCommon {
+		if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+			unmet = append(unmet, "not a commonly used password")
+		}
+	}
+	return unmet
+}
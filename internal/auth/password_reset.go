@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+	"github.com/aiox-platform/aiox/internal/users"
+)
+
+// AuditPublisher publishes audit events; satisfied by *nats.Publisher.
+type AuditPublisher interface {
+	PublishAuditEvent(ctx context.Context, event inats.AuditEvent) error
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// PasswordResetHandler handles the forgotten-password flow.
+type PasswordResetHandler struct {
+	authSvc  *Service
+	userSvc  *users.Service
+	mailer   Mailer
+	audit    AuditPublisher
+	validate *validator.Validate
+}
+
+// NewPasswordResetHandler creates a password reset handler. A nil mailer
+// falls back to LogMailer; a nil audit publisher disables audit events.
+func NewPasswordResetHandler(authSvc *Service, userSvc *users.Service, mailer Mailer, audit AuditPublisher) *PasswordResetHandler {
+	if mailer == nil {
+		mailer = LogMailer{}
+	}
+	return &PasswordResetHandler{
+		authSvc:  authSvc,
+		userSvc:  userSvc,
+		mailer:   mailer,
+		audit:    audit,
+		validate: validator.New(),
+	}
+}
+
+// Request issues a reset token and mails it. It responds identically whether
+// or not the email is registered, so it cannot be used to enumerate accounts.
+func (h *PasswordResetHandler) Request(w http.ResponseWriter, r *http.Request) {
+	var req PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	const msg = "if the email is registered, a password reset link has been sent"
+
+	user, err := h.userSvc.GetByEmail(r.Context(), req.Email)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "getting user by email", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if user == nil {
+		api.JSONMessage(w, http.StatusOK, msg)
+		return
+	}
+
+	token, err := h.authSvc.CreatePasswordResetToken(r.Context(), user.ID.String())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "creating password reset token", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	if err := h.mailer.SendPasswordReset(r.Context(), user.Email, token); err != nil {
+		slog.ErrorContext(r.Context(), "sending password reset email", "error", err, "user_id", user.ID)
+	}
+
+	h.publishAudit(r.Context(), user.ID, "password_reset_requested")
+	api.JSONMessage(w, http.StatusOK, msg)
+}
+
+// Confirm validates a reset token and sets the new password. All refresh
+// tokens are revoked so existing sessions must log in again.
+func (h *PasswordResetHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	var req PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+	// Checked before consuming the token so a rejected password can be retried
+	if unmet := h.authSvc.CheckPasswordStrength(req.NewPassword); len(unmet) > 0 {
+		api.HandleError(w, api.NewWeakPasswordError(unmet))
+		return
+	}
+
+	userID, err := h.authSvc.ConsumePasswordResetToken(r.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidResetToken) {
+			api.HandleError(w, api.NewBadRequestError(err.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "consuming password reset token", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError(ErrInvalidResetToken.Error()))
+		return
+	}
+
+	hash, err := HashPassword(req.NewPassword)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "hashing password", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	if err := h.userSvc.UpdatePassword(r.Context(), id, hash); err != nil {
+		slog.ErrorContext(r.Context(), "updating password", "error", err, "user_id", id)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	if err := h.authSvc.Logout(userID); err != nil {
+		slog.WarnContext(r.Context(), "revoking refresh tokens after password reset", "error", err, "user_id", id)
+	}
+
+	h.publishAudit(r.Context(), id, "password_reset_completed")
+	api.JSONMessage(w, http.StatusOK, "password has been reset")
+}
+
+func (h *PasswordResetHandler) publishAudit(ctx context.Context, userID uuid.UUID, eventType string) {
+	if h.audit == nil {
+		return
+	}
+	event := inats.AuditEvent{
+		OwnerUserID:  userID,
+		EventType:    eventType,
+		Severity:     "info",
+		ResourceType: "user",
+		ResourceID:   userID.String(),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := h.audit.PublishAuditEvent(ctx, event); err != nil {
+		slog.Error("publishing audit event", "error", err, "event_type", eventType)
+	}
+}
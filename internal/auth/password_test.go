@@ -27,3 +27,37 @@ func TestComparePassword(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestValidatePasswordStrength(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 12, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, RejectCommon: true}
+
+	tests := []struct {
+		name     string
+		password string
+		policy   PasswordPolicy
+		unmet    []string
+	}{
+		{"default accepts long password", "tangerine-kettle", DefaultPasswordPolicy(), nil},
+		{"default rejects short password", "abc", DefaultPasswordPolicy(), []string{"at least 8 characters"}},
+		{"common password rejected case-insensitively", "PassWord123", DefaultPasswordPolicy(), []string{"not a commonly used password"}},
+		{"common password allowed when disabled", "password123", PasswordPolicy{MinLength: 8}, nil},
+		{"strict accepts complex password", "Tangerine-Kettle-42", strict, nil},
+		{"strict lists every unmet rule", "kettle", strict, []string{
+			"at least 12 characters", "an uppercase letter", "a digit", "a symbol",
+		}},
+		{"length counts characters not bytes", "ünïcödé", PasswordPolicy{MinLength: 7}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.unmet, ValidatePasswordStrength(tt.password, tt.policy))
+		})
+	}
+}
+
+func TestCommonPasswordsLoaded(t *testing.T) {
+	assert.Contains(t, commonPasswords, "123456")
+	assert.NotContains(t, commonPasswords, "", "comments and blank lines are skipped")
+	for p := range commonPasswords {
+		assert.NotContains(t, p, "#")
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import "slices"
+
+// Scopes granted to access tokens and API keys.
+const (
+	ScopeAgentsRead     = "agents:read"
+	ScopeAgentsWrite    = "agents:write"
+	ScopeMemoriesRead   = "memories:read"
+	ScopeMemoriesWrite  = "memories:write"
+	ScopeGovernanceRead = "governance:read"
+	ScopeWebhooksRead   = "webhooks:read"
+	ScopeWebhooksWrite  = "webhooks:write"
+)
+
+// AllScopes is the full scope set carried by access tokens issued on login.
+var AllScopes = []string{
+	ScopeAgentsRead,
+	ScopeAgentsWrite,
+	ScopeMemoriesRead,
+	ScopeMemoriesWrite,
+	ScopeGovernanceRead,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+}
+
+// HasScope reports whether the claims grant scope.
+func (c *AccessClaims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
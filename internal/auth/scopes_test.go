@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTokenPair_DefaultsToAllScopes(t *testing.T) {
+	mgr := NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", 15*time.Minute, time.Hour)
+	pair, _, err := mgr.GenerateTokenPair("user-1", "u@example.com", false, "")
+	require.NoError(t, err)
+
+	claims, err := mgr.ValidateAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, AllScopes, claims.Scopes)
+}
+
+func TestRequireScope(t *testing.T) {
+	handler := RequireScope(ScopeAgentsWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(claims *AccessClaims) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), UserClaimsKey, claims))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(&AccessClaims{Scopes: AllScopes}))
+	assert.Equal(t, http.StatusForbidden, serve(&AccessClaims{Scopes: []string{ScopeAgentsRead}}))
+	assert.Equal(t, http.StatusForbidden, serve(&AccessClaims{}))
+	assert.Equal(t, http.StatusUnauthorized, serve(nil))
+}
+
+func TestRequireAdmin(t *testing.T) {
+	handler := RequireAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(claims *AccessClaims) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), UserClaimsKey, claims))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(&AccessClaims{IsAdmin: true}))
+	assert.Equal(t, http.StatusForbidden, serve(&AccessClaims{Scopes: AllScopes}))
+	assert.Equal(t, http.StatusUnauthorized, serve(nil))
+}
+
+func TestRequireSession(t *testing.T) {
+	handler := RequireSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(claims *AccessClaims) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		if claims != nil {
+			req = req.WithContext(context.WithValue(req.Context(), UserClaimsKey, claims))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(&AccessClaims{Scopes: AllScopes, SessionID: "s1"}))
+	assert.Equal(t, http.StatusForbidden, serve(&AccessClaims{Scopes: AllScopes}), "API keys carry no session")
+	assert.Equal(t, http.StatusUnauthorized, serve(nil))
+}
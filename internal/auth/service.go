@@ -2,76 +2,203 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
 )
 
+// PasswordResetTTL is how long a password reset token stays valid.
+const PasswordResetTTL = time.Hour
+
+var ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+
+var errRefreshTokenRevoked = errors.New("refresh token revoked")
+
+// ErrRefreshTokenReused is returned when an already rotated refresh token is
+// presented again. The token's whole family is revoked when this happens.
+var ErrRefreshTokenReused = errors.New("refresh token reused")
+
 type Service struct {
 	jwt         *JWTManager
 	redisClient *redis.Client
+	audit       AuditPublisher
+	policy      PasswordPolicy
+	lockout     LockoutPolicy
 }
 
 func NewService(jwt *JWTManager, redisClient *redis.Client) *Service {
 	return &Service{
 		jwt:         jwt,
 		redisClient: redisClient,
+		policy:      DefaultPasswordPolicy(),
+		lockout:     DefaultLockoutPolicy(),
 	}
 }
 
-func (s *Service) GenerateTokens(userID, email string) (*TokenPair, error) {
-	pair, tokenID, err := s.jwt.GenerateTokenPair(userID, email)
+// SetPasswordPolicy replaces DefaultPasswordPolicy. It must be called before
+// the service is used.
+func (s *Service) SetPasswordPolicy(p PasswordPolicy) {
+	s.policy = p
+}
+
+// SetLockoutPolicy replaces DefaultLockoutPolicy. It must be called before
+// the service is used.
+func (s *Service) SetLockoutPolicy(p LockoutPolicy) {
+	s.lockout = p
+}
+
+// CheckPasswordStrength returns the password policy requirements password
+// does not meet.
+func (s *Service) CheckPasswordStrength(password string) []string {
+	return ValidatePasswordStrength(password, s.policy)
+}
+
+// SetAuditPublisher enables refresh_token_reuse and account_locked audit
+// events. It must be called before the service is used.
+func (s *Service) SetAuditPublisher(p AuditPublisher) {
+	s.audit = p
+}
+
+// GenerateTokens starts a new session for the user and issues its first
+// token pair. client is shown in the session list.
+func (s *Service) GenerateTokens(userID, email string, isAdmin bool, client ClientInfo) (*TokenPair, error) {
+	pair, tokenID, err := s.jwt.GenerateTokenPair(userID, email, isAdmin, "")
 	if err != nil {
 		return nil, err
 	}
 
-	// Store refresh token ID in Redis
-	key := fmt.Sprintf("refresh:%s:%s", userID, tokenID)
-	err = s.redisClient.Set(context.Background(), key, "1", s.jwt.RefreshExpiry()).Err()
-	if err != nil {
+	now := time.Now().UTC()
+	record := sessionRecord{
+		TokenID:   tokenID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		CreatedAt: now,
+		LastUsed:  now,
+	}
+	if err := s.storeSession(context.Background(), userID, tokenID, record); err != nil {
 		return nil, fmt.Errorf("storing refresh token: %w", err)
 	}
 
 	return pair, nil
 }
 
+// RefreshTokens rotates the refresh token within its session, which is the
+// token family. Only the family's latest refresh token is accepted; rotated
+// tokens are remembered, and presenting one again is treated as theft: the
+// whole family is revoked and ErrRefreshTokenReused is returned.
 func (s *Service) RefreshTokens(refreshToken string) (*TokenPair, error) {
 	claims, err := s.jwt.ValidateRefreshToken(refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
-	// Check if refresh token exists in Redis
-	key := fmt.Sprintf("refresh:%s:%s", claims.UserID, claims.TokenID)
-	exists, err := s.redisClient.Exists(context.Background(), key).Result()
-	if err != nil {
-		return nil, fmt.Errorf("checking refresh token: %w", err)
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		sessionID = claims.TokenID
 	}
-	if exists == 0 {
-		return nil, fmt.Errorf("refresh token revoked")
+	ctx := context.Background()
+	key := sessionKey(claims.UserID, sessionID)
+	usedKey := usedTokensKey(claims.UserID, sessionID)
+
+	var pair *TokenPair
+	err = s.redisClient.Watch(ctx, func(tx *redis.Tx) error {
+		raw, err := tx.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return errRefreshTokenRevoked
+		}
+		if err != nil {
+			return fmt.Errorf("checking refresh token: %w", err)
+		}
+		record := parseSessionRecord(raw, claims)
+		if record.TokenID != claims.TokenID {
+			used, err := tx.SIsMember(ctx, usedKey, claims.TokenID).Result()
+			if err != nil {
+				return fmt.Errorf("checking used refresh tokens: %w", err)
+			}
+			if used {
+				return ErrRefreshTokenReused
+			}
+			return errRefreshTokenRevoked
+		}
+
+		var newTokenID string
+		pair, newTokenID, err = s.jwt.GenerateTokenPair(claims.UserID, "", claims.IsAdmin, sessionID)
+		if err != nil {
+			return err
+		}
+		record.TokenID = newTokenID
+		record.LastUsed = time.Now().UTC()
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("encoding session: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, s.jwt.RefreshExpiry())
+			pipe.SAdd(ctx, usedKey, claims.TokenID)
+			pipe.Expire(ctx, usedKey, s.jwt.RefreshExpiry())
+			return nil
+		})
+		if errors.Is(err, redis.TxFailedErr) {
+			// A concurrent refresh rotated the token first.
+			return errRefreshTokenRevoked
+		}
+		if err != nil {
+			return fmt.Errorf("storing new refresh token: %w", err)
+		}
+		return nil
+	}, key)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		s.revokeFamily(ctx, claims.UserID, sessionID)
+		return nil, err
 	}
-
-	// Revoke old refresh token
-	s.redisClient.Del(context.Background(), key)
-
-	// Generate new token pair
-	// We need email from the original token - fetch from new generation
-	pair, newTokenID, err := s.jwt.GenerateTokenPair(claims.UserID, "")
 	if err != nil {
 		return nil, err
 	}
 
-	// Store new refresh token
-	newKey := fmt.Sprintf("refresh:%s:%s", claims.UserID, newTokenID)
-	err = s.redisClient.Set(context.Background(), newKey, "1", s.jwt.RefreshExpiry()).Err()
-	if err != nil {
-		return nil, fmt.Errorf("storing new refresh token: %w", err)
+	return pair, nil
+}
+
+// revokeFamily deletes a session after one of its rotated refresh tokens was
+// replayed, so neither the attacker nor the victim can refresh again.
+func (s *Service) revokeFamily(ctx context.Context, userID, sessionID string) {
+	slog.Warn("refresh token reuse detected, revoking token family", "user_id", userID, "session_id", sessionID)
+	if err := s.redisClient.Del(ctx, sessionKey(userID, sessionID), usedTokensKey(userID, sessionID)).Err(); err != nil {
+		slog.Error("revoking token family", "error", err, "user_id", userID, "session_id", sessionID)
 	}
 
-	return pair, nil
+	if s.audit == nil {
+		return
+	}
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return
+	}
+	event := inats.AuditEvent{
+		OwnerUserID:  ownerID,
+		EventType:    "refresh_token_reuse",
+		Severity:     "error",
+		ResourceType: "user",
+		ResourceID:   userID,
+		Details:      "Rotated refresh token replayed; revoked session " + sessionID,
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := s.audit.PublishAuditEvent(ctx, event); err != nil {
+		slog.Error("publishing audit event", "error", err, "event_type", event.EventType)
+	}
 }
 
+// Logout revokes all of the user's refresh tokens. Access tokens already
+// issued stay valid until they expire.
 func (s *Service) Logout(userID string) error {
 	// Delete all refresh tokens for this user
 	pattern := fmt.Sprintf("refresh:%s:*", userID)
@@ -86,13 +213,35 @@ func (s *Service) ValidateAccessToken(token string) (*AccessClaims, error) {
 	return s.jwt.ValidateAccessToken(token)
 }
 
-// StoreRefreshTokenWithExpiry stores a refresh token with a specific TTL.
-// Used by the handler when email is available.
-func (s *Service) StoreRefreshToken(userID, tokenID string, expiry time.Duration) error {
-	key := fmt.Sprintf("refresh:%s:%s", userID, tokenID)
-	return s.redisClient.Set(context.Background(), key, "1", expiry).Err()
-}
-
 func (s *Service) JWT() *JWTManager {
 	return s.jwt
 }
+
+// CreatePasswordResetToken issues a single-use reset token for the user.
+func (s *Service) CreatePasswordResetToken(ctx context.Context, userID string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating reset token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	key := fmt.Sprintf("pwreset:%s", token)
+	if err := s.redisClient.Set(ctx, key, userID, PasswordResetTTL).Err(); err != nil {
+		return "", fmt.Errorf("storing reset token: %w", err)
+	}
+	return token, nil
+}
+
+// ConsumePasswordResetToken returns the user ID for token and deletes it,
+// so each token can be used at most once.
+func (s *Service) ConsumePasswordResetToken(ctx context.Context, token string) (string, error) {
+	key := fmt.Sprintf("pwreset:%s", token)
+	userID, err := s.redisClient.GetDel(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrInvalidResetToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("consuming reset token: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	mw "github.com/aiox-platform/aiox/internal/middleware"
+)
+
+// ErrSessionNotFound is returned when revoking a session that does not exist.
+var ErrSessionNotFound = errors.New("session not found")
+
+// ClientInfo describes the client that started a session.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// ClientInfoFromRequest reads the client's user agent and IP from r.
+func ClientInfoFromRequest(r *http.Request) ClientInfo {
+	return ClientInfo{UserAgent: r.UserAgent(), IP: mw.ClientIP(r)}
+}
+
+// Session is a login and the refresh tokens rotated from it.
+type Session struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used"`
+	// Current marks the session of the access token making the request.
+	Current bool `json:"current"`
+}
+
+// sessionRecord is the Redis value kept per session under
+// refresh:{userID}:{sessionID}. TokenID is the only refresh token accepted.
+type sessionRecord struct {
+	TokenID   string    `json:"token_id"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+func sessionKey(userID, sessionID string) string {
+	return fmt.Sprintf("refresh:%s:%s", userID, sessionID)
+}
+
+// usedTokensKey holds the IDs of the session's rotated refresh tokens, used
+// to detect replays.
+func usedTokensKey(userID, sessionID string) string {
+	return fmt.Sprintf("refresh_used:%s:%s", userID, sessionID)
+}
+
+func (s *Service) storeSession(ctx context.Context, userID, sessionID string, record sessionRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encoding session: %w", err)
+	}
+	return s.redisClient.Set(ctx, sessionKey(userID, sessionID), data, s.jwt.RefreshExpiry()).Err()
+}
+
+// parseSessionRecord decodes a stored session. Refresh tokens issued before
+// sessions existed are stored as "1" under their token ID; they read as a
+// session without client metadata.
+func parseSessionRecord(raw string, claims *RefreshClaims) sessionRecord {
+	var record sessionRecord
+	if err := json.Unmarshal([]byte(raw), &record); err == nil && record.TokenID != "" {
+		return record
+	}
+	record = sessionRecord{TokenID: claims.TokenID}
+	if claims.IssuedAt != nil {
+		record.CreatedAt = claims.IssuedAt.UTC()
+		record.LastUsed = record.CreatedAt
+	}
+	return record
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+// currentID is the session of the caller's access token.
+func (s *Service) ListSessions(ctx context.Context, userID, currentID string) ([]Session, error) {
+	prefix := sessionKey(userID, "")
+	var keys []string
+	iter := s.redisClient.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("scanning sessions: %w", err)
+	}
+
+	sessions := make([]Session, 0, len(keys))
+	if len(keys) == 0 {
+		return sessions, nil
+	}
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("reading sessions: %w", err)
+	}
+	for i, v := range values {
+		raw, ok := v.(string)
+		if !ok {
+			continue // expired between SCAN and MGET
+		}
+		id := strings.TrimPrefix(keys[i], prefix)
+		var record sessionRecord
+		_ = json.Unmarshal([]byte(raw), &record)
+		sessions = append(sessions, Session{
+			ID:        id,
+			UserAgent: record.UserAgent,
+			IP:        record.IP,
+			CreatedAt: record.CreatedAt,
+			LastUsed:  record.LastUsed,
+			Current:   id == currentID,
+		})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsed.After(sessions[j].LastUsed) })
+	return sessions, nil
+}
+
+// SessionStartedAt returns when the user logged in to start the session, or
+// the zero time when the session does not exist.
+func (s *Service) SessionStartedAt(ctx context.Context, userID, sessionID string) (time.Time, error) {
+	raw, err := s.redisClient.Get(ctx, sessionKey(userID, sessionID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading session: %w", err)
+	}
+	var record sessionRecord
+	_ = json.Unmarshal([]byte(raw), &record)
+	return record.CreatedAt, nil
+}
+
+// RevokeSession deletes one of the user's sessions, so its refresh token
+// stops working. Access tokens already issued stay valid until they expire.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	n, err := s.redisClient.Del(ctx, sessionKey(userID, sessionID)).Result()
+	if err != nil {
+		return fmt.Errorf("revoking session: %w", err)
+	}
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// ListSessions returns the caller's active sessions, marking the one the
+// request's access token belongs to.
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims := GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+
+	sessions, err := h.authSvc.ListSessions(r.Context(), claims.UserID, claims.SessionID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing sessions", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession revokes one of the caller's sessions.
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims := GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+
+	if err := h.authSvc.RevokeSession(r.Context(), claims.UserID, chi.URLParam(r, "sessionID")); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			api.HandleError(w, api.NewNotFoundError(err.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "revoking session", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "session revoked")
+}
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+func newSessionService(t *testing.T) (*Service, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	mgr := NewJWTManager("access-secret-32-chars-long!!!!!", "refresh-secret-32-chars-long!!!!", 15*time.Minute, time.Hour)
+	return NewService(mgr, client), mr
+}
+
+func TestSessions_RefreshRotatesWithinSession(t *testing.T) {
+	svc, _ := newSessionService(t)
+	ctx := context.Background()
+
+	pair, err := svc.GenerateTokens("user-1", "u@example.com", false, ClientInfo{UserAgent: "curl/8.0", IP: "10.0.0.1"})
+	require.NoError(t, err)
+	access, err := svc.ValidateAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+
+	sessions, err := svc.ListSessions(ctx, "user-1", access.SessionID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, access.SessionID, sessions[0].ID)
+	assert.Equal(t, "curl/8.0", sessions[0].UserAgent)
+	assert.Equal(t, "10.0.0.1", sessions[0].IP)
+	assert.True(t, sessions[0].Current)
+	created := sessions[0].CreatedAt
+
+	rotated, err := svc.RefreshTokens(pair.RefreshToken)
+	require.NoError(t, err)
+	rotatedAccess, err := svc.ValidateAccessToken(rotated.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, access.SessionID, rotatedAccess.SessionID)
+
+	sessions, err = svc.ListSessions(ctx, "user-1", "")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1, "refresh must not start a new session")
+	assert.Equal(t, created, sessions[0].CreatedAt)
+	assert.False(t, sessions[0].LastUsed.Before(created))
+	assert.False(t, sessions[0].Current)
+
+	_, err = svc.RefreshTokens(pair.RefreshToken)
+	assert.Error(t, err, "the rotated-out refresh token must be rejected")
+}
+
+func TestSessions_RevokeOne(t *testing.T) {
+	svc, _ := newSessionService(t)
+	ctx := context.Background()
+
+	first, err := svc.GenerateTokens("user-1", "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	second, err := svc.GenerateTokens("user-1", "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	claims, err := svc.ValidateAccessToken(first.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.RevokeSession(ctx, "user-1", claims.SessionID))
+	assert.ErrorIs(t, svc.RevokeSession(ctx, "user-1", claims.SessionID), ErrSessionNotFound)
+	assert.ErrorIs(t, svc.RevokeSession(ctx, "user-2", "missing"), ErrSessionNotFound)
+
+	_, err = svc.RefreshTokens(first.RefreshToken)
+	assert.Error(t, err)
+	_, err = svc.RefreshTokens(second.RefreshToken)
+	assert.NoError(t, err, "other sessions are unaffected")
+
+	sessions, err := svc.ListSessions(ctx, "user-1", "")
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
+
+func TestSessions_LegacyRefreshToken(t *testing.T) {
+	svc, mr := newSessionService(t)
+
+	pair, tokenID, err := svc.jwt.GenerateTokenPair("user-1", "", false, "")
+	require.NoError(t, err)
+	require.NoError(t, mr.Set("refresh:user-1:"+tokenID, "1"))
+
+	_, err = svc.RefreshTokens(pair.RefreshToken)
+	assert.NoError(t, err)
+}
+
+type recordingPublisher struct {
+	events []inats.AuditEvent
+}
+
+func (p *recordingPublisher) PublishAuditEvent(_ context.Context, e inats.AuditEvent) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+func TestRefreshTokens_RotationChain(t *testing.T) {
+	svc, _ := newSessionService(t)
+
+	pair, err := svc.GenerateTokens("user-1", "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		pair, err = svc.RefreshTokens(pair.RefreshToken)
+		require.NoError(t, err, "rotation %d", i)
+	}
+}
+
+func TestRefreshTokens_ReuseAfterRotationFails(t *testing.T) {
+	svc, _ := newSessionService(t)
+
+	first, err := svc.GenerateTokens("user-1", "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	_, err = svc.RefreshTokens(first.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = svc.RefreshTokens(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+}
+
+func TestRefreshTokens_ReuseRevokesFamily(t *testing.T) {
+	svc, _ := newSessionService(t)
+	pub := &recordingPublisher{}
+	svc.SetAuditPublisher(pub)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	stolen, err := svc.GenerateTokens(userID.String(), "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	other, err := svc.GenerateTokens(userID.String(), "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	second, err := svc.RefreshTokens(stolen.RefreshToken)
+	require.NoError(t, err)
+	latest, err := svc.RefreshTokens(second.RefreshToken)
+	require.NoError(t, err)
+
+	_, err = svc.RefreshTokens(stolen.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	_, err = svc.RefreshTokens(latest.RefreshToken)
+	assert.Error(t, err, "descendants of the replayed token must be revoked")
+	assert.NotErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = svc.RefreshTokens(other.RefreshToken)
+	assert.NoError(t, err, "other families are unaffected")
+
+	sessions, err := svc.ListSessions(ctx, userID.String(), "")
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "refresh_token_reuse", pub.events[0].EventType)
+	assert.Equal(t, "error", pub.events[0].Severity)
+	assert.Equal(t, userID, pub.events[0].OwnerUserID)
+}
+
+func TestRefreshTokens_AfterLogoutIsNotReuse(t *testing.T) {
+	svc, _ := newSessionService(t)
+	pub := &recordingPublisher{}
+	svc.SetAuditPublisher(pub)
+
+	pair, err := svc.GenerateTokens(uuid.NewString(), "u@example.com", false, ClientInfo{})
+	require.NoError(t, err)
+	claims, err := svc.ValidateAccessToken(pair.AccessToken)
+	require.NoError(t, err)
+	require.NoError(t, svc.Logout(claims.UserID))
+
+	_, err = svc.RefreshTokens(pair.RefreshToken)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Empty(t, pub.events)
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	// RFC 6238 appendix B (SHA-1), truncated to 6 digits.
+	assert.Equal(t, "287082", totpCode(key, 59/totpPeriod))
+	assert.Equal(t, "081804", totpCode(key, 1111111109/totpPeriod))
+	assert.Equal(t, "005924", totpCode(key, 1234567890/totpPeriod))
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	require.NoError(t, err)
+	key, err := totpEncoding.DecodeString(secret)
+	require.NoError(t, err)
+
+	now := time.Unix(1_700_000_000, 0)
+	step := now.Unix() / totpPeriod
+
+	t.Run("current code", func(t *testing.T) {
+		got, ok := validateTOTP(secret, totpCode(key, step), now)
+		assert.True(t, ok)
+		assert.Equal(t, step, got)
+	})
+
+	t.Run("one step of drift", func(t *testing.T) {
+		_, ok := validateTOTP(secret, totpCode(key, step-1), now)
+		assert.True(t, ok)
+	})
+
+	t.Run("too old", func(t *testing.T) {
+		_, ok := validateTOTP(secret, totpCode(key, step-3), now)
+		assert.False(t, ok)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, ok := validateTOTP(secret, "12345", now)
+		assert.False(t, ok)
+		_, ok = validateTOTP("not base32!", "123456", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTPURL(t *testing.T) {
+	u := totpURL("AIOX", "user@example.com", "ABCDEF")
+	assert.True(t, strings.HasPrefix(u, "otpauth://totp/AIOX:user@example.com?"))
+	assert.Contains(t, u, "secret=ABCDEF")
+	assert.Contains(t, u, "issuer=AIOX")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := generateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, hashes, recoveryCodeCount)
+
+	for i, code := range codes {
+		assert.Regexp(t, `^[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}$`, code)
+		assert.Equal(t, hashes[i], hashRecoveryCode(code))
+		assert.Equal(t, hashes[i], hashRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))))
+	}
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// PendingTOTPTTL is how long an enrollment may wait for its first code.
+	PendingTOTPTTL = 10 * time.Minute
+	// MFATokenTTL is how long a password-verified login waits for its TOTP code.
+	MFATokenTTL = 5 * time.Minute
+
+	maxMFAAttempts = 5
+)
+
+var ErrInvalidMFAToken = errors.New("invalid or expired mfa token")
+
+// StorePendingTOTP holds an unconfirmed secret until the user verifies a code.
+func (s *Service) StorePendingTOTP(ctx context.Context, userID, secret string) error {
+	key := fmt.Sprintf("2fa:pending:%s", userID)
+	if err := s.redisClient.Set(ctx, key, secret, PendingTOTPTTL).Err(); err != nil {
+		return fmt.Errorf("storing pending totp secret: %w", err)
+	}
+	return nil
+}
+
+// PendingTOTP returns the unconfirmed secret, or "" if none is pending.
+func (s *Service) PendingTOTP(ctx context.Context, userID string) (string, error) {
+	key := fmt.Sprintf("2fa:pending:%s", userID)
+	secret, err := s.redisClient.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading pending totp secret: %w", err)
+	}
+	return secret, nil
+}
+
+func (s *Service) ClearPendingTOTP(ctx context.Context, userID string) error {
+	return s.redisClient.Del(ctx, fmt.Sprintf("2fa:pending:%s", userID)).Err()
+}
+
+// CheckTOTP validates code for the user and rejects a code already used in the
+// same time step.
+func (s *Service) CheckTOTP(ctx context.Context, userID, secret, code string) (bool, error) {
+	step, ok := validateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	key := fmt.Sprintf("2fa:used:%s:%d", userID, step)
+	fresh, err := s.redisClient.SetNX(ctx, key, "1", time.Duration(2*(totpSkew+1)*totpPeriod)*time.Second).Result()
+	if err != nil {
+		return false, fmt.Errorf("recording totp use: %w", err)
+	}
+	return fresh, nil
+}
+
+// CreateMFAToken issues the short-lived token returned by Login in place of a
+// TokenPair when the user has 2FA enabled.
+func (s *Service) CreateMFAToken(ctx context.Context, userID string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating mfa token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	key := fmt.Sprintf("mfa:%s", token)
+	if err := s.redisClient.Set(ctx, key, userID, MFATokenTTL).Err(); err != nil {
+		return "", fmt.Errorf("storing mfa token: %w", err)
+	}
+	return token, nil
+}
+
+// MFATokenUser returns the user an MFA token was issued to and counts the
+// attempt; the token is revoked after too many failed codes.
+func (s *Service) MFATokenUser(ctx context.Context, token string) (string, error) {
+	key := fmt.Sprintf("mfa:%s", token)
+	userID, err := s.redisClient.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrInvalidMFAToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading mfa token: %w", err)
+	}
+
+	attemptsKey := key + ":attempts"
+	attempts, err := s.redisClient.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		return "", fmt.Errorf("counting mfa attempts: %w", err)
+	}
+	s.redisClient.Expire(ctx, attemptsKey, MFATokenTTL)
+	if attempts > maxMFAAttempts {
+		s.redisClient.Del(ctx, key, attemptsKey)
+		return "", ErrInvalidMFAToken
+	}
+	return userID, nil
+}
+
+// RevokeMFAToken deletes an MFA token once it has been exchanged.
+func (s *Service) RevokeMFAToken(ctx context.Context, token string) error {
+	key := fmt.Sprintf("mfa:%s", token)
+	return s.redisClient.Del(ctx, key, key+":attempts").Err()
+}
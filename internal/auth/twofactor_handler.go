@@ -0,0 +1,313 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/users"
+)
+
+// MFAChallenge is returned by Login instead of a TokenPair when 2FA is enabled.
+type MFAChallenge struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// TOTPEnrollRequest re-authenticates the caller before a secret is issued.
+type TOTPEnrollRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TOTPDisableRequest needs both the password and a second factor.
+type TOTPDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	// Code is a current TOTP code or an unused recovery code.
+	Code string `json:"code" validate:"required"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	// Code is a current TOTP code or an unused recovery code.
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorHandler handles TOTP enrollment and the second login step.
+type TwoFactorHandler struct {
+	authSvc   *Service
+	userSvc   *users.Service
+	encryptor *Encryptor
+	issuer    string
+	validate  *validator.Validate
+}
+
+// NewTwoFactorHandler creates a 2FA handler. issuer is shown in authenticator apps.
+func NewTwoFactorHandler(authSvc *Service, userSvc *users.Service, encryptor *Encryptor, issuer string) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		authSvc:   authSvc,
+		userSvc:   userSvc,
+		encryptor: encryptor,
+		issuer:    issuer,
+		validate:  validator.New(),
+	}
+}
+
+// Enroll checks the caller's password and generates a pending secret; 2FA is
+// not active until Verify succeeds.
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req TOTPEnrollRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if ComparePassword(user.PasswordHash, req.Password) != nil {
+		api.HandleError(w, api.ErrInvalidCredentials)
+		return
+	}
+
+	if user.TwoFactorEnabled() {
+		api.HandleError(w, api.NewConflictError("two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "generating totp secret", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if err := h.authSvc.StorePendingTOTP(r.Context(), user.ID.String(), secret); err != nil {
+		slog.ErrorContext(r.Context(), "storing pending totp secret", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURL: totpURL(h.issuer, user.Email, secret),
+	})
+}
+
+// Verify confirms the pending secret with a code, enables 2FA, and returns
+// the recovery codes. They are shown only once.
+func (h *TwoFactorHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req TOTPCodeRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	userID := user.ID.String()
+	secret, err := h.authSvc.PendingTOTP(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "reading pending totp secret", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if secret == "" {
+		api.HandleError(w, api.NewBadRequestError("no pending two-factor enrollment"))
+		return
+	}
+
+	valid, err := h.authSvc.CheckTOTP(r.Context(), userID, secret, req.Code)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "checking totp code", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if !valid {
+		api.HandleError(w, api.ErrInvalidMFACode)
+		return
+	}
+
+	encrypted, err := h.encryptor.Encrypt(secret)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "encrypting totp secret", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "generating recovery codes", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if err := h.userSvc.EnableTOTP(r.Context(), user.ID, encrypted, hashes); err != nil {
+		slog.ErrorContext(r.Context(), "enabling totp", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if err := h.authSvc.ClearPendingTOTP(r.Context(), userID); err != nil {
+		slog.WarnContext(r.Context(), "clearing pending totp secret", "error", err)
+	}
+
+	api.JSON(w, http.StatusOK, map[string][]string{"recovery_codes": codes})
+}
+
+// Disable turns 2FA off after checking the caller's password and a current
+// TOTP or recovery code.
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req TOTPDisableRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	if ComparePassword(user.PasswordHash, req.Password) != nil {
+		api.HandleError(w, api.ErrInvalidCredentials)
+		return
+	}
+
+	if !user.TwoFactorEnabled() {
+		api.HandleError(w, api.NewBadRequestError("two-factor authentication is not enabled"))
+		return
+	}
+
+	valid, err := h.verifySecondFactor(r.Context(), user, req.Code)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "verifying second factor", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if !valid {
+		api.HandleError(w, api.ErrInvalidMFACode)
+		return
+	}
+
+	if err := h.userSvc.DisableTOTP(r.Context(), user.ID); err != nil {
+		slog.ErrorContext(r.Context(), "disabling totp", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "two-factor authentication disabled")
+}
+
+// Login exchanges an MFA token and a second factor for a TokenPair.
+func (h *TwoFactorHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req MFALoginRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	userID, err := h.authSvc.MFATokenUser(r.Context(), req.MFAToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFAToken) {
+			api.HandleError(w, api.ErrInvalidToken)
+			return
+		}
+		slog.ErrorContext(r.Context(), "reading mfa token", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		api.HandleError(w, api.ErrInvalidToken)
+		return
+	}
+	user, err := h.userSvc.GetByID(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "getting user by id", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if user == nil || !user.TwoFactorEnabled() {
+		api.HandleError(w, api.ErrInvalidToken)
+		return
+	}
+
+	valid, err := h.verifySecondFactor(r.Context(), user, req.Code)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "verifying second factor", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if !valid {
+		api.HandleError(w, api.ErrInvalidMFACode)
+		return
+	}
+
+	if err := h.authSvc.RevokeMFAToken(r.Context(), req.MFAToken); err != nil {
+		slog.WarnContext(r.Context(), "revoking mfa token", "error", err)
+	}
+
+	tokens, err := h.authSvc.GenerateTokens(userID, user.Email, user.IsAdmin, ClientInfoFromRequest(r))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "generating tokens", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, tokens)
+}
+
+// verifySecondFactor accepts a TOTP code or consumes a recovery code.
+func (h *TwoFactorHandler) verifySecondFactor(ctx context.Context, user *users.User, code string) (bool, error) {
+	if len(code) == totpDigits {
+		secret, err := h.encryptor.Decrypt(user.TOTPSecret)
+		if err != nil {
+			return false, err
+		}
+		return h.authSvc.CheckTOTP(ctx, user.ID.String(), secret, code)
+	}
+	return h.userSvc.UseRecoveryCode(ctx, user.ID, hashRecoveryCode(code))
+}
+
+func (h *TwoFactorHandler) currentUser(w http.ResponseWriter, r *http.Request) (*users.User, bool) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	user, err := h.userSvc.GetByID(r.Context(), ownerID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "getting user by id", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return nil, false
+	}
+	if user == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
+
+func (h *TwoFactorHandler) decode(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return false
+	}
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return false
+	}
+	return true
+}
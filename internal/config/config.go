@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,11 +18,21 @@ type Config struct {
 	DB         DBConfig
 	Redis      RedisConfig
 	JWT        JWTConfig
+	Password   PasswordConfig
+	Lockout    LockoutConfig
 	Encryption EncryptionConfig
 	XMPP       XMPPConfig
 	NATS       NATSConfig
 	GRPC       GRPCConfig
 	Governance GovernanceCfg
+	Agents     AgentsConfig
+	Redaction  RedactionConfig
+	Tracing    TracingConfig
+	Pricing    PricingConfig
+	Embedder   EmbedderConfig
+	Moderation ModerationConfig
+	Webhook    WebhookConfig
+	Admin      AdminConfig
 	Log        LogConfig
 }
 
@@ -29,6 +40,107 @@ type GovernanceCfg struct {
 	MaxTokensPerDay    int
 	MaxTokensPerMinute int
 	MaxRequestsPerDay  int
+	// WarningThresholds are daily usage percentages, ascending, at which a
+	// quota_warning audit event is emitted.
+	WarningThresholds []int
+	// AllowedProvidersGlobal restricts the LLM providers any agent may use.
+	// Empty allows all providers.
+	AllowedProvidersGlobal []string
+	// MaxTokensPerRequest caps the max_tokens of any agent's LLM config.
+	// Zero is uncapped.
+	MaxTokensPerRequest int
+	// MinTemperature and MaxTemperature bound the temperature of any
+	// agent's LLM config.
+	MinTemperature float64
+	MaxTemperature float64
+	// AllowedModels restricts the LLM models any agent may use. Empty allows
+	// all models.
+	AllowedModels []string
+}
+
+// AgentsConfig limits the size of agent system prompts.
+type AgentsConfig struct {
+	// MaxSystemPromptLength is the longest system prompt an agent may have,
+	// in characters.
+	MaxSystemPromptLength int
+	// SystemPromptExternalThreshold is the prompt length, in characters,
+	// above which the encrypted prompt is stored in agent_prompts instead of
+	// inline in the profile. -1 keeps every prompt inline.
+	SystemPromptExternalThreshold int
+}
+
+// RedactionConfig holds deployment-wide PII redaction settings.
+// Patterns are built-in rule names (e.g. "email", "credit_card").
+type RedactionConfig struct {
+	Patterns       []string
+	RedactOutbound bool
+	// Audit also redacts audit log details before they are stored.
+	Audit bool
+}
+
+// TracingConfig controls OpenTelemetry trace export over OTLP/gRPC.
+type TracingConfig struct {
+	Enabled      bool
+	OTLPEndpoint string
+	Insecure     bool
+	ServiceName  string
+	SampleRatio  float64
+}
+
+// EmbedderConfig points at an OpenAI-compatible embeddings endpoint used to
+// embed incoming messages for long-term memory retrieval. An empty URL
+// disables server-side embedding.
+type EmbedderConfig struct {
+	URL     string
+	APIKey  string
+	Timeout time.Duration
+	// CacheTTL is how long embeddings are cached in Redis by model and text.
+	// Zero disables the cache.
+	CacheTTL time.Duration
+}
+
+// ModerationConfig controls screening of inbound messages before dispatch.
+// BlockedPatterns are regular expressions; an empty APIURL disables the
+// external moderation service.
+type ModerationConfig struct {
+	BlockedPatterns []string
+	APIURL          string
+	APIKey          string
+	Timeout         time.Duration
+	// FailClosed blocks messages when the moderation service errors instead
+	// of letting them through.
+	FailClosed bool
+}
+
+// WebhookConfig controls delivery of events to user webhooks.
+type WebhookConfig struct {
+	// MaxAttempts is how many times an event is sent before it counts as a
+	// dead letter.
+	MaxAttempts int
+	// DisableAfter disables a webhook after this many consecutive dead letters.
+	DisableAfter int
+	Timeout      time.Duration
+	// AllowPrivateNetworks lets webhooks target loopback, private and
+	// link-local addresses. Only for development.
+	AllowPrivateNetworks bool
+}
+
+// AdminConfig lists the users promoted to admin at startup and on registration.
+type AdminConfig struct {
+	Emails []string
+}
+
+// PricingConfig lists per-model token prices used to estimate execution cost.
+type PricingConfig struct {
+	Models []ModelPrice
+}
+
+// ModelPrice is a model's price in USD per million tokens.
+type ModelPrice struct {
+	Provider      string
+	Model         string
+	InputPerMTok  float64
+	OutputPerMTok float64
 }
 
 type GRPCConfig struct {
@@ -36,12 +148,53 @@ type GRPCConfig struct {
 	Port           int
 	WorkerAPIKey   string
 	TaskTimeoutSec int
+	// MaxTaskTimeoutSec caps the task timeout agents may set in their
+	// capabilities.
+	MaxTaskTimeoutSec int
+	MaxBufferedChunks int
+	// HeartbeatTimeoutSec evicts workers whose last heartbeat is older than this.
+	HeartbeatTimeoutSec int
+	// LoadBalancing is the worker selection strategy: least_loaded,
+	// round_robin, or weighted_random.
+	LoadBalancing string
+	// DebugContext allows agents and invoke requests to have what each task
+	// sends to the worker stored with its execution.
+	DebugContext bool
+	// HealthGate is what the dispatcher does with tasks while Postgres, or
+	// Redis for agents with memory, is unhealthy: closed holds them for
+	// redelivery, open dispatches them anyway.
+	HealthGate string
 }
 
 type ServerConfig struct {
 	Host               string
 	Port               int
 	CORSAllowedOrigins []string
+	// CORSAllowedMethods, CORSAllowedHeaders, and CORSExposedHeaders replace
+	// the middleware defaults when set.
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSExposedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAge           int
+
+	// ShutdownDelay is how long the readiness probe reports 503 before the
+	// listener closes, giving load balancers time to stop routing traffic.
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to drain.
+	ShutdownTimeout time.Duration
+
+	// Request body limits in bytes: the default, auth endpoints, and bulk imports.
+	MaxBodyBytes     int64
+	AuthMaxBodyBytes int64
+	BulkMaxBodyBytes int64
+	// HandlerTimeout aborts non-streaming requests that run longer.
+	HandlerTimeout time.Duration
+
+	// MetricsAgentLabel adds an agent_id label to HTTP metrics, capped at
+	// MetricsMaxAgentLabels distinct agents.
+	MetricsAgentLabel     bool
+	MetricsMaxAgentLabels int
 }
 
 type DBConfig struct {
@@ -57,6 +210,16 @@ type DBConfig struct {
 	MaxConnLifetime time.Duration
 	AutoMigrate     bool
 	MigrationsPath  string
+
+	// Reads are retried up to RetryAttempts times on transient errors, with
+	// a jittered backoff from RetryBaseDelay up to RetryMaxDelay.
+	RetryAttempts  int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerThreshold consecutive transient failures fail reads fast for
+	// BreakerCooldown; a negative threshold disables the breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 
 func (c DBConfig) DSN() string {
@@ -82,8 +245,30 @@ type JWTConfig struct {
 	RefreshExpiry time.Duration
 }
 
+// PasswordConfig is the strength policy for new passwords.
+type PasswordConfig struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// RejectCommon rejects passwords found in the built-in common-password list.
+	RejectCommon bool
+}
+
+// LockoutConfig locks an account for Duration after MaxAttempts failed
+// logins within Window. Negative MaxAttempts disables lockout.
+type LockoutConfig struct {
+	MaxAttempts int
+	Window      time.Duration
+	Duration    time.Duration
+}
+
 type EncryptionConfig struct {
 	Key string
+	// PreviousKeys are retired keys still accepted for decryption while
+	// stored values are rotated to Key.
+	PreviousKeys []string
 }
 
 type XMPPConfig struct {
@@ -92,6 +277,15 @@ type XMPPConfig struct {
 	ComponentPort   int
 	ComponentSecret string
 	ComponentName   string
+	// DedupWindowSec is how long a received message stanza is remembered so a
+	// redelivery is dropped. Zero or negative disables deduplication.
+	DedupWindowSec int
+	// OutboundMaxAttempts is how many sends of an outbound message may fail
+	// before it is dead-lettered.
+	OutboundMaxAttempts int
+	// RoutingFailure is how messages to an unknown or malformed agent address
+	// are answered: "reply", "audit", or "silent".
+	RoutingFailure string
 }
 
 func (c XMPPConfig) ComponentAddr() string {
@@ -100,11 +294,60 @@ func (c XMPPConfig) ComponentAddr() string {
 
 type NATSConfig struct {
 	URL string
+	// Codec is the payload encoding for published messages: "json" or "protobuf".
+	Codec string
+	// MaxDeliveries is how many times a task is delivered before it is moved
+	// to the dead-letter stream. Negative disables dead-lettering.
+	MaxDeliveries int
+	// PublishBufferSize is how many publishes are held in memory while NATS
+	// is disconnected, flushed on reconnect. Negative disables buffering.
+	PublishBufferSize int
+	Streams           NATSStreamsConfig
+	Consumers         NATSConsumersConfig
+}
+
+// NATSConsumersConfig sets the ack and redelivery policy of every durable
+// consumer. Zero durations use the built-in defaults. MaxDeliver of zero is
+// unlimited; when set, it must exceed MaxDeliveries so tasks are
+// dead-lettered before the server stops redelivering them.
+type NATSConsumersConfig struct {
+	AckWait     time.Duration
+	MaxDeliver  int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// NATSStreamsConfig sizes the JetStream streams created at startup. Each max
+// age bounds how long a stream retains messages; zero uses the built-in
+// default. Replicas applies to every stream and needs a clustered server
+// when above 1.
+type NATSStreamsConfig struct {
+	Replicas         int
+	MessagesMaxAge   time.Duration
+	TasksMaxAge      time.Duration
+	EventsMaxAge     time.Duration
+	DeadLetterMaxAge time.Duration
 }
 
 type LogConfig struct {
 	Level  string
 	Format string
+	// Requests configures the per-request log line.
+	Requests RequestLogConfig
+}
+
+// RequestLogConfig selects the fields of the per-request log line. Field
+// names are method, path, status, latency, user_id, request_id, remote_addr,
+// and headers; empty Fields means all but headers.
+type RequestLogConfig struct {
+	Fields        []string
+	ExcludeFields []string
+	// RedactHeaders are masked when headers are logged, in addition to
+	// Authorization.
+	RedactHeaders []string
+	// SampleRate logs one in SampleRate successful requests; errors are
+	// always logged.
+	SampleRate int
 }
 
 func Load() (*Config, error) {
@@ -149,20 +392,31 @@ func Load() (*Config, error) {
 			Key: k.String("encryption.key"),
 		},
 		XMPP: XMPPConfig{
-			Domain:          k.String("xmpp.domain"),
-			ComponentHost:   k.String("xmpp.component.host"),
-			ComponentPort:   k.Int("xmpp.component.port"),
-			ComponentSecret: k.String("xmpp.component.secret"),
-			ComponentName:   k.String("xmpp.component.name"),
+			Domain:              k.String("xmpp.domain"),
+			ComponentHost:       k.String("xmpp.component.host"),
+			ComponentPort:       k.Int("xmpp.component.port"),
+			ComponentSecret:     k.String("xmpp.component.secret"),
+			ComponentName:       k.String("xmpp.component.name"),
+			DedupWindowSec:      k.Int("xmpp.dedup.window.sec"),
+			OutboundMaxAttempts: k.Int("xmpp.outbound.max.attempts"),
+			RoutingFailure:      k.String("xmpp.routing.failure"),
 		},
 		NATS: NATSConfig{
-			URL: k.String("nats.url"),
+			URL:               k.String("nats.url"),
+			Codec:             k.String("nats.codec"),
+			MaxDeliveries:     k.Int("nats.max.deliveries"),
+			PublishBufferSize: k.Int("nats.publish.buffer.size"),
 		},
 		GRPC: GRPCConfig{
-			Host:           k.String("grpc.host"),
-			Port:           k.Int("grpc.port"),
-			WorkerAPIKey:   k.String("grpc.worker.api.key"),
-			TaskTimeoutSec: k.Int("grpc.task.timeout.sec"),
+			Host:                k.String("grpc.host"),
+			Port:                k.Int("grpc.port"),
+			WorkerAPIKey:        k.String("grpc.worker.api.key"),
+			TaskTimeoutSec:      k.Int("grpc.task.timeout.sec"),
+			MaxTaskTimeoutSec:   k.Int("grpc.max.task.timeout.sec"),
+			MaxBufferedChunks:   k.Int("grpc.max.buffered.chunks"),
+			HeartbeatTimeoutSec: k.Int("grpc.heartbeat.timeout.sec"),
+			LoadBalancing:       k.String("grpc.load.balancing"),
+			HealthGate:          k.String("grpc.health.gate"),
 		},
 		Governance: GovernanceCfg{
 			MaxTokensPerDay:    k.Int("governance.max.tokens.per.day"),
@@ -172,6 +426,12 @@ func Load() (*Config, error) {
 		Log: LogConfig{
 			Level:  k.String("log.level"),
 			Format: k.String("log.format"),
+			Requests: RequestLogConfig{
+				Fields:        splitList(k.String("log.request.fields")),
+				ExcludeFields: splitList(k.String("log.request.exclude.fields")),
+				RedactHeaders: splitList(k.String("log.redact.headers")),
+				SampleRate:    k.Int("log.request.sample.rate"),
+			},
 		},
 	}
 
@@ -221,9 +481,33 @@ func Load() (*Config, error) {
 	if cfg.XMPP.ComponentName == "" {
 		cfg.XMPP.ComponentName = "agents.aiox.local"
 	}
+	// Only an unset window defaults; an explicit 0 disables deduplication.
+	if k.String("xmpp.dedup.window.sec") == "" {
+		cfg.XMPP.DedupWindowSec = 60
+	}
+	if cfg.XMPP.OutboundMaxAttempts == 0 {
+		cfg.XMPP.OutboundMaxAttempts = 5
+	}
+	if cfg.XMPP.RoutingFailure == "" {
+		cfg.XMPP.RoutingFailure = "reply"
+	}
 	if cfg.NATS.URL == "" {
 		cfg.NATS.URL = "nats://localhost:4222"
 	}
+	if cfg.NATS.Codec == "" {
+		cfg.NATS.Codec = "json"
+	}
+	if cfg.NATS.MaxDeliveries == 0 {
+		cfg.NATS.MaxDeliveries = 20
+	}
+	if cfg.NATS.PublishBufferSize == 0 {
+		cfg.NATS.PublishBufferSize = 1000
+	}
+	cfg.NATS.Consumers.MaxDeliver = k.Int("nats.consumer.max.deliver")
+	cfg.NATS.Streams.Replicas = k.Int("nats.stream.replicas")
+	if cfg.NATS.Streams.Replicas == 0 {
+		cfg.NATS.Streams.Replicas = 1
+	}
 	if cfg.GRPC.Host == "" {
 		cfg.GRPC.Host = "0.0.0.0"
 	}
@@ -233,6 +517,22 @@ func Load() (*Config, error) {
 	if cfg.GRPC.TaskTimeoutSec == 0 {
 		cfg.GRPC.TaskTimeoutSec = 120
 	}
+	if cfg.GRPC.MaxTaskTimeoutSec == 0 {
+		cfg.GRPC.MaxTaskTimeoutSec = 600
+	}
+	if cfg.GRPC.HeartbeatTimeoutSec == 0 {
+		cfg.GRPC.HeartbeatTimeoutSec = 45
+	}
+	if cfg.GRPC.MaxBufferedChunks == 0 {
+		cfg.GRPC.MaxBufferedChunks = 256
+	}
+	if cfg.GRPC.LoadBalancing == "" {
+		cfg.GRPC.LoadBalancing = "least_loaded"
+	}
+	cfg.GRPC.DebugContext = parseBool(k.String("grpc.debug.context"), false)
+	if cfg.GRPC.HealthGate == "" {
+		cfg.GRPC.HealthGate = "closed"
+	}
 	if cfg.Governance.MaxTokensPerDay == 0 {
 		cfg.Governance.MaxTokensPerDay = 100000
 	}
@@ -248,20 +548,234 @@ func Load() (*Config, error) {
 	if cfg.Log.Format == "" {
 		cfg.Log.Format = "text"
 	}
+	if len(cfg.Log.Requests.RedactHeaders) == 0 {
+		cfg.Log.Requests.RedactHeaders = []string{"Cookie", "X-Api-Key"}
+	}
 
-	// CORS allowed origins (comma-separated)
-	corsRaw := k.String("cors.allowed.origins")
-	if corsRaw != "" {
-		for _, o := range strings.Split(corsRaw, ",") {
-			o = strings.TrimSpace(o)
-			if o != "" {
-				cfg.Server.CORSAllowedOrigins = append(cfg.Server.CORSAllowedOrigins, o)
-			}
+	cfg.Admin.Emails = splitList(k.String("admin.emails"))
+	cfg.Encryption.PreviousKeys = splitList(k.String("encryption.previous.keys"))
+
+	// Password policy
+	cfg.Password.MinLength = k.Int("password.min.length")
+	if cfg.Password.MinLength <= 0 {
+		cfg.Password.MinLength = 8
+	}
+	cfg.Password.RequireUpper = parseBool(k.String("password.require.upper"), false)
+	cfg.Password.RequireLower = parseBool(k.String("password.require.lower"), false)
+	cfg.Password.RequireDigit = parseBool(k.String("password.require.digit"), false)
+	cfg.Password.RequireSymbol = parseBool(k.String("password.require.symbol"), false)
+	cfg.Password.RejectCommon = parseBool(k.String("password.reject.common"), true)
+
+	// Account lockout after failed logins
+	cfg.Lockout.MaxAttempts = k.Int("lockout.max.attempts")
+	if cfg.Lockout.MaxAttempts == 0 {
+		cfg.Lockout.MaxAttempts = 5
+	}
+	cfg.Lockout.Window = 15 * time.Minute
+	if v := k.String("lockout.window.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Lockout.Window = time.Duration(n) * time.Millisecond
 		}
 	}
+	cfg.Lockout.Duration = 15 * time.Minute
+	if v := k.String("lockout.duration.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Lockout.Duration = time.Duration(n) * time.Millisecond
+		}
+	}
+
+	// CORS (lists are comma-separated)
+	cfg.Server.CORSAllowedOrigins = splitList(k.String("cors.allowed.origins"))
 	if len(cfg.Server.CORSAllowedOrigins) == 0 {
 		cfg.Server.CORSAllowedOrigins = []string{"http://localhost:3000"}
 	}
+	cfg.Server.CORSAllowedMethods = splitList(k.String("cors.allowed.methods"))
+	cfg.Server.CORSAllowedHeaders = splitList(k.String("cors.allowed.headers"))
+	cfg.Server.CORSExposedHeaders = splitList(k.String("cors.exposed.headers"))
+	// Credentials default to on unless an origin is "*", which browsers reject
+	// together with credentials; setting both explicitly fails validation.
+	if v := k.String("cors.allow.credentials"); v != "" {
+		cfg.Server.CORSAllowCredentials = v == "true" || v == "1"
+	} else {
+		cfg.Server.CORSAllowCredentials = !slices.Contains(cfg.Server.CORSAllowedOrigins, "*")
+	}
+	cfg.Server.CORSMaxAge = 300
+	if v := k.String("cors.max.age"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Server.CORSMaxAge = n
+		}
+	}
+
+	// Redaction patterns (comma-separated built-in names)
+	for _, p := range strings.Split(k.String("redaction.patterns"), ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			cfg.Redaction.Patterns = append(cfg.Redaction.Patterns, p)
+		}
+	}
+	redactOutboundStr := k.String("redaction.outbound")
+	cfg.Redaction.RedactOutbound = redactOutboundStr == "true" || redactOutboundStr == "1"
+	redactAuditStr := k.String("redaction.audit")
+	cfg.Redaction.Audit = redactAuditStr == "true" || redactAuditStr == "1"
+
+	// Tracing
+	tracingEnabledStr := k.String("tracing.enabled")
+	cfg.Tracing.Enabled = tracingEnabledStr == "true" || tracingEnabledStr == "1"
+	tracingInsecureStr := k.String("tracing.insecure")
+	cfg.Tracing.Insecure = tracingInsecureStr == "true" || tracingInsecureStr == "1"
+	cfg.Tracing.OTLPEndpoint = k.String("tracing.otlp.endpoint")
+	if cfg.Tracing.OTLPEndpoint == "" {
+		cfg.Tracing.OTLPEndpoint = "localhost:4317"
+	}
+	cfg.Tracing.ServiceName = k.String("tracing.service.name")
+	if cfg.Tracing.ServiceName == "" {
+		cfg.Tracing.ServiceName = "aiox-api"
+	}
+	cfg.Tracing.SampleRatio = 1.0
+	if v := k.String("tracing.sample.ratio"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			cfg.Tracing.SampleRatio = f
+		}
+	}
+
+	// Quota warning thresholds (comma-separated percentages)
+	thresholdsRaw := k.String("governance.warning.thresholds")
+	if thresholdsRaw == "" {
+		thresholdsRaw = "80,95"
+	}
+	cfg.Governance.WarningThresholds, err = parseThresholds(thresholdsRaw)
+	if err != nil {
+		return nil, fmt.Errorf("parsing GOVERNANCE_WARNING_THRESHOLDS: %w", err)
+	}
+
+	// Platform-wide LLM provider allow-list (comma-separated)
+	for _, p := range strings.Split(k.String("governance.allowed.providers.global"), ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p != "" {
+			cfg.Governance.AllowedProvidersGlobal = append(cfg.Governance.AllowedProvidersGlobal, p)
+		}
+	}
+
+	// LLM caps applied at dispatch; admins may override them per agent
+	cfg.Governance.MaxTokensPerRequest = k.Int("governance.max.tokens.per.request")
+	cfg.Governance.MaxTemperature = 2
+	for key, dst := range map[string]*float64{
+		"governance.min.temperature": &cfg.Governance.MinTemperature,
+		"governance.max.temperature": &cfg.Governance.MaxTemperature,
+	} {
+		if v := k.String(key); v != "" {
+			if *dst, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", strings.ToUpper(strings.ReplaceAll(key, ".", "_")), err)
+			}
+		}
+	}
+	cfg.Governance.AllowedModels = splitList(k.String("governance.allowed.models"))
+
+	// Pricing ("provider/model=input:output" per million tokens, comma-separated)
+	cfg.Pricing.Models, err = parseModelPrices(k.String("pricing.models"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing PRICING_MODELS: %w", err)
+	}
+
+	// Server-side embedding endpoint
+	cfg.Embedder.URL = k.String("embedder.url")
+	cfg.Embedder.APIKey = k.String("embedder.api.key")
+	cfg.Embedder.Timeout = 5 * time.Second
+	if v := k.String("embedder.timeout.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Embedder.Timeout = time.Duration(n) * time.Millisecond
+		}
+	}
+	cfg.Embedder.CacheTTL = 24 * time.Hour
+	if v := k.String("embedder.cache.ttl.sec"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Embedder.CacheTTL = time.Duration(n) * time.Second
+		}
+	}
+
+	// Content moderation
+	for _, p := range strings.Split(k.String("moderation.blocked.patterns"), ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			cfg.Moderation.BlockedPatterns = append(cfg.Moderation.BlockedPatterns, p)
+		}
+	}
+	cfg.Moderation.APIURL = k.String("moderation.api.url")
+	cfg.Moderation.APIKey = k.String("moderation.api.key")
+	cfg.Moderation.Timeout = 2 * time.Second
+	if v := k.String("moderation.timeout.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Moderation.Timeout = time.Duration(n) * time.Millisecond
+		}
+	}
+	failClosedStr := k.String("moderation.fail.closed")
+	cfg.Moderation.FailClosed = failClosedStr == "true" || failClosedStr == "1"
+
+	// HTTP shutdown draining
+	if v := k.String("server.shutdown.delay.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Server.ShutdownDelay = time.Duration(n) * time.Millisecond
+		}
+	}
+	cfg.Server.ShutdownTimeout = 30 * time.Second
+	if v := k.String("server.shutdown.timeout.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Server.ShutdownTimeout = time.Duration(n) * time.Millisecond
+		}
+	}
+
+	// Request body limits and handler timeout
+	cfg.Server.MaxBodyBytes = k.Int64("server.max.body.bytes")
+	if cfg.Server.MaxBodyBytes <= 0 {
+		cfg.Server.MaxBodyBytes = 1 << 20
+	}
+	cfg.Server.AuthMaxBodyBytes = k.Int64("server.auth.max.body.bytes")
+	if cfg.Server.AuthMaxBodyBytes <= 0 {
+		cfg.Server.AuthMaxBodyBytes = 64 << 10
+	}
+	cfg.Server.BulkMaxBodyBytes = k.Int64("server.bulk.max.body.bytes")
+	if cfg.Server.BulkMaxBodyBytes <= 0 {
+		cfg.Server.BulkMaxBodyBytes = 10 << 20
+	}
+	// Below the HTTP server's 15s write timeout so clients get the 503 body
+	cfg.Server.HandlerTimeout = 14 * time.Second
+	if v := k.String("server.handler.timeout.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Server.HandlerTimeout = time.Duration(n) * time.Millisecond
+		}
+	}
+	cfg.Server.MetricsAgentLabel = parseBool(k.String("metrics.agent.label"), false)
+	cfg.Server.MetricsMaxAgentLabels = k.Int("metrics.max.agent.labels")
+	if cfg.Server.MetricsMaxAgentLabels <= 0 {
+		cfg.Server.MetricsMaxAgentLabels = 100
+	}
+
+	// Agent system prompts
+	cfg.Agents.MaxSystemPromptLength = k.Int("agent.max.system.prompt.length")
+	if cfg.Agents.MaxSystemPromptLength == 0 {
+		cfg.Agents.MaxSystemPromptLength = 100000
+	}
+	cfg.Agents.SystemPromptExternalThreshold = k.Int("agent.system.prompt.external.threshold")
+	if cfg.Agents.SystemPromptExternalThreshold == 0 {
+		cfg.Agents.SystemPromptExternalThreshold = 8192
+	}
+
+	// Webhook delivery
+	cfg.Webhook.MaxAttempts = k.Int("webhook.max.attempts")
+	if cfg.Webhook.MaxAttempts <= 0 {
+		cfg.Webhook.MaxAttempts = 5
+	}
+	cfg.Webhook.DisableAfter = k.Int("webhook.disable.after")
+	if cfg.Webhook.DisableAfter <= 0 {
+		cfg.Webhook.DisableAfter = 10
+	}
+	cfg.Webhook.Timeout = 10 * time.Second
+	if v := k.String("webhook.timeout.ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.Webhook.Timeout = time.Duration(n) * time.Millisecond
+		}
+	}
+	cfg.Webhook.AllowPrivateNetworks = parseBool(k.String("webhook.allow.private.networks"), false)
 
 	// DB pool tuning
 	if v := k.String("db.min.conns"); v != "" {
@@ -270,11 +784,24 @@ func Load() (*Config, error) {
 		}
 	}
 	if cfg.DB.MinConns == 0 {
-		cfg.DB.MinConns = 2
+		cfg.DB.MinConns = min(2, cfg.DB.MaxConns)
 	}
 	cfg.DB.MaxConnIdleTime = 5 * time.Minute
 	cfg.DB.MaxConnLifetime = 1 * time.Hour
 
+	// Read retries and circuit breaker
+	cfg.DB.RetryAttempts = k.Int("db.retry.attempts")
+	if cfg.DB.RetryAttempts <= 0 {
+		cfg.DB.RetryAttempts = 3
+	}
+	cfg.DB.RetryBaseDelay = 50 * time.Millisecond
+	cfg.DB.RetryMaxDelay = time.Second
+	cfg.DB.BreakerThreshold = k.Int("db.breaker.threshold")
+	if cfg.DB.BreakerThreshold == 0 {
+		cfg.DB.BreakerThreshold = 5
+	}
+	cfg.DB.BreakerCooldown = 10 * time.Second
+
 	// Auto-migrate
 	autoMigrateStr := k.String("db.auto.migrate")
 	cfg.DB.AutoMigrate = autoMigrateStr == "true" || autoMigrateStr == "1"
@@ -303,5 +830,112 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("parsing jwt refresh expiry: %w", err)
 	}
 
+	for key, dst := range map[string]*time.Duration{
+		"nats.messages.max.age": &cfg.NATS.Streams.MessagesMaxAge,
+		"nats.tasks.max.age":    &cfg.NATS.Streams.TasksMaxAge,
+		"nats.events.max.age":   &cfg.NATS.Streams.EventsMaxAge,
+		"nats.dlq.max.age":      &cfg.NATS.Streams.DeadLetterMaxAge,
+		"nats.ack.wait":         &cfg.NATS.Consumers.AckWait,
+		"nats.nak.backoff.base": &cfg.NATS.Consumers.BackoffBase,
+		"nats.nak.backoff.max":  &cfg.NATS.Consumers.BackoffMax,
+		"db.retry.base.delay":   &cfg.DB.RetryBaseDelay,
+		"db.retry.max.delay":    &cfg.DB.RetryMaxDelay,
+		"db.breaker.cooldown":   &cfg.DB.BreakerCooldown,
+		"db.max.conn.lifetime":  &cfg.DB.MaxConnLifetime,
+		"db.max.conn.idle.time": &cfg.DB.MaxConnIdleTime,
+	} {
+		if v := k.String(key); v != "" {
+			if *dst, err = time.ParseDuration(v); err != nil {
+				return nil, fmt.Errorf("parsing %s: %w", key, err)
+			}
+		}
+	}
+
 	return cfg, nil
 }
+
+// parseBool reads "true"/"1" or "false"/"0", returning def for anything else.
+func parseBool(raw string, def bool) bool {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "true", "1":
+		return true
+	case "false", "0":
+		return false
+	}
+	return def
+}
+
+// splitList splits a comma-separated value, trimming spaces and dropping
+// empty entries.
+func splitList(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseModelPrices parses entries like "openai/gpt-4o=2.50:10.00". The
+// provider prefix is optional.
+func parseModelPrices(raw string) ([]ModelPrice, error) {
+	var prices []ModelPrice
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rates, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q: expected model=input:output", entry)
+		}
+		in, out, ok := strings.Cut(rates, ":")
+		if !ok {
+			return nil, fmt.Errorf("entry %q: expected model=input:output", entry)
+		}
+
+		var p ModelPrice
+		if provider, model, found := strings.Cut(name, "/"); found {
+			p.Provider, p.Model = strings.TrimSpace(provider), strings.TrimSpace(model)
+		} else {
+			p.Model = strings.TrimSpace(name)
+		}
+		if p.Model == "" {
+			return nil, fmt.Errorf("entry %q: missing model name", entry)
+		}
+
+		var err error
+		if p.InputPerMTok, err = strconv.ParseFloat(strings.TrimSpace(in), 64); err != nil || p.InputPerMTok < 0 {
+			return nil, fmt.Errorf("entry %q: invalid input price", entry)
+		}
+		if p.OutputPerMTok, err = strconv.ParseFloat(strings.TrimSpace(out), 64); err != nil || p.OutputPerMTok < 0 {
+			return nil, fmt.Errorf("entry %q: invalid output price", entry)
+		}
+		prices = append(prices, p)
+	}
+	return prices, nil
+}
+
+// parseThresholds parses comma-separated percentages between 1 and 99 and
+// returns them sorted ascending without duplicates.
+func parseThresholds(raw string) ([]int, error) {
+	var thresholds []int
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(entry, "%"))
+		if err != nil || n < 1 || n > 99 {
+			return nil, fmt.Errorf("threshold %q must be a percentage between 1 and 99", entry)
+		}
+		if !slices.Contains(thresholds, n) {
+			thresholds = append(thresholds, n)
+		}
+	}
+	slices.Sort(thresholds)
+	return thresholds, nil
+}
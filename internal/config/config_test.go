@@ -0,0 +1,64 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseModelPrices(t *testing.T) {
+	prices, err := parseModelPrices("openai/gpt-4o=2.50:10, claude-sonnet-4 = 3:15,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ModelPrice{
+		{Provider: "openai", Model: "gpt-4o", InputPerMTok: 2.5, OutputPerMTok: 10},
+		{Model: "claude-sonnet-4", InputPerMTok: 3, OutputPerMTok: 15},
+	}
+	if !reflect.DeepEqual(prices, want) {
+		t.Errorf("got %+v, want %+v", prices, want)
+	}
+}
+
+func TestParseModelPrices_Empty(t *testing.T) {
+	prices, err := parseModelPrices("")
+	if err != nil || len(prices) != 0 {
+		t.Errorf("expected no prices and no error, got %+v, %v", prices, err)
+	}
+}
+
+func TestParseModelPrices_Invalid(t *testing.T) {
+	for _, raw := range []string{"gpt-4o", "gpt-4o=2.5", "=1:2", "gpt-4o=x:2", "gpt-4o=1:-2"} {
+		if _, err := parseModelPrices(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	got, err := parseThresholds("95, 80%,80")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{80, 95}) {
+		t.Errorf("expected [80 95], got %v", got)
+	}
+
+	for _, raw := range []string{"0", "100", "abc"} {
+		if _, err := parseThresholds(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestLoad_DedupWindow(t *testing.T) {
+	for raw, want := range map[string]int{"": 60, "0": 0, "30": 30} {
+		t.Setenv("XMPP_DEDUP_WINDOW_SEC", raw)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.XMPP.DedupWindowSec != want {
+			t.Errorf("XMPP_DEDUP_WINDOW_SEC=%q: got %d, want %d", raw, cfg.XMPP.DedupWindowSec, want)
+		}
+	}
+}
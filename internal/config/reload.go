@@ -0,0 +1,119 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Change describes a hot-reloadable field whose value differs between two loads.
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// ReloadPlan splits the differences between two configs into changes that can
+// be applied at runtime and sections that only take effect after a restart.
+type ReloadPlan struct {
+	Apply           []Change
+	RestartRequired []string
+}
+
+// Diff compares the running config with a freshly loaded one. Restart-only
+// sections are reported by name only, so secrets never reach the logs.
+func Diff(current, next *Config) ReloadPlan {
+	var plan ReloadPlan
+
+	addChange := func(field, oldVal, newVal string) {
+		if oldVal != newVal {
+			plan.Apply = append(plan.Apply, Change{Field: field, Old: oldVal, New: newVal})
+		}
+	}
+	addChange("log.level", current.Log.Level, next.Log.Level)
+	addChange("governance.max_tokens_per_day", strconv.Itoa(current.Governance.MaxTokensPerDay), strconv.Itoa(next.Governance.MaxTokensPerDay))
+	addChange("governance.max_tokens_per_minute", strconv.Itoa(current.Governance.MaxTokensPerMinute), strconv.Itoa(next.Governance.MaxTokensPerMinute))
+	addChange("governance.max_requests_per_day", strconv.Itoa(current.Governance.MaxRequestsPerDay), strconv.Itoa(next.Governance.MaxRequestsPerDay))
+	addChange("governance.warning_thresholds", joinInts(current.Governance.WarningThresholds), joinInts(next.Governance.WarningThresholds))
+	addChange("governance.allowed_providers_global", strings.Join(current.Governance.AllowedProvidersGlobal, ","), strings.Join(next.Governance.AllowedProvidersGlobal, ","))
+	addChange("governance.max_tokens_per_request", strconv.Itoa(current.Governance.MaxTokensPerRequest), strconv.Itoa(next.Governance.MaxTokensPerRequest))
+	addChange("governance.min_temperature", strconv.FormatFloat(current.Governance.MinTemperature, 'g', -1, 64), strconv.FormatFloat(next.Governance.MinTemperature, 'g', -1, 64))
+	addChange("governance.max_temperature", strconv.FormatFloat(current.Governance.MaxTemperature, 'g', -1, 64), strconv.FormatFloat(next.Governance.MaxTemperature, 'g', -1, 64))
+	addChange("governance.allowed_models", strings.Join(current.Governance.AllowedModels, ","), strings.Join(next.Governance.AllowedModels, ","))
+	addChange("grpc.task_timeout_sec", strconv.Itoa(current.GRPC.TaskTimeoutSec), strconv.Itoa(next.GRPC.TaskTimeoutSec))
+	addChange("grpc.max_task_timeout_sec", strconv.Itoa(current.GRPC.MaxTaskTimeoutSec), strconv.Itoa(next.GRPC.MaxTaskTimeoutSec))
+	addChange("grpc.debug_context", strconv.FormatBool(current.GRPC.DebugContext), strconv.FormatBool(next.GRPC.DebugContext))
+	addChange("grpc.health_gate", current.GRPC.HealthGate, next.GRPC.HealthGate)
+	addChange("agents.max_system_prompt_length", strconv.Itoa(current.Agents.MaxSystemPromptLength), strconv.Itoa(next.Agents.MaxSystemPromptLength))
+	addChange("agents.system_prompt_external_threshold", strconv.Itoa(current.Agents.SystemPromptExternalThreshold), strconv.Itoa(next.Agents.SystemPromptExternalThreshold))
+
+	restartOnly := []struct {
+		name      string
+		old, next any
+	}{
+		{"server", current.Server, next.Server},
+		{"db", current.DB, next.DB},
+		{"redis", current.Redis, next.Redis},
+		{"jwt", current.JWT, next.JWT},
+		{"encryption", current.Encryption, next.Encryption},
+		{"xmpp", current.XMPP, next.XMPP},
+		{"nats", current.NATS, next.NATS},
+		{"grpc.host", current.GRPC.Host, next.GRPC.Host},
+		{"grpc.port", current.GRPC.Port, next.GRPC.Port},
+		{"grpc.worker_api_key", current.GRPC.WorkerAPIKey, next.GRPC.WorkerAPIKey},
+		{"grpc.max_buffered_chunks", current.GRPC.MaxBufferedChunks, next.GRPC.MaxBufferedChunks},
+		{"grpc.heartbeat_timeout_sec", current.GRPC.HeartbeatTimeoutSec, next.GRPC.HeartbeatTimeoutSec},
+		{"grpc.load_balancing", current.GRPC.LoadBalancing, next.GRPC.LoadBalancing},
+		{"redaction", current.Redaction, next.Redaction},
+		{"tracing", current.Tracing, next.Tracing},
+		{"pricing", current.Pricing, next.Pricing},
+		{"embedder", current.Embedder, next.Embedder},
+		{"moderation", current.Moderation, next.Moderation},
+		{"webhook", current.Webhook, next.Webhook},
+		{"log.format", current.Log.Format, next.Log.Format},
+		{"log.requests", current.Log.Requests, next.Log.Requests},
+	}
+	for _, s := range restartOnly {
+		if !reflect.DeepEqual(s.old, s.next) {
+			plan.RestartRequired = append(plan.RestartRequired, s.name)
+		}
+	}
+
+	return plan
+}
+
+// MergeReloadable returns a copy of c with the hot-reloadable fields taken from next.
+func (c *Config) MergeReloadable(next *Config) *Config {
+	merged := *c
+	merged.Log.Level = next.Log.Level
+	merged.Governance = next.Governance
+	merged.GRPC.TaskTimeoutSec = next.GRPC.TaskTimeoutSec
+	merged.GRPC.MaxTaskTimeoutSec = next.GRPC.MaxTaskTimeoutSec
+	merged.GRPC.DebugContext = next.GRPC.DebugContext
+	merged.GRPC.HealthGate = next.GRPC.HealthGate
+	merged.Agents = next.Agents
+	return &merged
+}
+
+// SlogLevel maps the configured level name to a slog.Level, defaulting to info.
+func (c LogConfig) SlogLevel() slog.Level {
+	switch c.Level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
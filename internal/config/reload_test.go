@@ -0,0 +1,69 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestDiff_ReloadableAndRestartFields(t *testing.T) {
+	current := validConfig()
+	current.Log.Level = "info"
+	current.Governance.MaxTokensPerDay = 1000
+
+	next := validConfig()
+	next.Log.Level = "debug"
+	next.Governance.MaxTokensPerDay = 2000
+	next.Server.Port = 9090
+	next.JWT.AccessSecret = "a-different-access-secret-of-32-chars!!"
+
+	plan := Diff(current, next)
+
+	if len(plan.Apply) != 2 {
+		t.Fatalf("expected 2 reloadable changes, got %+v", plan.Apply)
+	}
+	if plan.Apply[0].Field != "log.level" || plan.Apply[0].Old != "info" || plan.Apply[0].New != "debug" {
+		t.Errorf("unexpected log change: %+v", plan.Apply[0])
+	}
+	if plan.Apply[1].Field != "governance.max_tokens_per_day" || plan.Apply[1].New != "2000" {
+		t.Errorf("unexpected governance change: %+v", plan.Apply[1])
+	}
+
+	if len(plan.RestartRequired) != 2 || plan.RestartRequired[0] != "server" || plan.RestartRequired[1] != "jwt" {
+		t.Errorf("expected server and jwt to require restart, got %v", plan.RestartRequired)
+	}
+}
+
+func TestMergeReloadable_KeepsRestartOnlyFields(t *testing.T) {
+	current := validConfig()
+	next := validConfig()
+	next.Server.Port = 9090
+	next.GRPC.TaskTimeoutSec = 30
+	next.GRPC.DebugContext = true
+	next.Log.Level = "warn"
+
+	merged := current.MergeReloadable(next)
+	if merged.Server.Port != current.Server.Port {
+		t.Errorf("server port should not be reloaded, got %d", merged.Server.Port)
+	}
+	if merged.GRPC.TaskTimeoutSec != 30 || !merged.GRPC.DebugContext || merged.Log.Level != "warn" {
+		t.Errorf("reloadable fields not merged: %+v %+v", merged.GRPC, merged.Log)
+	}
+	if current.Log.Level == "warn" {
+		t.Error("MergeReloadable must not mutate the receiver")
+	}
+}
+
+func TestLogConfig_SlogLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"":      slog.LevelInfo,
+	}
+	for name, want := range cases {
+		if got := (LogConfig{Level: name}).SlogLevel(); got != want {
+			t.Errorf("level %q: got %v, want %v", name, got, want)
+		}
+	}
+}
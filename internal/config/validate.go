@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 )
 
 // Validate checks Config for production-critical problems.
@@ -32,6 +34,11 @@ func (c *Config) Validate() error {
 	} else if _, err := hex.DecodeString(c.Encryption.Key); err != nil {
 		errs = append(errs, "ENCRYPTION_KEY must be valid hex")
 	}
+	for i, key := range c.Encryption.PreviousKeys {
+		if _, err := hex.DecodeString(key); err != nil || len(key) != 64 {
+			errs = append(errs, fmt.Sprintf("ENCRYPTION_PREVIOUS_KEYS entry %d must be 64 hex characters", i+1))
+		}
+	}
 
 	// DB password
 	if c.DB.Password == "" {
@@ -52,6 +59,101 @@ func (c *Config) Validate() error {
 		errs = append(errs, fmt.Sprintf("GRPC_PORT must be 1–65535, got %d", c.GRPC.Port))
 	}
 
+	// Connection pool
+	if c.DB.MaxConns < 1 {
+		errs = append(errs, fmt.Sprintf("DB_MAX_CONNS must be at least 1, got %d", c.DB.MaxConns))
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+		errs = append(errs, fmt.Sprintf("DB_MIN_CONNS must be 0–DB_MAX_CONNS (%d), got %d", c.DB.MaxConns, c.DB.MinConns))
+	}
+	if c.DB.MaxConnLifetime <= 0 {
+		errs = append(errs, "DB_MAX_CONN_LIFETIME must be positive")
+	}
+	if c.DB.MaxConnIdleTime <= 0 {
+		errs = append(errs, "DB_MAX_CONN_IDLE_TIME must be positive")
+	}
+
+	if c.GRPC.MaxTaskTimeoutSec > 0 && c.GRPC.MaxTaskTimeoutSec < c.GRPC.TaskTimeoutSec {
+		errs = append(errs, fmt.Sprintf("GRPC_MAX_TASK_TIMEOUT_SEC must be at least GRPC_TASK_TIMEOUT_SEC (%d), got %d", c.GRPC.TaskTimeoutSec, c.GRPC.MaxTaskTimeoutSec))
+	}
+	if c.GRPC.HealthGate != "" && !slices.Contains([]string{"closed", "open"}, c.GRPC.HealthGate) {
+		errs = append(errs, fmt.Sprintf("GRPC_HEALTH_GATE must be closed or open, got %q", c.GRPC.HealthGate))
+	}
+
+	if c.Governance.MaxTokensPerRequest < 0 {
+		errs = append(errs, fmt.Sprintf("GOVERNANCE_MAX_TOKENS_PER_REQUEST must not be negative, got %d", c.Governance.MaxTokensPerRequest))
+	}
+	if c.Governance.MinTemperature < 0 || c.Governance.MaxTemperature > 2 || c.Governance.MinTemperature > c.Governance.MaxTemperature {
+		errs = append(errs, fmt.Sprintf("GOVERNANCE_MIN_TEMPERATURE and GOVERNANCE_MAX_TEMPERATURE must satisfy 0 ≤ min ≤ max ≤ 2, got %g and %g", c.Governance.MinTemperature, c.Governance.MaxTemperature))
+	}
+
+	if c.Agents.MaxSystemPromptLength < 1 {
+		errs = append(errs, fmt.Sprintf("AGENT_MAX_SYSTEM_PROMPT_LENGTH must be at least 1, got %d", c.Agents.MaxSystemPromptLength))
+	}
+	if c.Agents.SystemPromptExternalThreshold < -1 {
+		errs = append(errs, fmt.Sprintf("AGENT_SYSTEM_PROMPT_EXTERNAL_THRESHOLD must be -1 or positive, got %d", c.Agents.SystemPromptExternalThreshold))
+	}
+
+	if c.XMPP.OutboundMaxAttempts < 0 {
+		errs = append(errs, fmt.Sprintf("XMPP_OUTBOUND_MAX_ATTEMPTS must not be negative, got %d", c.XMPP.OutboundMaxAttempts))
+	}
+	if c.XMPP.RoutingFailure != "" && !slices.Contains([]string{"reply", "audit", "silent"}, c.XMPP.RoutingFailure) {
+		errs = append(errs, fmt.Sprintf("XMPP_ROUTING_FAILURE must be reply, audit, or silent, got %q", c.XMPP.RoutingFailure))
+	}
+
+	// JetStream caps replicas at 5
+	if c.NATS.Streams.Replicas < 0 || c.NATS.Streams.Replicas > 5 {
+		errs = append(errs, fmt.Sprintf("NATS_STREAM_REPLICAS must be 1–5, got %d", c.NATS.Streams.Replicas))
+	}
+	for name, d := range map[string]time.Duration{
+		"NATS_MESSAGES_MAX_AGE": c.NATS.Streams.MessagesMaxAge,
+		"NATS_TASKS_MAX_AGE":    c.NATS.Streams.TasksMaxAge,
+		"NATS_EVENTS_MAX_AGE":   c.NATS.Streams.EventsMaxAge,
+		"NATS_DLQ_MAX_AGE":      c.NATS.Streams.DeadLetterMaxAge,
+		"NATS_ACK_WAIT":         c.NATS.Consumers.AckWait,
+		"NATS_NAK_BACKOFF_BASE": c.NATS.Consumers.BackoffBase,
+		"NATS_NAK_BACKOFF_MAX":  c.NATS.Consumers.BackoffMax,
+		"DB_RETRY_BASE_DELAY":   c.DB.RetryBaseDelay,
+		"DB_RETRY_MAX_DELAY":    c.DB.RetryMaxDelay,
+		"DB_BREAKER_COOLDOWN":   c.DB.BreakerCooldown,
+	} {
+		if d < 0 {
+			errs = append(errs, fmt.Sprintf("%s must not be negative", name))
+		}
+	}
+	if c.NATS.Consumers.BackoffMax > 0 && c.NATS.Consumers.BackoffMax < c.NATS.Consumers.BackoffBase {
+		errs = append(errs, "NATS_NAK_BACKOFF_MAX must not be less than NATS_NAK_BACKOFF_BASE")
+	}
+	// A consumer that stops redelivering first would strand tasks in the work queue
+	if c.NATS.Consumers.MaxDeliver > 0 && c.NATS.MaxDeliveries > 0 && c.NATS.Consumers.MaxDeliver <= c.NATS.MaxDeliveries {
+		errs = append(errs, fmt.Sprintf("NATS_CONSUMER_MAX_DELIVER must exceed NATS_MAX_DELIVERIES (%d), got %d", c.NATS.MaxDeliveries, c.NATS.Consumers.MaxDeliver))
+	}
+
+	// Browsers reject credentialed responses with a wildcard origin
+	if c.Server.CORSAllowCredentials && slices.Contains(c.Server.CORSAllowedOrigins, "*") {
+		errs = append(errs, "CORS_ALLOW_CREDENTIALS cannot be true when CORS_ALLOWED_ORIGINS contains \"*\"")
+	}
+
+	// bcrypt ignores bytes past 72, so a longer minimum could never be checked
+	if c.Password.MinLength > 72 {
+		errs = append(errs, fmt.Sprintf("PASSWORD_MIN_LENGTH must be at most 72, got %d", c.Password.MinLength))
+	}
+
+	logFields := []string{"method", "path", "status", "latency", "user_id", "request_id", "remote_addr", "headers"}
+	for name, fields := range map[string][]string{
+		"LOG_REQUEST_FIELDS":         c.Log.Requests.Fields,
+		"LOG_REQUEST_EXCLUDE_FIELDS": c.Log.Requests.ExcludeFields,
+	} {
+		for _, f := range fields {
+			if !slices.Contains(logFields, f) {
+				errs = append(errs, fmt.Sprintf("%s: unknown field %q (want %s)", name, f, strings.Join(logFields, ", ")))
+			}
+		}
+	}
+	if c.Log.Requests.SampleRate < 0 {
+		errs = append(errs, fmt.Sprintf("LOG_REQUEST_SAMPLE_RATE must not be negative, got %d", c.Log.Requests.SampleRate))
+	}
+
 	// Worker API key: warn only
 	if c.GRPC.WorkerAPIKey == "" {
 		slog.Warn("GRPC_WORKER_API_KEY is empty — gRPC server has no authentication")
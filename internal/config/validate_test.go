@@ -12,6 +12,7 @@ func validConfig() *Config {
 		DB: DBConfig{
 			Host: "localhost", Port: 5432, User: "aiox",
 			Password: "secret", Name: "aiox", SSLMode: "disable", MaxConns: 25,
+			MinConns: 2, MaxConnLifetime: time.Hour, MaxConnIdleTime: 5 * time.Minute,
 		},
 		Redis: RedisConfig{Host: "localhost", Port: 6379},
 		JWT: JWTConfig{
@@ -22,6 +23,8 @@ func validConfig() *Config {
 		},
 		Encryption: EncryptionConfig{Key: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
 		GRPC:       GRPCConfig{Host: "0.0.0.0", Port: 50051, WorkerAPIKey: "some-key"},
+		Governance: GovernanceCfg{MaxTemperature: 2},
+		Agents:     AgentsConfig{MaxSystemPromptLength: 100000, SystemPromptExternalThreshold: 8192},
 	}
 }
 
@@ -112,6 +115,106 @@ func TestValidate_InvalidPorts(t *testing.T) {
 	}
 }
 
+func TestValidate_CORSCredentialsWithWildcard(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.CORSAllowedOrigins = []string{"https://app.example.com", "*"}
+	cfg.Server.CORSAllowCredentials = true
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "CORS_ALLOW_CREDENTIALS") {
+		t.Errorf("expected CORS_ALLOW_CREDENTIALS error, got %v", err)
+	}
+
+	cfg.Server.CORSAllowCredentials = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("wildcard origin without credentials should be valid: %v", err)
+	}
+}
+
+func TestValidate_PasswordMinLengthTooLong(t *testing.T) {
+	cfg := validConfig()
+	cfg.Password.MinLength = 73
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "PASSWORD_MIN_LENGTH") {
+		t.Errorf("expected PASSWORD_MIN_LENGTH error, got %v", err)
+	}
+}
+
+func TestValidate_NATSStreams(t *testing.T) {
+	cfg := validConfig()
+	cfg.NATS.Streams.Replicas = 7
+	cfg.NATS.Streams.TasksMaxAge = -time.Minute
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "NATS_STREAM_REPLICAS") || !strings.Contains(err.Error(), "NATS_TASKS_MAX_AGE") {
+		t.Fatalf("expected NATS stream errors, got: %v", err)
+	}
+}
+
+func TestValidate_NATSConsumers(t *testing.T) {
+	cfg := validConfig()
+	cfg.NATS.MaxDeliveries = 20
+	cfg.NATS.Consumers.MaxDeliver = 10
+	cfg.NATS.Consumers.BackoffBase = time.Minute
+	cfg.NATS.Consumers.BackoffMax = time.Second
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "NATS_CONSUMER_MAX_DELIVER") || !strings.Contains(err.Error(), "NATS_NAK_BACKOFF_MAX") {
+		t.Fatalf("expected NATS consumer errors, got: %v", err)
+	}
+}
+
+func TestValidate_DBPool(t *testing.T) {
+	cfg := validConfig()
+	cfg.DB.MinConns = 30
+	cfg.DB.MaxConnLifetime = 0
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "DB_MIN_CONNS") || !strings.Contains(err.Error(), "DB_MAX_CONN_LIFETIME") {
+		t.Fatalf("expected DB pool errors, got: %v", err)
+	}
+}
+
+func TestValidate_SystemPromptLimits(t *testing.T) {
+	cfg := validConfig()
+	cfg.Agents.SystemPromptExternalThreshold = -1
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("-1 should disable external prompt storage, got: %v", err)
+	}
+	cfg.Agents.MaxSystemPromptLength = 0
+	cfg.Agents.SystemPromptExternalThreshold = -2
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "AGENT_MAX_SYSTEM_PROMPT_LENGTH") || !strings.Contains(err.Error(), "AGENT_SYSTEM_PROMPT_EXTERNAL_THRESHOLD") {
+		t.Fatalf("expected system prompt limit errors, got: %v", err)
+	}
+}
+
+func TestValidate_LLMCaps(t *testing.T) {
+	cfg := validConfig()
+	cfg.Governance.MinTemperature = 0.5
+	cfg.Governance.MaxTemperature = 0.2
+	cfg.Governance.MaxTokensPerRequest = -1
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "GOVERNANCE_MIN_TEMPERATURE") || !strings.Contains(err.Error(), "GOVERNANCE_MAX_TOKENS_PER_REQUEST") {
+		t.Fatalf("expected LLM cap errors, got: %v", err)
+	}
+}
+
+func TestValidate_HealthGate(t *testing.T) {
+	cfg := validConfig()
+	cfg.GRPC.HealthGate = "fail-open"
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "GRPC_HEALTH_GATE") {
+		t.Fatalf("expected health gate error, got: %v", err)
+	}
+}
+
+func TestValidate_MaxTaskTimeout(t *testing.T) {
+	cfg := validConfig()
+	cfg.GRPC.TaskTimeoutSec = 120
+	cfg.GRPC.MaxTaskTimeoutSec = 60
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "GRPC_MAX_TASK_TIMEOUT_SEC") {
+		t.Fatalf("expected max task timeout error, got: %v", err)
+	}
+}
+
 func TestValidate_MultipleErrors(t *testing.T) {
 	cfg := &Config{
 		Server: ServerConfig{Port: 0},
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 
@@ -9,14 +10,24 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// RunMigrations applies all pending up-migrations.
-func RunMigrations(dsn, migrationsPath string) error {
+// NewMigrator opens a golang-migrate instance for the migrations in
+// migrationsPath. Callers must Close it.
+func NewMigrator(dsn, migrationsPath string) (*migrate.Migrate, error) {
 	m, err := migrate.New(
 		fmt.Sprintf("file://%s", migrationsPath),
 		dsn,
 	)
 	if err != nil {
-		return fmt.Errorf("creating migrator: %w", err)
+		return nil, fmt.Errorf("creating migrator: %w", err)
+	}
+	return m, nil
+}
+
+// RunMigrations applies all pending up-migrations.
+func RunMigrations(dsn, migrationsPath string) error {
+	m, err := NewMigrator(dsn, migrationsPath)
+	if err != nil {
+		return err
 	}
 	defer m.Close()
 
@@ -28,3 +39,16 @@ func RunMigrations(dsn, migrationsPath string) error {
 	slog.Info("database migrations applied", "version", ver, "dirty", dirty)
 	return nil
 }
+
+// MigrationVersion returns the applied schema version and whether the last
+// migration failed midway. ok is false when no migration has been applied.
+func MigrationVersion(m *migrate.Migrate) (version uint, dirty, ok bool, err error) {
+	version, dirty, err = m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, fmt.Errorf("reading migration version: %w", err)
+	}
+	return version, dirty, true, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+// PoolStatsInterval is how often MonitorPool samples the pool.
+const PoolStatsInterval = 15 * time.Second
+
+// saturatedSamples is how many samples in a row must find the pool saturated
+// before MonitorPool warns: a minute at PoolStatsInterval.
+const saturatedSamples = 4
+
+// PoolStat is the part of *pgxpool.Stat that MonitorPool reads.
+type PoolStat interface {
+	AcquiredConns() int32
+	IdleConns() int32
+	TotalConns() int32
+	MaxConns() int32
+	AcquireCount() int64
+	AcquireDuration() time.Duration
+	EmptyAcquireCount() int64
+}
+
+// MonitorPool exports pool statistics as Prometheus metrics every
+// PoolStatsInterval until ctx is canceled, and warns when the pool stays
+// saturated.
+func MonitorPool(ctx context.Context, pool *pgxpool.Pool) {
+	m := &poolMonitor{}
+	m.sample(pool.Stat())
+
+	ticker := time.NewTicker(PoolStatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sample(pool.Stat())
+		}
+	}
+}
+
+// poolMonitor turns the pool's cumulative counts into counter increments and
+// tracks how long the pool has been saturated.
+type poolMonitor struct {
+	last      PoolStat
+	saturated int
+}
+
+func (m *poolMonitor) sample(s PoolStat) {
+	metrics.DBPoolConns.WithLabelValues("acquired").Set(float64(s.AcquiredConns()))
+	metrics.DBPoolConns.WithLabelValues("idle").Set(float64(s.IdleConns()))
+	metrics.DBPoolConns.WithLabelValues("total").Set(float64(s.TotalConns()))
+	metrics.DBPoolMaxConns.Set(float64(s.MaxConns()))
+
+	var waited int64
+	if m.last != nil {
+		acquires := s.AcquireCount() - m.last.AcquireCount()
+		waited = s.EmptyAcquireCount() - m.last.EmptyAcquireCount()
+		metrics.DBPoolAcquiresTotal.Add(float64(acquires))
+		metrics.DBPoolEmptyAcquiresTotal.Add(float64(waited))
+		metrics.DBPoolAcquireWaitSeconds.Add((s.AcquireDuration() - m.last.AcquireDuration()).Seconds())
+	}
+	m.last = s
+
+	// Saturated: every connection is in use, or an acquire had to wait for one.
+	if s.AcquiredConns() < s.MaxConns() && waited == 0 {
+		if m.saturated >= saturatedSamples {
+			slog.Info("postgres pool no longer saturated", "acquired", s.AcquiredConns(), "max_conns", s.MaxConns())
+		}
+		m.saturated = 0
+		return
+	}
+	m.saturated++
+	if m.saturated == saturatedSamples {
+		slog.Warn("postgres pool saturated; consider raising DB_MAX_CONNS",
+			"acquired", s.AcquiredConns(), "max_conns", s.MaxConns(), "waiting_acquires", waited,
+			"for", time.Duration(saturatedSamples)*PoolStatsInterval)
+	}
+}
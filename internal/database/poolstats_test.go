@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+type fakeStat struct {
+	acquired, idle, max int32
+	acquires, empty     int64
+	wait                time.Duration
+}
+
+func (s fakeStat) AcquiredConns() int32           { return s.acquired }
+func (s fakeStat) IdleConns() int32               { return s.idle }
+func (s fakeStat) TotalConns() int32              { return s.acquired + s.idle }
+func (s fakeStat) MaxConns() int32                { return s.max }
+func (s fakeStat) AcquireCount() int64            { return s.acquires }
+func (s fakeStat) AcquireDuration() time.Duration { return s.wait }
+func (s fakeStat) EmptyAcquireCount() int64       { return s.empty }
+
+func TestPoolMonitor_Sample(t *testing.T) {
+	m := &poolMonitor{}
+	acquiresBefore := testutil.ToFloat64(metrics.DBPoolAcquiresTotal)
+	waitBefore := testutil.ToFloat64(metrics.DBPoolAcquireWaitSeconds)
+
+	m.sample(fakeStat{acquired: 2, idle: 3, max: 10, acquires: 100, wait: time.Second})
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.DBPoolConns.WithLabelValues("acquired")))
+	assert.Equal(t, 5.0, testutil.ToFloat64(metrics.DBPoolConns.WithLabelValues("total")))
+	assert.Equal(t, 10.0, testutil.ToFloat64(metrics.DBPoolMaxConns))
+	assert.Equal(t, acquiresBefore, testutil.ToFloat64(metrics.DBPoolAcquiresTotal), "the first sample only sets the baseline")
+	assert.Zero(t, m.saturated)
+
+	m.sample(fakeStat{acquired: 10, max: 10, acquires: 130, wait: 3 * time.Second})
+	assert.Equal(t, acquiresBefore+30, testutil.ToFloat64(metrics.DBPoolAcquiresTotal))
+	assert.InDelta(t, waitBefore+2, testutil.ToFloat64(metrics.DBPoolAcquireWaitSeconds), 1e-9)
+	assert.Equal(t, 1, m.saturated, "every connection in use")
+
+	m.sample(fakeStat{acquired: 4, idle: 6, max: 10, acquires: 140, empty: 5, wait: 4 * time.Second})
+	assert.Equal(t, 2, m.saturated, "acquires had to wait")
+
+	m.sample(fakeStat{acquired: 4, idle: 6, max: 10, acquires: 150, empty: 5, wait: 4 * time.Second})
+	assert.Zero(t, m.saturated)
+}
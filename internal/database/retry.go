@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrUnavailable is returned when Postgres cannot serve a query: the circuit
+// breaker is open, or a transient error persisted through every retry.
+var ErrUnavailable = errors.New("database unavailable")
+
+// transientCodes are SQLSTATEs after which the same query can succeed.
+var transientCodes = map[string]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now
+}
+
+// IsTransient reports whether err is a connection failure or a Postgres error
+// that may not recur, such as a failover in progress. Context cancellation
+// and query errors like constraint violations are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection_exception.
+		return transientCodes[pgErr.Code] || strings.HasPrefix(pgErr.Code, "08")
+	}
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	return pgconn.SafeToRetry(err) || errors.As(err, &connErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// Retrier reruns idempotent reads that fail with a transient error, waiting
+// a jittered, doubling delay between attempts. Its Breaker, when set, fails
+// reads fast once Postgres looks down. A nil *Retrier runs each read once.
+type Retrier struct {
+	attempts  int
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	breaker   *Breaker
+}
+
+// NewRetrier makes up to attempts tries per read. breaker may be nil.
+func NewRetrier(attempts int, baseDelay, maxDelay time.Duration, breaker *Breaker) *Retrier {
+	return &Retrier{attempts: max(attempts, 1), baseDelay: baseDelay, maxDelay: maxDelay, breaker: breaker}
+}
+
+// Breaker returns the retrier's circuit breaker, or nil.
+func (r *Retrier) Breaker() *Breaker {
+	if r == nil {
+		return nil
+	}
+	return r.breaker
+}
+
+// Do runs fn, retrying transient errors. fn must be safe to run more than
+// once: never wrap writes with Do. When every attempt fails transiently, the
+// error wraps ErrUnavailable.
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r == nil {
+		return fn(ctx)
+	}
+	var err error
+	for attempt := range r.attempts {
+		if attempt > 0 {
+			select {
+			case <-time.After(r.backoff(attempt)):
+			case <-ctx.Done():
+				return err
+			}
+		}
+		if !r.breaker.allow() {
+			return ErrUnavailable
+		}
+		err = fn(ctx)
+		r.breaker.record(err)
+		if !IsTransient(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
+
+// backoff returns a random delay up to baseDelay·2^(attempt-1), capped at
+// maxDelay, so retries from concurrent requests spread out.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	d := r.baseDelay << (attempt - 1)
+	if d <= 0 || (r.maxDelay > 0 && d > r.maxDelay) {
+		d = r.maxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d) + 1
+}
+
+// Circuit breaker states, as reported by Breaker.State.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// Breaker opens after threshold consecutive transient failures and then
+// rejects reads for cooldown. After that it lets a single probe through:
+// success closes it, failure reopens it. A nil *Breaker is always closed.
+type Breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker returns a closed breaker.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: max(threshold, 1), cooldown: cooldown, now: time.Now}
+}
+
+// State returns BreakerClosed, BreakerOpen, or BreakerHalfOpen.
+func (b *Breaker) State() string {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state()
+}
+
+func (b *Breaker) state() string {
+	switch {
+	case b.failures < b.threshold:
+		return BreakerClosed
+	case b.now().Sub(b.openedAt) < b.cooldown:
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// allow reports whether a read may run; while half-open only one probe is
+// in flight at a time.
+func (b *Breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state() {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return false
+	}
+}
+
+// record counts transient failures; any other outcome, including query
+// errors, shows Postgres is reachable and closes the breaker. A canceled
+// read says nothing either way.
+func (b *Breaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	if !IsTransient(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errConnReset = &pgconn.PgError{Code: "08006", Message: "connection failure"}
+
+func TestIsTransient(t *testing.T) {
+	cases := map[error]bool{
+		errConnReset:                                         true,
+		&pgconn.PgError{Code: "57P01"}:                       true,
+		&pgconn.PgError{Code: "40001"}:                       true,
+		fmt.Errorf("getting agent: %w", io.ErrUnexpectedEOF): true,
+		&pgconn.PgError{Code: "23505"}:                       false, // unique_violation
+		pgx.ErrNoRows:                                        false,
+		context.Canceled:                                     false,
+		nil:                                                  false,
+	}
+	for err, want := range cases {
+		assert.Equal(t, want, IsTransient(err), "%v", err)
+	}
+}
+
+func TestRetrier_Do(t *testing.T) {
+	r := NewRetrier(3, time.Millisecond, 5*time.Millisecond, nil)
+	ctx := context.Background()
+
+	calls := 0
+	err := r.Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errConnReset
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls, "transient errors are retried")
+
+	calls = 0
+	err = r.Do(ctx, func(context.Context) error {
+		calls++
+		return pgx.ErrNoRows
+	})
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.Equal(t, 1, calls, "other errors are returned at once")
+
+	calls = 0
+	err = r.Do(ctx, func(context.Context) error {
+		calls++
+		return errConnReset
+	})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorIs(t, err, errConnReset)
+	assert.Equal(t, 3, calls)
+
+	var nilRetrier *Retrier
+	calls = 0
+	assert.ErrorIs(t, nilRetrier.Do(ctx, func(context.Context) error { calls++; return errConnReset }), errConnReset)
+	assert.Equal(t, 1, calls, "a nil retrier runs once")
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(2, 10*time.Second)
+	b.now = func() time.Time { return now }
+	r := NewRetrier(1, 0, 0, b)
+	ctx := context.Background()
+	failing := func(context.Context) error { return errConnReset }
+
+	assert.ErrorIs(t, r.Do(ctx, failing), ErrUnavailable)
+	assert.Equal(t, BreakerClosed, b.State())
+	assert.ErrorIs(t, r.Do(ctx, failing), ErrUnavailable)
+	assert.Equal(t, BreakerOpen, b.State())
+
+	calls := 0
+	err := r.Do(ctx, func(context.Context) error { calls++; return nil })
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Zero(t, calls, "an open breaker fails fast")
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.ErrorIs(t, r.Do(ctx, failing), ErrUnavailable)
+	assert.Equal(t, BreakerOpen, b.State(), "a failed probe reopens the breaker")
+
+	now = now.Add(10 * time.Second)
+	require.NoError(t, r.Do(ctx, func(context.Context) error { return nil }))
+	assert.Equal(t, BreakerClosed, b.State(), "a successful probe closes the breaker")
+}
@@ -0,0 +1,249 @@
+// Package export streams a copy of everything the platform stores for a user,
+// for data portability and backups.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/governance/audit"
+	"github.com/aiox-platform/aiox/internal/memory"
+	"github.com/aiox-platform/aiox/internal/worker"
+)
+
+// batchSize is how many rows are read per query, bounding memory use
+// regardless of account size.
+const batchSize = 200
+
+// Resources that can be exported one at a time.
+const (
+	ResourceAgents     = "agents"
+	ResourceMemories   = "memories"
+	ResourceExecutions = "executions"
+	ResourceAuditLogs  = "audit_logs"
+)
+
+// Resources lists every exportable resource in archive order.
+var Resources = []string{ResourceAgents, ResourceMemories, ResourceExecutions, ResourceAuditLogs}
+
+// AgentLister lists an owner's agents with their decrypted system prompts;
+// satisfied by *agents.Service.
+type AgentLister interface {
+	ListByOwner(ctx context.Context, ownerID uuid.UUID, params agents.ListAgentsParams) ([]*agents.Agent, int64, error)
+}
+
+// MemoryLister pages through an agent's long-term memories; satisfied by
+// memory.Repository.
+type MemoryLister interface {
+	ListByAgentAfter(ctx context.Context, agentID, ownerUserID uuid.UUID, after *memory.Cursor, limit int, filter memory.MetadataFilter) ([]memory.Memory, error)
+}
+
+// ExecutionLister pages through an owner's executions; satisfied by
+// *worker.Repository.
+type ExecutionLister interface {
+	ListByOwnerAfter(ctx context.Context, ownerID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]worker.Execution, error)
+}
+
+// AuditLister pages through an owner's audit logs; satisfied by
+// *audit.Repository.
+type AuditLister interface {
+	ListByOwnerAfter(ctx context.Context, ownerUserID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]audit.AuditLog, error)
+}
+
+// Exporter writes a user's agents, memories, executions, and audit logs.
+type Exporter struct {
+	agents     AgentLister
+	memories   MemoryLister
+	executions ExecutionLister
+	audit      AuditLister
+}
+
+// NewExporter creates a new Exporter.
+func NewExporter(agents AgentLister, memories MemoryLister, executions ExecutionLister, audit AuditLister) *Exporter {
+	return &Exporter{agents: agents, memories: memories, executions: executions, audit: audit}
+}
+
+// WriteZIP writes a ZIP archive holding agents.json, memories/<agent-id>.json
+// for each agent, executions.json, and audit_logs.json. Each file is a JSON
+// array written as rows are read, so nothing is buffered in full.
+func (e *Exporter) WriteZIP(ctx context.Context, w io.Writer, ownerID uuid.UUID) error {
+	zw := zip.NewWriter(w)
+
+	var agentIDs []uuid.UUID
+	err := writeArray(zw, "agents.json", func(emit func(any) error) error {
+		return e.eachAgent(ctx, ownerID, func(a *agents.Agent) error {
+			agentIDs = append(agentIDs, a.ID)
+			return emit(a)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, agentID := range agentIDs {
+		err := writeArray(zw, "memories/"+agentID.String()+".json", func(emit func(any) error) error {
+			return e.eachMemory(ctx, ownerID, agentID, func(m memory.Memory) error { return emit(m) })
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeArray(zw, "executions.json", func(emit func(any) error) error {
+		return e.eachExecution(ctx, ownerID, func(x worker.Execution) error { return emit(x) })
+	})
+	if err != nil {
+		return err
+	}
+
+	err = writeArray(zw, "audit_logs.json", func(emit func(any) error) error {
+		return e.eachAuditLog(ctx, ownerID, func(l audit.AuditLog) error { return emit(l) })
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// WriteNDJSON writes one resource as newline-delimited JSON, one object per
+// line. Memories of all agents are written in agent order.
+func (e *Exporter) WriteNDJSON(ctx context.Context, w io.Writer, ownerID uuid.UUID, resource string) error {
+	enc := json.NewEncoder(w)
+	emit := func(v any) error { return enc.Encode(v) }
+
+	switch resource {
+	case ResourceAgents:
+		return e.eachAgent(ctx, ownerID, func(a *agents.Agent) error { return emit(a) })
+	case ResourceMemories:
+		return e.eachAgent(ctx, ownerID, func(a *agents.Agent) error {
+			return e.eachMemory(ctx, ownerID, a.ID, func(m memory.Memory) error { return emit(m) })
+		})
+	case ResourceExecutions:
+		return e.eachExecution(ctx, ownerID, func(x worker.Execution) error { return emit(x) })
+	case ResourceAuditLogs:
+		return e.eachAuditLog(ctx, ownerID, func(l audit.AuditLog) error { return emit(l) })
+	default:
+		return fmt.Errorf("unknown export resource %q", resource)
+	}
+}
+
+func (e *Exporter) eachAgent(ctx context.Context, ownerID uuid.UUID, fn func(*agents.Agent) error) error {
+	params := agents.ListAgentsParams{Page: 1, PageSize: batchSize}
+	for {
+		page, _, err := e.agents.ListByOwner(ctx, ownerID, params)
+		if err != nil {
+			return fmt.Errorf("listing agents: %w", err)
+		}
+		for _, a := range page {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		if len(page) < batchSize {
+			return nil
+		}
+		params.Page++
+	}
+}
+
+func (e *Exporter) eachMemory(ctx context.Context, ownerID, agentID uuid.UUID, fn func(memory.Memory) error) error {
+	var after *memory.Cursor
+	for {
+		page, err := e.memories.ListByAgentAfter(ctx, agentID, ownerID, after, batchSize, nil)
+		if err != nil {
+			return fmt.Errorf("listing memories: %w", err)
+		}
+		for _, m := range page {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		if len(page) < batchSize {
+			return nil
+		}
+		last := page[len(page)-1]
+		after = &memory.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+func (e *Exporter) eachExecution(ctx context.Context, ownerID uuid.UUID, fn func(worker.Execution) error) error {
+	var (
+		afterCreatedAt time.Time
+		afterID        uuid.UUID
+	)
+	for {
+		page, err := e.executions.ListByOwnerAfter(ctx, ownerID, afterCreatedAt, afterID, batchSize)
+		if err != nil {
+			return fmt.Errorf("listing executions: %w", err)
+		}
+		for _, x := range page {
+			if err := fn(x); err != nil {
+				return err
+			}
+		}
+		if len(page) < batchSize {
+			return nil
+		}
+		afterCreatedAt, afterID = page[len(page)-1].CreatedAt, page[len(page)-1].ID
+	}
+}
+
+func (e *Exporter) eachAuditLog(ctx context.Context, ownerID uuid.UUID, fn func(audit.AuditLog) error) error {
+	var (
+		afterCreatedAt time.Time
+		afterID        uuid.UUID
+	)
+	for {
+		page, err := e.audit.ListByOwnerAfter(ctx, ownerID, afterCreatedAt, afterID, batchSize)
+		if err != nil {
+			return fmt.Errorf("listing audit logs: %w", err)
+		}
+		for _, l := range page {
+			if err := fn(l); err != nil {
+				return err
+			}
+		}
+		if len(page) < batchSize {
+			return nil
+		}
+		afterCreatedAt, afterID = page[len(page)-1].CreatedAt, page[len(page)-1].ID
+	}
+}
+
+// writeArray adds name to the archive as a JSON array of the values fill
+// emits.
+func writeArray(zw *zip.Writer, name string, fill func(emit func(any) error) error) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("adding %s: %w", name, err)
+	}
+	if _, err := io.WriteString(f, "["); err != nil {
+		return err
+	}
+	sep := "\n"
+	err = fill(func(v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshaling %s entry: %w", name, err)
+		}
+		if _, err := io.WriteString(f, sep); err != nil {
+			return err
+		}
+		sep = ",\n"
+		_, err = f.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, "\n]\n")
+	return err
+}
@@ -0,0 +1,161 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/governance/audit"
+	"github.com/aiox-platform/aiox/internal/memory"
+	"github.com/aiox-platform/aiox/internal/worker"
+)
+
+type fakeAgents []*agents.Agent
+
+func (f fakeAgents) ListByOwner(_ context.Context, _ uuid.UUID, params agents.ListAgentsParams) ([]*agents.Agent, int64, error) {
+	start := min((params.Page-1)*params.PageSize, len(f))
+	end := min(start+params.PageSize, len(f))
+	return f[start:end], int64(len(f)), nil
+}
+
+// fakeMemories holds each agent's memories newest first.
+type fakeMemories map[uuid.UUID][]memory.Memory
+
+func (f fakeMemories) ListByAgentAfter(_ context.Context, agentID, _ uuid.UUID, after *memory.Cursor, limit int, _ memory.MetadataFilter) ([]memory.Memory, error) {
+	var out []memory.Memory
+	for _, m := range f[agentID] {
+		if after != nil && !m.CreatedAt.Before(after.CreatedAt) {
+			continue
+		}
+		if len(out) < limit {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// fakeExecutions holds executions oldest first and counts the pages read.
+type fakeExecutions struct {
+	rows  []worker.Execution
+	pages int
+}
+
+func (f *fakeExecutions) ListByOwnerAfter(_ context.Context, _ uuid.UUID, afterCreatedAt time.Time, _ uuid.UUID, limit int) ([]worker.Execution, error) {
+	f.pages++
+	var out []worker.Execution
+	for _, x := range f.rows {
+		if x.CreatedAt.After(afterCreatedAt) && len(out) < limit {
+			out = append(out, x)
+		}
+	}
+	return out, nil
+}
+
+type fakeAudit []audit.AuditLog
+
+func (f fakeAudit) ListByOwnerAfter(_ context.Context, _ uuid.UUID, afterCreatedAt time.Time, _ uuid.UUID, limit int) ([]audit.AuditLog, error) {
+	var out []audit.AuditLog
+	for _, l := range f {
+		if l.CreatedAt.After(afterCreatedAt) && len(out) < limit {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func newTestExporter(t *testing.T) (*Exporter, *fakeExecutions, uuid.UUID) {
+	t.Helper()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	agentID := uuid.New()
+
+	as := fakeAgents{
+		{ID: agentID, Profile: agents.AgentProfile{Name: "support", SystemPrompt: "You are helpful."}},
+		{ID: uuid.New(), Profile: agents.AgentProfile{Name: "empty"}},
+	}
+	mems := fakeMemories{agentID: {
+		{ID: uuid.New(), AgentID: agentID, Content: "newer", CreatedAt: base.Add(time.Hour)},
+		{ID: uuid.New(), AgentID: agentID, Content: "older", CreatedAt: base},
+	}}
+	execs := &fakeExecutions{}
+	for i := range batchSize + 5 {
+		execs.rows = append(execs.rows, worker.Execution{ID: uuid.New(), AgentID: agentID, Output: "ok", CreatedAt: base.Add(time.Duration(i+1) * time.Second)})
+	}
+	logs := fakeAudit{{ID: uuid.New(), EventType: "message_routed", CreatedAt: base}}
+
+	return NewExporter(as, mems, execs, logs), execs, agentID
+}
+
+func readZIPFile(t *testing.T, zr *zip.Reader, name string, v any) {
+	t.Helper()
+	f, err := zr.Open(name)
+	require.NoError(t, err, name)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v), name)
+}
+
+func TestWriteZIP(t *testing.T) {
+	e, execs, agentID := newTestExporter(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, e.WriteZIP(context.Background(), &buf, uuid.New()))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "agents.json")
+	assert.Contains(t, names, "memories/"+agentID.String()+".json")
+	assert.Len(t, names, 5, "one memories file per agent, even when empty")
+
+	var gotAgents []agents.Agent
+	readZIPFile(t, zr, "agents.json", &gotAgents)
+	require.Len(t, gotAgents, 2)
+	assert.Equal(t, "You are helpful.", gotAgents[0].Profile.SystemPrompt)
+
+	var gotMemories []memory.Memory
+	readZIPFile(t, zr, "memories/"+agentID.String()+".json", &gotMemories)
+	require.Len(t, gotMemories, 2)
+	assert.Equal(t, "newer", gotMemories[0].Content)
+
+	var gotExecs []worker.Execution
+	readZIPFile(t, zr, "executions.json", &gotExecs)
+	assert.Len(t, gotExecs, batchSize+5)
+	assert.Equal(t, 2, execs.pages, "executions are read in batches")
+
+	var gotLogs []audit.AuditLog
+	readZIPFile(t, zr, "audit_logs.json", &gotLogs)
+	assert.Len(t, gotLogs, 1)
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	e, _, _ := newTestExporter(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, e.WriteNDJSON(context.Background(), &buf, uuid.New(), ResourceMemories))
+
+	var contents []string
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var m memory.Memory
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &m))
+		contents = append(contents, m.Content)
+	}
+	assert.Equal(t, []string{"newer", "older"}, contents)
+
+	assert.Error(t, e.WriteNDJSON(context.Background(), &buf, uuid.New(), "users"))
+}
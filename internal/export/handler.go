@@ -0,0 +1,105 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// Handler serves account data exports.
+type Handler struct {
+	exporter *Exporter
+	audit    auth.AuditPublisher
+}
+
+// NewHandler creates a new Handler. A nil audit publisher disables the
+// data_export audit event.
+func NewHandler(exporter *Exporter, audit auth.AuditPublisher) *Handler {
+	return &Handler{exporter: exporter, audit: audit}
+}
+
+// Export streams the caller's data as a ZIP of JSON files, or with
+// ?format=ndjson&resource=<name> as one resource in newline-delimited JSON.
+// The response is written as rows are read, so a failure partway through
+// leaves it truncated rather than returning an error status.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	resource := r.URL.Query().Get("resource")
+	switch format {
+	case "", "zip":
+		format = "zip"
+	case "ndjson":
+		if !slices.Contains(Resources, resource) {
+			api.HandleError(w, api.NewValidationError("resource must be one of "+strings.Join(Resources, ", ")))
+			return
+		}
+	default:
+		api.HandleError(w, api.NewValidationError("format must be zip or ndjson"))
+		return
+	}
+
+	ctx := r.Context()
+	h.publishAudit(ctx, userID, format, resource)
+
+	// Large accounts take longer than the server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	stamp := time.Now().UTC().Format("20060102")
+	if format == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="aiox-export-%s.zip"`, stamp))
+		err = h.exporter.WriteZIP(ctx, w, userID)
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="aiox-%s-%s.ndjson"`, resource, stamp))
+		err = h.exporter.WriteNDJSON(ctx, w, userID, resource)
+	}
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(r.Context(), "exporting account data", "error", err, "user_id", userID, "format", format)
+	}
+}
+
+// publishAudit records the export before it starts, so an interrupted
+// export is audited too.
+func (h *Handler) publishAudit(ctx context.Context, userID uuid.UUID, format, resource string) {
+	if h.audit == nil {
+		return
+	}
+	details := "Exported account data as " + format
+	if resource != "" && format == "ndjson" {
+		details += " (" + resource + ")"
+	}
+	event := inats.AuditEvent{
+		OwnerUserID:  userID,
+		EventType:    "data_export",
+		Severity:     "info",
+		ResourceType: "user",
+		ResourceID:   userID.String(),
+		Details:      details,
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := h.audit.PublishAuditEvent(ctx, event); err != nil {
+		slog.Error("publishing audit event", "error", err, "event_type", "data_export")
+	}
+}
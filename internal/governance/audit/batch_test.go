@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// fakeWriter records inserted batches, or fails them with err. Logs whose
+// owner is in poisoned fail on their own with rowErr.
+type fakeWriter struct {
+	batches  [][]*AuditLog
+	err      error
+	poisoned map[uuid.UUID]bool
+	rowErr   error
+}
+
+func (w *fakeWriter) InsertBatch(_ context.Context, logs []*AuditLog) (map[int]error, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	failed := make(map[int]error)
+	var stored []*AuditLog
+	for i, log := range logs {
+		if w.poisoned[log.OwnerUserID] {
+			failed[i] = w.rowErr
+			continue
+		}
+		stored = append(stored, log)
+	}
+	w.batches = append(w.batches, stored)
+	return failed, nil
+}
+
+// fakeMsg is an audit event message that records how it was settled.
+type fakeMsg struct {
+	jetstream.Msg
+	data       []byte
+	acked      bool
+	nakked     bool
+	terminated bool
+}
+
+func (m *fakeMsg) Headers() nats.Header { return nil }
+func (m *fakeMsg) Data() []byte         { return m.data }
+func (m *fakeMsg) Ack() error           { m.acked = true; return nil }
+func (m *fakeMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: 1}, nil
+}
+func (m *fakeMsg) NakWithDelay(time.Duration) error { m.nakked = true; return nil }
+func (m *fakeMsg) TermWithReason(string) error      { m.terminated = true; return nil }
+
+// fakeBatch delivers a fixed set of messages.
+type fakeBatch struct {
+	jetstream.MessageBatch
+	ch chan jetstream.Msg
+}
+
+func (b *fakeBatch) Messages() <-chan jetstream.Msg { return b.ch }
+
+// fakeConsumer hands out one batch per Fetch and calls onFetch after the
+// batches run out.
+type fakeConsumer struct {
+	jetstream.Consumer
+	batches [][]*fakeMsg
+	onFetch func()
+}
+
+func (c *fakeConsumer) Fetch(int, ...jetstream.FetchOpt) (jetstream.MessageBatch, error) {
+	ch := make(chan jetstream.Msg, 16)
+	if len(c.batches) > 0 {
+		for _, m := range c.batches[0] {
+			ch <- m
+		}
+		c.batches = c.batches[1:]
+	} else if c.onFetch != nil {
+		c.onFetch()
+	}
+	close(ch)
+	return &fakeBatch{ch: ch}, nil
+}
+
+func auditMsgs(t *testing.T, n int) []*fakeMsg {
+	t.Helper()
+	msgs := make([]*fakeMsg, n)
+	for i := range msgs {
+		data, err := json.Marshal(inats.AuditEvent{OwnerUserID: uuid.New(), EventType: "task_completed", Severity: "info", Timestamp: time.Now().UTC()})
+		require.NoError(t, err)
+		msgs[i] = &fakeMsg{data: data}
+	}
+	return msgs
+}
+
+func testConsumer(w logWriter, batchSize int) *Consumer {
+	return &Consumer{
+		repo:          w,
+		consumerMgr:   inats.NewConsumerManager(nil),
+		batchSize:     batchSize,
+		flushInterval: time.Hour,
+	}
+}
+
+func TestConsumer_FlushesFullBatches(t *testing.T) {
+	w := &fakeWriter{}
+	c := testConsumer(w, 3)
+	msgs := auditMsgs(t, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.consume(ctx, &fakeConsumer{batches: [][]*fakeMsg{msgs[:2], msgs[2:]}, onFetch: cancel})
+
+	require.Len(t, w.batches, 1)
+	assert.Len(t, w.batches[0], 3)
+	for _, m := range msgs {
+		assert.True(t, m.acked)
+	}
+}
+
+func TestConsumer_ShutdownFlushesBuffer(t *testing.T) {
+	w := &fakeWriter{}
+	c := testConsumer(w, 100)
+	msgs := auditMsgs(t, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fc := &fakeConsumer{batches: [][]*fakeMsg{msgs}}
+	fc.onFetch = func() {
+		// Shut down with all five events still buffered.
+		assert.Empty(t, w.batches)
+		for _, m := range msgs {
+			assert.False(t, m.acked, "events are acked only after they are stored")
+		}
+		cancel()
+	}
+	c.consume(ctx, fc)
+
+	require.Len(t, w.batches, 1)
+	assert.Len(t, w.batches[0], 5)
+	for _, m := range msgs {
+		assert.True(t, m.acked)
+	}
+}
+
+func TestConsumer_FailedBatchIsNakked(t *testing.T) {
+	w := &fakeWriter{err: errors.New("database unavailable")}
+	c := testConsumer(w, 2)
+	msgs := auditMsgs(t, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.consume(ctx, &fakeConsumer{batches: [][]*fakeMsg{msgs}, onFetch: cancel})
+
+	for _, m := range msgs {
+		assert.False(t, m.acked)
+		assert.True(t, m.nakked)
+	}
+}
+
+func TestConsumer_PoisonedEventFailsAlone(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		rowErr     error
+		terminated bool
+	}{
+		{"deleted owner is terminated", fmt.Errorf("inserting audit log 1: %w", ErrUnknownOwner), true},
+		{"other failures are retried", errors.New("value too long"), false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := auditMsgs(t, 3)
+			var poisoned inats.AuditEvent
+			require.NoError(t, json.Unmarshal(msgs[1].data, &poisoned))
+			w := &fakeWriter{poisoned: map[uuid.UUID]bool{poisoned.OwnerUserID: true}, rowErr: tt.rowErr}
+			c := testConsumer(w, 3)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			c.consume(ctx, &fakeConsumer{batches: [][]*fakeMsg{msgs}, onFetch: cancel})
+
+			require.Len(t, w.batches, 1)
+			assert.Len(t, w.batches[0], 2)
+			for _, m := range []*fakeMsg{msgs[0], msgs[2]} {
+				assert.True(t, m.acked)
+				assert.False(t, m.nakked)
+			}
+			assert.False(t, msgs[1].acked)
+			assert.Equal(t, tt.terminated, msgs[1].terminated)
+			assert.Equal(t, !tt.terminated, msgs[1].nakked)
+		})
+	}
+}
@@ -3,62 +3,159 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go/jetstream"
 
+	"github.com/aiox-platform/aiox/internal/governance/redaction"
 	inats "github.com/aiox-platform/aiox/internal/nats"
 )
 
+// Events are written in batches of up to BatchSize, or every FlushInterval
+// when fewer arrive.
+const (
+	BatchSize     = 100
+	FlushInterval = time.Second
+)
+
+// logWriter persists audit logs. *Repository satisfies it.
+type logWriter interface {
+	InsertBatch(ctx context.Context, logs []*AuditLog) (map[int]error, error)
+}
+
 // Consumer listens on the audit event NATS subject and persists entries to the database.
 type Consumer struct {
-	repo        *Repository
+	repo        logWriter
 	consumerMgr *inats.ConsumerManager
+	redactor    redaction.Redactor
+
+	batchSize     int
+	flushInterval time.Duration
+	pending       []pendingLog
+}
+
+// pendingLog is a decoded event waiting for its batch to be written. Its
+// message is acked only once the batch is stored.
+type pendingLog struct {
+	msg jetstream.Msg
+	log *AuditLog
 }
 
 // NewConsumer creates a new audit event Consumer.
 func NewConsumer(repo *Repository, consumerMgr *inats.ConsumerManager) *Consumer {
 	return &Consumer{
-		repo:        repo,
-		consumerMgr: consumerMgr,
+		repo:          repo,
+		consumerMgr:   consumerMgr,
+		batchSize:     BatchSize,
+		flushInterval: FlushInterval,
 	}
 }
 
-// Start begins the consume loop. Blocks until ctx is cancelled.
+// SetRedactor masks PII in event details before they are stored. It must be
+// called before Start.
+func (c *Consumer) SetRedactor(r redaction.Redactor) {
+	c.redactor = r
+}
+
+// Start begins the consume loop. Blocks until ctx is cancelled, then writes
+// any buffered events before returning.
 func (c *Consumer) Start(ctx context.Context) error {
-	consumer, err := c.consumerMgr.EnsureConsumer(ctx, inats.StreamEvents, "audit-persister", inats.SubjectAuditEvent)
+	consumer, err := c.consumerMgr.EnsureConsumer(ctx, inats.StreamEvents, "audit-persister", inats.SubjectAuditAll)
 	if err != nil {
 		return err
 	}
 
 	slog.Info("audit consumer started", "consumer", "audit-persister")
+	c.consume(ctx, consumer)
+	return nil
+}
 
+func (c *Consumer) consume(ctx context.Context, consumer jetstream.Consumer) {
+	defer func() {
+		// ctx is done; give the final flush its own deadline.
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		c.flush(flushCtx)
+	}()
+
+	var oldest time.Time
 	for {
-		msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(inats.FetchTimeout))
+		wait := c.flushInterval
+		if len(c.pending) > 0 {
+			wait = max(time.Until(oldest.Add(c.flushInterval)), 10*time.Millisecond)
+		}
+		msgs, err := consumer.Fetch(c.batchSize-len(c.pending), jetstream.FetchMaxWait(wait))
 		if err != nil {
 			if ctx.Err() != nil {
-				return nil
+				return
 			}
 			slog.Debug("audit consumer: fetching events", "error", err)
-			continue
+		} else {
+			for msg := range msgs.Messages() {
+				if len(c.pending) == 0 {
+					oldest = time.Now()
+				}
+				c.handleEvent(msg)
+			}
 		}
 
-		for msg := range msgs.Messages() {
-			c.handleEvent(ctx, msg)
+		if len(c.pending) >= c.batchSize || (len(c.pending) > 0 && time.Since(oldest) >= c.flushInterval) {
+			c.flush(ctx)
 		}
-
 		if ctx.Err() != nil {
-			return nil
+			return
 		}
 	}
 }
 
-func (c *Consumer) handleEvent(ctx context.Context, msg jetstream.Msg) {
+// flush writes the buffered logs in one batch and acks the messages of those
+// stored. A log that fails on its own is nak'd for redelivery, or terminated
+// when its owner no longer exists; if the batch as a whole fails, every
+// message is nak'd.
+func (c *Consumer) flush(ctx context.Context) {
+	if len(c.pending) == 0 {
+		return
+	}
+	batch := c.pending
+	c.pending = nil
+
+	logs := make([]*AuditLog, len(batch))
+	for i, p := range batch {
+		logs[i] = p.log
+	}
+	failed, err := c.repo.InsertBatch(ctx, logs)
+	if err != nil {
+		slog.Error("audit consumer: persisting audit logs", "error", err, "count", len(batch))
+		for _, p := range batch {
+			_ = c.consumerMgr.Nak(p.msg)
+		}
+		return
+	}
+	for i, p := range batch {
+		err, ok := failed[i]
+		switch {
+		case !ok:
+			_ = p.msg.Ack()
+		case errors.Is(err, ErrUnknownOwner):
+			slog.Warn("audit consumer: dropping event for unknown owner", "owner_user_id", p.log.OwnerUserID, "event_type", p.log.EventType)
+			_ = p.msg.TermWithReason(err.Error())
+		default:
+			slog.Error("audit consumer: persisting audit log", "error", err, "event_type", p.log.EventType)
+			_ = c.consumerMgr.Nak(p.msg)
+		}
+	}
+	slog.Debug("audit consumer: persisted events", "count", len(batch)-len(failed))
+}
+
+// handleEvent decodes msg and buffers it for the next flush.
+func (c *Consumer) handleEvent(msg jetstream.Msg) {
 	var event inats.AuditEvent
-	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+	if err := inats.Decode(msg.Headers(), msg.Data(), &event); err != nil {
 		slog.Error("audit consumer: unmarshaling event", "error", err)
-		_ = msg.Nak()
+		_ = c.consumerMgr.Nak(msg)
 		return
 	}
 
@@ -80,22 +177,15 @@ func (c *Consumer) handleEvent(ctx context.Context, msg jetstream.Msg) {
 	}
 
 	// Store Details as JSONB {"message": "..."}
-	detailsMap := map[string]string{"message": event.Details}
+	details := event.Details
+	if c.redactor != nil {
+		details = c.redactor.Redact(details)
+		log.Redacted = details != event.Details
+	}
+	detailsMap := map[string]string{"message": details}
 	if data, err := json.Marshal(detailsMap); err == nil {
 		log.Details = data
 	}
 
-	if err := c.repo.Insert(ctx, log); err != nil {
-		slog.Error("audit consumer: persisting audit log", "error", err, "event_type", event.EventType)
-		_ = msg.Nak()
-		return
-	}
-
-	_ = msg.Ack()
-
-	slog.Debug("audit consumer: persisted event",
-		"event_type", event.EventType,
-		"owner", event.OwnerUserID,
-		"resource_id", event.ResourceID,
-	)
+	c.pending = append(c.pending, pendingLog{msg: msg, log: log})
 }
@@ -17,7 +17,9 @@ type AuditLog struct {
 	ResourceID   *uuid.UUID      `json:"resource_id,omitempty"`
 	Details      json.RawMessage `json:"details,omitempty"`
 	IPAddress    string          `json:"ip_address,omitempty"`
-	CreatedAt    time.Time       `json:"created_at"`
+	// Redacted is set when PII was masked in Details before storage.
+	Redacted  bool      `json:"redacted"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // ListParams holds pagination and filtering parameters for audit log queries.
@@ -3,16 +3,23 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/aiox-platform/aiox/internal/database"
 )
 
 // Repository handles audit_logs PostgreSQL operations.
 type Repository struct {
-	pool *pgxpool.Pool
+	pool  *pgxpool.Pool
+	retry *database.Retrier
 }
 
 // NewRepository creates a new audit Repository.
@@ -20,6 +27,12 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 	return &Repository{pool: pool}
 }
 
+// SetRetrier retries the List methods on transient Postgres errors. Inserts
+// are never retried.
+func (r *Repository) SetRetrier(retry *database.Retrier) {
+	r.retry = retry
+}
+
 // Insert persists a single audit log entry.
 func (r *Repository) Insert(ctx context.Context, log *AuditLog) error {
 	if log.ID == uuid.Nil {
@@ -32,15 +45,102 @@ func (r *Repository) Insert(ctx context.Context, log *AuditLog) error {
 	}
 
 	_, err := r.pool.Exec(ctx,
-		`INSERT INTO audit_logs (id, owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
-		log.ID, log.OwnerUserID, log.EventType, log.Severity, log.ResourceType, log.ResourceID, detailsJSON, log.IPAddress)
+		`INSERT INTO audit_logs (id, owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address, redacted)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		log.ID, log.OwnerUserID, log.EventType, log.Severity, log.ResourceType, log.ResourceID, detailsJSON, log.IPAddress, log.Redacted)
 	if err != nil {
 		return fmt.Errorf("inserting audit log: %w", err)
 	}
 	return nil
 }
 
+// ErrUnknownOwner is returned for a log whose owner no longer exists, such as
+// an event published just before the account was deleted.
+var ErrUnknownOwner = errors.New("audit log owner does not exist")
+
+// InsertBatch persists logs in one transaction, sent to the database in as
+// few round trips as possible, and returns the logs that failed by index.
+// Each log runs under a savepoint, so a failing log is rolled back alone and
+// the rest are stored. Each log keeps its CreatedAt, so buffering doesn't
+// shift event times.
+func (r *Repository) InsertBatch(ctx context.Context, logs []*AuditLog) (map[int]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning audit log batch: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	failed := make(map[int]error)
+	for next := 0; next < len(logs); {
+		at, err := sendAuditBatch(ctx, tx, logs, next)
+		if err == nil {
+			break
+		}
+		if at < 0 {
+			return nil, err
+		}
+		failed[at] = err
+		if _, err := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT audit_row"); err != nil {
+			return nil, fmt.Errorf("rolling back audit log %d: %w", at, err)
+		}
+		next = at + 1
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing audit log batch: %w", err)
+	}
+	return failed, nil
+}
+
+// sendAuditBatch inserts logs[from:] in one round trip. It stops at the first
+// log whose insert fails and returns its index with the error; other
+// failures are returned with index -1.
+func sendAuditBatch(ctx context.Context, tx pgx.Tx, logs []*AuditLog, from int) (int, error) {
+	batch := &pgx.Batch{}
+	for _, log := range logs[from:] {
+		if log.ID == uuid.Nil {
+			log.ID = uuid.New()
+		}
+		detailsJSON := log.Details
+		if len(detailsJSON) == 0 {
+			detailsJSON = json.RawMessage(`{}`)
+		}
+		var createdAt *time.Time
+		if !log.CreatedAt.IsZero() {
+			createdAt = &log.CreatedAt
+		}
+		batch.Queue("SAVEPOINT audit_row")
+		batch.Queue(
+			`INSERT INTO audit_logs (id, owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address, redacted, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, NOW()))`,
+			log.ID, log.OwnerUserID, log.EventType, log.Severity, log.ResourceType, log.ResourceID, detailsJSON, log.IPAddress, log.Redacted, createdAt,
+		)
+		batch.Queue("RELEASE SAVEPOINT audit_row")
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := from; i < len(logs); i++ {
+		if _, err := br.Exec(); err != nil {
+			return -1, fmt.Errorf("setting savepoint for audit log %d: %w", i, err)
+		}
+		if _, err := br.Exec(); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return i, fmt.Errorf("inserting audit log %d: %w", i, ErrUnknownOwner)
+			}
+			return i, fmt.Errorf("inserting audit log %d: %w", i, err)
+		}
+		if _, err := br.Exec(); err != nil {
+			return -1, fmt.Errorf("releasing savepoint for audit log %d: %w", i, err)
+		}
+	}
+	if err := br.Close(); err != nil {
+		return -1, fmt.Errorf("closing audit log batch: %w", err)
+	}
+	return -1, nil
+}
+
 // ListByOwner returns paginated audit logs for an owner with optional filters.
 func (r *Repository) ListByOwner(ctx context.Context, ownerUserID uuid.UUID, params ListParams) ([]AuditLog, int64, error) {
 	return r.list(ctx, ownerUserID, nil, params)
@@ -51,7 +151,15 @@ func (r *Repository) ListByResource(ctx context.Context, ownerUserID uuid.UUID,
 	return r.list(ctx, ownerUserID, &resourceID, params)
 }
 
-func (r *Repository) list(ctx context.Context, ownerUserID uuid.UUID, resourceID *uuid.UUID, params ListParams) ([]AuditLog, int64, error) {
+func (r *Repository) list(ctx context.Context, ownerUserID uuid.UUID, resourceID *uuid.UUID, params ListParams) (logs []AuditLog, total int64, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		logs, total, err = r.listOnce(ctx, ownerUserID, resourceID, params)
+		return err
+	})
+	return logs, total, err
+}
+
+func (r *Repository) listOnce(ctx context.Context, ownerUserID uuid.UUID, resourceID *uuid.UUID, params ListParams) ([]AuditLog, int64, error) {
 	if params.Page < 1 {
 		params.Page = 1
 	}
@@ -109,7 +217,7 @@ func (r *Repository) list(ctx context.Context, ownerUserID uuid.UUID, resourceID
 	// Data query
 	offset := (params.Page - 1) * params.PageSize
 	dataQuery := fmt.Sprintf(
-		`SELECT id, owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address, created_at
+		`SELECT id, owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address, redacted, created_at
 		 FROM audit_logs WHERE %s
 		 ORDER BY created_at DESC
 		 LIMIT $%d OFFSET $%d`, where, argIdx, argIdx+1)
@@ -125,7 +233,7 @@ func (r *Repository) list(ctx context.Context, ownerUserID uuid.UUID, resourceID
 	for rows.Next() {
 		var l AuditLog
 		if err := rows.Scan(&l.ID, &l.OwnerUserID, &l.EventType, &l.Severity,
-			&l.ResourceType, &l.ResourceID, &l.Details, &l.IPAddress, &l.CreatedAt); err != nil {
+			&l.ResourceType, &l.ResourceID, &l.Details, &l.IPAddress, &l.Redacted, &l.CreatedAt); err != nil {
 			return nil, 0, fmt.Errorf("scanning audit log: %w", err)
 		}
 		logs = append(logs, l)
@@ -133,3 +241,39 @@ func (r *Repository) list(ctx context.Context, ownerUserID uuid.UUID, resourceID
 
 	return logs, totalCount, nil
 }
+
+// ListByOwnerAfter returns up to limit of the owner's audit logs created
+// after the (afterCreatedAt, afterID) keyset position, oldest first. Zero
+// values start from the oldest log.
+func (r *Repository) ListByOwnerAfter(ctx context.Context, ownerUserID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int) (logs []AuditLog, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		logs, err = r.listByOwnerAfter(ctx, ownerUserID, afterCreatedAt, afterID, limit)
+		return err
+	})
+	return logs, err
+}
+
+func (r *Repository) listByOwnerAfter(ctx context.Context, ownerUserID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]AuditLog, error) {
+	query := `SELECT id, owner_user_id, event_type, severity, resource_type, resource_id, details, ip_address, redacted, created_at
+		 FROM audit_logs
+		 WHERE owner_user_id = $1 AND (created_at, id) > ($2, $3)
+		 ORDER BY created_at, id
+		 LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, ownerUserID, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing audit logs by owner: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []AuditLog
+	for rows.Next() {
+		var l AuditLog
+		if err := rows.Scan(&l.ID, &l.OwnerUserID, &l.EventType, &l.Severity,
+			&l.ResourceType, &l.ResourceID, &l.Details, &l.IPAddress, &l.Redacted, &l.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning audit log: %w", err)
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,101 @@
+package governance
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+	"github.com/aiox-platform/aiox/internal/server"
+)
+
+const auditStreamHeartbeat = 15 * time.Second
+
+// AuditStreamHandler pushes the user's audit events to the client as
+// Server-Sent Events.
+type AuditStreamHandler struct {
+	feed *inats.AuditFeed
+}
+
+// NewAuditStreamHandler creates a new AuditStreamHandler.
+func NewAuditStreamHandler(feed *inats.AuditFeed) *AuditStreamHandler {
+	return &AuditStreamHandler{feed: feed}
+}
+
+// Stream sends each new audit event for the authenticated user as an SSE
+// "audit" event whose ID is the event's stream sequence. A Last-Event-ID
+// header resumes after that event; heartbeat comments keep idle
+// connections open. The stream is closed when the server shuts down.
+func (h *AuditStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	var afterSeq uint64
+	if last := r.Header.Get("Last-Event-ID"); last != "" {
+		seq, err := strconv.ParseUint(last, 10, 64)
+		if err != nil {
+			api.HandleError(w, api.NewBadRequestError("invalid Last-Event-ID"))
+			return
+		}
+		afterSeq = seq
+	}
+
+	// The stream ends when the server shuts down, so it doesn't hold up the
+	// drain; clients reconnect with Last-Event-ID.
+	ctx, cancel := server.StreamContext(r.Context())
+	defer cancel()
+	records, err := h.feed.Follow(ctx, userID, afterSeq)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "starting audit stream", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	// The server's write timeout would otherwise cut the stream off.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.WarnContext(r.Context(), "audit stream: flushing headers", "error", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(auditStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case rec, ok := <-records:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(rec.AuditEvent)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: audit\ndata: %s\n\n", rec.Sequence, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
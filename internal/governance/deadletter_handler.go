@@ -0,0 +1,99 @@
+package governance
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 500
+)
+
+// DeadLetterHandler lets owners inspect and retry tasks that exceeded their
+// delivery limit.
+type DeadLetterHandler struct {
+	store *inats.DeadLetterStore
+}
+
+// NewDeadLetterHandler creates a new DeadLetterHandler.
+func NewDeadLetterHandler(store *inats.DeadLetterStore) *DeadLetterHandler {
+	return &DeadLetterHandler{store: store}
+}
+
+// List returns the authenticated user's dead-lettered tasks, oldest first.
+// Accepts ?limit= (default 50, max 500).
+func (h *DeadLetterHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeadLetterLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		if v, err := strconv.Atoi(l); err == nil && v > 0 && v <= maxDeadLetterLimit {
+			limit = v
+		}
+	}
+
+	records, err := h.store.List(r.Context(), userID, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing dead letters", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, records)
+}
+
+// Retry republishes a dead-lettered task to its agent and removes it from the
+// dead-letter stream.
+func (h *DeadLetterHandler) Retry(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userFromClaims(w, r)
+	if !ok {
+		return
+	}
+
+	seq, err := strconv.ParseUint(chi.URLParam(r, "deadLetterID"), 10, 64)
+	if err != nil || seq == 0 {
+		api.HandleError(w, api.NewBadRequestError("invalid dead letter ID"))
+		return
+	}
+
+	record, err := h.store.Retry(r.Context(), userID, seq)
+	if err != nil {
+		if errors.Is(err, inats.ErrDeadLetterNotFound) {
+			api.HandleError(w, api.NewNotFoundError(err.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "retrying dead letter", "error", err, "id", seq)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, record)
+}
+
+func userFromClaims(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
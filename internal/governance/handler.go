@@ -1,6 +1,7 @@
 package governance
 
 import (
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/aiox-platform/aiox/internal/api"
 	"github.com/aiox-platform/aiox/internal/auth"
 	"github.com/aiox-platform/aiox/internal/governance/audit"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 	"github.com/aiox-platform/aiox/internal/governance/quota"
 )
 
@@ -81,7 +83,7 @@ func (h *Handler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ListAgentAuditLogs(w http.ResponseWriter, r *http.Request) {
 	agent := agents.GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
@@ -96,6 +98,86 @@ func (h *Handler) ListAgentAuditLogs(w http.ResponseWriter, r *http.Request) {
 	api.JSONPaginated(w, http.StatusOK, logs, total, params.Page, params.PageSize)
 }
 
+// GetAgentQuota returns a specific agent's usage and effective limits.
+// Expects the agent to be set in context by the OwnershipMiddleware.
+func (h *Handler) GetAgentQuota(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	gov := policy.Parse(agent.Governance)
+
+	status, err := h.quotaSvc.GetAgentQuota(r.Context(), agent.ID, agent.OwnerUserID, gov.Quota.Limits())
+	if err != nil {
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, status)
+}
+
+// GetCost returns the authenticated user's estimated spend grouped by agent
+// and model. Accepts ?from= and ?to= (RFC 3339); defaults to the current UTC
+// day up to now.
+func (h *Handler) GetCost(w http.ResponseWriter, r *http.Request) {
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+
+	now := time.Now().UTC()
+	from := now.Truncate(24 * time.Hour)
+	to := now
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			api.HandleError(w, api.NewBadRequestError("from must be an RFC 3339 timestamp"))
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			api.HandleError(w, api.NewBadRequestError("to must be an RFC 3339 timestamp"))
+			return
+		}
+	}
+	if to.Before(from) {
+		api.HandleError(w, api.NewBadRequestError("from must not be after to"))
+		return
+	}
+
+	report, err := h.quotaSvc.GetCost(r.Context(), userID, from, to)
+	if err != nil {
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, report)
+}
+
+// ListUserQuotas returns every user's daily usage, heaviest token users first.
+// Accepts ?page= and ?page_size=. Admin only.
+func (h *Handler) ListUserQuotas(w http.ResponseWriter, r *http.Request) {
+	params := parseAuditParams(r)
+
+	usage, total, err := h.quotaSvc.ListUsage(r.Context(), params.Page, params.PageSize)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing user quotas", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONPaginated(w, http.StatusOK, usage, total, params.Page, params.PageSize)
+}
+
 func parseAuditParams(r *http.Request) audit.ListParams {
 	params := audit.DefaultListParams()
 
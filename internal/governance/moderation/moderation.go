@@ -0,0 +1,226 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+)
+
+// DefaultRefusal is the reply to a blocked message when the agent's policy
+// doesn't set one.
+const DefaultRefusal = "Your message was blocked by the content policy."
+
+// Moderator decides whether inbound text may reach the LLM. reason explains a
+// block and is recorded in the audit log. Implementations must be safe for
+// concurrent use.
+type Moderator interface {
+	Check(ctx context.Context, text string) (allowed bool, reason string, err error)
+}
+
+// Blocklist blocks text matching any of its regular expressions.
+type Blocklist struct {
+	patterns []*regexp.Regexp
+}
+
+// NewBlocklist compiles patterns into a Blocklist. Blank patterns are skipped.
+func NewBlocklist(patterns []string) (*Blocklist, error) {
+	b := &Blocklist{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("compiling blocked pattern %q: %w", p, err)
+		}
+		b.patterns = append(b.patterns, re)
+	}
+	return b, nil
+}
+
+// Check blocks text that matches a pattern.
+func (b *Blocklist) Check(_ context.Context, text string) (bool, string, error) {
+	for _, re := range b.patterns {
+		if re.MatchString(text) {
+			return false, fmt.Sprintf("matched blocked pattern %q", re.String()), nil
+		}
+	}
+	return true, "", nil
+}
+
+// Empty reports whether the blocklist has no patterns.
+func (b *Blocklist) Empty() bool {
+	return len(b.patterns) == 0
+}
+
+// HTTPModerator asks an external moderation service about each message
+// (POST {"text"} → {"allowed", "reason"}).
+type HTTPModerator struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPModerator creates a moderator for the service at url. apiKey, when
+// set, is sent as a bearer token.
+func NewHTTPModerator(url, apiKey string, timeout time.Duration) *HTTPModerator {
+	return &HTTPModerator{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type checkRequest struct {
+	Text string `json:"text"`
+}
+
+type checkResponse struct {
+	Allowed *bool  `json:"allowed"`
+	Reason  string `json:"reason"`
+}
+
+// Check sends text to the moderation service.
+func (m *HTTPModerator) Check(ctx context.Context, text string) (bool, string, error) {
+	body, err := json.Marshal(checkRequest{Text: text})
+	if err != nil {
+		return false, "", fmt.Errorf("marshaling moderation request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return false, "", fmt.Errorf("building moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return false, "", fmt.Errorf("calling moderation service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, "", fmt.Errorf("moderation service returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var out checkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return false, "", fmt.Errorf("decoding moderation response: %w", err)
+	}
+	if out.Allowed == nil {
+		return false, "", errors.New("moderation response has no allowed field")
+	}
+	return *out.Allowed, out.Reason, nil
+}
+
+// chain consults moderators in order and stops at the first block. A
+// moderator error blocks the text when failClosed is set and is otherwise
+// skipped; either way it is returned alongside the decision.
+type chain struct {
+	moderators []Moderator
+	failClosed bool
+}
+
+func (c chain) Check(ctx context.Context, text string) (bool, string, error) {
+	var firstErr error
+	for _, m := range c.moderators {
+		allowed, reason, err := m.Check(ctx, text)
+		if err != nil {
+			if c.failClosed {
+				return false, "content could not be screened", err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !allowed {
+			return false, reason, firstErr
+		}
+	}
+	return true, "", firstErr
+}
+
+// Engine combines the deployment-wide blocklist, the optional external
+// service, and per-agent blocklists.
+type Engine struct {
+	base       *Blocklist
+	external   Moderator
+	failClosed bool
+	cache      sync.Map // per-agent pattern list → *Blocklist
+}
+
+// NewEngine creates a moderation Engine from deployment configuration.
+func NewEngine(cfg config.ModerationConfig) (*Engine, error) {
+	base, err := NewBlocklist(cfg.BlockedPatterns)
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{base: base, failClosed: cfg.FailClosed}
+	if cfg.APIURL != "" {
+		e.external = NewHTTPModerator(cfg.APIURL, cfg.APIKey, cfg.Timeout)
+	}
+	return e, nil
+}
+
+// ForAgent returns the moderator for an agent's policy: the agent's own
+// patterns, then the deployment-wide ones, then the external service, which is
+// called only for text the blocklists allow. Invalid agent patterns are logged
+// and skipped.
+func (e *Engine) ForAgent(p policy.ModerationPolicy) Moderator {
+	c := chain{}
+	if e == nil {
+		return c
+	}
+	c.failClosed = e.failClosed
+	if len(p.BlockedPatterns) > 0 {
+		if agent := e.compileCached(p.BlockedPatterns); agent != nil {
+			c.moderators = append(c.moderators, agent)
+		}
+	}
+	if !e.base.Empty() {
+		c.moderators = append(c.moderators, e.base)
+	}
+	if e.external != nil {
+		c.moderators = append(c.moderators, e.external)
+	}
+	return c
+}
+
+func (e *Engine) compileCached(patterns []string) *Blocklist {
+	key := strings.Join(patterns, "\x00")
+	if v, ok := e.cache.Load(key); ok {
+		return v.(*Blocklist)
+	}
+	b, err := NewBlocklist(patterns)
+	if err != nil {
+		slog.Warn("moderation: ignoring invalid agent patterns", "error", err)
+		return nil
+	}
+	e.cache.Store(key, b)
+	return b
+}
+
+// Refusal returns the reply to a message blocked under p.
+func Refusal(p policy.ModerationPolicy) string {
+	if p.RefusalMessage != "" {
+		return p.RefusalMessage
+	}
+	return DefaultRefusal
+}
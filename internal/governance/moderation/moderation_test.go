@@ -0,0 +1,97 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+)
+
+func TestBlocklist(t *testing.T) {
+	b, err := NewBlocklist([]string{`(?i)\bforbidden\b`, " "})
+	require.NoError(t, err)
+
+	allowed, _, err := b.Check(context.Background(), "a harmless message")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, reason, err := b.Check(context.Background(), "this is FORBIDDEN talk")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "forbidden")
+
+	_, err = NewBlocklist([]string{"(unclosed"})
+	assert.Error(t, err)
+}
+
+func TestHTTPModerator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var req checkRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Text == "spam" {
+			_, _ = w.Write([]byte(`{"allowed": false, "reason": "spam"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"allowed": true}`))
+	}))
+	defer srv.Close()
+
+	m := NewHTTPModerator(srv.URL, "secret", 0)
+	allowed, _, err := m.Check(context.Background(), "hello")
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, reason, err := m.Check(context.Background(), "spam")
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, "spam", reason)
+}
+
+type failing struct{}
+
+func (failing) Check(context.Context, string) (bool, string, error) {
+	return false, "", errors.New("unavailable")
+}
+
+func TestChain_FailOpenAndClosed(t *testing.T) {
+	allowed, _, err := chain{moderators: []Moderator{failing{}}}.Check(context.Background(), "hi")
+	assert.Error(t, err)
+	assert.True(t, allowed, "fail open lets the message through")
+
+	allowed, _, err = chain{moderators: []Moderator{failing{}}, failClosed: true}.Check(context.Background(), "hi")
+	assert.Error(t, err)
+	assert.False(t, allowed)
+}
+
+func TestEngine_ForAgent(t *testing.T) {
+	e, err := NewEngine(config.ModerationConfig{BlockedPatterns: []string{"global-bad"}})
+	require.NoError(t, err)
+
+	m := e.ForAgent(policy.ModerationPolicy{BlockedPatterns: []string{"agent-bad"}})
+	for text, want := range map[string]bool{"fine": true, "global-bad": false, "agent-bad": false} {
+		allowed, _, err := m.Check(context.Background(), text)
+		assert.NoError(t, err)
+		assert.Equal(t, want, allowed, text)
+	}
+
+	allowed, _, _ := e.ForAgent(policy.ModerationPolicy{}).Check(context.Background(), "agent-bad")
+	assert.True(t, allowed, "agent patterns don't leak to other agents")
+
+	var none *Engine
+	allowed, _, _ = none.ForAgent(policy.ModerationPolicy{}).Check(context.Background(), "anything")
+	assert.True(t, allowed)
+}
+
+func TestRefusal(t *testing.T) {
+	assert.Equal(t, DefaultRefusal, Refusal(policy.ModerationPolicy{}))
+	assert.Equal(t, "No.", Refusal(policy.ModerationPolicy{RefusalMessage: "No."}))
+}
@@ -0,0 +1,280 @@
+// Package policy defines the governance policy stored on each agent. It is
+// shared by agent validation, the orchestrator, and the dispatcher so the
+// JSONB shape is parsed in one place.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aiox-platform/aiox/internal/governance/quota"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// GovernancePolicy represents the governance JSONB structure on an agent.
+type GovernancePolicy struct {
+	AllowedDomains      []string        `json:"allowed_domains,omitempty"`
+	MaxTokensPerRequest int             `json:"max_tokens_per_request,omitempty"`
+	AllowedProviders    []string        `json:"allowed_providers,omitempty"`
+	Blocked             bool            `json:"blocked,omitempty"`
+	Redaction           RedactionPolicy `json:"redaction,omitempty"`
+	Quota               QuotaPolicy     `json:"quota,omitempty"`
+	// AllowedSenderDomains restricts which XMPP domains may message the agent.
+	// Empty means any sender is accepted.
+	AllowedSenderDomains []string `json:"allowed_sender_domains,omitempty"`
+	// MaxMessagesPerMinute caps how many messages a single sender JID may send
+	// the agent per minute. Zero means unlimited.
+	MaxMessagesPerMinute int `json:"max_messages_per_minute,omitempty"`
+	// AllowedHours limits when the agent answers. Nil means always.
+	AllowedHours *HoursWindow     `json:"allowed_hours,omitempty"`
+	Moderation   ModerationPolicy `json:"moderation,omitempty"`
+	// Priority is the task queue for the agent's messages: "high", "normal",
+	// or "low". Empty means normal.
+	Priority string `json:"priority,omitempty"`
+	// PrioritySenders lists sender bare JIDs or domains whose messages are
+	// queued as high priority whatever the agent's Priority.
+	PrioritySenders []string `json:"priority_senders,omitempty"`
+}
+
+// TaskPriority returns the queue priority for a message from fromJID.
+func (p GovernancePolicy) TaskPriority(fromJID string) string {
+	bare, _, _ := strings.Cut(fromJID, "/")
+	_, domain, _ := strings.Cut(bare, "@")
+	for _, s := range p.PrioritySenders {
+		if strings.EqualFold(s, bare) || (domain != "" && strings.EqualFold(s, domain)) {
+			return inats.PriorityHigh
+		}
+	}
+	if p.Priority == "" {
+		return inats.PriorityNormal
+	}
+	return p.Priority
+}
+
+// QuotaPolicy holds per-agent quota overrides. Zero values inherit the
+// user/global limits; overrides can only tighten them.
+type QuotaPolicy struct {
+	MaxTokensPerDay    int `json:"max_tokens_per_day,omitempty"`
+	MaxRequestsPerDay  int `json:"max_requests_per_day,omitempty"`
+	MaxTokensPerMinute int `json:"max_tokens_per_minute,omitempty"`
+}
+
+// Limits converts the policy into quota.AgentLimits.
+func (p QuotaPolicy) Limits() quota.AgentLimits {
+	return quota.AgentLimits{
+		MaxTokensPerDay:    p.MaxTokensPerDay,
+		MaxRequestsPerDay:  p.MaxRequestsPerDay,
+		MaxTokensPerMinute: p.MaxTokensPerMinute,
+	}
+}
+
+// RedactionPolicy is the per-agent PII redaction configuration. Patterns may be
+// built-in rule names or regular expressions and are applied on top of the
+// deployment-wide patterns.
+type RedactionPolicy struct {
+	Patterns       []string `json:"patterns,omitempty"`
+	RedactOutbound bool     `json:"redact_outbound,omitempty"`
+}
+
+// ModerationPolicy adds per-agent content moderation on top of the
+// deployment-wide rules. BlockedPatterns are regular expressions matched
+// against inbound messages.
+type ModerationPolicy struct {
+	BlockedPatterns []string `json:"blocked_patterns,omitempty"`
+	// RefusalMessage is the reply to a blocked message. Empty uses a default.
+	RefusalMessage string `json:"refusal_message,omitempty"`
+}
+
+// HoursWindow is a weekly schedule of daily time windows in "HH:MM" 24-hour
+// form. A window whose end is before its start wraps past midnight, e.g.
+// 22:00–06:00; the hours after midnight belong to the day the window started.
+type HoursWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Timezone is an IANA zone name such as "Europe/Berlin". Empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+	// Days lists the weekdays the window opens on ("mon" … "sun"). Empty
+	// means every day.
+	Days []string `json:"days,omitempty"`
+	// Message is the reply sent outside the window. Empty uses
+	// DefaultOutsideHoursMessage.
+	Message string `json:"message,omitempty"`
+}
+
+// DefaultOutsideHoursMessage is the reply to messages outside an agent's
+// allowed hours.
+const DefaultOutsideHoursMessage = "This agent is outside its operating hours. Please try again later."
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Contains reports whether t falls inside the schedule. A nil or malformed
+// window contains every time.
+func (w *HoursWindow) Contains(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	start, end, loc, err := w.parse()
+	if err != nil {
+		return true
+	}
+
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case start <= end:
+		return minute >= start && minute < end && w.opensOn(t.Weekday())
+	case minute >= start:
+		return w.opensOn(t.Weekday())
+	case minute < end:
+		return w.opensOn((t.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+// OutsideMessage returns the reply for messages outside the window.
+func (w *HoursWindow) OutsideMessage() string {
+	if w == nil || w.Message == "" {
+		return DefaultOutsideHoursMessage
+	}
+	return w.Message
+}
+
+func (w *HoursWindow) opensOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if wd, ok := weekdays[strings.ToLower(d)]; ok && wd == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parse returns the window bounds as minutes since midnight and its location.
+func (w *HoursWindow) parse() (start, end int, loc *time.Location, err error) {
+	if start, err = parseClock(w.Start); err != nil {
+		return 0, 0, nil, fmt.Errorf("start: %w", err)
+	}
+	if end, err = parseClock(w.End); err != nil {
+		return 0, 0, nil, fmt.Errorf("end: %w", err)
+	}
+	if start == end {
+		return 0, 0, nil, errors.New("start and end must differ")
+	}
+	for _, d := range w.Days {
+		if _, ok := weekdays[strings.ToLower(d)]; !ok {
+			return 0, 0, nil, fmt.Errorf("days: %q is not one of mon, tue, wed, thu, fri, sat, sun", d)
+		}
+	}
+	loc = time.UTC
+	if w.Timezone != "" {
+		if loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return 0, 0, nil, fmt.Errorf("unknown timezone %q", w.Timezone)
+		}
+	}
+	return start, end, loc, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// InvalidPolicyError is returned by Decode for governance that has unknown
+// fields or invalid values.
+type InvalidPolicyError struct {
+	Reason string
+}
+
+func (e *InvalidPolicyError) Error() string {
+	return "invalid governance: " + e.Reason
+}
+
+// Parse parses agent governance JSONB into a GovernancePolicy.
+// Returns zero-value policy on nil, empty, or invalid input.
+func Parse(data []byte) GovernancePolicy {
+	var p GovernancePolicy
+	if len(data) == 0 {
+		return p
+	}
+	_ = json.Unmarshal(data, &p)
+	return p
+}
+
+// Decode strictly parses governance submitted for an agent, rejecting unknown
+// fields and invalid values with an *InvalidPolicyError. Nil, empty, or null
+// input yields the zero policy.
+func Decode(data []byte) (GovernancePolicy, error) {
+	var p GovernancePolicy
+	if len(bytes.TrimSpace(data)) == 0 {
+		return p, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return GovernancePolicy{}, &InvalidPolicyError{Reason: strings.TrimPrefix(err.Error(), "json: ")}
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return GovernancePolicy{}, &InvalidPolicyError{Reason: "unexpected data after the policy object"}
+	}
+	if err := p.validate(); err != nil {
+		return GovernancePolicy{}, &InvalidPolicyError{Reason: err.Error()}
+	}
+	return p, nil
+}
+
+func (p GovernancePolicy) validate() error {
+	for name, v := range map[string]int{
+		"max_tokens_per_request":      p.MaxTokensPerRequest,
+		"max_messages_per_minute":     p.MaxMessagesPerMinute,
+		"quota.max_tokens_per_day":    p.Quota.MaxTokensPerDay,
+		"quota.max_requests_per_day":  p.Quota.MaxRequestsPerDay,
+		"quota.max_tokens_per_minute": p.Quota.MaxTokensPerMinute,
+	} {
+		if v < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	for name, list := range map[string][]string{
+		"allowed_domains":             p.AllowedDomains,
+		"allowed_providers":           p.AllowedProviders,
+		"allowed_sender_domains":      p.AllowedSenderDomains,
+		"redaction.patterns":          p.Redaction.Patterns,
+		"moderation.blocked_patterns": p.Moderation.BlockedPatterns,
+		"priority_senders":            p.PrioritySenders,
+	} {
+		for _, v := range list {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("%s must not contain empty entries", name)
+			}
+		}
+	}
+	for _, pattern := range p.Moderation.BlockedPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("moderation.blocked_patterns: %q is not a valid regular expression", pattern)
+		}
+	}
+	if !inats.ValidPriority(p.Priority) {
+		return fmt.Errorf("priority must be one of %s", strings.Join(inats.Priorities, ", "))
+	}
+	if p.AllowedHours != nil {
+		if _, _, _, err := p.AllowedHours.parse(); err != nil {
+			return fmt.Errorf("allowed_hours %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Nil(t *testing.T) {
+	cfg := Parse(nil)
+	assert.False(t, cfg.Blocked)
+	assert.Nil(t, cfg.AllowedDomains)
+	assert.Nil(t, cfg.AllowedProviders)
+	assert.Equal(t, 0, cfg.MaxTokensPerRequest)
+}
+
+func TestParse_Empty(t *testing.T) {
+	cfg := Parse([]byte{})
+	assert.False(t, cfg.Blocked)
+}
+
+func TestParse_InvalidJSON(t *testing.T) {
+	cfg := Parse([]byte("not json"))
+	assert.False(t, cfg.Blocked)
+}
+
+func TestParse_Blocked(t *testing.T) {
+	data, _ := json.Marshal(GovernancePolicy{Blocked: true})
+	cfg := Parse(data)
+	assert.True(t, cfg.Blocked)
+}
+
+func TestParse_AllowedProviders(t *testing.T) {
+	data, _ := json.Marshal(GovernancePolicy{AllowedProviders: []string{"openai", "anthropic"}})
+	cfg := Parse(data)
+	assert.Equal(t, []string{"openai", "anthropic"}, cfg.AllowedProviders)
+	assert.False(t, cfg.Blocked)
+}
+
+func TestParse_MaxTokensPerRequest(t *testing.T) {
+	data, _ := json.Marshal(GovernancePolicy{MaxTokensPerRequest: 4096})
+	cfg := Parse(data)
+	assert.Equal(t, 4096, cfg.MaxTokensPerRequest)
+}
+
+func TestParse_Partial(t *testing.T) {
+	data := []byte(`{"blocked": true, "allowed_domains": ["example.com"]}`)
+	cfg := Parse(data)
+	assert.True(t, cfg.Blocked)
+	assert.Equal(t, []string{"example.com"}, cfg.AllowedDomains)
+	assert.Nil(t, cfg.AllowedProviders)
+	assert.Equal(t, 0, cfg.MaxTokensPerRequest)
+}
+
+func TestParse_FullConfig(t *testing.T) {
+	data := []byte(`{
+		"allowed_domains": ["agents.aiox.local"],
+		"max_tokens_per_request": 2048,
+		"allowed_providers": ["openai"],
+		"blocked": false
+	}`)
+	cfg := Parse(data)
+	assert.False(t, cfg.Blocked)
+	assert.Equal(t, []string{"agents.aiox.local"}, cfg.AllowedDomains)
+	assert.Equal(t, 2048, cfg.MaxTokensPerRequest)
+	assert.Equal(t, []string{"openai"}, cfg.AllowedProviders)
+}
+
+func TestParse_Quota(t *testing.T) {
+	data := []byte(`{"quota": {"max_tokens_per_day": 5000, "max_tokens_per_minute": 3}}`)
+	cfg := Parse(data)
+	limits := cfg.Quota.Limits()
+	assert.Equal(t, 5000, limits.MaxTokensPerDay)
+	assert.Equal(t, 0, limits.MaxRequestsPerDay)
+	assert.Equal(t, 3, limits.MaxTokensPerMinute)
+}
+
+func TestDecode(t *testing.T) {
+	p, err := Decode(nil)
+	assert.NoError(t, err)
+	assert.False(t, p.Blocked)
+
+	p, err = Decode([]byte(`{"blocked": true, "allowed_hours": {"start": "09:00", "end": "17:30", "timezone": "Europe/Berlin"}}`))
+	assert.NoError(t, err)
+	assert.True(t, p.Blocked)
+	assert.Equal(t, "17:30", p.AllowedHours.End)
+
+	for name, data := range map[string]string{
+		"unknown field":        `{"blokced": true}`,
+		"unknown nested field": `{"quota": {"max_tokens": 5}}`,
+		"wrong type":           `{"allowed_providers": "openai"}`,
+		"negative limit":       `{"max_messages_per_minute": -1}`,
+		"empty entry":          `{"allowed_providers": [""]}`,
+		"bad clock":            `{"allowed_hours": {"start": "9am", "end": "17:00"}}`,
+		"bad timezone":         `{"allowed_hours": {"start": "09:00", "end": "17:00", "timezone": "Mars/Base"}}`,
+		"bad blocked pattern":  `{"moderation": {"blocked_patterns": ["(unclosed"]}}`,
+		"bad day":              `{"allowed_hours": {"start": "09:00", "end": "17:00", "days": ["monday"]}}`,
+		"bad priority":         `{"priority": "urgent"}`,
+		"trailing data":        `{} {}`,
+	} {
+		_, err := Decode([]byte(data))
+		var invalid *InvalidPolicyError
+		assert.ErrorAs(t, err, &invalid, name)
+	}
+}
+
+func TestTaskPriority(t *testing.T) {
+	assert.Equal(t, "normal", GovernancePolicy{}.TaskPriority("alice@example.com"))
+	assert.Equal(t, "low", GovernancePolicy{Priority: "low"}.TaskPriority("alice@example.com"))
+
+	p := GovernancePolicy{Priority: "low", PrioritySenders: []string{"boss@example.com", "vip.example.org"}}
+	assert.Equal(t, "high", p.TaskPriority("Boss@Example.com/phone"))
+	assert.Equal(t, "high", p.TaskPriority("anyone@vip.example.org"))
+	assert.Equal(t, "low", p.TaskPriority("alice@example.com"))
+}
+
+func TestHoursWindow_Contains(t *testing.T) {
+	var none *HoursWindow
+	assert.True(t, none.Contains(time.Now()))
+
+	day := &HoursWindow{Start: "09:00", End: "17:00"}
+	assert.True(t, day.Contains(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)))
+	assert.False(t, day.Contains(time.Date(2026, 1, 5, 17, 0, 0, 0, time.UTC)))
+
+	night := &HoursWindow{Start: "22:00", End: "06:00", Timezone: "America/New_York"}
+	assert.True(t, night.Contains(time.Date(2026, 1, 5, 4, 0, 0, 0, time.UTC)), "23:00 in New York")
+	assert.False(t, night.Contains(time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)), "10:00 in New York")
+}
+
+func TestHoursWindow_Days(t *testing.T) {
+	// 2026-01-09 is a Friday.
+	weekdays := &HoursWindow{Start: "08:00", End: "17:00", Timezone: "Asia/Tokyo", Days: []string{"mon", "tue", "wed", "thu", "fri"}}
+	assert.True(t, weekdays.Contains(time.Date(2026, 1, 9, 1, 0, 0, 0, time.UTC)), "Friday 10:00 in Tokyo")
+	assert.False(t, weekdays.Contains(time.Date(2026, 1, 10, 1, 0, 0, 0, time.UTC)), "Saturday 10:00 in Tokyo")
+	assert.True(t, weekdays.Contains(time.Date(2026, 1, 11, 23, 0, 0, 0, time.UTC)), "Monday 08:00 in Tokyo is Sunday in UTC")
+	assert.False(t, weekdays.Contains(time.Date(2026, 1, 11, 22, 0, 0, 0, time.UTC)), "Monday 07:00 in Tokyo")
+
+	// Friday-night shift: Saturday early hours belong to Friday's window.
+	fridayNight := &HoursWindow{Start: "22:00", End: "06:00", Days: []string{"FRI"}}
+	assert.True(t, fridayNight.Contains(time.Date(2026, 1, 9, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, fridayNight.Contains(time.Date(2026, 1, 10, 5, 59, 0, 0, time.UTC)))
+	assert.False(t, fridayNight.Contains(time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)))
+	assert.False(t, fridayNight.Contains(time.Date(2026, 1, 9, 3, 0, 0, 0, time.UTC)), "Friday early hours belong to Thursday")
+}
+
+func TestHoursWindow_OutsideMessage(t *testing.T) {
+	var none *HoursWindow
+	assert.Equal(t, DefaultOutsideHoursMessage, none.OutsideMessage())
+	assert.Equal(t, "Back on Monday!", (&HoursWindow{Message: "Back on Monday!"}).OutsideMessage())
+}
@@ -19,10 +19,68 @@ type UserQuota struct {
 
 // QuotaStatus is the API response showing current quota usage and limits.
 type QuotaStatus struct {
-	TokensUsedToday    int `json:"tokens_used_today"`
-	TokensLimitDay     int `json:"tokens_limit_day"`
-	RequestsToday      int `json:"requests_today"`
-	RequestsLimitDay   int `json:"requests_limit_day"`
-	TokensUsedMinute   int `json:"tokens_used_minute"`
-	TokensLimitMinute  int `json:"tokens_limit_minute"`
+	TokensUsedToday   int `json:"tokens_used_today"`
+	TokensLimitDay    int `json:"tokens_limit_day"`
+	RequestsToday     int `json:"requests_today"`
+	RequestsLimitDay  int `json:"requests_limit_day"`
+	TokensUsedMinute  int `json:"tokens_used_minute"`
+	TokensLimitMinute int `json:"tokens_limit_minute"`
+	// CostTodayUSD is the estimated spend since the daily counters last reset.
+	CostTodayUSD float64 `json:"cost_today_usd"`
+	// Warning is the highest warning threshold (percent of a daily limit)
+	// the usage has crossed, or 0 if none.
+	Warning int `json:"warning,omitempty"`
+}
+
+// UserUsage is one user's daily usage in the admin quota overview.
+type UserUsage struct {
+	UserID           uuid.UUID `json:"user_id"`
+	Email            string    `json:"email"`
+	IsAdmin          bool      `json:"is_admin"`
+	TokensUsedToday  int       `json:"tokens_used_today"`
+	TokensLimitDay   int       `json:"tokens_limit_day"`
+	RequestsToday    int       `json:"requests_today"`
+	RequestsLimitDay int       `json:"requests_limit_day"`
+	// LastDailyReset is nil for users that never sent a message.
+	LastDailyReset *time.Time `json:"last_daily_reset,omitempty"`
+}
+
+// AgentQuota matches the agent_quotas table schema.
+type AgentQuota struct {
+	AgentID         uuid.UUID `json:"agent_id"`
+	OwnerUserID     uuid.UUID `json:"owner_user_id"`
+	TokensUsedToday int       `json:"tokens_used_today"`
+	RequestsToday   int       `json:"requests_today"`
+	LastDailyReset  time.Time `json:"last_daily_reset"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AgentLimits are per-agent quota overrides. Zero fields inherit the user/global limit.
+type AgentLimits struct {
+	MaxTokensPerDay    int
+	MaxRequestsPerDay  int
+	MaxTokensPerMinute int
+}
+
+// AgentQuotaStatus is the API response showing an agent's usage against its effective limits.
+type AgentQuotaStatus struct {
+	AgentID uuid.UUID `json:"agent_id"`
+	QuotaStatus
+}
+
+// CostLine is the estimated spend of one agent on one model.
+type CostLine struct {
+	AgentID    uuid.UUID `json:"agent_id"`
+	Model      string    `json:"model"`
+	Executions int       `json:"executions"`
+	TokensUsed int       `json:"tokens_used"`
+	CostUSD    float64   `json:"cost_usd"`
+}
+
+// CostReport is the API response summarizing spend over a time range.
+type CostReport struct {
+	From         time.Time  `json:"from"`
+	To           time.Time  `json:"to"`
+	TotalCostUSD float64    `json:"total_cost_usd"`
+	Breakdown    []CostLine `json:"breakdown"`
 }
@@ -0,0 +1,38 @@
+package quota
+
+import (
+	"strings"
+
+	"github.com/aiox-platform/aiox/internal/config"
+)
+
+// Pricing estimates the USD cost of token usage from configured model prices.
+type Pricing struct {
+	prices map[string]config.ModelPrice
+}
+
+// NewPricing indexes prices by model name and by "provider/model".
+func NewPricing(models []config.ModelPrice) *Pricing {
+	p := &Pricing{prices: make(map[string]config.ModelPrice, len(models)*2)}
+	for _, m := range models {
+		p.prices[strings.ToLower(m.Model)] = m
+		if m.Provider != "" {
+			p.prices[strings.ToLower(m.Provider+"/"+m.Model)] = m
+		}
+	}
+	return p
+}
+
+// EstimateCost returns the cost of tokens on model, or 0 for unpriced models.
+// Workers report a single token total, so it is charged at the mean of the
+// model's input and output prices.
+func (p *Pricing) EstimateCost(model string, tokens int) float64 {
+	if p == nil || tokens <= 0 {
+		return 0
+	}
+	price, ok := p.prices[strings.ToLower(model)]
+	if !ok {
+		return 0
+	}
+	return float64(tokens) * (price.InputPerMTok + price.OutputPerMTok) / 2 / 1_000_000
+}
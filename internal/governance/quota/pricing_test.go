@@ -0,0 +1,25 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aiox-platform/aiox/internal/config"
+)
+
+func TestPricing_EstimateCost(t *testing.T) {
+	p := NewPricing([]config.ModelPrice{
+		{Provider: "openai", Model: "gpt-4o", InputPerMTok: 2, OutputPerMTok: 10},
+	})
+
+	assert.InDelta(t, 0.006, p.EstimateCost("gpt-4o", 1000), 1e-9)
+	assert.InDelta(t, 0.006, p.EstimateCost("OpenAI/GPT-4o", 1000), 1e-9)
+	assert.Zero(t, p.EstimateCost("unknown-model", 1000))
+	assert.Zero(t, p.EstimateCost("gpt-4o", 0))
+}
+
+func TestPricing_NilIsFree(t *testing.T) {
+	var p *Pricing
+	assert.Zero(t, p.EstimateCost("gpt-4o", 1000))
+}
@@ -11,9 +11,13 @@ import (
 )
 
 const (
-	rateLimitKeyPrefix = "quota:minute:"
-	windowDuration     = 60 * time.Second
-	keyTTL             = 90 * time.Second
+	rateLimitKeyPrefix       = "quota:minute:"
+	agentRateLimitKeyPrefix  = "quota:agent:minute:"
+	senderRateLimitKeyPrefix = "quota:sender:minute:"
+	warningKeyPrefix         = "quota:warning:"
+	windowDuration           = 60 * time.Second
+	keyTTL                   = 90 * time.Second
+	warningKeyTTL            = 25 * time.Hour
 )
 
 // RateLimiter implements a Redis sorted-set sliding window for per-minute rate limiting.
@@ -30,7 +34,20 @@ func NewRateLimiter(rdb redis.Cmdable) *RateLimiter {
 // If under limit, it increments the counter and returns true (allowed).
 // If over limit, it returns false (denied).
 func (rl *RateLimiter) CheckAndIncrement(ctx context.Context, userID uuid.UUID, maxPerMinute int) (bool, error) {
-	key := rateLimitKeyPrefix + userID.String()
+	return rl.checkAndIncrement(ctx, rateLimitKeyPrefix+userID.String(), maxPerMinute)
+}
+
+// CheckAndIncrementAgent is CheckAndIncrement for an agent's own per-minute window.
+func (rl *RateLimiter) CheckAndIncrementAgent(ctx context.Context, agentID uuid.UUID, maxPerMinute int) (bool, error) {
+	return rl.checkAndIncrement(ctx, agentRateLimitKeyPrefix+agentID.String(), maxPerMinute)
+}
+
+// CheckAndIncrementSender is CheckAndIncrement for one sender's messages to an agent.
+func (rl *RateLimiter) CheckAndIncrementSender(ctx context.Context, agentID uuid.UUID, senderJID string, maxPerMinute int) (bool, error) {
+	return rl.checkAndIncrement(ctx, senderRateLimitKeyPrefix+agentID.String()+":"+senderJID, maxPerMinute)
+}
+
+func (rl *RateLimiter) checkAndIncrement(ctx context.Context, key string, maxPerMinute int) (bool, error) {
 	now := time.Now()
 	nowMs := float64(now.UnixMilli())
 	windowStart := float64(now.Add(-windowDuration).UnixMilli())
@@ -69,7 +86,15 @@ func (rl *RateLimiter) CheckAndIncrement(ctx context.Context, userID uuid.UUID,
 
 // GetMinuteUsage returns the current number of requests in the sliding window.
 func (rl *RateLimiter) GetMinuteUsage(ctx context.Context, userID uuid.UUID) (int, error) {
-	key := rateLimitKeyPrefix + userID.String()
+	return rl.minuteUsage(ctx, rateLimitKeyPrefix+userID.String())
+}
+
+// GetAgentMinuteUsage returns the current number of requests in the agent's sliding window.
+func (rl *RateLimiter) GetAgentMinuteUsage(ctx context.Context, agentID uuid.UUID) (int, error) {
+	return rl.minuteUsage(ctx, agentRateLimitKeyPrefix+agentID.String())
+}
+
+func (rl *RateLimiter) minuteUsage(ctx context.Context, key string) (int, error) {
 	now := time.Now()
 	windowStart := float64(now.Add(-windowDuration).UnixMilli())
 	nowMs := float64(now.UnixMilli())
@@ -80,3 +105,14 @@ func (rl *RateLimiter) GetMinuteUsage(ctx context.Context, userID uuid.UUID) (in
 	}
 	return int(count), nil
 }
+
+// MarkWarning records that the user crossed threshold on the given UTC day.
+// It returns true only the first time for each user, day, and threshold.
+func (rl *RateLimiter) MarkWarning(ctx context.Context, userID uuid.UUID, threshold int, day time.Time) (bool, error) {
+	key := fmt.Sprintf("%s%s:%s:%d", warningKeyPrefix, userID, day.UTC().Format(time.DateOnly), threshold)
+	first, err := rl.rdb.SetNX(ctx, key, 1, warningKeyTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("marking quota warning: %w", err)
+	}
+	return first, nil
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// Repository handles user_quotas PostgreSQL operations.
+// Repository handles user_quotas and agent_quotas PostgreSQL operations.
 type Repository struct {
 	pool *pgxpool.Pool
 }
@@ -71,6 +71,43 @@ func (r *Repository) ResetDailyIfStale(ctx context.Context, userID uuid.UUID) (b
 	return tag.RowsAffected() > 0, nil
 }
 
+// ListUsage returns every user's daily counters, heaviest token users first,
+// with the total number of users. Counters past their daily reset read as zero.
+func (r *Repository) ListUsage(ctx context.Context, page, pageSize int) ([]UserUsage, int64, error) {
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting users: %w", err)
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT u.id, u.email, u.is_admin,
+		        CASE WHEN q.last_daily_reset < NOW() - INTERVAL '24 hours' THEN 0 ELSE COALESCE(q.tokens_used_today, 0) END,
+		        CASE WHEN q.last_daily_reset < NOW() - INTERVAL '24 hours' THEN 0 ELSE COALESCE(q.requests_today, 0) END,
+		        q.last_daily_reset
+		 FROM users u
+		 LEFT JOIN user_quotas q ON q.user_id = u.id
+		 ORDER BY 4 DESC, 5 DESC, u.email
+		 LIMIT $1 OFFSET $2`,
+		pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying user usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make([]UserUsage, 0)
+	for rows.Next() {
+		var u UserUsage
+		if err := rows.Scan(&u.UserID, &u.Email, &u.IsAdmin, &u.TokensUsedToday, &u.RequestsToday, &u.LastDailyReset); err != nil {
+			return nil, 0, fmt.Errorf("scanning user usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterating user usage: %w", err)
+	}
+	return usage, total, nil
+}
+
 // RecordViolation appends a violation entry to the violations JSONB array.
 func (r *Repository) RecordViolation(ctx context.Context, userID uuid.UUID, violation string) error {
 	entry := map[string]any{
@@ -92,3 +129,94 @@ func (r *Repository) RecordViolation(ctx context.Context, userID uuid.UUID, viol
 	}
 	return nil
 }
+
+// GetOrCreateAgent returns the agent's quota row, creating one if it doesn't exist.
+func (r *Repository) GetOrCreateAgent(ctx context.Context, agentID, ownerUserID uuid.UUID) (*AgentQuota, error) {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO agent_quotas (agent_id, owner_user_id) VALUES ($1, $2) ON CONFLICT (agent_id) DO NOTHING`,
+		agentID, ownerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("ensuring agent quota: %w", err)
+	}
+
+	var q AgentQuota
+	err = r.pool.QueryRow(ctx,
+		`SELECT agent_id, owner_user_id, tokens_used_today, requests_today, last_daily_reset, updated_at
+		 FROM agent_quotas WHERE agent_id = $1`, agentID,
+	).Scan(&q.AgentID, &q.OwnerUserID, &q.TokensUsedToday, &q.RequestsToday, &q.LastDailyReset, &q.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("fetching agent quota: %w", err)
+	}
+	return &q, nil
+}
+
+// IncrementAgentDaily adds tokens and increments the agent's request count for the day.
+func (r *Repository) IncrementAgentDaily(ctx context.Context, agentID, ownerUserID uuid.UUID, tokens int) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO agent_quotas (agent_id, owner_user_id, tokens_used_today, requests_today)
+		 VALUES ($1, $2, $3, 1)
+		 ON CONFLICT (agent_id) DO UPDATE
+		 SET tokens_used_today = agent_quotas.tokens_used_today + EXCLUDED.tokens_used_today,
+		     requests_today = agent_quotas.requests_today + 1,
+		     updated_at = NOW()`, agentID, ownerUserID, tokens)
+	if err != nil {
+		return fmt.Errorf("incrementing agent daily quota: %w", err)
+	}
+	return nil
+}
+
+// ResetAgentDailyIfStale resets the agent's daily counters if last reset was more than 24h ago.
+func (r *Repository) ResetAgentDailyIfStale(ctx context.Context, agentID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE agent_quotas
+		 SET tokens_used_today = 0,
+		     requests_today = 0,
+		     last_daily_reset = NOW(),
+		     updated_at = NOW()
+		 WHERE agent_id = $1 AND last_daily_reset < NOW() - INTERVAL '24 hours'`, agentID)
+	if err != nil {
+		return false, fmt.Errorf("resetting agent daily quota: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// SpendSince sums the estimated cost of the user's executions since the given
+// time, optionally restricted to one agent.
+func (r *Repository) SpendSince(ctx context.Context, ownerUserID uuid.UUID, agentID *uuid.UUID, since time.Time) (float64, error) {
+	var spend float64
+	err := r.pool.QueryRow(ctx,
+		`SELECT COALESCE(SUM(cost_usd), 0)::float8 FROM executions
+		 WHERE owner_user_id = $1 AND ($2::uuid IS NULL OR agent_id = $2) AND created_at >= $3`,
+		ownerUserID, agentID, since,
+	).Scan(&spend)
+	if err != nil {
+		return 0, fmt.Errorf("summing spend: %w", err)
+	}
+	return spend, nil
+}
+
+// CostByAgentModel returns the user's executions in [from, to] grouped by agent
+// and model, most expensive first.
+func (r *Repository) CostByAgentModel(ctx context.Context, ownerUserID uuid.UUID, from, to time.Time) ([]CostLine, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT agent_id, COALESCE(model, ''), COUNT(*), COALESCE(SUM(tokens_used), 0), COALESCE(SUM(cost_usd), 0)::float8
+		 FROM executions
+		 WHERE owner_user_id = $1 AND created_at >= $2 AND created_at <= $3
+		 GROUP BY agent_id, COALESCE(model, '')
+		 ORDER BY 5 DESC, 1, 2`,
+		ownerUserID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying cost breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]CostLine, 0)
+	for rows.Next() {
+		var l CostLine
+		if err := rows.Scan(&l.AgentID, &l.Model, &l.Executions, &l.TokensUsed, &l.CostUSD); err != nil {
+			return nil, fmt.Errorf("scanning cost breakdown: %w", err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
@@ -2,41 +2,88 @@ package quota
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/aiox-platform/aiox/internal/config"
+	inats "github.com/aiox-platform/aiox/internal/nats"
 )
 
+// ErrQuotaExceeded matches (via errors.Is) every limit hit returned by
+// CheckQuota and CheckAgentQuota, so callers can tell them apart from
+// infrastructure failures.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// LimitError reports which limit a request hit.
+type LimitError struct {
+	// Limit names the limit, e.g. "daily_token_limit" or "agent_rate_limit_minute".
+	Limit   string
+	Message string
+}
+
+func (e *LimitError) Error() string { return e.Message }
+
+func (e *LimitError) Is(target error) bool { return target == ErrQuotaExceeded }
+
+func limitError(limit, format string, args ...any) error {
+	return &LimitError{Limit: limit, Message: fmt.Sprintf(format, args...)}
+}
+
+// AuditPublisher publishes audit events. *inats.Publisher satisfies it.
+type AuditPublisher interface {
+	PublishAuditEvent(ctx context.Context, event inats.AuditEvent) error
+}
+
 // Service orchestrates Redis rate limiting and PostgreSQL quota tracking.
 type Service struct {
-	repo    *Repository
-	limiter *RateLimiter
-	cfg     config.GovernanceCfg
+	repo      *Repository
+	limiter   *RateLimiter
+	cfg       atomic.Pointer[config.GovernanceCfg]
+	publisher AuditPublisher
 }
 
 // NewService creates a new quota Service.
 func NewService(repo *Repository, limiter *RateLimiter, cfg config.GovernanceCfg) *Service {
-	return &Service{
+	s := &Service{
 		repo:    repo,
 		limiter: limiter,
-		cfg:     cfg,
 	}
+	s.cfg.Store(&cfg)
+	return s
+}
+
+// SetConfig swaps the global limits at runtime (e.g. on SIGHUP reload).
+func (s *Service) SetConfig(cfg config.GovernanceCfg) {
+	s.cfg.Store(&cfg)
+}
+
+// SetAuditPublisher enables quota_warning audit events. It must be called
+// before the service is used.
+func (s *Service) SetAuditPublisher(p AuditPublisher) {
+	s.publisher = p
+}
+
+func (s *Service) limits() *config.GovernanceCfg {
+	return s.cfg.Load()
 }
 
 // CheckQuota verifies that the user has not exceeded rate or daily limits.
 // Returns nil if allowed, or an error describing the exceeded limit.
 func (s *Service) CheckQuota(ctx context.Context, userID uuid.UUID) error {
+	cfg := s.limits()
 	// 1. Redis sliding-window per-minute rate limit (fast path)
-	allowed, err := s.limiter.CheckAndIncrement(ctx, userID, s.cfg.MaxTokensPerMinute)
+	allowed, err := s.limiter.CheckAndIncrement(ctx, userID, cfg.MaxTokensPerMinute)
 	if err != nil {
 		slog.Warn("quota: rate limiter check failed, allowing request", "error", err)
 		// Fail open on Redis errors to not block the user
 	} else if !allowed {
 		_ = s.repo.RecordViolation(ctx, userID, "rate_limit_minute")
-		return fmt.Errorf("rate limit exceeded: max %d requests per minute", s.cfg.MaxTokensPerMinute)
+		return limitError("rate_limit_minute", "rate limit exceeded: max %d requests per minute", cfg.MaxTokensPerMinute)
 	}
 
 	// 2. PostgreSQL daily limits
@@ -51,19 +98,83 @@ func (s *Service) CheckQuota(ctx context.Context, userID uuid.UUID) error {
 		return nil // Fail open
 	}
 
-	if quota.TokensUsedToday >= s.cfg.MaxTokensPerDay {
+	if quota.TokensUsedToday >= cfg.MaxTokensPerDay {
 		_ = s.repo.RecordViolation(ctx, userID, "daily_token_limit")
-		return fmt.Errorf("daily token limit exceeded: %d/%d tokens used", quota.TokensUsedToday, s.cfg.MaxTokensPerDay)
+		return limitError("daily_token_limit", "daily token limit exceeded: %d/%d tokens used", quota.TokensUsedToday, cfg.MaxTokensPerDay)
 	}
 
-	if quota.RequestsToday >= s.cfg.MaxRequestsPerDay {
+	if quota.RequestsToday >= cfg.MaxRequestsPerDay {
 		_ = s.repo.RecordViolation(ctx, userID, "daily_request_limit")
-		return fmt.Errorf("daily request limit exceeded: %d/%d requests", quota.RequestsToday, s.cfg.MaxRequestsPerDay)
+		return limitError("daily_request_limit", "daily request limit exceeded: %d/%d requests", quota.RequestsToday, cfg.MaxRequestsPerDay)
 	}
 
+	s.warnIfNearLimit(ctx, userID, quota, cfg)
+
 	return nil
 }
 
+// warnIfNearLimit publishes a quota_warning audit event for each warning
+// threshold the user's daily usage has crossed, once per threshold per day.
+func (s *Service) warnIfNearLimit(ctx context.Context, userID uuid.UUID, quota *UserQuota, cfg *config.GovernanceCfg) {
+	if s.publisher == nil {
+		return
+	}
+
+	percent := usagePercent(quota, cfg)
+	now := time.Now().UTC()
+	for _, threshold := range cfg.WarningThresholds {
+		if percent < threshold {
+			break
+		}
+		first, err := s.limiter.MarkWarning(ctx, userID, threshold, now)
+		if err != nil {
+			slog.Warn("quota: recording warning failed", "error", err)
+			return
+		}
+		if !first {
+			continue
+		}
+
+		event := inats.AuditEvent{
+			OwnerUserID:  userID,
+			EventType:    "quota_warning",
+			Severity:     "warn",
+			ResourceType: "user",
+			ResourceID:   userID.String(),
+			Details: fmt.Sprintf("Daily quota %d%% used (warning at %d%%): %d/%d tokens, %d/%d requests",
+				percent, threshold, quota.TokensUsedToday, cfg.MaxTokensPerDay, quota.RequestsToday, cfg.MaxRequestsPerDay),
+			Timestamp: now,
+		}
+		if err := s.publisher.PublishAuditEvent(ctx, event); err != nil {
+			slog.Error("quota: publishing warning", "error", err)
+		}
+	}
+}
+
+// usagePercent is the higher of the token and request usage as a percentage
+// of their daily limits.
+func usagePercent(quota *UserQuota, cfg *config.GovernanceCfg) int {
+	var percent int
+	if cfg.MaxTokensPerDay > 0 {
+		percent = quota.TokensUsedToday * 100 / cfg.MaxTokensPerDay
+	}
+	if cfg.MaxRequestsPerDay > 0 {
+		percent = max(percent, quota.RequestsToday*100/cfg.MaxRequestsPerDay)
+	}
+	return percent
+}
+
+// highestThreshold returns the largest threshold at or below percent, or 0.
+func highestThreshold(percent int, thresholds []int) int {
+	var crossed int
+	for _, t := range thresholds {
+		if percent >= t {
+			crossed = t
+		}
+	}
+	return crossed
+}
+
 // DeductTokens records token usage after a successful worker response.
 func (s *Service) DeductTokens(ctx context.Context, userID uuid.UUID, tokensUsed int) error {
 	return s.repo.IncrementDaily(ctx, userID, tokensUsed)
@@ -71,6 +182,7 @@ func (s *Service) DeductTokens(ctx context.Context, userID uuid.UUID, tokensUsed
 
 // GetQuota returns the user's current quota status for API display.
 func (s *Service) GetQuota(ctx context.Context, userID uuid.UUID) (*QuotaStatus, error) {
+	cfg := s.limits()
 	// Reset if stale before reading
 	if _, err := s.repo.ResetDailyIfStale(ctx, userID); err != nil {
 		slog.Warn("quota: daily reset check failed", "error", err)
@@ -87,12 +199,144 @@ func (s *Service) GetQuota(ctx context.Context, userID uuid.UUID) (*QuotaStatus,
 		minuteUsage = 0
 	}
 
+	spend, err := s.repo.SpendSince(ctx, userID, nil, quota.LastDailyReset)
+	if err != nil {
+		slog.Warn("quota: failed to get spend", "error", err)
+	}
+
 	return &QuotaStatus{
 		TokensUsedToday:   quota.TokensUsedToday,
-		TokensLimitDay:    s.cfg.MaxTokensPerDay,
+		TokensLimitDay:    cfg.MaxTokensPerDay,
 		RequestsToday:     quota.RequestsToday,
-		RequestsLimitDay:  s.cfg.MaxRequestsPerDay,
+		RequestsLimitDay:  cfg.MaxRequestsPerDay,
 		TokensUsedMinute:  minuteUsage,
-		TokensLimitMinute: s.cfg.MaxTokensPerMinute,
+		TokensLimitMinute: cfg.MaxTokensPerMinute,
+		CostTodayUSD:      spend,
+		Warning:           highestThreshold(usagePercent(quota, cfg), cfg.WarningThresholds),
+	}, nil
+}
+
+// ListUsage returns every user's daily usage against the global limits, for
+// the admin quota overview.
+func (s *Service) ListUsage(ctx context.Context, page, pageSize int) ([]UserUsage, int64, error) {
+	cfg := s.limits()
+	usage, total, err := s.repo.ListUsage(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range usage {
+		usage[i].TokensLimitDay = cfg.MaxTokensPerDay
+		usage[i].RequestsLimitDay = cfg.MaxRequestsPerDay
+	}
+	return usage, total, nil
+}
+
+// EffectiveAgentLimits resolves an agent's overrides against the user/global
+// limits. An agent can only tighten a limit, never exceed the user's own.
+func (s *Service) EffectiveAgentLimits(limits AgentLimits) AgentLimits {
+	cfg := s.limits()
+	return AgentLimits{
+		MaxTokensPerDay:    effectiveLimit(limits.MaxTokensPerDay, cfg.MaxTokensPerDay),
+		MaxRequestsPerDay:  effectiveLimit(limits.MaxRequestsPerDay, cfg.MaxRequestsPerDay),
+		MaxTokensPerMinute: effectiveLimit(limits.MaxTokensPerMinute, cfg.MaxTokensPerMinute),
+	}
+}
+
+func effectiveLimit(agentLimit, userLimit int) int {
+	if agentLimit > 0 && agentLimit < userLimit {
+		return agentLimit
+	}
+	return userLimit
+}
+
+// CheckAgentQuota verifies the agent has not exceeded its own overrides.
+// Limits left at zero are not checked here; CheckQuota covers the user-level ones.
+func (s *Service) CheckAgentQuota(ctx context.Context, agentID, ownerUserID uuid.UUID, limits AgentLimits) error {
+	if limits.MaxTokensPerMinute > 0 {
+		allowed, err := s.limiter.CheckAndIncrementAgent(ctx, agentID, limits.MaxTokensPerMinute)
+		if err != nil {
+			slog.Warn("quota: agent rate limiter check failed, allowing request", "error", err)
+		} else if !allowed {
+			return limitError("agent_rate_limit_minute", "agent rate limit exceeded: max %d requests per minute", limits.MaxTokensPerMinute)
+		}
+	}
+
+	if limits.MaxTokensPerDay <= 0 && limits.MaxRequestsPerDay <= 0 {
+		return nil
+	}
+
+	if _, err := s.repo.ResetAgentDailyIfStale(ctx, agentID); err != nil {
+		slog.Warn("quota: agent daily reset check failed", "error", err)
+	}
+
+	quota, err := s.repo.GetOrCreateAgent(ctx, agentID, ownerUserID)
+	if err != nil {
+		slog.Warn("quota: failed to get agent quota, allowing request", "error", err)
+		return nil // Fail open
+	}
+
+	if limits.MaxTokensPerDay > 0 && quota.TokensUsedToday >= limits.MaxTokensPerDay {
+		return limitError("agent_daily_token_limit", "agent daily token limit exceeded: %d/%d tokens used", quota.TokensUsedToday, limits.MaxTokensPerDay)
+	}
+	if limits.MaxRequestsPerDay > 0 && quota.RequestsToday >= limits.MaxRequestsPerDay {
+		return limitError("agent_daily_request_limit", "agent daily request limit exceeded: %d/%d requests", quota.RequestsToday, limits.MaxRequestsPerDay)
+	}
+
+	return nil
+}
+
+// DeductAgentTokens records token usage against the agent's own counters.
+func (s *Service) DeductAgentTokens(ctx context.Context, agentID, ownerUserID uuid.UUID, tokensUsed int) error {
+	return s.repo.IncrementAgentDaily(ctx, agentID, ownerUserID, tokensUsed)
+}
+
+// GetAgentQuota returns an agent's current usage and effective limits for API display.
+func (s *Service) GetAgentQuota(ctx context.Context, agentID, ownerUserID uuid.UUID, limits AgentLimits) (*AgentQuotaStatus, error) {
+	if _, err := s.repo.ResetAgentDailyIfStale(ctx, agentID); err != nil {
+		slog.Warn("quota: agent daily reset check failed", "error", err)
+	}
+
+	quota, err := s.repo.GetOrCreateAgent(ctx, agentID, ownerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting agent quota: %w", err)
+	}
+
+	minuteUsage, err := s.limiter.GetAgentMinuteUsage(ctx, agentID)
+	if err != nil {
+		slog.Warn("quota: failed to get agent minute usage", "error", err)
+		minuteUsage = 0
+	}
+
+	spend, err := s.repo.SpendSince(ctx, ownerUserID, &agentID, quota.LastDailyReset)
+	if err != nil {
+		slog.Warn("quota: failed to get agent spend", "error", err)
+	}
+
+	effective := s.EffectiveAgentLimits(limits)
+	return &AgentQuotaStatus{
+		AgentID: agentID,
+		QuotaStatus: QuotaStatus{
+			TokensUsedToday:   quota.TokensUsedToday,
+			TokensLimitDay:    effective.MaxTokensPerDay,
+			RequestsToday:     quota.RequestsToday,
+			RequestsLimitDay:  effective.MaxRequestsPerDay,
+			TokensUsedMinute:  minuteUsage,
+			TokensLimitMinute: effective.MaxTokensPerMinute,
+			CostTodayUSD:      spend,
+		},
 	}, nil
 }
+
+// GetCost summarizes the user's estimated spend in [from, to] by agent and model.
+func (s *Service) GetCost(ctx context.Context, userID uuid.UUID, from, to time.Time) (*CostReport, error) {
+	lines, err := s.repo.CostByAgentModel(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting cost breakdown: %w", err)
+	}
+
+	report := &CostReport{From: from, To: to, Breakdown: lines}
+	for _, l := range lines {
+		report.TotalCostUSD += l.CostUSD
+	}
+	return report, nil
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/config"
+	inats "github.com/aiox-platform/aiox/internal/nats"
 )
 
 func setupMiniredis(t *testing.T) *redis.Client {
@@ -120,3 +123,98 @@ func TestRateLimiter_GetMinuteUsageEmpty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, usage)
 }
+
+func TestRateLimiter_AgentWindowSeparateFromUser(t *testing.T) {
+	rdb := setupMiniredis(t)
+	rl := NewRateLimiter(rdb)
+	ctx := context.Background()
+	id := uuid.New()
+
+	allowed, err := rl.CheckAndIncrementAgent(ctx, id, 1)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = rl.CheckAndIncrementAgent(ctx, id, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// The same UUID as a user key has its own window.
+	allowed, err = rl.CheckAndIncrement(ctx, id, 1)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	usage, err := rl.GetAgentMinuteUsage(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage)
+}
+
+func TestEffectiveAgentLimits(t *testing.T) {
+	svc := NewService(nil, nil, config.GovernanceCfg{MaxTokensPerDay: 1000, MaxRequestsPerDay: 50, MaxTokensPerMinute: 10})
+
+	limits := svc.EffectiveAgentLimits(AgentLimits{MaxTokensPerDay: 200, MaxRequestsPerDay: 500})
+	assert.Equal(t, 200, limits.MaxTokensPerDay)
+	assert.Equal(t, 50, limits.MaxRequestsPerDay, "agent cannot exceed the user limit")
+	assert.Equal(t, 10, limits.MaxTokensPerMinute, "unset falls back to the user limit")
+}
+
+func TestSetConfig_SwapsLimitsForReload(t *testing.T) {
+	svc := NewService(nil, nil, config.GovernanceCfg{MaxTokensPerDay: 1000, MaxRequestsPerDay: 50, MaxTokensPerMinute: 10})
+
+	svc.SetConfig(config.GovernanceCfg{MaxTokensPerDay: 5000, MaxRequestsPerDay: 20, MaxTokensPerMinute: 30})
+
+	limits := svc.EffectiveAgentLimits(AgentLimits{})
+	assert.Equal(t, 5000, limits.MaxTokensPerDay)
+	assert.Equal(t, 20, limits.MaxRequestsPerDay)
+	assert.Equal(t, 30, limits.MaxTokensPerMinute)
+}
+
+type recordingPublisher struct {
+	events []inats.AuditEvent
+}
+
+func (p *recordingPublisher) PublishAuditEvent(_ context.Context, e inats.AuditEvent) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+func TestWarnIfNearLimit_OncePerThresholdPerDay(t *testing.T) {
+	pub := &recordingPublisher{}
+	svc := NewService(nil, NewRateLimiter(setupMiniredis(t)), config.GovernanceCfg{})
+	svc.SetAuditPublisher(pub)
+	cfg := &config.GovernanceCfg{MaxTokensPerDay: 1000, MaxRequestsPerDay: 100, WarningThresholds: []int{80, 95}}
+	userID := uuid.New()
+	ctx := context.Background()
+
+	svc.warnIfNearLimit(ctx, userID, &UserQuota{TokensUsedToday: 500, RequestsToday: 10}, cfg)
+	assert.Empty(t, pub.events)
+
+	svc.warnIfNearLimit(ctx, userID, &UserQuota{TokensUsedToday: 850, RequestsToday: 10}, cfg)
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "quota_warning", pub.events[0].EventType)
+	assert.Contains(t, pub.events[0].Details, "warning at 80%")
+
+	svc.warnIfNearLimit(ctx, userID, &UserQuota{TokensUsedToday: 900, RequestsToday: 10}, cfg)
+	assert.Len(t, pub.events, 1, "80% warning must not repeat the same day")
+
+	svc.warnIfNearLimit(ctx, userID, &UserQuota{TokensUsedToday: 900, RequestsToday: 96}, cfg)
+	require.Len(t, pub.events, 2)
+	assert.Contains(t, pub.events[1].Details, "warning at 95%")
+}
+
+func TestHighestThreshold(t *testing.T) {
+	thresholds := []int{80, 95}
+	assert.Equal(t, 0, highestThreshold(79, thresholds))
+	assert.Equal(t, 80, highestThreshold(80, thresholds))
+	assert.Equal(t, 95, highestThreshold(99, thresholds))
+	assert.Equal(t, 0, highestThreshold(99, nil))
+}
+
+func TestLimitError_MatchesErrQuotaExceeded(t *testing.T) {
+	err := fmt.Errorf("checking quota: %w", limitError("daily_token_limit", "daily token limit exceeded: %d/%d tokens used", 10, 10))
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "daily_token_limit", limitErr.Limit)
+	assert.Equal(t, "daily token limit exceeded: 10/10 tokens used", limitErr.Error())
+}
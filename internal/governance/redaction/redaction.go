@@ -0,0 +1,234 @@
+package redaction
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+)
+
+// Redactor transforms text before it is persisted or delivered.
+// Implementations must be safe for concurrent use.
+type Redactor interface {
+	Redact(text string) string
+}
+
+// RedactorFunc adapts a plain function to the Redactor interface.
+type RedactorFunc func(text string) string
+
+// Redact calls f(text).
+func (f RedactorFunc) Redact(text string) string {
+	return f(text)
+}
+
+// Rule is a single named regex replacement.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Validate, if set, is called for each match; matches it rejects are kept as-is.
+	Validate func(match string) bool
+}
+
+// builtinRules are the named patterns available to deployments and agents.
+var builtinRules = map[string]Rule{
+	"email": {
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	},
+	"credit_card": {
+		Name:     "credit_card",
+		Pattern:  regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		Validate: luhnValid,
+	},
+	"phone": {
+		Name:     "phone",
+		Pattern:  regexp.MustCompile(`(?:\+\d{1,3}[ \-]?)?(?:\(\d{2,4}\)[ \-]?|\b)\d{2,4}(?:[ \-]?\d{2,4}){2,3}\b`),
+		Validate: phoneValid,
+	},
+	"ipv4": {
+		Name:    "ipv4",
+		Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+	},
+}
+
+// PatternRedactor replaces every match of its rules with "[REDACTED:<name>]".
+type PatternRedactor struct {
+	rules []Rule
+}
+
+// Compile builds a PatternRedactor from built-in rule names or raw regular expressions.
+func Compile(patterns []string) (*PatternRedactor, error) {
+	pr := &PatternRedactor{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if rule, ok := builtinRules[p]; ok {
+			pr.rules = append(pr.rules, rule)
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("compiling redaction pattern %q: %w", p, err)
+		}
+		pr.rules = append(pr.rules, Rule{Name: "custom", Pattern: re})
+	}
+	return pr, nil
+}
+
+// Redact applies all rules to text in order.
+func (p *PatternRedactor) Redact(text string) string {
+	for _, rule := range p.rules {
+		replacement := "[REDACTED:" + rule.Name + "]"
+		if rule.Validate == nil {
+			text = rule.Pattern.ReplaceAllLiteralString(text, replacement)
+			continue
+		}
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.Validate(match) {
+				return replacement
+			}
+			return match
+		})
+	}
+	return text
+}
+
+// Empty reports whether the redactor has no rules.
+func (p *PatternRedactor) Empty() bool {
+	return len(p.rules) == 0
+}
+
+// chain applies several redactors in sequence.
+type chain []Redactor
+
+func (c chain) Redact(text string) string {
+	for _, r := range c {
+		text = r.Redact(text)
+	}
+	return text
+}
+
+// None leaves text untouched. The dispatcher uses it for tasks an admin
+// asked to run unredacted.
+var None Redactor = RedactorFunc(func(text string) string { return text })
+
+// Engine combines deployment-wide patterns, per-agent policies, and any
+// registered custom redactors.
+type Engine struct {
+	base    *PatternRedactor
+	cfg     config.RedactionConfig
+	mu      sync.RWMutex
+	plugins []Redactor
+	cache   sync.Map // per-agent pattern list → *PatternRedactor
+}
+
+// NewEngine creates a redaction Engine from deployment configuration.
+func NewEngine(cfg config.RedactionConfig) (*Engine, error) {
+	base, err := Compile(cfg.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{base: base, cfg: cfg}, nil
+}
+
+// Register adds a custom redactor applied after the pattern rules.
+func (e *Engine) Register(r Redactor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.plugins = append(e.plugins, r)
+}
+
+// ForAgent returns the redactors for stored content and for outbound replies,
+// given the agent's governance policy. Invalid agent patterns are logged and skipped.
+func (e *Engine) ForAgent(policy policy.RedactionPolicy) (storage Redactor, outbound Redactor) {
+	if e == nil {
+		return None, None
+	}
+
+	c := chain{}
+	if !e.base.Empty() {
+		c = append(c, e.base)
+	}
+	if len(policy.Patterns) > 0 {
+		if agentRedactor := e.compileCached(policy.Patterns); agentRedactor != nil {
+			c = append(c, agentRedactor)
+		}
+	}
+	e.mu.RLock()
+	c = append(c, e.plugins...)
+	e.mu.RUnlock()
+
+	if len(c) == 0 {
+		return None, None
+	}
+
+	storage = c
+	outbound = None
+	if e.cfg.RedactOutbound || policy.RedactOutbound {
+		outbound = c
+	}
+	return storage, outbound
+}
+
+// Deployment returns the redactor for content with no agent policy, such as
+// audit log details: the deployment-wide patterns and registered redactors.
+func (e *Engine) Deployment() Redactor {
+	storage, _ := e.ForAgent(policy.RedactionPolicy{})
+	return storage
+}
+
+func (e *Engine) compileCached(patterns []string) *PatternRedactor {
+	key := strings.Join(patterns, "\x00")
+	if v, ok := e.cache.Load(key); ok {
+		return v.(*PatternRedactor)
+	}
+	pr, err := Compile(patterns)
+	if err != nil {
+		slog.Warn("redaction: ignoring invalid agent patterns", "error", err)
+		return nil
+	}
+	e.cache.Store(key, pr)
+	return pr
+}
+
+// phoneValid accepts matches with 10 to 15 digits, the range of E.164
+// numbers with an area code, so short numbers and dates are left alone.
+func phoneValid(s string) bool {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits++
+		}
+	}
+	return digits >= 10 && digits <= 15
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	digits := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
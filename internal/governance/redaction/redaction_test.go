@@ -0,0 +1,93 @@
+package redaction
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+)
+
+func TestCompile_Builtins(t *testing.T) {
+	r, err := Compile([]string{"email", "credit_card"})
+	require.NoError(t, err)
+
+	out := r.Redact("mail alice@example.com, card 4111 1111 1111 1111")
+	assert.Equal(t, "mail [REDACTED:email], card [REDACTED:credit_card]", out)
+}
+
+func TestCompile_CreditCardRequiresLuhn(t *testing.T) {
+	r, err := Compile([]string{"credit_card"})
+	require.NoError(t, err)
+
+	// Fails the Luhn check, so it is left alone.
+	assert.Equal(t, "order 1234567890123456", r.Redact("order 1234567890123456"))
+}
+
+func TestCompile_CustomRegex(t *testing.T) {
+	r, err := Compile([]string{`ACME-\d{4}`})
+	require.NoError(t, err)
+	assert.Equal(t, "ticket [REDACTED:custom]", r.Redact("ticket ACME-1234"))
+}
+
+func TestCompile_InvalidRegex(t *testing.T) {
+	_, err := Compile([]string{"("})
+	assert.Error(t, err)
+}
+
+func TestEngine_ForAgent(t *testing.T) {
+	e, err := NewEngine(config.RedactionConfig{Patterns: []string{"email"}})
+	require.NoError(t, err)
+
+	storage, outbound := e.ForAgent(policy.RedactionPolicy{Patterns: []string{"ipv4"}})
+	assert.Equal(t, "[REDACTED:email] from [REDACTED:ipv4]", storage.Redact("bob@example.org from 10.0.0.1"))
+	assert.Equal(t, "bob@example.org", outbound.Redact("bob@example.org"))
+
+	_, outbound = e.ForAgent(policy.RedactionPolicy{RedactOutbound: true})
+	assert.Equal(t, "[REDACTED:email]", outbound.Redact("bob@example.org"))
+}
+
+func TestEngine_Register(t *testing.T) {
+	e, err := NewEngine(config.RedactionConfig{})
+	require.NoError(t, err)
+	e.Register(RedactorFunc(strings.ToUpper))
+
+	storage, _ := e.ForAgent(policy.RedactionPolicy{})
+	assert.Equal(t, "SECRET", storage.Redact("secret"))
+}
+
+func TestCompile_Phone(t *testing.T) {
+	r, err := Compile([]string{"phone"})
+	require.NoError(t, err)
+
+	for in, want := range map[string]string{
+		"call +1 415 555 0132 now":  "call [REDACTED:phone] now",
+		"office (415) 555-0132":     "office [REDACTED:phone]",
+		"uk +44 20 7946 0958":       "uk [REDACTED:phone]",
+		"due on 2026-01-05":         "due on 2026-01-05",
+		"extension 5550":            "extension 5550",
+		"server at 192.168.100.200": "server at 192.168.100.200",
+	} {
+		assert.Equal(t, want, r.Redact(in), in)
+	}
+}
+
+func TestCompile_LeavesNormalTextUntouched(t *testing.T) {
+	r, err := Compile([]string{"email", "phone", "credit_card"})
+	require.NoError(t, err)
+
+	text := "Meeting moved to 3pm in room 42; bring the Q3 report (v2.1)."
+	assert.Equal(t, text, r.Redact(text))
+
+	assert.Equal(t, "reach me at [REDACTED:email] or [REDACTED:phone], card [REDACTED:credit_card]",
+		r.Redact("reach me at bob@example.org or 415-555-0132, card 4111 1111 1111 1111"))
+}
+
+func TestEngine_Deployment(t *testing.T) {
+	e, err := NewEngine(config.RedactionConfig{Patterns: []string{"email"}})
+	require.NoError(t, err)
+	assert.Equal(t, "from [REDACTED:email]", e.Deployment().Redact("from bob@example.org"))
+}
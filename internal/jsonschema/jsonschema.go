@@ -0,0 +1,409 @@
+// Package jsonschema validates JSON documents against the subset of JSON
+// Schema that agents use to describe structured responses: type, enum,
+// const, properties, required, additionalProperties, items, the numeric,
+// string and array bounds, pattern, and allOf/anyOf/oneOf. Annotations such
+// as title and description are ignored; $ref is rejected rather than
+// silently skipped.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var types = []string{"array", "boolean", "integer", "null", "number", "object", "string"}
+
+// Schema is a compiled JSON Schema. The zero value accepts any document.
+type Schema struct {
+	reject bool // the false schema
+
+	types    []string
+	enum     []any
+	constVal any
+	hasConst bool
+
+	properties  map[string]*Schema
+	required    []string
+	additional  *Schema
+	minimum     *float64
+	maximum     *float64
+	exclMinimum *float64
+	exclMaximum *float64
+	minLength   *int
+	maxLength   *int
+	pattern     *regexp.Regexp
+	items       *Schema
+	minItems    *int
+	maxItems    *int
+	allOf       []*Schema
+	anyOf       []*Schema
+	oneOf       []*Schema
+}
+
+// Compile parses a JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	var raw any
+	if err := decode(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	return compile(raw, "$")
+}
+
+// ValidationError reports the first part of a document that does not match
+// its schema.
+type ValidationError struct {
+	// Path locates the offending value, e.g. "$.items[2].name".
+	Path   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Reason
+}
+
+// Validate checks that doc is a single JSON value matching the schema.
+// A mismatch is reported as a *ValidationError.
+func (s *Schema) Validate(doc []byte) error {
+	var v any
+	if err := decode(doc, &v); err != nil {
+		return fmt.Errorf("parsing document: %w", err)
+	}
+	return s.validate(v, "$")
+}
+
+func decode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the top-level value")
+	}
+	return nil
+}
+
+func compile(raw any, path string) (*Schema, error) {
+	switch v := raw.(type) {
+	case bool:
+		return &Schema{reject: !v}, nil
+	case map[string]any:
+		return compileObject(v, path)
+	}
+	return nil, fmt.Errorf("%s: schema must be an object or a boolean", path)
+}
+
+func compileObject(m map[string]any, path string) (*Schema, error) {
+	if _, ok := m["$ref"]; ok {
+		return nil, fmt.Errorf("%s: $ref is not supported", path)
+	}
+
+	s := &Schema{}
+	var err error
+	if t, ok := m["type"]; ok {
+		if s.types, err = compileTypes(t); err != nil {
+			return nil, fmt.Errorf("%s.type: %w", path, err)
+		}
+	}
+	if e, ok := m["enum"]; ok {
+		list, isList := e.([]any)
+		if !isList || len(list) == 0 {
+			return nil, fmt.Errorf("%s.enum: must be a non-empty array", path)
+		}
+		s.enum = list
+	}
+	if c, ok := m["const"]; ok {
+		s.constVal, s.hasConst = c, true
+	}
+
+	if p, ok := m["properties"]; ok {
+		props, isObject := p.(map[string]any)
+		if !isObject {
+			return nil, fmt.Errorf("%s.properties: must be an object", path)
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, sub := range props {
+			if s.properties[name], err = compile(sub, path+".properties."+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r, ok := m["required"]; ok {
+		list, isList := r.([]any)
+		if !isList {
+			return nil, fmt.Errorf("%s.required: must be an array of strings", path)
+		}
+		for _, name := range list {
+			str, isString := name.(string)
+			if !isString {
+				return nil, fmt.Errorf("%s.required: must be an array of strings", path)
+			}
+			s.required = append(s.required, str)
+		}
+	}
+	if a, ok := m["additionalProperties"]; ok {
+		if s.additional, err = compile(a, path+".additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if i, ok := m["items"]; ok {
+		if s.items, err = compile(i, path+".items"); err != nil {
+			return nil, err
+		}
+	}
+	for keyword, list := range map[string]*[]*Schema{"allOf": &s.allOf, "anyOf": &s.anyOf, "oneOf": &s.oneOf} {
+		v, ok := m[keyword]
+		if !ok {
+			continue
+		}
+		subs, isList := v.([]any)
+		if !isList || len(subs) == 0 {
+			return nil, fmt.Errorf("%s.%s: must be a non-empty array", path, keyword)
+		}
+		for i, sub := range subs {
+			c, err := compile(sub, fmt.Sprintf("%s.%s[%d]", path, keyword, i))
+			if err != nil {
+				return nil, err
+			}
+			*list = append(*list, c)
+		}
+	}
+
+	for keyword, dst := range map[string]**float64{
+		"minimum": &s.minimum, "maximum": &s.maximum,
+		"exclusiveMinimum": &s.exclMinimum, "exclusiveMaximum": &s.exclMaximum,
+	} {
+		if v, ok := m[keyword]; ok {
+			n, err := number(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", path, keyword, err)
+			}
+			*dst = &n
+		}
+	}
+	for keyword, dst := range map[string]**int{
+		"minLength": &s.minLength, "maxLength": &s.maxLength,
+		"minItems": &s.minItems, "maxItems": &s.maxItems,
+	} {
+		if v, ok := m[keyword]; ok {
+			n, err := number(v)
+			if err != nil || n < 0 || n != math.Trunc(n) {
+				return nil, fmt.Errorf("%s.%s: must be a non-negative integer", path, keyword)
+			}
+			i := int(n)
+			*dst = &i
+		}
+	}
+	if p, ok := m["pattern"]; ok {
+		str, isString := p.(string)
+		if !isString {
+			return nil, fmt.Errorf("%s.pattern: must be a string", path)
+		}
+		if s.pattern, err = regexp.Compile(str); err != nil {
+			return nil, fmt.Errorf("%s.pattern: %q is not a valid regular expression", path, str)
+		}
+	}
+	return s, nil
+}
+
+func compileTypes(v any) ([]string, error) {
+	var names []string
+	switch t := v.(type) {
+	case string:
+		names = []string{t}
+	case []any:
+		for _, e := range t {
+			str, ok := e.(string)
+			if !ok {
+				return nil, errors.New("must be a string or an array of strings")
+			}
+			names = append(names, str)
+		}
+	default:
+		return nil, errors.New("must be a string or an array of strings")
+	}
+	for _, name := range names {
+		if !slices.Contains(types, name) {
+			return nil, fmt.Errorf("%q is not one of %s", name, strings.Join(types, ", "))
+		}
+	}
+	return names, nil
+}
+
+func number(v any) (float64, error) {
+	n, ok := v.(float64)
+	if !ok {
+		return 0, errors.New("must be a number")
+	}
+	return n, nil
+}
+
+func (s *Schema) validate(v any, path string) error {
+	if s.reject {
+		return &ValidationError{Path: path, Reason: "no value is allowed here"}
+	}
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return hasType(v, t) }) {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))}
+	}
+	if s.enum != nil && !slices.ContainsFunc(s.enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		return &ValidationError{Path: path, Reason: "value is not one of the allowed values"}
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constVal, v) {
+		return &ValidationError{Path: path, Reason: "value does not equal the required constant"}
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		if err := s.validateObject(val, path); err != nil {
+			return err
+		}
+	case []any:
+		if err := s.validateArray(val, path); err != nil {
+			return err
+		}
+	case string:
+		if err := s.validateString(val, path); err != nil {
+			return err
+		}
+	case float64:
+		if err := s.validateNumber(val, path); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range s.allOf {
+		if err := sub.validate(v, path); err != nil {
+			return err
+		}
+	}
+	if s.anyOf != nil && !slices.ContainsFunc(s.anyOf, func(sub *Schema) bool { return sub.validate(v, path) == nil }) {
+		return &ValidationError{Path: path, Reason: "value matches none of the anyOf schemas"}
+	}
+	if s.oneOf != nil {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.validate(v, path) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("value matches %d of the oneOf schemas, want exactly 1", matched)}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(obj map[string]any, path string) error {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("missing required property %q", name)}
+		}
+	}
+
+	// Check properties in a fixed order so the reported error is stable.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sub, ok := s.properties[name]
+		if !ok {
+			sub = s.additional
+		}
+		if sub == nil {
+			continue
+		}
+		if !ok && sub.reject {
+			return &ValidationError{Path: path, Reason: fmt.Sprintf("property %q is not allowed", name)}
+		}
+		if err := sub.validate(obj[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(arr []any, path string) error {
+	if s.minItems != nil && len(arr) < *s.minItems {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at least %d items, got %d", *s.minItems, len(arr))}
+	}
+	if s.maxItems != nil && len(arr) > *s.maxItems {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at most %d items, got %d", *s.maxItems, len(arr))}
+	}
+	if s.items != nil {
+		for i, e := range arr {
+			if err := s.items.validate(e, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateString(str, path string) error {
+	n := utf8.RuneCountInString(str)
+	if s.minLength != nil && n < *s.minLength {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at least %d characters, got %d", *s.minLength, n)}
+	}
+	if s.maxLength != nil && n > *s.maxLength {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("expected at most %d characters, got %d", *s.maxLength, n)}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("does not match pattern %q", s.pattern.String())}
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(n float64, path string) error {
+	switch {
+	case s.minimum != nil && n < *s.minimum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("must be at least %v", *s.minimum)}
+	case s.maximum != nil && n > *s.maximum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("must be at most %v", *s.maximum)}
+	case s.exclMinimum != nil && n <= *s.exclMinimum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("must be greater than %v", *s.exclMinimum)}
+	case s.exclMaximum != nil && n >= *s.exclMaximum:
+		return &ValidationError{Path: path, Reason: fmt.Sprintf("must be less than %v", *s.exclMaximum)}
+	}
+	return nil
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == t
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
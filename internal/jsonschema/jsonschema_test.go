@@ -0,0 +1,79 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ticketSchema = `{
+	"type": "object",
+	"required": ["title", "priority"],
+	"additionalProperties": false,
+	"properties": {
+		"title": {"type": "string", "minLength": 1, "maxLength": 80},
+		"priority": {"enum": ["low", "medium", "high"]},
+		"estimate_hours": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "maxItems": 3, "items": {"type": "string", "pattern": "^[a-z-]+$"}}
+	}
+}`
+
+func TestValidate(t *testing.T) {
+	s, err := Compile([]byte(ticketSchema))
+	require.NoError(t, err)
+
+	for doc, wantErr := range map[string]string{
+		`{"title": "Fix login", "priority": "high", "estimate_hours": 3, "tags": ["auth"]}`: "",
+		`{"title": "Fix login"}`:                                      `$: missing required property "priority"`,
+		`{"title": "", "priority": "low"}`:                            "$.title: expected at least 1 characters, got 0",
+		`{"title": "x", "priority": "urgent"}`:                        "$.priority: value is not one of the allowed values",
+		`{"title": "x", "priority": "low", "estimate_hours": 1.5}`:    "$.estimate_hours: expected integer, got number",
+		`{"title": "x", "priority": "low", "tags": ["ok", "Not OK"]}`: `$.tags[1]: does not match pattern "^[a-z-]+$"`,
+		`{"title": "x", "priority": "low", "owner": "bob"}`:           `$: property "owner" is not allowed`,
+		`["title"]`: "$: expected object, got array",
+	} {
+		err := s.Validate([]byte(doc))
+		if wantErr == "" {
+			assert.NoError(t, err, doc)
+			continue
+		}
+		var verr *ValidationError
+		require.ErrorAs(t, err, &verr, doc)
+		assert.EqualError(t, err, wantErr, doc)
+	}
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	s, err := Compile([]byte(`{"oneOf": [{"type": "string"}, {"type": "number", "exclusiveMinimum": 0}]}`))
+	require.NoError(t, err)
+
+	assert.NoError(t, s.Validate([]byte(`"ok"`)))
+	assert.NoError(t, s.Validate([]byte(`2`)))
+	assert.Error(t, s.Validate([]byte(`0`)))
+	assert.Error(t, s.Validate([]byte(`null`)))
+}
+
+func TestValidate_InvalidDocument(t *testing.T) {
+	s, err := Compile([]byte(`true`))
+	require.NoError(t, err)
+
+	assert.NoError(t, s.Validate([]byte(`{"anything": 1}`)))
+	assert.Error(t, s.Validate([]byte(`{"a": 1} {"b": 2}`)))
+	assert.Error(t, s.Validate([]byte(`not json`)))
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, schema := range []string{
+		`"object"`,
+		`{"type": "map"}`,
+		`{"required": "title"}`,
+		`{"enum": []}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"properties": {"a": {"$ref": "#/defs/a"}}}`,
+	} {
+		_, err := Compile([]byte(schema))
+		assert.Error(t, err, schema)
+	}
+}
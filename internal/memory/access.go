@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// accessLog buffers search hits so a busy agent costs one UPDATE per flush
+// rather than one per search.
+type accessLog struct {
+	mu   sync.Mutex
+	hits map[uuid.UUID]int
+}
+
+func (l *accessLog) add(results []SearchResult) {
+	if len(results) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.hits == nil {
+		l.hits = make(map[uuid.UUID]int)
+	}
+	for _, r := range results {
+		l.hits[r.Memory.ID]++
+	}
+}
+
+// take removes and returns up to limit buffered memories.
+func (l *accessLog) take(limit int) map[uuid.UUID]int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	batch := make(map[uuid.UUID]int, min(limit, len(l.hits)))
+	for id, n := range l.hits {
+		if len(batch) == limit {
+			break
+		}
+		batch[id] = n
+		delete(l.hits, id)
+	}
+	return batch
+}
+
+// RunAccessFlush writes buffered search hits to access_count and
+// last_accessed_at every AccessFlushInterval until ctx is cancelled, then
+// flushes once more.
+func (s *Service) RunAccessFlush(ctx context.Context) error {
+	ticker := time.NewTicker(AccessFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The run context is gone; give the final flush its own deadline.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			s.flushAccess(flushCtx)
+			return nil
+		case <-ticker.C:
+			s.flushAccess(ctx)
+		}
+	}
+}
+
+func (s *Service) flushAccess(ctx context.Context) {
+	now := time.Now()
+	for {
+		batch := s.access.take(AccessFlushBatchSize)
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.repo.RecordAccess(ctx, batch, now); err != nil {
+			slog.Error("memory: recording memory access", "error", err, "count", len(batch))
+			return
+		}
+	}
+}
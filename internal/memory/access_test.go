@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accessRepo returns fixed search results and records access flushes.
+type accessRepo struct {
+	Repository
+	results []SearchResult
+	flushes []map[uuid.UUID]int
+}
+
+func (r *accessRepo) SearchSimilar(_ context.Context, _, _ uuid.UUID, _ EmbeddingSpace, _ []float32, _ int, _ float64, _ MetadataFilter, _ ...string) ([]SearchResult, error) {
+	return r.results, nil
+}
+
+func (r *accessRepo) RecordAccess(_ context.Context, hits map[uuid.UUID]int, _ time.Time) error {
+	r.flushes = append(r.flushes, hits)
+	return nil
+}
+
+func TestSearch_RecordsAccessInBatches(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	repo := &accessRepo{results: []SearchResult{{Memory: Memory{ID: a}}, {Memory: Memory{ID: b}}}}
+	svc := NewService(repo, nil)
+	req := &SearchMemoryRequest{Embedding: []float32{1, 0}}
+
+	for range 3 {
+		_, err := svc.Search(context.Background(), uuid.New(), uuid.New(), req, dedupConfig())
+		require.NoError(t, err)
+	}
+	assert.Empty(t, repo.flushes, "hits are buffered until the next flush")
+
+	svc.flushAccess(context.Background())
+	require.Len(t, repo.flushes, 1)
+	assert.Equal(t, map[uuid.UUID]int{a: 3, b: 3}, repo.flushes[0])
+
+	svc.flushAccess(context.Background())
+	assert.Len(t, repo.flushes, 1, "an empty buffer writes nothing")
+}
+
+func TestStoreLongTermMemory_DedupSearchIsNotAccess(t *testing.T) {
+	repo := &dedupRepo{match: &SearchResult{Memory: Memory{ID: uuid.New()}, Similarity: 0.99}}
+	svc := NewService(repo, nil)
+
+	_, err := svc.StoreLongTermMemory(context.Background(), &Memory{Content: "x", Embedding: []float32{1, 0}}, dedupConfig())
+	require.NoError(t, err)
+	assert.Empty(t, svc.access.take(AccessFlushBatchSize))
+}
+
+func TestAccessLog_TakeLimit(t *testing.T) {
+	var l accessLog
+	results := make([]SearchResult, 5)
+	for i := range results {
+		results[i].Memory.ID = uuid.New()
+	}
+	l.add(results)
+
+	assert.Len(t, l.take(2), 2)
+	assert.Len(t, l.take(2), 2)
+	assert.Len(t, l.take(2), 1)
+	assert.Empty(t, l.take(2))
+}
@@ -0,0 +1,74 @@
+package memory
+
+import "unicode/utf8"
+
+// TokenCounter estimates how many LLM tokens text takes.
+type TokenCounter func(text string) int
+
+// EstimateTokens is the default TokenCounter: about four characters per
+// token, the usual ratio for English text, rounded up.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// SetTokenCounter replaces EstimateTokens for enforcing max_context_tokens,
+// e.g. with the tokenizer of the model agents use.
+func (s *Service) SetTokenCounter(count TokenCounter) {
+	s.tokens = count
+}
+
+// ContextTruncation reports what was left out of a conversation context to
+// fit memory_config.max_context_tokens.
+type ContextTruncation struct {
+	MaxTokens int `json:"max_tokens"`
+	// EstimatedTokens is the estimate for what was kept.
+	EstimatedTokens int `json:"estimated_tokens"`
+	DroppedMessages int `json:"dropped_messages"`
+	DroppedMemories int `json:"dropped_memories"`
+}
+
+// fitBudget drops entries from p until its estimated size is at most
+// maxTokens. Each step trims whichever side is using more of the budget: the
+// oldest short-term message, or the long-term memory with the lowest
+// similarity. It returns nil when nothing had to be dropped.
+func fitBudget(p *ContextPayload, maxTokens int, count TokenCounter) *ContextTruncation {
+	msgTokens := make([]int, len(p.RecentMessages))
+	var shortTotal int
+	for i, m := range p.RecentMessages {
+		msgTokens[i] = count(m.Content)
+		shortTotal += msgTokens[i]
+	}
+	memTokens := make([]int, len(p.RelevantMemories))
+	var longTotal int
+	for i, m := range p.RelevantMemories {
+		memTokens[i] = count(m.Content)
+		longTotal += memTokens[i]
+	}
+
+	t := ContextTruncation{MaxTokens: maxTokens}
+	for shortTotal+longTotal > maxTokens && len(p.RecentMessages)+len(p.RelevantMemories) > 0 {
+		if len(p.RelevantMemories) == 0 || (len(p.RecentMessages) > 0 && shortTotal >= longTotal) {
+			shortTotal -= msgTokens[0]
+			msgTokens = msgTokens[1:]
+			p.RecentMessages = p.RecentMessages[1:]
+			t.DroppedMessages++
+			continue
+		}
+		lowest := 0
+		for i, m := range p.RelevantMemories {
+			if m.Similarity < p.RelevantMemories[lowest].Similarity {
+				lowest = i
+			}
+		}
+		longTotal -= memTokens[lowest]
+		memTokens = append(memTokens[:lowest], memTokens[lowest+1:]...)
+		p.RelevantMemories = append(p.RelevantMemories[:lowest], p.RelevantMemories[lowest+1:]...)
+		t.DroppedMemories++
+	}
+
+	if t.DroppedMessages == 0 && t.DroppedMemories == 0 {
+		return nil
+	}
+	t.EstimatedTokens = shortTotal + longTotal
+	return &t
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("hi"))
+	assert.Equal(t, 3, EstimateTokens("hello, world"))
+	assert.Equal(t, 1, EstimateTokens("日本語"), "counts characters, not bytes")
+}
+
+// words counts one token per word, to keep budgets easy to read.
+func words(text string) int { return len(strings.Fields(text)) }
+
+func TestFitBudget(t *testing.T) {
+	p := &ContextPayload{
+		RecentMessages: []ConversationEntry{
+			{Content: "one two three four"},
+			{Content: "five six"},
+			{Content: "seven"},
+		},
+		RelevantMemories: []RelevantMemory{
+			{Content: "a b c", Similarity: 0.9},
+			{Content: "d e", Similarity: 0.7},
+			{Content: "f", Similarity: 0.8},
+		},
+	}
+
+	trunc := fitBudget(p, 6, words)
+	require.NotNil(t, trunc)
+	// From 7+6 tokens: the oldest message goes (7 ≥ 6), then the memories
+	// at 0.7 and 0.8, as the messages' 3 tokens are fewer than theirs.
+	assert.Equal(t, []ConversationEntry{{Content: "five six"}, {Content: "seven"}}, p.RecentMessages)
+	assert.Equal(t, []RelevantMemory{{Content: "a b c", Similarity: 0.9}}, p.RelevantMemories)
+	assert.Equal(t, ContextTruncation{MaxTokens: 6, EstimatedTokens: 6, DroppedMessages: 1, DroppedMemories: 2}, *trunc)
+
+	assert.Nil(t, fitBudget(p, 6, words), "a payload within budget is left alone")
+}
+
+func TestGetConversationContext_MaxContextTokens(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	svc := NewService(&typedRepo{results: typedMemories()}, store)
+	svc.SetTokenCounter(words)
+	ctx := context.Background()
+	agentID := uuid.New()
+
+	for _, content := range []string{"old turn here", "newer turn", "latest"} {
+		require.NoError(t, store.AppendMessage(ctx, agentID, "user@example.com", ConversationEntry{Role: "user", Content: content}, 20, 3600))
+	}
+
+	cfg := dedupConfig()
+	payload, err := svc.GetConversationContext(ctx, agentID, uuid.New(), "user@example.com", cfg, []float32{1, 0})
+	require.NoError(t, err)
+	assert.Nil(t, payload.Truncation, "no budget by default")
+
+	cfg.MaxContextTokens = 4
+	payload, err = svc.GetConversationContext(ctx, agentID, uuid.New(), "user@example.com", cfg, []float32{1, 0})
+	require.NoError(t, err)
+	require.NotNil(t, payload.Truncation)
+	assert.LessOrEqual(t, payload.Truncation.EstimatedTokens, 4)
+	assert.Equal(t, "latest", payload.RecentMessages[len(payload.RecentMessages)-1].Content, "the newest turns are kept")
+	assert.Equal(t, 2, payload.Truncation.DroppedMessages)
+}
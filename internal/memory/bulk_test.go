@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchRepo fails every memory whose content is "bad". Without partial the
+// first failure rolls back the batch; with partial the other rows commit.
+type batchRepo struct {
+	Repository
+	committed []*Memory
+	calls     int
+}
+
+func (r *batchRepo) CreateBatch(_ context.Context, mems []*Memory, partial bool) (map[int]error, error) {
+	r.calls++
+	failed := make(map[int]error)
+	var kept []*Memory
+	for i, m := range mems {
+		if m.Content != "bad" {
+			kept = append(kept, m)
+			continue
+		}
+		failed[i] = &pgconn.PgError{Message: "expected 384 dimensions, not 3"}
+		if !partial {
+			return failed, nil
+		}
+	}
+	r.committed = append(r.committed, kept...)
+	return failed, nil
+}
+
+func bulkRequests(contents ...string) []CreateMemoryRequest {
+	reqs := make([]CreateMemoryRequest, len(contents))
+	for i, c := range contents {
+		reqs[i] = CreateMemoryRequest{Content: c, MemoryType: "fact"}
+	}
+	return reqs
+}
+
+func TestBulkCreate_AllOrNothing(t *testing.T) {
+	repo := &batchRepo{}
+	svc := NewService(repo, nil)
+
+	res, err := svc.BulkCreate(context.Background(), uuid.New(), uuid.New(), bulkRequests("a", "bad", "c"), nil, false, DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Created)
+	assert.Equal(t, 3, res.Failed)
+	assert.Equal(t, "expected 384 dimensions, not 3", res.Results[1].Error)
+	assert.Equal(t, "not inserted: batch rolled back", res.Results[0].Error)
+	assert.Empty(t, repo.committed)
+}
+
+func TestBulkCreate_RejectedSkipsInsert(t *testing.T) {
+	repo := &batchRepo{}
+	svc := NewService(repo, nil)
+
+	rejected := map[int]error{0: errors.New("content is required")}
+	res, err := svc.BulkCreate(context.Background(), uuid.New(), uuid.New(), bulkRequests("", "b"), rejected, false, DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 0, res.Created)
+	assert.Equal(t, 0, repo.calls)
+}
+
+func TestBulkCreate_Partial(t *testing.T) {
+	repo := &batchRepo{}
+	svc := NewService(repo, nil)
+
+	rejected := map[int]error{3: errors.New("content is required")}
+	res, err := svc.BulkCreate(context.Background(), uuid.New(), uuid.New(), bulkRequests("a", "bad", "c", ""), rejected, true, DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Created)
+	assert.Equal(t, 2, res.Failed)
+	require.NotNil(t, res.Results[0].ID)
+	require.NotNil(t, res.Results[2].ID)
+	assert.Nil(t, res.Results[1].ID)
+	assert.Equal(t, "content is required", res.Results[3].Error)
+	assert.Len(t, repo.committed, 2)
+	assert.Equal(t, *res.Results[2].ID, repo.committed[1].ID)
+}
+
+func TestBulkCreate_PartialMultipleFailures(t *testing.T) {
+	repo := &batchRepo{}
+	svc := NewService(repo, nil)
+
+	res, err := svc.BulkCreate(context.Background(), uuid.New(), uuid.New(), bulkRequests("a", "bad", "c", "bad", "e"), nil, true, DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Created)
+	assert.Equal(t, 2, res.Failed)
+	assert.Equal(t, 1, repo.calls)
+	for _, i := range []int{1, 3} {
+		assert.Nil(t, res.Results[i].ID)
+		assert.Equal(t, "expected 384 dimensions, not 3", res.Results[i].Error)
+	}
+	require.Len(t, repo.committed, 3)
+	for n, i := range []int{0, 2, 4} {
+		require.NotNil(t, res.Results[i].ID)
+		assert.Equal(t, *res.Results[i].ID, repo.committed[n].ID)
+	}
+}
@@ -1,6 +1,10 @@
 package memory
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+)
 
 // MemoryConfig holds agent-level memory settings parsed from agents.memory_config JSONB.
 type MemoryConfig struct {
@@ -11,6 +15,57 @@ type MemoryConfig struct {
 	ShortTermTTLSec     int     `json:"short_term_ttl_sec"`
 	MaxLongTermResults  int     `json:"max_long_term_results"`
 	SimilarityThreshold float64 `json:"similarity_threshold"`
+	// DedupThreshold is the similarity at or above which a new long-term
+	// memory is treated as a duplicate of an existing one. Zero disables dedup.
+	DedupThreshold float64 `json:"dedup_threshold"`
+	// AutoSummarize promotes old short-term turns to a long-term summary once
+	// more than SummarizeThreshold messages are buffered, summarizing the
+	// oldest SummarizeBatch of them. The threshold must stay below
+	// MaxShortTermMsgs or the buffer is trimmed before it is ever reached.
+	AutoSummarize      bool `json:"auto_summarize"`
+	SummarizeThreshold int  `json:"summarize_threshold"`
+	SummarizeBatch     int  `json:"summarize_batch"`
+	// MaxLongTermMemories caps the agent's live long-term memories and
+	// LongTermTTLDays expires them; the prune job enforces both. Zero disables each.
+	MaxLongTermMemories int `json:"max_long_term_memories"`
+	LongTermTTLDays     int `json:"long_term_ttl_days"`
+	// EmbeddingModel and EmbeddingDim name the space the agent's embeddings
+	// live in. Memories are stamped with them and only searched within them.
+	EmbeddingModel string `json:"embedding_model"`
+	EmbeddingDim   int    `json:"embedding_dim"`
+	// Rerank boosts long-term results by recency and access frequency, both
+	// for conversation context and for searches that don't set their own.
+	Rerank RerankWeights `json:"rerank"`
+	// ContextMemoryTypes limits the long-term memories injected into
+	// conversation context to these memory_types; empty includes every type.
+	// MaxResultsPerType caps how many of a type are injected, within
+	// MaxLongTermResults overall.
+	ContextMemoryTypes []string       `json:"context_memory_types"`
+	MaxResultsPerType  map[string]int `json:"max_results_per_type"`
+	// MaxContextTokens caps the estimated size of the short-term messages and
+	// long-term memories injected into conversation context. Zero disables it.
+	MaxContextTokens int `json:"max_context_tokens"`
+}
+
+// Default embedding space, matching the Python worker's sentence-transformers model.
+const (
+	DefaultEmbeddingModel = "sentence-transformers/all-MiniLM-L6-v2"
+	DefaultEmbeddingDim   = 384
+	// MaxEmbeddingDim is the largest dimension pgvector can store.
+	MaxEmbeddingDim = 16000
+)
+
+// EmbeddingSpace returns the model and dimension embeddings are compared in.
+func (c MemoryConfig) EmbeddingSpace() EmbeddingSpace {
+	return EmbeddingSpace{Model: c.EmbeddingModel, Dim: c.EmbeddingDim}
+}
+
+// checkEmbedding rejects a non-empty embedding whose length isn't EmbeddingDim.
+func (c MemoryConfig) checkEmbedding(embedding []float32) error {
+	if len(embedding) > 0 && len(embedding) != c.EmbeddingDim {
+		return &EmbeddingDimensionError{Got: len(embedding), Want: c.EmbeddingDim}
+	}
+	return nil
 }
 
 // DefaultConfig returns a MemoryConfig with sensible defaults.
@@ -23,6 +78,12 @@ func DefaultConfig() MemoryConfig {
 		ShortTermTTLSec:     3600,
 		MaxLongTermResults:  5,
 		SimilarityThreshold: 0.7,
+		DedupThreshold:      0.95,
+		AutoSummarize:       false,
+		SummarizeThreshold:  16,
+		SummarizeBatch:      10,
+		EmbeddingModel:      DefaultEmbeddingModel,
+		EmbeddingDim:        DefaultEmbeddingDim,
 	}
 }
 
@@ -45,5 +106,15 @@ func ParseConfig(data []byte) MemoryConfig {
 
 	// Unmarshal over defaults so only provided fields are overwritten
 	_ = json.Unmarshal(data, &cfg)
+	if cfg.EmbeddingModel == "" {
+		cfg.EmbeddingModel = DefaultEmbeddingModel
+	}
+	if cfg.EmbeddingDim <= 0 || cfg.EmbeddingDim > MaxEmbeddingDim {
+		cfg.EmbeddingDim = DefaultEmbeddingDim
+	}
+	cfg.Rerank = cfg.Rerank.sanitize()
+	cfg.ContextMemoryTypes = slices.DeleteFunc(cfg.ContextMemoryTypes, func(t string) bool { return t == "" })
+	maps.DeleteFunc(cfg.MaxResultsPerType, func(_ string, n int) bool { return n < 0 })
+	cfg.MaxContextTokens = max(cfg.MaxContextTokens, 0)
 	return cfg
 }
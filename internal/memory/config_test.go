@@ -15,6 +15,20 @@ func TestParseConfig_Nil(t *testing.T) {
 	assert.Equal(t, 3600, cfg.ShortTermTTLSec)
 	assert.Equal(t, 5, cfg.MaxLongTermResults)
 	assert.Equal(t, 0.7, cfg.SimilarityThreshold)
+	assert.Equal(t, 0.95, cfg.DedupThreshold)
+	assert.False(t, cfg.AutoSummarize)
+	assert.Equal(t, 16, cfg.SummarizeThreshold)
+	assert.Equal(t, 10, cfg.SummarizeBatch)
+	assert.Equal(t, DefaultEmbeddingModel, cfg.EmbeddingModel)
+	assert.Equal(t, 384, cfg.EmbeddingDim)
+}
+
+func TestParseConfig_EmbeddingSpace(t *testing.T) {
+	cfg := ParseConfig([]byte(`{"embedding_model": "text-embedding-3-small", "embedding_dim": 1536}`))
+	assert.Equal(t, EmbeddingSpace{Model: "text-embedding-3-small", Dim: 1536}, cfg.EmbeddingSpace())
+
+	cfg = ParseConfig([]byte(`{"embedding_model": "", "embedding_dim": -1}`))
+	assert.Equal(t, EmbeddingSpace{Model: DefaultEmbeddingModel, Dim: DefaultEmbeddingDim}, cfg.EmbeddingSpace())
 }
 
 func TestParseConfig_Empty(t *testing.T) {
@@ -72,3 +86,17 @@ func TestParseConfig_DisabledExplicitly(t *testing.T) {
 	assert.False(t, cfg.ShortTermEnabled)
 	assert.False(t, cfg.LongTermEnabled)
 }
+
+func TestParseConfig_Rerank(t *testing.T) {
+	cfg := ParseConfig([]byte(`{"rerank": {"recency_weight": 0.2, "frequency_weight": 0.1, "recency_half_life_days": 7}}`))
+	assert.Equal(t, RerankWeights{RecencyWeight: 0.2, FrequencyWeight: 0.1, RecencyHalfLifeDays: 7}, cfg.Rerank)
+
+	cfg = ParseConfig([]byte(`{"rerank": {"recency_weight": 3, "frequency_weight": -1}}`))
+	assert.False(t, cfg.Rerank.Enabled())
+}
+
+func TestParseConfig_ContextMemoryTypes(t *testing.T) {
+	cfg := ParseConfig([]byte(`{"context_memory_types": ["fact", "", "preference"], "max_results_per_type": {"summary": 1, "fact": -2}}`))
+	assert.Equal(t, []string{"fact", "preference"}, cfg.ContextMemoryTypes)
+	assert.Equal(t, map[string]int{"summary": 1}, cfg.MaxResultsPerType)
+}
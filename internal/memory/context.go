@@ -13,6 +13,8 @@ type ConversationEntry struct {
 type ContextPayload struct {
 	RecentMessages   []ConversationEntry `json:"recent_messages"`
 	RelevantMemories []RelevantMemory    `json:"relevant_memories"`
+	// Truncation is set when entries were dropped to fit MaxContextTokens.
+	Truncation *ContextTruncation `json:"truncation,omitempty"`
 }
 
 // RelevantMemory is a long-term memory returned from pgvector similarity search.
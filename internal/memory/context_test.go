@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// typedRepo returns its memories, most similar first, filtered by type as
+// the SQL query does.
+type typedRepo struct {
+	Repository
+	results []SearchResult
+	types   []string
+}
+
+func (r *typedRepo) SearchSimilar(_ context.Context, _, _ uuid.UUID, _ EmbeddingSpace, _ []float32, limit int, _ float64, _ MetadataFilter, memoryTypes ...string) ([]SearchResult, error) {
+	r.types = memoryTypes
+	var out []SearchResult
+	for _, res := range r.results {
+		if len(memoryTypes) == 0 || slices.Contains(memoryTypes, res.Memory.MemoryType) {
+			out = append(out, res)
+		}
+	}
+	return out[:min(limit, len(out))], nil
+}
+
+func typedMemories() []SearchResult {
+	var out []SearchResult
+	for i, typ := range []string{"summary", "summary", "fact", "preference", "fact", "fact", "summary", "preference"} {
+		out = append(out, SearchResult{Memory: Memory{ID: uuid.New(), Content: typ, MemoryType: typ}, Similarity: 0.99 - float64(i)/100})
+	}
+	return out
+}
+
+func contextTypes(p *ContextPayload) []string {
+	var types []string
+	for _, m := range p.RelevantMemories {
+		types = append(types, m.MemoryType)
+	}
+	return types
+}
+
+func TestGetConversationContext_MemoryTypes(t *testing.T) {
+	repo := &typedRepo{results: typedMemories()}
+	svc := NewService(repo, nil)
+	cfg := dedupConfig()
+	cfg.ContextMemoryTypes = []string{"preference", "fact"}
+
+	payload, err := svc.GetConversationContext(context.Background(), uuid.New(), uuid.New(), "user@example.com", cfg, []float32{1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"preference", "fact"}, repo.types)
+	assert.Equal(t, []string{"fact", "preference", "fact", "fact", "preference"}, contextTypes(payload))
+	assert.NotContains(t, contextTypes(payload), "summary")
+}
+
+func TestGetConversationContext_MaxResultsPerType(t *testing.T) {
+	repo := &typedRepo{results: typedMemories()}
+	svc := NewService(repo, nil)
+	cfg := dedupConfig()
+	cfg.MaxLongTermResults = 4
+	cfg.MaxResultsPerType = map[string]int{"summary": 1, "fact": 2}
+
+	payload, err := svc.GetConversationContext(context.Background(), uuid.New(), uuid.New(), "user@example.com", cfg, []float32{1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"summary", "fact", "preference", "fact"}, contextTypes(payload))
+
+	cfg.MaxResultsPerType = map[string]int{"summary": 0}
+	payload, err = svc.GetConversationContext(context.Background(), uuid.New(), uuid.New(), "user@example.com", cfg, []float32{1, 0})
+	require.NoError(t, err)
+	assert.NotContains(t, contextTypes(payload), "summary")
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dedupRepo returns match from SearchSimilar when the threshold allows it.
+type dedupRepo struct {
+	Repository
+	match      *SearchResult
+	created    []*Memory
+	merged     []uuid.UUID
+	mergedMeta json.RawMessage
+}
+
+func (r *dedupRepo) SearchSimilar(_ context.Context, _, _ uuid.UUID, _ EmbeddingSpace, _ []float32, _ int, threshold float64, _ MetadataFilter, _ ...string) ([]SearchResult, error) {
+	if r.match == nil || r.match.Similarity < threshold {
+		return nil, nil
+	}
+	return []SearchResult{*r.match}, nil
+}
+
+func (r *dedupRepo) Create(_ context.Context, mem *Memory) error {
+	r.created = append(r.created, mem)
+	return nil
+}
+
+func (r *dedupRepo) MergeDuplicate(_ context.Context, id, _ uuid.UUID, metadata json.RawMessage) error {
+	r.merged = append(r.merged, id)
+	r.mergedMeta = metadata
+	return nil
+}
+
+// dedupConfig matches the two-dimensional embeddings used in these tests.
+func dedupConfig() MemoryConfig {
+	cfg := DefaultConfig()
+	cfg.EmbeddingDim = 2
+	return cfg
+}
+
+func TestStoreLongTermMemory_Dedup(t *testing.T) {
+	existing := uuid.New()
+	repo := &dedupRepo{match: &SearchResult{Memory: Memory{ID: existing}, Similarity: 0.97}}
+	svc := NewService(repo, nil)
+	mem := &Memory{Content: "likes tea", Embedding: []float32{1, 0}, Metadata: json.RawMessage(`{"source":"chat"}`)}
+
+	created, err := svc.StoreLongTermMemory(context.Background(), mem, dedupConfig())
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, []uuid.UUID{existing}, repo.merged)
+	assert.JSONEq(t, `{"source":"chat"}`, string(repo.mergedMeta))
+	assert.Empty(t, repo.created)
+}
+
+func TestStoreLongTermMemory_BelowThresholdCreates(t *testing.T) {
+	repo := &dedupRepo{match: &SearchResult{Memory: Memory{ID: uuid.New()}, Similarity: 0.9}}
+	svc := NewService(repo, nil)
+
+	created, err := svc.StoreLongTermMemory(context.Background(), &Memory{Embedding: []float32{1, 0}}, dedupConfig())
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Len(t, repo.created, 1)
+	assert.Empty(t, repo.merged)
+}
+
+func TestStoreLongTermMemory_DedupDisabled(t *testing.T) {
+	repo := &dedupRepo{match: &SearchResult{Memory: Memory{ID: uuid.New()}, Similarity: 1}}
+	svc := NewService(repo, nil)
+	cfg := dedupConfig()
+	cfg.DedupThreshold = 0
+
+	created, err := svc.StoreLongTermMemory(context.Background(), &Memory{Embedding: []float32{1, 0}}, cfg)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Empty(t, repo.merged)
+}
+
+func TestStoreLongTermMemory_StampsEmbeddingSpace(t *testing.T) {
+	repo := &dedupRepo{}
+	svc := NewService(repo, nil)
+
+	_, err := svc.StoreLongTermMemory(context.Background(), &Memory{Embedding: []float32{1, 0}}, dedupConfig())
+	require.NoError(t, err)
+	require.Len(t, repo.created, 1)
+	assert.Equal(t, DefaultEmbeddingModel, repo.created[0].EmbeddingModel)
+	assert.Equal(t, 2, repo.created[0].EmbeddingDim)
+}
+
+func TestStoreLongTermMemory_WrongDimension(t *testing.T) {
+	repo := &dedupRepo{}
+	svc := NewService(repo, nil)
+
+	_, err := svc.StoreLongTermMemory(context.Background(), &Memory{Embedding: []float32{1, 0, 0}}, dedupConfig())
+	var dimErr *EmbeddingDimensionError
+	require.ErrorAs(t, err, &dimErr)
+	assert.Equal(t, 3, dimErr.Got)
+	assert.Equal(t, 2, dimErr.Want)
+	assert.Empty(t, repo.created)
+}
@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+// Embedder turns text into an embedding in the given model's space.
+type Embedder interface {
+	Embed(ctx context.Context, model, text string) ([]float32, error)
+}
+
+// HTTPEmbedder calls an OpenAI-compatible embeddings endpoint
+// (POST {"model", "input"} → {"data": [{"embedding": [...]}]}).
+type HTTPEmbedder struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPEmbedder creates an embedder for the endpoint at url. apiKey, when
+// set, is sent as a bearer token.
+func NewHTTPEmbedder(url, apiKey string, timeout time.Duration) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type embeddingRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed requests an embedding of text from the endpoint.
+func (e *HTTPEmbedder) Embed(ctx context.Context, model, text string) ([]float32, error) {
+	body, err := json.Marshal(embeddingRequest{Model: model, Input: text})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling embedding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling embedding endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("embedding endpoint returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var out embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decoding embedding response: %w", err)
+	}
+	if len(out.Data) == 0 || len(out.Data[0].Embedding) == 0 {
+		return nil, errors.New("embedding endpoint returned no embedding")
+	}
+	return out.Data[0].Embedding, nil
+}
+
+// CachedEmbedder wraps an Embedder with a Redis cache keyed by model and
+// text, shared across agents and senders, so repeated texts such as greetings
+// and retries are embedded once. Redis errors fall through to the wrapped
+// Embedder.
+type CachedEmbedder struct {
+	next   Embedder
+	client redis.Cmdable
+	ttl    time.Duration
+}
+
+// NewCachedEmbedder creates an embedder that caches next's embeddings for ttl.
+func NewCachedEmbedder(next Embedder, client redis.Cmdable, ttl time.Duration) *CachedEmbedder {
+	return &CachedEmbedder{next: next, client: client, ttl: ttl}
+}
+
+// embeddingCacheKey includes the model, so models never share entries.
+func embeddingCacheKey(model, text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return "embedding:" + model + ":" + hex.EncodeToString(sum[:])
+}
+
+// Embed returns the cached embedding of text, or embeds and caches it.
+func (e *CachedEmbedder) Embed(ctx context.Context, model, text string) ([]float32, error) {
+	key := embeddingCacheKey(model, text)
+	data, err := e.client.Get(ctx, key).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		slog.Warn("memory: reading embedding cache", "error", err)
+	}
+	if embedding := decodeEmbedding(data); embedding != nil {
+		metrics.EmbeddingCacheHitsTotal.Inc()
+		return embedding, nil
+	}
+	metrics.EmbeddingCacheMissesTotal.Inc()
+
+	embedding, err := e.next.Embed(ctx, model, text)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.client.Set(ctx, key, encodeEmbedding(embedding), e.ttl).Err(); err != nil {
+		slog.Warn("memory: writing embedding cache", "error", err)
+	}
+	return embedding, nil
+}
+
+// encodeEmbedding packs an embedding as little-endian float32s.
+func encodeEmbedding(embedding []float32) []byte {
+	data := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+// decodeEmbedding reverses encodeEmbedding, returning nil for anything that
+// isn't a packed embedding.
+func decodeEmbedding(data []byte) []float32 {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return nil
+	}
+	embedding := make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return embedding
+}
+
+// SetEmbedder enables server-side query embeddings. Without one,
+// QueryEmbedding returns nil and long-term retrieval is skipped.
+func (s *Service) SetEmbedder(embedder Embedder) {
+	s.embedder = embedder
+}
+
+// QueryEmbedding embeds an incoming message for long-term retrieval in the
+// agent's embedding space. The last embedding per agent+sender is cached in
+// Redis, so a repeated message is not re-embedded. It returns nil without
+// error when no embedder is configured or long-term memory is off.
+func (s *Service) QueryEmbedding(ctx context.Context, agentID uuid.UUID, userJID, text string, cfg MemoryConfig) ([]float32, error) {
+	if s.embedder == nil || !cfg.LongTermEnabled || text == "" {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256([]byte(cfg.EmbeddingModel + "\x00" + text))
+	key := hex.EncodeToString(sum[:])
+	if s.shortTerm != nil {
+		if cached, err := s.shortTerm.GetCachedEmbedding(ctx, agentID, userJID, key); err == nil && cached != nil {
+			return cached, nil
+		}
+	}
+
+	embedding, err := s.embedder.Embed(ctx, cfg.EmbeddingModel, text)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkEmbedding(embedding); err != nil {
+		return nil, err
+	}
+
+	if s.shortTerm != nil {
+		ttl := time.Duration(cfg.ShortTermTTLSec) * time.Second
+		if err := s.shortTerm.SetCachedEmbedding(ctx, agentID, userJID, key, embedding, ttl); err != nil {
+			return embedding, fmt.Errorf("caching query embedding: %w", err)
+		}
+	}
+	return embedding, nil
+}
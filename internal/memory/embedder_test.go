@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+func TestHTTPEmbedder_Embed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		var req embeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "tiny-model", req.Model)
+		assert.Equal(t, "hello", req.Input)
+		_, _ = w.Write([]byte(`{"data":[{"embedding":[0.5,0.25]}]}`))
+	}))
+	defer srv.Close()
+
+	emb, err := NewHTTPEmbedder(srv.URL, "secret", time.Second).Embed(context.Background(), "tiny-model", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.5, 0.25}, emb)
+}
+
+func TestHTTPEmbedder_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := NewHTTPEmbedder(srv.URL, "", time.Second).Embed(context.Background(), "missing", "hello")
+	assert.ErrorContains(t, err, "404: model not found")
+}
+
+// countingEmbedder returns a fixed embedding and counts calls.
+type countingEmbedder struct {
+	calls     int
+	embedding []float32
+}
+
+func (e *countingEmbedder) Embed(_ context.Context, _, _ string) ([]float32, error) {
+	e.calls++
+	return e.embedding, nil
+}
+
+func TestQueryEmbedding_CachesPerSender(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	svc := NewService(nil, store)
+	embedder := &countingEmbedder{embedding: []float32{1, 0}}
+	svc.SetEmbedder(embedder)
+	ctx := context.Background()
+	agentID := uuid.New()
+	cfg := dedupConfig()
+
+	for i := 0; i < 2; i++ {
+		emb, err := svc.QueryEmbedding(ctx, agentID, "alice@example.com", "hello", cfg)
+		require.NoError(t, err)
+		assert.Equal(t, []float32{1, 0}, emb)
+	}
+	assert.Equal(t, 1, embedder.calls)
+
+	_, err := svc.QueryEmbedding(ctx, agentID, "alice@example.com", "something else", cfg)
+	require.NoError(t, err)
+	_, err = svc.QueryEmbedding(ctx, agentID, "bob@example.com", "something else", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, embedder.calls)
+}
+
+func TestQueryEmbedding_WrongDimensionNotCached(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	svc := NewService(nil, store)
+	embedder := &countingEmbedder{embedding: []float32{1, 0, 0}}
+	svc.SetEmbedder(embedder)
+	agentID := uuid.New()
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.QueryEmbedding(context.Background(), agentID, "alice@example.com", "hello", dedupConfig())
+		var dimErr *EmbeddingDimensionError
+		assert.ErrorAs(t, err, &dimErr)
+	}
+	assert.Equal(t, 2, embedder.calls)
+}
+
+func TestQueryEmbedding_NoEmbedder(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	svc := NewService(nil, store)
+
+	emb, err := svc.QueryEmbedding(context.Background(), uuid.New(), "alice@example.com", "hello", DefaultConfig())
+	require.NoError(t, err)
+	assert.Nil(t, emb)
+}
+
+func TestCachedEmbedder(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	next := &countingEmbedder{embedding: []float32{0.25, -1, 3.5}}
+	e := NewCachedEmbedder(next, client, time.Hour)
+	ctx := context.Background()
+	hits, misses := testutil.ToFloat64(metrics.EmbeddingCacheHitsTotal), testutil.ToFloat64(metrics.EmbeddingCacheMissesTotal)
+
+	for i := 0; i < 2; i++ {
+		emb, err := e.Embed(ctx, "model-a", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, []float32{0.25, -1, 3.5}, emb)
+	}
+	assert.Equal(t, 1, next.calls)
+
+	_, err := e.Embed(ctx, "model-b", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.calls, "models never share cache entries")
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.EmbeddingCacheHitsTotal))
+	assert.Equal(t, misses+2, testutil.ToFloat64(metrics.EmbeddingCacheMissesTotal))
+	assert.Equal(t, time.Hour, mr.TTL(embeddingCacheKey("model-a", "hello")))
+
+	mr.SetError("connection lost")
+	emb, err := e.Embed(ctx, "model-a", "hello")
+	require.NoError(t, err, "Redis errors fall through to the embedder")
+	assert.Len(t, emb, 3)
+	assert.Equal(t, 3, next.calls)
+}
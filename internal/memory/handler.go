@@ -2,8 +2,11 @@ package memory
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
@@ -28,11 +31,12 @@ func NewHandler(svc *Service) *Handler {
 	}
 }
 
-// List returns paginated memories for an agent.
+// List returns paginated memories for an agent. Accepts ?metadata= with a
+// JSON object to return only memories whose metadata contains it.
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	agent := agents.GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
@@ -49,9 +53,23 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	memories, totalCount, err := h.svc.List(r.Context(), agent.ID, agent.OwnerUserID, page, pageSize)
+	var filter MetadataFilter
+	if raw := r.URL.Query().Get("metadata"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &filter); err != nil {
+			api.HandleError(w, api.NewBadRequestError("metadata must be a JSON object"))
+			return
+		}
+	}
+
+	// Cursor mode is selected by the presence of ?after=; an empty value starts from the newest memory.
+	if r.URL.Query().Has("after") {
+		h.listByCursor(w, r, agent.ID, agent.OwnerUserID, pageSize, filter)
+		return
+	}
+
+	memories, totalCount, err := h.svc.List(r.Context(), agent.ID, agent.OwnerUserID, page, pageSize, filter)
 	if err != nil {
-		slog.Error("listing memories", "error", err)
+		slog.ErrorContext(r.Context(), "listing memories", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -59,11 +77,32 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	api.JSONPaginated(w, http.StatusOK, memories, totalCount, page, pageSize)
 }
 
+func (h *Handler) listByCursor(w http.ResponseWriter, r *http.Request, agentID, ownerUserID uuid.UUID, pageSize int, filter MetadataFilter) {
+	var after *Cursor
+	if raw := r.URL.Query().Get("after"); raw != "" {
+		c, err := DecodeCursor(raw)
+		if err != nil {
+			api.HandleError(w, api.NewBadRequestError("invalid cursor"))
+			return
+		}
+		after = c
+	}
+
+	memories, next, err := h.svc.ListAfter(r.Context(), agentID, ownerUserID, after, pageSize, filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing memories by cursor", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONCursor(w, http.StatusOK, memories, next, pageSize)
+}
+
 // Create creates a new memory for an agent.
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	agent := agents.GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
@@ -78,9 +117,14 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mem, err := h.svc.Create(r.Context(), agent.ID, agent.OwnerUserID, &req)
+	mem, err := h.svc.Create(r.Context(), agent.ID, agent.OwnerUserID, &req, ParseConfig(agent.MemoryConfig))
 	if err != nil {
-		slog.Error("creating memory", "error", err)
+		var dimErr *EmbeddingDimensionError
+		if errors.As(err, &dimErr) {
+			api.HandleError(w, api.NewBadRequestError(dimErr.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "creating memory", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -88,11 +132,63 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	api.JSON(w, http.StatusCreated, mem)
 }
 
+// BulkCreate imports up to MaxBulkMemories memories in one transaction.
+// With ?partial=true, valid items are committed even if others fail.
+func (h *Handler) BulkCreate(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	var reqs []CreateMemoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+	if len(reqs) == 0 {
+		api.HandleError(w, api.NewValidationError("at least one memory is required"))
+		return
+	}
+	if len(reqs) > MaxBulkMemories {
+		api.HandleError(w, api.NewValidationError(fmt.Sprintf("bulk import is limited to %d memories", MaxBulkMemories)))
+		return
+	}
+
+	cfg := ParseConfig(agent.MemoryConfig)
+	rejected := make(map[int]error)
+	for i := range reqs {
+		if err := h.validate.Struct(reqs[i]); err != nil {
+			rejected[i] = err
+		} else if err := cfg.checkEmbedding(reqs[i].Embedding); err != nil {
+			rejected[i] = err
+		}
+	}
+
+	partial := r.URL.Query().Get("partial") == "true"
+
+	result, err := h.svc.BulkCreate(r.Context(), agent.ID, agent.OwnerUserID, reqs, rejected, partial, cfg)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "bulk creating memories", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case result.Created == 0:
+		status = http.StatusUnprocessableEntity
+	case result.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	api.JSON(w, status, result)
+}
+
 // Search performs a similarity search on agent memories.
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	agent := agents.GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
@@ -107,9 +203,14 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	results, err := h.svc.Search(r.Context(), agent.ID, agent.OwnerUserID, &req)
+	results, err := h.svc.Search(r.Context(), agent.ID, agent.OwnerUserID, &req, ParseConfig(agent.MemoryConfig))
 	if err != nil {
-		slog.Error("searching memories", "error", err)
+		var dimErr *EmbeddingDimensionError
+		if errors.As(err, &dimErr) {
+			api.HandleError(w, api.NewBadRequestError(dimErr.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "searching memories", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -121,7 +222,7 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	agent := agents.GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
@@ -137,7 +238,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 			api.HandleError(w, api.NewNotFoundError("memory not found"))
 			return
 		}
-		slog.Error("deleting memory", "error", err)
+		slog.ErrorContext(r.Context(), "deleting memory", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
@@ -145,19 +246,120 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	api.JSONMessage(w, http.StatusOK, "memory deleted successfully")
 }
 
+// Restore restores a soft-deleted memory.
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	memoryIDStr := chi.URLParam(r, "memoryID")
+	memoryID, err := uuid.Parse(memoryIDStr)
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid memory ID"))
+		return
+	}
+
+	if err := h.svc.Restore(r.Context(), memoryID, agent.OwnerUserID); err != nil {
+		if err.Error() == "memory not found" {
+			api.HandleError(w, api.NewNotFoundError("memory not found"))
+			return
+		}
+		slog.ErrorContext(r.Context(), "restoring memory", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "memory restored successfully")
+}
+
 // DeleteAll deletes all memories for an agent.
 func (h *Handler) DeleteAll(w http.ResponseWriter, r *http.Request) {
 	agent := agents.GetAgentFromContext(r.Context())
 	if agent == nil {
-		api.HandleError(w, api.ErrNotFound)
+		api.HandleError(w, api.ErrAgentNotFound)
 		return
 	}
 
 	if err := h.svc.DeleteByAgent(r.Context(), agent.ID, agent.OwnerUserID); err != nil {
-		slog.Error("deleting all memories", "error", err)
+		slog.ErrorContext(r.Context(), "deleting all memories", "error", err)
 		api.HandleError(w, api.ErrInternalServer)
 		return
 	}
 
 	api.JSONMessage(w, http.StatusOK, "all memories deleted successfully")
 }
+
+// ListConversations returns the agent's conversations held in short-term
+// memory.
+func (h *Handler) ListConversations(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	conversations, err := h.svc.ListConversations(r.Context(), agent.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing conversations", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, conversations)
+}
+
+// GetConversation returns the short-term window of one conversation.
+func (h *Handler) GetConversation(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+	userJID, ok := userJIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	messages, err := h.svc.GetConversation(r.Context(), agent.ID, userJID, ParseConfig(agent.MemoryConfig))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "getting conversation", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, messages)
+}
+
+// ClearConversation deletes one conversation's short-term memory.
+func (h *Handler) ClearConversation(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+	userJID, ok := userJIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.ClearConversation(r.Context(), agent.ID, userJID); err != nil {
+		slog.ErrorContext(r.Context(), "clearing conversation", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "conversation cleared")
+}
+
+// userJIDParam reads the {userJID} path segment. A full JID's resource
+// separator must be sent percent-encoded as %2F.
+func userJIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userJID, err := url.PathUnescape(chi.URLParam(r, "userJID"))
+	if err != nil || userJID == "" {
+		api.HandleError(w, api.NewBadRequestError("invalid user JID"))
+		return "", false
+	}
+	return userJID, true
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hybridRepo records which search ran and with what weighting, returning
+// fixed fused scores from SearchHybrid.
+type hybridRepo struct {
+	Repository
+	results   []SearchResult
+	mode      string
+	query     string
+	alpha     float64
+	threshold float64
+}
+
+func (r *hybridRepo) SearchSimilar(_ context.Context, _, _ uuid.UUID, _ EmbeddingSpace, _ []float32, _ int, threshold float64, _ MetadataFilter, _ ...string) ([]SearchResult, error) {
+	r.mode, r.threshold = "vector", threshold
+	return nil, nil
+}
+
+func (r *hybridRepo) SearchHybrid(_ context.Context, _, _ uuid.UUID, _ EmbeddingSpace, _ []float32, query string, alpha float64, _ int, threshold float64, _ MetadataFilter) ([]SearchResult, error) {
+	r.mode, r.query, r.alpha, r.threshold = "hybrid", query, alpha, threshold
+	return r.results, nil
+}
+
+func TestSearch_HybridModeSwitch(t *testing.T) {
+	tests := []struct {
+		name string
+		req  SearchMemoryRequest
+		mode string
+	}{
+		{"default is vector", SearchMemoryRequest{}, "vector"},
+		{"explicit vector", SearchMemoryRequest{Mode: "vector", Query: "tea"}, "vector"},
+		{"hybrid without a query falls back to vector", SearchMemoryRequest{Mode: "hybrid"}, "vector"},
+		{"hybrid with a query", SearchMemoryRequest{Mode: "hybrid", Query: "tea"}, "hybrid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &hybridRepo{}
+			req := tt.req
+			req.Embedding = []float32{1, 0}
+
+			_, err := NewService(repo, nil).Search(context.Background(), uuid.New(), uuid.New(), &req, dedupConfig())
+			require.NoError(t, err)
+			assert.Equal(t, tt.mode, repo.mode)
+		})
+	}
+}
+
+func TestSearch_HybridWeighting(t *testing.T) {
+	zero, half := 0.0, 0.5
+	tests := []struct {
+		name      string
+		alpha     *float64
+		threshold float64
+		wantAlpha float64
+	}{
+		{"default alpha", nil, 0, DefaultHybridAlpha},
+		{"text only", &zero, 0, 0},
+		{"explicit alpha and threshold", &half, 0.2, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &hybridRepo{}
+			req := &SearchMemoryRequest{Embedding: []float32{1, 0}, Mode: "hybrid", Query: "green tea", Alpha: tt.alpha, Threshold: tt.threshold}
+
+			_, err := NewService(repo, nil).Search(context.Background(), uuid.New(), uuid.New(), req, dedupConfig())
+			require.NoError(t, err)
+			assert.Equal(t, "green tea", repo.query)
+			assert.Equal(t, tt.wantAlpha, repo.alpha)
+			// Fused scores don't share the vector scale, so no default threshold applies.
+			assert.Equal(t, tt.threshold, repo.threshold)
+		})
+	}
+}
+
+func TestSearch_HybridScoresAreReranked(t *testing.T) {
+	now := time.Now()
+	old := SearchResult{Memory: Memory{ID: uuid.New(), CreatedAt: now.AddDate(0, 0, -365)}, Similarity: 0.6}
+	recent := SearchResult{Memory: Memory{ID: uuid.New(), CreatedAt: now}, Similarity: 0.5}
+
+	t.Run("fused score kept without weights", func(t *testing.T) {
+		repo := &hybridRepo{results: []SearchResult{old, recent}}
+		req := &SearchMemoryRequest{Embedding: []float32{1, 0}, Mode: "hybrid", Query: "tea"}
+
+		results, err := NewService(repo, nil).Search(context.Background(), uuid.New(), uuid.New(), req, dedupConfig())
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, old.Memory.ID, results[0].Memory.ID)
+		assert.Equal(t, 0.6, results[0].Score)
+		assert.Equal(t, 0.5, results[1].Score)
+	})
+
+	t.Run("recency boost applies on top of the fused score", func(t *testing.T) {
+		repo := &hybridRepo{results: []SearchResult{old, recent}}
+		req := &SearchMemoryRequest{Embedding: []float32{1, 0}, Mode: "hybrid", Query: "tea", Rerank: &RerankWeights{RecencyWeight: 0.5}}
+
+		results, err := NewService(repo, nil).Search(context.Background(), uuid.New(), uuid.New(), req, dedupConfig())
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, recent.Memory.ID, results[0].Memory.ID)
+		assert.Equal(t, 0.5, results[0].Similarity, "the fused score is kept as the similarity")
+	})
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,7 +17,43 @@ type Memory struct {
 	Embedding   []float32       `json:"embedding,omitempty"`
 	MemoryType  string          `json:"memory_type"`
 	Metadata    json.RawMessage `json:"metadata"`
-	CreatedAt   time.Time       `json:"created_at"`
+	// EmbeddingModel and EmbeddingDim record the space Embedding was produced
+	// in; both are empty for memories stored without an embedding.
+	EmbeddingModel string `json:"embedding_model,omitempty"`
+	EmbeddingDim   int    `json:"embedding_dim,omitempty"`
+	// AccessCount counts near-duplicate writes merged into this memory and
+	// searches that returned it; LastAccessedAt is the latest such search.
+	AccessCount    int        `json:"access_count"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// EmbeddingSpace identifies the model and dimension an embedding was produced
+// in. Similarity is only meaningful between embeddings of the same space.
+type EmbeddingSpace struct {
+	Model string
+	Dim   int
+}
+
+// EmbeddingDimensionError reports an embedding whose length doesn't match the
+// agent's configured embedding_dim.
+type EmbeddingDimensionError struct {
+	Got  int
+	Want int
+}
+
+func (e *EmbeddingDimensionError) Error() string {
+	return fmt.Sprintf("embedding has %d dimensions, but the agent's embedding_dim is %d", e.Got, e.Want)
+}
+
+// stamp records the embedding space of mem, or clears it when there is no embedding.
+func (mem *Memory) stamp(space EmbeddingSpace) {
+	if len(mem.Embedding) == 0 {
+		mem.EmbeddingModel, mem.EmbeddingDim = "", 0
+		return
+	}
+	mem.EmbeddingModel, mem.EmbeddingDim = space.Model, space.Dim
 }
 
 // CreateMemoryRequest is used by the API to create a new memory.
@@ -27,15 +64,91 @@ type CreateMemoryRequest struct {
 	Metadata   json.RawMessage `json:"metadata,omitempty"`
 }
 
+// Soft-deleted memories can be restored until they are purged.
+const (
+	DeletedMemoryRetention = 30 * 24 * time.Hour
+	PurgeInterval          = time.Hour
+)
+
+// Long-term TTL and cap enforcement runs every PruneInterval, soft-deleting at
+// most PruneBatchSize rows per statement to keep lock times short.
+const (
+	PruneInterval  = time.Hour
+	PruneBatchSize = 500
+)
+
+// PruneTarget is an agent whose memory_config limits its long-term memories.
+type PruneTarget struct {
+	AgentID     uuid.UUID
+	OwnerUserID uuid.UUID
+	Config      MemoryConfig
+}
+
+// Search hits are buffered and written to access_count and last_accessed_at
+// every AccessFlushInterval, at most AccessFlushBatchSize memories per statement.
+const (
+	AccessFlushInterval  = 30 * time.Second
+	AccessFlushBatchSize = 500
+)
+
+// MaxBulkMemories caps the number of items accepted by a single bulk import.
+const MaxBulkMemories = 500
+
+// BulkItemResult reports the outcome of one item in a bulk import.
+type BulkItemResult struct {
+	Index int        `json:"index"`
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// BulkCreateResult is the API response for a bulk memory import.
+type BulkCreateResult struct {
+	Created int              `json:"created"`
+	Failed  int              `json:"failed"`
+	Results []BulkItemResult `json:"results"`
+}
+
 // SearchMemoryRequest is used by the API to search memories by embedding similarity.
+// Mode "hybrid" with a Query fuses vector similarity with full-text rank,
+// weighted by Alpha (1 = vector only, 0 = text only).
 type SearchMemoryRequest struct {
 	Embedding []float32 `json:"embedding" validate:"required"`
 	Limit     int       `json:"limit,omitempty"`
 	Threshold float64   `json:"threshold,omitempty"`
+	Mode      string    `json:"mode,omitempty" validate:"omitempty,oneof=vector hybrid"`
+	Query     string    `json:"query,omitempty" validate:"required_if=Mode hybrid"`
+	Alpha     *float64  `json:"alpha,omitempty" validate:"omitempty,gte=0,lte=1"`
+	// MetadataFilter restricts results to memories whose metadata contains it.
+	MetadataFilter MetadataFilter `json:"metadata_filter,omitempty"`
+	// Rerank overrides the agent's memory_config.rerank weights.
+	Rerank *RerankWeights `json:"rerank,omitempty"`
+}
+
+// MetadataFilter matches memories whose metadata contains every key/value
+// pair, using jsonb containment (metadata @> filter). Nested objects and
+// arrays match the same way. An empty filter matches every memory.
+type MetadataFilter map[string]any
+
+// containment returns the filter as the JSON operand for @>. An empty filter
+// becomes {}, which every object contains.
+func (f MetadataFilter) containment() ([]byte, error) {
+	if len(f) == 0 {
+		return []byte(`{}`), nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, fmt.Errorf("encoding metadata filter: %w", err)
+	}
+	return b, nil
 }
 
-// SearchResult wraps a Memory with its similarity score.
+// DefaultHybridAlpha weights hybrid search towards vector similarity.
+const DefaultHybridAlpha = 0.7
+
+// SearchResult wraps a Memory with its similarity score. Score is the
+// similarity after reranking, and equals it when reranking is off.
 type SearchResult struct {
 	Memory     Memory  `json:"memory"`
 	Similarity float64 `json:"similarity"`
+	Score      float64 `json:"score"`
 }
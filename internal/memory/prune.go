@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// AuditPublisher publishes audit events. *inats.Publisher satisfies it.
+type AuditPublisher interface {
+	PublishAuditEvent(ctx context.Context, event inats.AuditEvent) error
+}
+
+// SetAuditPublisher enables audit events for pruned memories.
+func (s *Service) SetAuditPublisher(p AuditPublisher) {
+	s.publisher = p
+}
+
+// RunPrune enforces each agent's LongTermTTLDays and MaxLongTermMemories,
+// checking every PruneInterval until ctx is cancelled.
+func (s *Service) RunPrune(ctx context.Context) error {
+	ticker := time.NewTicker(PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.pruneAll(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("memory: pruning long-term memories", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// pruneAll walks every agent with limits configured, a page at a time.
+func (s *Service) pruneAll(ctx context.Context) error {
+	after := uuid.Nil
+	for {
+		targets, err := s.repo.ListPruneTargets(ctx, after, PruneBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, t := range targets {
+			if _, _, err := s.PruneAgent(ctx, t); err != nil {
+				slog.Error("memory: pruning agent memories", "error", err, "agent_id", t.AgentID)
+			}
+		}
+		if len(targets) < PruneBatchSize {
+			return nil
+		}
+		after = targets[len(targets)-1].AgentID
+	}
+}
+
+// PruneAgent soft-deletes the agent's memories older than its TTL, then, if it
+// is still over its cap, the least-reinforced oldest ones until it is back
+// under. Deletes run in batches of PruneBatchSize and are scoped to the
+// agent's owner. An audit event is published when anything was pruned.
+func (s *Service) PruneAgent(ctx context.Context, t PruneTarget) (expired, overCap int64, err error) {
+	if t.Config.LongTermTTLDays > 0 {
+		before := time.Now().AddDate(0, 0, -t.Config.LongTermTTLDays)
+		for {
+			n, err := s.repo.PruneExpired(ctx, t.AgentID, t.OwnerUserID, before, PruneBatchSize)
+			if err != nil {
+				return expired, overCap, err
+			}
+			expired += n
+			if n < PruneBatchSize {
+				break
+			}
+		}
+	}
+
+	if t.Config.MaxLongTermMemories > 0 {
+		count, err := s.repo.CountByAgent(ctx, t.AgentID, t.OwnerUserID, nil)
+		if err != nil {
+			return expired, overCap, err
+		}
+		for excess := count - int64(t.Config.MaxLongTermMemories); excess > 0; {
+			n, err := s.repo.PruneOldest(ctx, t.AgentID, t.OwnerUserID, int(min(excess, PruneBatchSize)))
+			if err != nil {
+				return expired, overCap, err
+			}
+			if n == 0 {
+				break
+			}
+			overCap += n
+			excess -= n
+		}
+	}
+
+	if expired+overCap > 0 {
+		s.publishPruned(ctx, t, expired, overCap)
+	}
+	return expired, overCap, nil
+}
+
+func (s *Service) publishPruned(ctx context.Context, t PruneTarget, expired, overCap int64) {
+	slog.Info("memory: pruned long-term memories", "agent_id", t.AgentID, "expired", expired, "over_cap", overCap)
+	if s.publisher == nil {
+		return
+	}
+	event := inats.AuditEvent{
+		OwnerUserID:  t.OwnerUserID,
+		EventType:    "memories_pruned",
+		Severity:     "info",
+		ResourceType: "agent",
+		ResourceID:   t.AgentID.String(),
+		Details:      fmt.Sprintf("Pruned %d expired and %d over-cap long-term memories", expired, overCap),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := s.publisher.PublishAuditEvent(ctx, event); err != nil {
+		slog.Error("memory: publishing prune audit event", "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// pruneRepo simulates an agent with live memories, expired of which are past the TTL.
+type pruneRepo struct {
+	Repository
+	live, expired int64
+	limits        []int
+	before        time.Time
+}
+
+func (r *pruneRepo) PruneExpired(_ context.Context, _, _ uuid.UUID, before time.Time, limit int) (int64, error) {
+	r.before = before
+	n := min(r.expired, int64(limit))
+	r.expired -= n
+	r.live -= n
+	return n, nil
+}
+
+func (r *pruneRepo) CountByAgent(_ context.Context, _, _ uuid.UUID, _ MetadataFilter) (int64, error) {
+	return r.live, nil
+}
+
+func (r *pruneRepo) PruneOldest(_ context.Context, _, _ uuid.UUID, limit int) (int64, error) {
+	r.limits = append(r.limits, limit)
+	n := min(r.live, int64(limit))
+	r.live -= n
+	return n, nil
+}
+
+type recordingPublisher struct {
+	events []inats.AuditEvent
+}
+
+func (p *recordingPublisher) PublishAuditEvent(_ context.Context, event inats.AuditEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestPruneAgent_TTLAndCap(t *testing.T) {
+	repo := &pruneRepo{live: 1800, expired: 700}
+	svc := NewService(repo, nil)
+	pub := &recordingPublisher{}
+	svc.SetAuditPublisher(pub)
+	target := PruneTarget{
+		AgentID:     uuid.New(),
+		OwnerUserID: uuid.New(),
+		Config:      MemoryConfig{MaxLongTermMemories: 400, LongTermTTLDays: 30},
+	}
+
+	expired, overCap, err := svc.PruneAgent(context.Background(), target)
+	require.NoError(t, err)
+	assert.Equal(t, int64(700), expired)
+	assert.Equal(t, int64(700), overCap)
+	assert.Equal(t, int64(400), repo.live)
+	assert.Equal(t, []int{PruneBatchSize, 200}, repo.limits)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), repo.before, time.Minute)
+
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "memories_pruned", pub.events[0].EventType)
+	assert.Equal(t, target.OwnerUserID, pub.events[0].OwnerUserID)
+	assert.Equal(t, target.AgentID.String(), pub.events[0].ResourceID)
+	assert.Contains(t, pub.events[0].Details, "700 expired and 700 over-cap")
+}
+
+func TestPruneAgent_UnderLimits(t *testing.T) {
+	repo := &pruneRepo{live: 10}
+	svc := NewService(repo, nil)
+	pub := &recordingPublisher{}
+	svc.SetAuditPublisher(pub)
+
+	expired, overCap, err := svc.PruneAgent(context.Background(), PruneTarget{
+		Config: MemoryConfig{MaxLongTermMemories: 10, LongTermTTLDays: 7},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+	assert.Zero(t, overCap)
+	assert.Empty(t, repo.limits)
+	assert.Empty(t, pub.events)
+}
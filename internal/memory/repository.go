@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	pgvector "github.com/pgvector/pgvector-go"
 )
@@ -13,12 +15,22 @@ import (
 // Repository defines memory persistence operations.
 type Repository interface {
 	Create(ctx context.Context, mem *Memory) error
-	SearchSimilar(ctx context.Context, agentID, ownerUserID uuid.UUID, embedding []float32, limit int, threshold float64) ([]SearchResult, error)
-	ListByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int) ([]Memory, error)
-	CountByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID) (int64, error)
+	CreateBatch(ctx context.Context, mems []*Memory, partial bool) (map[int]error, error)
+	SearchSimilar(ctx context.Context, agentID, ownerUserID uuid.UUID, space EmbeddingSpace, embedding []float32, limit int, threshold float64, filter MetadataFilter, memoryTypes ...string) ([]SearchResult, error)
+	SearchHybrid(ctx context.Context, agentID, ownerUserID uuid.UUID, space EmbeddingSpace, embedding []float32, query string, alpha float64, limit int, threshold float64, filter MetadataFilter) ([]SearchResult, error)
+	ListByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int, filter MetadataFilter) ([]Memory, error)
+	ListByAgentAfter(ctx context.Context, agentID, ownerUserID uuid.UUID, after *Cursor, limit int, filter MetadataFilter) ([]Memory, error)
+	CountByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, filter MetadataFilter) (int64, error)
 	GetByID(ctx context.Context, id, ownerUserID uuid.UUID) (*Memory, error)
 	Delete(ctx context.Context, id, ownerUserID uuid.UUID) error
 	DeleteByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID) error
+	Restore(ctx context.Context, id, ownerUserID uuid.UUID) error
+	MergeDuplicate(ctx context.Context, id, ownerUserID uuid.UUID, metadata json.RawMessage) error
+	RecordAccess(ctx context.Context, hits map[uuid.UUID]int, at time.Time) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	ListPruneTargets(ctx context.Context, afterAgentID uuid.UUID, limit int) ([]PruneTarget, error)
+	PruneExpired(ctx context.Context, agentID, ownerUserID uuid.UUID, before time.Time, limit int) (int64, error)
+	PruneOldest(ctx context.Context, agentID, ownerUserID uuid.UUID, limit int) (int64, error)
 }
 
 // PostgresRepository implements Repository using pgx + pgvector.
@@ -44,9 +56,9 @@ func (r *PostgresRepository) Create(ctx context.Context, mem *Memory) error {
 	if len(mem.Embedding) > 0 {
 		vec := pgvector.NewVector(mem.Embedding)
 		_, err := r.pool.Exec(ctx,
-			`INSERT INTO agent_memories (id, owner_user_id, agent_id, content, embedding, memory_type, metadata)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-			mem.ID, mem.OwnerUserID, mem.AgentID, mem.Content, vec, mem.MemoryType, metadataBytes,
+			`INSERT INTO agent_memories (id, owner_user_id, agent_id, content, embedding, embedding_model, embedding_dim, memory_type, metadata)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			mem.ID, mem.OwnerUserID, mem.AgentID, mem.Content, vec, mem.EmbeddingModel, mem.EmbeddingDim, mem.MemoryType, metadataBytes,
 		)
 		if err != nil {
 			return fmt.Errorf("inserting memory with embedding: %w", err)
@@ -64,18 +76,130 @@ func (r *PostgresRepository) Create(ctx context.Context, mem *Memory) error {
 	return nil
 }
 
-func (r *PostgresRepository) SearchSimilar(ctx context.Context, agentID, ownerUserID uuid.UUID, embedding []float32, limit int, threshold float64) ([]SearchResult, error) {
+// CreateBatch inserts memories in a single transaction using pgx batches and
+// returns the rows that failed by index. Without partial, the first failing
+// row rolls back the whole batch. With partial, each row runs under a
+// savepoint: a failing row is rolled back alone and the rows after it are
+// sent again in one more batch, so every row is sent once and the rest commit.
+func (r *PostgresRepository) CreateBatch(ctx context.Context, mems []*Memory, partial bool) (map[int]error, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning memory batch: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	failed := make(map[int]error)
+	for next := 0; next < len(mems); {
+		at, err := sendMemoryBatch(ctx, tx, mems, next, partial)
+		if err == nil {
+			break
+		}
+		if at < 0 {
+			return nil, err
+		}
+		failed[at] = err
+		if !partial {
+			return failed, nil
+		}
+		if _, err := tx.Exec(ctx, "ROLLBACK TO SAVEPOINT memory_row"); err != nil {
+			return nil, fmt.Errorf("rolling back memory %d: %w", at, err)
+		}
+		next = at + 1
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing memory batch: %w", err)
+	}
+	return failed, nil
+}
+
+// sendMemoryBatch inserts mems[from:] in one round trip. It stops at the
+// first row whose insert fails and returns its index with the error; other
+// failures are returned with index -1.
+func sendMemoryBatch(ctx context.Context, tx pgx.Tx, mems []*Memory, from int, partial bool) (int, error) {
+	batch := &pgx.Batch{}
+	for _, mem := range mems[from:] {
+		if mem.ID == uuid.Nil {
+			mem.ID = uuid.New()
+		}
+		metadataBytes := mem.Metadata
+		if len(metadataBytes) == 0 {
+			metadataBytes = json.RawMessage(`{}`)
+		}
+
+		if partial {
+			batch.Queue("SAVEPOINT memory_row")
+		}
+		if len(mem.Embedding) > 0 {
+			batch.Queue(
+				`INSERT INTO agent_memories (id, owner_user_id, agent_id, content, embedding, embedding_model, embedding_dim, memory_type, metadata, created_at, updated_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)`,
+				mem.ID, mem.OwnerUserID, mem.AgentID, mem.Content, pgvector.NewVector(mem.Embedding), mem.EmbeddingModel, mem.EmbeddingDim, mem.MemoryType, metadataBytes, mem.CreatedAt,
+			)
+		} else {
+			batch.Queue(
+				`INSERT INTO agent_memories (id, owner_user_id, agent_id, content, memory_type, metadata, created_at, updated_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`,
+				mem.ID, mem.OwnerUserID, mem.AgentID, mem.Content, mem.MemoryType, metadataBytes, mem.CreatedAt,
+			)
+		}
+		if partial {
+			batch.Queue("RELEASE SAVEPOINT memory_row")
+		}
+	}
+
+	br := tx.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := from; i < len(mems); i++ {
+		if partial {
+			if _, err := br.Exec(); err != nil {
+				return -1, fmt.Errorf("setting savepoint for memory %d: %w", i, err)
+			}
+		}
+		if _, err := br.Exec(); err != nil {
+			return i, fmt.Errorf("inserting memory %d: %w", i, err)
+		}
+		if partial {
+			if _, err := br.Exec(); err != nil {
+				return -1, fmt.Errorf("releasing savepoint for memory %d: %w", i, err)
+			}
+		}
+	}
+	if err := br.Close(); err != nil {
+		return -1, fmt.Errorf("closing memory batch: %w", err)
+	}
+	return -1, nil
+}
+
+// SearchSimilar returns the memories closest to embedding. Non-empty
+// memoryTypes restricts results to those types.
+func (r *PostgresRepository) SearchSimilar(ctx context.Context, agentID, ownerUserID uuid.UUID, space EmbeddingSpace, embedding []float32, limit int, threshold float64, filter MetadataFilter, memoryTypes ...string) ([]SearchResult, error) {
+	metadata, err := filter.containment()
+	if err != nil {
+		return nil, err
+	}
 	vec := pgvector.NewVector(embedding)
+	// The typmod cast lets the 384-dim partial index apply, but it fails on
+	// rows of other dimensions. Postgres may evaluate WHERE conditions in any
+	// order, so the threshold check guards the cast with CASE. The select list
+	// and ORDER BY only see rows that passed the space filter, and ORDER BY
+	// keeps the bare expression the index is built on.
+	distance := fmt.Sprintf("embedding::vector(%d) <=> $1", space.Dim)
+	guarded := fmt.Sprintf("CASE WHEN embedding_dim = %d THEN %s END", space.Dim, distance)
+	inSpace := fmt.Sprintf("embedding_dim = %d AND embedding_model = $7", space.Dim)
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, owner_user_id, agent_id, content, memory_type, metadata, created_at,
-		        1 - (embedding <=> $1) AS similarity
+		`SELECT id, owner_user_id, agent_id, content, memory_type, metadata, embedding_model, embedding_dim, access_count, last_accessed_at, created_at, updated_at,
+		        1 - (`+guarded+`) AS similarity
 		 FROM agent_memories
-		 WHERE agent_id = $2 AND owner_user_id = $3
+		 WHERE agent_id = $2 AND owner_user_id = $3 AND deleted_at IS NULL
+		   AND `+inSpace+`
 		   AND embedding IS NOT NULL
-		   AND 1 - (embedding <=> $1) >= $4
-		 ORDER BY embedding <=> $1
+		   AND 1 - (`+guarded+`) >= $4
+		   AND metadata @> $6
+		   AND (COALESCE(cardinality($8::text[]), 0) = 0 OR memory_type = ANY($8))
+		 ORDER BY `+distance+`
 		 LIMIT $5`,
-		vec, agentID, ownerUserID, threshold, limit,
+		vec, agentID, ownerUserID, threshold, limit, metadata, space.Model, memoryTypes,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("searching similar memories: %w", err)
@@ -86,7 +210,7 @@ func (r *PostgresRepository) SearchSimilar(ctx context.Context, agentID, ownerUs
 	for rows.Next() {
 		var m Memory
 		var similarity float64
-		if err := rows.Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.CreatedAt, &similarity); err != nil {
+		if err := rows.Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.EmbeddingModel, &m.EmbeddingDim, &m.AccessCount, &m.LastAccessedAt, &m.CreatedAt, &m.UpdatedAt, &similarity); err != nil {
 			return nil, fmt.Errorf("scanning search result: %w", err)
 		}
 		results = append(results, SearchResult{Memory: m, Similarity: similarity})
@@ -94,15 +218,66 @@ func (r *PostgresRepository) SearchSimilar(ctx context.Context, agentID, ownerUs
 	return results, rows.Err()
 }
 
-func (r *PostgresRepository) ListByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int) ([]Memory, error) {
+// SearchHybrid ranks memories by alpha*cosine similarity + (1-alpha)*text rank.
+// The text rank uses ts_rank_cd normalised into [0,1) so both terms share a scale;
+// memories without an embedding, or embedded in another space, can still
+// match on text alone. Their vector term is guarded by CASE so embeddings of
+// other dimensions are never compared.
+func (r *PostgresRepository) SearchHybrid(ctx context.Context, agentID, ownerUserID uuid.UUID, space EmbeddingSpace, embedding []float32, query string, alpha float64, limit int, threshold float64, filter MetadataFilter) ([]SearchResult, error) {
+	metadata, err := filter.containment()
+	if err != nil {
+		return nil, err
+	}
+	vec := pgvector.NewVector(embedding)
+	inSpace := fmt.Sprintf("m.embedding IS NOT NULL AND m.embedding_dim = %d AND m.embedding_model = $9", space.Dim)
+	rows, err := r.pool.Query(ctx,
+		`WITH q AS (SELECT websearch_to_tsquery('simple', $2) AS tsq)
+		 SELECT id, owner_user_id, agent_id, content, memory_type, metadata, embedding_model, embedding_dim, access_count, last_accessed_at, created_at, updated_at, score
+		 FROM (
+		     SELECT m.id, m.owner_user_id, m.agent_id, m.content, m.memory_type, m.metadata, m.embedding_model, m.embedding_dim, m.access_count, m.last_accessed_at, m.created_at, m.updated_at,
+		            $3 * COALESCE(CASE WHEN `+inSpace+` THEN 1 - (m.embedding <=> $1) END, 0)
+		              + (1 - $3) * ts_rank_cd(m.content_tsv, q.tsq, 32) AS score
+		     FROM agent_memories m, q
+		     WHERE m.agent_id = $4 AND m.owner_user_id = $5 AND m.deleted_at IS NULL
+		       AND ((`+inSpace+`) OR m.content_tsv @@ q.tsq)
+		       AND m.metadata @> $8
+		 ) ranked
+		 WHERE score >= $6
+		 ORDER BY score DESC
+		 LIMIT $7`,
+		vec, query, alpha, agentID, ownerUserID, threshold, limit, metadata, space.Model,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("hybrid searching memories: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var m Memory
+		var score float64
+		if err := rows.Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.EmbeddingModel, &m.EmbeddingDim, &m.AccessCount, &m.LastAccessedAt, &m.CreatedAt, &m.UpdatedAt, &score); err != nil {
+			return nil, fmt.Errorf("scanning hybrid search result: %w", err)
+		}
+		results = append(results, SearchResult{Memory: m, Similarity: score})
+	}
+	return results, rows.Err()
+}
+
+func (r *PostgresRepository) ListByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int, filter MetadataFilter) ([]Memory, error) {
+	metadata, err := filter.containment()
+	if err != nil {
+		return nil, err
+	}
 	offset := (page - 1) * pageSize
 	rows, err := r.pool.Query(ctx,
-		`SELECT id, owner_user_id, agent_id, content, memory_type, metadata, created_at
+		`SELECT id, owner_user_id, agent_id, content, memory_type, metadata, embedding_model, embedding_dim, access_count, last_accessed_at, created_at, updated_at
 		 FROM agent_memories
-		 WHERE agent_id = $1 AND owner_user_id = $2
+		 WHERE agent_id = $1 AND owner_user_id = $2 AND deleted_at IS NULL
+		   AND metadata @> $5
 		 ORDER BY created_at DESC
 		 LIMIT $3 OFFSET $4`,
-		agentID, ownerUserID, pageSize, offset,
+		agentID, ownerUserID, pageSize, offset, metadata,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("listing memories: %w", err)
@@ -112,7 +287,43 @@ func (r *PostgresRepository) ListByAgent(ctx context.Context, agentID, ownerUser
 	var memories []Memory
 	for rows.Next() {
 		var m Memory
-		if err := rows.Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.CreatedAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.EmbeddingModel, &m.EmbeddingDim, &m.AccessCount, &m.LastAccessedAt, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning memory: %w", err)
+		}
+		memories = append(memories, m)
+	}
+	return memories, rows.Err()
+}
+
+// ListByAgentAfter returns up to limit memories strictly older than the cursor
+// using a keyset predicate. A nil cursor starts from the newest memory.
+func (r *PostgresRepository) ListByAgentAfter(ctx context.Context, agentID, ownerUserID uuid.UUID, after *Cursor, limit int, filter MetadataFilter) ([]Memory, error) {
+	metadata, err := filter.containment()
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT id, owner_user_id, agent_id, content, memory_type, metadata, embedding_model, embedding_dim, access_count, last_accessed_at, created_at, updated_at
+		 FROM agent_memories
+		 WHERE agent_id = $1 AND owner_user_id = $2 AND deleted_at IS NULL
+		   AND metadata @> $3`
+	args := []any{agentID, ownerUserID, metadata}
+	if after != nil {
+		query += ` AND (created_at, id) < ($4, $5)`
+		args = append(args, after.CreatedAt, after.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing memories by cursor: %w", err)
+	}
+	defer rows.Close()
+
+	var memories []Memory
+	for rows.Next() {
+		var m Memory
+		if err := rows.Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.EmbeddingModel, &m.EmbeddingDim, &m.AccessCount, &m.LastAccessedAt, &m.CreatedAt, &m.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scanning memory: %w", err)
 		}
 		memories = append(memories, m)
@@ -120,11 +331,15 @@ func (r *PostgresRepository) ListByAgent(ctx context.Context, agentID, ownerUser
 	return memories, rows.Err()
 }
 
-func (r *PostgresRepository) CountByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID) (int64, error) {
+func (r *PostgresRepository) CountByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, filter MetadataFilter) (int64, error) {
+	metadata, err := filter.containment()
+	if err != nil {
+		return 0, err
+	}
 	var count int64
-	err := r.pool.QueryRow(ctx,
-		`SELECT COUNT(*) FROM agent_memories WHERE agent_id = $1 AND owner_user_id = $2`,
-		agentID, ownerUserID,
+	err = r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM agent_memories WHERE agent_id = $1 AND owner_user_id = $2 AND deleted_at IS NULL AND metadata @> $3`,
+		agentID, ownerUserID, metadata,
 	).Scan(&count)
 	return count, err
 }
@@ -132,11 +347,11 @@ func (r *PostgresRepository) CountByAgent(ctx context.Context, agentID, ownerUse
 func (r *PostgresRepository) GetByID(ctx context.Context, id, ownerUserID uuid.UUID) (*Memory, error) {
 	var m Memory
 	err := r.pool.QueryRow(ctx,
-		`SELECT id, owner_user_id, agent_id, content, memory_type, metadata, created_at
+		`SELECT id, owner_user_id, agent_id, content, memory_type, metadata, embedding_model, embedding_dim, access_count, last_accessed_at, created_at, updated_at
 		 FROM agent_memories
-		 WHERE id = $1 AND owner_user_id = $2`,
+		 WHERE id = $1 AND owner_user_id = $2 AND deleted_at IS NULL`,
 		id, ownerUserID,
-	).Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.CreatedAt)
+	).Scan(&m.ID, &m.OwnerUserID, &m.AgentID, &m.Content, &m.MemoryType, &m.Metadata, &m.EmbeddingModel, &m.EmbeddingDim, &m.AccessCount, &m.LastAccessedAt, &m.CreatedAt, &m.UpdatedAt)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
 			return nil, nil
@@ -148,7 +363,7 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id, ownerUserID uuid.U
 
 func (r *PostgresRepository) Delete(ctx context.Context, id, ownerUserID uuid.UUID) error {
 	tag, err := r.pool.Exec(ctx,
-		`DELETE FROM agent_memories WHERE id = $1 AND owner_user_id = $2`,
+		`UPDATE agent_memories SET deleted_at = NOW() WHERE id = $1 AND owner_user_id = $2 AND deleted_at IS NULL`,
 		id, ownerUserID,
 	)
 	if err != nil {
@@ -162,7 +377,7 @@ func (r *PostgresRepository) Delete(ctx context.Context, id, ownerUserID uuid.UU
 
 func (r *PostgresRepository) DeleteByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID) error {
 	_, err := r.pool.Exec(ctx,
-		`DELETE FROM agent_memories WHERE agent_id = $1 AND owner_user_id = $2`,
+		`UPDATE agent_memories SET deleted_at = NOW() WHERE agent_id = $1 AND owner_user_id = $2 AND deleted_at IS NULL`,
 		agentID, ownerUserID,
 	)
 	if err != nil {
@@ -170,3 +385,141 @@ func (r *PostgresRepository) DeleteByAgent(ctx context.Context, agentID, ownerUs
 	}
 	return nil
 }
+
+// Restore clears deleted_at on a soft-deleted memory.
+func (r *PostgresRepository) Restore(ctx context.Context, id, ownerUserID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE agent_memories SET deleted_at = NULL WHERE id = $1 AND owner_user_id = $2 AND deleted_at IS NOT NULL`,
+		id, ownerUserID,
+	)
+	if err != nil {
+		return fmt.Errorf("restoring memory: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("memory not found")
+	}
+	return nil
+}
+
+// MergeDuplicate folds a near-duplicate write into an existing memory: the new
+// metadata is merged over the stored keys and access_count is incremented.
+func (r *PostgresRepository) MergeDuplicate(ctx context.Context, id, ownerUserID uuid.UUID, metadata json.RawMessage) error {
+	if len(metadata) == 0 {
+		metadata = json.RawMessage(`{}`)
+	}
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE agent_memories
+		 SET metadata = COALESCE(metadata, '{}'::jsonb) || $3, access_count = access_count + 1, updated_at = NOW()
+		 WHERE id = $1 AND owner_user_id = $2 AND deleted_at IS NULL`,
+		id, ownerUserID, metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("merging duplicate memory: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("memory not found")
+	}
+	return nil
+}
+
+// RecordAccess adds each memory's search hits to access_count and sets
+// last_accessed_at, in a single statement.
+func (r *PostgresRepository) RecordAccess(ctx context.Context, hits map[uuid.UUID]int, at time.Time) error {
+	ids := make([]uuid.UUID, 0, len(hits))
+	counts := make([]int32, 0, len(hits))
+	for id, n := range hits {
+		ids = append(ids, id)
+		counts = append(counts, int32(n))
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE agent_memories m
+		 SET access_count = m.access_count + h.n,
+		     last_accessed_at = GREATEST(m.last_accessed_at, $3)
+		 FROM unnest($1::uuid[], $2::int[]) AS h(id, n)
+		 WHERE m.id = h.id`,
+		ids, counts, at,
+	)
+	if err != nil {
+		return fmt.Errorf("recording memory access: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted permanently removes memories soft-deleted before the given time.
+func (r *PostgresRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM agent_memories WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("purging deleted memories: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListPruneTargets returns live agents, ordered by ID after afterAgentID, whose
+// memory_config sets a long-term TTL or cap.
+func (r *PostgresRepository) ListPruneTargets(ctx context.Context, afterAgentID uuid.UUID, limit int) ([]PruneTarget, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, owner_user_id, memory_config
+		 FROM agents
+		 WHERE deleted_at IS NULL
+		   AND memory_config ?| array['max_long_term_memories', 'long_term_ttl_days']
+		   AND id > $1
+		 ORDER BY id
+		 LIMIT $2`,
+		afterAgentID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing prune targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []PruneTarget
+	for rows.Next() {
+		var t PruneTarget
+		var cfg []byte
+		if err := rows.Scan(&t.AgentID, &t.OwnerUserID, &cfg); err != nil {
+			return nil, fmt.Errorf("scanning prune target: %w", err)
+		}
+		t.Config = ParseConfig(cfg)
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// PruneExpired soft-deletes up to limit of the agent's memories created before the given time.
+func (r *PostgresRepository) PruneExpired(ctx context.Context, agentID, ownerUserID uuid.UUID, before time.Time, limit int) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE agent_memories SET deleted_at = NOW()
+		 WHERE id IN (
+		   SELECT id FROM agent_memories
+		   WHERE agent_id = $1 AND owner_user_id = $2 AND deleted_at IS NULL AND created_at < $3
+		   LIMIT $4
+		 )`,
+		agentID, ownerUserID, before, limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("pruning expired memories: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneOldest soft-deletes the agent's limit least-reinforced memories,
+// fewest merged duplicates first and oldest among equals.
+func (r *PostgresRepository) PruneOldest(ctx context.Context, agentID, ownerUserID uuid.UUID, limit int) (int64, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE agent_memories SET deleted_at = NOW()
+		 WHERE id IN (
+		   SELECT id FROM agent_memories
+		   WHERE agent_id = $1 AND owner_user_id = $2 AND deleted_at IS NULL
+		   ORDER BY access_count ASC, created_at ASC, id ASC
+		   LIMIT $3
+		 )`,
+		agentID, ownerUserID, limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("pruning oldest memories: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// DefaultRecencyHalfLifeDays is how long a memory takes to lose half its
+// recency boost when the weights don't set a half-life.
+const DefaultRecencyHalfLifeDays = 30
+
+// rerankCandidateFactor widens the database search when reranking or capping
+// types, so memories just below the top by similarity can still be included.
+const (
+	rerankCandidateFactor = 3
+	maxRerankCandidates   = 100
+)
+
+// RerankWeights boosts search results by recency and access frequency. The
+// final score is similarity + RecencyWeight·recency + FrequencyWeight·frequency,
+// where recency halves every RecencyHalfLifeDays since the memory was created
+// and frequency grows with access_count towards 1. Zero weights disable
+// reranking.
+type RerankWeights struct {
+	RecencyWeight       float64 `json:"recency_weight" validate:"gte=0,lte=1"`
+	FrequencyWeight     float64 `json:"frequency_weight" validate:"gte=0,lte=1"`
+	RecencyHalfLifeDays float64 `json:"recency_half_life_days,omitempty" validate:"gte=0"`
+}
+
+// Enabled reports whether w changes the ranking.
+func (w RerankWeights) Enabled() bool {
+	return w.RecencyWeight > 0 || w.FrequencyWeight > 0
+}
+
+// score combines a result's similarity with its recency and frequency boosts.
+func (w RerankWeights) score(r SearchResult, now time.Time) float64 {
+	halfLife := w.RecencyHalfLifeDays
+	if halfLife <= 0 {
+		halfLife = DefaultRecencyHalfLifeDays
+	}
+	ageDays := max(now.Sub(r.Memory.CreatedAt).Hours()/24, 0)
+	recency := math.Exp2(-ageDays / halfLife)
+
+	n := math.Log1p(float64(max(r.Memory.AccessCount, 0)))
+	frequency := n / (n + 1)
+
+	return r.Similarity + w.RecencyWeight*recency + w.FrequencyWeight*frequency
+}
+
+// sanitize zeroes weights outside their allowed range.
+func (w RerankWeights) sanitize() RerankWeights {
+	if w.RecencyWeight < 0 || w.RecencyWeight > 1 {
+		w.RecencyWeight = 0
+	}
+	if w.FrequencyWeight < 0 || w.FrequencyWeight > 1 {
+		w.FrequencyWeight = 0
+	}
+	if w.RecencyHalfLifeDays < 0 {
+		w.RecencyHalfLifeDays = 0
+	}
+	return w
+}
+
+// rerank scores results and orders them by score. Without weights each score
+// is the raw similarity and the order is kept.
+func rerank(results []SearchResult, w RerankWeights, now time.Time) []SearchResult {
+	for i := range results {
+		results[i].Score = results[i].Similarity
+		if w.Enabled() {
+			results[i].Score = w.score(results[i], now)
+		}
+	}
+	if w.Enabled() {
+		slices.SortStableFunc(results, func(a, b SearchResult) int {
+			switch {
+			case a.Score > b.Score:
+				return -1
+			case a.Score < b.Score:
+				return 1
+			}
+			return 0
+		})
+	}
+	return results
+}
+
+// top keeps the first limit results, skipping those whose memory_type has
+// already reached its cap in perType.
+func top(results []SearchResult, limit int, perType map[string]int) []SearchResult {
+	if len(perType) == 0 {
+		return results[:min(limit, len(results))]
+	}
+	kept := results[:0]
+	seen := make(map[string]int)
+	for _, r := range results {
+		if len(kept) == limit {
+			break
+		}
+		if typeCap, capped := perType[r.Memory.MemoryType]; capped && seen[r.Memory.MemoryType] >= typeCap {
+			continue
+		}
+		seen[r.Memory.MemoryType]++
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// candidateLimit is how many results to fetch so that reranking or per-type
+// caps (widen) can still pick limit.
+func candidateLimit(limit int, widen bool) int {
+	if !widen {
+		return limit
+	}
+	return max(limit, min(limit*rerankCandidateFactor, maxRerankCandidates))
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRerank(t *testing.T) {
+	now := time.Now()
+	stale := SearchResult{Memory: Memory{ID: uuid.New(), CreatedAt: now.Add(-90 * 24 * time.Hour)}, Similarity: 0.9}
+	fresh := SearchResult{Memory: Memory{ID: uuid.New(), CreatedAt: now}, Similarity: 0.8}
+	popular := SearchResult{Memory: Memory{ID: uuid.New(), CreatedAt: now.Add(-90 * 24 * time.Hour), AccessCount: 50}, Similarity: 0.85}
+
+	t.Run("disabled keeps similarity order", func(t *testing.T) {
+		out := top(rerank([]SearchResult{stale, popular, fresh}, RerankWeights{}, now), 2, nil)
+		require.Len(t, out, 2)
+		assert.Equal(t, stale.Memory.ID, out[0].Memory.ID)
+		assert.Equal(t, out[0].Similarity, out[0].Score)
+	})
+
+	t.Run("recency", func(t *testing.T) {
+		out := rerank([]SearchResult{stale, popular, fresh}, RerankWeights{RecencyWeight: 0.3}, now)
+		assert.Equal(t, fresh.Memory.ID, out[0].Memory.ID)
+		assert.InDelta(t, 1.1, out[0].Score, 1e-9)
+		assert.Equal(t, 0.8, out[0].Similarity)
+	})
+
+	t.Run("frequency", func(t *testing.T) {
+		out := rerank([]SearchResult{stale, popular, fresh}, RerankWeights{FrequencyWeight: 0.3}, now)
+		assert.Equal(t, popular.Memory.ID, out[0].Memory.ID)
+	})
+
+	t.Run("half-life", func(t *testing.T) {
+		w := RerankWeights{RecencyWeight: 1, RecencyHalfLifeDays: 90}
+		assert.InDelta(t, 0.9+0.5, w.score(stale, now), 1e-9)
+	})
+}
+
+func TestSearch_RerankWidensCandidates(t *testing.T) {
+	repo := &limitRepo{}
+	svc := NewService(repo, nil)
+	cfg := dedupConfig()
+
+	_, err := svc.Search(context.Background(), uuid.New(), uuid.New(), &SearchMemoryRequest{Embedding: []float32{1, 0}, Limit: 4}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 4, repo.limit)
+
+	cfg.Rerank = RerankWeights{RecencyWeight: 0.1}
+	_, err = svc.Search(context.Background(), uuid.New(), uuid.New(), &SearchMemoryRequest{Embedding: []float32{1, 0}, Limit: 4}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 12, repo.limit)
+
+	_, err = svc.Search(context.Background(), uuid.New(), uuid.New(), &SearchMemoryRequest{Embedding: []float32{1, 0}, Limit: 4, Rerank: &RerankWeights{}}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 4, repo.limit, "request weights override the agent's")
+}
+
+// limitRepo records the limit of the last similarity search.
+type limitRepo struct {
+	Repository
+	limit int
+}
+
+func (r *limitRepo) SearchSimilar(_ context.Context, _, _ uuid.UUID, _ EmbeddingSpace, _ []float32, limit int, _ float64, _ MetadataFilter, _ ...string) ([]SearchResult, error) {
+	r.limit = limit
+	return nil, nil
+}
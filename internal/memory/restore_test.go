@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+)
+
+// trashRepo keeps soft-deleted memories in memory, following the
+// PostgresRepository semantics for Restore and PurgeDeleted.
+type trashRepo struct {
+	Repository
+	owners  map[uuid.UUID]uuid.UUID
+	deleted map[uuid.UUID]time.Time
+	cutoff  time.Time
+}
+
+func newTrashRepo() *trashRepo {
+	return &trashRepo{owners: make(map[uuid.UUID]uuid.UUID), deleted: make(map[uuid.UUID]time.Time)}
+}
+
+func (r *trashRepo) add(owner uuid.UUID, deletedAt time.Time) uuid.UUID {
+	id := uuid.New()
+	r.owners[id] = owner
+	r.deleted[id] = deletedAt
+	return id
+}
+
+func (r *trashRepo) Restore(_ context.Context, id, ownerUserID uuid.UUID) error {
+	if _, ok := r.deleted[id]; !ok || r.owners[id] != ownerUserID {
+		return errors.New("memory not found")
+	}
+	delete(r.deleted, id)
+	return nil
+}
+
+func (r *trashRepo) PurgeDeleted(_ context.Context, before time.Time) (int64, error) {
+	r.cutoff = before
+	var n int64
+	for id, at := range r.deleted {
+		if at.Before(before) {
+			delete(r.deleted, id)
+			delete(r.owners, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestRunPurge(t *testing.T) {
+	repo := newTrashRepo()
+	owner := uuid.New()
+	now := time.Now()
+	recent := repo.add(owner, now.Add(-DeletedMemoryRetention+time.Hour))
+	expired := repo.add(owner, now.Add(-DeletedMemoryRetention-time.Hour))
+	svc := NewService(repo, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, svc.RunPurge(ctx), "the first purge runs before ctx is checked")
+
+	assert.WithinDuration(t, now.Add(-DeletedMemoryRetention), repo.cutoff, time.Minute)
+	assert.Contains(t, repo.deleted, recent)
+	assert.NotContains(t, repo.deleted, expired)
+
+	require.NoError(t, svc.Restore(context.Background(), recent, owner))
+	assert.EqualError(t, svc.Restore(context.Background(), expired, owner), "memory not found")
+}
+
+func TestHandler_Restore(t *testing.T) {
+	repo := newTrashRepo()
+	owner, other := uuid.New(), uuid.New()
+	now := time.Now()
+	inWindow := repo.add(owner, now.Add(-24*time.Hour))
+	othersMemory := repo.add(other, now.Add(-time.Hour))
+	purged := repo.add(owner, now.Add(-DeletedMemoryRetention-time.Hour))
+
+	svc := NewService(repo, nil)
+	_, err := repo.PurgeDeleted(context.Background(), now.Add(-DeletedMemoryRetention))
+	require.NoError(t, err)
+	h := NewHandler(svc)
+
+	restore := func(agentOwner uuid.UUID, memoryID string) int {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("memoryID", memoryID)
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = agents.SetAgentInContext(ctx, &agents.Agent{ID: uuid.New(), OwnerUserID: agentOwner})
+		rec := httptest.NewRecorder()
+		h.Restore(rec, req.WithContext(ctx))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, restore(owner, "not-a-uuid"))
+	assert.Equal(t, http.StatusNotFound, restore(owner, othersMemory.String()), "another owner's memory")
+	assert.Contains(t, repo.deleted, othersMemory)
+	assert.Equal(t, http.StatusNotFound, restore(owner, purged.String()))
+
+	assert.Equal(t, http.StatusOK, restore(owner, inWindow.String()))
+	assert.NotContains(t, repo.deleted, inWindow)
+	assert.Equal(t, http.StatusNotFound, restore(owner, inWindow.String()), "a live memory can't be restored")
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/database"
+)
+
+// retryingRepository retries the reads of a Repository on transient Postgres
+// errors. Writes pass through untouched: a write that failed after reaching
+// the server may have been applied.
+type retryingRepository struct {
+	Repository
+	retry *database.Retrier
+}
+
+// WithRetry wraps repo so that its reads are retried by retry.
+func WithRetry(repo Repository, retry *database.Retrier) Repository {
+	return &retryingRepository{Repository: repo, retry: retry}
+}
+
+func (r *retryingRepository) SearchSimilar(ctx context.Context, agentID, ownerUserID uuid.UUID, space EmbeddingSpace, embedding []float32, limit int, threshold float64, filter MetadataFilter, memoryTypes ...string) (results []SearchResult, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		results, err = r.Repository.SearchSimilar(ctx, agentID, ownerUserID, space, embedding, limit, threshold, filter, memoryTypes...)
+		return err
+	})
+	return results, err
+}
+
+func (r *retryingRepository) SearchHybrid(ctx context.Context, agentID, ownerUserID uuid.UUID, space EmbeddingSpace, embedding []float32, query string, alpha float64, limit int, threshold float64, filter MetadataFilter) (results []SearchResult, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		results, err = r.Repository.SearchHybrid(ctx, agentID, ownerUserID, space, embedding, query, alpha, limit, threshold, filter)
+		return err
+	})
+	return results, err
+}
+
+func (r *retryingRepository) ListByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int, filter MetadataFilter) (mems []Memory, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		mems, err = r.Repository.ListByAgent(ctx, agentID, ownerUserID, page, pageSize, filter)
+		return err
+	})
+	return mems, err
+}
+
+func (r *retryingRepository) ListByAgentAfter(ctx context.Context, agentID, ownerUserID uuid.UUID, after *Cursor, limit int, filter MetadataFilter) (mems []Memory, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		mems, err = r.Repository.ListByAgentAfter(ctx, agentID, ownerUserID, after, limit, filter)
+		return err
+	})
+	return mems, err
+}
+
+func (r *retryingRepository) CountByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID, filter MetadataFilter) (n int64, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		n, err = r.Repository.CountByAgent(ctx, agentID, ownerUserID, filter)
+		return err
+	})
+	return n, err
+}
+
+func (r *retryingRepository) GetByID(ctx context.Context, id, ownerUserID uuid.UUID) (mem *Memory, err error) {
+	err = r.retry.Do(ctx, func(ctx context.Context) error {
+		mem, err = r.Repository.GetByID(ctx, id, ownerUserID)
+		return err
+	})
+	return mem, err
+}
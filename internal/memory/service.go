@@ -3,17 +3,24 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // Service orchestrates short-term (Redis) and long-term (pgvector) memory operations.
 type Service struct {
 	repo       Repository
 	shortTerm  *ShortTermStore
+	summarizer Summarizer
+	publisher  AuditPublisher
+	embedder   Embedder
+	access     accessLog
+	tokens     TokenCounter
 }
 
 // NewService creates a new memory service.
@@ -46,11 +53,17 @@ func (s *Service) GetConversationContext(
 	}
 
 	// Long-term: semantic similarity search (only if we have a query embedding)
-	if cfg.LongTermEnabled && len(queryEmbedding) > 0 {
-		results, err := s.repo.SearchSimilar(ctx, agentID, ownerUserID, queryEmbedding, cfg.MaxLongTermResults, cfg.SimilarityThreshold)
+	if err := cfg.checkEmbedding(queryEmbedding); err != nil {
+		slog.Warn("memory: skipping long-term search", "error", err, "agent_id", agentID)
+	} else if cfg.LongTermEnabled && len(queryEmbedding) > 0 {
+		limit := cfg.MaxLongTermResults
+		fetch := candidateLimit(limit, cfg.Rerank.Enabled() || len(cfg.MaxResultsPerType) > 0)
+		results, err := s.repo.SearchSimilar(ctx, agentID, ownerUserID, cfg.EmbeddingSpace(), queryEmbedding, fetch, cfg.SimilarityThreshold, nil, cfg.ContextMemoryTypes...)
 		if err != nil {
 			slog.Warn("memory: failed to search long-term memories", "error", err, "agent_id", agentID)
 		} else {
+			results = top(rerank(results, cfg.Rerank, time.Now()), limit, cfg.MaxResultsPerType)
+			s.access.add(results)
 			for _, r := range results {
 				payload.RelevantMemories = append(payload.RelevantMemories, RelevantMemory{
 					Content:    r.Memory.Content,
@@ -61,6 +74,19 @@ func (s *Service) GetConversationContext(
 		}
 	}
 
+	if cfg.MaxContextTokens > 0 {
+		count := s.tokens
+		if count == nil {
+			count = EstimateTokens
+		}
+		if t := fitBudget(payload, cfg.MaxContextTokens, count); t != nil {
+			payload.Truncation = t
+			slog.Info("memory: trimmed conversation context to token budget",
+				"agent_id", agentID, "max_tokens", t.MaxTokens, "estimated_tokens", t.EstimatedTokens,
+				"dropped_messages", t.DroppedMessages, "dropped_memories", t.DroppedMemories)
+		}
+	}
+
 	return payload, nil
 }
 
@@ -101,26 +127,72 @@ func (s *Service) StoreConversationTurn(
 	return nil
 }
 
-// StoreLongTermMemory persists a memory with its embedding to pgvector.
-func (s *Service) StoreLongTermMemory(ctx context.Context, mem *Memory) error {
-	return s.repo.Create(ctx, mem)
+// StoreLongTermMemory persists a memory with its embedding to pgvector and
+// reports whether a new row was created. The embedding must match the agent's
+// embedding space. When cfg.DedupThreshold is set and an existing memory is
+// at least that similar, the write is merged into it instead (metadata
+// merged, access_count bumped) and created is false.
+func (s *Service) StoreLongTermMemory(ctx context.Context, mem *Memory, cfg MemoryConfig) (bool, error) {
+	if err := cfg.checkEmbedding(mem.Embedding); err != nil {
+		return false, err
+	}
+	mem.stamp(cfg.EmbeddingSpace())
+
+	if cfg.DedupThreshold > 0 && len(mem.Embedding) > 0 {
+		matches, err := s.repo.SearchSimilar(ctx, mem.AgentID, mem.OwnerUserID, cfg.EmbeddingSpace(), mem.Embedding, 1, cfg.DedupThreshold, nil)
+		if err != nil {
+			return false, fmt.Errorf("checking for duplicate memory: %w", err)
+		}
+		if len(matches) > 0 {
+			if err := s.repo.MergeDuplicate(ctx, matches[0].Memory.ID, mem.OwnerUserID, mem.Metadata); err != nil {
+				return false, err
+			}
+			return false, nil
+		}
+	}
+	if err := s.repo.Create(ctx, mem); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// List returns paginated memories for an agent.
-func (s *Service) List(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int) ([]Memory, int64, error) {
-	memories, err := s.repo.ListByAgent(ctx, agentID, ownerUserID, page, pageSize)
+// List returns paginated memories for an agent that match filter.
+func (s *Service) List(ctx context.Context, agentID, ownerUserID uuid.UUID, page, pageSize int, filter MetadataFilter) ([]Memory, int64, error) {
+	memories, err := s.repo.ListByAgent(ctx, agentID, ownerUserID, page, pageSize, filter)
 	if err != nil {
 		return nil, 0, err
 	}
-	count, err := s.repo.CountByAgent(ctx, agentID, ownerUserID)
+	count, err := s.repo.CountByAgent(ctx, agentID, ownerUserID, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 	return memories, count, nil
 }
 
-// Create creates a new memory.
-func (s *Service) Create(ctx context.Context, agentID, ownerUserID uuid.UUID, req *CreateMemoryRequest) (*Memory, error) {
+// ListAfter returns a page of memories older than the cursor and, when more
+// rows exist, the cursor for the next page.
+func (s *Service) ListAfter(ctx context.Context, agentID, ownerUserID uuid.UUID, after *Cursor, pageSize int, filter MetadataFilter) ([]Memory, string, error) {
+	// Fetch one extra row to learn whether another page follows.
+	memories, err := s.repo.ListByAgentAfter(ctx, agentID, ownerUserID, after, pageSize+1, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(memories) > pageSize {
+		memories = memories[:pageSize]
+		last := memories[len(memories)-1]
+		next = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return memories, next, nil
+}
+
+// Create creates a new memory in the agent's embedding space. An embedding of
+// the wrong length is rejected with an *EmbeddingDimensionError.
+func (s *Service) Create(ctx context.Context, agentID, ownerUserID uuid.UUID, req *CreateMemoryRequest, cfg MemoryConfig) (*Memory, error) {
+	if err := cfg.checkEmbedding(req.Embedding); err != nil {
+		return nil, err
+	}
 	mem := &Memory{
 		ID:          uuid.New(),
 		OwnerUserID: ownerUserID,
@@ -131,6 +203,7 @@ func (s *Service) Create(ctx context.Context, agentID, ownerUserID uuid.UUID, re
 		Metadata:    req.Metadata,
 		CreatedAt:   time.Now(),
 	}
+	mem.stamp(cfg.EmbeddingSpace())
 	if len(mem.Metadata) == 0 {
 		mem.Metadata = json.RawMessage(`{}`)
 	}
@@ -140,25 +213,204 @@ func (s *Service) Create(ctx context.Context, agentID, ownerUserID uuid.UUID, re
 	return mem, nil
 }
 
-// Search performs a similarity search on agent memories.
-func (s *Service) Search(ctx context.Context, agentID, ownerUserID uuid.UUID, req *SearchMemoryRequest) ([]SearchResult, error) {
+// Search performs a similarity search on agent memories within the agent's
+// embedding space. A query embedding of the wrong length is rejected with an
+// *EmbeddingDimensionError. Results are reranked with req.Rerank, falling
+// back to the agent's weights, and returned memories are counted as accessed.
+func (s *Service) Search(ctx context.Context, agentID, ownerUserID uuid.UUID, req *SearchMemoryRequest, cfg MemoryConfig) ([]SearchResult, error) {
+	weights := cfg.Rerank
+	if req.Rerank != nil {
+		weights = *req.Rerank
+	}
 	limit := req.Limit
 	if limit <= 0 {
 		limit = 5
 	}
+
+	results, err := s.search(ctx, agentID, ownerUserID, req, candidateLimit(limit, weights.Enabled()), cfg)
+	if err != nil {
+		return nil, err
+	}
+	results = top(rerank(results, weights, time.Now()), limit, nil)
+	s.access.add(results)
+	return results, nil
+}
+
+func (s *Service) search(ctx context.Context, agentID, ownerUserID uuid.UUID, req *SearchMemoryRequest, limit int, cfg MemoryConfig) ([]SearchResult, error) {
+	if err := cfg.checkEmbedding(req.Embedding); err != nil {
+		return nil, err
+	}
+	if req.Mode == "hybrid" && req.Query != "" {
+		alpha := DefaultHybridAlpha
+		if req.Alpha != nil {
+			alpha = *req.Alpha
+		}
+		// Fused scores sit on a different scale, so only an explicit threshold applies.
+		return s.repo.SearchHybrid(ctx, agentID, ownerUserID, cfg.EmbeddingSpace(), req.Embedding, req.Query, alpha, limit, req.Threshold, req.MetadataFilter)
+	}
 	threshold := req.Threshold
 	if threshold <= 0 {
 		threshold = 0.7
 	}
-	return s.repo.SearchSimilar(ctx, agentID, ownerUserID, req.Embedding, limit, threshold)
+	return s.repo.SearchSimilar(ctx, agentID, ownerUserID, cfg.EmbeddingSpace(), req.Embedding, limit, threshold, req.MetadataFilter)
+}
+
+// BulkCreate inserts many memories in one transaction. Items listed in rejected
+// (failed validation) are reported and never inserted. Without partial, any
+// failure leaves the whole batch uncommitted; with partial, failing rows are
+// skipped and the remaining ones commit.
+// Memories are stamped with the agent's embedding space; callers reject
+// mismatched embeddings up front via rejected.
+func (s *Service) BulkCreate(ctx context.Context, agentID, ownerUserID uuid.UUID, reqs []CreateMemoryRequest, rejected map[int]error, partial bool, cfg MemoryConfig) (*BulkCreateResult, error) {
+	results := make([]BulkItemResult, len(reqs))
+	var pending []int
+	var mems []*Memory
+	now := time.Now()
+	for i, req := range reqs {
+		results[i].Index = i
+		if err, ok := rejected[i]; ok {
+			results[i].Error = err.Error()
+			continue
+		}
+		pending = append(pending, i)
+		mem := &Memory{
+			ID:          uuid.New(),
+			OwnerUserID: ownerUserID,
+			AgentID:     agentID,
+			Content:     req.Content,
+			MemoryType:  req.MemoryType,
+			Embedding:   req.Embedding,
+			Metadata:    req.Metadata,
+			CreatedAt:   now,
+		}
+		mem.stamp(cfg.EmbeddingSpace())
+		mems = append(mems, mem)
+	}
+
+	if len(rejected) > 0 && !partial {
+		return finishBulk(results, pending, nil), nil
+	}
+	if len(mems) == 0 {
+		return finishBulk(results, nil, nil), nil
+	}
+
+	failed, err := s.repo.CreateBatch(ctx, mems, partial)
+	if err != nil {
+		return nil, err
+	}
+	if len(failed) == 0 {
+		return finishBulk(results, pending, mems), nil
+	}
+
+	var kept []int
+	var keptMems []*Memory
+	for n, i := range pending {
+		if err, ok := failed[n]; ok {
+			results[i].Error = bulkErrorMessage(err)
+			continue
+		}
+		kept = append(kept, i)
+		keptMems = append(keptMems, mems[n])
+	}
+	if !partial {
+		return finishBulk(results, kept, nil), nil
+	}
+	return finishBulk(results, kept, keptMems), nil
+}
+
+// finishBulk fills in created IDs for committed memories (parallel to pending)
+// or, when nothing was committed, marks pending items as rolled back.
+func finishBulk(results []BulkItemResult, pending []int, committed []*Memory) *BulkCreateResult {
+	for n, i := range pending {
+		if committed != nil {
+			id := committed[n].ID
+			results[i].ID = &id
+		} else {
+			results[i].Error = "not inserted: batch rolled back"
+		}
+	}
+
+	out := &BulkCreateResult{Results: results}
+	for _, r := range results {
+		if r.ID != nil {
+			out.Created++
+		} else {
+			out.Failed++
+		}
+	}
+	return out
+}
+
+// bulkErrorMessage exposes the database message for a failed row without the
+// surrounding wrapping.
+func bulkErrorMessage(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Message
+	}
+	return "insert failed"
 }
 
-// Delete deletes a single memory.
+// Delete soft-deletes a single memory.
 func (s *Service) Delete(ctx context.Context, id, ownerUserID uuid.UUID) error {
 	return s.repo.Delete(ctx, id, ownerUserID)
 }
 
+// Restore undoes a soft delete.
+func (s *Service) Restore(ctx context.Context, id, ownerUserID uuid.UUID) error {
+	return s.repo.Restore(ctx, id, ownerUserID)
+}
+
+// RunPurge permanently removes memories soft-deleted longer than
+// DeletedMemoryRetention ago, checking every PurgeInterval until ctx is cancelled.
+func (s *Service) RunPurge(ctx context.Context) error {
+	ticker := time.NewTicker(PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.repo.PurgeDeleted(ctx, time.Now().Add(-DeletedMemoryRetention))
+		if err != nil {
+			slog.Error("memory: purging deleted memories", "error", err)
+		} else if n > 0 {
+			slog.Info("memory: purged deleted memories", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 // DeleteByAgent deletes all memories for an agent.
 func (s *Service) DeleteByAgent(ctx context.Context, agentID, ownerUserID uuid.UUID) error {
 	return s.repo.DeleteByAgent(ctx, agentID, ownerUserID)
 }
+
+// ListConversations returns the agent's conversations held in short-term
+// memory, most recently active first.
+func (s *Service) ListConversations(ctx context.Context, agentID uuid.UUID) ([]ConversationSummary, error) {
+	if s.shortTerm == nil {
+		return []ConversationSummary{}, nil
+	}
+	return s.shortTerm.ListConversations(ctx, agentID)
+}
+
+// GetConversation returns the short-term window the agent currently sees for
+// the conversation with userJID, oldest first.
+func (s *Service) GetConversation(ctx context.Context, agentID uuid.UUID, userJID string, cfg MemoryConfig) ([]ConversationEntry, error) {
+	if s.shortTerm == nil {
+		return []ConversationEntry{}, nil
+	}
+	return s.shortTerm.GetRecentMessages(ctx, agentID, userJID, cfg.MaxShortTermMsgs)
+}
+
+// ClearConversation resets the conversation with userJID, so the agent's next
+// reply starts without short-term context.
+func (s *Service) ClearConversation(ctx context.Context, agentID uuid.UUID, userJID string) error {
+	if s.shortTerm == nil {
+		return nil
+	}
+	return s.shortTerm.ClearConversation(ctx, agentID, userJID)
+}
@@ -3,7 +3,9 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -24,6 +26,19 @@ func convKey(agentID uuid.UUID, userJID string) string {
 	return fmt.Sprintf("conv:%s:%s", agentID.String(), userJID)
 }
 
+// convIndexKey is a sorted set of the agent's conversations, scored by when
+// each one's buffer expires, so they can be listed without scanning.
+func convIndexKey(agentID uuid.UUID) string {
+	return fmt.Sprintf("conv:index:%s", agentID.String())
+}
+
+// ConversationSummary describes a conversation held in short-term memory.
+type ConversationSummary struct {
+	UserJID      string    `json:"user_jid"`
+	MessageCount int64     `json:"message_count"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
 // GetRecentMessages returns the last `limit` conversation entries for the given agent+user pair.
 func (s *ShortTermStore) GetRecentMessages(ctx context.Context, agentID uuid.UUID, userJID string, limit int) ([]ConversationEntry, error) {
 	key := convKey(agentID, userJID)
@@ -54,10 +69,14 @@ func (s *ShortTermStore) AppendMessage(ctx context.Context, agentID uuid.UUID, u
 		return fmt.Errorf("marshaling entry: %w", err)
 	}
 
+	ttl := time.Duration(ttlSec) * time.Second
+	index := convIndexKey(agentID)
 	pipe := s.client.Pipeline()
 	pipe.RPush(ctx, key, string(data))
 	pipe.LTrim(ctx, key, int64(-maxMsgs), -1)
-	pipe.Expire(ctx, key, time.Duration(ttlSec)*time.Second)
+	pipe.Expire(ctx, key, ttl)
+	pipe.ZAdd(ctx, index, redis.Z{Score: float64(time.Now().Add(ttl).Unix()), Member: userJID})
+	pipe.Expire(ctx, index, ttl)
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("pipeline exec for %s: %w", key, err)
@@ -67,6 +86,207 @@ func (s *ShortTermStore) AppendMessage(ctx context.Context, agentID uuid.UUID, u
 
 // ClearConversation deletes the conversation history for the given agent+user pair.
 func (s *ShortTermStore) ClearConversation(ctx context.Context, agentID uuid.UUID, userJID string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, convKey(agentID, userJID))
+	pipe.ZRem(ctx, convIndexKey(agentID), userJID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ListConversations returns the agent's conversations that still hold
+// messages, most recently active first. Entries whose buffer has expired or
+// was cleared are evicted from the index as they are found.
+func (s *ShortTermStore) ListConversations(ctx context.Context, agentID uuid.UUID) ([]ConversationSummary, error) {
+	index := convIndexKey(agentID)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	if err := s.client.ZRemRangeByScore(ctx, index, "-inf", "("+now).Err(); err != nil {
+		return nil, fmt.Errorf("zremrangebyscore %s: %w", index, err)
+	}
+	members, err := s.client.ZRevRangeWithScores(ctx, index, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("zrevrange %s: %w", index, err)
+	}
+
+	pipe := s.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(members))
+	for i, m := range members {
+		counts[i] = pipe.LLen(ctx, convKey(agentID, m.Member.(string)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("counting conversation messages: %w", err)
+	}
+
+	conversations := make([]ConversationSummary, 0, len(members))
+	var stale []any
+	for i, m := range members {
+		n := counts[i].Val()
+		if n == 0 {
+			stale = append(stale, m.Member)
+			continue
+		}
+		conversations = append(conversations, ConversationSummary{
+			UserJID:      m.Member.(string),
+			MessageCount: n,
+			ExpiresAt:    time.Unix(int64(m.Score), 0).UTC(),
+		})
+	}
+	if len(stale) > 0 {
+		if err := s.client.ZRem(ctx, index, stale...).Err(); err != nil {
+			return nil, fmt.Errorf("zrem %s: %w", index, err)
+		}
+	}
+	return conversations, nil
+}
+
+// ClearAgent deletes the conversation history, summary locks, and cached
+// embeddings of every conversation with the given agent.
+func (s *ShortTermStore) ClearAgent(ctx context.Context, agentID uuid.UUID) error {
+	if err := s.client.Del(ctx, convIndexKey(agentID)).Err(); err != nil {
+		return fmt.Errorf("del %s: %w", convIndexKey(agentID), err)
+	}
+	for _, pattern := range []string{
+		convKey(agentID, "*"),
+		summaryLockKey(agentID, "*"),
+		embeddingKey(agentID, "*"),
+	} {
+		iter := s.client.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			if err := s.client.Del(ctx, iter.Val()).Err(); err != nil {
+				return fmt.Errorf("del %s: %w", iter.Val(), err)
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("scanning %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// trimIfUnchangedScript drops the first len(ARGV) entries of the list only if
+// they still match ARGV, so a concurrent append-and-trim can't make us remove
+// turns that were never read.
+var trimIfUnchangedScript = redis.NewScript(`
+local head = redis.call('LRANGE', KEYS[1], 0, #ARGV - 1)
+if #head ~= #ARGV then
+	return 0
+end
+for i = 1, #ARGV do
+	if head[i] ~= ARGV[i] then
+		return 0
+	end
+end
+redis.call('LTRIM', KEYS[1], #ARGV, -1)
+return 1
+`)
+
+// CountMessages returns the number of buffered entries for the given agent+user pair.
+func (s *ShortTermStore) CountMessages(ctx context.Context, agentID uuid.UUID, userJID string) (int64, error) {
+	key := convKey(agentID, userJID)
+	n, err := s.client.LLen(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("llen %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// GetOldestMessages returns up to `limit` entries from the start of the
+// conversation. raw holds the stored encodings of every entry read, including
+// malformed ones, for passing to TrimOldest.
+func (s *ShortTermStore) GetOldestMessages(ctx context.Context, agentID uuid.UUID, userJID string, limit int) (entries []ConversationEntry, raw []string, err error) {
+	key := convKey(agentID, userJID)
+
+	raw, err = s.client.LRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("lrange %s: %w", key, err)
+	}
+
+	entries = make([]ConversationEntry, 0, len(raw))
+	for _, v := range raw {
+		var entry ConversationEntry
+		if err := json.Unmarshal([]byte(v), &entry); err != nil {
+			continue // skip malformed entries
+		}
+		entries = append(entries, entry)
+	}
+	return entries, raw, nil
+}
+
+// TrimOldest removes raw from the start of the conversation, as returned by
+// GetOldestMessages. It reports false and leaves the list alone if those
+// entries are no longer at the start, e.g. because they were trimmed already.
+func (s *ShortTermStore) TrimOldest(ctx context.Context, agentID uuid.UUID, userJID string, raw []string) (bool, error) {
+	if len(raw) == 0 {
+		return false, nil
+	}
 	key := convKey(agentID, userJID)
-	return s.client.Del(ctx, key).Err()
+
+	args := make([]any, len(raw))
+	for i, v := range raw {
+		args[i] = v
+	}
+	n, err := trimIfUnchangedScript.Run(ctx, s.client, []string{key}, args...).Int()
+	if err != nil {
+		return false, fmt.Errorf("trimming %s: %w", key, err)
+	}
+	return n == 1, nil
+}
+
+func summaryLockKey(agentID uuid.UUID, userJID string) string {
+	return fmt.Sprintf("conv:summarize:%s:%s", agentID.String(), userJID)
+}
+
+// AcquireSummaryLock claims the right to summarize the given conversation for
+// ttl, so concurrent results (or API replicas) don't summarize the same turns.
+func (s *ShortTermStore) AcquireSummaryLock(ctx context.Context, agentID uuid.UUID, userJID string, ttl time.Duration) (bool, error) {
+	key := summaryLockKey(agentID, userJID)
+	ok, err := s.client.SetNX(ctx, key, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("setnx %s: %w", key, err)
+	}
+	return ok, nil
+}
+
+// ReleaseSummaryLock releases a lock taken with AcquireSummaryLock.
+func (s *ShortTermStore) ReleaseSummaryLock(ctx context.Context, agentID uuid.UUID, userJID string) error {
+	return s.client.Del(ctx, summaryLockKey(agentID, userJID)).Err()
+}
+
+func embeddingKey(agentID uuid.UUID, userJID string) string {
+	return fmt.Sprintf("conv:embedding:%s:%s", agentID.String(), userJID)
+}
+
+// cachedEmbedding is the last query embedding for a conversation and the hash
+// of the text (and model) it was computed from.
+type cachedEmbedding struct {
+	Key       string    `json:"key"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// GetCachedEmbedding returns the cached embedding for the given agent+user
+// pair if it was stored under key, or nil.
+func (s *ShortTermStore) GetCachedEmbedding(ctx context.Context, agentID uuid.UUID, userJID, key string) ([]float32, error) {
+	redisKey := embeddingKey(agentID, userJID)
+	data, err := s.client.Get(ctx, redisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", redisKey, err)
+	}
+
+	var cached cachedEmbedding
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Key != key {
+		return nil, nil
+	}
+	return cached.Embedding, nil
+}
+
+// SetCachedEmbedding replaces the cached embedding for the given agent+user pair.
+func (s *ShortTermStore) SetCachedEmbedding(ctx context.Context, agentID uuid.UUID, userJID, key string, embedding []float32, ttl time.Duration) error {
+	redisKey := embeddingKey(agentID, userJID)
+	data, err := json.Marshal(cachedEmbedding{Key: key, Embedding: embedding})
+	if err != nil {
+		return fmt.Errorf("marshaling embedding: %w", err)
+	}
+	return s.client.Set(ctx, redisKey, data, ttl).Err()
 }
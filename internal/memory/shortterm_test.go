@@ -116,6 +116,59 @@ func TestShortTermStore_Clear(t *testing.T) {
 	assert.Empty(t, msgs)
 }
 
+func TestShortTermStore_ClearAgent(t *testing.T) {
+	store, mr := setupMiniredis(t)
+	ctx := context.Background()
+	agentID, otherID := uuid.New(), uuid.New()
+
+	for _, id := range []uuid.UUID{agentID, otherID} {
+		for _, jid := range []string{"alice@example.com", "bob@example.com"} {
+			require.NoError(t, store.AppendMessage(ctx, id, jid, ConversationEntry{Role: "user", Content: "Hello"}, 20, 3600))
+		}
+	}
+	ok, err := store.AcquireSummaryLock(ctx, agentID, "alice@example.com", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, store.ClearAgent(ctx, agentID))
+
+	assert.Len(t, mr.Keys(), 3, "only the other agent's conversations and index remain")
+	msgs, err := store.GetRecentMessages(ctx, otherID, "bob@example.com", 10)
+	require.NoError(t, err)
+	assert.Len(t, msgs, 1)
+}
+
+func TestShortTermStore_ListConversations(t *testing.T) {
+	store, mr := setupMiniredis(t)
+	ctx := context.Background()
+	agentID := uuid.New()
+
+	entry := ConversationEntry{Role: "user", Content: "Hello"}
+	require.NoError(t, store.AppendMessage(ctx, agentID, "alice@example.com/phone", entry, 20, 60))
+	require.NoError(t, store.AppendMessage(ctx, agentID, "alice@example.com/phone", entry, 20, 60))
+	require.NoError(t, store.AppendMessage(ctx, agentID, "bob@example.com", entry, 20, 3600))
+	require.NoError(t, store.AppendMessage(ctx, agentID, "carol@example.com", entry, 20, 3600))
+	require.NoError(t, store.AppendMessage(ctx, uuid.New(), "dave@example.com", entry, 20, 3600))
+
+	convs, err := store.ListConversations(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, convs, 3)
+	assert.Equal(t, "alice@example.com/phone", convs[2].UserJID, "the soonest to expire is listed last")
+	assert.Equal(t, int64(2), convs[2].MessageCount)
+
+	require.NoError(t, store.ClearConversation(ctx, agentID, "carol@example.com"))
+	// Bob's buffer disappears without going through the store.
+	mr.Del(convKey(agentID, "bob@example.com"))
+
+	convs, err = store.ListConversations(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, convs, 1)
+	assert.Equal(t, "alice@example.com/phone", convs[0].UserJID)
+	members, err := mr.ZMembers(convIndexKey(agentID))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@example.com/phone"}, members, "stale entries are evicted from the index")
+}
+
 func TestShortTermStore_GetEmptyReturnsEmpty(t *testing.T) {
 	store, _ := setupMiniredis(t)
 	ctx := context.Background()
@@ -165,3 +218,61 @@ func TestShortTermStore_IsolatedByAgentAndUser(t *testing.T) {
 	assert.Len(t, msgs, 1)
 	assert.Equal(t, "A2U1", msgs[0].Content)
 }
+
+func TestShortTermStore_TrimOldest(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	ctx := context.Background()
+	agentID := uuid.New()
+	userJID := "user@example.com"
+
+	for _, content := range []string{"a", "b", "c"} {
+		err := store.AppendMessage(ctx, agentID, userJID, ConversationEntry{Role: "user", Content: content, Timestamp: time.Now()}, 3, 3600)
+		require.NoError(t, err)
+	}
+
+	entries, raw, err := store.GetOldestMessages(ctx, agentID, userJID, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Content)
+
+	// A new message pushes "a" out of the capped list, so the read batch is stale.
+	err = store.AppendMessage(ctx, agentID, userJID, ConversationEntry{Role: "user", Content: "d", Timestamp: time.Now()}, 3, 3600)
+	require.NoError(t, err)
+	trimmed, err := store.TrimOldest(ctx, agentID, userJID, raw)
+	require.NoError(t, err)
+	assert.False(t, trimmed)
+
+	n, err := store.CountMessages(ctx, agentID, userJID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	_, raw, err = store.GetOldestMessages(ctx, agentID, userJID, 2)
+	require.NoError(t, err)
+	trimmed, err = store.TrimOldest(ctx, agentID, userJID, raw)
+	require.NoError(t, err)
+	assert.True(t, trimmed)
+
+	remaining, err := store.GetRecentMessages(ctx, agentID, userJID, 10)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "d", remaining[0].Content)
+}
+
+func TestShortTermStore_SummaryLock(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	ctx := context.Background()
+	agentID := uuid.New()
+
+	ok, err := store.AcquireSummaryLock(ctx, agentID, "user@example.com", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.AcquireSummaryLock(ctx, agentID, "user@example.com", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.ReleaseSummaryLock(ctx, agentID, "user@example.com"))
+	ok, err = store.AcquireSummaryLock(ctx, agentID, "user@example.com", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
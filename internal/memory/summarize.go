@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SummaryMemoryType is the memory_type of long-term memories produced by
+// conversation summarization.
+const SummaryMemoryType = "summary"
+
+// summaryLockTTL bounds how long a crashed summarization can block the next one.
+const summaryLockTTL = 5 * time.Minute
+
+// SummaryTask is a batch of conversation turns to condense into one memory.
+type SummaryTask struct {
+	AgentID     uuid.UUID
+	OwnerUserID uuid.UUID
+	UserJID     string
+	LLMConfig   json.RawMessage
+	// EmbeddingModel is the model the summary must be embedded with.
+	EmbeddingModel string
+	Turns          []ConversationEntry
+}
+
+// Summary is the condensed form of a SummaryTask, embedded for similarity search.
+type Summary struct {
+	Content   string
+	Embedding []float32
+	Metadata  json.RawMessage
+}
+
+// Summarizer turns conversation turns into a Summary, typically by handing
+// them to a worker. Summarize blocks until the summary is ready.
+type Summarizer interface {
+	Summarize(ctx context.Context, task SummaryTask) (*Summary, error)
+}
+
+// SetSummarizer enables SummarizeConversation. Without one, auto-summarization is a no-op.
+func (s *Service) SetSummarizer(summarizer Summarizer) {
+	s.summarizer = summarizer
+}
+
+// SummarizeConversation promotes the oldest short-term turns of a conversation
+// to long-term memory once more than cfg.SummarizeThreshold are buffered. The
+// oldest cfg.SummarizeBatch entries are summarized, stored via
+// StoreLongTermMemory, and then trimmed from Redis. It reports whether a
+// summary was stored; turns are only trimmed after a successful store.
+func (s *Service) SummarizeConversation(
+	ctx context.Context,
+	agentID, ownerUserID uuid.UUID,
+	userJID string,
+	llmConfig json.RawMessage,
+	cfg MemoryConfig,
+) (bool, error) {
+	if !cfg.AutoSummarize || !cfg.ShortTermEnabled || !cfg.LongTermEnabled || s.shortTerm == nil || s.summarizer == nil {
+		return false, nil
+	}
+	if cfg.SummarizeBatch <= 0 {
+		return false, nil
+	}
+
+	count, err := s.shortTerm.CountMessages(ctx, agentID, userJID)
+	if err != nil {
+		return false, err
+	}
+	if count <= int64(cfg.SummarizeThreshold) {
+		return false, nil
+	}
+
+	locked, err := s.shortTerm.AcquireSummaryLock(ctx, agentID, userJID, summaryLockTTL)
+	if err != nil {
+		return false, err
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		if err := s.shortTerm.ReleaseSummaryLock(context.WithoutCancel(ctx), agentID, userJID); err != nil {
+			slog.Warn("memory: releasing summary lock", "error", err, "agent_id", agentID)
+		}
+	}()
+
+	turns, raw, err := s.shortTerm.GetOldestMessages(ctx, agentID, userJID, cfg.SummarizeBatch)
+	if err != nil {
+		return false, err
+	}
+	if len(turns) == 0 {
+		return false, nil
+	}
+
+	summary, err := s.summarizer.Summarize(ctx, SummaryTask{
+		AgentID:        agentID,
+		OwnerUserID:    ownerUserID,
+		UserJID:        userJID,
+		LLMConfig:      llmConfig,
+		EmbeddingModel: cfg.EmbeddingModel,
+		Turns:          turns,
+	})
+	if err != nil {
+		return false, fmt.Errorf("summarizing conversation: %w", err)
+	}
+	if summary == nil || summary.Content == "" {
+		return false, errors.New("summarizing conversation: empty summary")
+	}
+
+	metadata := summary.Metadata
+	if len(metadata) == 0 {
+		metadata, _ = json.Marshal(map[string]any{
+			"source":   "conversation_summary",
+			"user_jid": userJID,
+			"turns":    len(turns),
+			"from":     turns[0].Timestamp,
+			"to":       turns[len(turns)-1].Timestamp,
+		})
+	}
+
+	mem := &Memory{
+		ID:          uuid.New(),
+		OwnerUserID: ownerUserID,
+		AgentID:     agentID,
+		Content:     summary.Content,
+		Embedding:   summary.Embedding,
+		MemoryType:  SummaryMemoryType,
+		Metadata:    metadata,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := s.StoreLongTermMemory(ctx, mem, cfg); err != nil {
+		return false, fmt.Errorf("storing conversation summary: %w", err)
+	}
+
+	trimmed, err := s.shortTerm.TrimOldest(ctx, agentID, userJID, raw)
+	if err != nil {
+		return true, err
+	}
+	if !trimmed {
+		slog.Warn("memory: summarized turns changed before trim", "agent_id", agentID)
+	}
+	return true, nil
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSummarizer struct {
+	task SummaryTask
+	err  error
+}
+
+func (f *fakeSummarizer) Summarize(_ context.Context, task SummaryTask) (*Summary, error) {
+	f.task = task
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &Summary{Content: "user likes tea", Embedding: []float32{1, 0}}, nil
+}
+
+func summarizeConfig() MemoryConfig {
+	cfg := DefaultConfig()
+	cfg.Enabled = true
+	cfg.AutoSummarize = true
+	cfg.SummarizeThreshold = 4
+	cfg.SummarizeBatch = 3
+	cfg.EmbeddingDim = 2
+	return cfg
+}
+
+func appendTurns(t *testing.T, store *ShortTermStore, agentID uuid.UUID, userJID string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := store.AppendMessage(context.Background(), agentID, userJID, ConversationEntry{
+			Role:      "user",
+			Content:   fmt.Sprintf("msg %d", i),
+			Timestamp: time.Now(),
+		}, 20, 3600)
+		require.NoError(t, err)
+	}
+}
+
+func TestSummarizeConversation_StoresAndTrims(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	repo := &dedupRepo{}
+	svc := NewService(repo, store)
+	summarizer := &fakeSummarizer{}
+	svc.SetSummarizer(summarizer)
+	ctx := context.Background()
+	agentID, ownerID := uuid.New(), uuid.New()
+	appendTurns(t, store, agentID, "user@example.com", 5)
+
+	stored, err := svc.SummarizeConversation(ctx, agentID, ownerID, "user@example.com", []byte(`{"provider":"openai"}`), summarizeConfig())
+	require.NoError(t, err)
+	assert.True(t, stored)
+
+	require.Len(t, summarizer.task.Turns, 3)
+	assert.Equal(t, "msg 0", summarizer.task.Turns[0].Content)
+	assert.Equal(t, ownerID, summarizer.task.OwnerUserID)
+
+	require.Len(t, repo.created, 1)
+	assert.Equal(t, SummaryMemoryType, repo.created[0].MemoryType)
+	assert.Equal(t, "user likes tea", repo.created[0].Content)
+	assert.Equal(t, agentID, repo.created[0].AgentID)
+
+	remaining, err := store.GetRecentMessages(ctx, agentID, "user@example.com", 20)
+	require.NoError(t, err)
+	require.Len(t, remaining, 2)
+	assert.Equal(t, "msg 3", remaining[0].Content)
+}
+
+func TestSummarizeConversation_BelowThreshold(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	repo := &dedupRepo{}
+	svc := NewService(repo, store)
+	summarizer := &fakeSummarizer{}
+	svc.SetSummarizer(summarizer)
+	agentID := uuid.New()
+	appendTurns(t, store, agentID, "user@example.com", 4)
+
+	stored, err := svc.SummarizeConversation(context.Background(), agentID, uuid.New(), "user@example.com", nil, summarizeConfig())
+	require.NoError(t, err)
+	assert.False(t, stored)
+	assert.Empty(t, summarizer.task.Turns)
+	assert.Empty(t, repo.created)
+}
+
+func TestSummarizeConversation_Disabled(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	svc := NewService(&dedupRepo{}, store)
+	summarizer := &fakeSummarizer{}
+	svc.SetSummarizer(summarizer)
+	agentID := uuid.New()
+	appendTurns(t, store, agentID, "user@example.com", 10)
+
+	cfg := summarizeConfig()
+	cfg.AutoSummarize = false
+	stored, err := svc.SummarizeConversation(context.Background(), agentID, uuid.New(), "user@example.com", nil, cfg)
+	require.NoError(t, err)
+	assert.False(t, stored)
+	assert.Empty(t, summarizer.task.Turns)
+}
+
+func TestSummarizeConversation_FailureKeepsTurns(t *testing.T) {
+	store, _ := setupMiniredis(t)
+	repo := &dedupRepo{}
+	svc := NewService(repo, store)
+	svc.SetSummarizer(&fakeSummarizer{err: errors.New("no workers available")})
+	ctx := context.Background()
+	agentID := uuid.New()
+	appendTurns(t, store, agentID, "user@example.com", 5)
+
+	stored, err := svc.SummarizeConversation(ctx, agentID, uuid.New(), "user@example.com", nil, summarizeConfig())
+	require.Error(t, err)
+	assert.False(t, stored)
+	assert.Empty(t, repo.created)
+
+	n, err := store.CountMessages(ctx, agentID, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	// The lock is released, so a later attempt can proceed.
+	ok, err := store.AcquireSummaryLock(ctx, agentID, "user@example.com", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -8,7 +8,8 @@ var (
 			Name: "aiox_http_requests_total",
 			Help: "Total number of HTTP requests.",
 		},
-		[]string{"method", "path", "status"},
+		// agent_id is empty unless per-agent labels are enabled.
+		[]string{"method", "path", "status", "agent_id"},
 	)
 
 	HTTPRequestDuration = prometheus.NewHistogramVec(
@@ -17,7 +18,7 @@ var (
 			Help:    "HTTP request duration in seconds.",
 			Buckets: prometheus.DefBuckets,
 		},
-		[]string{"method", "path"},
+		[]string{"method", "path", "status_class", "agent_id"},
 	)
 
 	TasksDispatchedTotal = prometheus.NewCounter(
@@ -41,6 +42,99 @@ var (
 			Help: "Number of connected gRPC workers.",
 		},
 	)
+
+	ResponseCacheHitsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_response_cache_hits_total",
+			Help: "Total number of messages answered from the response cache.",
+		},
+	)
+
+	EmbeddingCacheHitsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_embedding_cache_hits_total",
+			Help: "Total number of embeddings served from the embedding cache.",
+		},
+	)
+
+	EmbeddingCacheMissesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_embedding_cache_misses_total",
+			Help: "Total number of embeddings computed because they were not in the embedding cache.",
+		},
+	)
+
+	DBPoolConns = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "aiox_db_pool_conns",
+			Help: "Number of Postgres pool connections, by state (acquired, idle, total).",
+		},
+		[]string{"state"},
+	)
+
+	DBPoolMaxConns = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "aiox_db_pool_max_conns",
+			Help: "Maximum size of the Postgres pool.",
+		},
+	)
+
+	DBPoolAcquiresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_db_pool_acquires_total",
+			Help: "Total number of connections acquired from the Postgres pool.",
+		},
+	)
+
+	DBPoolEmptyAcquiresTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_db_pool_empty_acquires_total",
+			Help: "Total number of acquires that waited because the Postgres pool had no idle connection.",
+		},
+	)
+
+	DBPoolAcquireWaitSeconds = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_db_pool_acquire_wait_seconds_total",
+			Help: "Total time spent acquiring connections from the Postgres pool.",
+		},
+	)
+
+	NATSPublishBuffered = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "aiox_nats_publish_buffered",
+			Help: "Number of messages buffered while NATS is disconnected.",
+		},
+	)
+
+	NATSPublishDroppedTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_nats_publish_dropped_total",
+			Help: "Total number of messages dropped because the NATS publish buffer was full.",
+		},
+	)
+
+	TasksDeadLetteredTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_tasks_dead_lettered_total",
+			Help: "Total number of tasks moved to the dead-letter stream after exceeding their delivery limit.",
+		},
+	)
+
+	TasksHeldTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "aiox_tasks_held_total",
+			Help: "Total number of tasks held because a dependency was unhealthy.",
+		},
+		[]string{"dependency"},
+	)
+
+	ChunkBufferOverflowsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "aiox_worker_chunk_buffer_overflows_total",
+			Help: "Total number of requests whose streaming chunk buffer overflowed and fell back to the final response.",
+		},
+	)
 )
 
 func init() {
@@ -50,5 +144,18 @@ func init() {
 		TasksDispatchedTotal,
 		TasksCompletedTotal,
 		WorkerPoolConnected,
+		ResponseCacheHitsTotal,
+		EmbeddingCacheHitsTotal,
+		EmbeddingCacheMissesTotal,
+		ChunkBufferOverflowsTotal,
+		TasksDeadLetteredTotal,
+		TasksHeldTotal,
+		NATSPublishBuffered,
+		NATSPublishDroppedTotal,
+		DBPoolConns,
+		DBPoolMaxConns,
+		DBPoolAcquiresTotal,
+		DBPoolEmptyAcquiresTotal,
+		DBPoolAcquireWaitSeconds,
 	)
 }
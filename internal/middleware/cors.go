@@ -1,31 +1,59 @@
 package middleware
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/go-chi/cors"
 )
 
-// CORS returns cors.Options parameterized by the given allowed origins.
-// If "*" is present, AllowCredentials is set to false (browsers reject
-// Access-Control-Allow-Credentials: true with a wildcard origin).
-func CORS(allowedOrigins []string) cors.Options {
-	if len(allowedOrigins) == 0 {
-		allowedOrigins = []string{"http://localhost:3000"}
+const requestIDHeader = "X-Request-ID"
+
+// CORSConfig configures cross-origin access for browser clients. Empty lists
+// fall back to the defaults used by the API's own endpoints.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is how long, in seconds, browsers may cache a preflight response.
+	MaxAge int
+}
+
+// CORS returns cors.Options for cfg. X-Request-ID is always exposed so browser
+// clients can quote it in support tickets. Config validation rejects
+// AllowCredentials with a "*" origin, which browsers refuse.
+func CORS(cfg CORSConfig) cors.Options {
+	origins := cfg.AllowedOrigins
+	if len(origins) == 0 {
+		origins = []string{"http://localhost:3000"}
 	}
 
-	allowCreds := true
-	for _, o := range allowedOrigins {
-		if o == "*" {
-			allowCreds = false
-			break
-		}
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	}
+
+	headers := cfg.AllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"Accept", "Authorization", "Content-Type", "If-Match", requestIDHeader, IdempotencyKeyHeader}
+	}
+
+	exposed := cfg.ExposedHeaders
+	if len(exposed) == 0 {
+		exposed = []string{"ETag", IdempotentReplayHeader}
+	}
+	if !slices.ContainsFunc(exposed, func(h string) bool { return strings.EqualFold(h, requestIDHeader) }) {
+		exposed = append(slices.Clip(exposed), requestIDHeader)
 	}
 
 	return cors.Options{
-		AllowedOrigins:   allowedOrigins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"},
-		ExposedHeaders:   []string{"X-Request-ID"},
-		AllowCredentials: allowCreds,
-		MaxAge:           300,
+		AllowedOrigins:   origins,
+		AllowedMethods:   methods,
+		AllowedHeaders:   headers,
+		ExposedHeaders:   exposed,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
 	}
 }
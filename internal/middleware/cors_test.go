@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCORS_Defaults(t *testing.T) {
+	opts := CORS(CORSConfig{})
+	if !reflect.DeepEqual(opts.AllowedOrigins, []string{"http://localhost:3000"}) {
+		t.Errorf("unexpected default origins %v", opts.AllowedOrigins)
+	}
+	if len(opts.AllowedMethods) == 0 || len(opts.AllowedHeaders) == 0 {
+		t.Error("expected default methods and headers")
+	}
+	if opts.AllowCredentials {
+		t.Error("credentials should only be allowed when configured")
+	}
+	if opts.ExposedHeaders[len(opts.ExposedHeaders)-1] != requestIDHeader {
+		t.Errorf("expected %s to be exposed, got %v", requestIDHeader, opts.ExposedHeaders)
+	}
+}
+
+func TestCORS_Configured(t *testing.T) {
+	exposed := make([]string, 1, 4)
+	exposed[0] = "X-Custom"
+	opts := CORS(CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "PATCH"},
+		AllowedHeaders:   []string{"Authorization"},
+		ExposedHeaders:   exposed,
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+
+	if !reflect.DeepEqual(opts.AllowedMethods, []string{"GET", "PATCH"}) {
+		t.Errorf("unexpected methods %v", opts.AllowedMethods)
+	}
+	if !reflect.DeepEqual(opts.ExposedHeaders, []string{"X-Custom", requestIDHeader}) {
+		t.Errorf("unexpected exposed headers %v", opts.ExposedHeaders)
+	}
+	if exposed[:2][1] != "" {
+		t.Error("configured slice must not be modified")
+	}
+	if !opts.AllowCredentials || opts.MaxAge != 600 {
+		t.Errorf("expected credentials and max age 600, got %v %d", opts.AllowCredentials, opts.MaxAge)
+	}
+
+	// An explicitly exposed X-Request-ID is not duplicated
+	opts = CORS(CORSConfig{ExposedHeaders: []string{"x-request-id"}})
+	if len(opts.ExposedHeaders) != 1 {
+		t.Errorf("expected no duplicate, got %v", opts.ExposedHeaders)
+	}
+}
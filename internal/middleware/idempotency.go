@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key for a create request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader is set on responses replayed from a stored key.
+	IdempotentReplayHeader = "Idempotent-Replay"
+
+	// IdempotencyTTL is how long a key and its response are remembered.
+	IdempotencyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotencyRecord is what is stored in Redis under an idempotency key. While
+// the first request is still running, Pending is true and no response is
+// stored yet.
+type idempotencyRecord struct {
+	Fingerprint string          `json:"fingerprint"`
+	Pending     bool            `json:"pending,omitempty"`
+	ContentType string          `json:"content_type,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// Idempotency makes create endpoints safe to retry. A request carrying an
+// Idempotency-Key header runs once per user and key; repeats with the same
+// body replay the original response with 200 and Idempotent-Replay: true,
+// and a repeat with a different body is rejected with 409.
+type Idempotency struct {
+	client redis.Cmdable
+	userID func(*http.Request) string
+	ttl    time.Duration
+}
+
+// NewIdempotency creates the middleware. userID returns the authenticated
+// caller for a request; requests without a user are passed through.
+func NewIdempotency(client redis.Cmdable, userID func(*http.Request) string) *Idempotency {
+	return &Idempotency{client: client, userID: userID, ttl: IdempotencyTTL}
+}
+
+// Middleware returns an HTTP middleware enforcing idempotency keys. Requests
+// without the header are unaffected. Only successful responses are stored, so
+// a failed request can be retried with the same key. On Redis errors it fails
+// open (runs the request without idempotency).
+func (m *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idemKey := r.Header.Get(IdempotencyKeyHeader)
+		if idemKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(idemKey) > maxIdempotencyKeyLen {
+			writeJSONError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		userID := m.userID(r)
+		if userID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "bad request")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := "idempotency:" + userID + ":" + idemKey
+		fingerprint := requestFingerprint(r, body)
+
+		claimed, err := m.claim(r.Context(), key, fingerprint)
+		if err != nil {
+			slog.Warn("idempotency: redis error, failing open", "error", err, "key", idemKey)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !claimed {
+			m.replay(w, r, key, fingerprint)
+			return
+		}
+
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Use a fresh context: the request's may be cancelled once the
+		// response is written, and the pending marker must not linger.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 2*time.Second)
+		defer cancel()
+		if rec.status < 200 || rec.status >= 300 {
+			if err := m.client.Del(ctx, key).Err(); err != nil {
+				slog.Warn("idempotency: releasing key", "error", err, "key", idemKey)
+			}
+			return
+		}
+		if err := m.store(ctx, key, idempotencyRecord{
+			Fingerprint: fingerprint,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		}); err != nil {
+			slog.Warn("idempotency: storing response", "error", err, "key", idemKey)
+		}
+	})
+}
+
+// claim reserves key for this request with a pending record. It returns false
+// when the key is already taken.
+func (m *Idempotency) claim(ctx context.Context, key, fingerprint string) (bool, error) {
+	data, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint, Pending: true})
+	if err != nil {
+		return false, err
+	}
+	return m.client.SetNX(ctx, key, data, m.ttl).Result()
+}
+
+func (m *Idempotency) store(ctx context.Context, key string, rec idempotencyRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return m.client.Set(ctx, key, data, m.ttl).Err()
+}
+
+// replay answers a request whose key was already claimed.
+func (m *Idempotency) replay(w http.ResponseWriter, r *http.Request, key, fingerprint string) {
+	data, err := m.client.Get(r.Context(), key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The first request failed and released the key in between.
+		writeJSONError(w, http.StatusConflict, "a request with this Idempotency-Key is in progress")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "idempotency: loading key", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	var rec idempotencyRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		slog.ErrorContext(r.Context(), "idempotency: decoding record", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if rec.Fingerprint != fingerprint {
+		writeJSONError(w, http.StatusConflict, "Idempotency-Key was already used with a different request")
+		return
+	}
+	if rec.Pending {
+		writeJSONError(w, http.StatusConflict, "a request with this Idempotency-Key is in progress")
+		return
+	}
+
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set(IdempotentReplayHeader, "true")
+	w.WriteHeader(http.StatusOK)
+	w.Write(rec.Body)
+}
+
+// requestFingerprint identifies a request by method, path and body, so a key
+// reused on another endpoint also counts as a mismatch.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\x00"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter passes the response through while keeping a copy of the
+// status and body.
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.status = code
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
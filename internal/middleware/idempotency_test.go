@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// countingHandler answers 201 with a body that changes on every call.
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	status := h.status
+	if status == 0 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(`{"id":"` + strings.Repeat("a", h.calls) + `"}`))
+}
+
+func setupIdempotency(t *testing.T) (*Idempotency, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewIdempotency(client, func(r *http.Request) string { return r.Header.Get("X-User") }), mr
+}
+
+func idempotentRequest(user, key, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/api/v1/agents", strings.NewReader(body))
+	req.Header.Set("X-User", user)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotency_ReplaysOriginalResponse(t *testing.T) {
+	m, mr := setupIdempotency(t)
+	next := &countingHandler{}
+	handler := m.Middleware(next)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u1", "k1", `{"name":"a"}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("first request: expected 201, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u1", "k1", `{"name":"a"}`))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("replay: expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get(IdempotentReplayHeader) != "true" {
+		t.Fatalf("expected %s: true", IdempotentReplayHeader)
+	}
+	if rec.Body.String() != `{"id":"a"}` {
+		t.Fatalf("expected original body, got %q", rec.Body.String())
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", next.calls)
+	}
+	if ttl := mr.TTL("idempotency:u1:k1"); ttl != IdempotencyTTL {
+		t.Fatalf("expected TTL %v, got %v", IdempotencyTTL, ttl)
+	}
+}
+
+func TestIdempotency_DifferentBodyConflicts(t *testing.T) {
+	m, _ := setupIdempotency(t)
+	next := &countingHandler{}
+	handler := m.Middleware(next)
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("u1", "k1", `{"name":"a"}`))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u1", "k1", `{"name":"b"}`))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+	if next.calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_KeysArePerUser(t *testing.T) {
+	m, _ := setupIdempotency(t)
+	next := &countingHandler{}
+	handler := m.Middleware(next)
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("u1", "k1", `{}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u2", "k1", `{}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for another user, got %d", rec.Code)
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_FailureReleasesKey(t *testing.T) {
+	m, mr := setupIdempotency(t)
+	next := &countingHandler{status: http.StatusInternalServerError}
+	handler := m.Middleware(next)
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest("u1", "k1", `{}`))
+	if mr.Exists("idempotency:u1:k1") {
+		t.Fatal("expected failed request to release its key")
+	}
+
+	next.status = 0
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u1", "k1", `{}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected retry to run, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_PendingConflicts(t *testing.T) {
+	m, mr := setupIdempotency(t)
+	next := &countingHandler{}
+	handler := m.Middleware(next)
+
+	fp := requestFingerprint(idempotentRequest("u1", "k1", `{}`), []byte(`{}`))
+	mr.Set("idempotency:u1:k1", `{"fingerprint":"`+fp+`","pending":true}`)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u1", "k1", `{}`))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while in progress, got %d", rec.Code)
+	}
+	if next.calls != 0 {
+		t.Fatalf("expected handler not to run, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_NoHeaderPassesThrough(t *testing.T) {
+	m, _ := setupIdempotency(t)
+	next := &countingHandler{}
+	handler := m.Middleware(next)
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, idempotentRequest("u1", "", `{}`))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: expected 201, got %d", i+1, rec.Code)
+		}
+	}
+	if next.calls != 2 {
+		t.Fatalf("expected handler to run twice, ran %d times", next.calls)
+	}
+}
+
+func TestIdempotency_FailsOpenOnRedisError(t *testing.T) {
+	m, mr := setupIdempotency(t)
+	next := &countingHandler{}
+	handler := m.Middleware(next)
+	mr.Close()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest("u1", "k1", `{}`))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 when redis is down, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const oneMB = 1 << 20
+
+func echoBody(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Write(body)
+}
+
+func TestMaxBodySize_RejectsOversizedBody(t *testing.T) {
+	handler := MaxBodySize(func(*http.Request) int64 { return oneMB })(http.HandlerFunc(echoBody))
+	big := bytes.Repeat([]byte("a"), 2*oneMB)
+
+	// Declared Content-Length is rejected without reading the body.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(big)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "1048576 byte limit") {
+		t.Errorf("expected limit in message, got %s", rec.Body.String())
+	}
+
+	// Chunked bodies without a length are cut off while reading.
+	req := httptest.NewRequest("POST", "/", io.MultiReader(bytes.NewReader(big)))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for chunked body, got %d", rec.Code)
+	}
+}
+
+func TestMaxBodySize_PassesBodyWithinLimit(t *testing.T) {
+	handler := MaxBodySize(func(*http.Request) int64 { return oneMB })(http.HandlerFunc(echoBody))
+	body := bytes.Repeat([]byte("b"), oneMB)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), body) {
+		t.Error("handler did not receive the full body")
+	}
+}
+
+func TestMaxBodySize_PerRouteLimit(t *testing.T) {
+	handler := MaxBodySize(func(r *http.Request) int64 {
+		if strings.HasSuffix(r.URL.Path, "/bulk") {
+			return 4 * oneMB
+		}
+		return oneMB
+	})(http.HandlerFunc(echoBody))
+	body := bytes.Repeat([]byte("c"), 2*oneMB)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/memories/bulk", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected bulk route to accept 2MB, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/memories", bytes.NewReader(body)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected default route to reject 2MB, got %d", rec.Code)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	})
+	handler := Timeout(20*time.Millisecond, func(r *http.Request) bool {
+		return r.URL.Path == "/stream"
+	})(slow)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "request timed out") {
+		t.Errorf("unexpected body %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected skipped route to run to completion, got %d", rec.Code)
+	}
+}
@@ -1,8 +1,17 @@
 package middleware
 
 import (
+	"bufio"
+	"context"
+	"fmt"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,22 +25,180 @@ func (w *wrappedWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
-func Logging(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		wrapped := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+// Hijack lets WebSocket upgrades pass through the logging wrapper.
+func (w *wrappedWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *wrappedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not implement http.Hijacker")
+	}
+	return hj.Hijack()
+}
+
+// Request log fields selectable with LoggingConfig.Fields. FieldLatency is
+// logged as duration_ms.
+const (
+	FieldMethod     = "method"
+	FieldPath       = "path"
+	FieldStatus     = "status"
+	FieldLatency    = "latency"
+	FieldUserID     = "user_id"
+	FieldRequestID  = "request_id"
+	FieldRemoteAddr = "remote_addr"
+	FieldHeaders    = "headers"
+)
+
+// DefaultLogFields are logged when LoggingConfig.Fields is empty. Request
+// headers are opt-in.
+var DefaultLogFields = []string{FieldMethod, FieldPath, FieldStatus, FieldLatency, FieldUserID, FieldRequestID, FieldRemoteAddr}
+
+const redacted = "[REDACTED]"
+
+// LoggingConfig controls the per-request log line.
+type LoggingConfig struct {
+	// Fields lists what is logged; empty means DefaultLogFields.
+	Fields []string
+	// Exclude removes fields from Fields.
+	Exclude []string
+	// RedactHeaders are logged as "[REDACTED]" when headers are logged.
+	// Authorization is always redacted.
+	RedactHeaders []string
+	// SampleRate logs one in SampleRate requests that end below 400.
+	// Errors are always logged. Zero or one logs every request.
+	SampleRate int
+}
+
+// requestLog carries what Logging and ContextHandler attach to log records.
+// The user is only known once authentication has run further down the chain.
+type requestLog struct {
+	mu     sync.Mutex
+	userID string
+}
+
+const requestLogKey contextKey = "request_log"
+
+// SetLogUser records the authenticated user for the request's log line and
+// for records logged with its context. It is a no-op outside Logging.
+func SetLogUser(ctx context.Context, userID string) {
+	if l, ok := ctx.Value(requestLogKey).(*requestLog); ok {
+		l.mu.Lock()
+		l.userID = userID
+		l.mu.Unlock()
+	}
+}
 
-		next.ServeHTTP(wrapped, r)
+func logUser(ctx context.Context) string {
+	l, ok := ctx.Value(requestLogKey).(*requestLog)
+	if !ok {
+		return ""
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.userID
+}
+
+// Logging logs one line per request with the fields selected by cfg.
+func Logging(cfg LoggingConfig) func(http.Handler) http.Handler {
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = DefaultLogFields
+	}
+	fields = slices.DeleteFunc(slices.Clone(fields), func(f string) bool { return slices.Contains(cfg.Exclude, f) })
+	redact := map[string]bool{"Authorization": true}
+	for _, h := range cfg.RedactHeaders {
+		redact[http.CanonicalHeaderKey(h)] = true
+	}
+	var successes atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			wrapped := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			l := &requestLog{}
+			r = r.WithContext(context.WithValue(r.Context(), requestLogKey, l))
+
+			next.ServeHTTP(wrapped, r)
+
+			if wrapped.statusCode < 400 && cfg.SampleRate > 1 && (successes.Add(1)-1)%uint64(cfg.SampleRate) != 0 {
+				return
+			}
+
+			attrs := make([]slog.Attr, 0, len(fields))
+			for _, f := range fields {
+				switch f {
+				case FieldMethod:
+					attrs = append(attrs, slog.String("method", r.Method))
+				case FieldPath:
+					attrs = append(attrs, slog.String("path", r.URL.Path))
+				case FieldStatus:
+					attrs = append(attrs, slog.Int("status", wrapped.statusCode))
+				case FieldLatency:
+					attrs = append(attrs, slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+				case FieldUserID:
+					if id := logUser(r.Context()); id != "" {
+						attrs = append(attrs, slog.String("user_id", id))
+					}
+				case FieldRequestID:
+					attrs = append(attrs, slog.String("request_id", GetRequestID(r.Context())))
+				case FieldRemoteAddr:
+					attrs = append(attrs, slog.String("remote_addr", r.RemoteAddr))
+				case FieldHeaders:
+					attrs = append(attrs, headerAttrs(r.Header, redact))
+				}
+			}
+			// Logged without the request context, so ContextHandler does
+			// not add back fields that were left out.
+			slog.LogAttrs(context.Background(), slog.LevelInfo, "request", attrs...)
+		})
+	}
+}
+
+func headerAttrs(h http.Header, redact map[string]bool) slog.Attr {
+	names := slices.Sorted(maps.Keys(h))
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(h[name], ", ")
+		if redact[name] {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// ContextHandler adds the request ID and authenticated user ID to records
+// logged with a request context, e.g. slog.ErrorContext(r.Context(), ...).
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps h.
+func NewContextHandler(h slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: h}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, rec slog.Record) error {
+	if id := GetRequestID(ctx); id != "" {
+		rec.AddAttrs(slog.String("request_id", id))
+	}
+	if id := logUser(ctx); id != "" {
+		rec.AddAttrs(slog.String("user_id", id))
+	}
+	return h.Handler.Handle(ctx, rec)
+}
 
-		duration := time.Since(start)
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
 
-		slog.Info("request",
-			"method", r.Method,
-			"path", r.URL.Path,
-			"status", wrapped.statusCode,
-			"duration_ms", duration.Milliseconds(),
-			"remote_addr", r.RemoteAddr,
-			"request_id", r.Context().Value(requestIDKey),
-		)
-	})
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
 }
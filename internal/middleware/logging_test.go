@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// captureLogs routes the default logger, wrapped in a ContextHandler, into a
+// buffer for the duration of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("decoding log line %q: %v", line, err)
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestLogging_FieldsAndRedaction(t *testing.T) {
+	buf := captureLogs(t)
+	handler := RequestID(Logging(LoggingConfig{
+		Fields:        []string{FieldMethod, FieldStatus, FieldUserID, FieldHeaders, FieldRequestID},
+		Exclude:       []string{FieldRequestID},
+		RedactHeaders: []string{"x-api-key"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetLogUser(r.Context(), "user-1")
+		slog.InfoContext(r.Context(), "handled")
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/agents", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Api-Key", "secret")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Request-ID", "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := logLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf)
+	}
+
+	handled := lines[0]
+	if handled["request_id"] != "req-1" || handled["user_id"] != "user-1" {
+		t.Errorf("handler log should carry request and user IDs, got %v", handled)
+	}
+
+	request := lines[1]
+	if request["method"] != "POST" || request["status"] != float64(http.StatusCreated) || request["user_id"] != "user-1" {
+		t.Errorf("unexpected request log %v", request)
+	}
+	for _, field := range []string{"path", "duration_ms", "request_id", "remote_addr"} {
+		if _, ok := request[field]; ok {
+			t.Errorf("field %s should not be logged", field)
+		}
+	}
+	headers, _ := request["headers"].(map[string]any)
+	if headers["Authorization"] != redacted || headers["X-Api-Key"] != redacted {
+		t.Errorf("credentials should be redacted, got %v", headers)
+	}
+	if headers["Accept"] != "application/json" {
+		t.Errorf("other headers should be logged as sent, got %v", headers)
+	}
+}
+
+func TestLogging_Sampling(t *testing.T) {
+	buf := captureLogs(t)
+	status := http.StatusOK
+	handler := Logging(LoggingConfig{SampleRate: 3})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	for range 6 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	status = http.StatusInternalServerError
+	for range 2 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	var ok, failed int
+	for _, rec := range logLines(t, buf) {
+		if rec["status"] == float64(http.StatusOK) {
+			ok++
+		} else {
+			failed++
+		}
+	}
+	if ok != 2 || failed != 2 {
+		t.Errorf("expected 2 of 6 successes and every error logged, got %d and %d", ok, failed)
+	}
+}
@@ -1,33 +1,93 @@
 package middleware
 
 import (
+	"bufio"
+	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 
 	"github.com/aiox-platform/aiox/internal/metrics"
 )
 
-// Metrics records HTTP request count and latency as Prometheus metrics.
-func Metrics(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		ww := &statusWriter{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(ww, r)
-
-		// Use the route pattern for a low-cardinality path label.
-		path := "unknown"
-		if rctx := chi.RouteContext(r.Context()); rctx != nil {
-			if pat := rctx.RoutePattern(); pat != "" {
-				path = pat
+// DefaultMaxAgentLabels caps distinct agent_id label values when
+// MetricsConfig.MaxAgents is zero.
+const DefaultMaxAgentLabels = 100
+
+// otherAgents labels requests for agents beyond the agent_id cap.
+const otherAgents = "other"
+
+// MetricsConfig controls the labels on HTTP metrics.
+type MetricsConfig struct {
+	// AgentLabel labels requests to /agents/{agentID} routes with the agent
+	// ID. Every agent adds a series per route and status, so this is only
+	// suitable for deployments with few agents.
+	AgentLabel bool
+	// MaxAgents caps the distinct agent_id values; requests for agents seen
+	// after the cap is reached are labeled "other".
+	MaxAgents int
+}
+
+// Metrics records HTTP request count and latency as Prometheus metrics. Paths
+// are labeled with the matched route pattern rather than the concrete path,
+// and latency with the status class, to keep the series count bounded.
+func Metrics(cfg MetricsConfig) func(http.Handler) http.Handler {
+	agents := &agentLabels{max: cfg.MaxAgents, seen: make(map[string]struct{})}
+	if agents.max <= 0 {
+		agents.max = DefaultMaxAgentLabels
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(ww, r)
+
+			path := "unknown"
+			var agentID string
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pat := rctx.RoutePattern(); pat != "" {
+					path = pat
+				}
+				if cfg.AgentLabel {
+					agentID = agents.label(rctx.URLParam("agentID"))
+				}
 			}
-		}
 
-		metrics.HTTPRequestsTotal.WithLabelValues(r.Method, path, strconv.Itoa(ww.status)).Inc()
-		metrics.HTTPRequestDuration.WithLabelValues(r.Method, path).Observe(time.Since(start).Seconds())
-	})
+			metrics.HTTPRequestsTotal.WithLabelValues(r.Method, path, strconv.Itoa(ww.status), agentID).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(r.Method, path, strconv.Itoa(ww.status/100)+"xx", agentID).
+				Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// agentLabels hands out agent_id label values, up to max distinct agents.
+type agentLabels struct {
+	max  int
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// label returns the label value for the agentID route parameter: empty when
+// it is missing or not a UUID, so arbitrary paths cannot mint series.
+func (a *agentLabels) label(agentID string) string {
+	if _, err := uuid.Parse(agentID); err != nil {
+		return ""
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.seen[agentID]; ok {
+		return agentID
+	}
+	if len(a.seen) >= a.max {
+		return otherAgents
+	}
+	a.seen[agentID] = struct{}{}
+	return agentID
 }
 
 type statusWriter struct {
@@ -43,3 +103,13 @@ func (w *statusWriter) WriteHeader(code int) {
 	}
 	w.ResponseWriter.WriteHeader(code)
 }
+
+// Hijack lets WebSocket upgrades pass through the metrics wrapper.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
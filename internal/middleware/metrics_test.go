@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+func metricsRouter(cfg MetricsConfig) http.Handler {
+	r := chi.NewRouter()
+	r.Use(Metrics(cfg))
+	r.Get("/api/v1/agents/{agentID}", func(w http.ResponseWriter, r *http.Request) {})
+	r.Delete("/api/v1/agents/{agentID}", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNotFound) })
+	return r
+}
+
+func serve(h http.Handler, method, path string) {
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+}
+
+func TestMetrics_RoutePatternLabel(t *testing.T) {
+	const route = "/api/v1/agents/{agentID}"
+	h := metricsRouter(MetricsConfig{})
+	ok := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, route, "200", "")
+	notFound := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodDelete, route, "404", "")
+	before, beforeNotFound := testutil.ToFloat64(ok), testutil.ToFloat64(notFound)
+
+	serve(h, http.MethodGet, "/api/v1/agents/"+uuid.NewString())
+	serve(h, http.MethodGet, "/api/v1/agents/"+uuid.NewString())
+	serve(h, http.MethodDelete, "/api/v1/agents/"+uuid.NewString())
+
+	if got := testutil.ToFloat64(ok) - before; got != 2 {
+		t.Errorf("expected both agents counted under %s, got %v", route, got)
+	}
+	if got := testutil.ToFloat64(notFound) - beforeNotFound; got != 1 {
+		t.Errorf("expected the 404 counted under %s, got %v", route, got)
+	}
+}
+
+func TestMetrics_AgentLabelCap(t *testing.T) {
+	const route = "/api/v1/agents/{agentID}"
+	h := metricsRouter(MetricsConfig{AgentLabel: true, MaxAgents: 1})
+	first, second := uuid.NewString(), uuid.NewString()
+	other := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, route, "200", otherAgents)
+	unlabeled := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, route, "200", "")
+	beforeOther, beforeUnlabeled := testutil.ToFloat64(other), testutil.ToFloat64(unlabeled)
+
+	serve(h, http.MethodGet, "/api/v1/agents/"+first)
+	serve(h, http.MethodGet, "/api/v1/agents/"+second)
+	serve(h, http.MethodGet, "/api/v1/agents/"+first)
+	serve(h, http.MethodGet, "/api/v1/agents/not-a-uuid")
+
+	if got := testutil.ToFloat64(metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, route, "200", first)); got != 2 {
+		t.Errorf("expected the first agent labeled, got %v", got)
+	}
+	if got := testutil.ToFloat64(other) - beforeOther; got != 1 {
+		t.Errorf("expected agents past the cap labeled %q, got %v", otherAgents, got)
+	}
+	if got := testutil.ToFloat64(unlabeled) - beforeUnlabeled; got != 1 {
+		t.Errorf("expected a non-UUID agent ID left unlabeled, got %v", got)
+	}
+}
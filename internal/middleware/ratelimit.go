@@ -28,7 +28,7 @@ func NewRateLimiter(client redis.Cmdable, maxReqs, windowSec int) *RateLimiter {
 // On Redis errors it fails open (allows the request through).
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := clientIP(r)
+		ip := ClientIP(r)
 		key := "ratelimit:auth:" + ip
 
 		allowed, err := rl.allow(r.Context(), key)
@@ -68,7 +68,9 @@ func (rl *RateLimiter) allow(ctx context.Context, key string) (bool, error) {
 	return countCmd.Val() < int64(rl.maxReqs), nil
 }
 
-func clientIP(r *http.Request) string {
+// ClientIP returns the caller's address, preferring the X-Forwarded-For and
+// X-Real-IP headers set by a trusted reverse proxy.
+func ClientIP(r *http.Request) string {
 	// Check X-Forwarded-For first (trusted reverse proxy)
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
 		// Take the first IP
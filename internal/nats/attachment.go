@@ -0,0 +1,78 @@
+package nats
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// Attachment limits. Attachments beyond MaxAttachments, or larger than
+// MaxAttachmentSize when the size is known, are dropped.
+const (
+	MaxAttachments    = 10
+	MaxAttachmentSize = 25 << 20 // 25 MB
+	maxURLLength      = 2048
+)
+
+// Attachment is a file or image shared by URL, carried over XMPP as
+// out-of-band data (XEP-0066).
+type Attachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+	// Size is in bytes; zero when unknown.
+	Size int64 `json:"size,omitempty"`
+	// Description is the OOB <desc>, e.g. a file name.
+	Description string `json:"description,omitempty"`
+}
+
+// ValidAttachments returns the attachments with an absolute http(s) URL and
+// an allowed size, capped at MaxAttachments. It reports how many were dropped.
+func ValidAttachments(in []Attachment) ([]Attachment, int) {
+	var out []Attachment
+	for _, a := range in {
+		if len(out) == MaxAttachments {
+			break
+		}
+		if a.Size < 0 || a.Size > MaxAttachmentSize || !validAttachmentURL(a.URL) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out, len(in) - len(out)
+}
+
+func validAttachmentURL(raw string) bool {
+	if raw == "" || len(raw) > maxURLLength {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// MarshalAttachments encodes attachments for the worker protocol's
+// attachments_json fields. It returns "" when there are none.
+func MarshalAttachments(attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(attachments)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// UnmarshalAttachments decodes an attachments_json field. Empty or invalid
+// input yields no attachments.
+func UnmarshalAttachments(data string) []Attachment {
+	if data == "" {
+		return nil
+	}
+	var attachments []Attachment
+	if err := json.Unmarshal([]byte(data), &attachments); err != nil {
+		return nil
+	}
+	return attachments
+}
@@ -0,0 +1,38 @@
+package nats
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidAttachments(t *testing.T) {
+	in := []Attachment{
+		{URL: "https://files.example.com/cat.png", MimeType: "image/png", Size: 1024},
+		{URL: "ftp://files.example.com/cat.png"},
+		{URL: "/relative/path.png"},
+		{URL: "https://files.example.com/huge.bin", Size: MaxAttachmentSize + 1},
+		{URL: "http://files.example.com/doc.pdf"},
+	}
+	got, dropped := ValidAttachments(in)
+	assert.Equal(t, []Attachment{in[0], in[4]}, got)
+	assert.Equal(t, 3, dropped)
+
+	var many []Attachment
+	for i := range MaxAttachments + 2 {
+		many = append(many, Attachment{URL: fmt.Sprintf("https://files.example.com/%d.png", i)})
+	}
+	got, dropped = ValidAttachments(many)
+	assert.Len(t, got, MaxAttachments)
+	assert.Equal(t, 2, dropped)
+}
+
+func TestAttachmentsJSON_RoundTrip(t *testing.T) {
+	assert.Equal(t, "", MarshalAttachments(nil))
+	assert.Nil(t, UnmarshalAttachments(""))
+	assert.Nil(t, UnmarshalAttachments("not json"))
+
+	in := []Attachment{{URL: "https://files.example.com/cat.png", MimeType: "image/png", Size: 1024}}
+	assert.Equal(t, in, UnmarshalAttachments(MarshalAttachments(in)))
+}
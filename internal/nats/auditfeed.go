@@ -0,0 +1,92 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// AuditRecord is an audit event with its AIOX_EVENTS stream sequence, which
+// clients can use to resume a feed.
+type AuditRecord struct {
+	Sequence uint64
+	AuditEvent
+}
+
+// AuditFeed tails audit events from the AIOX_EVENTS stream.
+type AuditFeed struct {
+	js jetstream.JetStream
+}
+
+// NewAuditFeed creates an AuditFeed.
+func NewAuditFeed(js jetstream.JetStream) *AuditFeed {
+	return &AuditFeed{js: js}
+}
+
+// Follow delivers the owner's audit events on the returned channel until ctx
+// is cancelled or the stream fails, then closes it. With afterSeq 0 only new
+// events are delivered; otherwise delivery resumes after that sequence.
+func (f *AuditFeed) Follow(ctx context.Context, ownerID uuid.UUID, afterSeq uint64) (<-chan AuditRecord, error) {
+	stream, err := f.js.Stream(ctx, StreamEvents)
+	if err != nil {
+		return nil, fmt.Errorf("getting events stream: %w", err)
+	}
+
+	cfg := jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{AuditSubject(ownerID)},
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	}
+	if afterSeq > 0 {
+		cfg.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = afterSeq + 1
+	}
+	consumer, err := stream.OrderedConsumer(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating audit feed consumer: %w", err)
+	}
+
+	iter, err := consumer.Messages()
+	if err != nil {
+		return nil, fmt.Errorf("starting audit feed: %w", err)
+	}
+
+	// Next blocks, so stopping the iterator is what ends the loop below.
+	go func() {
+		<-ctx.Done()
+		iter.Stop()
+	}()
+
+	records := make(chan AuditRecord)
+	go func() {
+		defer close(records)
+		for {
+			msg, err := iter.Next()
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.Warn("audit feed: reading events", "error", err)
+				}
+				return
+			}
+
+			meta, err := msg.Metadata()
+			if err != nil {
+				continue
+			}
+			rec := AuditRecord{Sequence: meta.Sequence.Stream}
+			if err := Decode(msg.Headers(), msg.Data(), &rec.AuditEvent); err != nil {
+				slog.Warn("audit feed: decoding event", "error", err, "seq", rec.Sequence)
+				continue
+			}
+
+			select {
+			case records <- rec:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return records, nil
+}
@@ -0,0 +1,177 @@
+package nats
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// feedMsg is a stored AIOX_EVENTS message.
+type feedMsg struct {
+	jetstream.Msg
+	subject string
+	seq     uint64
+	data    []byte
+}
+
+func (m *feedMsg) Subject() string      { return m.subject }
+func (m *feedMsg) Data() []byte         { return m.data }
+func (m *feedMsg) Headers() nats.Header { return nats.Header{} }
+func (m *feedMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Sequence: jetstream.SequencePair{Stream: m.seq}}, nil
+}
+
+// feedStream delivers messages to the latest ordered consumer, applying its
+// filter subjects, deliver policy and start sequence the way the server does.
+type feedStream struct {
+	jetstream.Stream
+	stored []*feedMsg
+	cfg    jetstream.OrderedConsumerConfig
+	iter   *feedIter
+}
+
+func (s *feedStream) OrderedConsumer(_ context.Context, cfg jetstream.OrderedConsumerConfig) (jetstream.Consumer, error) {
+	s.cfg = cfg
+	s.iter = &feedIter{msgs: make(chan jetstream.Msg, 16), stopped: make(chan struct{})}
+	if cfg.DeliverPolicy == jetstream.DeliverByStartSequencePolicy {
+		for _, m := range s.stored {
+			if m.seq >= cfg.OptStartSeq {
+				s.deliver(m)
+			}
+		}
+	}
+	return &feedConsumer{iter: s.iter}, nil
+}
+
+func (s *feedStream) store(m *feedMsg) {
+	m.seq = uint64(len(s.stored) + 1)
+	s.stored = append(s.stored, m)
+	if s.iter != nil {
+		s.deliver(m)
+	}
+}
+
+func (s *feedStream) deliver(m *feedMsg) {
+	for _, f := range s.cfg.FilterSubjects {
+		if m.subject == f {
+			s.iter.msgs <- m
+		}
+	}
+}
+
+type feedConsumer struct {
+	jetstream.Consumer
+	iter *feedIter
+}
+
+func (c *feedConsumer) Messages(...jetstream.PullMessagesOpt) (jetstream.MessagesContext, error) {
+	return c.iter, nil
+}
+
+type feedIter struct {
+	jetstream.MessagesContext
+	msgs    chan jetstream.Msg
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func (it *feedIter) Next(...jetstream.NextOpt) (jetstream.Msg, error) {
+	select {
+	case m := <-it.msgs:
+		return m, nil
+	case <-it.stopped:
+		return nil, jetstream.ErrMsgIteratorClosed
+	}
+}
+
+func (it *feedIter) Stop() { it.once.Do(func() { close(it.stopped) }) }
+
+type feedJS struct {
+	jetstream.JetStream
+	stream *feedStream
+}
+
+func (js *feedJS) Stream(_ context.Context, name string) (jetstream.Stream, error) {
+	if name != StreamEvents {
+		return nil, jetstream.ErrStreamNotFound
+	}
+	return js.stream, nil
+}
+
+func storeAudit(t *testing.T, stream *feedStream, event AuditEvent) {
+	t.Helper()
+	data, err := JSONCodec{}.Marshal(event)
+	require.NoError(t, err)
+	stream.store(&feedMsg{subject: AuditSubject(event.OwnerUserID), data: data})
+}
+
+func TestAuditFeed_Follow(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	stream := &feedStream{}
+	storeAudit(t, stream, AuditEvent{OwnerUserID: owner, EventType: "first"})
+	storeAudit(t, stream, AuditEvent{OwnerUserID: other, EventType: "not yours"})
+	storeAudit(t, stream, AuditEvent{OwnerUserID: owner, EventType: "second"})
+	stream.store(&feedMsg{subject: AuditSubject(owner), data: []byte("not json")})
+	storeAudit(t, stream, AuditEvent{OwnerUserID: owner, EventType: "third"})
+	feed := NewAuditFeed(&feedJS{stream: stream})
+
+	next := func(t *testing.T, records <-chan AuditRecord) AuditRecord {
+		t.Helper()
+		select {
+		case rec, ok := <-records:
+			require.True(t, ok, "feed closed early")
+			return rec
+		case <-time.After(time.Second):
+			t.Fatal("no record delivered")
+			return AuditRecord{}
+		}
+	}
+
+	t.Run("filters by owner on the server and resumes after a sequence", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		records, err := feed.Follow(ctx, owner, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{AuditSubject(owner)}, stream.cfg.FilterSubjects)
+		assert.Equal(t, jetstream.DeliverByStartSequencePolicy, stream.cfg.DeliverPolicy)
+		assert.Equal(t, uint64(2), stream.cfg.OptStartSeq)
+
+		second := next(t, records)
+		assert.Equal(t, uint64(3), second.Sequence)
+		assert.Equal(t, "second", second.EventType)
+		assert.Equal(t, owner, second.OwnerUserID)
+		// The undecodable record at sequence 4 is skipped.
+		third := next(t, records)
+		assert.Equal(t, uint64(5), third.Sequence)
+		assert.Equal(t, "third", third.EventType)
+	})
+
+	t.Run("starts with new events and closes when ctx ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		records, err := feed.Follow(ctx, other, 0)
+		require.NoError(t, err)
+		assert.Equal(t, jetstream.DeliverNewPolicy, stream.cfg.DeliverPolicy)
+
+		storeAudit(t, stream, AuditEvent{OwnerUserID: owner, EventType: "fourth"})
+		storeAudit(t, stream, AuditEvent{OwnerUserID: other, EventType: "new"})
+		rec := next(t, records)
+		assert.Equal(t, uint64(7), rec.Sequence, "only events after Follow, for this owner")
+		assert.Equal(t, "new", rec.EventType)
+
+		cancel()
+		select {
+		case _, ok := <-records:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("feed not closed after cancel")
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+// ErrPublishBufferFull is returned for a publish made while disconnected
+// when the buffer holds only messages of higher priority.
+var ErrPublishBufferFull = errors.New("publish buffer full")
+
+const (
+	// flushTimeout bounds one flush of the buffer.
+	flushTimeout = time.Minute
+	// flushRetryBase is the pause before retrying a failed flush while
+	// connected; it doubles up to flushRetryMax.
+	flushRetryBase = time.Second
+	flushRetryMax  = 30 * time.Second
+)
+
+// publishPriority orders buffered messages for eviction: when the buffer is
+// full, the oldest message of the lowest priority is dropped first. Critical
+// messages are never dropped; publishing one into a full buffer waits.
+type publishPriority int
+
+const (
+	priorityLow publishPriority = iota
+	priorityNormal
+	priorityCritical
+)
+
+// ConnectionState reports and signals NATS connectivity; satisfied by *Client.
+type ConnectionState interface {
+	Healthy() bool
+	NotifyReconnect(fn func())
+}
+
+type bufferedMsg struct {
+	msg      *nats.Msg
+	priority publishPriority
+}
+
+// publishBuffer holds messages published while NATS was unreachable.
+type publishBuffer struct {
+	size int
+	conn ConnectionState
+
+	mu   sync.Mutex
+	msgs []*bufferedMsg
+	// freed is closed and replaced whenever messages leave the buffer, waking
+	// critical publishes waiting for space.
+	freed    chan struct{}
+	flushing bool
+	// draining is set while a goroutine is flushing the buffer until it is
+	// empty.
+	draining   bool
+	retryDelay time.Duration
+}
+
+// EnableBuffer queues publishes made while conn is disconnected, up to size
+// messages, and republishes them in order once it reconnects. Until the
+// buffer is empty again, new publishes are queued behind it so they can't
+// overtake it. A publish that is buffered returns nil. Messages still
+// buffered when the process exits are lost.
+func (p *Publisher) EnableBuffer(size int, conn ConnectionState) {
+	p.buffer = &publishBuffer{size: size, conn: conn, freed: make(chan struct{}), retryDelay: flushRetryBase}
+	conn.NotifyReconnect(p.startDrain)
+}
+
+// startDrain flushes the buffer in the background until it is empty, unless
+// that is already under way.
+func (p *Publisher) startDrain() {
+	b := p.buffer
+	b.mu.Lock()
+	if b.draining {
+		b.mu.Unlock()
+		return
+	}
+	b.draining = true
+	b.mu.Unlock()
+	go p.drain()
+}
+
+// drain flushes the buffer until it is empty, retrying failed flushes with
+// growing pauses, for instance while JetStream is still recovering after a
+// reconnect. It stops early when the connection drops; the next reconnect
+// starts it again.
+func (p *Publisher) drain() {
+	b := p.buffer
+	delay := b.retryDelay
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+		n, err := p.Flush(ctx)
+		cancel()
+		if n > 0 {
+			slog.Info("flushed NATS publish buffer", "flushed", n)
+		}
+
+		b.mu.Lock()
+		if len(b.msgs) == 0 || !b.conn.Healthy() {
+			// Checked under the lock publish takes to queue a message, so a
+			// message queued after this starts a new drain.
+			b.draining = false
+			b.mu.Unlock()
+			if err != nil {
+				slog.Warn("flushing NATS publish buffer", "error", err, "flushed", n)
+			}
+			return
+		}
+		b.mu.Unlock()
+
+		if err == nil {
+			// Messages arrived during the flush, or another Flush was
+			// running; go again.
+			delay = b.retryDelay
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		slog.Warn("flushing NATS publish buffer, retrying", "error", err, "flushed", n, "retry_in", delay)
+		time.Sleep(delay)
+		delay = min(delay*2, flushRetryMax)
+	}
+}
+
+// pending reports whether messages are waiting to be flushed.
+func (b *publishBuffer) pending() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.msgs) > 0
+}
+
+// Buffered returns the number of messages waiting for NATS to reconnect.
+func (p *Publisher) Buffered() int {
+	if p.buffer == nil {
+		return 0
+	}
+	p.buffer.mu.Lock()
+	defer p.buffer.mu.Unlock()
+	return len(p.buffer.msgs)
+}
+
+// Flush republishes buffered messages oldest first, stopping at the first
+// failure, and returns how many were sent. Concurrent calls return at once.
+func (p *Publisher) Flush(ctx context.Context) (int, error) {
+	b := p.buffer
+	if b == nil {
+		return 0, nil
+	}
+	b.mu.Lock()
+	if b.flushing {
+		b.mu.Unlock()
+		return 0, nil
+	}
+	b.flushing = true
+	b.mu.Unlock()
+
+	var sent int
+	for {
+		b.mu.Lock()
+		if len(b.msgs) == 0 {
+			// Cleared together with the emptiness check so a message queued
+			// right after is never left to a flush that has finished.
+			b.flushing = false
+			b.mu.Unlock()
+			return sent, nil
+		}
+		next := b.msgs[0]
+		b.mu.Unlock()
+
+		if _, err := p.js.PublishMsg(ctx, next.msg); err != nil {
+			b.mu.Lock()
+			b.flushing = false
+			b.mu.Unlock()
+			return sent, fmt.Errorf("publishing to %s: %w", next.msg.Subject, err)
+		}
+		b.remove(next)
+		sent++
+	}
+}
+
+// add queues m, evicting a message of lower or equal priority when full.
+func (b *publishBuffer) add(ctx context.Context, m *bufferedMsg) error {
+	for {
+		b.mu.Lock()
+		if len(b.msgs) < b.size {
+			b.msgs = append(b.msgs, m)
+			metrics.NATSPublishBuffered.Set(float64(len(b.msgs)))
+			b.mu.Unlock()
+			return nil
+		}
+
+		victim := -1
+		for i, q := range b.msgs {
+			if q.priority < priorityCritical && q.priority <= m.priority &&
+				(victim < 0 || q.priority < b.msgs[victim].priority) {
+				victim = i
+			}
+		}
+		if victim >= 0 {
+			dropped := b.msgs[victim]
+			b.msgs = append(b.msgs[:victim], b.msgs[victim+1:]...)
+			b.msgs = append(b.msgs, m)
+			b.mu.Unlock()
+			metrics.NATSPublishDroppedTotal.Inc()
+			slog.Warn("NATS publish buffer full, dropped buffered message", "subject", dropped.msg.Subject)
+			return nil
+		}
+		if m.priority < priorityCritical {
+			b.mu.Unlock()
+			metrics.NATSPublishDroppedTotal.Inc()
+			slog.Warn("NATS publish buffer full, dropped message", "subject", m.msg.Subject)
+			return ErrPublishBufferFull
+		}
+
+		freed := b.freed
+		b.mu.Unlock()
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for publish buffer space: %w", ctx.Err())
+		}
+	}
+}
+
+// remove deletes m if it is still buffered; add may have evicted it.
+func (b *publishBuffer) remove(m *bufferedMsg) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, q := range b.msgs {
+		if q == m {
+			b.msgs = append(b.msgs[:i], b.msgs[i+1:]...)
+			break
+		}
+	}
+	metrics.NATSPublishBuffered.Set(float64(len(b.msgs)))
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
@@ -0,0 +1,185 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is a ConnectionState toggled by the test.
+type fakeConn struct {
+	healthy     atomic.Bool
+	reconnected func()
+}
+
+func (c *fakeConn) Healthy() bool             { return c.healthy.Load() }
+func (c *fakeConn) NotifyReconnect(fn func()) { c.reconnected = fn }
+
+// recordingJS records published subjects and outbound message IDs, or fails
+// while down is set and for the next failures publishes.
+type recordingJS struct {
+	jetstream.JetStream
+	mu       sync.Mutex
+	down     bool
+	failures int
+	subjects []string
+	ids      []string
+}
+
+func (js *recordingJS) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if js.down {
+		return nil, nats.ErrConnectionClosed
+	}
+	if js.failures > 0 {
+		js.failures--
+		return nil, jetstream.ErrNoStreamResponse
+	}
+	js.subjects = append(js.subjects, msg.Subject)
+	var out OutboundMessage
+	_ = json.Unmarshal(msg.Data, &out)
+	js.ids = append(js.ids, out.ID)
+	return &jetstream.PubAck{}, nil
+}
+
+func (js *recordingJS) setDown(down bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.down = down
+}
+
+func (js *recordingJS) published() []string {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	return append([]string(nil), js.subjects...)
+}
+
+func newBufferedPublisher(size int) (*Publisher, *recordingJS, *fakeConn) {
+	js := &recordingJS{down: true}
+	conn := &fakeConn{}
+	p := NewPublisher(js)
+	p.EnableBuffer(size, conn)
+	return p, js, conn
+}
+
+func TestPublisher_BuffersUntilReconnect(t *testing.T) {
+	p, js, conn := newBufferedPublisher(10)
+	ctx := context.Background()
+
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "1"}))
+	require.NoError(t, p.PublishAuditEvent(ctx, AuditEvent{Severity: "info"}))
+	assert.Equal(t, 2, p.Buffered())
+	assert.Empty(t, js.subjects)
+
+	// Still down: the flush stops at the first failure and keeps everything.
+	n, err := p.Flush(ctx)
+	assert.Error(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, 2, p.Buffered())
+
+	js.setDown(false)
+	conn.healthy.Store(true)
+	conn.reconnected()
+	require.Eventually(t, func() bool { return p.Buffered() == 0 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{SubjectOutboundMessage, AuditSubject(uuid.Nil)}, js.published(), "flushed in publish order")
+
+	require.NoError(t, p.PublishAgentEvent(ctx, AgentEvent{}))
+	assert.Equal(t, SubjectAgentEvent, js.published()[2], "published directly while connected")
+}
+
+func TestPublisher_BufferFullDropsLowestPriority(t *testing.T) {
+	p, js, _ := newBufferedPublisher(2)
+	ctx := context.Background()
+
+	require.NoError(t, p.PublishAuditEvent(ctx, AuditEvent{Severity: "info"}))
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "1"}))
+	// Full: the info audit event is evicted for the outbound message.
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "2"}))
+	// Nothing of lower priority is left, so a low-priority event is refused.
+	assert.ErrorIs(t, p.PublishAgentEvent(ctx, AgentEvent{}), ErrPublishBufferFull)
+
+	// Critical events evict normal ones, but never each other.
+	require.NoError(t, p.PublishAuditEvent(ctx, AuditEvent{Severity: "error"}))
+	require.NoError(t, p.PublishAuditEvent(ctx, AuditEvent{Severity: "error"}))
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err := p.PublishAuditEvent(waitCtx, AuditEvent{Severity: "error"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "a critical event waits for space: %v", err)
+
+	js.setDown(false)
+	_, err = p.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{AuditSubject(uuid.Nil), AuditSubject(uuid.Nil)}, js.published())
+}
+
+func TestPublisher_CriticalPublishResumesWhenFlushed(t *testing.T) {
+	p, js, _ := newBufferedPublisher(1)
+	ctx := context.Background()
+	require.NoError(t, p.PublishAuditEvent(ctx, AuditEvent{Severity: "error"}))
+
+	done := make(chan error, 1)
+	go func() { done <- p.PublishAuditEvent(ctx, AuditEvent{Severity: "critical"}) }()
+
+	js.setDown(false)
+	require.Eventually(t, func() bool {
+		_, err := p.Flush(ctx)
+		return err == nil && len(js.published()) >= 1
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, <-done)
+	// The flush may or may not have picked up the waiting event too.
+	assert.Equal(t, 2, len(js.published())+p.Buffered(), "the waiting event was buffered once space freed up")
+}
+
+func TestPublisher_RetriesFlushUntilEmpty(t *testing.T) {
+	p, js, conn := newBufferedPublisher(10)
+	p.buffer.retryDelay = 5 * time.Millisecond
+	ctx := context.Background()
+
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "1"}))
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "2"}))
+
+	// Reconnected, but JetStream fails the first flushes.
+	js.mu.Lock()
+	js.down, js.failures = false, 3
+	js.mu.Unlock()
+	conn.healthy.Store(true)
+	conn.reconnected()
+
+	require.Eventually(t, func() bool { return p.Buffered() == 0 }, time.Second, 5*time.Millisecond)
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	assert.Equal(t, []string{"1", "2"}, js.ids)
+}
+
+func TestPublisher_QueuesBehindBufferedMessages(t *testing.T) {
+	p, js, conn := newBufferedPublisher(10)
+	p.buffer.retryDelay = 5 * time.Millisecond
+	ctx := context.Background()
+
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "1"}))
+
+	// Connected again but the buffer is not flushed yet: a new publish must
+	// not overtake it.
+	js.mu.Lock()
+	js.down, js.failures = false, 2
+	js.mu.Unlock()
+	conn.healthy.Store(true)
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "2"}))
+	require.NoError(t, p.PublishOutboundMessage(ctx, OutboundMessage{ID: "3"}))
+
+	require.Eventually(t, func() bool { return p.Buffered() == 0 }, time.Second, 5*time.Millisecond)
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	assert.Equal(t, []string{"1", "2", "3"}, js.ids)
+}
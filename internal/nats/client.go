@@ -1,9 +1,10 @@
 package nats
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
@@ -16,19 +17,30 @@ import (
 type Client struct {
 	conn *nats.Conn
 	js   jetstream.JetStream
+
+	mu          sync.Mutex
+	onReconnect []func()
 }
 
-// NewClient connects to NATS and ensures required JetStream streams exist.
-func NewClient(ctx context.Context, cfg config.NATSConfig) (*Client, error) {
+// NewClient connects to NATS. Call EnsureStreams before publishing. The
+// connection reconnects indefinitely after an outage.
+func NewClient(cfg config.NATSConfig) (*Client, error) {
+	c := &Client{}
 	nc, err := nats.Connect(cfg.URL,
 		nats.RetryOnFailedConnect(true),
-		nats.MaxReconnects(10),
+		nats.MaxReconnects(-1),
 		nats.ReconnectWait(2*time.Second),
 		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
 			slog.Warn("NATS disconnected", "error", err)
 		}),
 		nats.ReconnectHandler(func(_ *nats.Conn) {
 			slog.Info("NATS reconnected")
+			c.mu.Lock()
+			handlers := slices.Clone(c.onReconnect)
+			c.mu.Unlock()
+			for _, fn := range handlers {
+				fn()
+			}
 		}),
 	)
 	if err != nil {
@@ -41,47 +53,17 @@ func NewClient(ctx context.Context, cfg config.NATSConfig) (*Client, error) {
 		return nil, fmt.Errorf("creating JetStream context: %w", err)
 	}
 
-	c := &Client{conn: nc, js: js}
-
-	if err := c.ensureStreams(ctx); err != nil {
-		nc.Close()
-		return nil, fmt.Errorf("ensuring streams: %w", err)
-	}
-
 	slog.Info("connected to NATS", "url", cfg.URL)
+	c.conn, c.js = nc, js
 	return c, nil
 }
 
-func (c *Client) ensureStreams(ctx context.Context) error {
-	streams := []jetstream.StreamConfig{
-		{
-			Name:      StreamMessages,
-			Subjects:  []string{"aiox.messages.>"},
-			Retention: jetstream.WorkQueuePolicy,
-			MaxAge:    24 * time.Hour,
-		},
-		{
-			Name:      StreamTasks,
-			Subjects:  []string{"aiox.tasks.>"},
-			Retention: jetstream.WorkQueuePolicy,
-			MaxAge:    1 * time.Hour,
-		},
-		{
-			Name:      StreamEvents,
-			Subjects:  []string{"aiox.events.>"},
-			Retention: jetstream.LimitsPolicy,
-			MaxAge:    7 * 24 * time.Hour,
-		},
-	}
-
-	for _, cfg := range streams {
-		_, err := c.js.CreateOrUpdateStream(ctx, cfg)
-		if err != nil {
-			return fmt.Errorf("creating stream %s: %w", cfg.Name, err)
-		}
-		slog.Debug("ensured NATS stream", "name", cfg.Name)
-	}
-	return nil
+// NotifyReconnect registers fn to run after every reconnect. fn runs on the
+// connection's callback goroutine and must not block.
+func (c *Client) NotifyReconnect(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReconnect = append(c.onReconnect, fn)
 }
 
 // JetStream returns the JetStream context.
@@ -99,6 +81,11 @@ func (c *Client) Healthy() bool {
 	return c.conn.IsConnected()
 }
 
+// RTT measures the round-trip time to the NATS server.
+func (c *Client) RTT() (time.Duration, error) {
+	return c.conn.RTT()
+}
+
 // Close drains and closes the NATS connection.
 func (c *Client) Close() {
 	if err := c.conn.Drain(); err != nil {
@@ -0,0 +1,57 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// HeaderContentType carries the payload encoding on every published message.
+const HeaderContentType = "Content-Type"
+
+// Content types understood by Decode.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/protobuf"
+)
+
+// Codec encodes and decodes NATS message payloads.
+type Codec interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// CodecByName returns the codec for a NATS_CODEC value ("json" or "protobuf").
+// An empty name selects JSON.
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "", "json":
+		return JSONCodec{}, nil
+	case "protobuf", "proto":
+		return ProtobufCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unknown NATS codec %q", name)
+	}
+}
+
+// JSONCodec encodes payloads as JSON. It is the default.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string { return ContentTypeJSON }
+
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// Decode unmarshals a message payload using the codec named by its
+// Content-Type header. Messages without the header are treated as JSON so
+// consumers keep working while publishers are migrated.
+func Decode(header nats.Header, data []byte, v any) error {
+	var codec Codec = JSONCodec{}
+	if header != nil && header.Get(HeaderContentType) == ContentTypeProtobuf {
+		codec = ProtobufCodec{}
+	}
+	return codec.Unmarshal(data, v)
+}
@@ -0,0 +1,343 @@
+package nats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/aiox-platform/aiox/internal/nats/eventspb"
+)
+
+// ProtobufCodec encodes the event types in this package as the messages of
+// proto/events/v1/events.proto, generated into the eventspb package.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (ProtobufCodec) Marshal(v any) ([]byte, error) {
+	var m proto.Message
+	switch e := v.(type) {
+	case InboundMessage:
+		m = inboundToProto(&e)
+	case *InboundMessage:
+		m = inboundToProto(e)
+	case OutboundMessage:
+		m = outboundToProto(&e)
+	case *OutboundMessage:
+		m = outboundToProto(e)
+	case TaskMessage:
+		m = taskToProto(&e)
+	case *TaskMessage:
+		m = taskToProto(e)
+	case AgentEvent:
+		m = agentEventToProto(&e)
+	case *AgentEvent:
+		m = agentEventToProto(e)
+	case AuditEvent:
+		m = auditEventToProto(&e)
+	case *AuditEvent:
+		m = auditEventToProto(e)
+	case DeadLetter:
+		m = deadLetterToProto(&e)
+	case *DeadLetter:
+		m = deadLetterToProto(e)
+	default:
+		return nil, fmt.Errorf("no protobuf mapping for %T", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v any) error {
+	switch e := v.(type) {
+	case *InboundMessage:
+		var m eventspb.InboundMessage
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		*e = inboundFromProto(&m)
+		return nil
+	case *OutboundMessage:
+		var m eventspb.OutboundMessage
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		*e = outboundFromProto(&m)
+		return nil
+	case *TaskMessage:
+		var m eventspb.TaskMessage
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		return taskFromProto(&m, e)
+	case *AgentEvent:
+		var m eventspb.AgentEvent
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		return agentEventFromProto(&m, e)
+	case *AuditEvent:
+		var m eventspb.AuditEvent
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		return auditEventFromProto(&m, e)
+	case *DeadLetter:
+		var m eventspb.DeadLetter
+		if err := proto.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		return deadLetterFromProto(&m, e)
+	default:
+		return fmt.Errorf("no protobuf mapping for %T", v)
+	}
+}
+
+func inboundToProto(m *InboundMessage) *eventspb.InboundMessage {
+	return &eventspb.InboundMessage{
+		Id:               m.ID,
+		FromJid:          m.FromJID,
+		ToJid:            m.ToJID,
+		Body:             m.Body,
+		StanzaType:       m.StanzaType,
+		ReceivedAt:       timestampToProto(m.ReceivedAt),
+		TraceParent:      m.TraceParent,
+		RoomJid:          m.RoomJID,
+		Nickname:         m.Nickname,
+		StanzaId:         m.StanzaID,
+		ReceiptRequested: m.ReceiptRequested,
+		Attachments:      attachmentsToProto(m.Attachments),
+	}
+}
+
+func inboundFromProto(m *eventspb.InboundMessage) InboundMessage {
+	return InboundMessage{
+		ID:               m.Id,
+		FromJID:          m.FromJid,
+		ToJID:            m.ToJid,
+		Body:             m.Body,
+		StanzaType:       m.StanzaType,
+		ReceivedAt:       timestampFromProto(m.ReceivedAt),
+		TraceParent:      m.TraceParent,
+		RoomJID:          m.RoomJid,
+		Nickname:         m.Nickname,
+		StanzaID:         m.StanzaId,
+		ReceiptRequested: m.ReceiptRequested,
+		Attachments:      attachmentsFromProto(m.Attachments),
+	}
+}
+
+func outboundToProto(m *OutboundMessage) *eventspb.OutboundMessage {
+	return &eventspb.OutboundMessage{
+		Id:          m.ID,
+		ToJid:       m.ToJID,
+		FromJid:     m.FromJID,
+		Body:        m.Body,
+		InReplyTo:   m.InReplyTo,
+		FromCache:   m.FromCache,
+		TraceParent: m.TraceParent,
+		RoomJid:     m.RoomJID,
+		ChatState:   m.ChatState,
+		ReceiptId:   m.ReceiptID,
+		Attachments: attachmentsToProto(m.Attachments),
+	}
+}
+
+func outboundFromProto(m *eventspb.OutboundMessage) OutboundMessage {
+	return OutboundMessage{
+		ID:          m.Id,
+		ToJID:       m.ToJid,
+		FromJID:     m.FromJid,
+		Body:        m.Body,
+		InReplyTo:   m.InReplyTo,
+		FromCache:   m.FromCache,
+		TraceParent: m.TraceParent,
+		RoomJID:     m.RoomJid,
+		ChatState:   m.ChatState,
+		ReceiptID:   m.ReceiptId,
+		Attachments: attachmentsFromProto(m.Attachments),
+	}
+}
+
+func taskToProto(m *TaskMessage) *eventspb.TaskMessage {
+	return &eventspb.TaskMessage{
+		RequestId:    m.RequestID,
+		AgentId:      uuidToProto(m.AgentID),
+		OwnerUserId:  uuidToProto(m.OwnerUserID),
+		Message:      m.Message,
+		FromJid:      m.FromJID,
+		AgentJid:     m.AgentJID,
+		AgentName:    m.AgentName,
+		TraceParent:  m.TraceParent,
+		Invoke:       m.Invoke,
+		RoomJid:      m.RoomJID,
+		Attachments:  attachmentsToProto(m.Attachments),
+		Priority:     m.Priority,
+		DebugContext: m.DebugContext,
+		UnredactedBy: m.UnredactedBy,
+	}
+}
+
+func taskFromProto(m *eventspb.TaskMessage, dst *TaskMessage) error {
+	agentID, err := uuidFromProto(m.AgentId)
+	if err != nil {
+		return fmt.Errorf("decoding agent_id: %w", err)
+	}
+	ownerID, err := uuidFromProto(m.OwnerUserId)
+	if err != nil {
+		return fmt.Errorf("decoding owner_user_id: %w", err)
+	}
+	*dst = TaskMessage{
+		RequestID:    m.RequestId,
+		AgentID:      agentID,
+		OwnerUserID:  ownerID,
+		Message:      m.Message,
+		FromJID:      m.FromJid,
+		AgentJID:     m.AgentJid,
+		AgentName:    m.AgentName,
+		TraceParent:  m.TraceParent,
+		Invoke:       m.Invoke,
+		RoomJID:      m.RoomJid,
+		Attachments:  attachmentsFromProto(m.Attachments),
+		Priority:     m.Priority,
+		DebugContext: m.DebugContext,
+		UnredactedBy: m.UnredactedBy,
+	}
+	return nil
+}
+
+func agentEventToProto(m *AgentEvent) *eventspb.AgentEvent {
+	return &eventspb.AgentEvent{
+		AgentId:     uuidToProto(m.AgentID),
+		OwnerUserId: uuidToProto(m.OwnerUserID),
+		Jid:         m.JID,
+		EventType:   m.EventType,
+		Timestamp:   timestampToProto(m.Timestamp),
+	}
+}
+
+func agentEventFromProto(m *eventspb.AgentEvent, dst *AgentEvent) error {
+	agentID, err := uuidFromProto(m.AgentId)
+	if err != nil {
+		return fmt.Errorf("decoding agent_id: %w", err)
+	}
+	ownerID, err := uuidFromProto(m.OwnerUserId)
+	if err != nil {
+		return fmt.Errorf("decoding owner_user_id: %w", err)
+	}
+	*dst = AgentEvent{
+		AgentID:     agentID,
+		OwnerUserID: ownerID,
+		JID:         m.Jid,
+		EventType:   m.EventType,
+		Timestamp:   timestampFromProto(m.Timestamp),
+	}
+	return nil
+}
+
+func auditEventToProto(m *AuditEvent) *eventspb.AuditEvent {
+	return &eventspb.AuditEvent{
+		OwnerUserId:  uuidToProto(m.OwnerUserID),
+		EventType:    m.EventType,
+		Severity:     m.Severity,
+		ResourceType: m.ResourceType,
+		ResourceId:   m.ResourceID,
+		Details:      m.Details,
+		Timestamp:    timestampToProto(m.Timestamp),
+	}
+}
+
+func auditEventFromProto(m *eventspb.AuditEvent, dst *AuditEvent) error {
+	ownerID, err := uuidFromProto(m.OwnerUserId)
+	if err != nil {
+		return fmt.Errorf("decoding owner_user_id: %w", err)
+	}
+	*dst = AuditEvent{
+		OwnerUserID:  ownerID,
+		EventType:    m.EventType,
+		Severity:     m.Severity,
+		ResourceType: m.ResourceType,
+		ResourceID:   m.ResourceId,
+		Details:      m.Details,
+		Timestamp:    timestampFromProto(m.Timestamp),
+	}
+	return nil
+}
+
+func deadLetterToProto(m *DeadLetter) *eventspb.DeadLetter {
+	return &eventspb.DeadLetter{
+		Task:       taskToProto(&m.Task),
+		Reason:     m.Reason,
+		Deliveries: m.Deliveries,
+		FailedAt:   timestampToProto(m.FailedAt),
+	}
+}
+
+func deadLetterFromProto(m *eventspb.DeadLetter, dst *DeadLetter) error {
+	*dst = DeadLetter{
+		Reason:     m.Reason,
+		Deliveries: m.Deliveries,
+		FailedAt:   timestampFromProto(m.FailedAt),
+	}
+	if m.Task == nil {
+		return nil
+	}
+	if err := taskFromProto(m.Task, &dst.Task); err != nil {
+		return fmt.Errorf("decoding task: %w", err)
+	}
+	return nil
+}
+
+func attachmentsToProto(v []Attachment) []*eventspb.Attachment {
+	if len(v) == 0 {
+		return nil
+	}
+	out := make([]*eventspb.Attachment, len(v))
+	for i, a := range v {
+		out[i] = &eventspb.Attachment{Url: a.URL, MimeType: a.MimeType, Size: a.Size, Description: a.Description}
+	}
+	return out
+}
+
+func attachmentsFromProto(v []*eventspb.Attachment) []Attachment {
+	if len(v) == 0 {
+		return nil
+	}
+	out := make([]Attachment, len(v))
+	for i, a := range v {
+		out[i] = Attachment{URL: a.Url, MimeType: a.MimeType, Size: a.Size, Description: a.Description}
+	}
+	return out
+}
+
+// uuidToProto leaves uuid.Nil unset.
+func uuidToProto(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+	return id.String()
+}
+
+func uuidFromProto(s string) (uuid.UUID, error) {
+	if s == "" {
+		return uuid.Nil, nil
+	}
+	return uuid.Parse(s)
+}
+
+// timestampToProto leaves the zero time unset.
+func timestampToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timestampFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,102 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecByName(t *testing.T) {
+	c, err := CodecByName("")
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeJSON, c.ContentType())
+
+	c, err = CodecByName("protobuf")
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeProtobuf, c.ContentType())
+
+	_, err = CodecByName("xml")
+	assert.Error(t, err)
+}
+
+func TestProtobufCodec_RoundTrip(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	codec := ProtobufCodec{}
+
+	task := TaskMessage{
+		RequestID:    "req-1",
+		AgentID:      uuid.New(),
+		OwnerUserID:  uuid.New(),
+		Message:      "hello",
+		FromJID:      "user@aiox.local",
+		AgentJID:     "agent@agents.aiox.local",
+		AgentName:    "Helper",
+		TraceParent:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Invoke:       true,
+		RoomJID:      "room@conference.aiox.local",
+		Attachments:  []Attachment{{URL: "https://f.example/a.png", MimeType: "image/png"}},
+		Priority:     PriorityHigh,
+		DebugContext: true,
+		UnredactedBy: uuid.NewString(),
+	}
+	data, err := codec.Marshal(task)
+	require.NoError(t, err)
+	var gotTask TaskMessage
+	require.NoError(t, codec.Unmarshal(data, &gotTask))
+	assert.Equal(t, task, gotTask)
+
+	inbound := InboundMessage{ID: "m1", FromJID: "a@b", ToJID: "c@d", Body: "hi", StanzaType: "groupchat", ReceivedAt: ts, RoomJID: "room@conference.b", Nickname: "alice", StanzaID: "s1", ReceiptRequested: true,
+		Attachments: []Attachment{{URL: "https://f.example/a.png", MimeType: "image/png", Size: 2048}, {URL: "https://f.example/b.pdf", Description: "b.pdf"}}}
+	data, err = codec.Marshal(&inbound)
+	require.NoError(t, err)
+	var gotInbound InboundMessage
+	require.NoError(t, codec.Unmarshal(data, &gotInbound))
+	assert.Equal(t, inbound, gotInbound)
+
+	outbound := OutboundMessage{ID: "o1", ToJID: "a@b", FromJID: "c@d", Body: "reply", InReplyTo: "m1", FromCache: true, RoomJID: "room@conference.b", ChatState: ChatStateActive, ReceiptID: "s1"}
+	data, err = codec.Marshal(outbound)
+	require.NoError(t, err)
+	var gotOutbound OutboundMessage
+	require.NoError(t, codec.Unmarshal(data, &gotOutbound))
+	assert.Equal(t, outbound, gotOutbound)
+
+	audit := AuditEvent{OwnerUserID: uuid.New(), EventType: "task_completed", Severity: "info", ResourceType: "agent", ResourceID: "x", Details: `{"a":1}`, Timestamp: ts}
+	data, err = codec.Marshal(audit)
+	require.NoError(t, err)
+	var gotAudit AuditEvent
+	require.NoError(t, codec.Unmarshal(data, &gotAudit))
+	assert.Equal(t, audit, gotAudit)
+
+	dl := DeadLetter{Task: task, Reason: "no workers available", Deliveries: 20, FailedAt: ts}
+	data, err = codec.Marshal(dl)
+	require.NoError(t, err)
+	var gotDL DeadLetter
+	require.NoError(t, codec.Unmarshal(data, &gotDL))
+	assert.Equal(t, dl, gotDL)
+}
+
+func TestProtobufCodec_UnknownType(t *testing.T) {
+	_, err := ProtobufCodec{}.Marshal(struct{}{})
+	assert.Error(t, err)
+}
+
+func TestDecode_UsesContentTypeHeader(t *testing.T) {
+	msg := OutboundMessage{ID: "o1", Body: "hi"}
+
+	pb, err := ProtobufCodec{}.Marshal(msg)
+	require.NoError(t, err)
+	var got OutboundMessage
+	require.NoError(t, Decode(nats.Header{HeaderContentType: []string{ContentTypeProtobuf}}, pb, &got))
+	assert.Equal(t, msg, got)
+
+	// Messages published before the header existed are JSON.
+	js, err := JSONCodec{}.Marshal(msg)
+	require.NoError(t, err)
+	got = OutboundMessage{}
+	require.NoError(t, Decode(nil, js, &got))
+	assert.Equal(t, msg, got)
+}
@@ -3,13 +3,60 @@ package nats
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/nats-io/nats.go/jetstream"
 )
 
+// Default consumer policy, used for zero values in ConsumerPolicy.
+const (
+	DefaultAckWait     = 30 * time.Second
+	DefaultBackoffBase = time.Second
+	DefaultBackoffMax  = 30 * time.Second
+)
+
+// ConsumerPolicy controls acknowledgment and redelivery for durable consumers.
+type ConsumerPolicy struct {
+	// AckWait is how long the server waits for an ack before redelivering.
+	AckWait time.Duration
+	// MaxDeliver caps deliveries per message. Zero or negative is unlimited.
+	MaxDeliver int
+	// BackoffBase is the delay before the first redelivery of a nak'd
+	// message; it doubles with each delivery up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// withDefaults fills zero durations with the package defaults.
+func (p ConsumerPolicy) withDefaults() ConsumerPolicy {
+	if p.AckWait <= 0 {
+		p.AckWait = DefaultAckWait
+	}
+	if p.BackoffBase <= 0 {
+		p.BackoffBase = DefaultBackoffBase
+	}
+	if p.BackoffMax <= 0 {
+		p.BackoffMax = DefaultBackoffMax
+	}
+	return p
+}
+
+// NakDelay returns the redelivery delay for a message delivered n times:
+// BackoffBase·2^(n-1), capped at BackoffMax.
+func (p ConsumerPolicy) NakDelay(delivered uint64) time.Duration {
+	p = p.withDefaults()
+	delay := p.BackoffBase
+	for i := uint64(1); i < delivered && delay < p.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, p.BackoffMax)
+}
+
 // ConsumerManager handles durable consumer creation and retrieval.
 type ConsumerManager struct {
-	js jetstream.JetStream
+	js     jetstream.JetStream
+	policy ConsumerPolicy
 }
 
 // NewConsumerManager creates a new ConsumerManager.
@@ -17,17 +64,50 @@ func NewConsumerManager(js jetstream.JetStream) *ConsumerManager {
 	return &ConsumerManager{js: js}
 }
 
+// SetPolicy sets the ack and redelivery policy for consumers. It must be
+// called before consumers are ensured.
+func (cm *ConsumerManager) SetPolicy(p ConsumerPolicy) {
+	cm.policy = p
+}
+
+// Policy returns the consumer policy with defaults applied.
+func (cm *ConsumerManager) Policy() ConsumerPolicy {
+	return cm.policy.withDefaults()
+}
+
 // EnsureConsumer creates or updates a durable consumer on the given stream.
 func (cm *ConsumerManager) EnsureConsumer(ctx context.Context, stream, name, filterSubject string) (jetstream.Consumer, error) {
+	consumer, err := cm.js.CreateOrUpdateConsumer(ctx, stream, cm.consumerConfig(name, filterSubject))
+	if err != nil {
+		return nil, fmt.Errorf("ensuring consumer %s on %s: %w", name, stream, err)
+	}
+	return consumer, nil
+}
+
+func (cm *ConsumerManager) consumerConfig(name, filterSubject string) jetstream.ConsumerConfig {
+	p := cm.Policy()
 	cfg := jetstream.ConsumerConfig{
 		Durable:       name,
 		FilterSubject: filterSubject,
 		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       p.AckWait,
+		MaxDeliver:    -1,
+	}
+	if p.MaxDeliver > 0 {
+		cfg.MaxDeliver = p.MaxDeliver
 	}
+	return cfg
+}
 
-	consumer, err := cm.js.CreateOrUpdateConsumer(ctx, stream, cfg)
-	if err != nil {
-		return nil, fmt.Errorf("ensuring consumer %s on %s: %w", name, stream, err)
+// Nak asks for msg to be redelivered after the policy's backoff delay, so
+// a failing dependency is retried with growing pauses instead of in a
+// tight loop.
+func (cm *ConsumerManager) Nak(msg jetstream.Msg) error {
+	var delivered uint64 = 1
+	if meta, err := msg.Metadata(); err == nil {
+		delivered = meta.NumDelivered
+	} else {
+		slog.Debug("reading message metadata for nak backoff", "error", err)
 	}
-	return consumer, nil
+	return msg.NakWithDelay(cm.Policy().NakDelay(delivered))
 }
@@ -0,0 +1,65 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumerPolicy_NakDelay(t *testing.T) {
+	p := ConsumerPolicy{BackoffBase: time.Second, BackoffMax: 10 * time.Second}
+	for delivered, want := range map[uint64]time.Duration{
+		0:   time.Second,
+		1:   time.Second,
+		2:   2 * time.Second,
+		3:   4 * time.Second,
+		4:   8 * time.Second,
+		5:   10 * time.Second,
+		500: 10 * time.Second,
+	} {
+		assert.Equal(t, want, p.NakDelay(delivered), "delivery %d", delivered)
+	}
+
+	assert.Equal(t, DefaultBackoffBase, ConsumerPolicy{}.NakDelay(1))
+	assert.Equal(t, DefaultBackoffMax, ConsumerPolicy{}.NakDelay(100))
+}
+
+func TestConsumerManager_ConsumerConfig(t *testing.T) {
+	cm := NewConsumerManager(nil)
+	cfg := cm.consumerConfig("orchestrator", SubjectInboundMessage)
+	assert.Equal(t, DefaultAckWait, cfg.AckWait)
+	assert.Equal(t, -1, cfg.MaxDeliver)
+	assert.Equal(t, jetstream.AckExplicitPolicy, cfg.AckPolicy)
+
+	cm.SetPolicy(ConsumerPolicy{AckWait: time.Minute, MaxDeliver: 50})
+	cfg = cm.consumerConfig("orchestrator", SubjectInboundMessage)
+	assert.Equal(t, time.Minute, cfg.AckWait)
+	assert.Equal(t, 50, cfg.MaxDeliver)
+}
+
+// nakMsg records the delay passed to NakWithDelay.
+type nakMsg struct {
+	jetstream.Msg
+	delivered uint64
+	delay     time.Duration
+}
+
+func (m *nakMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.delivered}, nil
+}
+
+func (m *nakMsg) NakWithDelay(d time.Duration) error {
+	m.delay = d
+	return nil
+}
+
+func TestConsumerManager_Nak(t *testing.T) {
+	cm := NewConsumerManager(nil)
+	cm.SetPolicy(ConsumerPolicy{BackoffBase: 500 * time.Millisecond, BackoffMax: time.Minute})
+
+	msg := &nakMsg{delivered: 4}
+	assert.NoError(t, cm.Nak(msg))
+	assert.Equal(t, 4*time.Second, msg.delay)
+}
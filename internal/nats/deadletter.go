@@ -0,0 +1,119 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ErrDeadLetterNotFound is returned when a dead letter does not exist or
+// belongs to another user.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetterRecord is a dead letter as stored in the AIOX_TASKS_DLQ stream.
+type DeadLetterRecord struct {
+	Sequence uint64 `json:"id"`
+	DeadLetter
+}
+
+// DeadLetterStore reads and retries dead-lettered tasks.
+type DeadLetterStore struct {
+	js        jetstream.JetStream
+	publisher *Publisher
+}
+
+// NewDeadLetterStore creates a DeadLetterStore. Retried tasks are republished
+// through publisher.
+func NewDeadLetterStore(js jetstream.JetStream, publisher *Publisher) *DeadLetterStore {
+	return &DeadLetterStore{js: js, publisher: publisher}
+}
+
+// List returns up to limit of the owner's dead letters, oldest first.
+func (s *DeadLetterStore) List(ctx context.Context, ownerID uuid.UUID, limit int) ([]*DeadLetterRecord, error) {
+	stream, err := s.js.Stream(ctx, StreamTasksDLQ)
+	if err != nil {
+		return nil, fmt.Errorf("getting dead-letter stream: %w", err)
+	}
+
+	consumer, err := stream.OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{ownerSubject(ownerID) + ".*"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating dead-letter reader: %w", err)
+	}
+
+	info, err := consumer.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading dead-letter consumer info: %w", err)
+	}
+	remaining := int(min(info.NumPending, uint64(limit)))
+
+	records := make([]*DeadLetterRecord, 0, remaining)
+	for remaining > 0 {
+		batch, err := consumer.Fetch(remaining, jetstream.FetchMaxWait(FetchTimeout))
+		if err != nil {
+			return nil, fmt.Errorf("fetching dead letters: %w", err)
+		}
+		received := 0
+		for msg := range batch.Messages() {
+			received++
+			meta, err := msg.Metadata()
+			if err != nil {
+				return nil, fmt.Errorf("reading dead-letter metadata: %w", err)
+			}
+			rec := &DeadLetterRecord{Sequence: meta.Sequence.Stream}
+			if err := Decode(msg.Headers(), msg.Data(), &rec.DeadLetter); err != nil {
+				return nil, fmt.Errorf("decoding dead letter %d: %w", meta.Sequence.Stream, err)
+			}
+			records = append(records, rec)
+		}
+		if received == 0 {
+			break
+		}
+		remaining -= received
+	}
+	return records, nil
+}
+
+// Retry republishes one of the owner's dead letters to its agent's task
+// subject and removes it from the dead-letter stream.
+func (s *DeadLetterStore) Retry(ctx context.Context, ownerID uuid.UUID, seq uint64) (*DeadLetterRecord, error) {
+	stream, err := s.js.Stream(ctx, StreamTasksDLQ)
+	if err != nil {
+		return nil, fmt.Errorf("getting dead-letter stream: %w", err)
+	}
+
+	raw, err := stream.GetMsg(ctx, seq)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("getting dead letter %d: %w", seq, err)
+	}
+	// The owner is part of the subject, so a sequence number from another
+	// user's dead letter is indistinguishable from a missing one.
+	if !strings.HasPrefix(raw.Subject, ownerSubject(ownerID)+".") {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	rec := &DeadLetterRecord{Sequence: seq}
+	if err := Decode(raw.Header, raw.Data, &rec.DeadLetter); err != nil {
+		return nil, fmt.Errorf("decoding dead letter %d: %w", seq, err)
+	}
+
+	if err := s.publisher.PublishTask(ctx, rec.Task.AgentID.String(), rec.Task); err != nil {
+		return nil, err
+	}
+	if err := stream.DeleteMsg(ctx, seq); err != nil {
+		return nil, fmt.Errorf("deleting dead letter %d: %w", seq, err)
+	}
+	return rec, nil
+}
+
+func ownerSubject(ownerID uuid.UUID) string {
+	return fmt.Sprintf("%s.%s", SubjectDeadTaskPrefix, ownerID)
+}
@@ -1,6 +1,7 @@
 package nats
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,17 +15,65 @@ const (
 	StreamMessages = "AIOX_MESSAGES"
 	StreamTasks    = "AIOX_TASKS"
 	StreamEvents   = "AIOX_EVENTS"
+	StreamTasksDLQ = "AIOX_TASKS_DLQ"
 )
 
 // Subject constants.
 const (
 	SubjectInboundMessage  = "aiox.messages.inbound"
 	SubjectOutboundMessage = "aiox.messages.outbound"
-	SubjectTaskPrefix      = "aiox.tasks"     // aiox.tasks.{agent_id}
+	SubjectTaskPrefix      = "aiox.tasks" // aiox.tasks[.{priority}].{agent_id}
 	SubjectAgentEvent      = "aiox.events.agent"
-	SubjectAuditEvent      = "aiox.events.audit"
+	SubjectAuditEvent      = "aiox.events.audit" // aiox.events.audit.{owner_user_id}
+	// Wildcards covering each stream's subjects.
+	SubjectMessagesAll = "aiox.messages.>"
+	SubjectTasksAll    = "aiox.tasks.>"
+	SubjectEventsAll   = "aiox.events.>"
+	SubjectAuditAll    = "aiox.events.audit.>"
+	// Dead letters live outside aiox.tasks.> so the task dispatcher never
+	// consumes them: aiox.dlq.tasks.{owner_user_id}.{agent_id}
+	SubjectDeadTaskPrefix = "aiox.dlq.tasks"
 )
 
+// Task priorities. Normal tasks keep the original aiox.tasks.{agent_id}
+// subject; high and low priority tasks add a token, e.g.
+// aiox.tasks.high.{agent_id}.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Priorities lists the task priorities from highest to lowest.
+var Priorities = []string{PriorityHigh, PriorityNormal, PriorityLow}
+
+// ValidPriority reports whether p names a task priority. Empty is valid and
+// means normal.
+func ValidPriority(p string) bool {
+	return p == "" || slices.Contains(Priorities, p)
+}
+
+// TaskSubject returns the subject a task for agentID is published on.
+// Unknown priorities are treated as normal.
+func TaskSubject(priority, agentID string) string {
+	switch priority {
+	case PriorityHigh, PriorityLow:
+		return SubjectTaskPrefix + "." + priority + "." + agentID
+	}
+	return SubjectTaskPrefix + "." + agentID
+}
+
+// TaskFilter returns the subject filter matching every task of a priority.
+func TaskFilter(priority string) string {
+	return TaskSubject(priority, "*")
+}
+
+// AuditSubject returns the subject an owner's audit events are published on,
+// so a feed for one owner can be filtered by the server.
+func AuditSubject(ownerID uuid.UUID) string {
+	return SubjectAuditEvent + "." + ownerID.String()
+}
+
 // InboundMessage is published when an XMPP message arrives at the component.
 type InboundMessage struct {
 	ID         string    `json:"id"`
@@ -33,8 +82,27 @@ type InboundMessage struct {
 	Body       string    `json:"body"`
 	StanzaType string    `json:"stanza_type"`
 	ReceivedAt time.Time `json:"received_at"`
+	// TraceParent is the W3C traceparent of the span that published the message.
+	TraceParent string `json:"trace_parent,omitempty"`
+	// RoomJID is the bare JID of the multi-user chat room a groupchat message
+	// was sent in, and Nickname the sender's occupant nickname there.
+	RoomJID  string `json:"room_jid,omitempty"`
+	Nickname string `json:"nickname,omitempty"`
+	// StanzaID is the id attribute of the <message> stanza, and
+	// ReceiptRequested whether the sender asked for a delivery receipt.
+	StanzaID         string `json:"stanza_id,omitempty"`
+	ReceiptRequested bool   `json:"receipt_requested,omitempty"`
+	// Attachments are the files shared with the message.
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
+// Chat states carried by OutboundMessage.ChatState (XEP-0085).
+const (
+	ChatStateActive    = "active"
+	ChatStateComposing = "composing"
+	ChatStatePaused    = "paused"
+)
+
 // OutboundMessage is published to send a message back via XMPP.
 type OutboundMessage struct {
 	ID        string `json:"id"`
@@ -42,6 +110,19 @@ type OutboundMessage struct {
 	FromJID   string `json:"from_jid"`
 	Body      string `json:"body"`
 	InReplyTo string `json:"in_reply_to,omitempty"`
+	FromCache bool   `json:"from_cache,omitempty"`
+	// TraceParent is the W3C traceparent of the span that published the message.
+	TraceParent string `json:"trace_parent,omitempty"`
+	// RoomJID, when set, sends the message to that room as a groupchat
+	// message instead of to ToJID.
+	RoomJID string `json:"room_jid,omitempty"`
+	// ChatState is the agent's chat state to send along with the message.
+	// Messages without a body only notify the state or a receipt.
+	ChatState string `json:"chat_state,omitempty"`
+	// ReceiptID acknowledges delivery of the stanza with this id (XEP-0184).
+	ReceiptID string `json:"receipt_id,omitempty"`
+	// Attachments are sent as out-of-band links.
+	Attachments []Attachment `json:"attachments,omitempty"`
 }
 
 // TaskMessage is published for agent task processing via Python workers.
@@ -53,6 +134,24 @@ type TaskMessage struct {
 	FromJID     string    `json:"from_jid"`
 	AgentJID    string    `json:"agent_jid"`
 	AgentName   string    `json:"agent_name"`
+	// TraceParent is the W3C traceparent of the span that published the task.
+	TraceParent string `json:"trace_parent,omitempty"`
+	// Invoke marks a task from the synchronous invoke endpoint. Its result goes
+	// to the waiting HTTP request instead of an outbound message.
+	Invoke bool `json:"invoke,omitempty"`
+	// RoomJID is set for messages from a multi-user chat room; the reply goes
+	// back to the room.
+	RoomJID string `json:"room_jid,omitempty"`
+	// Attachments are the files shared with the message.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Priority is the queue the task is published to. Empty means normal.
+	Priority string `json:"priority,omitempty"`
+	// DebugContext asks the dispatcher to store what is sent to the worker
+	// with the execution.
+	DebugContext bool `json:"debug_context,omitempty"`
+	// UnredactedBy is the admin who asked for this task to skip redaction.
+	// Empty for every other task.
+	UnredactedBy string `json:"unredacted_by,omitempty"`
 }
 
 // AgentEvent is published for agent lifecycle events.
@@ -74,3 +173,11 @@ type AuditEvent struct {
 	Details      string    `json:"details"`
 	Timestamp    time.Time `json:"timestamp"`
 }
+
+// DeadLetter is published when a task exceeds its delivery limit.
+type DeadLetter struct {
+	Task       TaskMessage `json:"task"`
+	Reason     string      `json:"reason"`
+	Deliveries uint64      `json:"deliveries"`
+	FailedAt   time.Time   `json:"failed_at"`
+}
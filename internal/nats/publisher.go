@@ -2,55 +2,95 @@ package nats
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 )
 
 // Publisher provides typed methods for publishing events to NATS JetStream.
 type Publisher struct {
-	js jetstream.JetStream
+	js     jetstream.JetStream
+	codec  Codec
+	buffer *publishBuffer
 }
 
-// NewPublisher creates a new Publisher.
+// NewPublisher creates a new Publisher that encodes payloads as JSON.
 func NewPublisher(js jetstream.JetStream) *Publisher {
-	return &Publisher{js: js}
+	return &Publisher{js: js, codec: JSONCodec{}}
+}
+
+// SetCodec changes the payload encoding. Every message carries a
+// Content-Type header, so consumers decode either encoding during a rollout.
+func (p *Publisher) SetCodec(c Codec) {
+	p.codec = c
 }
 
 // PublishInboundMessage publishes an inbound XMPP message for orchestrator processing.
 func (p *Publisher) PublishInboundMessage(ctx context.Context, msg InboundMessage) error {
-	return p.publish(ctx, SubjectInboundMessage, msg)
+	return p.publish(ctx, SubjectInboundMessage, msg, priorityNormal)
 }
 
 // PublishOutboundMessage publishes an outbound message for XMPP delivery.
 func (p *Publisher) PublishOutboundMessage(ctx context.Context, msg OutboundMessage) error {
-	return p.publish(ctx, SubjectOutboundMessage, msg)
+	return p.publish(ctx, SubjectOutboundMessage, msg, priorityNormal)
 }
 
-// PublishTask publishes a task for a specific agent (future Python worker processing).
+// PublishTask publishes a task for a specific agent on the subject of its
+// priority.
 func (p *Publisher) PublishTask(ctx context.Context, agentID string, msg TaskMessage) error {
-	subject := fmt.Sprintf("%s.%s", SubjectTaskPrefix, agentID)
-	return p.publish(ctx, subject, msg)
+	return p.publish(ctx, TaskSubject(msg.Priority, agentID), msg, priorityNormal)
 }
 
 // PublishAgentEvent publishes an agent lifecycle event.
 func (p *Publisher) PublishAgentEvent(ctx context.Context, event AgentEvent) error {
-	return p.publish(ctx, SubjectAgentEvent, event)
+	return p.publish(ctx, SubjectAgentEvent, event, priorityLow)
 }
 
-// PublishAuditEvent publishes an audit event.
+// PublishAuditEvent publishes an audit event on its owner's subject. While
+// NATS is down, events of severity error or critical wait for buffer space
+// rather than being dropped.
 func (p *Publisher) PublishAuditEvent(ctx context.Context, event AuditEvent) error {
-	return p.publish(ctx, SubjectAuditEvent, event)
+	priority := priorityLow
+	if event.Severity == "error" || event.Severity == "critical" {
+		priority = priorityCritical
+	}
+	return p.publish(ctx, AuditSubject(event.OwnerUserID), event, priority)
 }
 
-func (p *Publisher) publish(ctx context.Context, subject string, data any) error {
-	payload, err := json.Marshal(data)
+// PublishDeadLetter publishes a task that exceeded its delivery limit to the
+// dead-letter stream, keyed by owner and agent.
+func (p *Publisher) PublishDeadLetter(ctx context.Context, dl DeadLetter) error {
+	subject := fmt.Sprintf("%s.%s", ownerSubject(dl.Task.OwnerUserID), dl.Task.AgentID)
+	return p.publish(ctx, subject, dl, priorityNormal)
+}
+
+func (p *Publisher) publish(ctx context.Context, subject string, data any, priority publishPriority) error {
+	payload, err := p.codec.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("marshaling event for %s: %w", subject, err)
 	}
-	_, err = p.js.Publish(ctx, subject, payload)
+	msg := &nats.Msg{
+		Subject: subject,
+		Data:    payload,
+		Header:  nats.Header{HeaderContentType: []string{p.codec.ContentType()}},
+	}
+	if b := p.buffer; b != nil && (!b.conn.Healthy() || b.pending()) {
+		// Queue behind the buffered messages so this one can't overtake them.
+		if err := b.add(ctx, &bufferedMsg{msg: msg, priority: priority}); err != nil {
+			return err
+		}
+		if b.conn.Healthy() {
+			p.startDrain()
+		}
+		return nil
+	}
+	_, err = p.js.PublishMsg(ctx, msg)
 	if err != nil {
+		// The connection may have dropped during the publish.
+		if p.buffer != nil && !p.buffer.conn.Healthy() {
+			return p.buffer.add(ctx, &bufferedMsg{msg: msg, priority: priority})
+		}
 		return fmt.Errorf("publishing to %s: %w", subject, err)
 	}
 	return nil
@@ -0,0 +1,77 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/aiox-platform/aiox/internal/config"
+)
+
+// Default stream max ages, used when the config leaves them unset.
+const (
+	DefaultMessagesMaxAge   = 24 * time.Hour
+	DefaultTasksMaxAge      = time.Hour
+	DefaultEventsMaxAge     = 7 * 24 * time.Hour
+	DefaultDeadLetterMaxAge = 7 * 24 * time.Hour
+)
+
+// StreamConfigs returns the JetStream streams AIOX needs. Retention policies
+// are fixed: consumers rely on work-queue semantics for messages and tasks.
+func StreamConfigs(cfg config.NATSStreamsConfig) []jetstream.StreamConfig {
+	replicas := max(cfg.Replicas, 1)
+	return []jetstream.StreamConfig{
+		{
+			Name:      StreamMessages,
+			Subjects:  []string{SubjectMessagesAll},
+			Retention: jetstream.WorkQueuePolicy,
+			MaxAge:    orDefault(cfg.MessagesMaxAge, DefaultMessagesMaxAge),
+			Replicas:  replicas,
+		},
+		{
+			Name:      StreamTasks,
+			Subjects:  []string{SubjectTasksAll},
+			Retention: jetstream.WorkQueuePolicy,
+			MaxAge:    orDefault(cfg.TasksMaxAge, DefaultTasksMaxAge),
+			Replicas:  replicas,
+		},
+		{
+			Name:      StreamEvents,
+			Subjects:  []string{SubjectEventsAll},
+			Retention: jetstream.LimitsPolicy,
+			MaxAge:    orDefault(cfg.EventsMaxAge, DefaultEventsMaxAge),
+			Replicas:  replicas,
+		},
+		{
+			Name:      StreamTasksDLQ,
+			Subjects:  []string{SubjectDeadTaskPrefix + ".>"},
+			Retention: jetstream.LimitsPolicy,
+			MaxAge:    orDefault(cfg.DeadLetterMaxAge, DefaultDeadLetterMaxAge),
+			Replicas:  replicas,
+		},
+	}
+}
+
+// EnsureStreams creates the streams from StreamConfigs, or updates them to
+// match. It is idempotent and meant to run at startup, so a misconfigured
+// server fails fast instead of on the first publish.
+func EnsureStreams(ctx context.Context, js jetstream.JetStream, cfg config.NATSStreamsConfig) error {
+	for _, sc := range StreamConfigs(cfg) {
+		if _, err := js.CreateOrUpdateStream(ctx, sc); err != nil {
+			return fmt.Errorf("creating or updating stream %s (subjects %v, max age %s, %d replica(s)): %w",
+				sc.Name, sc.Subjects, sc.MaxAge, sc.Replicas, err)
+		}
+		slog.Debug("ensured NATS stream", "name", sc.Name, "max_age", sc.MaxAge, "replicas", sc.Replicas)
+	}
+	return nil
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
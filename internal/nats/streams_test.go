@@ -0,0 +1,48 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aiox-platform/aiox/internal/config"
+)
+
+func TestStreamConfigs(t *testing.T) {
+	byName := func(cfgs []jetstream.StreamConfig) map[string]jetstream.StreamConfig {
+		m := make(map[string]jetstream.StreamConfig)
+		for _, c := range cfgs {
+			m[c.Name] = c
+		}
+		return m
+	}
+
+	defaults := byName(StreamConfigs(config.NATSStreamsConfig{}))
+	assert.Len(t, defaults, 4)
+	assert.Equal(t, DefaultTasksMaxAge, defaults[StreamTasks].MaxAge)
+	assert.Equal(t, 1, defaults[StreamTasks].Replicas)
+	assert.Equal(t, jetstream.WorkQueuePolicy, defaults[StreamMessages].Retention)
+	assert.Equal(t, []string{SubjectEventsAll}, defaults[StreamEvents].Subjects)
+
+	custom := byName(StreamConfigs(config.NATSStreamsConfig{Replicas: 3, EventsMaxAge: 30 * 24 * time.Hour}))
+	assert.Equal(t, 30*24*time.Hour, custom[StreamEvents].MaxAge)
+	assert.Equal(t, DefaultMessagesMaxAge, custom[StreamMessages].MaxAge)
+	for _, c := range custom {
+		assert.Equal(t, 3, c.Replicas, c.Name)
+	}
+}
+
+func TestTaskSubject(t *testing.T) {
+	assert.Equal(t, "aiox.tasks.a1", TaskSubject("", "a1"))
+	assert.Equal(t, "aiox.tasks.a1", TaskSubject(PriorityNormal, "a1"))
+	assert.Equal(t, "aiox.tasks.a1", TaskSubject("urgent", "a1"))
+	assert.Equal(t, "aiox.tasks.high.a1", TaskSubject(PriorityHigh, "a1"))
+	assert.Equal(t, "aiox.tasks.low.*", TaskFilter(PriorityLow))
+	assert.Equal(t, "aiox.tasks.*", TaskFilter(PriorityNormal))
+
+	assert.True(t, ValidPriority(""))
+	assert.True(t, ValidPriority(PriorityLow))
+	assert.False(t, ValidPriority("urgent"))
+}
@@ -2,15 +2,36 @@ package orchestrator
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go/jetstream"
 
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/governance/moderation"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 	"github.com/aiox-platform/aiox/internal/governance/quota"
 	inats "github.com/aiox-platform/aiox/internal/nats"
+	"github.com/aiox-platform/aiox/internal/tracing"
+	ixmpp "github.com/aiox-platform/aiox/internal/xmpp"
+)
+
+// RoutingFailureMode selects how a message that cannot be routed to an agent
+// is answered.
+type RoutingFailureMode string
+
+const (
+	// RoutingFailureReply answers the sender with why the message was not
+	// delivered and records a routing_failed audit event.
+	RoutingFailureReply RoutingFailureMode = "reply"
+	// RoutingFailureAudit records the audit event without answering.
+	RoutingFailureAudit RoutingFailureMode = "audit"
+	// RoutingFailureSilent only logs the failure.
+	RoutingFailureSilent RoutingFailureMode = "silent"
 )
 
 // Orchestrator consumes inbound messages, validates ownership, routes them,
@@ -21,6 +42,9 @@ type Orchestrator struct {
 	validator   *Validator
 	router      *Router
 	quotaSvc    *quota.Service
+	senders     *SenderLimiter
+	moderator   *moderation.Engine
+	onUnrouted  RoutingFailureMode
 }
 
 // NewOrchestrator creates a new Orchestrator.
@@ -37,6 +61,27 @@ func NewOrchestrator(
 		validator:   validator,
 		router:      router,
 		quotaSvc:    quotaSvc,
+		onUnrouted:  RoutingFailureReply,
+	}
+}
+
+// SetSenderLimiter enables per-sender rate limiting for agents that set
+// max_messages_per_minute. It must be called before Start.
+func (o *Orchestrator) SetSenderLimiter(l *SenderLimiter) {
+	o.senders = l
+}
+
+// SetModerator screens inbound messages before a task is published. It must
+// be called before Start.
+func (o *Orchestrator) SetModerator(m *moderation.Engine) {
+	o.moderator = m
+}
+
+// SetRoutingFailureMode sets how messages to an unknown or malformed agent
+// address are answered. It must be called before Start.
+func (o *Orchestrator) SetRoutingFailureMode(m RoutingFailureMode) {
+	if m != "" {
+		o.onUnrouted = m
 	}
 }
 
@@ -71,40 +116,136 @@ func (o *Orchestrator) Start(ctx context.Context) error {
 
 func (o *Orchestrator) processMessage(ctx context.Context, msg jetstream.Msg) {
 	var inbound inats.InboundMessage
-	if err := json.Unmarshal(msg.Data(), &inbound); err != nil {
+	if err := inats.Decode(msg.Headers(), msg.Data(), &inbound); err != nil {
 		slog.Error("unmarshaling inbound message", "error", err)
-		_ = msg.Nak()
+		_ = o.consumerMgr.Nak(msg)
 		return
 	}
 
-	slog.Debug("orchestrator processing message",
-		"id", inbound.ID,
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, inbound.TraceParent), "orchestrator.process",
+		tracing.AttrRequestID.String(inbound.ID),
+	)
+	defer span.End()
+
+	log := slog.With("request_id", inbound.ID)
+
+	log.Debug("orchestrator processing message",
 		"from", inbound.FromJID,
 		"to", inbound.ToJID,
 	)
 
 	// Route: resolve target agent from JID
 	route, err := o.router.Route(ctx, inbound.ToJID)
+	if errors.Is(err, ErrAgentPaused) {
+		log.Info("agent is paused", "to_jid", inbound.ToJID)
+		o.sendErrorResponse(ctx, inbound, "Agent is paused")
+		_ = msg.Ack()
+		return
+	}
 	if err != nil {
-		slog.Warn("routing failed", "error", err, "to_jid", inbound.ToJID)
-		o.sendErrorResponse(ctx, inbound, "Agent not found")
+		o.rejectUnroutable(ctx, log, inbound, err)
 		_ = msg.Ack()
 		return
 	}
 
+	span.SetAttributes(tracing.AttrAgentID.String(route.AgentID.String()))
+
 	// Validate ownership and governance
 	if err := o.validator.Validate(route); err != nil {
-		slog.Warn("validation failed", "error", err, "agent_id", route.AgentID)
+		log.Warn("validation failed", "error", err, "agent_id", route.AgentID)
 		o.sendErrorResponse(ctx, inbound, "Message not authorized")
 		_ = msg.Ack()
 		return
 	}
 
+	// Drop messages from senders outside the agent's allowed domains. No reply is
+	// sent so the agent's existence isn't confirmed to foreign servers.
+	if err := o.validator.ValidateSender(route, inbound.FromJID); err != nil {
+		log.Warn("sender rejected", "error", err, "agent_id", route.AgentID, "from", inbound.FromJID)
+		audit := inats.AuditEvent{
+			OwnerUserID:  route.OwnerUserID,
+			EventType:    "message_rejected",
+			Severity:     "warn",
+			ResourceType: "agent",
+			ResourceID:   route.AgentID.String(),
+			Details:      "Message from " + inbound.FromJID + " dropped: " + err.Error(),
+			Timestamp:    time.Now().UTC(),
+		}
+		if err := o.publisher.PublishAuditEvent(ctx, audit); err != nil {
+			log.Error("publishing audit event", "error", err)
+		}
+		_ = msg.Ack()
+		return
+	}
+
+	// Acknowledge delivery as soon as the message is accepted from the sender
+	if inbound.ReceiptRequested && inbound.StanzaID != "" && agents.ParseChatFeatures(route.Capabilities).DeliveryReceipts {
+		o.sendReceipt(ctx, inbound)
+	}
+
+	// Throttle a single sender flooding the agent. Checked before the quota so
+	// throttled messages don't count against the owner.
+	if o.senders != nil {
+		allowed, limit, err := o.senders.Allow(ctx, route, inbound.FromJID)
+		if err != nil {
+			log.Warn("sender rate limiter check failed, allowing message", "error", err)
+		} else if !allowed {
+			log.Warn("sender throttled", "agent_id", route.AgentID, "from", inbound.FromJID, "limit", limit)
+			o.sendReply(ctx, inbound, "You're sending messages too quickly. Please wait a moment and try again.")
+			audit := inats.AuditEvent{
+				OwnerUserID:  route.OwnerUserID,
+				EventType:    "message_throttled",
+				Severity:     "warn",
+				ResourceType: "agent",
+				ResourceID:   route.AgentID.String(),
+				Details:      fmt.Sprintf("Message %s from %s throttled: over %d messages per minute", inbound.ID, inbound.FromJID, limit),
+				Timestamp:    time.Now().UTC(),
+			}
+			if err := o.publisher.PublishAuditEvent(ctx, audit); err != nil {
+				log.Error("publishing audit event", "error", err)
+			}
+			_ = msg.Ack()
+			return
+		}
+	}
+
+	// Keep disallowed content away from the LLM
+	if o.moderator != nil {
+		gov := policy.Parse(route.Governance)
+		allowed, reason, err := o.moderator.ForAgent(gov.Moderation).Check(ctx, inbound.Body)
+		if err != nil {
+			log.Warn("content moderation check failed", "error", err, "agent_id", route.AgentID, "allowed", allowed)
+		}
+		if !allowed {
+			log.Warn("content blocked", "agent_id", route.AgentID, "from", inbound.FromJID, "reason", reason)
+			o.sendReply(ctx, inbound, moderation.Refusal(gov.Moderation))
+			audit := inats.AuditEvent{
+				OwnerUserID:  route.OwnerUserID,
+				EventType:    "content_blocked",
+				Severity:     "warn",
+				ResourceType: "agent",
+				ResourceID:   route.AgentID.String(),
+				Details:      fmt.Sprintf("Message %s from %s blocked: %s", inbound.ID, inbound.FromJID, reason),
+				Timestamp:    time.Now().UTC(),
+			}
+			if err := o.publisher.PublishAuditEvent(ctx, audit); err != nil {
+				log.Error("publishing audit event", "error", err)
+			}
+			_ = msg.Ack()
+			return
+		}
+	}
+
 	// Check quota (fast-fail before NATS publish)
 	if o.quotaSvc != nil {
 		if err := o.quotaSvc.CheckQuota(ctx, route.OwnerUserID); err != nil {
-			slog.Warn("quota exceeded", "error", err, "user_id", route.OwnerUserID)
-			o.sendErrorResponse(ctx, inbound, "Quota exceeded: "+err.Error())
+			o.rejectOverQuota(ctx, log, inbound, err, "user_id", route.OwnerUserID)
+			_ = msg.Ack()
+			return
+		}
+		limits := policy.Parse(route.Governance).Quota.Limits()
+		if err := o.quotaSvc.CheckAgentQuota(ctx, route.AgentID, route.OwnerUserID, limits); err != nil {
+			o.rejectOverQuota(ctx, log, inbound, err, "agent_id", route.AgentID)
 			_ = msg.Ack()
 			return
 		}
@@ -119,9 +260,14 @@ func (o *Orchestrator) processMessage(ctx context.Context, msg jetstream.Msg) {
 		FromJID:     inbound.FromJID,
 		AgentJID:    route.AgentJID,
 		AgentName:   route.AgentName,
+		TraceParent: tracing.TraceParent(ctx),
+		RoomJID:     inbound.RoomJID,
+		Attachments: inbound.Attachments,
+		Priority:    policy.Parse(route.Governance).TaskPriority(inbound.FromJID),
 	}
 	if err := o.publisher.PublishTask(ctx, route.AgentID.String(), task); err != nil {
-		slog.Error("publishing task", "error", err)
+		span.RecordError(err)
+		log.Error("publishing task", "error", err)
 	}
 
 	// Publish audit event
@@ -131,25 +277,134 @@ func (o *Orchestrator) processMessage(ctx context.Context, msg jetstream.Msg) {
 		Severity:     "info",
 		ResourceType: "agent",
 		ResourceID:   route.AgentID.String(),
-		Details:      "Message routed from " + inbound.FromJID,
+		Details:      "Message " + inbound.ID + " routed from " + inbound.FromJID,
 		Timestamp:    time.Now().UTC(),
 	}
 	if err := o.publisher.PublishAuditEvent(ctx, audit); err != nil {
-		slog.Error("publishing audit event", "error", err)
+		log.Error("publishing audit event", "error", err)
 	}
 
 	_ = msg.Ack()
 }
 
+// rejectOverQuota answers a message refused by a quota check. Limit hits are
+// reported as such; any other failure gets a generic error so it is not
+// mistaken for the user running out of quota.
+func (o *Orchestrator) rejectOverQuota(ctx context.Context, log *slog.Logger, inbound inats.InboundMessage, err error, attrs ...any) {
+	var limitErr *quota.LimitError
+	if errors.As(err, &limitErr) {
+		log.Warn("quota exceeded", append(attrs, "limit", limitErr.Limit, "error", err)...)
+		o.sendErrorResponse(ctx, inbound, "Quota exceeded: "+limitErr.Error())
+		return
+	}
+	log.Error("quota check failed", append(attrs, "error", err)...)
+	o.sendErrorResponse(ctx, inbound, "could not check your quota, please try again later")
+}
+
+// rejectUnroutable answers a message Route could not resolve. A malformed
+// address and a missing agent get distinct replies with a routing_failed
+// audit event, as the routing failure mode allows; a lookup failure gets a
+// generic error so it is not mistaken for a missing agent.
+func (o *Orchestrator) rejectUnroutable(ctx context.Context, log *slog.Logger, inbound inats.InboundMessage, err error) {
+	var reply string
+	switch {
+	case errors.Is(err, ErrMalformedAgentJID):
+		reply = fmt.Sprintf("%s is not an agent address. Agent addresses look like agent-<id>@%s.", bareJID(inbound.ToJID), jidDomain(inbound.ToJID))
+	case errors.Is(err, ErrAgentNotFound):
+		reply = fmt.Sprintf("No agent exists at %s. It may have been deleted; check the address or list your agents.", bareJID(inbound.ToJID))
+	default:
+		log.Error("routing failed", "error", err, "to_jid", inbound.ToJID)
+		o.sendErrorResponse(ctx, inbound, "could not route your message, please try again later")
+		return
+	}
+
+	log.Warn("routing failed", "error", err, "to_jid", inbound.ToJID)
+	if o.onUnrouted == RoutingFailureSilent {
+		return
+	}
+	o.auditRoutingFailure(ctx, log, inbound, err)
+	if o.onUnrouted == RoutingFailureReply {
+		o.sendErrorResponse(ctx, inbound, reply)
+	}
+}
+
+// auditRoutingFailure records a routing_failed audit event for the owner of
+// the addressed agent, or for the sender. Failures with neither are only
+// logged.
+func (o *Orchestrator) auditRoutingFailure(ctx context.Context, log *slog.Logger, inbound inats.InboundMessage, err error) {
+	ownerID, lookupErr := o.router.MisroutedOwner(ctx, inbound.ToJID, inbound.FromJID)
+	if lookupErr != nil {
+		log.Error("resolving owner of unroutable message", "error", lookupErr)
+		return
+	}
+	if ownerID == uuid.Nil {
+		return
+	}
+
+	resourceID := ""
+	if agentID, err := ixmpp.ExtractAgentID(inbound.ToJID); err == nil {
+		resourceID = agentID.String()
+	}
+	audit := inats.AuditEvent{
+		OwnerUserID:  ownerID,
+		EventType:    "routing_failed",
+		Severity:     "warn",
+		ResourceType: "agent",
+		ResourceID:   resourceID,
+		Details:      fmt.Sprintf("Message %s from %s to %s not routed: %v", inbound.ID, inbound.FromJID, inbound.ToJID, err),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := o.publisher.PublishAuditEvent(ctx, audit); err != nil {
+		log.Error("publishing audit event", "error", err)
+	}
+}
+
+// bareJID strips the resource from jid.
+func bareJID(jid string) string {
+	bare, _, _ := strings.Cut(jid, "/")
+	return bare
+}
+
+// jidDomain returns the domain part of jid.
+func jidDomain(jid string) string {
+	bare := bareJID(jid)
+	if i := strings.LastIndex(bare, "@"); i >= 0 {
+		return bare[i+1:]
+	}
+	return bare
+}
+
 func (o *Orchestrator) sendErrorResponse(ctx context.Context, inbound inats.InboundMessage, errMsg string) {
+	o.sendReply(ctx, inbound, "Error: "+errMsg)
+}
+
+// sendReply sends body back to the sender on the agent's behalf, or to the
+// room for a groupchat message.
+func (o *Orchestrator) sendReply(ctx context.Context, inbound inats.InboundMessage, body string) {
+	outbound := inats.OutboundMessage{
+		ID:          uuid.New().String(),
+		ToJID:       inbound.FromJID,
+		FromJID:     inbound.ToJID,
+		Body:        body,
+		InReplyTo:   inbound.ID,
+		TraceParent: tracing.TraceParent(ctx),
+		RoomJID:     inbound.RoomJID,
+	}
+	if err := o.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
+		slog.Error("publishing reply", "error", err, "request_id", inbound.ID)
+	}
+}
+
+// sendReceipt acknowledges delivery of the inbound stanza (XEP-0184).
+func (o *Orchestrator) sendReceipt(ctx context.Context, inbound inats.InboundMessage) {
 	outbound := inats.OutboundMessage{
-		ID:        uuid.New().String(),
-		ToJID:     inbound.FromJID,
-		FromJID:   inbound.ToJID,
-		Body:      "Error: " + errMsg,
-		InReplyTo: inbound.ID,
+		ID:          uuid.New().String(),
+		ToJID:       inbound.FromJID,
+		FromJID:     inbound.ToJID,
+		ReceiptID:   inbound.StanzaID,
+		TraceParent: tracing.TraceParent(ctx),
 	}
 	if err := o.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
-		slog.Error("publishing error response", "error", err)
+		slog.Error("publishing delivery receipt", "error", err, "request_id", inbound.ID)
 	}
 }
@@ -0,0 +1,36 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+	"github.com/aiox-platform/aiox/internal/governance/quota"
+)
+
+// SenderLimiter caps how many messages one sender JID may send an agent per
+// minute, using the governance max_messages_per_minute of the target agent.
+// It is independent of the owner's quota, so one noisy client cannot flood
+// an agent on its owner's behalf.
+type SenderLimiter struct {
+	limiter *quota.RateLimiter
+}
+
+// NewSenderLimiter creates a SenderLimiter backed by the Redis sliding window.
+func NewSenderLimiter(limiter *quota.RateLimiter) *SenderLimiter {
+	return &SenderLimiter{limiter: limiter}
+}
+
+// Allow reports whether fromJID may send another message to the routed agent
+// and, if so, counts it. The limit applies to the bare JID, so switching XMPP
+// resources does not reset the window. It returns the configured limit for
+// use in replies; zero means the agent sets no limit.
+func (l *SenderLimiter) Allow(ctx context.Context, route *RouteResult, fromJID string) (bool, int, error) {
+	limit := policy.Parse(route.Governance).MaxMessagesPerMinute
+	if limit <= 0 {
+		return true, 0, nil
+	}
+	bare, _, _ := strings.Cut(fromJID, "/")
+	allowed, err := l.limiter.CheckAndIncrementSender(ctx, route.AgentID, strings.ToLower(bare), limit)
+	return allowed, limit, err
+}
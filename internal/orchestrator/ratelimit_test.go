@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/governance/quota"
+)
+
+func newTestSenderLimiter(t *testing.T) *SenderLimiter {
+	t.Helper()
+	s := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	return NewSenderLimiter(quota.NewRateLimiter(rdb))
+}
+
+func TestSenderLimiter_PerSender(t *testing.T) {
+	l := newTestSenderLimiter(t)
+	ctx := context.Background()
+	route := &RouteResult{AgentID: uuid.New(), Governance: []byte(`{"max_messages_per_minute":2}`)}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := l.Allow(ctx, route, "alice@example.com/phone")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// A different resource of the same account shares the window.
+	allowed, limit, err := l.Allow(ctx, route, "Alice@example.com/laptop")
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 2, limit)
+
+	// Other senders and other agents are unaffected.
+	allowed, _, err = l.Allow(ctx, route, "bob@example.com")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	other := &RouteResult{AgentID: uuid.New(), Governance: route.Governance}
+	allowed, _, err = l.Allow(ctx, other, "alice@example.com")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestSenderLimiter_NoLimit(t *testing.T) {
+	l := newTestSenderLimiter(t)
+	route := &RouteResult{AgentID: uuid.New(), Governance: []byte(`{}`)}
+
+	for i := 0; i < 5; i++ {
+		allowed, limit, err := l.Allow(context.Background(), route, "alice@example.com")
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Zero(t, limit)
+	}
+}
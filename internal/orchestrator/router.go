@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -10,14 +11,26 @@ import (
 	ixmpp "github.com/aiox-platform/aiox/internal/xmpp"
 )
 
+var (
+	// ErrMalformedAgentJID is returned by Route when the recipient is not an
+	// agent-<uuid> address.
+	ErrMalformedAgentJID = errors.New("not an agent JID")
+	// ErrAgentNotFound is returned by Route when no active agent has the
+	// recipient's ID.
+	ErrAgentNotFound = errors.New("agent not found")
+	// ErrAgentPaused is returned by Route when the recipient agent is paused.
+	ErrAgentPaused = errors.New("agent is paused")
+)
+
 // RouteResult contains the resolved agent information for a message.
 type RouteResult struct {
-	AgentID     uuid.UUID
-	OwnerUserID uuid.UUID
-	AgentName   string
-	AgentJID    string
-	Visibility  string
-	Governance  []byte
+	AgentID      uuid.UUID
+	OwnerUserID  uuid.UUID
+	AgentName    string
+	AgentJID     string
+	Visibility   string
+	Governance   []byte
+	Capabilities []byte
 }
 
 // Router resolves JIDs to agents using the agents repository.
@@ -34,7 +47,7 @@ func NewRouter(agentRepo agents.Repository) *Router {
 func (r *Router) Route(ctx context.Context, toJID string) (*RouteResult, error) {
 	agentID, err := ixmpp.ExtractAgentID(toJID)
 	if err != nil {
-		return nil, fmt.Errorf("extracting agent ID: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrMalformedAgentJID, err)
 	}
 
 	row, err := r.agentRepo.GetByID(ctx, agentID)
@@ -42,7 +55,10 @@ func (r *Router) Route(ctx context.Context, toJID string) (*RouteResult, error)
 		return nil, fmt.Errorf("looking up agent: %w", err)
 	}
 	if row == nil {
-		return nil, fmt.Errorf("agent %s not found", agentID)
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, agentID)
+	}
+	if row.Status == agents.StatusPaused {
+		return nil, fmt.Errorf("%w: %s", ErrAgentPaused, agentID)
 	}
 
 	// Parse profile to get agent name
@@ -53,11 +69,30 @@ func (r *Router) Route(ctx context.Context, toJID string) (*RouteResult, error)
 	}
 
 	return &RouteResult{
-		AgentID:     row.ID,
-		OwnerUserID: row.OwnerUserID,
-		AgentName:   name,
-		AgentJID:    row.JID,
-		Visibility:  row.Visibility,
-		Governance:  row.Governance,
+		AgentID:      row.ID,
+		OwnerUserID:  row.OwnerUserID,
+		AgentName:    name,
+		AgentJID:     row.JID,
+		Visibility:   row.Visibility,
+		Governance:   row.Governance,
+		Capabilities: row.Capabilities,
 	}, nil
 }
+
+// MisroutedOwner returns the user to notify about a message that could not be
+// routed to toJID: the owner of the deleted agent it addressed, or else the
+// platform user who sent it over WebSocket chat. It returns uuid.Nil when
+// neither is known.
+func (r *Router) MisroutedOwner(ctx context.Context, toJID, fromJID string) (uuid.UUID, error) {
+	if agentID, err := ixmpp.ExtractAgentID(toJID); err == nil {
+		ownerID, err := r.agentRepo.GetOwnerID(ctx, agentID)
+		if err != nil || ownerID != uuid.Nil {
+			return ownerID, err
+		}
+	}
+
+	if userID, ok := ixmpp.WebSocketUserID(fromJID); ok {
+		return userID, nil
+	}
+	return uuid.Nil, nil
+}
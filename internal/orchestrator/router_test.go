@@ -0,0 +1,124 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// fakeAgentRepo holds active agents and the owners of deleted ones.
+type fakeAgentRepo struct {
+	agents.Repository
+	active  map[uuid.UUID]*agents.AgentRow
+	deleted map[uuid.UUID]uuid.UUID
+	err     error
+}
+
+func (r *fakeAgentRepo) GetByID(_ context.Context, id uuid.UUID) (*agents.AgentRow, error) {
+	return r.active[id], r.err
+}
+
+func (r *fakeAgentRepo) GetOwnerID(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	if row, ok := r.active[id]; ok {
+		return row.OwnerUserID, r.err
+	}
+	return r.deleted[id], r.err
+}
+
+// subjectsJS records the subject of every message published through a
+// Publisher.
+type subjectsJS struct {
+	jetstream.JetStream
+	subjects []string
+}
+
+func (js *subjectsJS) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	js.subjects = append(js.subjects, msg.Subject)
+	return &jetstream.PubAck{}, nil
+}
+
+func TestRouter_RouteErrors(t *testing.T) {
+	repo := &fakeAgentRepo{}
+	r := NewRouter(repo)
+	ctx := context.Background()
+
+	_, err := r.Route(ctx, "support@agents.aiox.local")
+	assert.ErrorIs(t, err, ErrMalformedAgentJID)
+
+	_, err = r.Route(ctx, "agent-"+uuid.NewString()+"@agents.aiox.local/web")
+	assert.ErrorIs(t, err, ErrAgentNotFound)
+
+	pausedID := uuid.New()
+	repo.active = map[uuid.UUID]*agents.AgentRow{pausedID: {ID: pausedID, Status: agents.StatusPaused}}
+	_, err = r.Route(ctx, "agent-"+pausedID.String()+"@agents.aiox.local")
+	assert.ErrorIs(t, err, ErrAgentPaused)
+
+	repo.err = errors.New("connection refused")
+	_, err = r.Route(ctx, "agent-"+uuid.NewString()+"@agents.aiox.local")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrAgentNotFound)
+	assert.NotErrorIs(t, err, ErrMalformedAgentJID)
+}
+
+func TestRouter_MisroutedOwner(t *testing.T) {
+	agentID, ownerID, userID := uuid.New(), uuid.New(), uuid.New()
+	r := NewRouter(&fakeAgentRepo{deleted: map[uuid.UUID]uuid.UUID{agentID: ownerID}})
+	ctx := context.Background()
+
+	got, err := r.MisroutedOwner(ctx, "agent-"+agentID.String()+"@agents.aiox.local", "alice@aiox.local")
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, got, "the deleted agent's owner")
+
+	got, err = r.MisroutedOwner(ctx, "support@agents.aiox.local", "user-"+userID.String()+"@ws.aiox.local/tab")
+	require.NoError(t, err)
+	assert.Equal(t, userID, got, "the WebSocket sender")
+
+	got, err = r.MisroutedOwner(ctx, "agent-"+uuid.NewString()+"@agents.aiox.local", "alice@aiox.local")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, got)
+}
+
+func TestOrchestrator_RejectUnroutable(t *testing.T) {
+	agentID, ownerID := uuid.New(), uuid.New()
+	repo := &fakeAgentRepo{deleted: map[uuid.UUID]uuid.UUID{agentID: ownerID}}
+	inbound := inats.InboundMessage{
+		ID:      uuid.NewString(),
+		FromJID: "alice@aiox.local/phone",
+		ToJID:   "agent-" + agentID.String() + "@agents.aiox.local",
+	}
+
+	for mode, want := range map[RoutingFailureMode][]string{
+		RoutingFailureReply:  {inats.AuditSubject(ownerID), inats.SubjectOutboundMessage},
+		RoutingFailureAudit:  {inats.AuditSubject(ownerID)},
+		RoutingFailureSilent: nil,
+	} {
+		js := &subjectsJS{}
+		o := NewOrchestrator(inats.NewPublisher(js), nil, NewValidator(), NewRouter(repo), nil)
+		o.SetRoutingFailureMode(mode)
+
+		_, err := o.router.Route(context.Background(), inbound.ToJID)
+		require.ErrorIs(t, err, ErrAgentNotFound)
+		o.rejectUnroutable(context.Background(), slog.Default(), inbound, err)
+		assert.Equal(t, want, js.subjects, mode)
+	}
+}
+
+func TestOrchestrator_RejectUnroutable_LookupFailure(t *testing.T) {
+	js := &subjectsJS{}
+	o := NewOrchestrator(inats.NewPublisher(js), nil, NewValidator(), NewRouter(&fakeAgentRepo{}), nil)
+	o.SetRoutingFailureMode(RoutingFailureSilent)
+
+	inbound := inats.InboundMessage{ID: uuid.NewString(), FromJID: "alice@aiox.local", ToJID: "agent-" + uuid.NewString() + "@agents.aiox.local"}
+	o.rejectUnroutable(context.Background(), slog.Default(), inbound, errors.New("looking up agent: timeout"))
+	assert.Equal(t, []string{inats.SubjectOutboundMessage}, js.subjects, "infrastructure errors are always answered")
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/google/uuid"
 
-	"github.com/aiox-platform/aiox/internal/governance"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 )
 
 // Validator checks ownership and governance rules for message routing.
@@ -42,7 +42,7 @@ func (v *Validator) checkGovernance(route *RouteResult) error {
 		return nil
 	}
 
-	gov := governance.ParseGovernance(route.Governance)
+	gov := policy.Parse(route.Governance)
 
 	// Check if agent is blocked
 	if gov.Blocked {
@@ -60,6 +60,25 @@ func (v *Validator) checkGovernance(route *RouteResult) error {
 	return nil
 }
 
+// ValidateSender checks the sender's JID domain against the agent's
+// allowed_sender_domains policy.
+func (v *Validator) ValidateSender(route *RouteResult, fromJID string) error {
+	if len(route.Governance) == 0 || string(route.Governance) == "null" {
+		return nil
+	}
+
+	gov := policy.Parse(route.Governance)
+	if len(gov.AllowedSenderDomains) == 0 {
+		return nil
+	}
+
+	senderDomain := extractDomain(fromJID)
+	if !domainAllowed(senderDomain, gov.AllowedSenderDomains) {
+		return fmt.Errorf("sender domain %q not in allowed sender domains", senderDomain)
+	}
+	return nil
+}
+
 func extractDomain(jid string) string {
 	// Strip resource
 	bare := jid
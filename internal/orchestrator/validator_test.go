@@ -8,7 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/aiox-platform/aiox/internal/governance"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 )
 
 func TestValidator_Validate(t *testing.T) {
@@ -61,7 +61,7 @@ func TestValidator_Validate(t *testing.T) {
 	})
 
 	t.Run("allowed domain passes", func(t *testing.T) {
-		gov, _ := json.Marshal(governance.GovernanceConfig{AllowedDomains: []string{"agents.aiox.local"}})
+		gov, _ := json.Marshal(policy.GovernancePolicy{AllowedDomains: []string{"agents.aiox.local"}})
 		route := &RouteResult{
 			AgentID:     uuid.New(),
 			OwnerUserID: uuid.New(),
@@ -72,7 +72,7 @@ func TestValidator_Validate(t *testing.T) {
 	})
 
 	t.Run("disallowed domain fails", func(t *testing.T) {
-		gov, _ := json.Marshal(governance.GovernanceConfig{AllowedDomains: []string{"other.domain.com"}})
+		gov, _ := json.Marshal(policy.GovernancePolicy{AllowedDomains: []string{"other.domain.com"}})
 		route := &RouteResult{
 			AgentID:     uuid.New(),
 			OwnerUserID: uuid.New(),
@@ -83,7 +83,7 @@ func TestValidator_Validate(t *testing.T) {
 	})
 
 	t.Run("domain check is case insensitive", func(t *testing.T) {
-		gov, _ := json.Marshal(governance.GovernanceConfig{AllowedDomains: []string{"AGENTS.AIOX.LOCAL"}})
+		gov, _ := json.Marshal(policy.GovernancePolicy{AllowedDomains: []string{"AGENTS.AIOX.LOCAL"}})
 		route := &RouteResult{
 			AgentID:     uuid.New(),
 			OwnerUserID: uuid.New(),
@@ -94,7 +94,7 @@ func TestValidator_Validate(t *testing.T) {
 	})
 
 	t.Run("blocked agent fails", func(t *testing.T) {
-		gov, _ := json.Marshal(governance.GovernanceConfig{Blocked: true})
+		gov, _ := json.Marshal(policy.GovernancePolicy{Blocked: true})
 		route := &RouteResult{
 			AgentID:     uuid.New(),
 			OwnerUserID: uuid.New(),
@@ -107,7 +107,7 @@ func TestValidator_Validate(t *testing.T) {
 	})
 
 	t.Run("blocked false passes", func(t *testing.T) {
-		gov, _ := json.Marshal(governance.GovernanceConfig{Blocked: false})
+		gov, _ := json.Marshal(policy.GovernancePolicy{Blocked: false})
 		route := &RouteResult{
 			AgentID:     uuid.New(),
 			OwnerUserID: uuid.New(),
@@ -118,6 +118,32 @@ func TestValidator_Validate(t *testing.T) {
 	})
 }
 
+func TestValidator_ValidateSender(t *testing.T) {
+	v := NewValidator()
+	gov, _ := json.Marshal(policy.GovernancePolicy{AllowedSenderDomains: []string{"corp.example.com"}})
+	route := &RouteResult{
+		AgentID:     uuid.New(),
+		OwnerUserID: uuid.New(),
+		AgentJID:    "agent-123@agents.aiox.local",
+		Governance:  gov,
+	}
+
+	t.Run("allowed sender passes", func(t *testing.T) {
+		assert.NoError(t, v.ValidateSender(route, "alice@Corp.Example.com/phone"))
+	})
+
+	t.Run("foreign sender fails", func(t *testing.T) {
+		err := v.ValidateSender(route, "mallory@evil.example.org")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "evil.example.org")
+	})
+
+	t.Run("no policy accepts anyone", func(t *testing.T) {
+		open := &RouteResult{AgentID: uuid.New(), OwnerUserID: uuid.New(), Governance: []byte(`{}`)}
+		assert.NoError(t, v.ValidateSender(open, "anyone@anywhere.net"))
+	})
+}
+
 func TestValidator_ValidateOwnership(t *testing.T) {
 	v := NewValidator()
 
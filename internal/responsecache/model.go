@@ -0,0 +1,93 @@
+package responsecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is a cached worker response for one prompt under one agent fingerprint.
+type Entry struct {
+	ID          uuid.UUID
+	AgentID     uuid.UUID
+	Fingerprint string
+	PromptHash  string
+	Prompt      string
+	Response    string
+	ModelUsed   string
+	TokensUsed  int
+	Embedding   []float32
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Config is the per-agent cache setting, read from the "response_cache" key of
+// agents.capabilities JSONB.
+type Config struct {
+	Enabled             bool    `json:"enabled"`
+	TTLSec              int     `json:"ttl_sec"`
+	SimilarityThreshold float64 `json:"similarity_threshold"`
+}
+
+// DefaultConfig returns a disabled Config with sensible defaults.
+func DefaultConfig() Config {
+	return Config{
+		Enabled:             false,
+		TTLSec:              3600,
+		SimilarityThreshold: 0.95,
+	}
+}
+
+// TTL returns the configured entry lifetime.
+func (c Config) TTL() time.Duration {
+	return time.Duration(c.TTLSec) * time.Second
+}
+
+// ParseConfig extracts the response cache config from agent capabilities.
+// Returns defaults on nil, empty, or invalid input.
+func ParseConfig(capabilities []byte) Config {
+	cfg := DefaultConfig()
+	if len(capabilities) == 0 {
+		return cfg
+	}
+
+	var caps struct {
+		ResponseCache json.RawMessage `json:"response_cache"`
+	}
+	if err := json.Unmarshal(capabilities, &caps); err != nil || len(caps.ResponseCache) == 0 {
+		return cfg
+	}
+
+	_ = json.Unmarshal(caps.ResponseCache, &cfg)
+	if cfg.TTLSec <= 0 {
+		cfg.TTLSec = DefaultConfig().TTLSec
+	}
+	if cfg.SimilarityThreshold <= 0 || cfg.SimilarityThreshold > 1 {
+		cfg.SimilarityThreshold = DefaultConfig().SimilarityThreshold
+	}
+	return cfg
+}
+
+// Fingerprint identifies everything besides the user message that shapes a
+// response. Entries cached under a different fingerprint are never served,
+// so editing the system prompt or LLM config invalidates the agent's cache.
+func Fingerprint(systemPrompt string, llmConfig []byte, memoryContext string) string {
+	h := sha256.New()
+	for _, part := range []string{systemPrompt, string(llmConfig), memoryContext} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PromptHash hashes a prompt after trimming, lowercasing, and collapsing
+// whitespace so trivially different spellings share an entry.
+func PromptHash(prompt string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(prompt)), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,116 @@
+package responsecache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	pgvector "github.com/pgvector/pgvector-go"
+)
+
+// Repository defines response cache persistence operations.
+type Repository interface {
+	// FindExact returns the live entry whose prompt hash matches, or nil.
+	FindExact(ctx context.Context, agentID uuid.UUID, fingerprint, promptHash string) (*Entry, error)
+	// FindSimilar returns the closest live entry at or above threshold, or nil.
+	FindSimilar(ctx context.Context, agentID uuid.UUID, fingerprint string, embedding []float32, threshold float64) (*Entry, error)
+	// Put stores an entry and drops the agent's expired or stale-fingerprint entries.
+	Put(ctx context.Context, entry *Entry) error
+}
+
+// PostgresRepository implements Repository using pgx + pgvector.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new response cache repository.
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+const entryColumns = `id, agent_id, fingerprint, prompt_hash, prompt, response, model_used, tokens_used, created_at, expires_at`
+
+func (r *PostgresRepository) FindExact(ctx context.Context, agentID uuid.UUID, fingerprint, promptHash string) (*Entry, error) {
+	query := `SELECT ` + entryColumns + `
+		FROM agent_response_cache
+		WHERE agent_id = $1 AND fingerprint = $2 AND prompt_hash = $3 AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	entry, err := scanEntry(r.pool.QueryRow(ctx, query, agentID, fingerprint, promptHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying cached response: %w", err)
+	}
+	return entry, nil
+}
+
+func (r *PostgresRepository) FindSimilar(ctx context.Context, agentID uuid.UUID, fingerprint string, embedding []float32, threshold float64) (*Entry, error) {
+	query := `SELECT ` + entryColumns + `
+		FROM agent_response_cache
+		WHERE agent_id = $1 AND fingerprint = $2 AND expires_at > NOW()
+		  AND embedding IS NOT NULL
+		  AND 1 - (embedding <=> $3) >= $4
+		ORDER BY embedding <=> $3
+		LIMIT 1`
+
+	entry, err := scanEntry(r.pool.QueryRow(ctx, query, agentID, fingerprint, pgvector.NewVector(embedding), threshold))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("searching cached responses: %w", err)
+	}
+	return entry, nil
+}
+
+func (r *PostgresRepository) Put(ctx context.Context, entry *Entry) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM agent_response_cache
+		 WHERE agent_id = $1 AND (fingerprint <> $2 OR expires_at <= NOW() OR prompt_hash = $3)`,
+		entry.AgentID, entry.Fingerprint, entry.PromptHash)
+	if err != nil {
+		return fmt.Errorf("evicting cached responses: %w", err)
+	}
+
+	var vec *pgvector.Vector
+	if len(entry.Embedding) > 0 {
+		v := pgvector.NewVector(entry.Embedding)
+		vec = &v
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO agent_response_cache (id, agent_id, fingerprint, prompt_hash, prompt, response, model_used, tokens_used, embedding, created_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		entry.ID, entry.AgentID, entry.Fingerprint, entry.PromptHash, entry.Prompt, entry.Response,
+		entry.ModelUsed, entry.TokensUsed, vec, entry.CreatedAt, entry.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("inserting cached response: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing cached response: %w", err)
+	}
+	return nil
+}
+
+func scanEntry(row pgx.Row) (*Entry, error) {
+	e := &Entry{}
+	err := row.Scan(&e.ID, &e.AgentID, &e.Fingerprint, &e.PromptHash, &e.Prompt, &e.Response,
+		&e.ModelUsed, &e.TokensUsed, &e.CreatedAt, &e.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,103 @@
+package responsecache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmbeddingDim is the dimension of the cache's vector(384) column.
+const EmbeddingDim = 384
+
+// Embedder turns text into a vector compatible with the cache's vector(384) column.
+// Embeddings are otherwise produced by the Python workers, so an Embedder is
+// optional: without one, only normalized exact-prompt matches are served.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// EmbedderFunc adapts a plain function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, text string) ([]float32, error)
+
+// Embed calls f(ctx, text).
+func (f EmbedderFunc) Embed(ctx context.Context, text string) ([]float32, error) {
+	return f(ctx, text)
+}
+
+// Service looks up and stores cached worker responses.
+type Service struct {
+	repo     Repository
+	embedder Embedder
+}
+
+// NewService creates a response cache service. embedder may be nil.
+func NewService(repo Repository, embedder Embedder) *Service {
+	return &Service{repo: repo, embedder: embedder}
+}
+
+// Lookup is the state carried from a cache miss to the matching Store call.
+type Lookup struct {
+	AgentID     uuid.UUID
+	Fingerprint string
+	Prompt      string
+	PromptHash  string
+	Embedding   []float32
+	Config      Config
+}
+
+// Get returns a cached entry for prompt, or nil on a miss. The returned Lookup
+// should be passed to Store once the worker responds.
+func (s *Service) Get(ctx context.Context, agentID uuid.UUID, cfg Config, fingerprint, prompt string) (*Entry, *Lookup, error) {
+	lookup := &Lookup{
+		AgentID:     agentID,
+		Fingerprint: fingerprint,
+		Prompt:      prompt,
+		PromptHash:  PromptHash(prompt),
+		Config:      cfg,
+	}
+
+	entry, err := s.repo.FindExact(ctx, agentID, fingerprint, lookup.PromptHash)
+	if err != nil || entry != nil {
+		return entry, lookup, err
+	}
+
+	if s.embedder == nil {
+		return nil, lookup, nil
+	}
+
+	embedding, err := s.embedder.Embed(ctx, prompt)
+	if err != nil {
+		slog.Warn("response cache: embedding prompt", "error", err, "agent_id", agentID)
+		return nil, lookup, nil
+	}
+	if len(embedding) != EmbeddingDim {
+		// It couldn't be compared with, or stored next to, cached embeddings.
+		slog.Warn("response cache: embedding has the wrong dimension, matching exact prompts only",
+			"dimensions", len(embedding), "want", EmbeddingDim, "agent_id", agentID)
+		return nil, lookup, nil
+	}
+	lookup.Embedding = embedding
+
+	entry, err = s.repo.FindSimilar(ctx, agentID, fingerprint, embedding, cfg.SimilarityThreshold)
+	return entry, lookup, err
+}
+
+// Store caches a worker response for a previous miss.
+func (s *Service) Store(ctx context.Context, lookup *Lookup, response, modelUsed string, tokensUsed int) error {
+	now := time.Now().UTC()
+	return s.repo.Put(ctx, &Entry{
+		ID:          uuid.New(),
+		AgentID:     lookup.AgentID,
+		Fingerprint: lookup.Fingerprint,
+		PromptHash:  lookup.PromptHash,
+		Prompt:      lookup.Prompt,
+		Response:    response,
+		ModelUsed:   modelUsed,
+		TokensUsed:  tokensUsed,
+		Embedding:   lookup.Embedding,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lookup.Config.TTL()),
+	})
+}
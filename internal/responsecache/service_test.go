@@ -0,0 +1,130 @@
+package responsecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	entries []*Entry
+	similar *Entry
+}
+
+func (f *fakeRepo) FindExact(_ context.Context, agentID uuid.UUID, fingerprint, promptHash string) (*Entry, error) {
+	for _, e := range f.entries {
+		if e.AgentID == agentID && e.Fingerprint == fingerprint && e.PromptHash == promptHash {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeRepo) FindSimilar(_ context.Context, _ uuid.UUID, _ string, _ []float32, _ float64) (*Entry, error) {
+	return f.similar, nil
+}
+
+func (f *fakeRepo) Put(_ context.Context, entry *Entry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+type fakeEmbedder struct {
+	calls int
+	dim   int
+}
+
+func (f *fakeEmbedder) Embed(_ context.Context, _ string) ([]float32, error) {
+	f.calls++
+	dim := f.dim
+	if dim == 0 {
+		dim = EmbeddingDim
+	}
+	embedding := make([]float32, dim)
+	embedding[0] = 0.1
+	return embedding, nil
+}
+
+func TestParseConfig(t *testing.T) {
+	assert.Equal(t, DefaultConfig(), ParseConfig(nil))
+	assert.Equal(t, DefaultConfig(), ParseConfig([]byte(`{"tools":["search"]}`)))
+
+	cfg := ParseConfig([]byte(`{"response_cache":{"enabled":true,"ttl_sec":60}}`))
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, time.Minute, cfg.TTL())
+	assert.Equal(t, 0.95, cfg.SimilarityThreshold)
+
+	cfg = ParseConfig([]byte(`{"response_cache":{"enabled":true,"ttl_sec":-1,"similarity_threshold":2}}`))
+	assert.Equal(t, DefaultConfig().TTLSec, cfg.TTLSec)
+	assert.Equal(t, DefaultConfig().SimilarityThreshold, cfg.SimilarityThreshold)
+}
+
+func TestFingerprint_ChangesWithPromptAndConfig(t *testing.T) {
+	base := Fingerprint("be terse", []byte(`{"model":"a"}`), "")
+	assert.Equal(t, base, Fingerprint("be terse", []byte(`{"model":"a"}`), ""))
+	assert.NotEqual(t, base, Fingerprint("be verbose", []byte(`{"model":"a"}`), ""))
+	assert.NotEqual(t, base, Fingerprint("be terse", []byte(`{"model":"b"}`), ""))
+	assert.NotEqual(t, base, Fingerprint("be terse", []byte(`{"model":"a"}`), "ctx"))
+}
+
+func TestPromptHash_Normalizes(t *testing.T) {
+	assert.Equal(t, PromptHash("What is Go?"), PromptHash("  what   is go? "))
+	assert.NotEqual(t, PromptHash("What is Go?"), PromptHash("What is Rust?"))
+}
+
+func TestService_StoreThenHit(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo, nil)
+	agentID := uuid.New()
+	cfg := Config{Enabled: true, TTLSec: 60, SimilarityThreshold: 0.9}
+	fp := Fingerprint("sys", nil, "")
+
+	entry, lookup, err := svc.Get(context.Background(), agentID, cfg, fp, "Hello there")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	require.NoError(t, svc.Store(context.Background(), lookup, "Hi!", "gpt", 12))
+	require.Len(t, repo.entries, 1)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), repo.entries[0].ExpiresAt, 5*time.Second)
+
+	entry, _, err = svc.Get(context.Background(), agentID, cfg, fp, "hello   THERE")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, "Hi!", entry.Response)
+
+	entry, _, err = svc.Get(context.Background(), agentID, cfg, Fingerprint("changed", nil, ""), "Hello there")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestService_SimilarRequiresEmbedder(t *testing.T) {
+	similar := &Entry{Response: "close enough"}
+	repo := &fakeRepo{similar: similar}
+	cfg := DefaultConfig()
+
+	entry, _, err := NewService(repo, nil).Get(context.Background(), uuid.New(), cfg, "fp", "hi")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	emb := &fakeEmbedder{}
+	entry, lookup, err := NewService(repo, emb).Get(context.Background(), uuid.New(), cfg, "fp", "hi")
+	require.NoError(t, err)
+	assert.Same(t, similar, entry)
+	assert.Equal(t, 1, emb.calls)
+	assert.Len(t, lookup.Embedding, EmbeddingDim)
+}
+
+func TestService_SkipsEmbeddingOfWrongDimension(t *testing.T) {
+	repo := &fakeRepo{similar: &Entry{Response: "close enough"}}
+	emb := &fakeEmbedder{dim: 1536}
+
+	entry, lookup, err := NewService(repo, emb).Get(context.Background(), uuid.New(), DefaultConfig(), "fp", "hi")
+	require.NoError(t, err)
+	assert.Nil(t, entry, "no similarity search with a mismatched embedding")
+	assert.Equal(t, 1, emb.calls)
+	assert.Nil(t, lookup.Embedding, "the entry is stored without an embedding")
+}
@@ -0,0 +1,113 @@
+package rooms
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/api"
+)
+
+// Handler handles an agent's room bindings. Routes are mounted under
+// /agents/{agentID} behind the agent ownership middleware.
+type Handler struct {
+	svc      *Service
+	validate *validator.Validate
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{
+		svc:      svc,
+		validate: validator.New(),
+	}
+}
+
+// Create binds the agent to a room; the agent joins it right away.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	var req CreateBindingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	binding, err := h.svc.Create(r.Context(), agent, &req)
+	if err != nil {
+		h.handleError(w, "creating room binding", err)
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, binding)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	bindings, err := h.svc.List(r.Context(), agent.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing room bindings", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if bindings == nil {
+		bindings = []*Binding{}
+	}
+
+	api.JSON(w, http.StatusOK, bindings)
+}
+
+// Delete unbinds the agent from a room; the agent leaves it.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "bindingID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid room binding ID"))
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id, agent.ID); err != nil {
+		h.handleError(w, "deleting room binding", err)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "room binding deleted")
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, ErrBindingNotFound):
+		api.HandleError(w, api.NewNotFoundError(err.Error()))
+	case errors.Is(err, ErrRoomAlreadyBound):
+		api.HandleError(w, api.NewConflictError(err.Error()))
+	case errors.Is(err, ErrInvalidRoomJID):
+		api.HandleError(w, api.NewValidationError(err.Error()))
+	default:
+		slog.Error(op, "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+	}
+}
@@ -0,0 +1,55 @@
+package rooms
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBindingNotFound  = errors.New("room binding not found")
+	ErrRoomAlreadyBound = errors.New("room is already bound to an agent")
+	ErrInvalidRoomJID   = errors.New("room_jid must be a bare JID like room@conference.example.com")
+)
+
+// Binding places an agent in an XMPP multi-user chat room (XEP-0045). The
+// agent joins RoomJID as Nickname and answers messages that mention it.
+type Binding struct {
+	ID          uuid.UUID `json:"id"`
+	RoomJID     string    `json:"room_jid"`
+	AgentID     uuid.UUID `json:"agent_id"`
+	OwnerUserID uuid.UUID `json:"owner_user_id"`
+	Nickname    string    `json:"nickname"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// AgentJID is the occupant's real JID, joined from the agents table.
+	AgentJID string `json:"agent_jid"`
+}
+
+type CreateBindingRequest struct {
+	RoomJID string `json:"room_jid" validate:"required,max=1023"`
+	// Nickname defaults to the agent's name.
+	Nickname string `json:"nickname" validate:"omitempty,max=100"`
+}
+
+// NormalizeRoomJID returns the lowercase bare form of a room JID, or
+// ErrInvalidRoomJID if it is not of the form room@service.
+func NormalizeRoomJID(jid string) (string, error) {
+	jid = strings.ToLower(strings.TrimSpace(jid))
+	local, domain, ok := strings.Cut(jid, "@")
+	if !ok || local == "" || domain == "" || strings.ContainsAny(jid, "/ ") || strings.Contains(domain, "@") {
+		return "", ErrInvalidRoomJID
+	}
+	return jid, nil
+}
+
+// Mentions reports whether body addresses nickname, e.g. "helper: hi" or
+// "what do you think, @Helper?". Matching is case-insensitive.
+func Mentions(body, nickname string) bool {
+	if nickname == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(body), strings.ToLower(nickname))
+}
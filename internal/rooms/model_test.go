@@ -0,0 +1,26 @@
+package rooms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeRoomJID(t *testing.T) {
+	got, err := NormalizeRoomJID("  Lobby@Conference.AIOX.local ")
+	require.NoError(t, err)
+	assert.Equal(t, "lobby@conference.aiox.local", got)
+
+	for _, jid := range []string{"", "lobby", "@conference.aiox.local", "lobby@", "lobby@conference.aiox.local/nick", "a@b@c"} {
+		_, err := NormalizeRoomJID(jid)
+		assert.ErrorIs(t, err, ErrInvalidRoomJID, jid)
+	}
+}
+
+func TestMentions(t *testing.T) {
+	assert.True(t, Mentions("helper: what's the weather?", "Helper"))
+	assert.True(t, Mentions("what do you think, @HELPER?", "helper"))
+	assert.False(t, Mentions("good morning everyone", "helper"))
+	assert.False(t, Mentions("helper: hi", ""))
+}
@@ -0,0 +1,130 @@
+package rooms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// Create stores a binding, returning ErrRoomAlreadyBound if the room is
+	// bound to any agent.
+	Create(ctx context.Context, b *Binding) error
+	ListByAgent(ctx context.Context, agentID uuid.UUID) ([]*Binding, error)
+	// ListAll returns the bindings of every agent that is not deleted.
+	ListAll(ctx context.Context) ([]*Binding, error)
+	// GetByRoom returns the binding for a room, or nil.
+	GetByRoom(ctx context.Context, roomJID string) (*Binding, error)
+	// Delete removes the agent's binding and returns it, or
+	// ErrBindingNotFound.
+	Delete(ctx context.Context, id, agentID uuid.UUID) (*Binding, error)
+}
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+const bindingColumns = `b.id, b.room_jid, b.agent_id, b.owner_user_id, b.nickname, b.created_at, a.jid`
+
+const bindingFrom = `
+		FROM room_agent_bindings b
+		JOIN agents a ON a.id = b.agent_id AND a.deleted_at IS NULL`
+
+func (r *postgresRepository) Create(ctx context.Context, b *Binding) error {
+	query := `
+		INSERT INTO room_agent_bindings (id, room_jid, agent_id, owner_user_id, nickname, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.pool.Exec(ctx, query, b.ID, b.RoomJID, b.AgentID, b.OwnerUserID, b.Nickname, b.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrRoomAlreadyBound
+		}
+		return fmt.Errorf("inserting room binding: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListByAgent(ctx context.Context, agentID uuid.UUID) ([]*Binding, error) {
+	query := `SELECT ` + bindingColumns + bindingFrom + `
+		WHERE b.agent_id = $1
+		ORDER BY b.created_at DESC`
+
+	return r.query(ctx, "listing room bindings", query, agentID)
+}
+
+func (r *postgresRepository) ListAll(ctx context.Context) ([]*Binding, error) {
+	query := `SELECT ` + bindingColumns + bindingFrom + `
+		ORDER BY b.created_at`
+
+	return r.query(ctx, "listing all room bindings", query)
+}
+
+func (r *postgresRepository) GetByRoom(ctx context.Context, roomJID string) (*Binding, error) {
+	query := `SELECT ` + bindingColumns + bindingFrom + `
+		WHERE b.room_jid = $1`
+
+	b, err := scanBinding(r.pool.QueryRow(ctx, query, strings.ToLower(roomJID)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying room binding: %w", err)
+	}
+	return b, nil
+}
+
+func (r *postgresRepository) Delete(ctx context.Context, id, agentID uuid.UUID) (*Binding, error) {
+	query := `
+		DELETE FROM room_agent_bindings
+		WHERE id = $1 AND agent_id = $2
+		RETURNING id, room_jid, agent_id, owner_user_id, nickname, created_at,
+		          (SELECT jid FROM agents WHERE agents.id = agent_id)`
+
+	b, err := scanBinding(r.pool.QueryRow(ctx, query, id, agentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrBindingNotFound
+		}
+		return nil, fmt.Errorf("deleting room binding: %w", err)
+	}
+	return b, nil
+}
+
+func (r *postgresRepository) query(ctx context.Context, op, query string, args ...any) ([]*Binding, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var bindings []*Binding
+	for rows.Next() {
+		b, err := scanBinding(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning room binding: %w", err)
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings, rows.Err()
+}
+
+func scanBinding(row pgx.Row) (*Binding, error) {
+	b := &Binding{}
+	err := row.Scan(&b.ID, &b.RoomJID, &b.AgentID, &b.OwnerUserID, &b.Nickname, &b.CreatedAt, &b.AgentJID)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
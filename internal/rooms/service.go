@@ -0,0 +1,109 @@
+package rooms
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+)
+
+// Joiner makes agents enter and leave rooms; satisfied by *xmpp.RoomJoiner.
+type Joiner interface {
+	JoinRoom(roomJID, nickname, agentJID string) error
+	LeaveRoom(roomJID, nickname, agentJID string) error
+}
+
+// Service manages room bindings and keeps the agents' room presence in sync
+// with them.
+type Service struct {
+	repo   Repository
+	joiner Joiner
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SetJoiner enables joining and leaving rooms as bindings change. It must be
+// called before the service is used.
+func (s *Service) SetJoiner(j Joiner) {
+	s.joiner = j
+}
+
+// Create binds the agent to a room and joins it. The nickname defaults to the
+// agent's name.
+func (s *Service) Create(ctx context.Context, agent *agents.Agent, req *CreateBindingRequest) (*Binding, error) {
+	roomJID, err := NormalizeRoomJID(req.RoomJID)
+	if err != nil {
+		return nil, err
+	}
+	nickname := req.Nickname
+	if nickname == "" {
+		nickname = agent.Profile.Name
+	}
+
+	b := &Binding{
+		ID:          uuid.New(),
+		RoomJID:     roomJID,
+		AgentID:     agent.ID,
+		OwnerUserID: agent.OwnerUserID,
+		Nickname:    nickname,
+		CreatedAt:   time.Now().UTC(),
+		AgentJID:    agent.JID,
+	}
+	if err := s.repo.Create(ctx, b); err != nil {
+		return nil, err
+	}
+
+	s.join(b)
+	return b, nil
+}
+
+func (s *Service) List(ctx context.Context, agentID uuid.UUID) ([]*Binding, error) {
+	return s.repo.ListByAgent(ctx, agentID)
+}
+
+// GetByRoom returns the binding for a room, or nil if none exists.
+func (s *Service) GetByRoom(ctx context.Context, roomJID string) (*Binding, error) {
+	return s.repo.GetByRoom(ctx, roomJID)
+}
+
+// Delete unbinds the agent from a room and leaves it.
+func (s *Service) Delete(ctx context.Context, id, agentID uuid.UUID) error {
+	b, err := s.repo.Delete(ctx, id, agentID)
+	if err != nil {
+		return err
+	}
+	if s.joiner != nil {
+		if err := s.joiner.LeaveRoom(b.RoomJID, b.Nickname, b.AgentJID); err != nil {
+			slog.Warn("leaving room", "error", err, "room", b.RoomJID, "agent_id", b.AgentID)
+		}
+	}
+	return nil
+}
+
+// JoinAll joins every bound room. Presence does not survive a reconnect, so
+// it runs each time the XMPP component connects.
+func (s *Service) JoinAll(ctx context.Context) {
+	bindings, err := s.repo.ListAll(ctx)
+	if err != nil {
+		slog.Error("listing room bindings", "error", err)
+		return
+	}
+	for _, b := range bindings {
+		s.join(b)
+	}
+	slog.Info("joined bound rooms", "count", len(bindings))
+}
+
+func (s *Service) join(b *Binding) {
+	if s.joiner == nil {
+		return
+	}
+	if err := s.joiner.JoinRoom(b.RoomJID, b.Nickname, b.AgentJID); err != nil {
+		slog.Warn("joining room", "error", err, "room", b.RoomJID, "agent_id", b.AgentID)
+	}
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,25 +16,71 @@ import (
 )
 
 type Server struct {
-	httpServer *http.Server
+	httpServer      *http.Server
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+	onShutdown      []func()
 }
 
+// shutdownKey carries a context that is canceled when the server that
+// accepted the request starts shutting down.
+type shutdownKey struct{}
+
 func New(cfg config.ServerConfig, handler http.Handler) *Server {
-	return &Server{
-		httpServer: &http.Server{
-			Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-			Handler:      handler,
-			ReadTimeout:  15 * time.Second,
-			WriteTimeout: 15 * time.Second,
-			IdleTimeout:  60 * time.Second,
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	stopping, stop := context.WithCancel(context.Background())
+	httpServer := &http.Server{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler:      handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), shutdownKey{}, stopping)
 		},
 	}
+	// Shutdown waits for active requests, and streaming responses never end
+	// on their own; StreamContext ends them when shutdown begins.
+	httpServer.RegisterOnShutdown(stop)
+	return &Server{
+		httpServer:      httpServer,
+		shutdownDelay:   cfg.ShutdownDelay,
+		shutdownTimeout: timeout,
+	}
+}
+
+// StreamContext returns a copy of the request context that is also canceled
+// when the server begins shutting down. Handlers that stream until the
+// client disconnects, such as Server-Sent Events, should use it so they
+// don't hold up shutdown; ordinary requests keep r.Context() and are allowed
+// to finish.
+func StreamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stopping, ok := ctx.Value(shutdownKey{}).(context.Context)
+	if !ok {
+		return ctx, cancel
+	}
+	unregister := context.AfterFunc(stopping, cancel)
+	return ctx, func() {
+		unregister()
+		cancel()
+	}
+}
+
+// OnShutdown registers fn to run as soon as a shutdown signal arrives, before
+// the listener closes. Use it to fail readiness checks while draining.
+func (s *Server) OnShutdown(fn func()) {
+	s.onShutdown = append(s.onShutdown, fn)
 }
 
 func (s *Server) Start() error {
 	// Channel for shutdown signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
 
 	// Channel for server errors
 	errCh := make(chan error, 1)
@@ -52,11 +100,31 @@ func (s *Server) Start() error {
 		slog.Info("shutting down server", "signal", sig)
 	}
 
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	return s.Shutdown()
+}
+
+// Shutdown marks the server as draining, keeps accepting requests for the
+// configured delay so load balancers can observe the failing readiness probe,
+// then waits for in-flight requests to finish. Requests still running after
+// the shutdown timeout are cut off.
+func (s *Server) Shutdown() error {
+	for _, fn := range s.onShutdown {
+		fn()
+	}
+
+	if s.shutdownDelay > 0 {
+		slog.Info("draining before closing listener", "delay", s.shutdownDelay)
+		time.Sleep(s.shutdownDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
 	defer cancel()
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			slog.Warn("in-flight requests did not finish in time, closing connections", "timeout", s.shutdownTimeout)
+			_ = s.httpServer.Close()
+		}
 		return fmt.Errorf("server shutdown: %w", err)
 	}
 
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/config"
+)
+
+func TestShutdown_EndsStreams(t *testing.T) {
+	ended := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := StreamContext(r.Context())
+		defer cancel()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ready\n"))
+		http.NewResponseController(w).Flush()
+		<-ctx.Done()
+		ended <- r.Context().Err()
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			w.WriteHeader(http.StatusNoContent)
+		case <-r.Context().Done():
+		}
+	})
+
+	srv := New(config.ServerConfig{ShutdownTimeout: 5 * time.Second}, mux)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.httpServer.Serve(ln)
+	base := "http://" + ln.Addr().String()
+
+	resp, err := http.Get(base + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "ready\n", line)
+
+	slow := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow")
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, srv.Shutdown())
+	assert.Less(t, time.Since(start), 2*time.Second, "an open stream must not hold up shutdown")
+
+	select {
+	case err := <-ended:
+		assert.NoError(t, err, "only the stream context is canceled, not the request's")
+	default:
+		t.Fatal("stream handler still running after shutdown")
+	}
+	assert.Equal(t, http.StatusNoContent, <-slow, "ordinary requests still drain")
+}
+
+func TestStreamContext_WithoutServer(t *testing.T) {
+	ctx, cancel := StreamContext(context.Background())
+	assert.NoError(t, ctx.Err())
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
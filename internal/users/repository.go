@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,8 +16,16 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	EnableTOTP(ctx context.Context, id uuid.UUID, encryptedSecret string, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, id uuid.UUID) error
+	UseRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error)
+	PromoteAdmins(ctx context.Context, emails []string) (int64, error)
 }
 
+const userColumns = `id, email, password_hash, is_admin, created_at, updated_at,
+	COALESCE(totp_secret, ''), totp_enabled_at, totp_recovery_codes`
+
 type postgresRepository struct {
 	pool *pgxpool.Pool
 }
@@ -25,13 +34,17 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 	return &postgresRepository{pool: pool}
 }
 
+// Create inserts user. The first user ever registered is made an admin
+// regardless of user.IsAdmin; user.IsAdmin is updated to the stored value.
 func (r *postgresRepository) Create(ctx context.Context, user *User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)`
+		INSERT INTO users (id, email, password_hash, is_admin, created_at, updated_at)
+		VALUES ($1, $2, $3, $4 OR NOT EXISTS (SELECT 1 FROM users), $5, $6)
+		RETURNING is_admin`
 
-	_, err := r.pool.Exec(ctx, query,
-		user.ID, user.Email, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	err := r.pool.QueryRow(ctx, query,
+		user.ID, user.Email, user.PasswordHash, user.IsAdmin, user.CreatedAt, user.UpdatedAt,
+	).Scan(&user.IsAdmin)
 	if err != nil {
 		return fmt.Errorf("inserting user: %w", err)
 	}
@@ -39,11 +52,9 @@ func (r *postgresRepository) Create(ctx context.Context, user *User) error {
 }
 
 func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
-	query := `SELECT id, email, password_hash, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
-	user := &User{}
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -54,11 +65,9 @@ func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*User,
 }
 
 func (r *postgresRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
-	query := `SELECT id, email, password_hash, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
 
-	user := &User{}
-	err := r.pool.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt)
+	user, err := scanUser(r.pool.QueryRow(ctx, query, email))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -78,3 +87,84 @@ func (r *postgresRepository) ExistsByEmail(ctx context.Context, email string) (b
 	}
 	return exists, nil
 }
+
+func (r *postgresRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.pool.Exec(ctx, query, id, passwordHash)
+	if err != nil {
+		return fmt.Errorf("updating user password: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+func (r *postgresRepository) EnableTOTP(ctx context.Context, id uuid.UUID, encryptedSecret string, recoveryCodeHashes []string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $2, totp_enabled_at = NOW(), totp_recovery_codes = $3, updated_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, encryptedSecret, recoveryCodeHashes); err != nil {
+		return fmt.Errorf("enabling totp: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) DisableTOTP(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_recovery_codes = '{}', updated_at = NOW()
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("disabling totp: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode atomically removes codeHash from the user's recovery codes,
+// reporting whether it was present.
+func (r *postgresRepository) UseRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE users
+		SET totp_recovery_codes = array_remove(totp_recovery_codes, $2), updated_at = NOW()
+		WHERE id = $1 AND $2 = ANY(totp_recovery_codes)`
+
+	result, err := r.pool.Exec(ctx, query, id, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("using recovery code: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// PromoteAdmins makes the users with the given emails (case-insensitive)
+// admins, returning how many were promoted.
+func (r *postgresRepository) PromoteAdmins(ctx context.Context, emails []string) (int64, error) {
+	query := `
+		UPDATE users SET is_admin = TRUE, updated_at = NOW()
+		WHERE LOWER(email) = ANY($1) AND NOT is_admin`
+
+	lowered := make([]string, len(emails))
+	for i, e := range emails {
+		lowered[i] = strings.ToLower(e)
+	}
+	result, err := r.pool.Exec(ctx, query, lowered)
+	if err != nil {
+		return 0, fmt.Errorf("promoting admins: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanUser(row pgx.Row) (*User, error) {
+	user := &User{}
+	err := row.Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt,
+		&user.TOTPSecret, &user.TOTPEnabledAt, &user.RecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -2,25 +2,35 @@ package users
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	repo Repository
+	repo        Repository
+	adminEmails []string
 }
 
 func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
+// SetAdminEmails sets the emails that are made admins when they register.
+func (s *Service) SetAdminEmails(emails []string) {
+	s.adminEmails = emails
+}
+
+// Create registers a user. The first user, and users whose email is in the
+// admin list, become admins.
 func (s *Service) Create(ctx context.Context, email, passwordHash string) (*User, error) {
 	now := time.Now()
 	user := &User{
 		ID:           uuid.New(),
 		Email:        email,
 		PasswordHash: passwordHash,
+		IsAdmin:      s.isAdminEmail(email),
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
@@ -41,3 +51,37 @@ func (s *Service) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 func (s *Service) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	return s.repo.ExistsByEmail(ctx, email)
 }
+
+func (s *Service) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return s.repo.UpdatePassword(ctx, id, passwordHash)
+}
+
+func (s *Service) EnableTOTP(ctx context.Context, id uuid.UUID, encryptedSecret string, recoveryCodeHashes []string) error {
+	return s.repo.EnableTOTP(ctx, id, encryptedSecret, recoveryCodeHashes)
+}
+
+func (s *Service) DisableTOTP(ctx context.Context, id uuid.UUID) error {
+	return s.repo.DisableTOTP(ctx, id)
+}
+
+func (s *Service) UseRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error) {
+	return s.repo.UseRecoveryCode(ctx, id, codeHash)
+}
+
+// PromoteAdmins makes the already registered users in the admin list admins.
+// It is run at startup so the list also covers existing accounts.
+func (s *Service) PromoteAdmins(ctx context.Context) (int64, error) {
+	if len(s.adminEmails) == 0 {
+		return 0, nil
+	}
+	return s.repo.PromoteAdmins(ctx, s.adminEmails)
+}
+
+func (s *Service) isAdminEmail(email string) bool {
+	for _, e := range s.adminEmails {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
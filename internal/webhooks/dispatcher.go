@@ -0,0 +1,230 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+const (
+	// DefaultBackoff is the wait before the first retry; it doubles for each
+	// further attempt.
+	DefaultBackoff = time.Second
+
+	// maxConcurrentDeliveries bounds in-flight deliveries across all webhooks.
+	maxConcurrentDeliveries = 16
+)
+
+// AuditPublisher publishes audit events. *inats.Publisher satisfies it.
+type AuditPublisher interface {
+	PublishAuditEvent(ctx context.Context, event inats.AuditEvent) error
+}
+
+// Dispatcher consumes platform events from the AIOX_EVENTS stream and delivers
+// them to subscribed webhooks, retrying with exponential backoff.
+type Dispatcher struct {
+	svc          *Service
+	consumerMgr  *inats.ConsumerManager
+	publisher    AuditPublisher
+	maxAttempts  int
+	disableAfter int
+	backoff      time.Duration
+
+	sem      chan struct{}
+	inflight sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher. An event is sent up to maxAttempts
+// times; a webhook is disabled after disableAfter consecutive events fail.
+func NewDispatcher(svc *Service, consumerMgr *inats.ConsumerManager, maxAttempts, disableAfter int) *Dispatcher {
+	return &Dispatcher{
+		svc:          svc,
+		consumerMgr:  consumerMgr,
+		maxAttempts:  maxAttempts,
+		disableAfter: disableAfter,
+		backoff:      DefaultBackoff,
+		sem:          make(chan struct{}, maxConcurrentDeliveries),
+	}
+}
+
+// SetAuditPublisher enables the webhook_disabled audit event.
+func (d *Dispatcher) SetAuditPublisher(p AuditPublisher) {
+	d.publisher = p
+}
+
+// Start begins the consume loop. Blocks until ctx is cancelled and in-flight
+// deliveries have stopped.
+func (d *Dispatcher) Start(ctx context.Context) error {
+	consumer, err := d.consumerMgr.EnsureConsumer(ctx, inats.StreamEvents, "webhook-dispatcher", inats.SubjectEventsAll)
+	if err != nil {
+		return err
+	}
+	defer d.inflight.Wait()
+
+	slog.Info("webhook dispatcher started", "consumer", "webhook-dispatcher")
+
+	for {
+		msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(inats.FetchTimeout))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Debug("webhook dispatcher: fetching events", "error", err)
+			continue
+		}
+
+		for msg := range msgs.Messages() {
+			d.handleMessage(ctx, msg)
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) handleMessage(ctx context.Context, msg jetstream.Msg) {
+	ownerID, event, err := decodeEvent(msg.Subject(), msg.Headers(), msg.Data())
+	if err != nil {
+		slog.Error("webhook dispatcher: decoding event", "error", err, "subject", msg.Subject())
+		_ = msg.Ack()
+		return
+	}
+	if event == nil {
+		_ = msg.Ack()
+		return
+	}
+
+	hooks, err := d.svc.repo.ListSubscribers(ctx, ownerID, event.Type)
+	if err != nil {
+		slog.Error("webhook dispatcher: listing subscribers", "error", err, "event_type", event.Type)
+		_ = d.consumerMgr.Nak(msg)
+		return
+	}
+
+	// Deliveries run in the background so one slow endpoint cannot hold up
+	// the stream; the message is acked once they are scheduled.
+	for _, hook := range hooks {
+		d.inflight.Add(1)
+		go func(hook *Webhook) {
+			defer d.inflight.Done()
+			d.deliver(ctx, hook, event)
+		}(hook)
+	}
+	_ = msg.Ack()
+}
+
+// deliver sends event with retries and records the outcome on the webhook.
+func (d *Dispatcher) deliver(ctx context.Context, hook *Webhook, event *Event) {
+	select {
+	case d.sem <- struct{}{}:
+		defer func() { <-d.sem }()
+	case <-ctx.Done():
+		return
+	}
+
+	var result DeliveryResult
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		if attempt > 1 {
+			wait := d.backoff << (attempt - 2)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		result = d.svc.Deliver(ctx, hook, event)
+		if result.Delivered {
+			if err := d.svc.repo.RecordSuccess(ctx, hook.ID); err != nil {
+				slog.Warn("webhook dispatcher: recording delivery", "error", err, "webhook_id", hook.ID)
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Debug("webhook delivery failed",
+			"webhook_id", hook.ID,
+			"event_type", event.Type,
+			"attempt", attempt,
+			"error", result.Error,
+		)
+	}
+
+	failures, disabled, err := d.svc.repo.RecordFailure(ctx, hook.ID, result.Error, d.disableAfter)
+	if err != nil {
+		slog.Warn("webhook dispatcher: recording failure", "error", err, "webhook_id", hook.ID)
+		return
+	}
+	slog.Warn("webhook event dead-lettered",
+		"webhook_id", hook.ID,
+		"event_type", event.Type,
+		"attempts", d.maxAttempts,
+		"consecutive_failures", failures,
+		"error", result.Error,
+	)
+	if disabled {
+		d.emitDisabled(ctx, hook, failures, result.Error)
+	}
+}
+
+func (d *Dispatcher) emitDisabled(ctx context.Context, hook *Webhook, failures int, lastErr string) {
+	slog.Warn("webhook disabled after repeated failures", "webhook_id", hook.ID, "consecutive_failures", failures)
+	if d.publisher == nil {
+		return
+	}
+	event := inats.AuditEvent{
+		OwnerUserID:  hook.OwnerUserID,
+		EventType:    "webhook_disabled",
+		Severity:     "warn",
+		ResourceType: "webhook",
+		ResourceID:   hook.ID.String(),
+		Details:      fmt.Sprintf("disabled after %d consecutive failed deliveries; last error: %s", failures, lastErr),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := d.publisher.PublishAuditEvent(ctx, event); err != nil {
+		slog.Warn("publishing webhook_disabled audit event", "error", err, "webhook_id", hook.ID)
+	}
+}
+
+// decodeEvent turns a stream message into the owner it belongs to and the
+// webhook payload. Subjects without a webhook mapping yield a nil event.
+func decodeEvent(subject string, header nats.Header, payload []byte) (uuid.UUID, *Event, error) {
+	var (
+		ownerID   uuid.UUID
+		eventType string
+		at        time.Time
+		data      any
+	)
+	switch {
+	case strings.HasPrefix(subject, inats.SubjectAuditEvent+"."):
+		var e inats.AuditEvent
+		if err := inats.Decode(header, payload, &e); err != nil {
+			return uuid.Nil, nil, err
+		}
+		ownerID, eventType, at, data = e.OwnerUserID, e.EventType, e.Timestamp, e
+	case subject == inats.SubjectAgentEvent:
+		var e inats.AgentEvent
+		if err := inats.Decode(header, payload, &e); err != nil {
+			return uuid.Nil, nil, err
+		}
+		ownerID, eventType, at, data = e.OwnerUserID, e.EventType, e.Timestamp, e
+	}
+	if ownerID == uuid.Nil || eventType == "" {
+		return uuid.Nil, nil, nil
+	}
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	return ownerID, &Event{ID: uuid.New(), Type: eventType, CreatedAt: at, Data: data}, nil
+}
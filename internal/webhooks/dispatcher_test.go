@@ -0,0 +1,197 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/auth"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+const testEncryptionKey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// outcomeRepo records delivery outcomes and applies the disable rule in memory.
+type outcomeRepo struct {
+	Repository
+	mu        sync.Mutex
+	successes int
+	failures  int
+	lastError string
+	enabled   bool
+}
+
+func (r *outcomeRepo) RecordSuccess(context.Context, uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.successes++
+	r.failures = 0
+	return nil
+}
+
+func (r *outcomeRepo) RecordFailure(_ context.Context, _ uuid.UUID, lastError string, disableAfter int) (int, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	r.lastError = lastError
+	wasEnabled := r.enabled
+	r.enabled = r.enabled && r.failures < disableAfter
+	return r.failures, wasEnabled && !r.enabled, nil
+}
+
+type capturePublisher struct {
+	events []inats.AuditEvent
+}
+
+func (p *capturePublisher) PublishAuditEvent(_ context.Context, e inats.AuditEvent) error {
+	p.events = append(p.events, e)
+	return nil
+}
+
+func newTestService(t *testing.T, repo Repository) (*Service, *auth.Encryptor) {
+	t.Helper()
+	enc, err := auth.NewEncryptor(testEncryptionKey)
+	require.NoError(t, err)
+	svc := NewService(repo, enc, 2*time.Second)
+	// Test endpoints listen on loopback.
+	svc.AllowPrivateNetworks()
+	return svc, enc
+}
+
+func newTestHook(t *testing.T, enc *auth.Encryptor, url, secret string) *Webhook {
+	t.Helper()
+	encrypted, err := enc.Encrypt(secret)
+	require.NoError(t, err)
+	return &Webhook{ID: uuid.New(), OwnerUserID: uuid.New(), URL: url, Secret: encrypted, Enabled: true}
+}
+
+func TestSign(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	sig := Sign("whsec_test", ts, []byte(`{"a":1}`))
+	assert.Equal(t, sig, Sign("whsec_test", ts, []byte(`{"a":1}`)))
+	assert.NotEqual(t, sig, Sign("whsec_other", ts, []byte(`{"a":1}`)))
+	assert.NotEqual(t, sig, Sign("whsec_test", ts.Add(time.Second), []byte(`{"a":1}`)))
+	assert.Len(t, sig, len("sha256=")+64)
+}
+
+func TestDecodeEvent(t *testing.T) {
+	owner := uuid.New()
+	data, err := json.Marshal(inats.AuditEvent{OwnerUserID: owner, EventType: "task_completed", ResourceID: "exec-1"})
+	require.NoError(t, err)
+
+	gotOwner, event, err := decodeEvent(inats.AuditSubject(owner), nats.Header{}, data)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, owner, gotOwner)
+	assert.Equal(t, "task_completed", event.Type)
+	assert.False(t, event.CreatedAt.IsZero())
+
+	_, event, err = decodeEvent("aiox.events.other", nats.Header{}, data)
+	require.NoError(t, err)
+	assert.Nil(t, event, "unknown subjects are skipped")
+
+	_, _, err = decodeEvent(inats.AuditSubject(owner), nats.Header{}, []byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestDeliver_SignsRequest(t *testing.T) {
+	var gotSig, gotTS, gotEvent string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get(HeaderSignature)
+		gotTS = r.Header.Get(HeaderTimestamp)
+		gotEvent = r.Header.Get(HeaderEvent)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	svc, enc := newTestService(t, nil)
+	hook := newTestHook(t, enc, srv.URL, "whsec_abc")
+
+	result := svc.Deliver(context.Background(), hook, &Event{ID: uuid.New(), Type: "task_completed", Data: map[string]int{"n": 1}})
+	require.True(t, result.Delivered, result.Error)
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+	assert.Equal(t, "task_completed", gotEvent)
+
+	ts, err := strconv.ParseInt(gotTS, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("whsec_abc", time.Unix(ts, 0), gotBody), gotSig)
+}
+
+func TestDeliver_RedirectIsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	svc, enc := newTestService(t, nil)
+	result := svc.Deliver(context.Background(), newTestHook(t, enc, srv.URL, "s"), &Event{Type: "x"})
+	assert.False(t, result.Delivered)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+}
+
+func TestDispatcher_RetriesThenSucceeds(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repo := &outcomeRepo{enabled: true}
+	svc, enc := newTestService(t, repo)
+	d := NewDispatcher(svc, nil, 5, 3)
+	d.backoff = time.Millisecond
+
+	d.deliver(context.Background(), newTestHook(t, enc, srv.URL, "s"), &Event{Type: "task_completed"})
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 1, repo.successes)
+	assert.Equal(t, 0, repo.failures)
+}
+
+func TestDispatcher_DisablesAfterConsecutiveFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	repo := &outcomeRepo{enabled: true}
+	svc, enc := newTestService(t, repo)
+	pub := &capturePublisher{}
+	d := NewDispatcher(svc, nil, 2, 2)
+	d.backoff = time.Millisecond
+	d.SetAuditPublisher(pub)
+	hook := newTestHook(t, enc, srv.URL, "s")
+
+	d.deliver(context.Background(), hook, &Event{Type: "task_completed"})
+	assert.Equal(t, int32(2), calls.Load(), "each event is attempted maxAttempts times")
+	assert.Equal(t, 1, repo.failures)
+	assert.Empty(t, pub.events)
+
+	d.deliver(context.Background(), hook, &Event{Type: "task_completed"})
+	assert.Equal(t, 2, repo.failures)
+	assert.False(t, repo.enabled)
+	assert.Contains(t, repo.lastError, "500")
+	require.Len(t, pub.events, 1)
+	assert.Equal(t, "webhook_disabled", pub.events[0].EventType)
+	assert.Equal(t, hook.ID.String(), pub.events[0].ResourceID)
+	assert.Equal(t, hook.OwnerUserID, pub.events[0].OwnerUserID)
+}
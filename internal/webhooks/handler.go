@@ -0,0 +1,186 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+)
+
+// Handler handles webhook management endpoints.
+type Handler struct {
+	svc      *Service
+	validate *validator.Validate
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{
+		svc:      svc,
+		validate: validator.New(),
+	}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	created, err := h.svc.Create(r.Context(), ownerID, &req)
+	if err != nil {
+		h.handleError(w, "creating webhook", err)
+		return
+	}
+
+	api.JSON(w, http.StatusCreated, created)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := ownerFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	hooks, err := h.svc.List(r.Context(), ownerID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing webhooks", "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if hooks == nil {
+		hooks = []*Webhook{}
+	}
+
+	api.JSON(w, http.StatusOK, hooks)
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	ownerID, id, ok := webhookFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	hook, err := h.svc.Get(r.Context(), id, ownerID)
+	if err != nil {
+		h.handleError(w, "fetching webhook", err)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, hook)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	ownerID, id, ok := webhookFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		api.HandleError(w, api.NewValidationError(err.Error()))
+		return
+	}
+
+	hook, err := h.svc.Update(r.Context(), id, ownerID, &req)
+	if err != nil {
+		h.handleError(w, "updating webhook", err)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, hook)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	ownerID, id, ok := webhookFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id, ownerID); err != nil {
+		h.handleError(w, "deleting webhook", err)
+		return
+	}
+
+	api.JSONMessage(w, http.StatusOK, "webhook deleted")
+}
+
+// Test sends a ping event to the webhook and reports the outcome. A failed
+// ping is still a 200; the result says why it failed.
+func (h *Handler) Test(w http.ResponseWriter, r *http.Request) {
+	ownerID, id, ok := webhookFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.svc.Test(r.Context(), id, ownerID)
+	if err != nil {
+		h.handleError(w, "testing webhook", err)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) handleError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, ErrWebhookNotFound):
+		api.HandleError(w, api.NewNotFoundError(err.Error()))
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrPrivateURL):
+		api.HandleError(w, api.NewValidationError(err.Error()))
+	default:
+		slog.Error(op, "error", err)
+		api.HandleError(w, api.ErrInternalServer)
+	}
+}
+
+func webhookFromRequest(w http.ResponseWriter, r *http.Request) (ownerID, id uuid.UUID, ok bool) {
+	ownerID, ok = ownerFromRequest(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid webhook ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return ownerID, id, true
+}
+
+func ownerFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+
+	ownerID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return uuid.Nil, false
+	}
+	return ownerID, true
+}
@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// SecretPrefix marks generated signing secrets.
+	SecretPrefix = "whsec_"
+
+	// AllEvents subscribes a webhook to every event type.
+	AllEvents = "*"
+
+	// PingEvent is the event type sent by POST /webhooks/{id}/test.
+	PingEvent = "ping"
+)
+
+// Delivery request headers.
+const (
+	HeaderEvent     = "X-AIOX-Event"
+	HeaderDelivery  = "X-AIOX-Delivery"
+	HeaderTimestamp = "X-AIOX-Timestamp"
+	HeaderSignature = "X-AIOX-Signature"
+)
+
+var (
+	ErrWebhookNotFound = errors.New("webhook not found")
+	ErrInvalidURL      = errors.New("webhook url must be an absolute http or https URL")
+	ErrPrivateURL      = errors.New("webhook url must not point to a loopback, private, or link-local address")
+)
+
+// Webhook is a user's subscription to platform events. The signing secret is
+// stored encrypted and returned only once, at creation.
+type Webhook struct {
+	ID                  uuid.UUID  `json:"id"`
+	OwnerUserID         uuid.UUID  `json:"owner_user_id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Secret              string     `json:"-"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DeadLetterCount     int        `json:"dead_letter_count"`
+	LastDeliveryAt      *time.Time `json:"last_delivery_at,omitempty"`
+	LastError           *string    `json:"last_error,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// CreatedWebhook is the creation response; Secret is never returned again.
+type CreatedWebhook struct {
+	*Webhook
+	Secret string `json:"secret"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,min=1,max=100"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        *string   `json:"url" validate:"omitempty,url,max=2048"`
+	EventTypes *[]string `json:"event_types" validate:"omitempty,min=1,dive,min=1,max=100"`
+	// Enabled re-enables a webhook disabled after repeated failures, which
+	// also resets its failure count.
+	Enabled *bool `json:"enabled"`
+}
+
+// Event is the JSON body POSTed to a webhook.
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// DeliveryResult describes one delivery attempt.
+type DeliveryResult struct {
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// generateSecret returns a new random signing secret.
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Sign returns the X-AIOX-Signature value for body sent at timestamp: the hex
+// HMAC-SHA256 of "{timestamp}.{body}" keyed with the webhook secret.
+// Receivers recompute it to verify the sender and reject stale timestamps to
+// prevent replays.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,82 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+// errBlockedAddress is returned when a webhook host resolves to an address
+// that is not publicly routable.
+var errBlockedAddress = errors.New("webhook address is not publicly routable")
+
+// blockedPrefixes are special-purpose ranges not covered by the netip
+// predicates used in blockedAddr.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT, also used for some metadata services
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can reach IPv4 private ranges
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// blockedAddr reports whether webhooks must not be delivered to ip: loopback,
+// private (which holds the fd00:ec2::254 metadata endpoint), link-local
+// (which holds 169.254.169.254), unspecified, multicast and other
+// special-purpose addresses.
+func blockedAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, p := range blockedPrefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialControl rejects connections to blocked addresses. It runs for every
+// connection after DNS resolution, so a host that resolves to a public
+// address when the webhook is saved and to an internal one later is still
+// refused.
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parsing dial address %q: %w", address, err)
+	}
+	if blockedAddr(addrPort.Addr()) {
+		return errBlockedAddress
+	}
+	return nil
+}
+
+// checkHost resolves host and returns errBlockedAddress if any of its
+// addresses is blocked, so an unusable URL is refused when the webhook is
+// saved rather than on first delivery.
+func checkHost(ctx context.Context, resolver *net.Resolver, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if blockedAddr(ip) {
+			return errBlockedAddress
+		}
+		return nil
+	}
+	addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolving webhook host: %w", err)
+	}
+	for _, ip := range addrs {
+		if blockedAddr(ip) {
+			return errBlockedAddress
+		}
+	}
+	return nil
+}
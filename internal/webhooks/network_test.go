@@ -0,0 +1,119 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/auth"
+)
+
+func TestBlockedAddr(t *testing.T) {
+	for addr, blocked := range map[string]bool{
+		"127.0.0.1":        true,
+		"10.1.2.3":         true,
+		"172.16.0.1":       true,
+		"192.168.1.1":      true,
+		"169.254.169.254":  true,
+		"100.100.100.200":  true,
+		"0.0.0.0":          true,
+		"255.255.255.255":  true,
+		"::1":              true,
+		"::":               true,
+		"fe80::1":          true,
+		"fd00:ec2::254":    true,
+		"::ffff:127.0.0.1": true,
+		"64:ff9b::a00:1":   true,
+		"93.184.216.34":    false,
+		"2606:4700::1111":  false,
+	} {
+		assert.Equal(t, blocked, blockedAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+// hookRepo serves a single webhook.
+type hookRepo struct {
+	Repository
+	hook *Webhook
+}
+
+func (r *hookRepo) GetByID(_ context.Context, id, ownerID uuid.UUID) (*Webhook, error) {
+	if r.hook.ID != id || r.hook.OwnerUserID != ownerID {
+		return nil, nil
+	}
+	return r.hook, nil
+}
+
+func newGuardedService(t *testing.T, repo Repository) (*Service, *auth.Encryptor) {
+	t.Helper()
+	enc, err := auth.NewEncryptor(testEncryptionKey)
+	require.NoError(t, err)
+	return NewService(repo, enc, 2*time.Second), enc
+}
+
+func TestValidateURL_RejectsPrivateHosts(t *testing.T) {
+	svc, _ := newGuardedService(t, nil)
+	ctx := context.Background()
+
+	for _, raw := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"https://10.0.0.5/hook",
+	} {
+		assert.ErrorIs(t, svc.validateURL(ctx, raw), ErrPrivateURL, raw)
+	}
+	assert.ErrorIs(t, svc.validateURL(ctx, "ftp://example.com/hook"), ErrInvalidURL)
+	assert.NoError(t, svc.validateURL(ctx, "https://93.184.216.34/hook"))
+
+	svc.AllowPrivateNetworks()
+	assert.NoError(t, svc.validateURL(ctx, "http://127.0.0.1:8080/hook"))
+}
+
+func TestDeliver_BlocksPrivateAddressesAtDial(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+	svc, enc := newGuardedService(t, nil)
+
+	// Hosts are checked when connecting, whatever they resolved to when the
+	// webhook was saved.
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	for _, raw := range []string{srv.URL, "http://localhost:" + u.Port()} {
+		result := svc.Deliver(context.Background(), newTestHook(t, enc, raw, "s"), &Event{Type: "x"})
+		assert.False(t, result.Delivered, raw)
+		assert.Equal(t, errBlockedAddress.Error(), result.Error, raw)
+	}
+	assert.Zero(t, hits.Load())
+}
+
+func TestService_TestReportsStatusOnly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("internal secret"))
+	}))
+	defer srv.Close()
+
+	repo := &hookRepo{}
+	svc, enc := newTestService(t, repo)
+	repo.hook = newTestHook(t, enc, srv.URL, "s")
+
+	result, err := svc.Test(context.Background(), repo.hook.ID, repo.hook.OwnerUserID)
+	require.NoError(t, err)
+	assert.False(t, result.Delivered)
+	assert.Equal(t, http.StatusInternalServerError, result.StatusCode)
+	assert.Equal(t, "endpoint returned 500", result.Error)
+}
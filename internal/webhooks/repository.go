@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	Create(ctx context.Context, hook *Webhook) error
+	ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*Webhook, error)
+	GetByID(ctx context.Context, id, ownerID uuid.UUID) (*Webhook, error)
+	Update(ctx context.Context, hook *Webhook) error
+	Delete(ctx context.Context, id, ownerID uuid.UUID) error
+
+	// ListSubscribers returns the owner's enabled webhooks subscribed to
+	// eventType, directly or through "*".
+	ListSubscribers(ctx context.Context, ownerID uuid.UUID, eventType string) ([]*Webhook, error)
+	// RecordSuccess resets the failure streak after a delivery.
+	RecordSuccess(ctx context.Context, id uuid.UUID) error
+	// RecordFailure counts a dead letter and extends the failure streak,
+	// disabling the webhook once the streak reaches disableAfter. It returns
+	// the new streak and whether this call disabled the webhook.
+	RecordFailure(ctx context.Context, id uuid.UUID, lastError string, disableAfter int) (failures int, disabled bool, err error)
+}
+
+type postgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &postgresRepository{pool: pool}
+}
+
+const webhookColumns = `id, owner_user_id, url, event_types, secret, enabled, consecutive_failures,
+		       dead_letter_count, last_delivery_at, last_error, created_at, updated_at`
+
+func (r *postgresRepository) Create(ctx context.Context, hook *Webhook) error {
+	query := `
+		INSERT INTO webhooks (id, owner_user_id, url, event_types, secret, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.pool.Exec(ctx, query,
+		hook.ID, hook.OwnerUserID, hook.URL, hook.EventTypes, hook.Secret, hook.Enabled,
+		hook.CreatedAt, hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListByOwner(ctx context.Context, ownerID uuid.UUID) ([]*Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE owner_user_id = $1
+		ORDER BY created_at DESC`
+
+	return r.query(ctx, "listing webhooks", query, ownerID)
+}
+
+func (r *postgresRepository) GetByID(ctx context.Context, id, ownerID uuid.UUID) (*Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE id = $1 AND owner_user_id = $2`
+
+	hook, err := scanWebhook(r.pool.QueryRow(ctx, query, id, ownerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("querying webhook by id: %w", err)
+	}
+	return hook, nil
+}
+
+func (r *postgresRepository) Update(ctx context.Context, hook *Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $3, event_types = $4, enabled = $5, consecutive_failures = $6, updated_at = $7
+		WHERE id = $1 AND owner_user_id = $2`
+
+	result, err := r.pool.Exec(ctx, query,
+		hook.ID, hook.OwnerUserID, hook.URL, hook.EventTypes, hook.Enabled,
+		hook.ConsecutiveFailures, hook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("updating webhook: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND owner_user_id = $2`, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *postgresRepository) ListSubscribers(ctx context.Context, ownerID uuid.UUID, eventType string) ([]*Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE owner_user_id = $1 AND enabled
+		  AND ($2 = ANY(event_types) OR $3 = ANY(event_types))`
+
+	return r.query(ctx, "listing webhook subscribers", query, ownerID, eventType, AllEvents)
+}
+
+func (r *postgresRepository) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE webhooks
+		SET consecutive_failures = 0, last_delivery_at = NOW(), last_error = NULL
+		WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("recording webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresRepository) RecordFailure(ctx context.Context, id uuid.UUID, lastError string, disableAfter int) (int, bool, error) {
+	// The CTE reads the pre-update enabled flag so only the update that
+	// crosses the threshold reports disabling the webhook.
+	query := `
+		WITH prev AS (SELECT enabled FROM webhooks WHERE id = $1)
+		UPDATE webhooks w
+		SET consecutive_failures = w.consecutive_failures + 1,
+		    dead_letter_count = w.dead_letter_count + 1,
+		    last_error = $2,
+		    enabled = w.enabled AND w.consecutive_failures + 1 < $3
+		FROM prev
+		WHERE w.id = $1
+		RETURNING w.consecutive_failures, prev.enabled AND NOT w.enabled`
+
+	var failures int
+	var disabled bool
+	err := r.pool.QueryRow(ctx, query, id, lastError, disableAfter).Scan(&failures, &disabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, ErrWebhookNotFound
+		}
+		return 0, false, fmt.Errorf("recording webhook failure: %w", err)
+	}
+	return failures, disabled, nil
+}
+
+func (r *postgresRepository) query(ctx context.Context, op, query string, args ...any) ([]*Webhook, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var hooks []*Webhook
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning webhook: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, rows.Err()
+}
+
+func scanWebhook(row pgx.Row) (*Webhook, error) {
+	hook := &Webhook{}
+	err := row.Scan(
+		&hook.ID, &hook.OwnerUserID, &hook.URL, &hook.EventTypes, &hook.Secret, &hook.Enabled,
+		&hook.ConsecutiveFailures, &hook.DeadLetterCount, &hook.LastDeliveryAt, &hook.LastError,
+		&hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
@@ -0,0 +1,235 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/auth"
+)
+
+// maxErrorBody bounds how much of a failed response is kept as last_error.
+const maxErrorBody = 256
+
+// Service manages webhooks and performs single delivery attempts. Retries are
+// the Dispatcher's concern.
+type Service struct {
+	repo         Repository
+	encryptor    *auth.Encryptor
+	client       *http.Client
+	dialer       *net.Dialer
+	resolver     *net.Resolver
+	allowPrivate bool
+}
+
+// NewService creates a webhook service. Secrets are encrypted with encryptor;
+// each delivery attempt is bounded by timeout. Webhooks can only reach
+// publicly routable addresses unless AllowPrivateNetworks is called.
+func NewService(repo Repository, encryptor *auth.Encryptor, timeout time.Duration) *Service {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the webhook host, bypassing the check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &Service{
+		repo:      repo,
+		encryptor: encryptor,
+		dialer:    dialer,
+		resolver:  net.DefaultResolver,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+			// A redirect is reported as a failed delivery rather than followed,
+			// so the signed payload only ever goes to the registered URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// AllowPrivateNetworks lets webhooks reach loopback, private and link-local
+// addresses, for development setups. It must be called before the service is
+// used.
+func (s *Service) AllowPrivateNetworks() {
+	s.allowPrivate = true
+	s.dialer.Control = nil
+}
+
+// Create registers a webhook with a new signing secret. The returned Secret is
+// the only time the plaintext is available.
+func (s *Service) Create(ctx context.Context, ownerID uuid.UUID, req *CreateWebhookRequest) (*CreatedWebhook, error) {
+	if err := s.validateURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encryptor.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting webhook secret: %w", err)
+	}
+
+	now := time.Now().UTC()
+	hook := &Webhook{
+		ID:          uuid.New(),
+		OwnerUserID: ownerID,
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      encrypted,
+		Enabled:     true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+	return &CreatedWebhook{Webhook: hook, Secret: secret}, nil
+}
+
+func (s *Service) List(ctx context.Context, ownerID uuid.UUID) ([]*Webhook, error) {
+	return s.repo.ListByOwner(ctx, ownerID)
+}
+
+// Get returns the owner's webhook or ErrWebhookNotFound.
+func (s *Service) Get(ctx context.Context, id, ownerID uuid.UUID) (*Webhook, error) {
+	hook, err := s.repo.GetByID(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if hook == nil {
+		return nil, ErrWebhookNotFound
+	}
+	return hook, nil
+}
+
+// Update applies req. Re-enabling a webhook clears its failure streak.
+func (s *Service) Update(ctx context.Context, id, ownerID uuid.UUID, req *UpdateWebhookRequest) (*Webhook, error) {
+	hook, err := s.Get(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := s.validateURL(ctx, *req.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		hook.EventTypes = *req.EventTypes
+	}
+	if req.Enabled != nil {
+		if *req.Enabled && !hook.Enabled {
+			hook.ConsecutiveFailures = 0
+		}
+		hook.Enabled = *req.Enabled
+	}
+	hook.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	return s.repo.Delete(ctx, id, ownerID)
+}
+
+// Test sends a single ping event to the webhook. It does not retry and does
+// not affect the failure streak, so it can be used to check an endpoint
+// before re-enabling it. Only the response status is reported, never the
+// body, so the endpoint's response can't be read through the API.
+func (s *Service) Test(ctx context.Context, id, ownerID uuid.UUID) (*DeliveryResult, error) {
+	hook, err := s.Get(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &Event{
+		ID:        uuid.New(),
+		Type:      PingEvent,
+		CreatedAt: time.Now().UTC(),
+		Data:      map[string]string{"webhook_id": hook.ID.String()},
+	}
+	result := s.Deliver(ctx, hook, event)
+	if !result.Delivered && result.StatusCode != 0 {
+		result.Error = statusError(result.StatusCode)
+	}
+	return &result, nil
+}
+
+// Deliver POSTs event to the webhook once, signed with its secret. Any 2xx
+// response counts as delivered.
+func (s *Service) Deliver(ctx context.Context, hook *Webhook, event *Event) DeliveryResult {
+	secret, err := s.encryptor.Decrypt(hook.Secret)
+	if err != nil {
+		return DeliveryResult{Error: "decrypting webhook secret"}
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return DeliveryResult{Error: fmt.Sprintf("encoding event: %v", err)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return DeliveryResult{Error: fmt.Sprintf("building request: %v", err)}
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AIOX-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(secret, now, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return DeliveryResult{Error: errBlockedAddress.Error()}
+		}
+		return DeliveryResult{Error: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		msg := statusError(resp.StatusCode)
+		if len(snippet) > 0 {
+			msg += ": " + string(snippet)
+		}
+		return DeliveryResult{StatusCode: resp.StatusCode, Error: msg}
+	}
+	return DeliveryResult{Delivered: true, StatusCode: resp.StatusCode}
+}
+
+func statusError(code int) string {
+	return fmt.Sprintf("endpoint returned %d", code)
+}
+
+// validateURL checks the URL's form and, unless private networks are allowed,
+// that its host does not resolve to a blocked address. A host that doesn't
+// resolve yet is accepted; deliveries check every address again at dial time.
+func (s *Service) validateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidURL
+	}
+	if !s.allowPrivate && errors.Is(checkHost(ctx, s.resolver, u.Hostname()), errBlockedAddress) {
+		return ErrPrivateURL
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"sync"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+// DefaultMaxBufferedChunks caps the streaming chunks held per request when no limit is configured.
+const DefaultMaxBufferedChunks = 256
+
+// ChunkBuffer holds the streaming chunks of a single request until the
+// outbound relay drains them. The worker protocol has no flow control, so
+// when the cap is exceeded the buffer falls back to drop-to-final: buffered
+// chunks are discarded, later chunks are refused, and the caller delivers
+// only the final TaskResponse.
+type ChunkBuffer struct {
+	mu         sync.Mutex
+	limit      int
+	chunks     []string
+	overflowed bool
+}
+
+// NewChunkBuffer creates a buffer holding at most limit chunks.
+func NewChunkBuffer(limit int) *ChunkBuffer {
+	if limit <= 0 {
+		limit = DefaultMaxBufferedChunks
+	}
+	return &ChunkBuffer{limit: limit}
+}
+
+// Push appends a chunk. It returns false once the buffer has overflowed,
+// signalling the caller to stop streaming and wait for the final response.
+func (b *ChunkBuffer) Push(chunk string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.overflowed {
+		return false
+	}
+	if len(b.chunks) >= b.limit {
+		b.overflowed = true
+		b.chunks = nil
+		metrics.ChunkBufferOverflowsTotal.Inc()
+		return false
+	}
+	b.chunks = append(b.chunks, chunk)
+	return true
+}
+
+// Drain removes and returns the buffered chunks.
+func (b *ChunkBuffer) Drain() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	chunks := b.chunks
+	b.chunks = nil
+	return chunks
+}
+
+// Overflowed reports whether the request has fallen back to drop-to-final.
+func (b *ChunkBuffer) Overflowed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.overflowed
+}
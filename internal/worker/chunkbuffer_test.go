@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aiox-platform/aiox/internal/metrics"
+)
+
+func TestChunkBuffer_PushAndDrain(t *testing.T) {
+	b := NewChunkBuffer(3)
+	assert.True(t, b.Push("a"))
+	assert.True(t, b.Push("b"))
+	assert.Equal(t, []string{"a", "b"}, b.Drain())
+	assert.Empty(t, b.Drain())
+	assert.False(t, b.Overflowed())
+}
+
+func TestChunkBuffer_OverflowDropsToFinal(t *testing.T) {
+	before := testutil.ToFloat64(metrics.ChunkBufferOverflowsTotal)
+
+	b := NewChunkBuffer(2)
+	assert.True(t, b.Push("a"))
+	assert.True(t, b.Push("b"))
+	assert.False(t, b.Push("c"))
+	assert.True(t, b.Overflowed())
+	assert.Empty(t, b.Drain())
+
+	// Once overflowed, the buffer stays closed even after draining.
+	assert.False(t, b.Push("d"))
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.ChunkBufferOverflowsTotal))
+}
+
+func TestNewChunkBuffer_DefaultLimit(t *testing.T) {
+	b := NewChunkBuffer(0)
+	for i := 0; i < DefaultMaxBufferedChunks; i++ {
+		assert.True(t, b.Push("x"))
+	}
+	assert.False(t, b.Push("x"))
+}
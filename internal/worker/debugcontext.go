@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
+)
+
+// DebugContextHeader asks the invoke endpoint to capture the task's context,
+// like the agent's debug_context capability.
+const DebugContextHeader = "X-Debug-Context"
+
+// TaskContext is what a task sent to the worker besides the system prompt.
+// JSON fields are passed through as the worker received them.
+type TaskContext struct {
+	UserMessage    string          `json:"user_message"`
+	LLMConfig      json.RawMessage `json:"llm_config,omitempty"`
+	MemoryContext  json.RawMessage `json:"memory_context,omitempty"`
+	MemoryConfig   json.RawMessage `json:"memory_config,omitempty"`
+	Tools          json.RawMessage `json:"tools,omitempty"`
+	ResponseSchema json.RawMessage `json:"response_schema,omitempty"`
+	Attachments    json.RawMessage `json:"attachments,omitempty"`
+}
+
+// ExecutionContext is the full context of an execution run with debug
+// context capture, as returned to the agent's owner. Nothing is redacted.
+type ExecutionContext struct {
+	ExecutionID  uuid.UUID `json:"execution_id"`
+	SystemPrompt string    `json:"system_prompt"`
+	TaskContext
+	CreatedAt time.Time `json:"created_at"`
+
+	// SystemPromptID names the agent_prompts row holding the prompt; nil when
+	// the agent had none or the row is gone.
+	SystemPromptID *uuid.UUID `json:"-"`
+}
+
+// capturedContext is a task's context held until its execution is recorded.
+type capturedContext struct {
+	agent *agents.Agent
+	task  TaskContext
+}
+
+// SetDebugContext enables capturing the context of tasks whose agent or
+// invoke request asks for it. It can be changed at runtime.
+func (d *Dispatcher) SetDebugContext(enabled bool) {
+	d.debugContext.Store(enabled)
+}
+
+// captureContext returns the context sent in req when capture is enabled and
+// requested, or nil.
+func (d *Dispatcher) captureContext(agent *agents.Agent, requested bool, req *pb.TaskRequest) *capturedContext {
+	if !d.debugContext.Load() || !requested {
+		return nil
+	}
+	return &capturedContext{
+		agent: agent,
+		task: TaskContext{
+			UserMessage:    req.UserMessage,
+			LLMConfig:      rawJSON(req.LlmConfigJson),
+			MemoryContext:  rawJSON(req.MemoryContextJson),
+			MemoryConfig:   rawJSON(req.MemoryConfigJson),
+			Tools:          rawJSON(req.ToolsJson),
+			ResponseSchema: rawJSON(req.ResponseSchemaJson),
+			Attachments:    rawJSON(req.AttachmentsJson),
+		},
+	}
+}
+
+// recordContext stores a captured context with its execution. The system
+// prompt is kept encrypted in agent_prompts. Failures are only logged; the
+// execution itself is already recorded.
+func (d *Dispatcher) recordContext(ctx context.Context, execID uuid.UUID, c *capturedContext) {
+	if c == nil {
+		return
+	}
+	ec := &ExecutionContext{ExecutionID: execID, TaskContext: c.task, CreatedAt: time.Now()}
+	promptID, err := d.agentSvc.SnapshotPrompt(ctx, c.agent)
+	if err != nil {
+		slog.Error("dispatcher: storing debug context prompt", "error", err, "execution_id", execID)
+		return
+	}
+	if promptID != uuid.Nil {
+		ec.SystemPromptID = &promptID
+	}
+	if err := d.repo.RecordContext(ctx, ec); err != nil {
+		slog.Error("dispatcher: recording debug context", "error", err, "execution_id", execID)
+	}
+}
+
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return nil
+	}
+	return json.RawMessage(s)
+}
@@ -3,23 +3,43 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go/jetstream"
 
 	"github.com/aiox-platform/aiox/internal/agents"
-	"github.com/aiox-platform/aiox/internal/governance"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
 	"github.com/aiox-platform/aiox/internal/governance/quota"
+	"github.com/aiox-platform/aiox/internal/governance/redaction"
+	"github.com/aiox-platform/aiox/internal/jsonschema"
 	"github.com/aiox-platform/aiox/internal/memory"
 	"github.com/aiox-platform/aiox/internal/metrics"
 	inats "github.com/aiox-platform/aiox/internal/nats"
+	"github.com/aiox-platform/aiox/internal/responsecache"
+	"github.com/aiox-platform/aiox/internal/tracing"
 	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
 )
 
+// structuredFallback is the reply sent when a worker's structured output does
+// not match the agent's response schema.
+const structuredFallback = "Sorry, I couldn't produce a response in the expected format. Please try again."
+
+// shutdownReply answers tasks still waiting for a worker when the dispatcher
+// stops.
+const shutdownReply = "Sorry, the service is restarting. Please retry your message."
+
+// ShutdownDrainTimeout bounds how long Start spends answering pending tasks
+// after its context is canceled, leaving room in cmd/api's 15s shutdown wait
+// for the rest of the process to stop.
+const ShutdownDrainTimeout = 10 * time.Second
+
 // pendingTask holds metadata for a dispatched task awaiting a response.
 type pendingTask struct {
 	RequestID    string
@@ -31,7 +51,45 @@ type pendingTask struct {
 	WorkerID     string
 	Input        string
 	DispatchedAt time.Time
+	// Timeout is the agent's task timeout, fixed at dispatch time.
+	Timeout      time.Duration
 	MemoryConfig memory.MemoryConfig
+	LLMConfig    json.RawMessage
+
+	// TraceParent is the W3C traceparent of the dispatch span, used to parent
+	// the result span when the worker responds.
+	TraceParent string
+
+	// Redactors resolved from deployment + agent policy at dispatch time.
+	StorageRedactor  redaction.Redactor
+	OutboundRedactor redaction.Redactor
+
+	// CacheLookup is set on a response cache miss so the result can be cached.
+	CacheLookup *responsecache.Lookup
+
+	// Context is set when the task's context is captured for debugging; it
+	// is stored with the execution.
+	Context *capturedContext
+
+	// Invoke is set for tasks from the synchronous invoke endpoint.
+	Invoke bool
+
+	// ResponseSchema is set when the agent requires structured output; the
+	// worker's structured_json is checked against it before delivery.
+	ResponseSchema *jsonschema.Schema
+
+	// RoomJID is set for tasks from a multi-user chat room.
+	RoomJID string
+
+	// ChatStates is set when the sender was shown the agent composing; the
+	// reply then clears the state.
+	ChatStates bool
+}
+
+// executionRecorder is the part of Repository the dispatcher writes to.
+type executionRecorder interface {
+	RecordExecution(ctx context.Context, exec *Execution) error
+	RecordContext(ctx context.Context, ec *ExecutionContext) error
 }
 
 // Dispatcher consumes tasks from NATS, dispatches to Python workers via gRPC,
@@ -41,14 +99,38 @@ type Dispatcher struct {
 	publisher   *inats.Publisher
 	consumerMgr *inats.ConsumerManager
 	agentSvc    *agents.Service
-	repo        *Repository
+	repo        executionRecorder
 	memorySvc   *memory.Service
 	quotaSvc    *quota.Service
+	redactor    *redaction.Engine
 	resultCh    <-chan *pb.TaskResponse
-	taskTimeout time.Duration
+	taskTimeout atomic.Int64                   // time.Duration; swappable on config reload
+	maxTimeout  atomic.Int64                   // time.Duration cap on agent timeouts; 0 is uncapped
+	llmCaps     atomic.Pointer[agents.LLMCaps] // global LLM caps; swappable on config reload
+	maxChunks   int                            // per-request streaming chunk buffer cap
+	cache       *responsecache.Service
+	maxDeliver  int // deliveries before a task is dead-lettered; 0 retries forever
+	pricing     *quota.Pricing
+
+	// debugContext allows capturing task contexts on request; swappable on
+	// config reload.
+	debugContext atomic.Bool
+
+	// gate holds tasks while a dependency they need is down; nil dispatches
+	// regardless.
+	gate *HealthGate
+	// held tracks the goroutines holding tasks for the gate.
+	held sync.WaitGroup
+	// holdInterval overrides holdCheckInterval in tests.
+	holdInterval time.Duration
 
-	mu      sync.Mutex
-	pending map[string]*pendingTask
+	// summaryCh delivers worker responses to Summarize calls, keyed in summaries.
+	summaryCh <-chan *pb.SummarizeResponse
+
+	mu          sync.Mutex
+	pending     map[string]*pendingTask
+	summaries   map[string]chan *pb.SummarizeResponse
+	invocations map[string]chan InvokeResult
 }
 
 // NewDispatcher creates a new task dispatcher.
@@ -60,6 +142,7 @@ func NewDispatcher(
 	repo *Repository,
 	memorySvc *memory.Service,
 	quotaSvc *quota.Service,
+	redactor *redaction.Engine,
 	resultCh <-chan *pb.TaskResponse,
 	taskTimeoutSec int,
 ) *Dispatcher {
@@ -67,7 +150,7 @@ func NewDispatcher(
 	if timeout <= 0 {
 		timeout = 120 * time.Second
 	}
-	return &Dispatcher{
+	d := &Dispatcher{
 		pool:        pool,
 		publisher:   publisher,
 		consumerMgr: consumerMgr,
@@ -75,27 +158,174 @@ func NewDispatcher(
 		repo:        repo,
 		memorySvc:   memorySvc,
 		quotaSvc:    quotaSvc,
+		redactor:    redactor,
 		resultCh:    resultCh,
-		taskTimeout: timeout,
 		pending:     make(map[string]*pendingTask),
+		summaries:   make(map[string]chan *pb.SummarizeResponse),
+		invocations: make(map[string]chan InvokeResult),
+	}
+	d.taskTimeout.Store(int64(timeout))
+	return d
+}
+
+// SetTaskTimeout changes the timeout applied to pending tasks at runtime.
+func (d *Dispatcher) SetTaskTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		d.taskTimeout.Store(int64(timeout))
+	}
+}
+
+func (d *Dispatcher) timeout() time.Duration {
+	return time.Duration(d.taskTimeout.Load())
+}
+
+// SetMaxTaskTimeout caps the task timeout agents may set in their
+// capabilities. Zero leaves them uncapped. It can be changed at runtime.
+func (d *Dispatcher) SetMaxTaskTimeout(max time.Duration) {
+	d.maxTimeout.Store(int64(max))
+}
+
+// timeoutFor returns the task timeout for an agent: its task_timeout_sec
+// capability, capped by the maximum, or the dispatcher's timeout when unset.
+func (d *Dispatcher) timeoutFor(capabilities []byte) time.Duration {
+	caps, err := agents.ParseCapabilities(capabilities)
+	if err != nil || caps.TaskTimeoutSec <= 0 {
+		return d.timeout()
+	}
+	timeout := time.Duration(caps.TaskTimeoutSec) * time.Second
+	if max := time.Duration(d.maxTimeout.Load()); max > 0 {
+		timeout = min(timeout, max)
+	}
+	return timeout
+}
+
+// SetLLMCaps sets the deployment's caps on agents' LLM configs, which admins
+// may override per agent. It can be changed at runtime.
+func (d *Dispatcher) SetLLMCaps(caps agents.LLMCaps) {
+	d.llmCaps.Store(&caps)
+}
+
+// effectiveLLMConfig clamps the agent's LLM config to its caps.
+func (d *Dispatcher) effectiveLLMConfig(agent *agents.Agent, gov policy.GovernancePolicy) (json.RawMessage, []agents.Clamp, error) {
+	var caps agents.LLMCaps
+	if c := d.llmCaps.Load(); c != nil {
+		caps = *c
+	}
+	return caps.Merge(agent.LLMCaps).Apply(agent.LLMConfig, gov.MaxTokensPerRequest)
+}
+
+// SetMaxBufferedChunks sets the per-request streaming chunk buffer cap.
+// It must be called before Start.
+func (d *Dispatcher) SetMaxBufferedChunks(n int) {
+	d.maxChunks = n
+}
+
+// SetResponseCache enables serving cached responses for agents that opt in.
+// It must be called before Start.
+func (d *Dispatcher) SetResponseCache(cache *responsecache.Service) {
+	d.cache = cache
+}
+
+// SetHealthGate makes the dispatcher hold tasks while a dependency they need
+// is down. It must be called before Start, which runs the gate's checks.
+func (d *Dispatcher) SetHealthGate(gate *HealthGate) {
+	d.gate = gate
+}
+
+// holdForHealth holds msg until dependency recovers when it is down and the
+// gate fails closed, reporting whether it did. A held task is handled again
+// with ctx once the dependency is back, so ctx must not carry the span of the
+// attempt that held it.
+func (d *Dispatcher) holdForHealth(ctx context.Context, log *slog.Logger, msg jetstream.Msg, dependency string) bool {
+	down, hold := d.gate.Check(dependency)
+	if !down {
+		return false
+	}
+	if !hold {
+		log.Warn("dispatcher: dispatching despite unhealthy dependency", "dependency", dependency)
+		return false
 	}
+	log.Warn("dispatcher: holding task while a dependency is unhealthy", "dependency", dependency)
+	metrics.TasksHeldTotal.WithLabelValues(dependency).Inc()
+	d.held.Add(1)
+	go func() {
+		defer d.held.Done()
+		d.hold(ctx, msg, dependency, func() { d.handleTask(ctx, msg) })
+	}()
+	return true
+}
+
+// hold keeps msg in progress until dependency recovers, then calls resume.
+// Extending the ack deadline rather than nak'ing keeps the message from being
+// redelivered, so however long the outage lasts, holding uses up none of the
+// deliveries counted toward dead-lettering or the consumer's MaxDeliver. On
+// shutdown msg is nak'd so another instance picks it up.
+func (d *Dispatcher) hold(ctx context.Context, msg jetstream.Msg, dependency string, resume func()) {
+	ticker := time.NewTicker(d.holdCheckInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = msg.Nak()
+			return
+		case <-ticker.C:
+			if _, held := d.gate.Check(dependency); !held {
+				resume()
+				return
+			}
+			if err := msg.InProgress(); err != nil {
+				slog.Warn("dispatcher: extending held task's ack deadline", "error", err)
+			}
+		}
+	}
+}
+
+// holdCheckInterval is how often a held task checks the gate and extends its
+// ack deadline: every gate check, and at least twice per ack wait.
+func (d *Dispatcher) holdCheckInterval() time.Duration {
+	if d.holdInterval > 0 {
+		return d.holdInterval
+	}
+	interval := healthGateInterval
+	if d.consumerMgr != nil {
+		interval = min(interval, d.consumerMgr.Policy().AckWait/2)
+	}
+	return interval
+}
+
+// SetMaxDeliveries sets how many times a task may be delivered before it is
+// moved to the dead-letter stream. Zero disables dead-lettering.
+// It must be called before Start.
+func (d *Dispatcher) SetMaxDeliveries(n int) {
+	d.maxDeliver = n
+}
+
+// SetPricing sets the model prices used to record each execution's cost.
+// Without it, costs are recorded as zero. It must be called before Start.
+func (d *Dispatcher) SetPricing(p *quota.Pricing) {
+	d.pricing = p
+}
+
+// NewChunkBuffer returns a chunk buffer for one request, sized from the configured cap.
+func (d *Dispatcher) NewChunkBuffer() *ChunkBuffer {
+	return NewChunkBuffer(d.maxChunks)
 }
 
 // Start runs the dispatcher's consume, result processing, and timeout cleanup loops.
 func (d *Dispatcher) Start(ctx context.Context) error {
-	consumer, err := d.consumerMgr.EnsureConsumer(ctx, inats.StreamTasks, "task-dispatcher", "aiox.tasks.>")
+	queues, err := d.ensureTaskQueues(ctx)
 	if err != nil {
 		return err
 	}
 
-	slog.Info("task dispatcher started", "timeout", d.taskTimeout)
+	slog.Info("task dispatcher started", "timeout", d.timeout())
 
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		d.consumeTasks(ctx, consumer)
+		d.consumeTasks(ctx, queues)
 	}()
 
 	wg.Add(1)
@@ -110,110 +340,164 @@ func (d *Dispatcher) Start(ctx context.Context) error {
 		d.cleanupTimeouts(ctx)
 	}()
 
-	wg.Wait()
-	return nil
-}
-
-func (d *Dispatcher) consumeTasks(ctx context.Context, consumer jetstream.Consumer) {
-	for {
-		msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(inats.FetchTimeout))
-		if err != nil {
-			if ctx.Err() != nil {
-				return
-			}
-			slog.Debug("dispatcher: fetching tasks", "error", err)
-			continue
-		}
+	if d.gate != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.gate.Run(ctx)
+		}()
+	}
 
-		for msg := range msgs.Messages() {
-			d.handleTask(ctx, msg)
-		}
+	wg.Wait()
+	d.held.Wait()
 
-		if ctx.Err() != nil {
-			return
-		}
-	}
+	// No result can arrive any more, so answer the tasks still waiting
+	// instead of leaving their senders without a reply.
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ShutdownDrainTimeout)
+	defer cancel()
+	d.drainPending(drainCtx)
+	return nil
 }
 
 func (d *Dispatcher) handleTask(ctx context.Context, msg jetstream.Msg) {
 	var task inats.TaskMessage
-	if err := json.Unmarshal(msg.Data(), &task); err != nil {
+	if err := inats.Decode(msg.Headers(), msg.Data(), &task); err != nil {
 		slog.Error("dispatcher: unmarshaling task", "error", err)
-		_ = msg.Nak()
+		d.retryOrDeadLetter(ctx, msg, nil, "undecodable task: "+err.Error())
+		return
+	}
+
+	// A held task is handled again from the start, under its own span.
+	holdCtx := ctx
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, task.TraceParent), "dispatcher.dispatch",
+		tracing.AttrAgentID.String(task.AgentID.String()),
+		tracing.AttrRequestID.String(task.RequestID),
+	)
+	defer span.End()
+
+	log := slog.With("request_id", task.RequestID)
+
+	// Hold the task rather than run it without a database to record it in
+	if d.holdForHealth(holdCtx, log, msg, DependencyDatabase) {
 		return
 	}
 
 	// Fetch agent to get decrypted system prompt and LLM config
 	agent, err := d.agentSvc.GetByID(ctx, task.AgentID)
 	if err != nil {
-		slog.Error("dispatcher: fetching agent", "error", err, "agent_id", task.AgentID)
-		_ = msg.Nak()
+		log.Error("dispatcher: fetching agent", "error", err, "agent_id", task.AgentID)
+		d.retryOrDeadLetter(ctx, msg, &task, "fetching agent failed")
 		return
 	}
 	if agent == nil {
-		slog.Warn("dispatcher: agent not found", "agent_id", task.AgentID)
+		log.Warn("dispatcher: agent not found", "agent_id", task.AgentID)
 		d.sendErrorResponse(ctx, task, "Agent not found")
 		_ = msg.Ack()
 		return
 	}
 
+	// Likewise without the memory store, for agents that use it. Both holds
+	// come before anything is sent or audited, so a held task that is
+	// handled again doesn't repeat it.
+	memCfg := memory.ParseConfig(agent.MemoryConfig)
+	if memCfg.Enabled && d.memorySvc != nil && d.holdForHealth(holdCtx, log, msg, DependencyMemory) {
+		return
+	}
+
+	// Tasks queued before the agent was paused are not run.
+	if agent.Status == agents.StatusPaused {
+		log.Info("dispatcher: agent is paused", "agent_id", task.AgentID)
+		d.sendErrorResponse(ctx, task, "Agent is paused")
+		_ = msg.Ack()
+		return
+	}
+
 	// Governance checks at dispatch time
-	gov := governance.ParseGovernance(agent.Governance)
+	gov := policy.Parse(agent.Governance)
 
 	if gov.Blocked {
-		slog.Warn("dispatcher: agent blocked by governance", "agent_id", task.AgentID)
+		log.Warn("dispatcher: agent blocked by governance", "agent_id", task.AgentID)
 		d.sendErrorResponse(ctx, task, "Agent is blocked by governance policy")
 		_ = msg.Ack()
 		return
 	}
+	if !gov.AllowedHours.Contains(time.Now()) {
+		log.Info("dispatcher: agent outside allowed hours", "agent_id", task.AgentID)
+		d.rejectOutsideHours(ctx, task, gov.AllowedHours)
+		_ = msg.Ack()
+		return
+	}
 
 	// Check allowed providers against agent's LLM config
 	if len(gov.AllowedProviders) > 0 {
 		provider := extractProvider(agent.LLMConfig)
 		if provider != "" && !providerAllowed(provider, gov.AllowedProviders) {
-			slog.Warn("dispatcher: provider not allowed", "agent_id", task.AgentID, "provider", provider)
+			log.Warn("dispatcher: provider not allowed", "agent_id", task.AgentID, "provider", provider)
 			d.sendErrorResponse(ctx, task, "LLM provider '"+provider+"' not allowed by governance policy")
 			_ = msg.Ack()
 			return
 		}
 	}
 
-	// Select a worker
-	worker := d.pool.SelectWorker()
-	if worker == nil {
-		slog.Warn("dispatcher: no workers available, nacking for retry", "request_id", task.RequestID)
-		_ = msg.Nak()
+	// Enforce the LLM caps before the config reaches a worker
+	llmConfig, clamps, err := d.effectiveLLMConfig(agent, gov)
+	if err != nil {
+		log.Warn("dispatcher: LLM config rejected by caps", "error", err, "agent_id", task.AgentID)
+		d.auditTask(ctx, task, "llm_config_rejected", "warn", err.Error())
+		d.sendErrorResponse(ctx, task, err.Error())
+		_ = msg.Ack()
 		return
 	}
+	if len(clamps) > 0 {
+		details := make([]string, len(clamps))
+		for i, c := range clamps {
+			details[i] = c.String()
+		}
+		log.Info("dispatcher: LLM config clamped by caps", "agent_id", task.AgentID, "clamped", details)
+		d.auditTask(ctx, task, "llm_config_clamped", "info", "Clamped "+strings.Join(details, ", "))
+	}
 
 	// Build task request
-	llmConfigJSON, _ := json.Marshal(json.RawMessage(agent.LLMConfig))
+	llmConfigJSON, _ := json.Marshal(llmConfig)
 
 	taskReq := &pb.TaskRequest{
-		RequestId:     task.RequestID,
-		AgentId:       task.AgentID.String(),
-		OwnerUserId:   task.OwnerUserID.String(),
-		UserMessage:   task.Message,
-		SystemPrompt:  agent.Profile.SystemPrompt,
-		LlmConfigJson: string(llmConfigJSON),
-		FromJid:       task.FromJID,
-		AgentJid:      task.AgentJID,
-		AgentName:     task.AgentName,
-	}
-
-	// Parse memory config and fetch conversation context
-	memCfg := memory.ParseConfig(agent.MemoryConfig)
+		RequestId:       task.RequestID,
+		AgentId:         task.AgentID.String(),
+		OwnerUserId:     task.OwnerUserID.String(),
+		UserMessage:     task.Message,
+		SystemPrompt:    agent.Profile.SystemPrompt,
+		LlmConfigJson:   string(llmConfigJSON),
+		FromJid:         task.FromJID,
+		AgentJid:        task.AgentJID,
+		AgentName:       task.AgentName,
+		AttachmentsJson: inats.MarshalAttachments(task.Attachments),
+	}
+	caps, _ := agents.ParseCapabilities(agent.Capabilities)
+	if len(caps.EnabledTools) > 0 {
+		if toolsJSON, err := json.Marshal(caps.EnabledToolConfigs()); err == nil {
+			taskReq.ToolsJson = string(toolsJSON)
+		}
+	}
+	responseSchema, err := caps.Schema()
+	if err != nil {
+		log.Warn("dispatcher: ignoring invalid response schema", "error", err, "agent_id", task.AgentID)
+	} else if responseSchema != nil {
+		taskReq.ResponseSchemaJson = string(caps.ResponseSchema)
+	}
+
+	// Fetch conversation context
 	if memCfg.Enabled && d.memorySvc != nil {
-		// Note: queryEmbedding is nil here — on the first message there are no prior
-		// embeddings, so long-term search returns empty. Embeddings are generated by
-		// the Python worker and stored after the response. On subsequent messages the
-		// dispatcher still passes nil because embedding generation only happens in Python.
-		// Future: could cache the last user embedding in Redis for retrieval here.
+		// Without a server-side embedder queryEmbedding stays nil and long-term
+		// search is skipped; the Python worker still embeds what gets stored.
+		queryEmbedding, err := d.memorySvc.QueryEmbedding(ctx, task.AgentID, task.FromJID, task.Message, memCfg)
+		if err != nil {
+			log.Warn("dispatcher: embedding message for memory search", "error", err, "agent_id", task.AgentID)
+		}
 		memCtx, err := d.memorySvc.GetConversationContext(
-			ctx, task.AgentID, task.OwnerUserID, task.FromJID, memCfg, nil,
+			ctx, task.AgentID, task.OwnerUserID, task.FromJID, memCfg, queryEmbedding,
 		)
 		if err != nil {
-			slog.Warn("dispatcher: fetching memory context", "error", err, "agent_id", task.AgentID)
+			log.Warn("dispatcher: fetching memory context", "error", err, "agent_id", task.AgentID)
 		} else if memCtx != nil {
 			if ctxJSON, err := json.Marshal(memCtx); err == nil {
 				taskReq.MemoryContextJson = string(ctxJSON)
@@ -225,18 +509,60 @@ func (d *Dispatcher) handleTask(ctx context.Context, msg jetstream.Msg) {
 		}
 	}
 
-	// Send to worker
-	if err := worker.Send(&pb.ServerMessage{
+	captured := d.captureContext(agent, task.DebugContext || caps.DebugContext, taskReq)
+
+	storageRedactor, outboundRedactor := d.redactorsFor(ctx, log, task, gov.Redaction)
+
+	// Serve from the response cache when the agent opts in. Messages with
+	// attachments are never cached since the key covers only the text, nor
+	// are structured replies, which the cache does not keep.
+	var cacheLookup *responsecache.Lookup
+	if cacheCfg := responsecache.ParseConfig(agent.Capabilities); cacheCfg.Enabled && d.cache != nil &&
+		len(task.Attachments) == 0 && responseSchema == nil {
+		fingerprint := responsecache.Fingerprint(agent.Profile.SystemPrompt, llmConfig, taskReq.MemoryContextJson)
+		entry, lookup, err := d.cache.Get(ctx, task.AgentID, cacheCfg, fingerprint, task.Message)
+		if err != nil {
+			log.Warn("dispatcher: response cache lookup", "error", err, "agent_id", task.AgentID)
+		} else if entry != nil {
+			d.serveCached(ctx, task, entry, memCfg, storageRedactor, outboundRedactor)
+			_ = msg.Ack()
+			return
+		}
+		cacheLookup = lookup
+	}
+
+	// Send to a worker that serves the agent's provider
+	provider, model := extractProvider(agent.LLMConfig), extractModel(agent.LLMConfig)
+	worker, err := d.sendToWorker(provider, model, &pb.ServerMessage{
 		Payload: &pb.ServerMessage_TaskRequest{
 			TaskRequest: taskReq,
 		},
-	}); err != nil {
-		slog.Error("dispatcher: sending task to worker", "error", err, "worker_id", worker.WorkerID)
-		_ = msg.Nak()
+	})
+	if worker == nil {
+		if err != nil {
+			log.Error("dispatcher: sending task to worker", "error", err)
+			span.RecordError(err)
+			d.retryOrDeadLetter(ctx, msg, &task, "sending task to worker failed")
+			return
+		}
+		log.Warn("dispatcher: no workers available, nacking for retry", "provider", provider, "model", model)
+		reason := "no workers available"
+		if provider != "" {
+			reason = "no workers available for provider " + provider
+		}
+		if model != "" {
+			reason += " and model " + model
+		}
+		d.retryOrDeadLetter(ctx, msg, &task, reason)
 		return
 	}
+	span.SetAttributes(tracing.AttrWorkerID.String(worker.WorkerID))
 
-	worker.IncrementActive()
+	// Show the agent composing until the result arrives
+	chatStates := !task.Invoke && task.RoomJID == "" && agents.ParseChatFeatures(agent.Capabilities).ChatStates
+	if chatStates {
+		d.sendChatState(ctx, task, inats.ChatStateComposing)
+	}
 
 	// Track pending task
 	d.mu.Lock()
@@ -250,15 +576,26 @@ func (d *Dispatcher) handleTask(ctx context.Context, msg jetstream.Msg) {
 		WorkerID:     worker.WorkerID,
 		Input:        task.Message,
 		DispatchedAt: time.Now(),
+		Timeout:      d.timeoutFor(agent.Capabilities),
 		MemoryConfig: memCfg,
+		LLMConfig:    llmConfig,
+		TraceParent:  tracing.TraceParent(ctx),
+
+		StorageRedactor:  storageRedactor,
+		OutboundRedactor: outboundRedactor,
+		CacheLookup:      cacheLookup,
+		Context:          captured,
+		Invoke:           task.Invoke,
+		ResponseSchema:   responseSchema,
+		RoomJID:          task.RoomJID,
+		ChatStates:       chatStates,
 	}
 	d.mu.Unlock()
 
 	_ = msg.Ack()
 	metrics.TasksDispatchedTotal.Inc()
 
-	slog.Debug("dispatcher: task dispatched",
-		"request_id", task.RequestID,
+	log.Debug("dispatcher: task dispatched",
 		"agent_id", task.AgentID,
 		"worker_id", worker.WorkerID,
 	)
@@ -271,6 +608,8 @@ func (d *Dispatcher) processResults(ctx context.Context) {
 			return
 		case resp := <-d.resultCh:
 			d.handleResult(ctx, resp)
+		case resp := <-d.summaryCh:
+			d.handleSummary(resp)
 		}
 	}
 }
@@ -288,6 +627,16 @@ func (d *Dispatcher) handleResult(ctx context.Context, resp *pb.TaskResponse) {
 		return
 	}
 
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, pt.TraceParent), "dispatcher.result",
+		tracing.AttrAgentID.String(pt.AgentID.String()),
+		tracing.AttrRequestID.String(pt.RequestID),
+		tracing.AttrWorkerID.String(resp.WorkerId),
+		tracing.AttrTokens.Int64(int64(resp.TokensUsed)),
+	)
+	defer span.End()
+
+	log := slog.With("request_id", pt.RequestID)
+
 	// Decrement worker's active count
 	if w := d.pool.Get(resp.WorkerId); w != nil {
 		w.DecrementActive()
@@ -295,58 +644,121 @@ func (d *Dispatcher) handleResult(ctx context.Context, resp *pb.TaskResponse) {
 
 	goLatency := int(time.Since(pt.DispatchedAt).Milliseconds())
 
-	// Determine response body
-	body := resp.ResponseText
+	// Determine response body. A structured-only reply is sent as its JSON.
+	output := resp.ResponseText
+	if output == "" {
+		output = resp.StructuredJson
+	}
+	body := output
 	status := "completed"
-	if resp.ErrorMessage != "" {
-		body = "Error processing your message: " + resp.ErrorMessage
+	errMsg := resp.ErrorMessage
+	var structured string
+	if errMsg != "" {
+		body = "Error processing your message: " + errMsg
 		status = "error"
+	} else if pt.ResponseSchema != nil {
+		if err := checkStructured(pt.ResponseSchema, resp.StructuredJson); err != nil {
+			log.Warn("dispatcher: structured output does not match the response schema", "worker_id", resp.WorkerId, "error", err)
+			errMsg = "structured output does not match the response schema: " + err.Error()
+			body = structuredFallback
+			status = "error"
+		} else {
+			structured = resp.StructuredJson
+		}
 	}
 
-	// Publish outbound message
-	outbound := inats.OutboundMessage{
-		ID:        uuid.New().String(),
-		ToJID:     pt.FromJID,
-		FromJID:   pt.AgentJID,
-		Body:      body,
-		InReplyTo: pt.RequestID,
-	}
-	if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
-		slog.Error("dispatcher: publishing outbound", "error", err)
+	// Redact before anything leaves or is persisted
+	storedInput := pt.StorageRedactor.Redact(pt.Input)
+	storedOutput := pt.StorageRedactor.Redact(output)
+
+	// Answer the waiting invoke request, or publish an outbound message
+	if pt.Invoke {
+		res := InvokeResult{
+			Response:   pt.OutboundRedactor.Redact(resp.ResponseText),
+			TokensUsed: int(resp.TokensUsed),
+			Model:      resp.ModelUsed,
+			LatencyMs:  goLatency,
+			Error:      errMsg,
+		}
+		if structured != "" {
+			res.Structured = json.RawMessage(pt.OutboundRedactor.Redact(structured))
+		}
+		d.notifyInvocation(pt.RequestID, res)
+	} else {
+		outbound := inats.OutboundMessage{
+			ID:          uuid.New().String(),
+			ToJID:       pt.FromJID,
+			FromJID:     pt.AgentJID,
+			Body:        pt.OutboundRedactor.Redact(body),
+			InReplyTo:   pt.RequestID,
+			TraceParent: tracing.TraceParent(ctx),
+			RoomJID:     pt.RoomJID,
+		}
+		if pt.ChatStates {
+			outbound.ChatState = inats.ChatStateActive
+		}
+		if status == "completed" {
+			attachments, dropped := inats.ValidAttachments(inats.UnmarshalAttachments(resp.AttachmentsJson))
+			if dropped > 0 {
+				log.Warn("dispatcher: dropped invalid or excess attachments from worker", "worker_id", resp.WorkerId, "dropped", dropped)
+			}
+			outbound.Attachments = attachments
+		}
+		if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
+			log.Error("dispatcher: publishing outbound", "error", err)
+		}
 	}
 
 	// Record execution
 	exec := &Execution{
 		ID:              uuid.New(),
+		RequestID:       pt.RequestID,
 		OwnerUserID:     pt.OwnerUserID,
 		AgentID:         pt.AgentID,
-		Input:           pt.Input,
-		Output:          resp.ResponseText,
+		Input:           storedInput,
+		Output:          storedOutput,
 		TokensUsed:      int(resp.TokensUsed),
+		Model:           resp.ModelUsed,
+		CostUSD:         d.pricing.EstimateCost(resp.ModelUsed, int(resp.TokensUsed)),
 		WorkerID:        resp.WorkerId,
 		DurationMs:      int(resp.DurationMs),
 		GoLatencyMs:     goLatency,
 		PythonLatencyMs: int(resp.DurationMs),
 		Status:          status,
-		ErrorMessage:    resp.ErrorMessage,
+		ErrorMessage:    errMsg,
 		CreatedAt:       time.Now(),
+		Redacted:        storedInput != pt.Input || storedOutput != output,
 	}
 	if err := d.repo.RecordExecution(ctx, exec); err != nil {
-		slog.Error("dispatcher: recording execution", "error", err)
+		log.Error("dispatcher: recording execution", "error", err)
+	} else {
+		d.recordContext(ctx, exec.ID, pt.Context)
 	}
 
 	// Deduct tokens from quota after successful completion
 	if status == "completed" && resp.TokensUsed > 0 && d.quotaSvc != nil {
 		if err := d.quotaSvc.DeductTokens(ctx, pt.OwnerUserID, int(resp.TokensUsed)); err != nil {
-			slog.Warn("dispatcher: deducting tokens from quota", "error", err, "user_id", pt.OwnerUserID)
+			log.Warn("dispatcher: deducting tokens from quota", "error", err, "user_id", pt.OwnerUserID)
+		}
+		if err := d.quotaSvc.DeductAgentTokens(ctx, pt.AgentID, pt.OwnerUserID, int(resp.TokensUsed)); err != nil {
+			log.Warn("dispatcher: deducting tokens from agent quota", "error", err, "agent_id", pt.AgentID)
+		}
+	}
+
+	// Cache the response unless redaction would alter what is persisted or it
+	// carries attachments the cache cannot replay
+	if pt.CacheLookup != nil && status == "completed" && resp.AttachmentsJson == "" &&
+		storedInput == pt.Input && storedOutput == output {
+		if err := d.cache.Store(ctx, pt.CacheLookup, resp.ResponseText, resp.ModelUsed, int(resp.TokensUsed)); err != nil {
+			log.Warn("dispatcher: storing cached response", "error", err, "agent_id", pt.AgentID)
 		}
 	}
 
 	// Store memory if enabled
 	if pt.MemoryConfig.Enabled && d.memorySvc != nil && status == "completed" {
 		// Store short-term conversation turn
-		if err := d.memorySvc.StoreConversationTurn(ctx, pt.AgentID, pt.FromJID, pt.Input, resp.ResponseText, pt.MemoryConfig); err != nil {
-			slog.Warn("dispatcher: storing conversation turn", "error", err, "agent_id", pt.AgentID)
+		if err := d.memorySvc.StoreConversationTurn(ctx, pt.AgentID, pt.FromJID, storedInput, storedOutput, pt.MemoryConfig); err != nil {
+			log.Warn("dispatcher: storing conversation turn", "error", err, "agent_id", pt.AgentID)
 		}
 
 		// Store long-term memories returned by the Python worker (with embeddings)
@@ -363,16 +775,24 @@ func (d *Dispatcher) handleResult(ctx context.Context, resp *pb.TaskResponse) {
 				m := &memory.Memory{
 					OwnerUserID: pt.OwnerUserID,
 					AgentID:     pt.AgentID,
-					Content:     mem.Content,
+					Content:     pt.StorageRedactor.Redact(mem.Content),
 					Embedding:   embedding,
 					MemoryType:  mem.MemoryType,
 					Metadata:    metadata,
 				}
-				if err := d.memorySvc.StoreLongTermMemory(ctx, m); err != nil {
-					slog.Warn("dispatcher: storing long-term memory", "error", err, "agent_id", pt.AgentID)
+				created, err := d.memorySvc.StoreLongTermMemory(ctx, m, pt.MemoryConfig)
+				if err != nil {
+					log.Warn("dispatcher: storing long-term memory", "error", err, "agent_id", pt.AgentID)
+				} else if !created {
+					log.Debug("dispatcher: merged duplicate long-term memory", "agent_id", pt.AgentID)
 				}
 			}
 		}
+
+		// Runs in the background: the summary arrives through this same result loop.
+		if pt.MemoryConfig.AutoSummarize {
+			go d.summarizeConversation(ctx, pt)
+		}
 	}
 
 	// Audit event
@@ -382,7 +802,7 @@ func (d *Dispatcher) handleResult(ctx context.Context, resp *pb.TaskResponse) {
 		Severity:     "info",
 		ResourceType: "agent",
 		ResourceID:   pt.AgentID.String(),
-		Details:      "Task processed by worker " + resp.WorkerId + ", model: " + resp.ModelUsed,
+		Details:      "Task " + pt.RequestID + " processed by worker " + resp.WorkerId + ", model: " + resp.ModelUsed,
 		Timestamp:    time.Now().UTC(),
 	}
 	if status == "error" {
@@ -390,13 +810,12 @@ func (d *Dispatcher) handleResult(ctx context.Context, resp *pb.TaskResponse) {
 		audit.EventType = "task_failed"
 	}
 	if err := d.publisher.PublishAuditEvent(ctx, audit); err != nil {
-		slog.Error("dispatcher: publishing audit event", "error", err)
+		log.Error("dispatcher: publishing audit event", "error", err)
 	}
 
 	metrics.TasksCompletedTotal.WithLabelValues(status).Inc()
 
-	slog.Debug("dispatcher: result processed",
-		"request_id", resp.RequestId,
+	log.Debug("dispatcher: result processed",
 		"worker_id", resp.WorkerId,
 		"status", status,
 		"tokens", resp.TokensUsed,
@@ -404,6 +823,87 @@ func (d *Dispatcher) handleResult(ctx context.Context, resp *pb.TaskResponse) {
 	)
 }
 
+// serveCached replies with a cached response without involving a worker or spending tokens.
+func (d *Dispatcher) serveCached(
+	ctx context.Context,
+	task inats.TaskMessage,
+	entry *responsecache.Entry,
+	memCfg memory.MemoryConfig,
+	storageRedactor, outboundRedactor redaction.Redactor,
+) {
+	start := time.Now()
+	log := slog.With("request_id", task.RequestID)
+
+	if task.Invoke {
+		d.notifyInvocation(task.RequestID, InvokeResult{
+			Response:  outboundRedactor.Redact(entry.Response),
+			Model:     entry.ModelUsed,
+			LatencyMs: int(time.Since(start).Milliseconds()),
+			FromCache: true,
+		})
+	} else {
+		outbound := inats.OutboundMessage{
+			ID:          uuid.New().String(),
+			ToJID:       task.FromJID,
+			FromJID:     task.AgentJID,
+			Body:        outboundRedactor.Redact(entry.Response),
+			InReplyTo:   task.RequestID,
+			FromCache:   true,
+			TraceParent: tracing.TraceParent(ctx),
+			RoomJID:     task.RoomJID,
+		}
+		if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
+			log.Error("dispatcher: publishing cached outbound", "error", err)
+		}
+	}
+
+	storedInput := storageRedactor.Redact(task.Message)
+	storedOutput := storageRedactor.Redact(entry.Response)
+
+	exec := &Execution{
+		ID:          uuid.New(),
+		RequestID:   task.RequestID,
+		OwnerUserID: task.OwnerUserID,
+		AgentID:     task.AgentID,
+		Input:       storedInput,
+		Output:      storedOutput,
+		Model:       entry.ModelUsed,
+		GoLatencyMs: int(time.Since(start).Milliseconds()),
+		Status:      "cached",
+		CreatedAt:   time.Now(),
+		Redacted:    storedInput != task.Message || storedOutput != entry.Response,
+	}
+	if err := d.repo.RecordExecution(ctx, exec); err != nil {
+		log.Error("dispatcher: recording cached execution", "error", err)
+	}
+
+	if memCfg.Enabled && d.memorySvc != nil {
+		if err := d.memorySvc.StoreConversationTurn(ctx, task.AgentID, task.FromJID, storedInput, storedOutput, memCfg); err != nil {
+			log.Warn("dispatcher: storing conversation turn", "error", err, "agent_id", task.AgentID)
+		}
+	}
+
+	audit := inats.AuditEvent{
+		OwnerUserID:  task.OwnerUserID,
+		EventType:    "task_cache_hit",
+		Severity:     "info",
+		ResourceType: "agent",
+		ResourceID:   task.AgentID.String(),
+		Details:      "Served cached response to task " + task.RequestID + ", model: " + entry.ModelUsed,
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := d.publisher.PublishAuditEvent(ctx, audit); err != nil {
+		log.Error("dispatcher: publishing audit event", "error", err)
+	}
+
+	metrics.ResponseCacheHitsTotal.Inc()
+
+	log.Debug("dispatcher: served cached response",
+		"agent_id", task.AgentID,
+		"cache_entry", entry.ID,
+	)
+}
+
 func (d *Dispatcher) cleanupTimeouts(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -423,7 +923,7 @@ func (d *Dispatcher) expireStale(ctx context.Context) {
 	var expired []*pendingTask
 	now := time.Now()
 	for id, pt := range d.pending {
-		if now.Sub(pt.DispatchedAt) > d.taskTimeout {
+		if now.Sub(pt.DispatchedAt) > pt.Timeout {
 			expired = append(expired, pt)
 			delete(d.pending, id)
 		}
@@ -432,67 +932,292 @@ func (d *Dispatcher) expireStale(ctx context.Context) {
 
 	for _, pt := range expired {
 		slog.Warn("dispatcher: task timed out", "request_id", pt.RequestID, "agent_id", pt.AgentID)
+		// An invoke request times out on its own
+		d.abandon(ctx, pt, "Sorry, the request timed out. Please try again.",
+			"timeout", "task timed out after "+pt.Timeout.String())
+	}
+}
 
-		// Send timeout error to user
+// drainPending answers every task still waiting for a worker with
+// shutdownReply and records it with status shutdown, until ctx expires.
+func (d *Dispatcher) drainPending(ctx context.Context) {
+	d.mu.Lock()
+	pending := make([]*pendingTask, 0, len(d.pending))
+	for id, pt := range d.pending {
+		pending = append(pending, pt)
+		delete(d.pending, id)
+	}
+	d.mu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
+	slog.Info("dispatcher: answering pending tasks before shutdown", "pending", len(pending))
+
+	for i, pt := range pending {
+		if ctx.Err() != nil {
+			slog.Warn("dispatcher: shutdown drain timed out", "unanswered", len(pending)-i)
+			return
+		}
+		// Invoke requests ended when the HTTP server shut down
+		d.abandon(ctx, pt, shutdownReply, "shutdown", "service shut down before the worker replied")
+	}
+}
+
+// abandon gives up on a pending task: it sends reply to the sender, unless
+// the task came from the invoke endpoint, records the execution with status
+// and errMsg, and releases the worker's slot.
+func (d *Dispatcher) abandon(ctx context.Context, pt *pendingTask, reply, status, errMsg string) {
+	log := slog.With("request_id", pt.RequestID)
+
+	if !pt.Invoke {
 		outbound := inats.OutboundMessage{
 			ID:        uuid.New().String(),
 			ToJID:     pt.FromJID,
 			FromJID:   pt.AgentJID,
-			Body:      "Sorry, the request timed out. Please try again.",
+			Body:      reply,
 			InReplyTo: pt.RequestID,
+			RoomJID:   pt.RoomJID,
+		}
+		if pt.ChatStates {
+			outbound.ChatState = inats.ChatStatePaused
 		}
 		if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
-			slog.Error("dispatcher: publishing timeout response", "error", err)
+			log.Error("dispatcher: publishing "+status+" response", "error", err)
 		}
+	}
+
+	// Record failed execution
+	storedInput := pt.StorageRedactor.Redact(pt.Input)
+	exec := &Execution{
+		ID:           uuid.New(),
+		RequestID:    pt.RequestID,
+		OwnerUserID:  pt.OwnerUserID,
+		AgentID:      pt.AgentID,
+		Input:        storedInput,
+		Status:       status,
+		ErrorMessage: errMsg,
+		WorkerID:     pt.WorkerID,
+		GoLatencyMs:  int(time.Since(pt.DispatchedAt).Milliseconds()),
+		CreatedAt:    time.Now(),
+		Redacted:     storedInput != pt.Input,
+	}
+	if err := d.repo.RecordExecution(ctx, exec); err != nil {
+		log.Error("dispatcher: recording "+status+" execution", "error", err)
+	} else {
+		d.recordContext(ctx, exec.ID, pt.Context)
+	}
+
+	// Decrement worker active count
+	if w := d.pool.Get(pt.WorkerID); w != nil {
+		w.DecrementActive()
+	}
+}
 
-		// Record failed execution
-		exec := &Execution{
-			ID:           uuid.New(),
-			OwnerUserID:  pt.OwnerUserID,
-			AgentID:      pt.AgentID,
-			Input:        pt.Input,
-			Status:       "timeout",
-			ErrorMessage: "task timed out after " + d.taskTimeout.String(),
-			WorkerID:     pt.WorkerID,
-			GoLatencyMs:  int(time.Since(pt.DispatchedAt).Milliseconds()),
-			CreatedAt:    time.Now(),
+// sendToWorker reserves a slot on a worker that serves provider and model and
+// sends msg to it. A worker whose send fails is released, excluded from
+// selection for SendFailureCooldown, and the next one is tried. It returns a
+// nil worker when none could take msg, with the last send error if any
+// worker was tried.
+func (d *Dispatcher) sendToWorker(provider, model string, msg *pb.ServerMessage) (*ConnectedWorker, error) {
+	var lastErr error
+	for {
+		worker := d.pool.AcquireWorkerForProvider(provider, model)
+		if worker == nil {
+			return nil, lastErr
 		}
-		if err := d.repo.RecordExecution(ctx, exec); err != nil {
-			slog.Error("dispatcher: recording timeout execution", "error", err)
+		err := worker.Send(msg)
+		if err == nil {
+			return worker, nil
 		}
 
-		// Decrement worker active count
-		if w := d.pool.Get(pt.WorkerID); w != nil {
-			w.DecrementActive()
-		}
+		worker.DecrementActive()
+		worker.MarkUnhealthy(time.Now().Add(SendFailureCooldown))
+		slog.Warn("dispatcher: send to worker failed, excluding it", "worker_id", worker.WorkerID, "cooldown", SendFailureCooldown, "error", err)
+		lastErr = fmt.Errorf("sending to worker %s: %w", worker.WorkerID, err)
+	}
+}
+
+// retryOrDeadLetter naks msg for redelivery, or, once it has been delivered
+// maxDeliver times, moves the task to the dead-letter stream and terminates
+// the original. A nil task (undecodable payload) is terminated without a
+// dead letter since it cannot be attributed to an owner.
+func (d *Dispatcher) retryOrDeadLetter(ctx context.Context, msg jetstream.Msg, task *inats.TaskMessage, reason string) {
+	if d.maxDeliver <= 0 {
+		_ = d.consumerMgr.Nak(msg)
+		return
+	}
+
+	meta, err := msg.Metadata()
+	if err != nil {
+		slog.Error("dispatcher: reading task metadata", "error", err)
+		_ = d.consumerMgr.Nak(msg)
+		return
+	}
+	if meta.NumDelivered < uint64(d.maxDeliver) {
+		// Back off so the delivery budget is spread over time instead of
+		// being spent in a tight redelivery loop.
+		_ = msg.NakWithDelay(d.consumerMgr.Policy().NakDelay(meta.NumDelivered))
+		return
+	}
+
+	if task == nil {
+		slog.Error("dispatcher: dropping undecodable task", "deliveries", meta.NumDelivered, "reason", reason)
+		_ = msg.TermWithReason(reason)
+		return
+	}
+
+	dl := inats.DeadLetter{
+		Task:       *task,
+		Reason:     reason,
+		Deliveries: meta.NumDelivered,
+		FailedAt:   time.Now().UTC(),
+	}
+	if err := d.publisher.PublishDeadLetter(ctx, dl); err != nil {
+		// Keep the task in the work queue rather than lose it.
+		slog.Error("dispatcher: publishing dead letter", "error", err, "request_id", task.RequestID)
+		_ = msg.NakWithDelay(d.consumerMgr.Policy().NakDelay(meta.NumDelivered))
+		return
+	}
+	_ = msg.TermWithReason(reason)
+
+	slog.Warn("dispatcher: task dead-lettered",
+		"request_id", task.RequestID,
+		"agent_id", task.AgentID,
+		"deliveries", meta.NumDelivered,
+		"reason", reason,
+	)
+	metrics.TasksDeadLetteredTotal.Inc()
+
+	d.sendErrorResponse(ctx, *task, "Task could not be processed, please try again later")
+
+	audit := inats.AuditEvent{
+		OwnerUserID:  task.OwnerUserID,
+		EventType:    "task_dead_lettered",
+		Severity:     "warn",
+		ResourceType: "agent",
+		ResourceID:   task.AgentID.String(),
+		Details:      fmt.Sprintf("Task %s dead-lettered after %d deliveries: %s", task.RequestID, meta.NumDelivered, reason),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := d.publisher.PublishAuditEvent(ctx, audit); err != nil {
+		slog.Error("dispatcher: publishing audit event", "error", err)
 	}
 }
 
 func (d *Dispatcher) sendErrorResponse(ctx context.Context, task inats.TaskMessage, errMsg string) {
+	if task.Invoke {
+		d.notifyInvocation(task.RequestID, InvokeResult{Error: errMsg})
+		return
+	}
 	outbound := inats.OutboundMessage{
-		ID:        uuid.New().String(),
-		ToJID:     task.FromJID,
-		FromJID:   task.AgentJID,
-		Body:      "Error: " + errMsg,
-		InReplyTo: task.RequestID,
+		ID:          uuid.New().String(),
+		ToJID:       task.FromJID,
+		FromJID:     task.AgentJID,
+		Body:        "Error: " + errMsg,
+		InReplyTo:   task.RequestID,
+		TraceParent: tracing.TraceParent(ctx),
+		RoomJID:     task.RoomJID,
 	}
 	if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
-		slog.Error("dispatcher: publishing error response", "error", err)
+		slog.Error("dispatcher: publishing error response", "error", err, "request_id", task.RequestID)
 	}
 }
 
-// extractProvider parses the provider field from the LLM config JSON.
-func extractProvider(llmConfig json.RawMessage) string {
-	if len(llmConfig) == 0 {
-		return ""
+// rejectOutsideHours answers a task that arrived outside the agent's allowed
+// hours with the schedule's message and records an audit event.
+func (d *Dispatcher) rejectOutsideHours(ctx context.Context, task inats.TaskMessage, hours *policy.HoursWindow) {
+	reply := hours.OutsideMessage()
+	if task.Invoke {
+		d.notifyInvocation(task.RequestID, InvokeResult{Error: reply})
+	} else {
+		outbound := inats.OutboundMessage{
+			ID:          uuid.New().String(),
+			ToJID:       task.FromJID,
+			FromJID:     task.AgentJID,
+			Body:        reply,
+			InReplyTo:   task.RequestID,
+			TraceParent: tracing.TraceParent(ctx),
+			RoomJID:     task.RoomJID,
+		}
+		if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
+			slog.Error("dispatcher: publishing outside hours reply", "error", err, "request_id", task.RequestID)
+		}
 	}
-	var cfg struct {
-		Provider string `json:"provider"`
+
+	audit := inats.AuditEvent{
+		OwnerUserID:  task.OwnerUserID,
+		EventType:    "message_outside_hours",
+		Severity:     "info",
+		ResourceType: "agent",
+		ResourceID:   task.AgentID.String(),
+		Details:      "Task " + task.RequestID + " from " + task.FromJID + " arrived outside allowed hours",
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := d.publisher.PublishAuditEvent(ctx, audit); err != nil {
+		slog.Error("dispatcher: publishing audit event", "error", err)
+	}
+}
+
+// redactorsFor returns the redactors for the task's stored content and reply.
+// A task an admin asked to run unredacted gets redaction.None for both, and
+// the bypass is audited on the agent owner's log.
+func (d *Dispatcher) redactorsFor(ctx context.Context, log *slog.Logger, task inats.TaskMessage, p policy.RedactionPolicy) (storage, outbound redaction.Redactor) {
+	if task.UnredactedBy == "" {
+		return d.redactor.ForAgent(p)
+	}
+	log.Warn("dispatcher: running task unredacted", "request_id", task.RequestID, "agent_id", task.AgentID, "admin_user_id", task.UnredactedBy)
+	d.auditTask(ctx, task, "redaction_bypassed", "warn", "stored and returned without redaction at the request of admin "+task.UnredactedBy)
+	return redaction.None, redaction.None
+}
+
+// auditTask records an event about a task on its agent's audit log.
+func (d *Dispatcher) auditTask(ctx context.Context, task inats.TaskMessage, eventType, severity, details string) {
+	audit := inats.AuditEvent{
+		OwnerUserID:  task.OwnerUserID,
+		EventType:    eventType,
+		Severity:     severity,
+		ResourceType: "agent",
+		ResourceID:   task.AgentID.String(),
+		Details:      "Task " + task.RequestID + ": " + details,
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := d.publisher.PublishAuditEvent(ctx, audit); err != nil {
+		slog.Error("dispatcher: publishing audit event", "error", err)
+	}
+}
+
+// sendChatState notifies the task's sender of the agent's chat state.
+func (d *Dispatcher) sendChatState(ctx context.Context, task inats.TaskMessage, state string) {
+	outbound := inats.OutboundMessage{
+		ID:          uuid.New().String(),
+		ToJID:       task.FromJID,
+		FromJID:     task.AgentJID,
+		ChatState:   state,
+		TraceParent: tracing.TraceParent(ctx),
+	}
+	if err := d.publisher.PublishOutboundMessage(ctx, outbound); err != nil {
+		slog.Warn("dispatcher: publishing chat state", "error", err, "request_id", task.RequestID)
 	}
-	if err := json.Unmarshal(llmConfig, &cfg); err != nil {
-		return ""
+}
+
+// checkStructured validates a worker's structured reply against the agent's
+// response schema.
+func checkStructured(schema *jsonschema.Schema, doc string) error {
+	if doc == "" {
+		return errors.New("worker returned no structured output")
 	}
-	return cfg.Provider
+	return schema.Validate([]byte(doc))
+}
+
+// extractProvider parses the provider field from the LLM config JSON.
+func extractProvider(llmConfig json.RawMessage) string {
+	return agents.ParseLLMConfig(llmConfig).Provider
+}
+
+// extractModel parses the model field from the LLM config JSON.
+func extractModel(llmConfig json.RawMessage) string {
+	return agents.ParseLLMConfig(llmConfig).Model
 }
 
 // providerAllowed checks if a provider is in the allowed list (case-insensitive).
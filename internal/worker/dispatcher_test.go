@@ -0,0 +1,420 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/config"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+	"github.com/aiox-platform/aiox/internal/governance/redaction"
+	"github.com/aiox-platform/aiox/internal/jsonschema"
+	"github.com/aiox-platform/aiox/internal/memory"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
+)
+
+// brokenStream fails every send, like a worker whose connection half-died.
+type brokenStream struct {
+	grpc.BidiStreamingServer[pb.WorkerMessage, pb.ServerMessage]
+	sends int
+}
+
+func (s *brokenStream) Send(*pb.ServerMessage) error {
+	s.sends++
+	return errors.New("transport is closing")
+}
+
+func TestDispatcher_SendToWorker_FailsOver(t *testing.T) {
+	broken := &brokenStream{}
+	healthy := &captureStream{sent: make(chan *pb.ServerMessage, 4)}
+	pool := NewPool()
+	// The broken worker is idle and sorts first, so it is always picked first.
+	bad := &ConnectedWorker{WorkerID: "a-broken", MaxConcurrent: 4, Stream: broken}
+	good := &ConnectedWorker{WorkerID: "b-healthy", MaxConcurrent: 4, ActiveTasks: 2, Stream: healthy}
+	pool.Register(bad)
+	pool.Register(good)
+	d := NewDispatcher(pool, nil, nil, nil, nil, nil, nil, nil, nil, 1)
+
+	w, err := d.sendToWorker("", "", &pb.ServerMessage{})
+	require.NoError(t, err)
+	assert.Equal(t, good, w)
+	assert.Len(t, healthy.sent, 1)
+	assert.Equal(t, 1, broken.sends)
+	assert.Equal(t, int32(0), bad.ActiveTasks, "the failed send's slot is released")
+	assert.False(t, bad.Healthy(time.Now()))
+
+	// Excluded for the cooldown: the next task skips it.
+	_, err = d.sendToWorker("", "", &pb.ServerMessage{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, broken.sends)
+	assert.True(t, bad.Healthy(time.Now().Add(SendFailureCooldown)))
+
+	infos := pool.List()
+	require.NotNil(t, infos[0].UnhealthyUntil)
+	assert.Nil(t, infos[1].UnhealthyUntil)
+}
+
+func TestDispatcher_SendToWorker_NoneHealthy(t *testing.T) {
+	broken := &brokenStream{}
+	pool := NewPool()
+	pool.Register(&ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4, Stream: broken})
+	d := NewDispatcher(pool, nil, nil, nil, nil, nil, nil, nil, nil, 1)
+
+	w, err := d.sendToWorker("", "", &pb.ServerMessage{})
+	assert.Nil(t, w)
+	assert.ErrorContains(t, err, "transport is closing")
+
+	// Once excluded there is simply no worker, and nothing is sent.
+	w, err = d.sendToWorker("", "", &pb.ServerMessage{})
+	assert.Nil(t, w)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, broken.sends)
+}
+
+func TestDispatcher_TimeoutFor(t *testing.T) {
+	d := NewDispatcher(NewPool(), nil, nil, nil, nil, nil, nil, nil, nil, 120)
+
+	assert.Equal(t, 120*time.Second, d.timeoutFor(nil))
+	assert.Equal(t, 120*time.Second, d.timeoutFor([]byte(`{"enabled_tools":["web_search"]}`)))
+	assert.Equal(t, 300*time.Second, d.timeoutFor([]byte(`{"task_timeout_sec":300}`)))
+	assert.Equal(t, 30*time.Second, d.timeoutFor([]byte(`{"task_timeout_sec":30}`)), "shorter than the default")
+
+	d.SetMaxTaskTimeout(200 * time.Second)
+	assert.Equal(t, 200*time.Second, d.timeoutFor([]byte(`{"task_timeout_sec":300}`)))
+
+	// A reloaded default still applies to agents without an override.
+	d.SetTaskTimeout(60 * time.Second)
+	assert.Equal(t, 60*time.Second, d.timeoutFor(nil))
+}
+
+func TestDispatcher_EffectiveLLMConfig(t *testing.T) {
+	d := NewDispatcher(NewPool(), nil, nil, nil, nil, nil, nil, nil, nil, 120)
+	agent := &agents.Agent{LLMConfig: []byte(`{"model":"gpt-4o","max_tokens":8000}`)}
+
+	cfg, clamps, err := d.effectiveLLMConfig(agent, policy.GovernancePolicy{})
+	require.NoError(t, err)
+	assert.Empty(t, clamps, "no caps by default")
+	assert.JSONEq(t, `{"model":"gpt-4o","max_tokens":8000}`, string(cfg))
+
+	d.SetLLMCaps(agents.LLMCaps{MaxTokens: 4096, AllowedModels: []string{"gpt-4o-mini"}})
+	_, _, err = d.effectiveLLMConfig(agent, policy.GovernancePolicy{})
+	assert.ErrorAs(t, err, new(*agents.ModelNotAllowedError))
+
+	// An admin override replaces the global caps for this agent only.
+	agent.LLMCaps = &agents.LLMCaps{MaxTokens: 6000, AllowedModels: []string{"gpt-4o"}}
+	cfg, clamps, err = d.effectiveLLMConfig(agent, policy.GovernancePolicy{})
+	require.NoError(t, err)
+	require.Len(t, clamps, 1)
+	assert.JSONEq(t, `{"model":"gpt-4o","max_tokens":6000}`, string(cfg))
+
+	cfg, _, err = d.effectiveLLMConfig(agent, policy.GovernancePolicy{MaxTokensPerRequest: 1000})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"model":"gpt-4o","max_tokens":1000}`, string(cfg), "the owner's governance can only tighten")
+}
+
+func TestDispatcher_CaptureContext(t *testing.T) {
+	d := NewDispatcher(NewPool(), nil, nil, nil, nil, nil, nil, nil, nil, 120)
+	req := &pb.TaskRequest{
+		UserMessage:       "hi",
+		SystemPrompt:      "be brief",
+		LlmConfigJson:     `{"model":"gpt-4o"}`,
+		MemoryContextJson: `{"history":[]}`,
+	}
+
+	assert.Nil(t, d.captureContext(&agents.Agent{}, true, req), "capture is off by default")
+
+	d.SetDebugContext(true)
+	assert.Nil(t, d.captureContext(&agents.Agent{}, false, req), "only requested tasks are captured")
+
+	c := d.captureContext(&agents.Agent{}, true, req)
+	require.NotNil(t, c)
+	assert.Equal(t, "hi", c.task.UserMessage)
+	assert.JSONEq(t, `{"model":"gpt-4o"}`, string(c.task.LLMConfig))
+	assert.Nil(t, c.task.Tools, "fields the task left empty are omitted")
+}
+
+func TestDispatcher_RedactorsFor(t *testing.T) {
+	d, js := newInvokeDispatcher(t)
+	engine, err := redaction.NewEngine(config.RedactionConfig{Patterns: []string{"email"}, RedactOutbound: true})
+	require.NoError(t, err)
+	d.redactor = engine
+	ctx := context.Background()
+	task := inats.TaskMessage{RequestID: "req-1", AgentID: uuid.New(), OwnerUserID: uuid.New()}
+
+	storage, outbound := d.redactorsFor(ctx, slog.Default(), task, policy.RedactionPolicy{})
+	assert.Equal(t, "[REDACTED:email]", storage.Redact("bob@example.org"))
+	assert.Equal(t, "[REDACTED:email]", outbound.Redact("bob@example.org"))
+	assert.Empty(t, js.audits)
+
+	task.UnredactedBy = "admin-1"
+	storage, outbound = d.redactorsFor(ctx, slog.Default(), task, policy.RedactionPolicy{})
+	assert.Equal(t, "bob@example.org", storage.Redact("bob@example.org"))
+	assert.Equal(t, "bob@example.org", outbound.Redact("bob@example.org"))
+	require.Len(t, js.audits, 1)
+	audit := <-js.audits
+	assert.Equal(t, "redaction_bypassed", audit.EventType)
+	assert.Equal(t, task.OwnerUserID, audit.OwnerUserID)
+	assert.Contains(t, audit.Details, "admin-1")
+}
+
+func TestCheckStructured(t *testing.T) {
+	schema, err := jsonschema.Compile([]byte(`{"type":"object","required":["answer"],"properties":{"answer":{"type":"string"}}}`))
+	require.NoError(t, err)
+
+	assert.NoError(t, checkStructured(schema, `{"answer":"42"}`))
+	assert.EqualError(t, checkStructured(schema, ""), "worker returned no structured output")
+	assert.EqualError(t, checkStructured(schema, `{"answer":42}`), "$.answer: expected string, got number")
+	assert.Error(t, checkStructured(schema, `The answer is 42.`))
+}
+
+// outboundJS records outbound messages published through a Publisher.
+type outboundJS struct {
+	jetstream.JetStream
+	mu       sync.Mutex
+	outbound []inats.OutboundMessage
+}
+
+func (js *outboundJS) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if msg.Subject != inats.SubjectOutboundMessage {
+		return &jetstream.PubAck{}, nil
+	}
+	var out inats.OutboundMessage
+	if err := inats.Decode(msg.Header, msg.Data, &out); err != nil {
+		return nil, err
+	}
+	js.mu.Lock()
+	js.outbound = append(js.outbound, out)
+	js.mu.Unlock()
+	return &jetstream.PubAck{}, nil
+}
+
+// execRecorder keeps recorded executions, calling onRecord after each.
+type execRecorder struct {
+	execs    []*Execution
+	onRecord func()
+}
+
+func (r *execRecorder) RecordExecution(_ context.Context, exec *Execution) error {
+	r.execs = append(r.execs, exec)
+	if r.onRecord != nil {
+		r.onRecord()
+	}
+	return nil
+}
+
+func (r *execRecorder) RecordContext(context.Context, *ExecutionContext) error { return nil }
+
+func newDrainDispatcher(t *testing.T) (*Dispatcher, *outboundJS, *execRecorder) {
+	t.Helper()
+	js := &outboundJS{}
+	rec := &execRecorder{}
+	d := NewDispatcher(NewPool(), inats.NewPublisher(js), nil, nil, nil, nil, nil, nil, nil, 1)
+	d.repo = rec
+	return d, js, rec
+}
+
+func addPending(d *Dispatcher, w *ConnectedWorker, pt *pendingTask) {
+	pt.AgentID = uuid.New()
+	pt.OwnerUserID = uuid.New()
+	pt.FromJID = "alice@aiox.local"
+	pt.AgentJID = "agent@agents.aiox.local"
+	pt.WorkerID = w.WorkerID
+	pt.DispatchedAt = time.Now()
+	if pt.StorageRedactor == nil {
+		pt.StorageRedactor = redaction.None
+	}
+	w.ActiveTasks++
+	d.pending[pt.RequestID] = pt
+}
+
+func TestDispatcher_DrainPending(t *testing.T) {
+	d, js, rec := newDrainDispatcher(t)
+	w := &ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4}
+	d.pool.Register(w)
+	email, err := redaction.Compile([]string{"email"})
+	require.NoError(t, err)
+
+	addPending(d, w, &pendingTask{RequestID: "chat", Input: "mail bob@example.org", StorageRedactor: email, ChatStates: true})
+	addPending(d, w, &pendingTask{RequestID: "room", RoomJID: "room@conference.aiox.local"})
+	addPending(d, w, &pendingTask{RequestID: "invoke", Invoke: true})
+
+	d.drainPending(context.Background())
+
+	assert.Empty(t, d.pending)
+	assert.Equal(t, int32(0), w.ActiveTasks, "every worker slot is released")
+
+	// Each sender is told to retry; the invoke caller's request has already ended.
+	replies := make(map[string]inats.OutboundMessage)
+	for _, out := range js.outbound {
+		assert.Equal(t, shutdownReply, out.Body)
+		replies[out.InReplyTo] = out
+	}
+	require.Len(t, replies, 2)
+	assert.Equal(t, inats.ChatStatePaused, replies["chat"].ChatState)
+	assert.Equal(t, "alice@aiox.local", replies["chat"].ToJID)
+	assert.Equal(t, "room@conference.aiox.local", replies["room"].RoomJID)
+
+	recorded := make(map[string]*Execution)
+	for _, exec := range rec.execs {
+		assert.Equal(t, "shutdown", exec.Status)
+		assert.Equal(t, "service shut down before the worker replied", exec.ErrorMessage)
+		recorded[exec.RequestID] = exec
+	}
+	require.Len(t, recorded, 3, "invoke tasks are recorded too")
+	assert.Equal(t, "mail [REDACTED:email]", recorded["chat"].Input)
+	assert.True(t, recorded["chat"].Redacted)
+}
+
+func TestDispatcher_DrainPendingDeadline(t *testing.T) {
+	d, js, rec := newDrainDispatcher(t)
+	w := &ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4}
+	d.pool.Register(w)
+	for _, id := range []string{"a", "b", "c"} {
+		addPending(d, w, &pendingTask{RequestID: id})
+	}
+
+	// The drain deadline passes while the first task is being answered.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rec.onRecord = cancel
+
+	d.drainPending(ctx)
+
+	assert.Len(t, js.outbound, 1)
+	assert.Len(t, rec.execs, 1)
+	assert.Empty(t, d.pending, "tasks left at the deadline are abandoned, not kept for a reply")
+}
+
+// heldMsg is a task delivery that records how it was acknowledged.
+type heldMsg struct {
+	jetstream.Msg
+	data       []byte
+	delivered  uint64
+	inProgress atomic.Int32
+	naks       atomic.Int32
+	terms      atomic.Int32
+}
+
+func (m *heldMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{NumDelivered: m.delivered}, nil
+}
+func (m *heldMsg) Headers() nats.Header             { return nil }
+func (m *heldMsg) Data() []byte                     { return m.data }
+func (m *heldMsg) InProgress() error                { m.inProgress.Add(1); return nil }
+func (m *heldMsg) Nak() error                       { m.naks.Add(1); return nil }
+func (m *heldMsg) NakWithDelay(time.Duration) error { m.naks.Add(1); return nil }
+func (m *heldMsg) TermWithReason(string) error      { m.terms.Add(1); return nil }
+
+func newHoldDispatcher(t *testing.T, dbErr *error) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(NewPool(), inats.NewPublisher(&outboundJS{}), inats.NewConsumerManager(nil), nil, nil, nil, nil, nil, nil, 1)
+	gate := NewHealthGate(func(context.Context) error { return *dbErr }, nil)
+	gate.probe(context.Background())
+	d.SetHealthGate(gate)
+	d.holdInterval = time.Millisecond
+	return d
+}
+
+func TestDispatcher_HoldOutlastsMaxDeliver(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	d := newHoldDispatcher(t, &dbErr)
+	d.SetMaxDeliveries(3)
+	msg := &heldMsg{delivered: 1}
+	task := &inats.TaskMessage{RequestID: "req-1", AgentID: uuid.New(), OwnerUserID: uuid.New()}
+
+	resumed := make(chan struct{})
+	go d.hold(context.Background(), msg, DependencyDatabase, func() {
+		// The task fails once dispatched: with the hold not counted, it is
+		// retried rather than dead-lettered.
+		d.retryOrDeadLetter(context.Background(), msg, task, "fetching agent failed")
+		close(resumed)
+	})
+
+	// Held for many times the delivery budget without being redelivered.
+	require.Eventually(t, func() bool { return msg.inProgress.Load() > 10 }, time.Second, time.Millisecond)
+	assert.Zero(t, msg.naks.Load())
+
+	dbErr = nil
+	d.gate.probe(context.Background())
+	select {
+	case <-resumed:
+	case <-time.After(time.Second):
+		t.Fatal("task not resumed after the database recovered")
+	}
+	assert.Equal(t, int32(1), msg.naks.Load(), "retried with backoff")
+	assert.Zero(t, msg.terms.Load(), "not dead-lettered")
+}
+
+func TestDispatcher_HoldReleasedOnShutdown(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	d := newHoldDispatcher(t, &dbErr)
+	msg := &heldMsg{delivered: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.True(t, d.holdForHealth(ctx, slog.Default(), msg, DependencyDatabase))
+	require.Eventually(t, func() bool { return msg.inProgress.Load() > 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	d.held.Wait()
+	assert.Equal(t, int32(1), msg.naks.Load(), "handed back for another instance")
+
+	d.gate.SetFailOpen(true)
+	assert.False(t, d.holdForHealth(context.Background(), slog.Default(), msg, DependencyDatabase))
+}
+
+// agentRepo serves a single agent.
+type agentRepo struct {
+	agents.Repository
+	row *agents.AgentRow
+}
+
+func (r *agentRepo) GetByID(context.Context, uuid.UUID) (*agents.AgentRow, error) {
+	return r.row, nil
+}
+
+func TestDispatcher_MemoryHoldPrecedesSideEffects(t *testing.T) {
+	d, js := newInvokeDispatcher(t)
+	row := &agents.AgentRow{
+		ID:           uuid.New(),
+		OwnerUserID:  uuid.New(),
+		Profile:      []byte(`{}`),
+		LLMConfig:    []byte(`{"model":"gpt-4o","max_tokens":8000}`),
+		MemoryConfig: []byte(`{"enabled":true}`),
+		Status:       agents.StatusActive,
+	}
+	d.agentSvc = agents.NewService(&agentRepo{row: row}, nil, nil, "aiox.local")
+	d.memorySvc = memory.NewService(nil, nil)
+	// Handling the task would clamp its LLM config and audit that.
+	d.SetLLMCaps(agents.LLMCaps{MaxTokens: 4096})
+	gate := NewHealthGate(nil, func(context.Context) error { return errors.New("connection refused") })
+	gate.probe(context.Background())
+	d.SetHealthGate(gate)
+	d.holdInterval = time.Millisecond
+
+	data, err := json.Marshal(inats.TaskMessage{RequestID: "req-1", AgentID: row.ID, OwnerUserID: row.OwnerUserID, Message: "hi"})
+	require.NoError(t, err)
+	msg := &heldMsg{data: data, delivered: 1}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.handleTask(ctx, msg)
+	require.Eventually(t, func() bool { return msg.inProgress.Load() > 0 }, time.Second, time.Millisecond)
+	assert.Empty(t, js.audits, "nothing is audited before the hold")
+
+	cancel()
+	d.held.Wait()
+	assert.Equal(t, int32(1), msg.naks.Load())
+}
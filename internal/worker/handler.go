@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/api"
+)
+
+// PoolHandler exposes the connected workers to operators.
+type PoolHandler struct {
+	pool *Pool
+}
+
+// NewPoolHandler creates a new PoolHandler.
+func NewPoolHandler(pool *Pool) *PoolHandler {
+	return &PoolHandler{pool: pool}
+}
+
+// List returns the connected workers with their advertised providers, models,
+// and context size, and their current load.
+func (h *PoolHandler) List(w http.ResponseWriter, r *http.Request) {
+	api.JSON(w, http.StatusOK, h.pool.List())
+}
+
+// ExecutionHandler exposes an agent's execution history.
+type ExecutionHandler struct {
+	repo     *Repository
+	agentSvc *agents.Service
+}
+
+// NewExecutionHandler creates a new ExecutionHandler. agentSvc decrypts the
+// system prompts of captured contexts.
+func NewExecutionHandler(repo *Repository, agentSvc *agents.Service) *ExecutionHandler {
+	return &ExecutionHandler{repo: repo, agentSvc: agentSvc}
+}
+
+// List returns the agent's executions, newest first, with input and output
+// truncated. Accepts ?status=completed|error|timeout|shutdown|cached, ?from= and ?to=
+// (RFC 3339), ?page= and ?page_size=. Expects the agent to be set in context
+// by the OwnershipMiddleware.
+func (h *ExecutionHandler) List(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	params, err := parseExecutionParams(r)
+	if err != nil {
+		api.HandleError(w, err)
+		return
+	}
+
+	executions, total, err := h.repo.ListByAgent(r.Context(), agent.ID, params)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "listing executions", "error", err, "agent_id", agent.ID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSONPaginated(w, http.StatusOK, executions, total, params.Page, params.PageSize)
+}
+
+// Get returns a single execution with its full input and output.
+// Expects the agent to be set in context by the OwnershipMiddleware.
+func (h *ExecutionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	execID, err := uuid.Parse(chi.URLParam(r, "execID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid execution ID"))
+		return
+	}
+
+	exec, err := h.repo.GetExecution(r.Context(), agent.ID, execID)
+	if err != nil {
+		if errors.Is(err, ErrExecutionNotFound) {
+			api.HandleError(w, api.NewNotFoundError(err.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "getting execution", "error", err, "execution_id", execID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+
+	api.JSON(w, http.StatusOK, exec)
+}
+
+// Context returns what was sent to the worker for an execution run with
+// debug context capture, unredacted: the system prompt, user message, memory
+// context, and LLM config. Expects the agent to be set in context by the
+// OwnershipMiddleware, so only the agent's owner can read it.
+func (h *ExecutionHandler) Context(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+
+	execID, err := uuid.Parse(chi.URLParam(r, "execID"))
+	if err != nil {
+		api.HandleError(w, api.NewBadRequestError("invalid execution ID"))
+		return
+	}
+
+	ec, err := h.repo.GetContext(r.Context(), agent.ID, execID)
+	if err != nil {
+		if errors.Is(err, ErrContextNotFound) {
+			api.HandleError(w, api.NewNotFoundError(err.Error()))
+			return
+		}
+		slog.ErrorContext(r.Context(), "getting execution context", "error", err, "execution_id", execID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if ec.SystemPromptID != nil {
+		if ec.SystemPrompt, _, err = h.agentSvc.LoadPrompt(r.Context(), *ec.SystemPromptID); err != nil {
+			slog.ErrorContext(r.Context(), "loading execution context prompt", "error", err, "execution_id", execID)
+			api.HandleError(w, api.ErrInternalServer)
+			return
+		}
+	}
+
+	api.JSON(w, http.StatusOK, ec)
+}
+
+func parseExecutionParams(r *http.Request) (ExecutionListParams, error) {
+	params := DefaultExecutionListParams()
+	q := r.URL.Query()
+
+	switch status := q.Get("status"); status {
+	case "":
+	case "completed", "error", "timeout", "shutdown", "cached":
+		params.Status = status
+	default:
+		return params, api.NewBadRequestError("status must be one of completed, error, timeout, shutdown, cached")
+	}
+
+	if p := q.Get("page"); p != "" {
+		if page, err := strconv.Atoi(p); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if ps := q.Get("page_size"); ps != "" {
+		if pageSize, err := strconv.Atoi(ps); err == nil && pageSize > 0 && pageSize <= 100 {
+			params.PageSize = pageSize
+		}
+	}
+	if from := q.Get("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return params, api.NewBadRequestError("from must be an RFC 3339 timestamp")
+		}
+		params.From = &t
+	}
+	if to := q.Get("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return params, api.NewBadRequestError("to must be an RFC 3339 timestamp")
+		}
+		params.To = &t
+	}
+
+	return params, nil
+}
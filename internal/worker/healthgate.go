@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// healthGateInterval is how often the gate re-checks its dependencies.
+	healthGateInterval = 5 * time.Second
+	// healthGateTimeout bounds each dependency check.
+	healthGateTimeout = 2 * time.Second
+)
+
+// Dependencies a HealthGate checks. The memory store is Redis, which holds
+// short-term conversation memory.
+const (
+	DependencyDatabase = "database"
+	DependencyMemory   = "memory"
+)
+
+// HealthGate tracks whether the dependencies a task needs are up, so the
+// dispatcher can hold tasks instead of spending tokens on results it cannot
+// record. Checks run in the background; the dispatch path only reads their
+// last outcome. A nil *HealthGate reports everything healthy.
+type HealthGate struct {
+	database func(ctx context.Context) error
+	memory   func(ctx context.Context) error
+
+	databaseDown atomic.Bool
+	memoryDown   atomic.Bool
+	failOpen     atomic.Bool
+}
+
+// NewHealthGate creates a gate checking Postgres with database and the
+// short-term memory store with memory; either may be nil to skip it.
+func NewHealthGate(database, memory func(ctx context.Context) error) *HealthGate {
+	return &HealthGate{database: database, memory: memory}
+}
+
+// SetFailOpen makes the gate let tasks through while a dependency is down,
+// for best-effort delivery. It can be changed at runtime.
+func (g *HealthGate) SetFailOpen(failOpen bool) {
+	g.failOpen.Store(failOpen)
+}
+
+// Run checks the dependencies every healthGateInterval until ctx is done.
+func (g *HealthGate) Run(ctx context.Context) {
+	g.probe(ctx)
+
+	ticker := time.NewTicker(healthGateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.probe(ctx)
+		}
+	}
+}
+
+func (g *HealthGate) probe(ctx context.Context) {
+	g.check(ctx, DependencyDatabase, g.database, &g.databaseDown)
+	g.check(ctx, DependencyMemory, g.memory, &g.memoryDown)
+}
+
+func (g *HealthGate) check(ctx context.Context, name string, check func(context.Context) error, down *atomic.Bool) {
+	if check == nil {
+		return
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, healthGateTimeout)
+	defer cancel()
+
+	err := check(checkCtx)
+	if ctx.Err() != nil {
+		// Shutting down; the failure says nothing about the dependency.
+		return
+	}
+	wasDown := down.Swap(err != nil)
+	switch {
+	case err != nil && !wasDown:
+		slog.Warn("dispatcher: dependency unhealthy", "dependency", name, "error", err)
+	case err == nil && wasDown:
+		slog.Info("dispatcher: dependency recovered", "dependency", name)
+	}
+}
+
+// Check reports whether dependency was down at the last check and, if so,
+// whether tasks needing it should be held.
+func (g *HealthGate) Check(dependency string) (down, hold bool) {
+	if g == nil {
+		return false, false
+	}
+	switch dependency {
+	case DependencyDatabase:
+		down = g.databaseDown.Load()
+	case DependencyMemory:
+		down = g.memoryDown.Load()
+	}
+	return down, down && !g.failOpen.Load()
+}
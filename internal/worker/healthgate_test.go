@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthGate(t *testing.T) {
+	var dbErr, redisErr error
+	g := NewHealthGate(
+		func(context.Context) error { return dbErr },
+		func(context.Context) error { return redisErr },
+	)
+	ctx := context.Background()
+
+	g.probe(ctx)
+	down, hold := g.Check(DependencyDatabase)
+	assert.False(t, down)
+	assert.False(t, hold)
+
+	redisErr = errors.New("connection refused")
+	g.probe(ctx)
+	down, hold = g.Check(DependencyMemory)
+	assert.True(t, down)
+	assert.True(t, hold, "the gate fails closed by default")
+	down, _ = g.Check(DependencyDatabase)
+	assert.False(t, down, "tasks without memory still run")
+
+	g.SetFailOpen(true)
+	down, hold = g.Check(DependencyMemory)
+	assert.True(t, down)
+	assert.False(t, hold)
+
+	redisErr = nil
+	g.probe(ctx)
+	down, _ = g.Check(DependencyMemory)
+	assert.False(t, down, "recovered")
+}
+
+func TestHealthGate_IgnoresFailuresWhileShuttingDown(t *testing.T) {
+	g := NewHealthGate(func(ctx context.Context) error { return ctx.Err() }, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g.probe(ctx)
+	down, _ := g.Check(DependencyDatabase)
+	assert.False(t, down)
+}
+
+func TestHealthGate_Nil(t *testing.T) {
+	var g *HealthGate
+	down, hold := g.Check(DependencyDatabase)
+	assert.False(t, down)
+	assert.False(t, hold)
+}
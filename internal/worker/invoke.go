@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/api"
+	"github.com/aiox-platform/aiox/internal/auth"
+	"github.com/aiox-platform/aiox/internal/governance/policy"
+	"github.com/aiox-platform/aiox/internal/governance/quota"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+	"github.com/aiox-platform/aiox/internal/tracing"
+)
+
+// maxInvokeMessageLen caps the message accepted by the invoke endpoint.
+const maxInvokeMessageLen = 32 * 1024
+
+// UnredactedHeader asks the invoke endpoint to store and return the task's
+// input and output without redaction, for troubleshooting. Only admins may
+// send it, and every use is audited.
+const UnredactedHeader = "X-Debug-Unredacted"
+
+// ErrInvokeTimeout is returned by Invoke when no result arrives within the task timeout.
+var ErrInvokeTimeout = errors.New("timed out waiting for the agent's response")
+
+// InvokeResult is the outcome of a synchronous invocation.
+type InvokeResult struct {
+	RequestID  string `json:"request_id"`
+	Response   string `json:"response"`
+	TokensUsed int    `json:"tokens_used"`
+	Model      string `json:"model,omitempty"`
+	LatencyMs  int    `json:"latency_ms"`
+	FromCache  bool   `json:"from_cache,omitempty"`
+	// Structured is the agent's reply validated against its response_schema.
+	Structured json.RawMessage `json:"structured,omitempty"`
+	// Error is set when the worker or dispatcher failed the task.
+	Error string `json:"-"`
+}
+
+// Invoke publishes task and waits for its result. The wait is bounded by
+// timeout, or the task timeout when zero, and by ctx. The result is delivered
+// by the dispatcher that consumes the task, so the API and the dispatcher must
+// run in the same process, as they do in cmd/api.
+func (d *Dispatcher) Invoke(ctx context.Context, task inats.TaskMessage, timeout time.Duration) (*InvokeResult, error) {
+	task.Invoke = true
+
+	ch := make(chan InvokeResult, 1)
+	d.mu.Lock()
+	d.invocations[task.RequestID] = ch
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.invocations, task.RequestID)
+		d.mu.Unlock()
+	}()
+
+	if err := d.publisher.PublishTask(ctx, task.AgentID.String(), task); err != nil {
+		return nil, fmt.Errorf("publishing invoke task: %w", err)
+	}
+
+	if timeout <= 0 {
+		timeout = d.timeout()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-ch:
+		res.RequestID = task.RequestID
+		return &res, nil
+	case <-timer.C:
+		return nil, ErrInvokeTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// notifyInvocation hands a result to the waiting Invoke call, if any.
+func (d *Dispatcher) notifyInvocation(requestID string, res InvokeResult) {
+	d.mu.Lock()
+	ch, ok := d.invocations[requestID]
+	d.mu.Unlock()
+
+	if !ok {
+		slog.Warn("dispatcher: received result for abandoned invocation", "request_id", requestID)
+		return
+	}
+	select {
+	case ch <- res:
+	default:
+	}
+}
+
+// InvokeRequest is the body of POST /api/v1/agents/{agentID}/invoke.
+type InvokeRequest struct {
+	Message string `json:"message"`
+}
+
+// InvokeHandler runs an agent synchronously over HTTP.
+type InvokeHandler struct {
+	dispatcher *Dispatcher
+	quotaSvc   *quota.Service
+}
+
+// NewInvokeHandler creates a new InvokeHandler.
+func NewInvokeHandler(dispatcher *Dispatcher, quotaSvc *quota.Service) *InvokeHandler {
+	return &InvokeHandler{dispatcher: dispatcher, quotaSvc: quotaSvc}
+}
+
+// Invoke sends a message to the agent and answers with its response, blocking
+// until the worker replies. Quotas are checked as for XMPP messages. Answers
+// 504 when the task timeout passes first and 502 when the task fails.
+// Expects the agent to be set in context by the OwnershipMiddleware.
+func (h *InvokeHandler) Invoke(w http.ResponseWriter, r *http.Request) {
+	agent := agents.GetAgentFromContext(r.Context())
+	if agent == nil {
+		api.HandleError(w, api.ErrAgentNotFound)
+		return
+	}
+	claims := auth.GetUserClaims(r.Context())
+	if claims == nil {
+		api.HandleError(w, api.ErrUnauthorized)
+		return
+	}
+	if agent.Status == agents.StatusPaused {
+		api.HandleError(w, api.NewConflictError("agent is paused"))
+		return
+	}
+
+	var req InvokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		api.HandleError(w, api.ErrBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		api.HandleError(w, api.NewValidationError("message is required"))
+		return
+	}
+	if len(req.Message) > maxInvokeMessageLen {
+		api.HandleError(w, api.NewValidationError(fmt.Sprintf("message exceeds %d bytes", maxInvokeMessageLen)))
+		return
+	}
+
+	unredacted, _ := strconv.ParseBool(r.Header.Get(UnredactedHeader))
+	if unredacted && !claims.IsAdmin {
+		api.HandleError(w, api.NewForbiddenError(UnredactedHeader+" requires admin access"))
+		return
+	}
+
+	ctx := r.Context()
+	if h.quotaSvc != nil {
+		err := h.quotaSvc.CheckQuota(ctx, agent.OwnerUserID)
+		if err == nil {
+			limits := policy.Parse(agent.Governance).Quota.Limits()
+			err = h.quotaSvc.CheckAgentQuota(ctx, agent.ID, agent.OwnerUserID, limits)
+		}
+		if err != nil {
+			var limitErr *quota.LimitError
+			if errors.As(err, &limitErr) {
+				api.HandleError(w, api.NewQuotaExceededError(limitErr.Error()))
+				return
+			}
+			slog.ErrorContext(r.Context(), "checking quota for invoke", "error", err, "agent_id", agent.ID)
+			api.HandleError(w, api.ErrInternalServer)
+			return
+		}
+	}
+
+	// The wait can outlast the server's write timeout.
+	timeout := h.dispatcher.timeoutFor(agent.Capabilities)
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + 5*time.Second))
+
+	task := inats.TaskMessage{
+		RequestID:   uuid.New().String(),
+		AgentID:     agent.ID,
+		OwnerUserID: agent.OwnerUserID,
+		Message:     req.Message,
+		FromJID:     "invoke:" + claims.UserID,
+		AgentJID:    agent.JID,
+		AgentName:   agent.Profile.Name,
+		TraceParent: tracing.TraceParent(ctx),
+	}
+	task.DebugContext, _ = strconv.ParseBool(r.Header.Get(DebugContextHeader))
+	if unredacted {
+		task.UnredactedBy = claims.UserID
+	}
+	task.Priority = policy.Parse(agent.Governance).TaskPriority(task.FromJID)
+	res, err := h.dispatcher.Invoke(ctx, task, timeout)
+	if err != nil {
+		if errors.Is(err, ErrInvokeTimeout) {
+			api.HandleError(w, api.NewError(api.CodeInvokeTimeout, err.Error()))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		slog.ErrorContext(r.Context(), "invoking agent", "error", err, "agent_id", agent.ID)
+		api.HandleError(w, api.ErrInternalServer)
+		return
+	}
+	if res.Error != "" {
+		api.HandleError(w, api.NewError(api.CodeAgentError, res.Error))
+		return
+	}
+
+	api.JSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/auth"
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// captureJS records tasks and audit events published through a Publisher.
+type captureJS struct {
+	jetstream.JetStream
+	published chan inats.TaskMessage
+	audits    chan inats.AuditEvent
+}
+
+func (js *captureJS) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if strings.HasPrefix(msg.Subject, inats.SubjectAuditEvent) {
+		var event inats.AuditEvent
+		if err := inats.Decode(msg.Header, msg.Data, &event); err != nil {
+			return nil, err
+		}
+		js.audits <- event
+		return &jetstream.PubAck{}, nil
+	}
+	var task inats.TaskMessage
+	if err := inats.Decode(msg.Header, msg.Data, &task); err != nil {
+		return nil, err
+	}
+	js.published <- task
+	return &jetstream.PubAck{}, nil
+}
+
+func newInvokeDispatcher(t *testing.T) (*Dispatcher, *captureJS) {
+	t.Helper()
+	js := &captureJS{published: make(chan inats.TaskMessage, 1), audits: make(chan inats.AuditEvent, 4)}
+	d := NewDispatcher(NewPool(), inats.NewPublisher(js), nil, nil, nil, nil, nil, nil, nil, 1)
+	return d, js
+}
+
+func TestDispatcher_Invoke(t *testing.T) {
+	d, js := newInvokeDispatcher(t)
+
+	go func() {
+		task := <-js.published
+		d.notifyInvocation(task.RequestID, InvokeResult{Response: "hi", TokensUsed: 12, Model: "gpt-4o", LatencyMs: 30})
+	}()
+
+	res, err := d.Invoke(context.Background(), inats.TaskMessage{RequestID: "req-1", AgentID: uuid.New(), Message: "hello"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", res.RequestID)
+	assert.Equal(t, "hi", res.Response)
+	assert.Equal(t, 12, res.TokensUsed)
+	assert.Equal(t, "gpt-4o", res.Model)
+	assert.Empty(t, d.invocations)
+}
+
+func TestDispatcher_InvokeMarksTask(t *testing.T) {
+	d, js := newInvokeDispatcher(t)
+
+	go func() {
+		task := <-js.published
+		assert.True(t, task.Invoke)
+		// Dispatch failures reach the caller instead of an outbound message.
+		d.sendErrorResponse(context.Background(), task, "Agent not found")
+	}()
+
+	res, err := d.Invoke(context.Background(), inats.TaskMessage{RequestID: "req-1", AgentID: uuid.New()}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Agent not found", res.Error)
+}
+
+func TestDispatcher_InvokeTimeout(t *testing.T) {
+	d, _ := newInvokeDispatcher(t)
+	d.SetTaskTimeout(10 * time.Millisecond)
+
+	_, err := d.Invoke(context.Background(), inats.TaskMessage{RequestID: "req-1", AgentID: uuid.New()}, 0)
+	assert.ErrorIs(t, err, ErrInvokeTimeout)
+	assert.Empty(t, d.invocations)
+}
+
+func TestInvokeHandler_UnredactedRequiresAdmin(t *testing.T) {
+	d, js := newInvokeDispatcher(t)
+	h := NewInvokeHandler(d, nil)
+	agent := &agents.Agent{ID: uuid.New(), OwnerUserID: uuid.New(), JID: "agent@agents.example.com"}
+
+	invoke := func(claims *auth.AccessClaims) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"message":"hello"}`))
+		req.Header.Set(UnredactedHeader, "true")
+		ctx := agents.SetAgentInContext(req.Context(), agent)
+		ctx = context.WithValue(ctx, auth.UserClaimsKey, claims)
+		rec := httptest.NewRecorder()
+		h.Invoke(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	rec := invoke(&auth.AccessClaims{UserID: agent.OwnerUserID.String()})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, js.published, "nothing is dispatched")
+
+	go func() {
+		task := <-js.published
+		assert.Equal(t, "admin-1", task.UnredactedBy)
+		d.notifyInvocation(task.RequestID, InvokeResult{Response: "hi"})
+	}()
+	rec = invoke(&auth.AccessClaims{UserID: "admin-1", IsAdmin: true})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -1,22 +1,63 @@
 package worker
 
 import (
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/aiox-platform/aiox/internal/metrics"
 	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
 	"google.golang.org/grpc"
 )
 
+// SendFailureCooldown is how long a worker whose stream failed a send is left
+// out of selection.
+const SendFailureCooldown = 30 * time.Second
+
 // ConnectedWorker represents a Python worker connected via gRPC bidirectional stream.
 type ConnectedWorker struct {
 	WorkerID           string
 	MaxConcurrent      int32
 	SupportedProviders []string
+	// SupportedModels lists the models the worker serves; empty means any
+	// model of its providers.
+	SupportedModels  []string
+	MaxContextTokens int32
+
+	mu            sync.Mutex
+	ActiveTasks   int32
+	LastHeartbeat time.Time
+	Stream        grpc.BidiStreamingServer[pb.WorkerMessage, pb.ServerMessage]
+	// unhealthyUntil excludes the worker from selection after a failed send.
+	unhealthyUntil time.Time
+}
+
+// MarkUnhealthy excludes the worker from selection until t.
+func (w *ConnectedWorker) MarkUnhealthy(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.unhealthyUntil = t
+}
+
+// Healthy reports whether the worker may be selected at now.
+func (w *ConnectedWorker) Healthy(now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !now.Before(w.unhealthyUntil)
+}
 
-	mu          sync.Mutex
-	ActiveTasks int32
-	Stream      grpc.BidiStreamingServer[pb.WorkerMessage, pb.ServerMessage]
+// Touch records a heartbeat at t.
+func (w *ConnectedWorker) Touch(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.LastHeartbeat = t
+}
+
+// HeartbeatAge returns how long ago the worker last heartbeated.
+func (w *ConnectedWorker) HeartbeatAge(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return now.Sub(w.LastHeartbeat)
 }
 
 // Send safely sends a ServerMessage to the worker's stream.
@@ -54,17 +95,26 @@ func (w *ConnectedWorker) LoadFraction() float64 {
 
 // Pool manages connected Python workers.
 type Pool struct {
-	mu      sync.RWMutex
-	workers map[string]*ConnectedWorker
+	mu       sync.RWMutex
+	workers  map[string]*ConnectedWorker
+	selector Selector
 }
 
-// NewPool creates a new worker pool.
+// NewPool creates a new worker pool that selects the least-loaded worker.
 func NewPool() *Pool {
 	return &Pool{
-		workers: make(map[string]*ConnectedWorker),
+		workers:  make(map[string]*ConnectedWorker),
+		selector: LeastLoaded{},
 	}
 }
 
+// SetSelector changes the load-balancing strategy.
+func (p *Pool) SetSelector(s Selector) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.selector = s
+}
+
 // Register adds a worker to the pool. Returns false if a worker with the same ID is already connected.
 func (p *Pool) Register(w *ConnectedWorker) bool {
 	p.mu.Lock()
@@ -85,26 +135,97 @@ func (p *Pool) Unregister(workerID string) {
 	metrics.WorkerPoolConnected.Set(float64(len(p.workers)))
 }
 
-// SelectWorker picks the least-loaded worker that has capacity.
-// Returns nil if no workers are available.
-func (p *Pool) SelectWorker() *ConnectedWorker {
+// Remove unregisters w only if it is still the worker registered under its
+// ID, so a stale stream cannot evict a worker that reconnected with the same ID.
+func (p *Pool) Remove(w *ConnectedWorker) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.workers[w.WorkerID] != w {
+		return false
+	}
+	delete(p.workers, w.WorkerID)
+	metrics.WorkerPoolConnected.Set(float64(len(p.workers)))
+	return true
+}
+
+// Stale returns workers whose last heartbeat is older than maxAge.
+func (p *Pool) Stale(now time.Time, maxAge time.Duration) []*ConnectedWorker {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	var best *ConnectedWorker
-	bestLoad := float64(2.0) // > 1.0 means none found yet
+	var stale []*ConnectedWorker
+	for _, w := range p.workers {
+		if w.HeartbeatAge(now) > maxAge {
+			stale = append(stale, w)
+		}
+	}
+	return stale
+}
+
+// SelectWorkerForProvider uses the pool's Selector to pick a healthy worker
+// with capacity whose SupportedProviders include provider and whose
+// SupportedModels include model (both case-insensitive). An empty provider or
+// model matches any worker, as does a worker that advertises no models.
+// Returns nil if no suitable worker is available.
+func (p *Pool) SelectWorkerForProvider(provider, model string) *ConnectedWorker {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 
+	now := time.Now()
+	var candidates []*ConnectedWorker
 	for _, w := range p.workers {
-		load := w.LoadFraction()
-		if load >= 1.0 {
+		if provider != "" && !providerAllowed(provider, w.SupportedProviders) {
+			continue
+		}
+		if model != "" && len(w.SupportedModels) > 0 && !providerAllowed(model, w.SupportedModels) {
+			continue
+		}
+		if w.LoadFraction() >= 1.0 {
 			continue // fully loaded
 		}
-		if load < bestLoad {
-			bestLoad = load
-			best = w
+		if !w.Healthy(now) {
+			continue // cooling down after a failed send
+		}
+		candidates = append(candidates, w)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].WorkerID < candidates[j].WorkerID })
+	return p.selector.Select(candidates)
+}
+
+// TryAcquire reserves a task slot on w if it is still registered and below
+// MaxConcurrent, and reports whether it did. The check and the increment
+// happen under the pool's write lock, so concurrent dispatches cannot push a
+// worker past its limit. Release the slot with DecrementActive.
+func (p *Pool) TryAcquire(w *ConnectedWorker) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.workers[w.WorkerID] != w {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ActiveTasks >= w.MaxConcurrent {
+		return false
+	}
+	w.ActiveTasks++
+	return true
+}
+
+// AcquireWorkerForProvider selects a worker as SelectWorkerForProvider does
+// and reserves a task slot on it. If a concurrent dispatch takes the last slot
+// first, another worker is selected. Returns nil once no suitable worker has
+// capacity.
+func (p *Pool) AcquireWorkerForProvider(provider, model string) *ConnectedWorker {
+	for {
+		w := p.SelectWorkerForProvider(provider, model)
+		if w == nil || p.TryAcquire(w) {
+			return w
 		}
 	}
-	return best
 }
 
 // ConnectedCount returns the number of connected workers.
@@ -114,6 +235,62 @@ func (p *Pool) ConnectedCount() int {
 	return len(p.workers)
 }
 
+// Capacity returns the total MaxConcurrent and ActiveTasks across connected workers.
+func (p *Pool) Capacity() (capacity, active int) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, w := range p.workers {
+		w.mu.Lock()
+		capacity += int(w.MaxConcurrent)
+		active += int(w.ActiveTasks)
+		w.mu.Unlock()
+	}
+	return capacity, active
+}
+
+// WorkerInfo describes a connected worker's advertised capabilities and current load.
+type WorkerInfo struct {
+	WorkerID           string    `json:"worker_id"`
+	SupportedProviders []string  `json:"supported_providers"`
+	SupportedModels    []string  `json:"supported_models"`
+	MaxContextTokens   int32     `json:"max_context_tokens"`
+	MaxConcurrent      int32     `json:"max_concurrent"`
+	ActiveTasks        int32     `json:"active_tasks"`
+	LastHeartbeat      time.Time `json:"last_heartbeat"`
+	// UnhealthyUntil is set while the worker is excluded after a failed send.
+	UnhealthyUntil *time.Time `json:"unhealthy_until,omitempty"`
+}
+
+// List returns the connected workers ordered by ID.
+func (p *Pool) List() []WorkerInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	now := time.Now()
+	infos := make([]WorkerInfo, 0, len(p.workers))
+	for _, w := range p.workers {
+		w.mu.Lock()
+		var unhealthyUntil *time.Time
+		if now.Before(w.unhealthyUntil) {
+			t := w.unhealthyUntil
+			unhealthyUntil = &t
+		}
+		infos = append(infos, WorkerInfo{
+			WorkerID:           w.WorkerID,
+			SupportedProviders: w.SupportedProviders,
+			SupportedModels:    w.SupportedModels,
+			MaxContextTokens:   w.MaxContextTokens,
+			MaxConcurrent:      w.MaxConcurrent,
+			ActiveTasks:        w.ActiveTasks,
+			LastHeartbeat:      w.LastHeartbeat,
+			UnhealthyUntil:     unhealthyUntil,
+		})
+		w.mu.Unlock()
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].WorkerID < infos[j].WorkerID })
+	return infos
+}
+
 // Get returns a worker by ID, or nil if not found.
 func (p *Pool) Get(workerID string) *ConnectedWorker {
 	p.mu.RLock()
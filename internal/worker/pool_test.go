@@ -1,6 +1,8 @@
 package worker
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -56,14 +58,14 @@ func TestPool_SelectWorker_LeastLoaded(t *testing.T) {
 	pool.Register(w2)
 	pool.Register(w3)
 
-	selected := pool.SelectWorker()
+	selected := pool.SelectWorkerForProvider("", "")
 	require.NotNil(t, selected)
 	assert.Equal(t, "w2", selected.WorkerID, "should select least loaded worker")
 }
 
 func TestPool_SelectWorker_NoneAvailable(t *testing.T) {
 	pool := NewPool()
-	assert.Nil(t, pool.SelectWorker(), "empty pool should return nil")
+	assert.Nil(t, pool.SelectWorkerForProvider("", ""), "empty pool should return nil")
 }
 
 func TestPool_SelectWorker_AllFullyLoaded(t *testing.T) {
@@ -75,7 +77,69 @@ func TestPool_SelectWorker_AllFullyLoaded(t *testing.T) {
 	pool.Register(w1)
 	pool.Register(w2)
 
-	assert.Nil(t, pool.SelectWorker(), "all fully loaded should return nil")
+	assert.Nil(t, pool.SelectWorkerForProvider("", ""), "all fully loaded should return nil")
+}
+
+func TestPool_SelectWorkerForProvider(t *testing.T) {
+	pool := NewPool()
+
+	openai := &ConnectedWorker{WorkerID: "openai", MaxConcurrent: 4, SupportedProviders: []string{"openai"}}
+	multi := &ConnectedWorker{WorkerID: "multi", MaxConcurrent: 4, ActiveTasks: 2, SupportedProviders: []string{"OpenAI", "Anthropic"}}
+
+	pool.Register(openai)
+	pool.Register(multi)
+
+	selected := pool.SelectWorkerForProvider("anthropic", "")
+	require.NotNil(t, selected)
+	assert.Equal(t, "multi", selected.WorkerID, "should skip workers without the provider despite lower load")
+
+	selected = pool.SelectWorkerForProvider("openai", "")
+	require.NotNil(t, selected)
+	assert.Equal(t, "openai", selected.WorkerID)
+
+	assert.Nil(t, pool.SelectWorkerForProvider("ollama", ""), "no worker supports the provider")
+
+	selected = pool.SelectWorkerForProvider("", "")
+	require.NotNil(t, selected)
+	assert.Equal(t, "openai", selected.WorkerID, "empty provider falls back to any worker")
+}
+
+func TestPool_SelectWorkerForModel(t *testing.T) {
+	pool := NewPool()
+
+	local := &ConnectedWorker{WorkerID: "local", MaxConcurrent: 4, SupportedProviders: []string{"openai"}, SupportedModels: []string{"llama3:8b"}}
+	hosted := &ConnectedWorker{WorkerID: "hosted", MaxConcurrent: 4, ActiveTasks: 2, SupportedProviders: []string{"openai"}, SupportedModels: []string{"GPT-4o"}}
+	pool.Register(local)
+	pool.Register(hosted)
+
+	selected := pool.SelectWorkerForProvider("openai", "gpt-4o")
+	require.NotNil(t, selected)
+	assert.Equal(t, "hosted", selected.WorkerID, "should skip workers without the model despite lower load")
+
+	assert.Nil(t, pool.SelectWorkerForProvider("openai", "gpt-4.1"), "no worker serves the model")
+
+	selected = pool.SelectWorkerForProvider("openai", "")
+	require.NotNil(t, selected)
+	assert.Equal(t, "local", selected.WorkerID, "empty model falls back to any worker")
+
+	pool.Register(&ConnectedWorker{WorkerID: "any", MaxConcurrent: 4, SupportedProviders: []string{"openai"}})
+	selected = pool.SelectWorkerForProvider("openai", "gpt-4.1")
+	require.NotNil(t, selected)
+	assert.Equal(t, "any", selected.WorkerID, "a worker without a model list serves any model")
+}
+
+func TestPool_List(t *testing.T) {
+	pool := NewPool()
+	pool.Register(&ConnectedWorker{WorkerID: "w2", MaxConcurrent: 4, ActiveTasks: 1, SupportedModels: []string{"gpt-4o"}, MaxContextTokens: 128000})
+	pool.Register(&ConnectedWorker{WorkerID: "w1", MaxConcurrent: 2})
+
+	infos := pool.List()
+	require.Len(t, infos, 2)
+	assert.Equal(t, "w1", infos[0].WorkerID)
+	assert.Equal(t, "w2", infos[1].WorkerID)
+	assert.Equal(t, []string{"gpt-4o"}, infos[1].SupportedModels)
+	assert.Equal(t, int32(128000), infos[1].MaxContextTokens)
+	assert.Equal(t, int32(1), infos[1].ActiveTasks)
 }
 
 func TestPool_Get(t *testing.T) {
@@ -126,3 +190,62 @@ func TestConnectedWorker_LoadFraction(t *testing.T) {
 	w2 := &ConnectedWorker{WorkerID: "w2", MaxConcurrent: 0}
 	assert.InDelta(t, 1.0, w2.LoadFraction(), 0.001)
 }
+
+func TestPool_TryAcquire(t *testing.T) {
+	pool := NewPool()
+	w := &ConnectedWorker{WorkerID: "w1", MaxConcurrent: 1}
+	assert.False(t, pool.TryAcquire(w), "unregistered worker")
+
+	pool.Register(w)
+	assert.True(t, pool.TryAcquire(w))
+	assert.False(t, pool.TryAcquire(w), "worker at capacity")
+
+	w.DecrementActive()
+	assert.True(t, pool.TryAcquire(w))
+}
+
+func TestPool_AcquireWorker_Concurrent(t *testing.T) {
+	pool := NewPool()
+	workers := []*ConnectedWorker{
+		{WorkerID: "w1", MaxConcurrent: 1},
+		{WorkerID: "w2", MaxConcurrent: 3},
+		{WorkerID: "w3", MaxConcurrent: 5},
+	}
+	peak := make(map[*ConnectedWorker]*atomic.Int32)
+	for _, w := range workers {
+		pool.Register(w)
+		peak[w] = &atomic.Int32{}
+	}
+
+	var acquired atomic.Int32
+	var wg sync.WaitGroup
+	for range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				w := pool.AcquireWorkerForProvider("", "")
+				if w == nil {
+					continue
+				}
+				acquired.Add(1)
+				w.mu.Lock()
+				active := w.ActiveTasks
+				w.mu.Unlock()
+				for p := peak[w]; ; {
+					if cur := p.Load(); active <= cur || p.CompareAndSwap(cur, active) {
+						break
+					}
+				}
+				w.DecrementActive()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Positive(t, acquired.Load())
+	for _, w := range workers {
+		assert.LessOrEqual(t, peak[w].Load(), w.MaxConcurrent, w.WorkerID)
+		assert.Equal(t, int32(0), w.ActiveTasks, w.WorkerID)
+	}
+}
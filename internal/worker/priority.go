@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+const (
+	// taskBatchSize is how many tasks are fetched from a queue at once.
+	taskBatchSize = 10
+	// fairnessInterval makes every fairnessInterval-th batch come from the
+	// lowest-priority queue with tasks waiting, so a steady stream of
+	// high-priority work can't starve the others.
+	fairnessInterval = 5
+	// taskIdleWait is the pause before polling again when every queue is empty.
+	taskIdleWait = 100 * time.Millisecond
+)
+
+// taskQueue is the durable consumer for one task priority.
+type taskQueue struct {
+	priority string
+	consumer jetstream.Consumer
+}
+
+// taskConsumerName returns the durable consumer name for a priority. Normal
+// keeps the original "task-dispatcher" name so its backlog carries over.
+func taskConsumerName(priority string) string {
+	if priority == inats.PriorityNormal {
+		return "task-dispatcher"
+	}
+	return "task-dispatcher-" + priority
+}
+
+// ensureTaskQueues ensures one consumer per priority and returns them highest
+// priority first.
+func (d *Dispatcher) ensureTaskQueues(ctx context.Context) ([]taskQueue, error) {
+	queues := make([]taskQueue, len(inats.Priorities))
+	// The normal consumer used to filter on every task subject. It is narrowed
+	// first because a work-queue stream rejects consumers with overlapping
+	// filters.
+	for _, p := range []string{inats.PriorityNormal, inats.PriorityHigh, inats.PriorityLow} {
+		consumer, err := d.consumerMgr.EnsureConsumer(ctx, inats.StreamTasks, taskConsumerName(p), inats.TaskFilter(p))
+		if err != nil {
+			return nil, err
+		}
+		queues[slices.Index(inats.Priorities, p)] = taskQueue{priority: p, consumer: consumer}
+	}
+	return queues, nil
+}
+
+// queueOrder returns the order queues are polled in for the n-th batch:
+// highest priority first, except every fairnessInterval-th batch, which
+// starts from the lowest.
+func queueOrder(queues []taskQueue, n int) []taskQueue {
+	if n%fairnessInterval != fairnessInterval-1 {
+		return queues
+	}
+	order := slices.Clone(queues)
+	slices.Reverse(order)
+	return order
+}
+
+// consumeTasks handles one batch at a time from the first queue, in
+// queueOrder, that has tasks waiting. Nothing is fetched while the gate holds
+// tasks for the database, since every task needs it.
+func (d *Dispatcher) consumeTasks(ctx context.Context, queues []taskQueue) {
+	for n := 0; ; {
+		served := false
+		if _, held := d.gate.Check(DependencyDatabase); !held {
+			for _, q := range queueOrder(queues, n) {
+				if d.fetchTasks(ctx, q) > 0 {
+					served = true
+					break
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if served {
+			n++
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(taskIdleWait):
+		}
+	}
+}
+
+// fetchTasks handles the tasks waiting in q, up to taskBatchSize, and returns
+// how many there were.
+func (d *Dispatcher) fetchTasks(ctx context.Context, q taskQueue) int {
+	msgs, err := q.consumer.FetchNoWait(taskBatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.Debug("dispatcher: fetching tasks", "priority", q.priority, "error", err)
+		}
+		return 0
+	}
+
+	n := 0
+	for msg := range msgs.Messages() {
+		d.handleTask(ctx, msg)
+		n++
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+func priorities(queues []taskQueue) []string {
+	out := make([]string, len(queues))
+	for i, q := range queues {
+		out[i] = q.priority
+	}
+	return out
+}
+
+func TestQueueOrder(t *testing.T) {
+	queues := []taskQueue{{priority: inats.PriorityHigh}, {priority: inats.PriorityNormal}, {priority: inats.PriorityLow}}
+
+	lowFirst := 0
+	for n := range 2 * fairnessInterval {
+		order := priorities(queueOrder(queues, n))
+		if order[0] == inats.PriorityLow {
+			lowFirst++
+			assert.Equal(t, []string{"low", "normal", "high"}, order, "batch %d", n)
+			continue
+		}
+		assert.Equal(t, []string{"high", "normal", "low"}, order, "batch %d", n)
+	}
+	assert.Equal(t, 2, lowFirst)
+
+	// The caller's slice is left in priority order.
+	assert.Equal(t, []string{"high", "normal", "low"}, priorities(queues))
+}
+
+func TestTaskConsumerName(t *testing.T) {
+	assert.Equal(t, "task-dispatcher", taskConsumerName(inats.PriorityNormal))
+	assert.Equal(t, "task-dispatcher-high", taskConsumerName(inats.PriorityHigh))
+}
+
+// emptyConsumer counts fetches from a queue that never has tasks.
+type emptyConsumer struct {
+	jetstream.Consumer
+	fetches atomic.Int32
+}
+
+func (c *emptyConsumer) FetchNoWait(int) (jetstream.MessageBatch, error) {
+	c.fetches.Add(1)
+	return nil, errors.New("no messages")
+}
+
+func TestConsumeTasks_PausedWhileDatabaseHeld(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	d := newHoldDispatcher(t, &dbErr)
+	consumer := &emptyConsumer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.consumeTasks(ctx, []taskQueue{{priority: inats.PriorityNormal, consumer: consumer}})
+
+	time.Sleep(3 * taskIdleWait)
+	assert.Zero(t, consumer.fetches.Load(), "no task is fetched while the database is down")
+
+	dbErr = nil
+	d.gate.probe(ctx)
+	require.Eventually(t, func() bool { return consumer.fetches.Load() > 0 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	// ReapInterval is how often the reaper scans the pool for silent workers.
+	ReapInterval = 15 * time.Second
+	// DefaultHeartbeatTimeout is used when no timeout is configured.
+	DefaultHeartbeatTimeout = 45 * time.Second
+)
+
+// Reaper evicts workers that stopped heartbeating without closing their stream,
+// so they no longer receive tasks.
+type Reaper struct {
+	pool    *Pool
+	repo    *Repository
+	timeout time.Duration
+}
+
+// NewReaper creates a reaper that evicts workers silent for longer than timeout.
+func NewReaper(pool *Pool, repo *Repository, timeout time.Duration) *Reaper {
+	if timeout <= 0 {
+		timeout = DefaultHeartbeatTimeout
+	}
+	return &Reaper{pool: pool, repo: repo, timeout: timeout}
+}
+
+// Run scans the pool every ReapInterval until ctx is cancelled.
+func (r *Reaper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reap(ctx, time.Now())
+		}
+	}
+}
+
+// reap unregisters stale workers and marks them offline. It returns the IDs evicted.
+func (r *Reaper) reap(ctx context.Context, now time.Time) []string {
+	var evicted []string
+	for _, w := range r.pool.Stale(now, r.timeout) {
+		if !r.pool.Remove(w) {
+			continue
+		}
+		evicted = append(evicted, w.WorkerID)
+		slog.Warn("evicting worker with no recent heartbeat",
+			"worker_id", w.WorkerID,
+			"last_heartbeat", w.HeartbeatAge(now),
+		)
+		if r.repo != nil {
+			if err := r.repo.MarkWorkerOffline(ctx, w.WorkerID); err != nil {
+				slog.Error("marking worker offline", "error", err, "worker_id", w.WorkerID)
+			}
+		}
+	}
+	return evicted
+}
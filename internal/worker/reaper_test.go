@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaper_EvictsSilentWorkers(t *testing.T) {
+	pool := NewPool()
+	now := time.Now()
+
+	fresh := &ConnectedWorker{WorkerID: "fresh", MaxConcurrent: 4, LastHeartbeat: now.Add(-10 * time.Second)}
+	silent := &ConnectedWorker{WorkerID: "silent", MaxConcurrent: 4, LastHeartbeat: now.Add(-time.Minute)}
+	pool.Register(fresh)
+	pool.Register(silent)
+
+	r := NewReaper(pool, nil, 45*time.Second)
+	evicted := r.reap(context.Background(), now)
+
+	assert.Equal(t, []string{"silent"}, evicted)
+	assert.Equal(t, 1, pool.ConnectedCount())
+	assert.Nil(t, pool.Get("silent"))
+	require.NotNil(t, pool.SelectWorkerForProvider("", ""))
+	assert.Equal(t, "fresh", pool.SelectWorkerForProvider("", "").WorkerID)
+}
+
+func TestReaper_TouchKeepsWorkerAlive(t *testing.T) {
+	pool := NewPool()
+	now := time.Now()
+
+	w := &ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4, LastHeartbeat: now.Add(-time.Minute)}
+	pool.Register(w)
+	w.Touch(now)
+
+	assert.Empty(t, NewReaper(pool, nil, 45*time.Second).reap(context.Background(), now))
+	assert.Equal(t, 1, pool.ConnectedCount())
+}
+
+func TestPool_RemoveIgnoresReplacedWorker(t *testing.T) {
+	pool := NewPool()
+
+	old := &ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4}
+	pool.Register(old)
+	require.True(t, pool.Remove(old))
+
+	// The worker reconnects with the same ID; the old stream's cleanup must not evict it.
+	reconnected := &ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4}
+	require.True(t, pool.Register(reconnected))
+	assert.False(t, pool.Remove(old))
+	assert.Equal(t, reconnected, pool.Get("w1"))
+}
@@ -2,28 +2,68 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Execution represents a recorded task execution.
 type Execution struct {
-	ID              uuid.UUID
-	OwnerUserID     uuid.UUID
-	AgentID         uuid.UUID
-	Input           string
-	Output          string
-	TokensUsed      int
-	WorkerID        string
-	DurationMs      int
-	GoLatencyMs     int
-	PythonLatencyMs int
-	Status          string
-	ErrorMessage    string
-	CreatedAt       time.Time
+	ID              uuid.UUID `json:"id"`
+	RequestID       string    `json:"request_id"`
+	OwnerUserID     uuid.UUID `json:"owner_user_id"`
+	AgentID         uuid.UUID `json:"agent_id"`
+	Input           string    `json:"input"`
+	Output          string    `json:"output"`
+	TokensUsed      int       `json:"tokens_used"`
+	Model           string    `json:"model"`
+	CostUSD         float64   `json:"cost_usd"`
+	WorkerID        string    `json:"worker_id"`
+	DurationMs      int       `json:"duration_ms"`
+	GoLatencyMs     int       `json:"go_latency_ms"`
+	PythonLatencyMs int       `json:"python_latency_ms"`
+	Status          string    `json:"status"`
+	ErrorMessage    string    `json:"error_message,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	// Redacted is set when PII was masked in Input or Output before storage.
+	Redacted bool `json:"redacted"`
+	// Truncated is set in list results when Input or Output was cut to
+	// ExecutionPreviewLength characters.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// ExecutionPreviewLength is how many characters of input and output are
+// returned per execution by ListByAgent.
+const ExecutionPreviewLength = 200
+
+// ErrExecutionNotFound is returned when an execution does not exist for the agent.
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ErrContextNotFound is returned when no debug context was captured for an
+// execution of the agent.
+var ErrContextNotFound = errors.New("no context was captured for this execution")
+
+// ExecutionListParams holds pagination and filter parameters for ListByAgent.
+type ExecutionListParams struct {
+	Page     int
+	PageSize int
+	Status   string
+	From     *time.Time
+	To       *time.Time
+}
+
+// DefaultExecutionListParams returns list params with sensible defaults.
+func DefaultExecutionListParams() ExecutionListParams {
+	return ExecutionListParams{
+		Page:     1,
+		PageSize: 20,
+	}
 }
 
 // Repository handles DB operations for workers and executions.
@@ -39,14 +79,14 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 // RecordExecution inserts an execution record into the database.
 func (r *Repository) RecordExecution(ctx context.Context, exec *Execution) error {
 	query := `
-		INSERT INTO executions (id, owner_user_id, agent_id, input, output, tokens_used, worker_id, duration_ms, go_latency_ms, python_latency_ms, status, error_message, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+		INSERT INTO executions (id, request_id, owner_user_id, agent_id, input, output, tokens_used, model, cost_usd, worker_id, duration_ms, go_latency_ms, python_latency_ms, status, error_message, created_at, redacted)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
 
 	_, err := r.pool.Exec(ctx, query,
-		exec.ID, exec.OwnerUserID, exec.AgentID,
-		exec.Input, exec.Output, exec.TokensUsed,
+		exec.ID, exec.RequestID, exec.OwnerUserID, exec.AgentID,
+		exec.Input, exec.Output, exec.TokensUsed, exec.Model, exec.CostUSD,
 		exec.WorkerID, exec.DurationMs, exec.GoLatencyMs, exec.PythonLatencyMs,
-		exec.Status, exec.ErrorMessage, exec.CreatedAt,
+		exec.Status, exec.ErrorMessage, exec.CreatedAt, exec.Redacted,
 	)
 	if err != nil {
 		return fmt.Errorf("inserting execution: %w", err)
@@ -54,6 +94,182 @@ func (r *Repository) RecordExecution(ctx context.Context, exec *Execution) error
 	return nil
 }
 
+// ListByAgent returns the agent's executions, newest first, with input and
+// output cut to ExecutionPreviewLength characters.
+func (r *Repository) ListByAgent(ctx context.Context, agentID uuid.UUID, params ExecutionListParams) ([]Execution, int64, error) {
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.PageSize < 1 || params.PageSize > 100 {
+		params.PageSize = 20
+	}
+
+	conditions := []string{"agent_id = $1"}
+	args := []any{agentID}
+	argIdx := 2
+
+	if params.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", argIdx))
+		args = append(args, params.Status)
+		argIdx++
+	}
+
+	if params.From != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIdx))
+		args = append(args, *params.From)
+		argIdx++
+	}
+
+	if params.To != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", argIdx))
+		args = append(args, *params.To)
+		argIdx++
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) FROM executions WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("counting executions: %w", err)
+	}
+
+	offset := (params.Page - 1) * params.PageSize
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(request_id, ''), owner_user_id, agent_id,
+		       LEFT(COALESCE(input, ''), %[1]d), LEFT(COALESCE(output, ''), %[1]d),
+		       tokens_used, COALESCE(model, ''), cost_usd, COALESCE(worker_id, ''), duration_ms, go_latency_ms, python_latency_ms,
+		       status, COALESCE(error_message, ''), created_at, redacted,
+		       char_length(input) > %[1]d OR char_length(output) > %[1]d
+		FROM executions WHERE %[2]s
+		ORDER BY created_at DESC
+		LIMIT $%[3]d OFFSET $%[4]d`, ExecutionPreviewLength, where, argIdx, argIdx+1)
+	args = append(args, params.PageSize, offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querying executions: %w", err)
+	}
+	defer rows.Close()
+
+	executions := make([]Execution, 0)
+	for rows.Next() {
+		var e Execution
+		var truncated *bool
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.OwnerUserID, &e.AgentID,
+			&e.Input, &e.Output, &e.TokensUsed, &e.Model, &e.CostUSD, &e.WorkerID,
+			&e.DurationMs, &e.GoLatencyMs, &e.PythonLatencyMs,
+			&e.Status, &e.ErrorMessage, &e.CreatedAt, &e.Redacted, &truncated); err != nil {
+			return nil, 0, fmt.Errorf("scanning execution: %w", err)
+		}
+		e.Truncated = truncated != nil && *truncated
+		executions = append(executions, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("iterating executions: %w", err)
+	}
+
+	return executions, total, nil
+}
+
+// GetExecution returns one of the agent's executions with its full input and output.
+func (r *Repository) GetExecution(ctx context.Context, agentID, execID uuid.UUID) (*Execution, error) {
+	query := `
+		SELECT id, COALESCE(request_id, ''), owner_user_id, agent_id,
+		       COALESCE(input, ''), COALESCE(output, ''),
+		       tokens_used, COALESCE(model, ''), cost_usd, COALESCE(worker_id, ''), duration_ms, go_latency_ms, python_latency_ms,
+		       status, COALESCE(error_message, ''), created_at, redacted
+		FROM executions WHERE id = $1 AND agent_id = $2`
+
+	var e Execution
+	err := r.pool.QueryRow(ctx, query, execID, agentID).Scan(&e.ID, &e.RequestID, &e.OwnerUserID, &e.AgentID,
+		&e.Input, &e.Output, &e.TokensUsed, &e.Model, &e.CostUSD, &e.WorkerID,
+		&e.DurationMs, &e.GoLatencyMs, &e.PythonLatencyMs,
+		&e.Status, &e.ErrorMessage, &e.CreatedAt, &e.Redacted)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrExecutionNotFound
+		}
+		return nil, fmt.Errorf("getting execution: %w", err)
+	}
+	return &e, nil
+}
+
+// RecordContext stores the debug context captured for an execution.
+func (r *Repository) RecordContext(ctx context.Context, ec *ExecutionContext) error {
+	request, err := json.Marshal(ec.TaskContext)
+	if err != nil {
+		return fmt.Errorf("marshaling execution context: %w", err)
+	}
+
+	query := `
+		INSERT INTO execution_contexts (execution_id, system_prompt_id, request, created_at)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := r.pool.Exec(ctx, query, ec.ExecutionID, ec.SystemPromptID, request, ec.CreatedAt); err != nil {
+		return fmt.Errorf("inserting execution context: %w", err)
+	}
+	return nil
+}
+
+// GetContext returns the debug context of one of the agent's executions,
+// without its system prompt, or ErrContextNotFound.
+func (r *Repository) GetContext(ctx context.Context, agentID, execID uuid.UUID) (*ExecutionContext, error) {
+	query := `
+		SELECT c.execution_id, c.system_prompt_id, c.request, c.created_at
+		FROM execution_contexts c
+		JOIN executions e ON e.id = c.execution_id
+		WHERE c.execution_id = $1 AND e.agent_id = $2`
+
+	var ec ExecutionContext
+	var request []byte
+	err := r.pool.QueryRow(ctx, query, execID, agentID).Scan(&ec.ExecutionID, &ec.SystemPromptID, &request, &ec.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrContextNotFound
+		}
+		return nil, fmt.Errorf("getting execution context: %w", err)
+	}
+	if err := json.Unmarshal(request, &ec.TaskContext); err != nil {
+		return nil, fmt.Errorf("unmarshaling execution context: %w", err)
+	}
+	return &ec, nil
+}
+
+// ListByOwnerAfter returns up to limit of the owner's executions, across all
+// agents, created after the (afterCreatedAt, afterID) keyset position in
+// oldest-first order, with their full input and output. Zero values start
+// from the oldest execution.
+func (r *Repository) ListByOwnerAfter(ctx context.Context, ownerID uuid.UUID, afterCreatedAt time.Time, afterID uuid.UUID, limit int) ([]Execution, error) {
+	query := `
+		SELECT id, COALESCE(request_id, ''), owner_user_id, agent_id,
+		       COALESCE(input, ''), COALESCE(output, ''),
+		       tokens_used, COALESCE(model, ''), cost_usd, COALESCE(worker_id, ''), duration_ms, go_latency_ms, python_latency_ms,
+		       status, COALESCE(error_message, ''), created_at, redacted
+		FROM executions
+		WHERE owner_user_id = $1 AND (created_at, id) > ($2, $3)
+		ORDER BY created_at, id
+		LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, ownerID, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing executions by owner: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []Execution
+	for rows.Next() {
+		var e Execution
+		if err := rows.Scan(&e.ID, &e.RequestID, &e.OwnerUserID, &e.AgentID,
+			&e.Input, &e.Output, &e.TokensUsed, &e.Model, &e.CostUSD, &e.WorkerID,
+			&e.DurationMs, &e.GoLatencyMs, &e.PythonLatencyMs,
+			&e.Status, &e.ErrorMessage, &e.CreatedAt, &e.Redacted); err != nil {
+			return nil, fmt.Errorf("scanning execution: %w", err)
+		}
+		executions = append(executions, e)
+	}
+	return executions, rows.Err()
+}
+
 // UpsertWorker inserts or updates a worker record on registration.
 func (r *Repository) UpsertWorker(ctx context.Context, workerID, host string, port int, capabilities []byte) error {
 	query := `
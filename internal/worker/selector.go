@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// Load-balancing strategies accepted by SelectorByName.
+const (
+	StrategyLeastLoaded    = "least_loaded"
+	StrategyRoundRobin     = "round_robin"
+	StrategyWeightedRandom = "weighted_random"
+)
+
+// Selector picks the worker for a task. candidates is never empty, is ordered
+// by WorkerID, and holds only workers that serve the task and are below
+// capacity. Implementations must be safe for concurrent use.
+type Selector interface {
+	Select(candidates []*ConnectedWorker) *ConnectedWorker
+}
+
+// SelectorByName returns the selector for a GRPC_LOAD_BALANCING value. An
+// empty name selects least-loaded.
+func SelectorByName(name string) (Selector, error) {
+	switch name {
+	case "", StrategyLeastLoaded:
+		return LeastLoaded{}, nil
+	case StrategyRoundRobin:
+		return &RoundRobin{}, nil
+	case StrategyWeightedRandom:
+		return WeightedRandom{}, nil
+	default:
+		return nil, fmt.Errorf("unknown load-balancing strategy %q", name)
+	}
+}
+
+// LeastLoaded picks the candidate with the lowest LoadFraction, the first by
+// WorkerID on a tie. It is the default.
+type LeastLoaded struct{}
+
+// Select implements Selector.
+func (LeastLoaded) Select(candidates []*ConnectedWorker) *ConnectedWorker {
+	best, bestLoad := candidates[0], candidates[0].LoadFraction()
+	for _, w := range candidates[1:] {
+		if load := w.LoadFraction(); load < bestLoad {
+			best, bestLoad = w, load
+		}
+	}
+	return best
+}
+
+// RoundRobin hands tasks to the candidates in turn, whatever their capacity
+// or load.
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+// Select implements Selector.
+func (r *RoundRobin) Select(candidates []*ConnectedWorker) *ConnectedWorker {
+	n := r.next.Add(1) - 1
+	return candidates[n%uint64(len(candidates))]
+}
+
+// WeightedRandom picks a candidate at random, weighted by its free slots
+// (MaxConcurrent × (1 − LoadFraction)), so larger and idler workers receive
+// proportionally more tasks.
+type WeightedRandom struct{}
+
+// Select implements Selector.
+func (WeightedRandom) Select(candidates []*ConnectedWorker) *ConnectedWorker {
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, w := range candidates {
+		weights[i] = float64(w.MaxConcurrent) * (1 - w.LoadFraction())
+		total += weights[i]
+	}
+	if total <= 0 {
+		return candidates[rand.IntN(len(candidates))]
+	}
+
+	r := rand.Float64() * total
+	for i, weight := range weights {
+		if r < weight {
+			return candidates[i]
+		}
+		r -= weight
+	}
+	return candidates[len(candidates)-1]
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockWorkers registers idle workers with capacities 2, 4, and 8.
+func mockWorkers(t *testing.T, s Selector) (*Pool, []*ConnectedWorker) {
+	t.Helper()
+	pool := NewPool()
+	pool.SetSelector(s)
+	workers := []*ConnectedWorker{
+		{WorkerID: "small", MaxConcurrent: 2},
+		{WorkerID: "medium", MaxConcurrent: 4},
+		{WorkerID: "large", MaxConcurrent: 8},
+	}
+	for _, w := range workers {
+		require.True(t, pool.Register(w))
+	}
+	return pool, workers
+}
+
+// dispatch acquires and immediately releases a worker n times and counts
+// how often each was picked.
+func dispatch(t *testing.T, pool *Pool, n int) map[string]int {
+	t.Helper()
+	counts := make(map[string]int)
+	for range n {
+		w := pool.AcquireWorkerForProvider("", "")
+		require.NotNil(t, w)
+		counts[w.WorkerID]++
+		w.DecrementActive()
+	}
+	return counts
+}
+
+func TestSelectorByName(t *testing.T) {
+	for name, want := range map[string]Selector{
+		"":                LeastLoaded{},
+		"least_loaded":    LeastLoaded{},
+		"round_robin":     &RoundRobin{},
+		"weighted_random": WeightedRandom{},
+	} {
+		s, err := SelectorByName(name)
+		require.NoError(t, err, name)
+		assert.IsType(t, want, s, name)
+	}
+
+	_, err := SelectorByName("random")
+	assert.Error(t, err)
+}
+
+func TestLeastLoaded_FillsProportionally(t *testing.T) {
+	pool, workers := mockWorkers(t, LeastLoaded{})
+
+	// Half of the total capacity lands as half of each worker's capacity.
+	for range 7 {
+		require.NotNil(t, pool.AcquireWorkerForProvider("", ""))
+	}
+	for _, w := range workers {
+		assert.Equal(t, w.MaxConcurrent/2, w.ActiveTasks, w.WorkerID)
+	}
+
+	for range 7 {
+		require.NotNil(t, pool.AcquireWorkerForProvider("", ""))
+	}
+	assert.Nil(t, pool.AcquireWorkerForProvider("", ""), "every worker is full")
+}
+
+func TestRoundRobin_EqualShares(t *testing.T) {
+	pool, _ := mockWorkers(t, &RoundRobin{})
+
+	counts := dispatch(t, pool, 300)
+	assert.Equal(t, map[string]int{"small": 100, "medium": 100, "large": 100}, counts)
+}
+
+func TestRoundRobin_SkipsFullWorkers(t *testing.T) {
+	pool, workers := mockWorkers(t, &RoundRobin{})
+	workers[0].ActiveTasks = workers[0].MaxConcurrent
+
+	counts := dispatch(t, pool, 100)
+	assert.Zero(t, counts["small"])
+	assert.Equal(t, 50, counts["medium"])
+	assert.Equal(t, 50, counts["large"])
+}
+
+func TestWeightedRandom_SharesFollowFreeCapacity(t *testing.T) {
+	pool, workers := mockWorkers(t, WeightedRandom{})
+
+	const n = 70000
+	counts := dispatch(t, pool, n)
+	assert.InDelta(t, 1.0/7, float64(counts["small"])/n, 0.02)
+	assert.InDelta(t, 2.0/7, float64(counts["medium"])/n, 0.02)
+	assert.InDelta(t, 4.0/7, float64(counts["large"])/n, 0.02)
+
+	// A busy worker's share shrinks with its free slots: large has 2 of 8 left.
+	workers[2].ActiveTasks = 6
+	counts = dispatch(t, pool, n)
+	assert.InDelta(t, 2.0/8, float64(counts["small"])/n, 0.02)
+	assert.InDelta(t, 4.0/8, float64(counts["medium"])/n, 0.02)
+	assert.InDelta(t, 2.0/8, float64(counts["large"])/n, 0.02)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"log/slog"
+	"time"
 
 	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
 	"google.golang.org/grpc"
@@ -14,17 +15,19 @@ import (
 type Server struct {
 	pb.UnimplementedWorkerServiceServer
 
-	pool     *Pool
-	repo     *Repository
-	resultCh chan *pb.TaskResponse
+	pool      *Pool
+	repo      *Repository
+	resultCh  chan *pb.TaskResponse
+	summaryCh chan *pb.SummarizeResponse
 }
 
 // NewServer creates a new gRPC worker server.
 func NewServer(pool *Pool, repo *Repository) *Server {
 	return &Server{
-		pool:     pool,
-		repo:     repo,
-		resultCh: make(chan *pb.TaskResponse, 256),
+		pool:      pool,
+		repo:      repo,
+		resultCh:  make(chan *pb.TaskResponse, 256),
+		summaryCh: make(chan *pb.SummarizeResponse, 64),
 	}
 }
 
@@ -33,9 +36,14 @@ func (s *Server) ResultChannel() <-chan *pb.TaskResponse {
 	return s.resultCh
 }
 
+// SummaryChannel returns the channel that receives summarize responses from workers.
+func (s *Server) SummaryChannel() <-chan *pb.SummarizeResponse {
+	return s.summaryCh
+}
+
 // TaskStream implements the bidirectional streaming RPC.
 // First message from worker must be RegisterWorker.
-// Subsequent messages are TaskResponse results.
+// Subsequent messages are TaskResponse and SummarizeResponse results.
 func (s *Server) TaskStream(stream grpc.BidiStreamingServer[pb.WorkerMessage, pb.ServerMessage]) error {
 	// First message must be registration
 	firstMsg, err := stream.Recv()
@@ -65,6 +73,9 @@ func (s *Server) TaskStream(stream grpc.BidiStreamingServer[pb.WorkerMessage, pb
 		WorkerID:           reg.WorkerId,
 		MaxConcurrent:      maxConcurrent,
 		SupportedProviders: reg.SupportedProviders,
+		SupportedModels:    reg.SupportedModels,
+		MaxContextTokens:   reg.MaxContextTokens,
+		LastHeartbeat:      time.Now(),
 		Stream:             stream,
 	}
 
@@ -84,12 +95,16 @@ func (s *Server) TaskStream(stream grpc.BidiStreamingServer[pb.WorkerMessage, pb
 		"worker_id", reg.WorkerId,
 		"max_concurrent", maxConcurrent,
 		"providers", reg.SupportedProviders,
+		"models", reg.SupportedModels,
+		"max_context_tokens", reg.MaxContextTokens,
 	)
 
 	// Upsert worker in DB
 	caps, _ := json.Marshal(map[string]any{
-		"providers":      reg.SupportedProviders,
-		"max_concurrent": maxConcurrent,
+		"providers":          reg.SupportedProviders,
+		"models":             reg.SupportedModels,
+		"max_context_tokens": reg.MaxContextTokens,
+		"max_concurrent":     maxConcurrent,
 	})
 	if s.repo != nil {
 		if err := s.repo.UpsertWorker(stream.Context(), reg.WorkerId, "grpc-stream", 0, caps); err != nil {
@@ -110,7 +125,7 @@ func (s *Server) TaskStream(stream grpc.BidiStreamingServer[pb.WorkerMessage, pb
 		return err
 	}
 
-	// Receive loop: read TaskResponse messages from the worker
+	// Receive loop: read TaskResponse and SummarizeResponse messages from the worker
 	for {
 		msg, err := stream.Recv()
 		if err != nil {
@@ -122,20 +137,32 @@ func (s *Server) TaskStream(stream grpc.BidiStreamingServer[pb.WorkerMessage, pb
 			break
 		}
 
+		if sum := msg.GetSummarizeResponse(); sum != nil {
+			sum.WorkerId = reg.WorkerId
+			slog.Debug("worker summary received", "worker_id", reg.WorkerId, "request_id", sum.RequestId)
+			s.summaryCh <- sum
+			continue
+		}
+
 		resp := msg.GetTaskResponse()
 		if resp == nil {
-			slog.Debug("ignoring non-TaskResponse message from worker", "worker_id", reg.WorkerId)
+			slog.Debug("ignoring unexpected message from worker", "worker_id", reg.WorkerId)
 			continue
 		}
 
 		resp.WorkerId = reg.WorkerId
+		slog.Debug("worker result received", "worker_id", reg.WorkerId, "request_id", resp.RequestId)
 		s.resultCh <- resp
 	}
 
-	// Cleanup on disconnect.
+	// Cleanup on disconnect. If the reaper already evicted this worker, the
+	// ID may now belong to a reconnected stream, which must be left alone.
 	// Use context.Background() because stream.Context() is already cancelled
 	// by the time we reach here.
-	s.pool.Unregister(reg.WorkerId)
+	if !s.pool.Remove(worker) {
+		slog.Info("worker stream closed after eviction", "worker_id", reg.WorkerId)
+		return nil
+	}
 	if s.repo != nil {
 		if err := s.repo.MarkWorkerOffline(context.Background(), reg.WorkerId); err != nil {
 			slog.Error("marking worker offline", "error", err)
@@ -148,6 +175,10 @@ func (s *Server) TaskStream(stream grpc.BidiStreamingServer[pb.WorkerMessage, pb
 
 // Heartbeat handles periodic health pings from workers.
 func (s *Server) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	if w := s.pool.Get(req.WorkerId); w != nil {
+		w.Touch(time.Now())
+	}
+
 	if s.repo != nil {
 		if err := s.repo.UpdateWorkerHeartbeat(ctx, req.WorkerId, int(req.ActiveTasks), int(req.AvgLatencyMs), int(req.MemoryUsageMb)); err != nil {
 			slog.Error("updating heartbeat", "error", err)
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/aiox-platform/aiox/internal/memory"
+	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
+)
+
+// SetSummaryChannel sets the channel that carries workers' summarize
+// responses, normally Server.SummaryChannel. Without it, Summarize calls
+// time out. It must be called before Start.
+func (d *Dispatcher) SetSummaryChannel(ch <-chan *pb.SummarizeResponse) {
+	d.summaryCh = ch
+}
+
+// Summarize implements memory.Summarizer: it sends the turns to a worker that
+// serves the agent's provider and model and waits up to the task timeout for the summary.
+// Tokens spent on the summary are deducted from the owner's quota.
+func (d *Dispatcher) Summarize(ctx context.Context, task memory.SummaryTask) (*memory.Summary, error) {
+	conversationJSON, err := json.Marshal(task.Turns)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling conversation: %w", err)
+	}
+
+	requestID := uuid.New().String()
+	ch := make(chan *pb.SummarizeResponse, 1)
+	d.mu.Lock()
+	d.summaries[requestID] = ch
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.summaries, requestID)
+		d.mu.Unlock()
+	}()
+
+	worker, err := d.sendToWorker(extractProvider(task.LLMConfig), extractModel(task.LLMConfig), &pb.ServerMessage{
+		Payload: &pb.ServerMessage_SummarizeRequest{
+			SummarizeRequest: &pb.SummarizeRequest{
+				RequestId:        requestID,
+				AgentId:          task.AgentID.String(),
+				OwnerUserId:      task.OwnerUserID.String(),
+				LlmConfigJson:    string(task.LLMConfig),
+				ConversationJson: string(conversationJSON),
+				EmbeddingModel:   task.EmbeddingModel,
+			},
+		},
+	})
+	if worker == nil {
+		if err != nil {
+			return nil, fmt.Errorf("sending summarize request: %w", err)
+		}
+		return nil, errors.New("no workers available")
+	}
+	defer worker.DecrementActive()
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout())
+	defer cancel()
+
+	var resp *pb.SummarizeResponse
+	select {
+	case resp = <-ch:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for summary from worker %s: %w", worker.WorkerID, ctx.Err())
+	}
+
+	if resp.TokensUsed > 0 && d.quotaSvc != nil {
+		if err := d.quotaSvc.DeductTokens(ctx, task.OwnerUserID, int(resp.TokensUsed)); err != nil {
+			slog.Warn("dispatcher: deducting summary tokens from quota", "error", err, "user_id", task.OwnerUserID)
+		}
+		if err := d.quotaSvc.DeductAgentTokens(ctx, task.AgentID, task.OwnerUserID, int(resp.TokensUsed)); err != nil {
+			slog.Warn("dispatcher: deducting summary tokens from agent quota", "error", err, "agent_id", task.AgentID)
+		}
+	}
+
+	if resp.ErrorMessage != "" {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+	if resp.Summary == nil {
+		return nil, errors.New("worker returned no summary")
+	}
+
+	summary := &memory.Summary{
+		Content:   resp.Summary.Content,
+		Embedding: resp.Summary.Embedding,
+	}
+	if resp.Summary.MetadataJson != "" {
+		summary.Metadata = json.RawMessage(resp.Summary.MetadataJson)
+	}
+	return summary, nil
+}
+
+// handleSummary hands a worker's summarize response to the waiting Summarize call.
+func (d *Dispatcher) handleSummary(resp *pb.SummarizeResponse) {
+	d.mu.Lock()
+	ch, ok := d.summaries[resp.RequestId]
+	d.mu.Unlock()
+
+	if !ok {
+		slog.Warn("dispatcher: received summary for unknown request", "request_id", resp.RequestId)
+		return
+	}
+	select {
+	case ch <- resp:
+	default:
+	}
+}
+
+// summarizeConversation promotes old short-term turns of pt's conversation to
+// long-term memory when the agent has auto-summarization enabled.
+func (d *Dispatcher) summarizeConversation(ctx context.Context, pt *pendingTask) {
+	stored, err := d.memorySvc.SummarizeConversation(ctx, pt.AgentID, pt.OwnerUserID, pt.FromJID, pt.LLMConfig, pt.MemoryConfig)
+	if err != nil {
+		slog.Warn("dispatcher: summarizing conversation", "error", err, "agent_id", pt.AgentID)
+		return
+	}
+	if stored {
+		slog.Debug("dispatcher: conversation summarized", "agent_id", pt.AgentID)
+	}
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/aiox-platform/aiox/internal/memory"
+	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
+)
+
+// captureStream records messages sent to a worker.
+type captureStream struct {
+	grpc.BidiStreamingServer[pb.WorkerMessage, pb.ServerMessage]
+	sent chan *pb.ServerMessage
+}
+
+func (s *captureStream) Send(msg *pb.ServerMessage) error {
+	s.sent <- msg
+	return nil
+}
+
+func newSummaryDispatcher(t *testing.T) (*Dispatcher, *captureStream) {
+	t.Helper()
+	stream := &captureStream{sent: make(chan *pb.ServerMessage, 1)}
+	pool := NewPool()
+	pool.Register(&ConnectedWorker{WorkerID: "w1", MaxConcurrent: 4, SupportedProviders: []string{"openai"}, Stream: stream})
+	d := NewDispatcher(pool, nil, nil, nil, nil, nil, nil, nil, nil, 1)
+	return d, stream
+}
+
+func TestDispatcher_Summarize(t *testing.T) {
+	d, stream := newSummaryDispatcher(t)
+	task := memory.SummaryTask{
+		AgentID:     uuid.New(),
+		OwnerUserID: uuid.New(),
+		LLMConfig:   []byte(`{"provider":"openai"}`),
+		Turns:       []memory.ConversationEntry{{Role: "user", Content: "I like tea"}},
+	}
+
+	go func() {
+		req := (<-stream.sent).GetSummarizeRequest()
+		d.handleSummary(&pb.SummarizeResponse{
+			RequestId: req.RequestId,
+			Summary:   &pb.MemoryEntry{Content: "likes tea", Embedding: []float32{1, 0}, MetadataJson: `{"turns":1}`},
+		})
+	}()
+
+	summary, err := d.Summarize(context.Background(), task)
+	require.NoError(t, err)
+	assert.Equal(t, "likes tea", summary.Content)
+	assert.Equal(t, []float32{1, 0}, summary.Embedding)
+	assert.JSONEq(t, `{"turns":1}`, string(summary.Metadata))
+	assert.Equal(t, int32(0), d.pool.Get("w1").ActiveTasks)
+}
+
+func TestDispatcher_SummarizeWorkerError(t *testing.T) {
+	d, stream := newSummaryDispatcher(t)
+
+	go func() {
+		req := (<-stream.sent).GetSummarizeRequest()
+		d.handleSummary(&pb.SummarizeResponse{RequestId: req.RequestId, ErrorMessage: "provider failed"})
+	}()
+
+	_, err := d.Summarize(context.Background(), memory.SummaryTask{LLMConfig: []byte(`{"provider":"openai"}`)})
+	assert.EqualError(t, err, "provider failed")
+}
+
+func TestDispatcher_SummarizeTimeout(t *testing.T) {
+	d, _ := newSummaryDispatcher(t)
+	d.SetTaskTimeout(10 * time.Millisecond)
+
+	_, err := d.Summarize(context.Background(), memory.SummaryTask{LLMConfig: []byte(`{"provider":"openai"}`)})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, d.summaries)
+}
+
+func TestDispatcher_SummarizeNoWorker(t *testing.T) {
+	d, _ := newSummaryDispatcher(t)
+
+	_, err := d.Summarize(context.Background(), memory.SummaryTask{LLMConfig: []byte(`{"provider":"anthropic"}`)})
+	assert.Error(t, err)
+}
@@ -0,0 +1,266 @@
+// Package workerclient implements the worker side of the WorkerService gRPC
+// protocol: it registers on the TaskStream, runs a handler for each TaskRequest,
+// streams back TaskResponses, sends periodic heartbeats, and reconnects when the
+// stream drops. It mirrors the behaviour of the Python worker in worker/.
+package workerclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
+)
+
+const (
+	defaultMaxConcurrent     = 4
+	defaultHeartbeatInterval = 30 * time.Second
+	defaultReconnectDelay    = 5 * time.Second
+	apiKeyHeader             = "x-api-key"
+	requestIDHeader          = "x-request-id"
+)
+
+// ErrRegistrationRejected is returned for a session whose RegisterWorker was not accepted.
+var ErrRegistrationRejected = errors.New("worker registration rejected")
+
+// Config configures a worker connection.
+type Config struct {
+	// Target is the gRPC server address, e.g. "localhost:50051".
+	Target   string
+	WorkerID string
+	// APIKey is sent as x-api-key metadata when non-empty (GRPC_WORKER_API_KEY on the server).
+	APIKey             string
+	MaxConcurrent      int
+	SupportedProviders []string
+	// SupportedModels restricts the worker to these models; empty serves any
+	// model of SupportedProviders.
+	SupportedModels   []string
+	MaxContextTokens  int
+	HeartbeatInterval time.Duration
+	ReconnectDelay    time.Duration
+	// DialOptions replace the default insecure transport credentials when set.
+	DialOptions []grpc.DialOption
+}
+
+// Handler processes one task. A returned error is reported to the server as the
+// response's error_message. RequestId, WorkerId, and DurationMs are filled in
+// automatically when left empty.
+type Handler func(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error)
+
+// RequestID returns the correlation ID of the task a Handler is processing,
+// carried in the handler context's incoming metadata as x-request-id. Log it
+// alongside the handler's own output to match it with the server's logs.
+func RequestID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(requestIDHeader); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// RunWorker connects to the server and processes tasks until ctx is cancelled,
+// reconnecting after ReconnectDelay whenever the stream fails. It only returns
+// on cancellation or invalid configuration.
+func RunWorker(ctx context.Context, cfg Config, handler Handler) error {
+	if cfg.Target == "" || cfg.WorkerID == "" {
+		return errors.New("workerclient: Target and WorkerID are required")
+	}
+	if handler == nil {
+		return errors.New("workerclient: handler is required")
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultMaxConcurrent
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	if cfg.ReconnectDelay <= 0 {
+		cfg.ReconnectDelay = defaultReconnectDelay
+	}
+	if len(cfg.DialOptions) == 0 {
+		cfg.DialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	for {
+		err := runSession(ctx, cfg, handler)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.Warn("workerclient: session ended, reconnecting",
+			"worker_id", cfg.WorkerID, "error", err, "delay", cfg.ReconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(cfg.ReconnectDelay):
+		}
+	}
+}
+
+// runSession handles a single connection: register, heartbeat, and task loop.
+func runSession(ctx context.Context, cfg Config, handler Handler) error {
+	conn, err := grpc.NewClient(cfg.Target, cfg.DialOptions...)
+	if err != nil {
+		return fmt.Errorf("creating grpc client: %w", err)
+	}
+	defer conn.Close()
+
+	client := pb.NewWorkerServiceClient(conn)
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if cfg.APIKey != "" {
+		sessionCtx = metadata.AppendToOutgoingContext(sessionCtx, apiKeyHeader, cfg.APIKey)
+	}
+
+	stream, err := client.TaskStream(sessionCtx)
+	if err != nil {
+		return fmt.Errorf("opening task stream: %w", err)
+	}
+
+	if err := stream.Send(&pb.WorkerMessage{
+		Payload: &pb.WorkerMessage_Register{
+			Register: &pb.RegisterWorker{
+				WorkerId:           cfg.WorkerID,
+				MaxConcurrent:      int32(cfg.MaxConcurrent),
+				SupportedProviders: cfg.SupportedProviders,
+				SupportedModels:    cfg.SupportedModels,
+				MaxContextTokens:   int32(cfg.MaxContextTokens),
+			},
+		},
+	}); err != nil {
+		return fmt.Errorf("sending registration: %w", err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("waiting for registration ack: %w", err)
+	}
+	ack := first.GetRegisterAck()
+	if ack == nil || !ack.Accepted {
+		msg := "no ack"
+		if ack != nil {
+			msg = ack.Message
+		}
+		return fmt.Errorf("%w: %s", ErrRegistrationRejected, msg)
+	}
+
+	slog.Info("workerclient: registered", "worker_id", cfg.WorkerID, "target", cfg.Target)
+
+	s := &session{
+		cfg:     cfg,
+		handler: handler,
+		stream:  stream,
+		slots:   make(chan struct{}, cfg.MaxConcurrent),
+	}
+
+	go s.heartbeat(sessionCtx, client)
+
+	err = s.receive(sessionCtx)
+	cancel()
+	s.wg.Wait()
+	return err
+}
+
+type session struct {
+	cfg     Config
+	handler Handler
+	stream  grpc.BidiStreamingClient[pb.WorkerMessage, pb.ServerMessage]
+
+	sendMu sync.Mutex // gRPC streams do not allow concurrent Send calls
+	slots  chan struct{}
+	active atomic.Int32
+	wg     sync.WaitGroup
+}
+
+func (s *session) receive(ctx context.Context) error {
+	for {
+		msg, err := s.stream.Recv()
+		if err != nil {
+			return fmt.Errorf("receiving from task stream: %w", err)
+		}
+
+		req := msg.GetTaskRequest()
+		if req == nil || req.RequestId == "" {
+			continue
+		}
+
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { <-s.slots }()
+			s.process(ctx, req)
+		}()
+	}
+}
+
+func (s *session) process(ctx context.Context, req *pb.TaskRequest) {
+	s.active.Add(1)
+	defer s.active.Add(-1)
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(requestIDHeader, req.RequestId))
+
+	start := time.Now()
+	resp, err := s.handler(ctx, req)
+	if resp == nil {
+		resp = &pb.TaskResponse{}
+	}
+	if err != nil && resp.ErrorMessage == "" {
+		resp.ErrorMessage = err.Error()
+	}
+	if resp.RequestId == "" {
+		resp.RequestId = req.RequestId
+	}
+	if resp.WorkerId == "" {
+		resp.WorkerId = s.cfg.WorkerID
+	}
+	if resp.DurationMs == 0 {
+		resp.DurationMs = int32(time.Since(start).Milliseconds())
+	}
+
+	s.sendMu.Lock()
+	err = s.stream.Send(&pb.WorkerMessage{
+		Payload: &pb.WorkerMessage_TaskResponse{TaskResponse: resp},
+	})
+	s.sendMu.Unlock()
+	if err != nil {
+		slog.Warn("workerclient: sending task response", "request_id", req.RequestId, "error", err)
+	}
+}
+
+func (s *session) heartbeat(ctx context.Context, client pb.WorkerServiceClient) {
+	ticker := time.NewTicker(s.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, err := client.Heartbeat(ctx, &pb.HeartbeatRequest{
+				WorkerId:    s.cfg.WorkerID,
+				ActiveTasks: s.active.Load(),
+			})
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("workerclient: heartbeat failed", "worker_id", s.cfg.WorkerID, "error", err)
+			}
+		}
+	}
+}
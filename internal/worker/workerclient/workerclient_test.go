@@ -0,0 +1,95 @@
+package workerclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/aiox-platform/aiox/internal/worker"
+	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
+)
+
+func startServer(t *testing.T, apiKey string) (*worker.Pool, *worker.Server, grpc.DialOption) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	pool := worker.NewPool()
+	srv := worker.NewServer(pool, nil)
+
+	gs := grpc.NewServer(grpc.StreamInterceptor(worker.StreamAuthInterceptor(apiKey)))
+	pb.RegisterWorkerServiceServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	return pool, srv, dialer
+}
+
+func TestRunWorker_ProcessesTasks(t *testing.T) {
+	pool, srv, dialer := startServer(t, "secret")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- RunWorker(ctx, Config{
+			Target:      "passthrough:///bufnet",
+			WorkerID:    "go-worker-1",
+			APIKey:      "secret",
+			DialOptions: []grpc.DialOption{dialer, grpc.WithTransportCredentials(insecure.NewCredentials())},
+		}, func(ctx context.Context, req *pb.TaskRequest) (*pb.TaskResponse, error) {
+			if RequestID(ctx) != req.RequestId {
+				return nil, errors.New("request ID missing from context")
+			}
+			if req.UserMessage == "fail" {
+				return nil, errors.New("boom")
+			}
+			return &pb.TaskResponse{ResponseText: "echo: " + req.UserMessage, TokensUsed: 3}, nil
+		})
+	}()
+
+	var w *worker.ConnectedWorker
+	require.Eventually(t, func() bool {
+		w = pool.Get("go-worker-1")
+		return w != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, m := range []string{"hi", "fail"} {
+		require.NoError(t, w.Send(&pb.ServerMessage{
+			Payload: &pb.ServerMessage_TaskRequest{TaskRequest: &pb.TaskRequest{RequestId: "req-" + m, UserMessage: m}},
+		}))
+	}
+
+	got := map[string]*pb.TaskResponse{}
+	for len(got) < 2 {
+		select {
+		case resp := <-srv.ResultChannel():
+			got[resp.RequestId] = resp
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for task responses")
+		}
+	}
+	assert.Equal(t, "echo: hi", got["req-hi"].ResponseText)
+	assert.Equal(t, "go-worker-1", got["req-hi"].WorkerId)
+	assert.Equal(t, "boom", got["req-fail"].ErrorMessage)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestRunWorker_RequiresConfig(t *testing.T) {
+	err := RunWorker(context.Background(), Config{}, func(context.Context, *pb.TaskRequest) (*pb.TaskResponse, error) {
+		return nil, nil
+	})
+	assert.Error(t, err)
+}
@@ -28,6 +28,7 @@ type WorkerMessage struct {
 	//
 	//	*WorkerMessage_Register
 	//	*WorkerMessage_TaskResponse
+	//	*WorkerMessage_SummarizeResponse
 	Payload       isWorkerMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -88,6 +89,15 @@ func (x *WorkerMessage) GetTaskResponse() *TaskResponse {
 	return nil
 }
 
+func (x *WorkerMessage) GetSummarizeResponse() *SummarizeResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*WorkerMessage_SummarizeResponse); ok {
+			return x.SummarizeResponse
+		}
+	}
+	return nil
+}
+
 type isWorkerMessage_Payload interface {
 	isWorkerMessage_Payload()
 }
@@ -100,10 +110,16 @@ type WorkerMessage_TaskResponse struct {
 	TaskResponse *TaskResponse `protobuf:"bytes,2,opt,name=task_response,json=taskResponse,proto3,oneof"`
 }
 
+type WorkerMessage_SummarizeResponse struct {
+	SummarizeResponse *SummarizeResponse `protobuf:"bytes,3,opt,name=summarize_response,json=summarizeResponse,proto3,oneof"`
+}
+
 func (*WorkerMessage_Register) isWorkerMessage_Payload() {}
 
 func (*WorkerMessage_TaskResponse) isWorkerMessage_Payload() {}
 
+func (*WorkerMessage_SummarizeResponse) isWorkerMessage_Payload() {}
+
 // ServerMessage is sent from the server to the worker.
 type ServerMessage struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -111,6 +127,7 @@ type ServerMessage struct {
 	//
 	//	*ServerMessage_RegisterAck
 	//	*ServerMessage_TaskRequest
+	//	*ServerMessage_SummarizeRequest
 	Payload       isServerMessage_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -171,6 +188,15 @@ func (x *ServerMessage) GetTaskRequest() *TaskRequest {
 	return nil
 }
 
+func (x *ServerMessage) GetSummarizeRequest() *SummarizeRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerMessage_SummarizeRequest); ok {
+			return x.SummarizeRequest
+		}
+	}
+	return nil
+}
+
 type isServerMessage_Payload interface {
 	isServerMessage_Payload()
 }
@@ -183,16 +209,24 @@ type ServerMessage_TaskRequest struct {
 	TaskRequest *TaskRequest `protobuf:"bytes,2,opt,name=task_request,json=taskRequest,proto3,oneof"`
 }
 
+type ServerMessage_SummarizeRequest struct {
+	SummarizeRequest *SummarizeRequest `protobuf:"bytes,3,opt,name=summarize_request,json=summarizeRequest,proto3,oneof"`
+}
+
 func (*ServerMessage_RegisterAck) isServerMessage_Payload() {}
 
 func (*ServerMessage_TaskRequest) isServerMessage_Payload() {}
 
+func (*ServerMessage_SummarizeRequest) isServerMessage_Payload() {}
+
 // RegisterWorker is the first message a worker sends to identify itself.
 type RegisterWorker struct {
 	state              protoimpl.MessageState `protogen:"open.v1"`
 	WorkerId           string                 `protobuf:"bytes,1,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
 	MaxConcurrent      int32                  `protobuf:"varint,2,opt,name=max_concurrent,json=maxConcurrent,proto3" json:"max_concurrent,omitempty"`
 	SupportedProviders []string               `protobuf:"bytes,3,rep,name=supported_providers,json=supportedProviders,proto3" json:"supported_providers,omitempty"` // e.g., ["openai", "anthropic", "ollama"]
+	SupportedModels    []string               `protobuf:"bytes,4,rep,name=supported_models,json=supportedModels,proto3" json:"supported_models,omitempty"`          // e.g., ["gpt-4o", "llama3:8b"]; empty means any model
+	MaxContextTokens   int32                  `protobuf:"varint,5,opt,name=max_context_tokens,json=maxContextTokens,proto3" json:"max_context_tokens,omitempty"`    // largest context window served; 0 means unknown
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -248,6 +282,20 @@ func (x *RegisterWorker) GetSupportedProviders() []string {
 	return nil
 }
 
+func (x *RegisterWorker) GetSupportedModels() []string {
+	if x != nil {
+		return x.SupportedModels
+	}
+	return nil
+}
+
+func (x *RegisterWorker) GetMaxContextTokens() int32 {
+	if x != nil {
+		return x.MaxContextTokens
+	}
+	return 0
+}
+
 // RegisterAck is sent by the server to confirm registration.
 type RegisterAck struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -303,20 +351,23 @@ func (x *RegisterAck) GetMessage() string {
 
 // TaskRequest is sent from the server to a worker to process a task.
 type TaskRequest struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	RequestId         string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	AgentId           string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	OwnerUserId       string                 `protobuf:"bytes,3,opt,name=owner_user_id,json=ownerUserId,proto3" json:"owner_user_id,omitempty"`
-	UserMessage       string                 `protobuf:"bytes,4,opt,name=user_message,json=userMessage,proto3" json:"user_message,omitempty"`
-	SystemPrompt      string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`      // Decrypted system prompt
-	LlmConfigJson     string                 `protobuf:"bytes,6,opt,name=llm_config_json,json=llmConfigJson,proto3" json:"llm_config_json,omitempty"` // JSON: {"provider":"openai","model":"gpt-4o-mini","temperature":0.7,"max_tokens":1024}
-	FromJid           string                 `protobuf:"bytes,7,opt,name=from_jid,json=fromJid,proto3" json:"from_jid,omitempty"`
-	AgentJid          string                 `protobuf:"bytes,8,opt,name=agent_jid,json=agentJid,proto3" json:"agent_jid,omitempty"`
-	AgentName         string                 `protobuf:"bytes,9,opt,name=agent_name,json=agentName,proto3" json:"agent_name,omitempty"`
-	MemoryContextJson string                 `protobuf:"bytes,10,opt,name=memory_context_json,json=memoryContextJson,proto3" json:"memory_context_json,omitempty"` // JSON: recent messages + relevant long-term memories
-	MemoryConfigJson  string                 `protobuf:"bytes,11,opt,name=memory_config_json,json=memoryConfigJson,proto3" json:"memory_config_json,omitempty"`    // JSON: memory configuration from agent
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	RequestId          string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	AgentId            string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	OwnerUserId        string                 `protobuf:"bytes,3,opt,name=owner_user_id,json=ownerUserId,proto3" json:"owner_user_id,omitempty"`
+	UserMessage        string                 `protobuf:"bytes,4,opt,name=user_message,json=userMessage,proto3" json:"user_message,omitempty"`
+	SystemPrompt       string                 `protobuf:"bytes,5,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`      // Decrypted system prompt
+	LlmConfigJson      string                 `protobuf:"bytes,6,opt,name=llm_config_json,json=llmConfigJson,proto3" json:"llm_config_json,omitempty"` // JSON: {"provider":"openai","model":"gpt-4o-mini","temperature":0.7,"max_tokens":1024}
+	FromJid            string                 `protobuf:"bytes,7,opt,name=from_jid,json=fromJid,proto3" json:"from_jid,omitempty"`
+	AgentJid           string                 `protobuf:"bytes,8,opt,name=agent_jid,json=agentJid,proto3" json:"agent_jid,omitempty"`
+	AgentName          string                 `protobuf:"bytes,9,opt,name=agent_name,json=agentName,proto3" json:"agent_name,omitempty"`
+	MemoryContextJson  string                 `protobuf:"bytes,10,opt,name=memory_context_json,json=memoryContextJson,proto3" json:"memory_context_json,omitempty"`    // JSON: recent messages + relevant long-term memories
+	MemoryConfigJson   string                 `protobuf:"bytes,11,opt,name=memory_config_json,json=memoryConfigJson,proto3" json:"memory_config_json,omitempty"`       // JSON: memory configuration from agent
+	AttachmentsJson    string                 `protobuf:"bytes,12,opt,name=attachments_json,json=attachmentsJson,proto3" json:"attachments_json,omitempty"`            // JSON array of {"url","mime_type","size","description"}
+	ToolsJson          string                 `protobuf:"bytes,13,opt,name=tools_json,json=toolsJson,proto3" json:"tools_json,omitempty"`                              // JSON array of {"name","config"} for the tools the LLM may call
+	ResponseSchemaJson string                 `protobuf:"bytes,14,opt,name=response_schema_json,json=responseSchemaJson,proto3" json:"response_schema_json,omitempty"` // JSON Schema the reply's structured_json must match; empty for plain text
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *TaskRequest) Reset() {
@@ -426,19 +477,42 @@ func (x *TaskRequest) GetMemoryConfigJson() string {
 	return ""
 }
 
+func (x *TaskRequest) GetAttachmentsJson() string {
+	if x != nil {
+		return x.AttachmentsJson
+	}
+	return ""
+}
+
+func (x *TaskRequest) GetToolsJson() string {
+	if x != nil {
+		return x.ToolsJson
+	}
+	return ""
+}
+
+func (x *TaskRequest) GetResponseSchemaJson() string {
+	if x != nil {
+		return x.ResponseSchemaJson
+	}
+	return ""
+}
+
 // TaskResponse is sent from the worker back to the server with the LLM result.
 type TaskResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	WorkerId      string                 `protobuf:"bytes,2,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
-	ResponseText  string                 `protobuf:"bytes,3,opt,name=response_text,json=responseText,proto3" json:"response_text,omitempty"`
-	TokensUsed    int32                  `protobuf:"varint,4,opt,name=tokens_used,json=tokensUsed,proto3" json:"tokens_used,omitempty"`
-	DurationMs    int32                  `protobuf:"varint,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	ModelUsed     string                 `protobuf:"bytes,6,opt,name=model_used,json=modelUsed,proto3" json:"model_used,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"` // Non-empty indicates failure
-	NewMemories   []*MemoryEntry         `protobuf:"bytes,8,rep,name=new_memories,json=newMemories,proto3" json:"new_memories,omitempty"`    // New memories to persist (with embeddings from Python)
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RequestId       string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	WorkerId        string                 `protobuf:"bytes,2,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	ResponseText    string                 `protobuf:"bytes,3,opt,name=response_text,json=responseText,proto3" json:"response_text,omitempty"`
+	TokensUsed      int32                  `protobuf:"varint,4,opt,name=tokens_used,json=tokensUsed,proto3" json:"tokens_used,omitempty"`
+	DurationMs      int32                  `protobuf:"varint,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	ModelUsed       string                 `protobuf:"bytes,6,opt,name=model_used,json=modelUsed,proto3" json:"model_used,omitempty"`
+	ErrorMessage    string                 `protobuf:"bytes,7,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`          // Non-empty indicates failure
+	NewMemories     []*MemoryEntry         `protobuf:"bytes,8,rep,name=new_memories,json=newMemories,proto3" json:"new_memories,omitempty"`             // New memories to persist (with embeddings from Python)
+	AttachmentsJson string                 `protobuf:"bytes,9,opt,name=attachments_json,json=attachmentsJson,proto3" json:"attachments_json,omitempty"` // Same shape as TaskRequest.attachments_json
+	StructuredJson  string                 `protobuf:"bytes,10,opt,name=structured_json,json=structuredJson,proto3" json:"structured_json,omitempty"`   // JSON document matching TaskRequest.response_schema_json
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *TaskResponse) Reset() {
@@ -527,11 +601,25 @@ func (x *TaskResponse) GetNewMemories() []*MemoryEntry {
 	return nil
 }
 
+func (x *TaskResponse) GetAttachmentsJson() string {
+	if x != nil {
+		return x.AttachmentsJson
+	}
+	return ""
+}
+
+func (x *TaskResponse) GetStructuredJson() string {
+	if x != nil {
+		return x.StructuredJson
+	}
+	return ""
+}
+
 // MemoryEntry represents a memory to be stored, with its embedding vector.
 type MemoryEntry struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
-	Embedding     []float32              `protobuf:"fixed32,2,rep,packed,name=embedding,proto3" json:"embedding,omitempty"`                  // 384-dim vector from sentence-transformers
+	Embedding     []float32              `protobuf:"fixed32,2,rep,packed,name=embedding,proto3" json:"embedding,omitempty"`                  // Vector from the agent's embedding_model (384-dim by default)
 	MemoryType    string                 `protobuf:"bytes,3,opt,name=memory_type,json=memoryType,proto3" json:"memory_type,omitempty"`       // e.g., "conversation", "fact", "preference"
 	MetadataJson  string                 `protobuf:"bytes,4,opt,name=metadata_json,json=metadataJson,proto3" json:"metadata_json,omitempty"` // Optional JSON metadata
 	unknownFields protoimpl.UnknownFields
@@ -596,6 +684,177 @@ func (x *MemoryEntry) GetMetadataJson() string {
 	return ""
 }
 
+// SummarizeRequest asks a worker to condense the oldest short-term conversation
+// turns into a single long-term memory.
+type SummarizeRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RequestId        string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	AgentId          string                 `protobuf:"bytes,2,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	OwnerUserId      string                 `protobuf:"bytes,3,opt,name=owner_user_id,json=ownerUserId,proto3" json:"owner_user_id,omitempty"`
+	LlmConfigJson    string                 `protobuf:"bytes,4,opt,name=llm_config_json,json=llmConfigJson,proto3" json:"llm_config_json,omitempty"`        // Same shape as TaskRequest.llm_config_json
+	ConversationJson string                 `protobuf:"bytes,5,opt,name=conversation_json,json=conversationJson,proto3" json:"conversation_json,omitempty"` // JSON array of {"role","content","timestamp"}, oldest first
+	EmbeddingModel   string                 `protobuf:"bytes,6,opt,name=embedding_model,json=embeddingModel,proto3" json:"embedding_model,omitempty"`       // Model to embed the summary with (agent's memory_config.embedding_model)
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SummarizeRequest) Reset() {
+	*x = SummarizeRequest{}
+	mi := &file_worker_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummarizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummarizeRequest) ProtoMessage() {}
+
+func (x *SummarizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummarizeRequest.ProtoReflect.Descriptor instead.
+func (*SummarizeRequest) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SummarizeRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SummarizeRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *SummarizeRequest) GetOwnerUserId() string {
+	if x != nil {
+		return x.OwnerUserId
+	}
+	return ""
+}
+
+func (x *SummarizeRequest) GetLlmConfigJson() string {
+	if x != nil {
+		return x.LlmConfigJson
+	}
+	return ""
+}
+
+func (x *SummarizeRequest) GetConversationJson() string {
+	if x != nil {
+		return x.ConversationJson
+	}
+	return ""
+}
+
+func (x *SummarizeRequest) GetEmbeddingModel() string {
+	if x != nil {
+		return x.EmbeddingModel
+	}
+	return ""
+}
+
+// SummarizeResponse returns the summary, with its embedding, for a SummarizeRequest.
+type SummarizeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	WorkerId      string                 `protobuf:"bytes,2,opt,name=worker_id,json=workerId,proto3" json:"worker_id,omitempty"`
+	Summary       *MemoryEntry           `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	TokensUsed    int32                  `protobuf:"varint,4,opt,name=tokens_used,json=tokensUsed,proto3" json:"tokens_used,omitempty"`
+	ModelUsed     string                 `protobuf:"bytes,5,opt,name=model_used,json=modelUsed,proto3" json:"model_used,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,6,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"` // Non-empty indicates failure
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SummarizeResponse) Reset() {
+	*x = SummarizeResponse{}
+	mi := &file_worker_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SummarizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SummarizeResponse) ProtoMessage() {}
+
+func (x *SummarizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_worker_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SummarizeResponse.ProtoReflect.Descriptor instead.
+func (*SummarizeResponse) Descriptor() ([]byte, []int) {
+	return file_worker_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SummarizeResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *SummarizeResponse) GetWorkerId() string {
+	if x != nil {
+		return x.WorkerId
+	}
+	return ""
+}
+
+func (x *SummarizeResponse) GetSummary() *MemoryEntry {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *SummarizeResponse) GetTokensUsed() int32 {
+	if x != nil {
+		return x.TokensUsed
+	}
+	return 0
+}
+
+func (x *SummarizeResponse) GetModelUsed() string {
+	if x != nil {
+		return x.ModelUsed
+	}
+	return ""
+}
+
+func (x *SummarizeResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
 // HeartbeatRequest is a periodic health check from the worker.
 type HeartbeatRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -609,7 +868,7 @@ type HeartbeatRequest struct {
 
 func (x *HeartbeatRequest) Reset() {
 	*x = HeartbeatRequest{}
-	mi := &file_worker_proto_msgTypes[7]
+	mi := &file_worker_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -621,7 +880,7 @@ func (x *HeartbeatRequest) String() string {
 func (*HeartbeatRequest) ProtoMessage() {}
 
 func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_worker_proto_msgTypes[7]
+	mi := &file_worker_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -634,7 +893,7 @@ func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
 func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
-	return file_worker_proto_rawDescGZIP(), []int{7}
+	return file_worker_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *HeartbeatRequest) GetWorkerId() string {
@@ -675,7 +934,7 @@ type HeartbeatResponse struct {
 
 func (x *HeartbeatResponse) Reset() {
 	*x = HeartbeatResponse{}
-	mi := &file_worker_proto_msgTypes[8]
+	mi := &file_worker_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -687,7 +946,7 @@ func (x *HeartbeatResponse) String() string {
 func (*HeartbeatResponse) ProtoMessage() {}
 
 func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_worker_proto_msgTypes[8]
+	mi := &file_worker_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -700,7 +959,7 @@ func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
 func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
-	return file_worker_proto_rawDescGZIP(), []int{8}
+	return file_worker_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *HeartbeatResponse) GetOk() bool {
@@ -714,22 +973,26 @@ var File_worker_proto protoreflect.FileDescriptor
 
 const file_worker_proto_rawDesc = "" +
 	"\n" +
-	"\fworker.proto\x12\tworker.v1\"\x93\x01\n" +
+	"\fworker.proto\x12\tworker.v1\"\xe2\x01\n" +
 	"\rWorkerMessage\x127\n" +
 	"\bregister\x18\x01 \x01(\v2\x19.worker.v1.RegisterWorkerH\x00R\bregister\x12>\n" +
-	"\rtask_response\x18\x02 \x01(\v2\x17.worker.v1.TaskResponseH\x00R\ftaskResponseB\t\n" +
-	"\apayload\"\x94\x01\n" +
+	"\rtask_response\x18\x02 \x01(\v2\x17.worker.v1.TaskResponseH\x00R\ftaskResponse\x12M\n" +
+	"\x12summarize_response\x18\x03 \x01(\v2\x1c.worker.v1.SummarizeResponseH\x00R\x11summarizeResponseB\t\n" +
+	"\apayload\"\xe0\x01\n" +
 	"\rServerMessage\x12;\n" +
 	"\fregister_ack\x18\x01 \x01(\v2\x16.worker.v1.RegisterAckH\x00R\vregisterAck\x12;\n" +
-	"\ftask_request\x18\x02 \x01(\v2\x16.worker.v1.TaskRequestH\x00R\vtaskRequestB\t\n" +
-	"\apayload\"\x85\x01\n" +
+	"\ftask_request\x18\x02 \x01(\v2\x16.worker.v1.TaskRequestH\x00R\vtaskRequest\x12J\n" +
+	"\x11summarize_request\x18\x03 \x01(\v2\x1b.worker.v1.SummarizeRequestH\x00R\x10summarizeRequestB\t\n" +
+	"\apayload\"\xde\x01\n" +
 	"\x0eRegisterWorker\x12\x1b\n" +
 	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12%\n" +
 	"\x0emax_concurrent\x18\x02 \x01(\x05R\rmaxConcurrent\x12/\n" +
-	"\x13supported_providers\x18\x03 \x03(\tR\x12supportedProviders\"C\n" +
+	"\x13supported_providers\x18\x03 \x03(\tR\x12supportedProviders\x12)\n" +
+	"\x10supported_models\x18\x04 \x03(\tR\x0fsupportedModels\x12,\n" +
+	"\x12max_context_tokens\x18\x05 \x01(\x05R\x10maxContextTokens\"C\n" +
 	"\vRegisterAck\x12\x1a\n" +
 	"\baccepted\x18\x01 \x01(\bR\baccepted\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\x90\x03\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\x8c\x04\n" +
 	"\vTaskRequest\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12\x19\n" +
@@ -744,7 +1007,11 @@ const file_worker_proto_rawDesc = "" +
 	"agent_name\x18\t \x01(\tR\tagentName\x12.\n" +
 	"\x13memory_context_json\x18\n" +
 	" \x01(\tR\x11memoryContextJson\x12,\n" +
-	"\x12memory_config_json\x18\v \x01(\tR\x10memoryConfigJson\"\xb0\x02\n" +
+	"\x12memory_config_json\x18\v \x01(\tR\x10memoryConfigJson\x12)\n" +
+	"\x10attachments_json\x18\f \x01(\tR\x0fattachmentsJson\x12\x1d\n" +
+	"\n" +
+	"tools_json\x18\r \x01(\tR\ttoolsJson\x120\n" +
+	"\x14response_schema_json\x18\x0e \x01(\tR\x12responseSchemaJson\"\x84\x03\n" +
 	"\fTaskResponse\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
@@ -757,13 +1024,34 @@ const file_worker_proto_rawDesc = "" +
 	"\n" +
 	"model_used\x18\x06 \x01(\tR\tmodelUsed\x12#\n" +
 	"\rerror_message\x18\a \x01(\tR\ferrorMessage\x129\n" +
-	"\fnew_memories\x18\b \x03(\v2\x16.worker.v1.MemoryEntryR\vnewMemories\"\x8b\x01\n" +
+	"\fnew_memories\x18\b \x03(\v2\x16.worker.v1.MemoryEntryR\vnewMemories\x12)\n" +
+	"\x10attachments_json\x18\t \x01(\tR\x0fattachmentsJson\x12'\n" +
+	"\x0fstructured_json\x18\n" +
+	" \x01(\tR\x0estructuredJson\"\x8b\x01\n" +
 	"\vMemoryEntry\x12\x18\n" +
 	"\acontent\x18\x01 \x01(\tR\acontent\x12\x1c\n" +
 	"\tembedding\x18\x02 \x03(\x02R\tembedding\x12\x1f\n" +
 	"\vmemory_type\x18\x03 \x01(\tR\n" +
 	"memoryType\x12#\n" +
-	"\rmetadata_json\x18\x04 \x01(\tR\fmetadataJson\"\xa0\x01\n" +
+	"\rmetadata_json\x18\x04 \x01(\tR\fmetadataJson\"\xee\x01\n" +
+	"\x10SummarizeRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\"\n" +
+	"\rowner_user_id\x18\x03 \x01(\tR\vownerUserId\x12&\n" +
+	"\x0fllm_config_json\x18\x04 \x01(\tR\rllmConfigJson\x12+\n" +
+	"\x11conversation_json\x18\x05 \x01(\tR\x10conversationJson\x12'\n" +
+	"\x0fembedding_model\x18\x06 \x01(\tR\x0eembeddingModel\"\xe6\x01\n" +
+	"\x11SummarizeResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
+	"\tworker_id\x18\x02 \x01(\tR\bworkerId\x120\n" +
+	"\asummary\x18\x03 \x01(\v2\x16.worker.v1.MemoryEntryR\asummary\x12\x1f\n" +
+	"\vtokens_used\x18\x04 \x01(\x05R\n" +
+	"tokensUsed\x12\x1d\n" +
+	"\n" +
+	"model_used\x18\x05 \x01(\tR\tmodelUsed\x12#\n" +
+	"\rerror_message\x18\x06 \x01(\tR\ferrorMessage\"\xa0\x01\n" +
 	"\x10HeartbeatRequest\x12\x1b\n" +
 	"\tworker_id\x18\x01 \x01(\tR\bworkerId\x12!\n" +
 	"\factive_tasks\x18\x02 \x01(\x05R\vactiveTasks\x12&\n" +
@@ -788,7 +1076,7 @@ func file_worker_proto_rawDescGZIP() []byte {
 	return file_worker_proto_rawDescData
 }
 
-var file_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_worker_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_worker_proto_goTypes = []any{
 	(*WorkerMessage)(nil),     // 0: worker.v1.WorkerMessage
 	(*ServerMessage)(nil),     // 1: worker.v1.ServerMessage
@@ -797,24 +1085,29 @@ var file_worker_proto_goTypes = []any{
 	(*TaskRequest)(nil),       // 4: worker.v1.TaskRequest
 	(*TaskResponse)(nil),      // 5: worker.v1.TaskResponse
 	(*MemoryEntry)(nil),       // 6: worker.v1.MemoryEntry
-	(*HeartbeatRequest)(nil),  // 7: worker.v1.HeartbeatRequest
-	(*HeartbeatResponse)(nil), // 8: worker.v1.HeartbeatResponse
+	(*SummarizeRequest)(nil),  // 7: worker.v1.SummarizeRequest
+	(*SummarizeResponse)(nil), // 8: worker.v1.SummarizeResponse
+	(*HeartbeatRequest)(nil),  // 9: worker.v1.HeartbeatRequest
+	(*HeartbeatResponse)(nil), // 10: worker.v1.HeartbeatResponse
 }
 var file_worker_proto_depIdxs = []int32{
-	2, // 0: worker.v1.WorkerMessage.register:type_name -> worker.v1.RegisterWorker
-	5, // 1: worker.v1.WorkerMessage.task_response:type_name -> worker.v1.TaskResponse
-	3, // 2: worker.v1.ServerMessage.register_ack:type_name -> worker.v1.RegisterAck
-	4, // 3: worker.v1.ServerMessage.task_request:type_name -> worker.v1.TaskRequest
-	6, // 4: worker.v1.TaskResponse.new_memories:type_name -> worker.v1.MemoryEntry
-	0, // 5: worker.v1.WorkerService.TaskStream:input_type -> worker.v1.WorkerMessage
-	7, // 6: worker.v1.WorkerService.Heartbeat:input_type -> worker.v1.HeartbeatRequest
-	1, // 7: worker.v1.WorkerService.TaskStream:output_type -> worker.v1.ServerMessage
-	8, // 8: worker.v1.WorkerService.Heartbeat:output_type -> worker.v1.HeartbeatResponse
-	7, // [7:9] is the sub-list for method output_type
-	5, // [5:7] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	2,  // 0: worker.v1.WorkerMessage.register:type_name -> worker.v1.RegisterWorker
+	5,  // 1: worker.v1.WorkerMessage.task_response:type_name -> worker.v1.TaskResponse
+	8,  // 2: worker.v1.WorkerMessage.summarize_response:type_name -> worker.v1.SummarizeResponse
+	3,  // 3: worker.v1.ServerMessage.register_ack:type_name -> worker.v1.RegisterAck
+	4,  // 4: worker.v1.ServerMessage.task_request:type_name -> worker.v1.TaskRequest
+	7,  // 5: worker.v1.ServerMessage.summarize_request:type_name -> worker.v1.SummarizeRequest
+	6,  // 6: worker.v1.TaskResponse.new_memories:type_name -> worker.v1.MemoryEntry
+	6,  // 7: worker.v1.SummarizeResponse.summary:type_name -> worker.v1.MemoryEntry
+	0,  // 8: worker.v1.WorkerService.TaskStream:input_type -> worker.v1.WorkerMessage
+	9,  // 9: worker.v1.WorkerService.Heartbeat:input_type -> worker.v1.HeartbeatRequest
+	1,  // 10: worker.v1.WorkerService.TaskStream:output_type -> worker.v1.ServerMessage
+	10, // 11: worker.v1.WorkerService.Heartbeat:output_type -> worker.v1.HeartbeatResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
 }
 
 func init() { file_worker_proto_init() }
@@ -825,10 +1118,12 @@ func file_worker_proto_init() {
 	file_worker_proto_msgTypes[0].OneofWrappers = []any{
 		(*WorkerMessage_Register)(nil),
 		(*WorkerMessage_TaskResponse)(nil),
+		(*WorkerMessage_SummarizeResponse)(nil),
 	}
 	file_worker_proto_msgTypes[1].OneofWrappers = []any{
 		(*ServerMessage_RegisterAck)(nil),
 		(*ServerMessage_TaskRequest)(nil),
+		(*ServerMessage_SummarizeRequest)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -836,7 +1131,7 @@ func file_worker_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_worker_proto_rawDesc), len(file_worker_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   9,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
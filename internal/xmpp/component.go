@@ -21,6 +21,7 @@ type Component struct {
 	comp        *xmpp.Component
 	reconnectCh chan struct{}
 	cancel      context.CancelFunc
+	onConnect   []func(ctx context.Context)
 }
 
 // NewComponent creates a new XMPP component with the given handler.
@@ -59,6 +60,13 @@ func NewComponent(cfg config.XMPPConfig, handler *Handler) (*Component, error) {
 	return &Component{comp: comp, reconnectCh: reconnectCh}, nil
 }
 
+// OnConnect registers fn to run after every successful connect, e.g. to
+// rejoin rooms since presence does not survive a reconnect. Functions run in
+// the order they were registered. It must be called before Start.
+func (c *Component) OnConnect(fn func(ctx context.Context)) {
+	c.onConnect = append(c.onConnect, fn)
+}
+
 // Start runs the XMPP component with automatic reconnection.
 // It blocks until ctx is cancelled.
 func (c *Component) Start(ctx context.Context) error {
@@ -70,6 +78,9 @@ func (c *Component) Start(ctx context.Context) error {
 			slog.Error("XMPP component connect failed", "error", err)
 		} else {
 			slog.Info("XMPP component connected")
+			for _, fn := range c.onConnect {
+				fn(ctx)
+			}
 		}
 
 		// Wait for a disconnection event or shutdown signal.
@@ -0,0 +1,57 @@
+package xmpp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gosrc.io/xmpp/stanza"
+)
+
+const dedupKeyPrefix = "xmpp:dedup:"
+
+// Deduplicator remembers recently received message stanzas in Redis so one
+// the XMPP server redelivers is published only once. Only stanzas with an ID
+// are deduplicated: without one, a redelivery can't be told apart from the
+// sender repeating the same text.
+type Deduplicator struct {
+	client redis.Cmdable
+	window time.Duration
+}
+
+// NewDeduplicator creates a Deduplicator that treats a stanza seen again
+// within window as a duplicate. A window of zero or less disables it.
+func NewDeduplicator(client redis.Cmdable, window time.Duration) *Deduplicator {
+	return &Deduplicator{client: client, window: window}
+}
+
+// FirstSeen reports whether msg has not been seen within the window, and
+// marks it as seen.
+func (d *Deduplicator) FirstSeen(ctx context.Context, msg stanza.Message) (bool, error) {
+	if msg.Id == "" || d.window <= 0 {
+		return true, nil
+	}
+	return d.client.SetNX(ctx, dedupKey(msg), "1", d.window).Result()
+}
+
+// Forget clears msg so a redelivery is published again, for when publishing
+// it failed.
+func (d *Deduplicator) Forget(ctx context.Context, msg stanza.Message) error {
+	if msg.Id == "" || d.window <= 0 {
+		return nil
+	}
+	return d.client.Del(ctx, dedupKey(msg)).Err()
+}
+
+// dedupKey identifies a stanza by its ID, which senders choose, so the ID is
+// scoped to the sender and recipient.
+func dedupKey(msg stanza.Message) string {
+	h := sha256.New()
+	for _, p := range []string{msg.From, msg.To, msg.Id} {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return dedupKeyPrefix + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,99 @@
+package xmpp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"gosrc.io/xmpp/stanza"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// countingJS counts messages published through a Publisher.
+type countingJS struct {
+	jetstream.JetStream
+	published int
+}
+
+func (js *countingJS) PublishMsg(context.Context, *nats.Msg, ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	js.published++
+	return &jetstream.PubAck{}, nil
+}
+
+func newDedupHandler(t *testing.T) (*Handler, *countingJS, *miniredis.Miniredis) {
+	return newDedupHandlerWindow(t, time.Minute)
+}
+
+func newDedupHandlerWindow(t *testing.T, window time.Duration) (*Handler, *countingJS, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	js := &countingJS{}
+	h := NewHandler(inats.NewPublisher(js))
+	h.SetDeduplicator(NewDeduplicator(redis.NewClient(&redis.Options{Addr: mr.Addr()}), window))
+	return h, js, mr
+}
+
+func chatMessage(id, body string) stanza.Message {
+	return stanza.Message{
+		Attrs: stanza.Attrs{
+			Id:   id,
+			From: "alice@aiox.local/phone",
+			To:   "agent-1@agents.aiox.local",
+			Type: "chat",
+		},
+		Body: body,
+	}
+}
+
+func TestHandleMessage_DropsRedeliveredStanza(t *testing.T) {
+	h, js, mr := newDedupHandler(t)
+
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	assert.Equal(t, 1, js.published)
+
+	// A new stanza ID is a new message, even with the same body.
+	h.HandleMessage(nil, chatMessage("m2", "hello"))
+	assert.Equal(t, 2, js.published)
+
+	// Once the window passes, the ID is forgotten.
+	mr.FastForward(2 * time.Minute)
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	assert.Equal(t, 3, js.published)
+}
+
+func TestHandleMessage_DedupWithoutStanzaID(t *testing.T) {
+	h, js, mr := newDedupHandler(t)
+
+	// The sender may well say the same thing twice; without an ID there's no
+	// telling that from a redelivery, so nothing is dropped.
+	h.HandleMessage(nil, chatMessage("", "hello"))
+	h.HandleMessage(nil, chatMessage("", "hello"))
+	h.HandleMessage(nil, chatMessage("", "hello again"))
+	assert.Equal(t, 3, js.published)
+	assert.Empty(t, mr.Keys())
+}
+
+func TestHandleMessage_DedupZeroWindow(t *testing.T) {
+	h, js, mr := newDedupHandlerWindow(t, 0)
+
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	assert.Equal(t, 2, js.published)
+	assert.Empty(t, mr.Keys())
+}
+
+func TestHandleMessage_DedupFailsOpen(t *testing.T) {
+	h, js, mr := newDedupHandler(t)
+	mr.Close()
+
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	h.HandleMessage(nil, chatMessage("m1", "hello"))
+	assert.Equal(t, 2, js.published)
+}
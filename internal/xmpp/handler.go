@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"mime"
+	"net/url"
+	"path"
 	"strings"
 	"time"
 
@@ -12,11 +15,14 @@ import (
 	"gosrc.io/xmpp/stanza"
 
 	inats "github.com/aiox-platform/aiox/internal/nats"
+	"github.com/aiox-platform/aiox/internal/tracing"
 )
 
 // Handler processes incoming XMPP stanzas and bridges them to NATS.
 type Handler struct {
 	publisher *inats.Publisher
+	rooms     RoomDirectory
+	dedup     *Deduplicator
 }
 
 // NewHandler creates a new XMPP stanza handler.
@@ -24,6 +30,19 @@ func NewHandler(publisher *inats.Publisher) *Handler {
 	return &Handler{publisher: publisher}
 }
 
+// SetRooms enables groupchat messages from multi-user chat rooms bound to an
+// agent. Without it groupchat messages are ignored. It must be called before
+// the component connects.
+func (h *Handler) SetRooms(dir RoomDirectory) {
+	h.rooms = dir
+}
+
+// SetDeduplicator drops message stanzas already received within the
+// deduplicator's window. It must be called before the component connects.
+func (h *Handler) SetDeduplicator(d *Deduplicator) {
+	h.dedup = d
+}
+
 // HandleMessage processes incoming <message> stanzas and publishes them to NATS.
 func (h *Handler) HandleMessage(s xmpp.Sender, p stanza.Packet) {
 	msg, ok := p.(stanza.Message)
@@ -31,7 +50,8 @@ func (h *Handler) HandleMessage(s xmpp.Sender, p stanza.Packet) {
 		return
 	}
 
-	if msg.Body == "" {
+	attachments := parseAttachments(msg)
+	if msg.Body == "" && len(attachments) == 0 {
 		return
 	}
 
@@ -41,21 +61,65 @@ func (h *Handler) HandleMessage(s xmpp.Sender, p stanza.Packet) {
 		"type", string(msg.Type),
 	)
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A redelivered stanza would otherwise be answered twice. Redis errors
+	// let the message through.
+	if h.dedup != nil {
+		first, err := h.dedup.FirstSeen(ctx, msg)
+		if err != nil {
+			slog.Warn("checking XMPP message for duplicates", "error", err, "from", msg.From)
+		} else if !first {
+			slog.Debug("dropping duplicate XMPP message", "from", msg.From, "stanza_id", msg.Id)
+			return
+		}
+	}
+
+	id := uuid.New().String()
+	ctx, span := tracing.Start(ctx, "xmpp.receive", tracing.AttrRequestID.String(id))
+	defer span.End()
+
 	inbound := inats.InboundMessage{
-		ID:         uuid.New().String(),
-		FromJID:    msg.From,
-		ToJID:      msg.To,
-		Body:       msg.Body,
-		StanzaType: string(msg.Type),
-		ReceivedAt: time.Now().UTC(),
+		ID:          id,
+		FromJID:     msg.From,
+		ToJID:       msg.To,
+		Body:        msg.Body,
+		StanzaType:  string(msg.Type),
+		ReceivedAt:  time.Now().UTC(),
+		TraceParent: tracing.TraceParent(ctx),
+		StanzaID:    msg.Id,
+		Attachments: attachments,
+	}
+	// Receipts are not sent in rooms (XEP-0184 §5.3); the orchestrator
+	// acknowledges the message once it has been accepted.
+	if msg.Type != "groupchat" {
+		inbound.ReceiptRequested = msg.Get(&stanza.ReceiptRequest{})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// Room messages are addressed to the agent's occupant; route them to the
+	// bound agent and answer in the room.
+	if msg.Type == "groupchat" {
+		binding, nickname := h.roomMessage(ctx, msg)
+		if binding == nil {
+			return
+		}
+		inbound.ToJID = binding.AgentJID
+		inbound.RoomJID = binding.RoomJID
+		inbound.Nickname = nickname
+	}
 
 	if err := h.publisher.PublishInboundMessage(ctx, inbound); err != nil {
+		span.RecordError(err)
 		slog.Error("publishing inbound message", "error", err, "from", msg.From)
-		h.sendError(s, msg.From, msg.To, "Internal error processing your message")
+		if h.dedup != nil {
+			if err := h.dedup.Forget(ctx, msg); err != nil {
+				slog.Warn("clearing XMPP message from duplicate check", "error", err, "from", msg.From)
+			}
+		}
+		if inbound.RoomJID == "" {
+			h.sendError(s, msg.From, msg.To, "Internal error processing your message")
+		}
 		return
 	}
 }
@@ -73,6 +137,10 @@ func (h *Handler) HandlePresence(s xmpp.Sender, p stanza.Packet) {
 		"type", string(pres.Type),
 	)
 
+	if h.handleRoomPresence(pres) {
+		return
+	}
+
 	if pres.Type == "subscribe" {
 		reply := stanza.Presence{
 			Attrs: stanza.Attrs{
@@ -96,7 +164,9 @@ func (h *Handler) HandleIQ(_ xmpp.Sender, p stanza.Packet) {
 	slog.Debug("XMPP IQ received", "from", iq.From, "to", iq.To, "type", string(iq.Type))
 }
 
-// SendOutboundMessage sends a <message> stanza via XMPP.
+// SendOutboundMessage sends a <message> stanza via XMPP. Messages for a room
+// are sent to the room as groupchat from the agent's occupant. Chat states and
+// receipts are added as extensions; a message may carry only those.
 func (h *Handler) SendOutboundMessage(s xmpp.Sender, outbound inats.OutboundMessage) error {
 	msg := stanza.Message{
 		Attrs: stanza.Attrs{
@@ -107,9 +177,73 @@ func (h *Handler) SendOutboundMessage(s xmpp.Sender, outbound inats.OutboundMess
 		},
 		Body: outbound.Body,
 	}
+	if outbound.RoomJID != "" {
+		msg.From = outbound.FromJID + "/" + agentResource
+		msg.To = outbound.RoomJID
+		msg.Type = "groupchat"
+	}
+	for _, a := range outbound.Attachments {
+		msg.Extensions = append(msg.Extensions, stanza.OOB{URL: a.URL, Desc: a.Description})
+	}
+	// Clients render a link as media when the body is just its URL
+	if msg.Body == "" && len(outbound.Attachments) > 0 {
+		msg.Body = outbound.Attachments[0].URL
+	}
+	if outbound.ReceiptID != "" {
+		msg.Extensions = append(msg.Extensions, stanza.ReceiptReceived{ID: outbound.ReceiptID})
+	}
+	if state := chatState(outbound.ChatState); state != nil {
+		msg.Extensions = append(msg.Extensions, state)
+	}
 	return s.Send(msg)
 }
 
+// parseAttachments collects the out-of-band data (XEP-0066) of a message,
+// dropping invalid URLs and any beyond the attachment limit. The MIME type is
+// guessed from the URL's file extension.
+func parseAttachments(msg stanza.Message) []inats.Attachment {
+	var attachments []inats.Attachment
+	for _, ext := range msg.Extensions {
+		var oob stanza.OOB
+		switch e := ext.(type) {
+		case stanza.OOB:
+			oob = e
+		case *stanza.OOB:
+			oob = *e
+		default:
+			continue
+		}
+		attachments = append(attachments, inats.Attachment{
+			URL:         strings.TrimSpace(oob.URL),
+			Description: oob.Desc,
+		})
+	}
+
+	valid, dropped := inats.ValidAttachments(attachments)
+	if dropped > 0 {
+		slog.Warn("dropped invalid or excess attachments", "from", msg.From, "dropped", dropped)
+	}
+	for i := range valid {
+		if u, err := url.Parse(valid[i].URL); err == nil {
+			valid[i].MimeType = mime.TypeByExtension(path.Ext(u.Path))
+		}
+	}
+	return valid
+}
+
+// chatState returns the XEP-0085 element for a chat state, or nil.
+func chatState(state string) stanza.MsgExtension {
+	switch state {
+	case inats.ChatStateActive:
+		return stanza.StateActive{}
+	case inats.ChatStateComposing:
+		return stanza.StateComposing{}
+	case inats.ChatStatePaused:
+		return stanza.StatePaused{}
+	}
+	return nil
+}
+
 func (h *Handler) sendError(s xmpp.Sender, to, from, body string) {
 	msg := stanza.Message{
 		Attrs: stanza.Attrs{
@@ -150,3 +284,20 @@ func ExtractAgentID(jid string) (uuid.UUID, error) {
 	}
 	return id, nil
 }
+
+// WebSocketUserID parses the platform user from a JID synthesized for
+// WebSocket chat, like "user-<uuid>@ws.domain". Those addresses exist only
+// on the API's WebSocket connections, never on the XMPP server.
+func WebSocketUserID(jid string) (uuid.UUID, bool) {
+	local, domain, _ := strings.Cut(jid, "@")
+	domain, _, _ = strings.Cut(domain, "/")
+	id, ok := strings.CutPrefix(local, "user-")
+	if !ok || !strings.HasPrefix(domain, "ws.") {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -66,3 +66,15 @@ func TestExtractAgentID(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitOccupantJID(t *testing.T) {
+	room, nick, ok := SplitOccupantJID("Lobby@conference.aiox.local/Alice Smith")
+	require.True(t, ok)
+	assert.Equal(t, "lobby@conference.aiox.local", room)
+	assert.Equal(t, "Alice Smith", nick)
+
+	for _, jid := range []string{"lobby@conference.aiox.local", "lobby@conference.aiox.local/", "/alice", ""} {
+		_, _, ok := SplitOccupantJID(jid)
+		assert.False(t, ok, jid)
+	}
+}
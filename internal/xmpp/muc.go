@@ -0,0 +1,151 @@
+package xmpp
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gosrc.io/xmpp"
+	"gosrc.io/xmpp/stanza"
+
+	"github.com/aiox-platform/aiox/internal/rooms"
+)
+
+// agentResource is the resource agents join multi-user chat rooms from. Room
+// services route groupchat messages to this full JID.
+const agentResource = "aiox"
+
+// RoomDirectory resolves the agent bound to a multi-user chat room.
+type RoomDirectory interface {
+	GetByRoom(ctx context.Context, roomJID string) (*rooms.Binding, error)
+}
+
+// mucJoin is the <x xmlns='http://jabber.org/protocol/muc'/> element of a
+// join presence (XEP-0045 §7.2). Room history is declined so an agent does
+// not answer messages sent before it joined.
+type mucJoin struct {
+	XMLName xml.Name   `xml:"http://jabber.org/protocol/muc x"`
+	History mucHistory `xml:"history"`
+}
+
+type mucHistory struct {
+	MaxStanzas int `xml:"maxstanzas,attr"`
+}
+
+// RoomJoiner sends the presence that makes agents enter and leave rooms.
+type RoomJoiner struct {
+	sender xmpp.Sender
+}
+
+// NewRoomJoiner creates a RoomJoiner sending through the component.
+func NewRoomJoiner(sender xmpp.Sender) *RoomJoiner {
+	return &RoomJoiner{sender: sender}
+}
+
+// JoinRoom enters roomJID as nickname on behalf of the agent.
+func (j *RoomJoiner) JoinRoom(roomJID, nickname, agentJID string) error {
+	pres := stanza.Presence{
+		Attrs: stanza.Attrs{
+			From: agentJID + "/" + agentResource,
+			To:   roomJID + "/" + nickname,
+		},
+		Extensions: []stanza.PresExtension{mucJoin{}},
+	}
+	if err := j.sender.Send(pres); err != nil {
+		return fmt.Errorf("sending join presence: %w", err)
+	}
+	return nil
+}
+
+// LeaveRoom exits roomJID on behalf of the agent.
+func (j *RoomJoiner) LeaveRoom(roomJID, nickname, agentJID string) error {
+	pres := stanza.Presence{
+		Attrs: stanza.Attrs{
+			From: agentJID + "/" + agentResource,
+			To:   roomJID + "/" + nickname,
+			Type: "unavailable",
+		},
+	}
+	if err := j.sender.Send(pres); err != nil {
+		return fmt.Errorf("sending leave presence: %w", err)
+	}
+	return nil
+}
+
+// SplitOccupantJID splits an occupant JID like "room@conference.example.com/nick"
+// into the room's bare JID and the occupant's nickname. ok is false for JIDs
+// without a nickname, such as messages from the room itself.
+func SplitOccupantJID(jid string) (room, nickname string, ok bool) {
+	room, nickname, ok = strings.Cut(jid, "/")
+	if !ok || room == "" || nickname == "" {
+		return "", "", false
+	}
+	return strings.ToLower(room), nickname, true
+}
+
+// roomMessage resolves a groupchat message to the bound agent. It returns nil
+// for messages the agent should not answer: rooms without a binding, the
+// agent's own messages echoed back, and messages that don't mention it.
+func (h *Handler) roomMessage(ctx context.Context, msg stanza.Message) (*rooms.Binding, string) {
+	room, nickname, ok := SplitOccupantJID(msg.From)
+	if !ok || h.rooms == nil {
+		return nil, ""
+	}
+
+	binding, err := h.rooms.GetByRoom(ctx, room)
+	if err != nil {
+		slog.Error("looking up room binding", "error", err, "room", room)
+		return nil, ""
+	}
+	if binding == nil {
+		slog.Debug("groupchat message for unbound room", "room", room)
+		return nil, ""
+	}
+	if strings.EqualFold(nickname, binding.Nickname) {
+		return nil, ""
+	}
+	if !rooms.Mentions(msg.Body, binding.Nickname) {
+		return nil, ""
+	}
+	return binding, nickname
+}
+
+// handleRoomPresence handles presence from a bound room and reports whether
+// pres came from one. Other occupants' presence is ignored. For the agent's
+// own occupant, join failures (e.g. a nickname conflict or a members-only
+// room) arrive as error presence, and being kicked or the room closing as
+// unavailable presence.
+func (h *Handler) handleRoomPresence(pres stanza.Presence) bool {
+	room, nickname, ok := SplitOccupantJID(pres.From)
+	if !ok || h.rooms == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	binding, err := h.rooms.GetByRoom(ctx, room)
+	if err != nil {
+		slog.Error("looking up room binding", "error", err, "room", room)
+		return false
+	}
+	if binding == nil {
+		return false
+	}
+	if !strings.EqualFold(nickname, binding.Nickname) {
+		return true
+	}
+
+	switch pres.Type {
+	case "error":
+		slog.Warn("joining room failed", "room", room, "nickname", nickname, "agent_id", binding.AgentID,
+			"error", pres.Error.Reason)
+	case "unavailable":
+		slog.Warn("agent left room", "room", room, "nickname", nickname, "agent_id", binding.AgentID)
+	default:
+		slog.Info("agent joined room", "room", room, "nickname", nickname, "agent_id", binding.AgentID)
+	}
+	return true
+}
@@ -2,32 +2,88 @@ package xmpp
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go/jetstream"
 	"gosrc.io/xmpp"
 
 	inats "github.com/aiox-platform/aiox/internal/nats"
 )
 
+const (
+	// DefaultOutboundMaxAttempts is how many sends of a message fail before
+	// it is dead-lettered when no limit is configured.
+	DefaultOutboundMaxAttempts = 5
+	// OutboundRetryDelay is how long the relay waits after a failed send
+	// before retrying, unless the component reconnects first.
+	OutboundRetryDelay = 10 * time.Second
+
+	outboundDrainBatch = 100
+	// deliveredRetention is how long delivered messages are kept.
+	deliveredRetention = 24 * time.Hour
+	pruneInterval      = time.Hour
+)
+
 // OutboundRelay consumes outbound messages from NATS and sends them via XMPP.
+// Messages are stored before they are acknowledged on NATS and stay pending
+// until a send succeeds, so nothing is lost while the component is
+// disconnected.
 type OutboundRelay struct {
-	handler    *Handler
-	sender     xmpp.Sender
+	handler     *Handler
+	sender      xmpp.Sender
 	consumerMgr *inats.ConsumerManager
+	store       OutboundStore
+	publisher   *inats.Publisher
+	maxAttempts int
+
+	// retryAt is the time, in Unix nanoseconds, before which pending
+	// messages are not retried after a failed send.
+	retryAt atomic.Int64
+	// backlog is set while the store may hold pending messages.
+	backlog   bool
+	lastPrune time.Time
 }
 
-// NewOutboundRelay creates a new OutboundRelay.
-func NewOutboundRelay(handler *Handler, sender xmpp.Sender, consumerMgr *inats.ConsumerManager) *OutboundRelay {
+// NewOutboundRelay creates a new OutboundRelay that keeps undelivered
+// messages in store and records an audit event through publisher for each
+// message it gives up on.
+func NewOutboundRelay(
+	handler *Handler,
+	sender xmpp.Sender,
+	consumerMgr *inats.ConsumerManager,
+	store OutboundStore,
+	publisher *inats.Publisher,
+) *OutboundRelay {
 	return &OutboundRelay{
-		handler:    handler,
-		sender:     sender,
+		handler:     handler,
+		sender:      sender,
 		consumerMgr: consumerMgr,
+		store:       store,
+		publisher:   publisher,
+		maxAttempts: DefaultOutboundMaxAttempts,
 	}
 }
 
-// Start begins consuming outbound messages and sending them via XMPP.
+// SetMaxAttempts sets how many sends of a message may fail before it is
+// dead-lettered. It must be called before Start.
+func (r *OutboundRelay) SetMaxAttempts(n int) {
+	if n > 0 {
+		r.maxAttempts = n
+	}
+}
+
+// Reconnected retries pending messages without waiting for the retry delay.
+// Register it with Component.OnConnect.
+func (r *OutboundRelay) Reconnected(context.Context) {
+	r.retryAt.Store(0)
+}
+
+// Start sends the messages left pending by a previous run, then consumes
+// outbound messages and sends them via XMPP.
 func (r *OutboundRelay) Start(ctx context.Context) error {
 	consumer, err := r.consumerMgr.EnsureConsumer(ctx, inats.StreamMessages, "outbound-relay", inats.SubjectOutboundMessage)
 	if err != nil {
@@ -36,7 +92,11 @@ func (r *OutboundRelay) Start(ctx context.Context) error {
 
 	slog.Info("outbound relay started", "consumer", "outbound-relay")
 
+	r.backlog = true
 	for {
+		r.drain(ctx, time.Now())
+		r.prune(ctx, time.Now())
+
 		msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(inats.FetchTimeout))
 		if err != nil {
 			if ctx.Err() != nil {
@@ -47,21 +107,7 @@ func (r *OutboundRelay) Start(ctx context.Context) error {
 		}
 
 		for msg := range msgs.Messages() {
-			var outbound inats.OutboundMessage
-			if err := json.Unmarshal(msg.Data(), &outbound); err != nil {
-				slog.Error("unmarshaling outbound message", "error", err)
-				_ = msg.Nak()
-				continue
-			}
-
-			if err := r.handler.SendOutboundMessage(r.sender, outbound); err != nil {
-				slog.Error("sending outbound XMPP message", "error", err, "to", outbound.ToJID)
-				_ = msg.Nak()
-				continue
-			}
-
-			slog.Debug("sent outbound XMPP message", "to", outbound.ToJID, "from", outbound.FromJID)
-			_ = msg.Ack()
+			r.receive(ctx, msg)
 		}
 
 		if ctx.Err() != nil {
@@ -69,3 +115,152 @@ func (r *OutboundRelay) Start(ctx context.Context) error {
 		}
 	}
 }
+
+// receive stores msg, acknowledges it, and sends it straight away unless
+// earlier messages are still waiting, in which case they are sent first.
+// Messages for WebSocket chat users are acknowledged and skipped.
+func (r *OutboundRelay) receive(ctx context.Context, msg jetstream.Msg) {
+	var outbound inats.OutboundMessage
+	if err := inats.Decode(msg.Headers(), msg.Data(), &outbound); err != nil {
+		slog.Error("unmarshaling outbound message", "error", err)
+		_ = r.consumerMgr.Nak(msg)
+		return
+	}
+	// Replies to WebSocket chat reach their client through the API's own
+	// subscription; the XMPP server has no such domain.
+	if _, ok := WebSocketUserID(outbound.ToJID); ok {
+		_ = msg.Ack()
+		return
+	}
+	if outbound.ID == "" {
+		outbound.ID = deliveryID(msg)
+	}
+
+	if err := r.store.Save(ctx, outbound); err != nil {
+		slog.Error("storing outbound message", "error", err, "to", outbound.ToJID)
+		_ = r.consumerMgr.Nak(msg)
+		return
+	}
+	_ = msg.Ack()
+
+	now := time.Now()
+	if r.backlog || now.UnixNano() < r.retryAt.Load() {
+		r.backlog = true
+		r.drain(ctx, now)
+		return
+	}
+	if !r.deliver(ctx, PendingOutbound{Message: outbound}) {
+		r.backlog = true
+	}
+}
+
+// deliveryID identifies a message published without an ID by its stream
+// sequence, which a redelivery keeps, so it is still stored only once.
+func deliveryID(msg jetstream.Msg) string {
+	meta, err := msg.Metadata()
+	if err != nil {
+		slog.Warn("reading outbound message metadata", "error", err)
+		return uuid.New().String()
+	}
+	return fmt.Sprintf("%s-%d", meta.Stream, meta.Sequence.Stream)
+}
+
+// drain sends pending messages oldest first. A failed send stops the drain
+// until OutboundRetryDelay passes or the component reconnects, so later
+// messages are not sent ahead of it.
+func (r *OutboundRelay) drain(ctx context.Context, now time.Time) {
+	if !r.backlog || now.UnixNano() < r.retryAt.Load() {
+		return
+	}
+	for ctx.Err() == nil {
+		pending, err := r.store.ListPending(ctx, outboundDrainBatch)
+		if err != nil {
+			slog.Error("listing pending outbound messages", "error", err)
+			return
+		}
+		for _, p := range pending {
+			if !r.deliver(ctx, p) {
+				return
+			}
+		}
+		if len(pending) < outboundDrainBatch {
+			r.backlog = false
+			return
+		}
+	}
+}
+
+// deliver sends one pending message and reports whether the drain may go on
+// to the next.
+func (r *OutboundRelay) deliver(ctx context.Context, p PendingOutbound) bool {
+	msg := p.Message
+	sendErr := r.handler.SendOutboundMessage(r.sender, msg)
+	if sendErr == nil {
+		// A message whose flag cannot be set is sent again on the next drain.
+		if err := r.store.MarkDelivered(ctx, msg.ID); err != nil {
+			slog.Error("marking outbound message delivered", "error", err, "id", msg.ID)
+		}
+		slog.Debug("sent outbound XMPP message", "to", msg.ToJID, "from", msg.FromJID)
+		return true
+	}
+
+	attempts, err := r.store.RecordFailure(ctx, msg.ID, sendErr.Error())
+	if err != nil {
+		slog.Error("recording outbound message failure", "error", err, "id", msg.ID)
+		attempts = p.Attempts + 1
+	}
+	slog.Warn("sending outbound XMPP message", "error", sendErr, "to", msg.ToJID, "attempt", attempts)
+
+	if attempts >= r.maxAttempts {
+		r.deadLetter(ctx, msg, attempts, sendErr)
+		return true
+	}
+	r.retryAt.Store(time.Now().Add(OutboundRetryDelay).UnixNano())
+	return false
+}
+
+// deadLetter gives up on msg and records a message_dead_lettered audit event
+// for the sending agent's owner.
+func (r *OutboundRelay) deadLetter(ctx context.Context, msg inats.OutboundMessage, attempts int, sendErr error) {
+	slog.Error("giving up on outbound XMPP message", "id", msg.ID, "to", msg.ToJID, "attempts", attempts)
+
+	agentID, ownerUserID, err := r.store.MarkDeadLettered(ctx, msg.ID)
+	if err != nil {
+		slog.Error("dead-lettering outbound message", "error", err, "id", msg.ID)
+		return
+	}
+	if ownerUserID == uuid.Nil || r.publisher == nil {
+		return
+	}
+
+	audit := inats.AuditEvent{
+		OwnerUserID:  ownerUserID,
+		EventType:    "message_dead_lettered",
+		Severity:     "error",
+		ResourceType: "agent",
+		ResourceID:   agentID.String(),
+		Details:      fmt.Sprintf("Message %s to %s not delivered after %d attempts: %v", msg.ID, msg.ToJID, attempts, sendErr),
+		Timestamp:    time.Now().UTC(),
+	}
+	if err := r.publisher.PublishAuditEvent(ctx, audit); err != nil {
+		slog.Error("publishing audit event", "error", err)
+	}
+}
+
+// prune deletes delivered messages past their retention, at most once per
+// pruneInterval.
+func (r *OutboundRelay) prune(ctx context.Context, now time.Time) {
+	if now.Sub(r.lastPrune) < pruneInterval {
+		return
+	}
+	r.lastPrune = now
+
+	n, err := r.store.DeleteDelivered(ctx, now.Add(-deliveredRetention))
+	if err != nil {
+		slog.Error("pruning delivered outbound messages", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Debug("pruned delivered outbound messages", "count", n)
+	}
+}
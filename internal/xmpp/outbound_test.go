@@ -0,0 +1,226 @@
+package xmpp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gosrc.io/xmpp"
+	"gosrc.io/xmpp/stanza"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// fakeSender records sent stanzas, or fails while down is set.
+type fakeSender struct {
+	xmpp.Sender
+	down bool
+	sent []string
+}
+
+func (s *fakeSender) Send(p stanza.Packet) error {
+	if s.down {
+		return errors.New("component is not connected")
+	}
+	s.sent = append(s.sent, p.(stanza.Message).Id)
+	return nil
+}
+
+type storedOutbound struct {
+	PendingOutbound
+	delivered, dead bool
+}
+
+// memoryOutboundStore is an OutboundStore kept in insertion order.
+type memoryOutboundStore struct {
+	msgs  []*storedOutbound
+	owner uuid.UUID
+}
+
+func (s *memoryOutboundStore) find(id string) *storedOutbound {
+	for _, m := range s.msgs {
+		if m.Message.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (s *memoryOutboundStore) Save(_ context.Context, msg inats.OutboundMessage) error {
+	if s.find(msg.ID) == nil {
+		s.msgs = append(s.msgs, &storedOutbound{PendingOutbound: PendingOutbound{Message: msg}})
+	}
+	return nil
+}
+
+func (s *memoryOutboundStore) ListPending(_ context.Context, limit int) ([]PendingOutbound, error) {
+	var pending []PendingOutbound
+	for _, m := range s.msgs {
+		if !m.delivered && !m.dead && len(pending) < limit {
+			pending = append(pending, m.PendingOutbound)
+		}
+	}
+	return pending, nil
+}
+
+func (s *memoryOutboundStore) MarkDelivered(_ context.Context, id string) error {
+	s.find(id).delivered = true
+	return nil
+}
+
+func (s *memoryOutboundStore) RecordFailure(_ context.Context, id, _ string) (int, error) {
+	m := s.find(id)
+	m.Attempts++
+	return m.Attempts, nil
+}
+
+func (s *memoryOutboundStore) MarkDeadLettered(_ context.Context, id string) (uuid.UUID, uuid.UUID, error) {
+	s.find(id).dead = true
+	return uuid.New(), s.owner, nil
+}
+
+func (s *memoryOutboundStore) DeleteDelivered(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestRelay(t *testing.T) (*OutboundRelay, *fakeSender, *memoryOutboundStore, *countingJS) {
+	t.Helper()
+	sender := &fakeSender{}
+	store := &memoryOutboundStore{owner: uuid.New()}
+	js := &countingJS{}
+	r := NewOutboundRelay(NewHandler(nil), sender, nil, store, inats.NewPublisher(js))
+	return r, sender, store, js
+}
+
+func enqueue(t *testing.T, r *OutboundRelay, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		require.NoError(t, r.store.Save(context.Background(), inats.OutboundMessage{ID: id, ToJID: "alice@aiox.local", Body: "hi"}))
+	}
+	r.backlog = true
+}
+
+func TestOutboundRelay_RetriesInOrderAfterReconnect(t *testing.T) {
+	r, sender, store, _ := newTestRelay(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	sender.down = true
+	enqueue(t, r, "m1", "m2")
+	r.drain(ctx, now)
+	assert.Empty(t, sender.sent)
+	assert.Equal(t, 1, store.msgs[0].Attempts)
+	assert.Zero(t, store.msgs[1].Attempts, "later messages wait behind the failed one")
+
+	// Still within the retry delay: nothing is attempted.
+	sender.down = false
+	r.drain(ctx, time.Now())
+	assert.Empty(t, sender.sent)
+
+	r.Reconnected(ctx)
+	r.drain(ctx, time.Now())
+	assert.Equal(t, []string{"m1", "m2"}, sender.sent)
+	assert.True(t, store.msgs[0].delivered)
+	assert.True(t, store.msgs[1].delivered)
+	assert.False(t, r.backlog)
+}
+
+func TestOutboundRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	r, sender, store, js := newTestRelay(t)
+	r.SetMaxAttempts(2)
+	ctx := context.Background()
+
+	sender.down = true
+	enqueue(t, r, "m1")
+	r.drain(ctx, time.Now())
+	assert.False(t, store.msgs[0].dead)
+	assert.Zero(t, js.published)
+
+	r.Reconnected(ctx)
+	r.drain(ctx, time.Now())
+	assert.True(t, store.msgs[0].dead)
+	assert.Equal(t, 2, store.msgs[0].Attempts)
+	assert.Equal(t, 1, js.published, "a message_dead_lettered audit event is published")
+
+	// Dead-lettered messages are not retried.
+	sender.down = false
+	r.Reconnected(ctx)
+	enqueue(t, r)
+	r.drain(ctx, time.Now())
+	assert.Empty(t, sender.sent)
+}
+
+// outboundDelivery is an outbound message as delivered by JetStream.
+type outboundDelivery struct {
+	jetstream.Msg
+	data  []byte
+	seq   uint64
+	acked bool
+}
+
+func newOutboundDelivery(t *testing.T, msg inats.OutboundMessage, seq uint64) *outboundDelivery {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	return &outboundDelivery{data: data, seq: seq}
+}
+
+func (m *outboundDelivery) Headers() nats.Header { return nil }
+func (m *outboundDelivery) Data() []byte         { return m.data }
+func (m *outboundDelivery) Ack() error           { m.acked = true; return nil }
+func (m *outboundDelivery) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Stream: inats.StreamMessages, Sequence: jetstream.SequencePair{Stream: m.seq}}, nil
+}
+
+func TestOutboundRelay_SendsOnReceipt(t *testing.T) {
+	r, sender, store, _ := newTestRelay(t)
+	ctx := context.Background()
+
+	delivery := newOutboundDelivery(t, inats.OutboundMessage{ID: "m1", ToJID: "alice@aiox.local", Body: "hi"}, 1)
+	r.receive(ctx, delivery)
+	assert.True(t, delivery.acked)
+	assert.Equal(t, []string{"m1"}, sender.sent, "sent without waiting for the next fetch")
+	assert.True(t, store.msgs[0].delivered)
+	assert.False(t, r.backlog)
+
+	// A failed send leaves the message queued, and later ones wait behind it.
+	sender.down = true
+	r.receive(ctx, newOutboundDelivery(t, inats.OutboundMessage{ID: "m2", ToJID: "alice@aiox.local"}, 2))
+	assert.True(t, r.backlog)
+	sender.down = false
+	r.receive(ctx, newOutboundDelivery(t, inats.OutboundMessage{ID: "m3", ToJID: "alice@aiox.local"}, 3))
+	assert.Equal(t, []string{"m1"}, sender.sent)
+
+	r.Reconnected(ctx)
+	r.receive(ctx, newOutboundDelivery(t, inats.OutboundMessage{ID: "m4", ToJID: "alice@aiox.local"}, 4))
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4"}, sender.sent)
+}
+
+func TestOutboundRelay_RedeliveryWithoutIDStoredOnce(t *testing.T) {
+	r, sender, store, _ := newTestRelay(t)
+	ctx := context.Background()
+	sender.down = true
+
+	msg := inats.OutboundMessage{ToJID: "alice@aiox.local", Body: "hi"}
+	r.receive(ctx, newOutboundDelivery(t, msg, 7))
+	r.receive(ctx, newOutboundDelivery(t, msg, 7))
+	require.Len(t, store.msgs, 1)
+	assert.Equal(t, inats.StreamMessages+"-7", store.msgs[0].Message.ID)
+}
+
+func TestOutboundRelay_SkipsWebSocketRecipients(t *testing.T) {
+	r, sender, store, _ := newTestRelay(t)
+
+	delivery := newOutboundDelivery(t, inats.OutboundMessage{ID: "m1", ToJID: "user-" + uuid.NewString() + "@ws.aiox.local", Body: "hi"}, 1)
+	r.receive(context.Background(), delivery)
+	assert.True(t, delivery.acked)
+	assert.Empty(t, store.msgs, "not stored")
+	assert.Empty(t, sender.sent, "not sent over XMPP")
+}
@@ -0,0 +1,151 @@
+package xmpp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	inats "github.com/aiox-platform/aiox/internal/nats"
+)
+
+// PendingOutbound is a stored outbound message that has not been delivered.
+type PendingOutbound struct {
+	Message  inats.OutboundMessage
+	Attempts int
+}
+
+// OutboundStore persists outbound messages until the XMPP component has
+// sent them.
+type OutboundStore interface {
+	// Save stores msg as pending. Saving a message that is already stored is
+	// a no-op, so a NATS redelivery is not sent twice.
+	Save(ctx context.Context, msg inats.OutboundMessage) error
+	// ListPending returns up to limit messages that are neither delivered nor
+	// dead-lettered, oldest first.
+	ListPending(ctx context.Context, limit int) ([]PendingOutbound, error)
+	MarkDelivered(ctx context.Context, id string) error
+	// RecordFailure counts a failed send and returns the attempts so far.
+	RecordFailure(ctx context.Context, id, reason string) (int, error)
+	// MarkDeadLettered gives up on a message and returns the agent that sent
+	// it and the agent's owner, or uuid.Nil when the agent is unknown.
+	MarkDeadLettered(ctx context.Context, id string) (agentID, ownerUserID uuid.UUID, err error)
+	// DeleteDelivered removes messages delivered before the given time.
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, error)
+}
+
+type postgresOutboundStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboundStore creates an OutboundStore backed by the outbound_messages
+// table.
+func NewOutboundStore(pool *pgxpool.Pool) OutboundStore {
+	return &postgresOutboundStore{pool: pool}
+}
+
+func (s *postgresOutboundStore) Save(ctx context.Context, msg inats.OutboundMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshaling outbound message: %w", err)
+	}
+	var agentID *uuid.UUID
+	if id, err := ExtractAgentID(msg.FromJID); err == nil {
+		agentID = &id
+	}
+
+	query := `
+		INSERT INTO outbound_messages (id, agent_id, message)
+		SELECT $1, a.id, $3
+		FROM (SELECT $2::uuid AS id) v
+		LEFT JOIN agents a ON a.id = v.id
+		ON CONFLICT (id) DO NOTHING`
+
+	if _, err := s.pool.Exec(ctx, query, msg.ID, agentID, data); err != nil {
+		return fmt.Errorf("inserting outbound message: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresOutboundStore) ListPending(ctx context.Context, limit int) ([]PendingOutbound, error) {
+	query := `
+		SELECT message, attempts
+		FROM outbound_messages
+		WHERE NOT delivered AND dead_lettered_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $1`
+
+	rows, err := s.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing pending outbound messages: %w", err)
+	}
+	defer rows.Close()
+
+	var pending []PendingOutbound
+	for rows.Next() {
+		var (
+			data []byte
+			p    PendingOutbound
+		)
+		if err := rows.Scan(&data, &p.Attempts); err != nil {
+			return nil, fmt.Errorf("scanning outbound message: %w", err)
+		}
+		if err := json.Unmarshal(data, &p.Message); err != nil {
+			return nil, fmt.Errorf("decoding outbound message: %w", err)
+		}
+		pending = append(pending, p)
+	}
+	return pending, rows.Err()
+}
+
+func (s *postgresOutboundStore) MarkDelivered(ctx context.Context, id string) error {
+	query := `UPDATE outbound_messages SET delivered = TRUE, delivered_at = NOW() WHERE id = $1`
+
+	if _, err := s.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("marking outbound message delivered: %w", err)
+	}
+	return nil
+}
+
+func (s *postgresOutboundStore) RecordFailure(ctx context.Context, id, reason string) (int, error) {
+	query := `
+		UPDATE outbound_messages SET attempts = attempts + 1, last_error = $2
+		WHERE id = $1
+		RETURNING attempts`
+
+	var attempts int
+	if err := s.pool.QueryRow(ctx, query, id, reason).Scan(&attempts); err != nil {
+		return 0, fmt.Errorf("recording outbound message failure: %w", err)
+	}
+	return attempts, nil
+}
+
+func (s *postgresOutboundStore) MarkDeadLettered(ctx context.Context, id string) (uuid.UUID, uuid.UUID, error) {
+	query := `
+		UPDATE outbound_messages o SET dead_lettered_at = NOW()
+		WHERE o.id = $1
+		RETURNING o.agent_id, (SELECT a.owner_user_id FROM agents a WHERE a.id = o.agent_id)`
+
+	var agentID, ownerUserID *uuid.UUID
+	err := s.pool.QueryRow(ctx, query, id).Scan(&agentID, &ownerUserID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, uuid.Nil, fmt.Errorf("dead-lettering outbound message: %w", err)
+	}
+	if agentID == nil || ownerUserID == nil {
+		return uuid.Nil, uuid.Nil, nil
+	}
+	return *agentID, *ownerUserID, nil
+}
+
+func (s *postgresOutboundStore) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM outbound_messages WHERE delivered AND delivered_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting delivered outbound messages: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
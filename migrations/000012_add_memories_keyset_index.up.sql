@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_agent_memories_keyset ON agent_memories (agent_id, created_at DESC, id DESC);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS agent_quotas (
+    agent_id UUID PRIMARY KEY REFERENCES agents(id) ON DELETE CASCADE,
+    owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tokens_used_today INT NOT NULL DEFAULT 0,
+    requests_today INT NOT NULL DEFAULT 0,
+    last_daily_reset TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_agent_quotas_owner ON agent_quotas (owner_user_id);
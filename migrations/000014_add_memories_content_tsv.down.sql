@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_agent_memories_content_tsv;
+ALTER TABLE agent_memories DROP COLUMN IF EXISTS content_tsv;
@@ -0,0 +1,6 @@
+-- 'simple' keeps names and identifiers intact (no stemming or stop words).
+ALTER TABLE agent_memories
+    ADD COLUMN IF NOT EXISTS content_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_agent_memories_content_tsv ON agent_memories USING GIN (content_tsv);
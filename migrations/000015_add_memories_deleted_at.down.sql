@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_agent_memories_deleted;
+DROP INDEX IF EXISTS idx_agent_memories_active;
+ALTER TABLE agent_memories DROP COLUMN IF EXISTS deleted_at;
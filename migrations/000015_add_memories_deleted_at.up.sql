@@ -0,0 +1,4 @@
+ALTER TABLE agent_memories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_agent_memories_active ON agent_memories (agent_id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_agent_memories_deleted ON agent_memories (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS agent_response_cache (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    fingerprint TEXT NOT NULL,
+    prompt_hash TEXT NOT NULL,
+    prompt TEXT NOT NULL,
+    response TEXT NOT NULL,
+    model_used TEXT NOT NULL DEFAULT '',
+    tokens_used INT NOT NULL DEFAULT 0,
+    embedding vector(384),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_agent_response_cache_lookup ON agent_response_cache (agent_id, fingerprint, prompt_hash);
+CREATE INDEX idx_agent_response_cache_embedding ON agent_response_cache USING ivfflat (embedding vector_cosine_ops) WITH (lists = 100);
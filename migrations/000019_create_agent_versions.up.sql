@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS agent_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    profile JSONB NOT NULL DEFAULT '{}'::jsonb,
+    llm_config JSONB NOT NULL DEFAULT '{}'::jsonb,
+    capabilities JSONB NOT NULL DEFAULT '{}'::jsonb,
+    memory_config JSONB NOT NULL DEFAULT '{}'::jsonb,
+    governance JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (agent_id, version)
+);
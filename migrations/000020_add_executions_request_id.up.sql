@@ -0,0 +1,3 @@
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS request_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_executions_request_id ON executions (request_id);
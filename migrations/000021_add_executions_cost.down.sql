@@ -0,0 +1,2 @@
+ALTER TABLE executions DROP COLUMN IF EXISTS cost_usd;
+ALTER TABLE executions DROP COLUMN IF EXISTS model;
@@ -0,0 +1,2 @@
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS model TEXT;
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS cost_usd NUMERIC(12, 6) NOT NULL DEFAULT 0;
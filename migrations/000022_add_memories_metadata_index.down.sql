@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_agent_memories_metadata;
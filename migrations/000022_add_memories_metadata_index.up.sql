@@ -0,0 +1,2 @@
+-- jsonb_path_ops supports the @> containment used by metadata filters.
+CREATE INDEX IF NOT EXISTS idx_agent_memories_metadata ON agent_memories USING GIN (metadata jsonb_path_ops);
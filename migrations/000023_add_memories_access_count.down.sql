@@ -0,0 +1 @@
+ALTER TABLE agent_memories DROP COLUMN IF EXISTS access_count;
@@ -0,0 +1,2 @@
+-- Incremented when a near-duplicate write is merged into an existing memory.
+ALTER TABLE agent_memories ADD COLUMN IF NOT EXISTS access_count INTEGER NOT NULL DEFAULT 0;
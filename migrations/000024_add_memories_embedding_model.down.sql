@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_agent_memories_embedding_384;
+-- Embeddings of any other dimension cannot be kept in a vector(384) column.
+UPDATE agent_memories SET embedding = NULL WHERE embedding IS NOT NULL AND embedding_dim <> 384;
+ALTER TABLE agent_memories ALTER COLUMN embedding TYPE vector(384);
+CREATE INDEX IF NOT EXISTS idx_agent_memories_embedding ON agent_memories USING ivfflat (embedding vector_cosine_ops) WITH (lists = 100);
+ALTER TABLE agent_memories DROP COLUMN IF EXISTS embedding_dim, DROP COLUMN IF EXISTS embedding_model;
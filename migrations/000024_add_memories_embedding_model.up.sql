@@ -0,0 +1,18 @@
+-- Embeddings are only comparable within the model that produced them, so each
+-- memory records its model and dimension and the column accepts any dimension.
+ALTER TABLE agent_memories
+    ADD COLUMN IF NOT EXISTS embedding_model TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS embedding_dim INTEGER NOT NULL DEFAULT 0;
+
+-- Existing embeddings all came from the worker's default model.
+UPDATE agent_memories
+SET embedding_model = 'sentence-transformers/all-MiniLM-L6-v2', embedding_dim = 384
+WHERE embedding IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_agent_memories_embedding;
+ALTER TABLE agent_memories ALTER COLUMN embedding TYPE vector;
+
+-- ivfflat needs a fixed dimension, so only the default 384-dim space is indexed.
+CREATE INDEX IF NOT EXISTS idx_agent_memories_embedding_384 ON agent_memories
+    USING ivfflat ((embedding::vector(384)) vector_cosine_ops) WITH (lists = 100)
+    WHERE embedding_dim = 384;
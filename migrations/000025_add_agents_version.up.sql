@@ -0,0 +1,2 @@
+-- Optimistic concurrency token, incremented on every update.
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Admins can reach operator endpoints (worker pool, all users' quotas, session revocation).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
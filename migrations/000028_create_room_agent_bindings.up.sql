@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS room_agent_bindings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- Bare JID of the XMPP multi-user chat room; one agent per room.
+    room_jid TEXT NOT NULL UNIQUE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Occupant nickname the agent joins the room with.
+    nickname TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_room_agent_bindings_agent ON room_agent_bindings (agent_id, created_at DESC);
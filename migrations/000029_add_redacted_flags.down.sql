@@ -0,0 +1,2 @@
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS redacted;
+ALTER TABLE executions DROP COLUMN IF EXISTS redacted;
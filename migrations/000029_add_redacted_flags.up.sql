@@ -0,0 +1,2 @@
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS redacted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS redacted BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+ALTER TABLE agent_memories DROP COLUMN IF EXISTS last_accessed_at;
+ALTER TABLE agent_memories DROP COLUMN IF EXISTS updated_at;
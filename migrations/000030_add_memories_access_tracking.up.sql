@@ -0,0 +1,5 @@
+-- updated_at changes when a near-duplicate write is merged into a memory;
+-- last_accessed_at is set when the memory is returned by a search.
+ALTER TABLE agent_memories ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE agent_memories ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMPTZ;
+UPDATE agent_memories SET updated_at = created_at;
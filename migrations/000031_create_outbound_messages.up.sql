@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS outbound_messages (
+    -- ID of the outbound NATS message, so a redelivery is stored once.
+    id TEXT PRIMARY KEY,
+    -- Sending agent, when the sender JID belongs to one; used for audit events.
+    agent_id UUID REFERENCES agents(id) ON DELETE CASCADE,
+    message JSONB NOT NULL,
+    delivered BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    -- Set when the relay gives up after the maximum number of attempts.
+    dead_lettered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbound_messages_pending ON outbound_messages (created_at, id)
+    WHERE NOT delivered AND dead_lettered_at IS NULL;
+CREATE INDEX idx_outbound_messages_delivered ON outbound_messages (delivered_at) WHERE delivered;
@@ -0,0 +1,3 @@
+-- Paused agents keep their configuration but receive no messages.
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('active', 'paused'));
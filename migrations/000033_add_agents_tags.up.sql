@@ -0,0 +1,3 @@
+-- Owner-defined labels for organizing agents, filtered with && and @>.
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_agents_tags ON agents USING GIN (tags) WHERE deleted_at IS NULL;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS retained_audit_logs;
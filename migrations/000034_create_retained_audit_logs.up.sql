@@ -0,0 +1,15 @@
+-- Audit events that must outlive the account they describe, such as the
+-- account's own deletion. No foreign key to users, unlike audit_logs.
+CREATE TABLE IF NOT EXISTS retained_audit_logs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_user_id UUID NOT NULL,
+    event_type TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'info',
+    resource_type TEXT,
+    resource_id UUID,
+    details JSONB DEFAULT '{}'::jsonb,
+    ip_address TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_retained_audit_logs_owner ON retained_audit_logs (owner_user_id, created_at DESC);
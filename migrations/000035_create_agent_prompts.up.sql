@@ -0,0 +1,11 @@
+-- Encrypted system prompts too large to keep inline in agents.profile. The
+-- profile's system_prompt_ref names the row; rows are never changed except to
+-- re-encrypt them, so agent versions can share them.
+CREATE TABLE IF NOT EXISTS agent_prompts (
+    id UUID PRIMARY KEY,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    prompt TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_prompts_agent_id ON agent_prompts(agent_id);
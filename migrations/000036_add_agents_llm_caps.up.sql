@@ -0,0 +1,2 @@
+-- Per-agent overrides of the deployment's LLM caps, set by admins only.
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS llm_caps JSONB;
@@ -0,0 +1,9 @@
+-- What was sent to the worker for executions run with debug context capture.
+-- The system prompt is kept encrypted in agent_prompts; request holds the
+-- rest of the task request as JSON.
+CREATE TABLE IF NOT EXISTS execution_contexts (
+    execution_id UUID PRIMARY KEY REFERENCES executions(id) ON DELETE CASCADE,
+    system_prompt_id UUID REFERENCES agent_prompts(id) ON DELETE SET NULL,
+    request JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -14,16 +14,16 @@ import (
 func TestAgentCRUD(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	RegisterUser(t, env, "agent-crud@example.com", "password123")
-	token := LoginUser(t, env, "agent-crud@example.com", "password123")
+	RegisterUser(t, env, "agent-crud@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-crud@example.com", "tangerine-kettle-42")
 
 	var agentID string
 
 	t.Run("create agent", func(t *testing.T) {
 		body := map[string]any{
-			"name":         "Test Agent",
-			"description":  "A test agent",
-			"system_prompt": "You are a helpful assistant.",
+			"name":               "Test Agent",
+			"description":        "A test agent",
+			"system_prompt":      "You are a helpful assistant.",
 			"personality_traits": []string{"helpful", "concise"},
 			"llm_config": map[string]any{
 				"provider":    "openai",
@@ -79,6 +79,7 @@ func TestAgentCRUD(t *testing.T) {
 			"name":          "Updated Agent",
 			"description":   "An updated test agent",
 			"system_prompt": "You are an updated assistant.",
+			"version":       1,
 		}
 
 		resp := DoRequest(t, env, "PUT", "/api/v1/agents/"+agentID, body, token)
@@ -115,8 +116,8 @@ func TestAgentCRUD(t *testing.T) {
 func TestAgentValidation(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	RegisterUser(t, env, "agent-val@example.com", "password123")
-	token := LoginUser(t, env, "agent-val@example.com", "password123")
+	RegisterUser(t, env, "agent-val@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-val@example.com", "tangerine-kettle-42")
 
 	t.Run("missing name", func(t *testing.T) {
 		body := map[string]any{
@@ -138,13 +139,25 @@ func TestAgentValidation(t *testing.T) {
 		resp := DoRequest(t, env, "GET", "/api/v1/agents/not-a-uuid", nil, token)
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
+
+	t.Run("provider not in governance allow-list", func(t *testing.T) {
+		body := map[string]any{
+			"name":          "Disallowed Provider Agent",
+			"system_prompt": "You are a helper.",
+			"llm_config":    map[string]any{"provider": "openai", "model": "gpt-4o"},
+			"governance":    map[string]any{"allowed_providers": []string{"anthropic"}},
+		}
+		resp := DoRequest(t, env, "POST", "/api/v1/agents", body, token)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, ParseResponse(t, resp)["error"], "openai")
+	})
 }
 
 func TestAgentJIDGeneration(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	RegisterUser(t, env, "agent-jid@example.com", "password123")
-	token := LoginUser(t, env, "agent-jid@example.com", "password123")
+	RegisterUser(t, env, "agent-jid@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-jid@example.com", "tangerine-kettle-42")
 
 	body := map[string]any{
 		"name":          "JID Agent",
@@ -166,8 +179,8 @@ func TestAgentJIDGeneration(t *testing.T) {
 func TestAgentSystemPromptEncryption(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	RegisterUser(t, env, "agent-enc@example.com", "password123")
-	token := LoginUser(t, env, "agent-enc@example.com", "password123")
+	RegisterUser(t, env, "agent-enc@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-enc@example.com", "tangerine-kettle-42")
 
 	body := map[string]any{
 		"name":          "Encrypted Agent",
@@ -204,3 +217,206 @@ func TestAgentSystemPromptEncryption(t *testing.T) {
 	profile := getData["profile"].(map[string]any)
 	assert.Equal(t, "Super secret prompt that should be encrypted", profile["system_prompt"])
 }
+
+func TestAgentVersions(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "agent-versions@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-versions@example.com", "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Versioned",
+		"system_prompt": "Original prompt.",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentID := ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+
+	resp = DoRequest(t, env, "PUT", "/api/v1/agents/"+agentID, map[string]any{
+		"name":          "Renamed",
+		"system_prompt": "Changed prompt.",
+		"version":       1,
+	}, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = DoRequest(t, env, "GET", "/api/v1/agents/"+agentID+"/versions", nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	result := ParseResponse(t, resp)
+	versions := result["data"].([]any)
+	require.Len(t, versions, 2)
+
+	latest := versions[0].(map[string]any)
+	original := versions[1].(map[string]any)
+	assert.Equal(t, float64(2), latest["version"])
+	assert.Equal(t, "Renamed", latest["profile"].(map[string]any)["name"])
+	assert.Equal(t, "Original prompt.", original["profile"].(map[string]any)["system_prompt"])
+
+	t.Run("rollback restores snapshot as new version", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", "/api/v1/agents/"+agentID+"/versions/"+original["id"].(string)+"/rollback", nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		profile := ParseResponse(t, resp)["data"].(map[string]any)["profile"].(map[string]any)
+		assert.Equal(t, "Versioned", profile["name"])
+		assert.Equal(t, "Original prompt.", profile["system_prompt"])
+
+		resp = DoRequest(t, env, "GET", "/api/v1/agents/"+agentID+"/versions", nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		versions := ParseResponse(t, resp)["data"].([]any)
+		require.Len(t, versions, 3)
+		assert.Equal(t, float64(3), versions[0].(map[string]any)["version"])
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", "/api/v1/agents/"+agentID+"/versions/00000000-0000-0000-0000-000000000000/rollback", nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("other user cannot read versions", func(t *testing.T) {
+		RegisterUser(t, env, "agent-versions-other@example.com", "tangerine-kettle-42")
+		other := LoginUser(t, env, "agent-versions-other@example.com", "tangerine-kettle-42")
+
+		resp := DoRequest(t, env, "GET", "/api/v1/agents/"+agentID+"/versions", nil, other)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp.Body.Close()
+	})
+}
+
+func TestAgentUpdateConcurrency(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "agent-occ@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-occ@example.com", "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Contended",
+		"system_prompt": "Original prompt.",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	data := ParseResponse(t, resp)["data"].(map[string]any)
+	agentID := data["id"].(string)
+	assert.Equal(t, float64(1), data["version"])
+
+	t.Run("missing version is rejected", func(t *testing.T) {
+		resp := DoRequest(t, env, "PUT", "/api/v1/agents/"+agentID, map[string]any{"name": "No Version"}, token)
+		assert.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("If-Match update bumps version", func(t *testing.T) {
+		resp := DoRequestWithHeaders(t, env, "PUT", "/api/v1/agents/"+agentID,
+			map[string]any{"name": "First Writer"}, token, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+		assert.Equal(t, float64(2), ParseResponse(t, resp)["data"].(map[string]any)["version"])
+	})
+
+	t.Run("stale version conflicts", func(t *testing.T) {
+		resp := DoRequest(t, env, "PUT", "/api/v1/agents/"+agentID,
+			map[string]any{"name": "Second Writer", "version": 1}, token)
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+		assert.Contains(t, ParseResponse(t, resp)["error"], "current version is 2")
+
+		resp = DoRequest(t, env, "GET", "/api/v1/agents/"+agentID, nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		profile := ParseResponse(t, resp)["data"].(map[string]any)["profile"].(map[string]any)
+		assert.Equal(t, "First Writer", profile["name"])
+	})
+}
+
+func TestPublicAgents(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "agent-public-owner@example.com", "tangerine-kettle-42")
+	owner := LoginUser(t, env, "agent-public-owner@example.com", "tangerine-kettle-42")
+	RegisterUser(t, env, "agent-public-viewer@example.com", "tangerine-kettle-42")
+	viewer := LoginUser(t, env, "agent-public-viewer@example.com", "tangerine-kettle-42")
+
+	create := func(name, visibility string) string {
+		resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+			"name":               name,
+			"description":        name + " description",
+			"system_prompt":      "Secret instructions for " + name,
+			"personality_traits": []string{"friendly"},
+			"visibility":         visibility,
+		}, owner)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		return ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+	}
+	publicID := create("Public Helper 100%", "public")
+	create("Private Helper", "private")
+	deletedID := create("Public Deleted", "public")
+	resp := DoRequest(t, env, "DELETE", "/api/v1/agents/"+deletedID, nil, owner)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	t.Run("lists only public, non-deleted agents without secrets", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", "/api/v1/agents/public?page_size=100", nil, viewer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := ParseResponse(t, resp)["data"].([]any)
+
+		var found map[string]any
+		for _, item := range items {
+			a := item.(map[string]any)
+			assert.NotEqual(t, deletedID, a["id"])
+			assert.NotEqual(t, "Private Helper", a["name"])
+			if a["id"] == publicID {
+				found = a
+			}
+		}
+		require.NotNil(t, found)
+		assert.Equal(t, "Public Helper 100% description", found["description"])
+		assert.NotEmpty(t, found["jid"])
+		assert.NotContains(t, found, "system_prompt")
+		assert.NotContains(t, found, "profile")
+		assert.NotContains(t, found, "owner_user_id")
+	})
+
+	t.Run("filters by name", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", "/api/v1/agents/public?q=helper%20100%25", nil, viewer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := ParseResponse(t, resp)["data"].([]any)
+		require.Len(t, items, 1)
+		assert.Equal(t, publicID, items[0].(map[string]any)["id"])
+
+		resp = DoRequest(t, env, "GET", "/api/v1/agents/public?q=helper%20_00", nil, viewer)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, ParseResponse(t, resp)["data"])
+	})
+}
+
+func TestAgentSearch(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "agent-search@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "agent-search@example.com", "tangerine-kettle-42")
+
+	for _, a := range []map[string]any{
+		{"name": "Billing Bot", "description": "Answers invoice questions", "system_prompt": "secret-marker"},
+		{"name": "Support Bot", "description": "Handles billing escalations", "system_prompt": "You help."},
+		{"name": "Travel Planner", "description": "Books trips", "system_prompt": "You plan."},
+	} {
+		resp := DoRequest(t, env, "POST", "/api/v1/agents", a, token)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	search := func(q string) map[string]any {
+		resp := DoRequest(t, env, "GET", "/api/v1/agents?q="+q, nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return ParseResponse(t, resp)
+	}
+
+	t.Run("matches name and description case-insensitively", func(t *testing.T) {
+		result := search("BILLING")
+		assert.Len(t, result["data"], 2)
+		assert.Equal(t, float64(2), result["total_count"])
+	})
+
+	t.Run("does not search system prompt", func(t *testing.T) {
+		assert.Empty(t, search("secret-marker")["data"])
+	})
+
+	t.Run("treats wildcards literally", func(t *testing.T) {
+		assert.Empty(t, search("%25")["data"])
+	})
+}
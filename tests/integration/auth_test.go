@@ -25,7 +25,7 @@ func TestRegister(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	t.Run("successful registration", func(t *testing.T) {
-		result := RegisterUser(t, env, "test-reg@example.com", "password123")
+		result := RegisterUser(t, env, "test-reg@example.com", "tangerine-kettle-42")
 		data := result["data"].(map[string]any)
 
 		assert.NotEmpty(t, data["access_token"])
@@ -34,15 +34,15 @@ func TestRegister(t *testing.T) {
 	})
 
 	t.Run("duplicate email", func(t *testing.T) {
-		RegisterUser(t, env, "dupe@example.com", "password123")
+		RegisterUser(t, env, "dupe@example.com", "tangerine-kettle-42")
 
-		body := map[string]string{"email": "dupe@example.com", "password": "password123"}
+		body := map[string]string{"email": "dupe@example.com", "password": "tangerine-kettle-42"}
 		resp := DoRequest(t, env, "POST", "/api/v1/auth/register", body, "")
 		assert.Equal(t, http.StatusConflict, resp.StatusCode)
 	})
 
 	t.Run("invalid email", func(t *testing.T) {
-		body := map[string]string{"email": "not-an-email", "password": "password123"}
+		body := map[string]string{"email": "not-an-email", "password": "tangerine-kettle-42"}
 		resp := DoRequest(t, env, "POST", "/api/v1/auth/register", body, "")
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
@@ -52,15 +52,25 @@ func TestRegister(t *testing.T) {
 		resp := DoRequest(t, env, "POST", "/api/v1/auth/register", body, "")
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
+
+	t.Run("common password", func(t *testing.T) {
+		body := map[string]string{"email": "common@example.com", "password": "password123"}
+		resp := DoRequest(t, env, "POST", "/api/v1/auth/register", body, "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		result := ParseResponse(t, resp)
+		assert.Equal(t, "WEAK_PASSWORD", result["code"])
+		assert.Equal(t, []any{"not a commonly used password"}, result["details"])
+	})
 }
 
 func TestLogin(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	RegisterUser(t, env, "login@example.com", "password123")
+	RegisterUser(t, env, "login@example.com", "tangerine-kettle-42")
 
 	t.Run("successful login", func(t *testing.T) {
-		body := map[string]string{"email": "login@example.com", "password": "password123"}
+		body := map[string]string{"email": "login@example.com", "password": "tangerine-kettle-42"}
 		resp := DoRequest(t, env, "POST", "/api/v1/auth/login", body, "")
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 
@@ -77,16 +87,33 @@ func TestLogin(t *testing.T) {
 	})
 
 	t.Run("non-existent user", func(t *testing.T) {
-		body := map[string]string{"email": "nobody@example.com", "password": "password123"}
+		body := map[string]string{"email": "nobody@example.com", "password": "tangerine-kettle-42"}
 		resp := DoRequest(t, env, "POST", "/api/v1/auth/login", body, "")
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
+
+	t.Run("locked after repeated failures", func(t *testing.T) {
+		RegisterUser(t, env, "lockout@example.com", "tangerine-kettle-42")
+		wrong := map[string]string{"email": "lockout@example.com", "password": "wrongpass"}
+		for i := 0; i < 4; i++ {
+			resp := DoRequest(t, env, "POST", "/api/v1/auth/login", wrong, "")
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		}
+		resp := DoRequest(t, env, "POST", "/api/v1/auth/login", wrong, "")
+		assert.Equal(t, http.StatusLocked, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+		right := map[string]string{"email": "lockout@example.com", "password": "tangerine-kettle-42"}
+		resp = DoRequest(t, env, "POST", "/api/v1/auth/login", right, "")
+		assert.Equal(t, http.StatusLocked, resp.StatusCode)
+		assert.Equal(t, "ACCOUNT_LOCKED", ParseResponse(t, resp)["code"])
+	})
 }
 
 func TestRefreshToken(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	result := RegisterUser(t, env, "refresh@example.com", "password123")
+	result := RegisterUser(t, env, "refresh@example.com", "tangerine-kettle-42")
 	data := result["data"].(map[string]any)
 	refreshToken := data["refresh_token"].(string)
 
@@ -118,8 +145,8 @@ func TestRefreshToken(t *testing.T) {
 func TestLogout(t *testing.T) {
 	env := SetupTestEnv(t)
 
-	RegisterUser(t, env, "logout@example.com", "password123")
-	token := LoginUser(t, env, "logout@example.com", "password123")
+	RegisterUser(t, env, "logout@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "logout@example.com", "tangerine-kettle-42")
 
 	t.Run("successful logout", func(t *testing.T) {
 		resp := DoRequest(t, env, "POST", "/api/v1/auth/logout", nil, token)
@@ -131,3 +158,86 @@ func TestLogout(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 	})
 }
+
+func TestPasswordReset(t *testing.T) {
+	env := SetupTestEnv(t)
+	RegisterUser(t, env, "reset@example.com", "tangerine-kettle-42")
+
+	t.Run("unknown email still returns 200", func(t *testing.T) {
+		body := map[string]string{"email": "nobody@example.com"}
+		resp := DoRequest(t, env, "POST", "/api/v1/auth/password-reset/request", body, "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, env.Mailer.ResetToken("nobody@example.com"))
+	})
+
+	t.Run("reset and login with new password", func(t *testing.T) {
+		body := map[string]string{"email": "reset@example.com"}
+		resp := DoRequest(t, env, "POST", "/api/v1/auth/password-reset/request", body, "")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		token := env.Mailer.ResetToken("reset@example.com")
+		require.NotEmpty(t, token)
+
+		confirm := map[string]string{"token": token, "new_password": "newpassword456"}
+		resp = DoRequest(t, env, "POST", "/api/v1/auth/password-reset/confirm", confirm, "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		assert.NotEmpty(t, LoginUser(t, env, "reset@example.com", "newpassword456"))
+
+		old := map[string]string{"email": "reset@example.com", "password": "tangerine-kettle-42"}
+		resp = DoRequest(t, env, "POST", "/api/v1/auth/login", old, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		// Tokens are single-use
+		resp = DoRequest(t, env, "POST", "/api/v1/auth/password-reset/confirm", confirm, "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestAuthRoutesRequireSession(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "apikey-session@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "apikey-session@example.com", "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/auth/api-keys/", map[string]any{"name": "ci", "scopes": []string{"agents:read"}}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	key := ParseResponse(t, resp)["data"].(map[string]any)["key"].(string)
+	withKey := map[string]string{"Authorization": "ApiKey " + key}
+
+	resp = DoRequestWithHeaders(t, env, "GET", "/api/v1/agents", nil, "", withKey)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/auth/logout"},
+		{"GET", "/api/v1/auth/sessions"},
+		{"POST", "/api/v1/auth/2fa/enroll"},
+		{"POST", "/api/v1/auth/2fa/disable"},
+		{"POST", "/api/v1/auth/api-keys/"},
+		{"GET", "/api/v1/auth/api-keys/"},
+	} {
+		resp := DoRequestWithHeaders(t, env, route.method, route.path, map[string]any{}, "", withKey)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, "%s %s", route.method, route.path)
+		resp.Body.Close()
+	}
+}
+
+func TestTwoFactorEnrollRequiresPassword(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "2fa-reauth@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "2fa-reauth@example.com", "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/auth/2fa/enroll", map[string]string{}, token)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = DoRequest(t, env, "POST", "/api/v1/auth/2fa/enroll", map[string]string{"password": "wrong-password-1"}, token)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = DoRequest(t, env, "POST", "/api/v1/auth/2fa/enroll", map[string]string{"password": "tangerine-kettle-42"}, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, ParseResponse(t, resp)["data"].(map[string]any)["secret"])
+}
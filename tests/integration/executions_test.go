@@ -0,0 +1,126 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/worker"
+)
+
+func TestAgentExecutions(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	email := fmt.Sprintf("executions-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Executed",
+		"system_prompt": "You are helpful.",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentData := ParseResponse(t, resp)["data"].(map[string]any)
+	agentID := uuid.MustParse(agentData["id"].(string))
+	ownerID := uuid.MustParse(agentData["owner_user_id"].(string))
+
+	repo := worker.NewRepository(env.Pool)
+	longInput := strings.Repeat("a", worker.ExecutionPreviewLength+50)
+	completed := &worker.Execution{
+		ID:          uuid.New(),
+		RequestID:   "req-completed",
+		OwnerUserID: ownerID,
+		AgentID:     agentID,
+		Input:       longInput,
+		Output:      "done",
+		TokensUsed:  12,
+		WorkerID:    "w1",
+		Status:      "completed",
+		CreatedAt:   time.Now().Add(-time.Minute),
+	}
+	failed := &worker.Execution{
+		ID:           uuid.New(),
+		RequestID:    "req-error",
+		OwnerUserID:  ownerID,
+		AgentID:      agentID,
+		Input:        "hi",
+		Status:       "error",
+		ErrorMessage: "boom",
+		CreatedAt:    time.Now(),
+	}
+	require.NoError(t, repo.RecordExecution(context.Background(), completed))
+	require.NoError(t, repo.RecordExecution(context.Background(), failed))
+
+	base := "/api/v1/agents/" + agentID.String() + "/executions"
+
+	t.Run("list truncates payloads", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base, nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := ParseResponse(t, resp)
+		items := result["data"].([]any)
+		require.Len(t, items, 2)
+
+		first := items[0].(map[string]any)
+		second := items[1].(map[string]any)
+		assert.Equal(t, "req-error", first["request_id"])
+		assert.Len(t, second["input"], worker.ExecutionPreviewLength)
+		assert.Equal(t, true, second["truncated"])
+	})
+
+	t.Run("filter by status", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base+"?status=completed", nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := ParseResponse(t, resp)["data"].([]any)
+		require.Len(t, items, 1)
+		assert.Equal(t, "req-completed", items[0].(map[string]any)["request_id"])
+	})
+
+	t.Run("filter by time range", func(t *testing.T) {
+		from := time.Now().Add(-30 * time.Second).UTC().Format(time.RFC3339)
+		resp := DoRequest(t, env, "GET", base+"?from="+from, nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		items := ParseResponse(t, resp)["data"].([]any)
+		require.Len(t, items, 1)
+		assert.Equal(t, "req-error", items[0].(map[string]any)["request_id"])
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base+"?status=pending", nil, token)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("detail returns full payload", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base+"/"+completed.ID.String(), nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		data := ParseResponse(t, resp)["data"].(map[string]any)
+		assert.Equal(t, longInput, data["input"])
+		assert.Equal(t, float64(12), data["tokens_used"])
+		assert.Equal(t, "w1", data["worker_id"])
+	})
+
+	t.Run("unknown execution", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base+"/"+uuid.New().String(), nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("other user cannot read executions", func(t *testing.T) {
+		otherEmail := fmt.Sprintf("executions-other-%d@test.com", uniqueID())
+		RegisterUser(t, env, otherEmail, "tangerine-kettle-42")
+		other := LoginUser(t, env, otherEmail, "tangerine-kettle-42")
+
+		resp := DoRequest(t, env, "GET", base, nil, other)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		resp.Body.Close()
+	})
+}
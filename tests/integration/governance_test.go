@@ -7,20 +7,22 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/aiox-platform/aiox/internal/governance/audit"
+	"github.com/aiox-platform/aiox/internal/worker"
 )
 
 func TestGovernance_Quota_API(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	email := fmt.Sprintf("govquota-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	// GET quota — should return defaults with zero usage
 	resp := DoRequest(t, env, "GET", "/api/v1/governance/quota", nil, token)
@@ -47,8 +49,8 @@ func TestGovernance_AuditLogs_Empty(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	email := fmt.Sprintf("govaudit-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	// GET audit logs — should be empty for new user
 	resp := DoRequest(t, env, "GET", "/api/v1/governance/audit", nil, token)
@@ -61,11 +63,11 @@ func TestGovernance_AuditLogs_WithPersistedEntries(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	email := fmt.Sprintf("govauditpersist-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	// Get user ID from login
-	loginResp := DoRequest(t, env, "POST", "/api/v1/auth/login", map[string]string{"email": email, "password": "password123"}, "")
+	loginResp := DoRequest(t, env, "POST", "/api/v1/auth/login", map[string]string{"email": email, "password": "tangerine-kettle-42"}, "")
 	loginResult := ParseResponse(t, loginResp)
 	loginData := loginResult["data"].(map[string]any)
 	token = loginData["access_token"].(string)
@@ -144,13 +146,13 @@ func TestGovernance_AuditLogs_OwnershipIsolation(t *testing.T) {
 
 	// User 1
 	email1 := fmt.Sprintf("goviso1-%d@test.com", uniqueID())
-	RegisterUser(t, env, email1, "password123")
-	token1 := LoginUser(t, env, email1, "password123")
+	RegisterUser(t, env, email1, "tangerine-kettle-42")
+	token1 := LoginUser(t, env, email1, "tangerine-kettle-42")
 
 	// User 2
 	email2 := fmt.Sprintf("goviso2-%d@test.com", uniqueID())
-	RegisterUser(t, env, email2, "password123")
-	token2 := LoginUser(t, env, email2, "password123")
+	RegisterUser(t, env, email2, "tangerine-kettle-42")
+	token2 := LoginUser(t, env, email2, "tangerine-kettle-42")
 
 	// Create agent for user 1
 	agentBody := map[string]any{
@@ -199,8 +201,8 @@ func TestGovernance_BlockedAgent(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	email := fmt.Sprintf("govblocked-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	// Create an agent with blocked governance
 	agentBody := map[string]any{
@@ -224,6 +226,7 @@ func TestGovernance_BlockedAgent(t *testing.T) {
 		"governance": map[string]any{
 			"blocked": false,
 		},
+		"version": 1,
 	}
 	agentID := agentData["id"].(string)
 	resp = DoRequest(t, env, "PUT", fmt.Sprintf("/api/v1/agents/%s", agentID), updateBody, token)
@@ -233,3 +236,91 @@ func TestGovernance_BlockedAgent(t *testing.T) {
 	updatedGov := updateData["governance"].(map[string]any)
 	assert.Equal(t, false, updatedGov["blocked"])
 }
+
+func TestGovernance_Cost_API(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	email := fmt.Sprintf("govcost-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Cost Agent",
+		"system_prompt": "You are helpful.",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentData := ParseResponse(t, resp)["data"].(map[string]any)
+	agentID := uuid.MustParse(agentData["id"].(string))
+	ownerID := uuid.MustParse(agentData["owner_user_id"].(string))
+
+	repo := worker.NewRepository(env.Pool)
+	for _, e := range []struct {
+		model string
+		cost  float64
+	}{{"gpt-4o", 0.25}, {"gpt-4o", 0.5}, {"gpt-4o-mini", 0.125}} {
+		require.NoError(t, repo.RecordExecution(context.Background(), &worker.Execution{
+			ID:          uuid.New(),
+			OwnerUserID: ownerID,
+			AgentID:     agentID,
+			TokensUsed:  100,
+			Model:       e.model,
+			CostUSD:     e.cost,
+			Status:      "completed",
+			CreatedAt:   time.Now(),
+		}))
+	}
+
+	resp = DoRequest(t, env, "GET", "/api/v1/governance/cost", nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	report := ParseResponse(t, resp)["data"].(map[string]any)
+	assert.InDelta(t, 0.875, report["total_cost_usd"], 1e-9)
+
+	breakdown := report["breakdown"].([]any)
+	require.Len(t, breakdown, 2)
+	top := breakdown[0].(map[string]any)
+	assert.Equal(t, "gpt-4o", top["model"])
+	assert.Equal(t, agentID.String(), top["agent_id"])
+	assert.Equal(t, float64(2), top["executions"])
+	assert.InDelta(t, 0.75, top["cost_usd"], 1e-9)
+
+	resp = DoRequest(t, env, "GET", "/api/v1/governance/quota", nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	quota := ParseResponse(t, resp)["data"].(map[string]any)
+	assert.InDelta(t, 0.875, quota["cost_today_usd"], 1e-9)
+
+	resp = DoRequest(t, env, "GET", "/api/v1/governance/cost?from=not-a-time", nil, token)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestGovernance_AuditInsertBatch_UnknownOwner(t *testing.T) {
+	env := SetupTestEnv(t)
+	ctx := context.Background()
+
+	email := fmt.Sprintf("govauditbatch-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Audit Batch Agent",
+		"system_prompt": "Test agent.",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	ownerID := uuid.MustParse(ParseResponse(t, resp)["data"].(map[string]any)["owner_user_id"].(string))
+
+	// The middle log's owner doesn't exist, as after an account is deleted.
+	logs := []*audit.AuditLog{
+		{OwnerUserID: ownerID, EventType: "batch_first", Severity: "info", ResourceType: "agent"},
+		{OwnerUserID: uuid.New(), EventType: "batch_orphan", Severity: "info", ResourceType: "agent"},
+		{OwnerUserID: ownerID, EventType: "batch_last", Severity: "info", ResourceType: "agent"},
+	}
+	failed, err := audit.NewRepository(env.Pool).InsertBatch(ctx, logs)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.ErrorIs(t, failed[1], audit.ErrUnknownOwner)
+
+	var count int
+	require.NoError(t, env.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM audit_logs WHERE owner_user_id = $1 AND event_type IN ('batch_first', 'batch_last')`, ownerID).Scan(&count))
+	assert.Equal(t, 2, count)
+}
@@ -3,12 +3,18 @@
 package integration
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/memory"
 )
 
 func TestMemory_CRUD(t *testing.T) {
@@ -16,8 +22,8 @@ func TestMemory_CRUD(t *testing.T) {
 
 	// Register and login
 	email := fmt.Sprintf("memtest-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	// Create an agent with memory enabled
 	agentBody := map[string]any{
@@ -75,8 +81,8 @@ func TestMemory_DeleteAll(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	email := fmt.Sprintf("memdelall-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	agentBody := map[string]any{
 		"name":          "Delete All Agent",
@@ -117,13 +123,13 @@ func TestMemory_OwnershipIsolation(t *testing.T) {
 
 	// User 1
 	email1 := fmt.Sprintf("memowner1-%d@test.com", uniqueID())
-	RegisterUser(t, env, email1, "password123")
-	token1 := LoginUser(t, env, email1, "password123")
+	RegisterUser(t, env, email1, "tangerine-kettle-42")
+	token1 := LoginUser(t, env, email1, "tangerine-kettle-42")
 
 	// User 2
 	email2 := fmt.Sprintf("memowner2-%d@test.com", uniqueID())
-	RegisterUser(t, env, email2, "password123")
-	token2 := LoginUser(t, env, email2, "password123")
+	RegisterUser(t, env, email2, "tangerine-kettle-42")
+	token2 := LoginUser(t, env, email2, "tangerine-kettle-42")
 
 	// User 1 creates agent
 	agentBody := map[string]any{
@@ -149,12 +155,71 @@ func TestMemory_OwnershipIsolation(t *testing.T) {
 	ParseResponse(t, resp) // drain body
 }
 
+func TestMemory_RestoreAndPurge(t *testing.T) {
+	env := SetupTestEnv(t)
+	ctx := context.Background()
+
+	email := fmt.Sprintf("memrestore-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+	otherEmail := fmt.Sprintf("memrestore-other-%d@test.com", uniqueID())
+	RegisterUser(t, env, otherEmail, "tangerine-kettle-42")
+	otherToken := LoginUser(t, env, otherEmail, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Restore Agent",
+		"system_prompt": "Restore test",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentID := ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+	base := fmt.Sprintf("/api/v1/agents/%s/memories", agentID)
+
+	create := func(content string) string {
+		resp := DoRequest(t, env, "POST", base, map[string]any{"content": content, "memory_type": "fact"}, token)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		id := ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+		resp = DoRequest(t, env, "DELETE", base+"/"+id, nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+		return id
+	}
+	recent := create("deleted yesterday")
+	expired := create("deleted last quarter")
+	_, err := env.Pool.Exec(ctx, `UPDATE agent_memories SET deleted_at = NOW() - INTERVAL '1 day' WHERE id = $1`, recent)
+	require.NoError(t, err)
+	_, err = env.Pool.Exec(ctx, `UPDATE agent_memories SET deleted_at = NOW() - INTERVAL '90 days' WHERE id = $1`, expired)
+	require.NoError(t, err)
+
+	// Another user can't restore this owner's memories.
+	resp = DoRequest(t, env, "POST", base+"/"+recent+"/restore", nil, otherToken)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp.Body.Close()
+
+	purged, err := memory.NewPostgresRepository(env.Pool).PurgeDeleted(ctx, time.Now().Add(-memory.DeletedMemoryRetention))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, purged, int64(1))
+
+	resp = DoRequest(t, env, "POST", base+"/"+expired+"/restore", nil, token)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = DoRequest(t, env, "POST", base+"/"+recent+"/restore", nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+
+	resp = DoRequest(t, env, "GET", base, nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	data := ParseResponse(t, resp)["data"].([]any)
+	require.Len(t, data, 1)
+	assert.Equal(t, recent, data[0].(map[string]any)["id"])
+}
+
 func TestMemory_SearchWithEmbedding(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	email := fmt.Sprintf("memsearch-%d@test.com", uniqueID())
-	RegisterUser(t, env, email, "password123")
-	token := LoginUser(t, env, email, "password123")
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
 
 	agentBody := map[string]any{
 		"name":          "Search Agent",
@@ -203,6 +268,340 @@ func TestMemory_SearchWithEmbedding(t *testing.T) {
 	}
 }
 
+func TestMemory_SearchHybrid(t *testing.T) {
+	env := SetupTestEnv(t)
+	ctx := context.Background()
+
+	email := fmt.Sprintf("memhybrid-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Hybrid Agent",
+		"system_prompt": "Hybrid test",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentData := ParseResponse(t, resp)["data"].(map[string]any)
+	agentID := uuid.MustParse(agentData["id"].(string))
+	ownerID := uuid.MustParse(agentData["owner_user_id"].(string))
+
+	space := memory.DefaultConfig().EmbeddingSpace()
+	query := make([]float32, space.Dim)
+	query[0] = 1
+	far := make([]float32, space.Dim)
+	far[space.Dim-1] = 1
+
+	repo := memory.NewPostgresRepository(env.Pool)
+	create := func(content string, embedding []float32) uuid.UUID {
+		mem := &memory.Memory{OwnerUserID: ownerID, AgentID: agentID, Content: content, MemoryType: "fact", Embedding: embedding}
+		if embedding != nil {
+			mem.EmbeddingModel, mem.EmbeddingDim = space.Model, space.Dim
+		}
+		require.NoError(t, repo.Create(ctx, mem))
+		return mem.ID
+	}
+	vectorOnly := create("the user owns a bicycle", query)
+	textOnly := create("the user drinks green tea", nil)
+	both := create("green tea every morning", far)
+
+	scores := func(alpha float64) map[uuid.UUID]float64 {
+		results, err := repo.SearchHybrid(ctx, agentID, ownerID, space, query, "green tea", alpha, 10, 0, nil)
+		require.NoError(t, err)
+		out := make(map[uuid.UUID]float64)
+		for _, r := range results {
+			out[r.Memory.ID] = r.Similarity
+		}
+		return out
+	}
+
+	t.Run("vector only", func(t *testing.T) {
+		got := scores(1)
+		require.Len(t, got, 3)
+		assert.InDelta(t, 1.0, got[vectorOnly], 1e-6)
+		assert.InDelta(t, 0.0, got[textOnly], 1e-6, "memories without an embedding score nothing on the vector side")
+		assert.InDelta(t, 0.0, got[both], 1e-6)
+	})
+
+	t.Run("text only", func(t *testing.T) {
+		got := scores(0)
+		assert.InDelta(t, 0.0, got[vectorOnly], 1e-6)
+		assert.Greater(t, got[textOnly], 0.0)
+		assert.Greater(t, got[both], 0.0)
+		assert.Less(t, got[both], 1.0, "the text rank is normalised into [0,1)")
+	})
+
+	t.Run("fused", func(t *testing.T) {
+		text := scores(0)
+		got := scores(0.5)
+		assert.InDelta(t, 0.5, got[vectorOnly], 1e-6)
+		assert.InDelta(t, 0.5*text[textOnly], got[textOnly], 1e-6)
+		assert.InDelta(t, 0.5*text[both], got[both], 1e-6)
+	})
+
+	t.Run("threshold and order", func(t *testing.T) {
+		results, err := repo.SearchHybrid(ctx, agentID, ownerID, space, query, "green tea", 0.5, 10, 0.4, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, vectorOnly, results[0].Memory.ID)
+		for i := 1; i < len(results); i++ {
+			assert.GreaterOrEqual(t, results[i-1].Similarity, results[i].Similarity)
+		}
+		for _, r := range results {
+			assert.GreaterOrEqual(t, r.Similarity, 0.4)
+		}
+	})
+}
+
+func TestMemory_MetadataFilter(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	email := fmt.Sprintf("memmeta-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Metadata Agent",
+		"system_prompt": "Metadata test",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentID := ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+	base := fmt.Sprintf("/api/v1/agents/%s/memories", agentID)
+
+	emb := make([]float64, 384)
+	emb[0] = 1.0
+	for _, mb := range []map[string]any{
+		{"content": "imported", "memory_type": "fact", "embedding": emb, "metadata": map[string]any{"source": "import", "batch": 1}},
+		{"content": "manual", "memory_type": "fact", "embedding": emb, "metadata": map[string]any{"source": "manual"}},
+	} {
+		resp = DoRequest(t, env, "POST", base, mb, token)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+
+	t.Run("list", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base+"?metadata="+url.QueryEscape(`{"source":"import"}`), nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := ParseResponse(t, resp)
+		items := result["data"].([]any)
+		require.Len(t, items, 1)
+		assert.Equal(t, "imported", items[0].(map[string]any)["content"])
+		assert.Equal(t, float64(1), result["total_count"])
+	})
+
+	t.Run("search", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", base+"/search", map[string]any{
+			"embedding":       emb,
+			"metadata_filter": map[string]any{"source": "manual"},
+		}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		results := ParseResponse(t, resp)["data"].([]any)
+		require.Len(t, results, 1)
+		assert.Equal(t, "manual", results[0].(map[string]any)["memory"].(map[string]any)["content"])
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", base+"?metadata=not-json", nil, token)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp.Body.Close()
+	})
+}
+
+func TestMemory_EmbeddingSpace(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	email := fmt.Sprintf("memspace-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Space Agent",
+		"system_prompt": "Embedding space test",
+		"memory_config": map[string]any{"embedding_model": "tiny-model", "embedding_dim": 3},
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentID := ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+	base := fmt.Sprintf("/api/v1/agents/%s/memories", agentID)
+
+	resp = DoRequest(t, env, "POST", base, map[string]any{"content": "tiny", "memory_type": "fact", "embedding": []float64{1, 0, 0}}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := ParseResponse(t, resp)["data"].(map[string]any)
+	assert.Equal(t, "tiny-model", created["embedding_model"])
+	assert.Equal(t, float64(3), created["embedding_dim"])
+
+	t.Run("wrong dimension rejected", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", base, map[string]any{"content": "big", "memory_type": "fact", "embedding": make([]float64, 384)}, token)
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, ParseResponse(t, resp)["error"], "embedding_dim is 3")
+
+		resp = DoRequest(t, env, "POST", base+"/search", map[string]any{"embedding": make([]float64, 384)}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("search stays within model", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", base+"/search", map[string]any{"embedding": []float64{1, 0, 0}}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Len(t, ParseResponse(t, resp)["data"], 1)
+
+		resp = DoRequest(t, env, "PUT", "/api/v1/agents/"+agentID, map[string]any{
+			"memory_config": map[string]any{"embedding_model": "other-model", "embedding_dim": 3},
+			"version":       1,
+		}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+
+		resp = DoRequest(t, env, "POST", base+"/search", map[string]any{"embedding": []float64{1, 0, 0}}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, ParseResponse(t, resp)["data"])
+	})
+
+	t.Run("other dimensions are never cast", func(t *testing.T) {
+		resp := DoRequest(t, env, "PUT", "/api/v1/agents/"+agentID, map[string]any{
+			"memory_config": map[string]any{"embedding_model": "sentence-transformers/all-MiniLM-L6-v2", "embedding_dim": 384},
+			"version":       2,
+		}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+
+		query := make([]float64, 384)
+		query[0] = 1
+		resp = DoRequest(t, env, "POST", base, map[string]any{"content": "big", "memory_type": "fact", "embedding": query}, token)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+
+		// The 3-dim memory shares the table with the 384-dim one; a tiny
+		// threshold makes every row in the 384-dim space pass.
+		resp = DoRequest(t, env, "POST", base+"/search", map[string]any{"embedding": query, "threshold": 0.0001}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		results := ParseResponse(t, resp)["data"].([]any)
+		require.Len(t, results, 1)
+		assert.Equal(t, "big", results[0].(map[string]any)["memory"].(map[string]any)["content"])
+
+		// Hybrid search still finds the 3-dim memory by its text.
+		resp = DoRequest(t, env, "POST", base+"/search", map[string]any{"embedding": query, "mode": "hybrid", "query": "tiny"}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var contents []string
+		for _, r := range ParseResponse(t, resp)["data"].([]any) {
+			contents = append(contents, r.(map[string]any)["memory"].(map[string]any)["content"].(string))
+		}
+		assert.ElementsMatch(t, []string{"big", "tiny"}, contents)
+	})
+}
+
+func TestMemory_Prune(t *testing.T) {
+	env := SetupTestEnv(t)
+	ctx := context.Background()
+
+	email := fmt.Sprintf("memprune-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Prune Agent",
+		"system_prompt": "Prune test",
+		"memory_config": map[string]any{"max_long_term_memories": 2, "long_term_ttl_days": 1},
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentID := ParseResponse(t, resp)["data"].(map[string]any)["id"].(string)
+	base := fmt.Sprintf("/api/v1/agents/%s/memories", agentID)
+
+	for _, content := range []string{"stale", "first", "second", "third"} {
+		resp = DoRequest(t, env, "POST", base, map[string]any{"content": content, "memory_type": "fact"}, token)
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		resp.Body.Close()
+	}
+	_, err := env.Pool.Exec(ctx, `UPDATE agent_memories SET created_at = NOW() - INTERVAL '2 days' WHERE agent_id = $1 AND content = 'stale'`, agentID)
+	require.NoError(t, err)
+	_, err = env.Pool.Exec(ctx, `UPDATE agent_memories SET created_at = NOW() - INTERVAL '1 hour' WHERE agent_id = $1 AND content = 'first'`, agentID)
+	require.NoError(t, err)
+
+	repo := memory.NewPostgresRepository(env.Pool)
+	targets, err := repo.ListPruneTargets(ctx, uuid.Nil, 1000)
+	require.NoError(t, err)
+	var target *memory.PruneTarget
+	for i := range targets {
+		if targets[i].AgentID.String() == agentID {
+			target = &targets[i]
+		}
+	}
+	require.NotNil(t, target)
+
+	expired, overCap, err := memory.NewService(repo, nil).PruneAgent(ctx, *target)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), expired)
+	assert.Equal(t, int64(1), overCap)
+
+	resp = DoRequest(t, env, "GET", base, nil, token)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var remaining []string
+	for _, item := range ParseResponse(t, resp)["data"].([]any) {
+		remaining = append(remaining, item.(map[string]any)["content"].(string))
+	}
+	assert.ElementsMatch(t, []string{"second", "third"}, remaining)
+}
+
+func TestMemory_CreateBatchPartial(t *testing.T) {
+	env := SetupTestEnv(t)
+	ctx := context.Background()
+
+	email := fmt.Sprintf("membatch-%d@test.com", uniqueID())
+	RegisterUser(t, env, email, "tangerine-kettle-42")
+	token := LoginUser(t, env, email, "tangerine-kettle-42")
+
+	resp := DoRequest(t, env, "POST", "/api/v1/agents", map[string]any{
+		"name":          "Batch Agent",
+		"system_prompt": "Batch test",
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	agentData := ParseResponse(t, resp)["data"].(map[string]any)
+	agentID := uuid.MustParse(agentData["id"].(string))
+	ownerID := uuid.MustParse(agentData["owner_user_id"].(string))
+
+	repo := memory.NewPostgresRepository(env.Pool)
+	existing := &memory.Memory{OwnerUserID: ownerID, AgentID: agentID, Content: "existing", MemoryType: "fact"}
+	require.NoError(t, repo.Create(ctx, existing))
+
+	// Rows 1 and 3 reuse the existing memory's ID and violate the primary key.
+	var mems []*memory.Memory
+	for _, content := range []string{"a", "dup", "c", "dup", "e"} {
+		mem := &memory.Memory{OwnerUserID: ownerID, AgentID: agentID, Content: content, MemoryType: "fact"}
+		if content == "dup" {
+			mem.ID = existing.ID
+		}
+		mems = append(mems, mem)
+	}
+
+	failed, err := repo.CreateBatch(ctx, mems, true)
+	require.NoError(t, err)
+	assert.Len(t, failed, 2)
+	assert.Contains(t, failed, 1)
+	assert.Contains(t, failed, 3)
+
+	var contents []string
+	rows, err := env.Pool.Query(ctx, `SELECT content FROM agent_memories WHERE agent_id = $1`, agentID)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var c string
+		require.NoError(t, rows.Scan(&c))
+		contents = append(contents, c)
+	}
+	require.NoError(t, rows.Err())
+	assert.ElementsMatch(t, []string{"existing", "a", "c", "e"}, contents)
+
+	// Without partial the first failure rolls back every row.
+	fresh := []*memory.Memory{
+		{OwnerUserID: ownerID, AgentID: agentID, Content: "f", MemoryType: "fact"},
+		{ID: existing.ID, OwnerUserID: ownerID, AgentID: agentID, Content: "dup", MemoryType: "fact"},
+	}
+	failed, err = repo.CreateBatch(ctx, fresh, false)
+	require.NoError(t, err)
+	assert.Contains(t, failed, 1)
+	var count int
+	require.NoError(t, env.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM agent_memories WHERE agent_id = $1`, agentID).Scan(&count))
+	assert.Equal(t, 4, count)
+}
+
 var _uniqueCounter int64
 
 func uniqueID() int64 {
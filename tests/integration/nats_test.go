@@ -38,11 +38,12 @@ func setupNATSContainer(t *testing.T) *inats.Client {
 	host, _ := natsContainer.Host(ctx)
 	port, _ := natsContainer.MappedPort(ctx, "4222")
 
-	client, err := inats.NewClient(ctx, config.NATSConfig{
+	client, err := inats.NewClient(config.NATSConfig{
 		URL: fmt.Sprintf("nats://%s:%s", host, port.Port()),
 	})
 	require.NoError(t, err)
 	t.Cleanup(func() { client.Close() })
+	require.NoError(t, inats.EnsureStreams(ctx, client.JetStream(), config.NATSStreamsConfig{}))
 
 	return client
 }
@@ -43,18 +43,19 @@ func TestOrchestratorFlow(t *testing.T) {
 	host, _ := natsContainer.Host(ctx)
 	port, _ := natsContainer.MappedPort(ctx, "4222")
 
-	natsClient, err := inats.NewClient(ctx, config.NATSConfig{
+	natsClient, err := inats.NewClient(config.NATSConfig{
 		URL: fmt.Sprintf("nats://%s:%s", host, port.Port()),
 	})
 	require.NoError(t, err)
 	t.Cleanup(func() { natsClient.Close() })
+	require.NoError(t, inats.EnsureStreams(ctx, natsClient.JetStream(), config.NATSStreamsConfig{}))
 
 	publisher := inats.NewPublisher(natsClient.JetStream())
 	consumerMgr := inats.NewConsumerManager(natsClient.JetStream())
 
 	// Create a user and agent via HTTP API
-	RegisterUser(t, env, "orch-test@aiox.local", "password123")
-	token := LoginUser(t, env, "orch-test@aiox.local", "password123")
+	RegisterUser(t, env, "orch-test@aiox.local", "tangerine-kettle-42")
+	token := LoginUser(t, env, "orch-test@aiox.local", "tangerine-kettle-42")
 
 	agentBody := map[string]any{
 		"name":          "Orchestrator Test Agent",
@@ -14,11 +14,11 @@ func TestOwnershipIsolation(t *testing.T) {
 	env := SetupTestEnv(t)
 
 	// Create two users
-	RegisterUser(t, env, "owner-a@example.com", "password123")
-	RegisterUser(t, env, "owner-b@example.com", "password123")
+	RegisterUser(t, env, "owner-a@example.com", "tangerine-kettle-42")
+	RegisterUser(t, env, "owner-b@example.com", "tangerine-kettle-42")
 
-	tokenA := LoginUser(t, env, "owner-a@example.com", "password123")
-	tokenB := LoginUser(t, env, "owner-b@example.com", "password123")
+	tokenA := LoginUser(t, env, "owner-a@example.com", "tangerine-kettle-42")
+	tokenB := LoginUser(t, env, "owner-b@example.com", "tangerine-kettle-42")
 
 	// User A creates an agent
 	body := map[string]any{
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -32,6 +33,8 @@ import (
 	"github.com/aiox-platform/aiox/internal/governance/quota"
 	"github.com/aiox-platform/aiox/internal/memory"
 	"github.com/aiox-platform/aiox/internal/users"
+	"github.com/aiox-platform/aiox/internal/webhooks"
+	"github.com/aiox-platform/aiox/internal/worker"
 )
 
 type TestEnv struct {
@@ -40,6 +43,27 @@ type TestEnv struct {
 	Server      *httptest.Server
 	AuthSvc     *auth.Service
 	UserSvc     *users.Service
+	Mailer      *CaptureMailer
+}
+
+// CaptureMailer records the last password reset token sent to each address.
+type CaptureMailer struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (m *CaptureMailer) SendPasswordReset(_ context.Context, email, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens[email] = token
+	return nil
+}
+
+// ResetToken returns the last token sent to email.
+func (m *CaptureMailer) ResetToken(email string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tokens[email]
 }
 
 var testEnv *TestEnv
@@ -135,9 +159,18 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	userRepo := users.NewRepository(pool)
 	userSvc := users.NewService(userRepo)
 	authHandler := auth.NewHandler(authSvc, userSvc)
+	apiKeySvc := auth.NewAPIKeyService(auth.NewAPIKeyRepository(pool))
+	apiKeyHandler := auth.NewAPIKeyHandler(apiKeySvc)
+	encryptor, err := auth.NewEncryptor(encryptionKey)
+	if err != nil {
+		t.Fatalf("creating encryptor: %v", err)
+	}
+	twoFactorHandler := auth.NewTwoFactorHandler(authSvc, userSvc, encryptor, "AIOX")
+	mailer := &CaptureMailer{tokens: make(map[string]string)}
+	passwordResetHandler := auth.NewPasswordResetHandler(authSvc, userSvc, mailer, nil)
 
 	agentRepo := agents.NewRepository(pool)
-	agentSvc := agents.NewService(agentRepo, encryptionKey, xmppDomain)
+	agentSvc := agents.NewService(agentRepo, agents.NewTemplateRepository(pool), encryptor, xmppDomain)
 	agentHandler := agents.NewHandler(agentSvc)
 
 	// Memory (Phase 4)
@@ -157,31 +190,70 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	quotaSvc := quota.NewService(quotaRepo, rateLimiter, govCfg)
 	auditRepo := audit.NewRepository(pool)
 	govHandler := governance.NewHandler(quotaSvc, auditRepo)
+	executionHandler := worker.NewExecutionHandler(worker.NewRepository(pool), agentSvc)
+	webhookSvc := webhooks.NewService(webhooks.NewRepository(pool), encryptor, 5*time.Second)
+	// Test receivers listen on loopback.
+	webhookSvc.AllowPrivateNetworks()
+	webhookHandler := webhooks.NewHandler(webhookSvc)
 
-	router := api.NewRouter(pool, nil, api.RouterConfig{}, api.HandlerSet{
+	router := api.NewRouter(pool, nil, redisClient, api.RouterConfig{}, api.HandlerSet{
 		Register: authHandler.Register,
 		Login:    authHandler.Login,
 		Refresh:  authHandler.Refresh,
 		Logout:   authHandler.Logout,
 
+		ListSessions:  authHandler.ListSessions,
+		RevokeSession: authHandler.RevokeSession,
+
+		EnrollTwoFactor:  twoFactorHandler.Enroll,
+		VerifyTwoFactor:  twoFactorHandler.Verify,
+		DisableTwoFactor: twoFactorHandler.Disable,
+		TwoFactorLogin:   twoFactorHandler.Login,
+
+		RequestPasswordReset: passwordResetHandler.Request,
+		ConfirmPasswordReset: passwordResetHandler.Confirm,
+
+		CreateAPIKey: apiKeyHandler.Create,
+		ListAPIKeys:  apiKeyHandler.List,
+		RevokeAPIKey: apiKeyHandler.Revoke,
+
 		CreateAgent:         agentHandler.Create,
 		ListAgents:          agentHandler.List,
+		ListPublicAgents:    agentHandler.ListPublic,
 		GetAgent:            agentHandler.Get,
 		UpdateAgent:         agentHandler.Update,
 		DeleteAgent:         agentHandler.Delete,
+		ListAgentVersions:   agentHandler.ListVersions,
+		RollbackAgent:       agentHandler.Rollback,
+		ListAgentExecutions: executionHandler.List,
+		GetAgentExecution:   executionHandler.Get,
+		GetExecutionContext: executionHandler.Context,
 		OwnershipMiddleware: agentHandler.OwnershipMiddleware,
 
-		ListMemories:      memoryHandler.List,
-		CreateMemory:      memoryHandler.Create,
-		SearchMemories:    memoryHandler.Search,
-		DeleteMemory:      memoryHandler.Delete,
-		DeleteAllMemories: memoryHandler.DeleteAll,
+		ListMemories:       memoryHandler.List,
+		CreateMemory:       memoryHandler.Create,
+		BulkCreateMemories: memoryHandler.BulkCreate,
+		SearchMemories:     memoryHandler.Search,
+		DeleteMemory:       memoryHandler.Delete,
+		DeleteAllMemories:  memoryHandler.DeleteAll,
+		RestoreMemory:      memoryHandler.Restore,
 
 		GetUserQuota:       govHandler.GetQuota,
 		ListAuditLogs:      govHandler.ListAuditLogs,
 		ListAgentAuditLogs: govHandler.ListAgentAuditLogs,
-
-		AuthMiddleware: auth.Middleware(authSvc),
+		GetAgentQuota:      govHandler.GetAgentQuota,
+		GetUserCost:        govHandler.GetCost,
+
+		CreateWebhook: webhookHandler.Create,
+		ListWebhooks:  webhookHandler.List,
+		GetWebhook:    webhookHandler.Get,
+		UpdateWebhook: webhookHandler.Update,
+		DeleteWebhook: webhookHandler.Delete,
+		TestWebhook:   webhookHandler.Test,
+
+		AuthMiddleware: auth.Middleware(authSvc, apiKeySvc),
+		RequireSession: auth.RequireSession,
+		RequireScope:   auth.RequireScope,
 	})
 
 	server := httptest.NewServer(router)
@@ -193,6 +265,7 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 		Server:      server,
 		AuthSvc:     authSvc,
 		UserSvc:     userSvc,
+		Mailer:      mailer,
 	}
 
 	return testEnv
@@ -238,6 +311,12 @@ func LoginUser(t *testing.T, env *TestEnv, email, password string) string {
 }
 
 func DoRequest(t *testing.T, env *TestEnv, method, path string, body any, token string) *http.Response {
+	t.Helper()
+	return DoRequestWithHeaders(t, env, method, path, body, token, nil)
+}
+
+// DoRequestWithHeaders is DoRequest with extra request headers.
+func DoRequestWithHeaders(t *testing.T, env *TestEnv, method, path string, body any, token string, headers map[string]string) *http.Response {
 	t.Helper()
 	var bodyReader io.Reader
 	if body != nil {
@@ -253,6 +332,9 @@ func DoRequest(t *testing.T, env *TestEnv, method, path string, body any, token
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
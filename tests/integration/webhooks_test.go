@@ -0,0 +1,109 @@
+//go:build integration
+
+package integration
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aiox-platform/aiox/internal/webhooks"
+)
+
+func TestWebhooks(t *testing.T) {
+	env := SetupTestEnv(t)
+
+	RegisterUser(t, env, "webhooks@example.com", "tangerine-kettle-42")
+	token := LoginUser(t, env, "webhooks@example.com", "tangerine-kettle-42")
+	RegisterUser(t, env, "webhooks-other@example.com", "tangerine-kettle-42")
+	otherToken := LoginUser(t, env, "webhooks-other@example.com", "tangerine-kettle-42")
+
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	resp := DoRequest(t, env, "POST", "/api/v1/webhooks", map[string]any{
+		"url":         receiver.URL,
+		"event_types": []string{"task_completed"},
+	}, token)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	created := ParseResponse(t, resp)["data"].(map[string]any)
+	hookID := created["id"].(string)
+	secret := created["secret"].(string)
+	assert.Contains(t, secret, webhooks.SecretPrefix)
+	assert.Equal(t, true, created["enabled"])
+
+	t.Run("secret is not returned again", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", "/api/v1/webhooks/"+hookID, nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, ParseResponse(t, resp)["data"], "secret")
+	})
+
+	t.Run("test ping is signed", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", "/api/v1/webhooks/"+hookID+"/test", nil, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		result := ParseResponse(t, resp)["data"].(map[string]any)
+		assert.Equal(t, true, result["delivered"])
+		assert.Equal(t, float64(http.StatusNoContent), result["status_code"])
+
+		req := <-received
+		body := <-bodies
+		assert.Equal(t, webhooks.PingEvent, req.Header.Get(webhooks.HeaderEvent))
+		ts, err := strconv.ParseInt(req.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, webhooks.Sign(secret, time.Unix(ts, 0), body), req.Header.Get(webhooks.HeaderSignature))
+	})
+
+	t.Run("update event types", func(t *testing.T) {
+		resp := DoRequest(t, env, "PUT", "/api/v1/webhooks/"+hookID, map[string]any{
+			"event_types": []string{"*"},
+		}, token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []any{"*"}, ParseResponse(t, resp)["data"].(map[string]any)["event_types"])
+	})
+
+	t.Run("invalid url rejected", func(t *testing.T) {
+		resp := DoRequest(t, env, "POST", "/api/v1/webhooks", map[string]any{
+			"url":         "ftp://example.com/hook",
+			"event_types": []string{"task_completed"},
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp.Body.Close()
+	})
+
+	t.Run("other user cannot see webhook", func(t *testing.T) {
+		resp := DoRequest(t, env, "GET", "/api/v1/webhooks/"+hookID, nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp.Body.Close()
+
+		resp = DoRequest(t, env, "POST", "/api/v1/webhooks/"+hookID+"/test", nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp.Body.Close()
+
+		resp = DoRequest(t, env, "GET", "/api/v1/webhooks", nil, otherToken)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Empty(t, ParseResponse(t, resp)["data"])
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp := DoRequest(t, env, "DELETE", "/api/v1/webhooks/"+hookID, nil, token)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+
+		resp = DoRequest(t, env, "GET", "/api/v1/webhooks/"+hookID, nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		resp.Body.Close()
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/aiox-platform/aiox/internal/agents"
+	"github.com/aiox-platform/aiox/internal/config"
 	inats "github.com/aiox-platform/aiox/internal/nats"
 	"github.com/aiox-platform/aiox/internal/worker"
 	pb "github.com/aiox-platform/aiox/internal/worker/workerpb"
@@ -45,15 +46,7 @@ func TestWorkerEndToEnd(t *testing.T) {
 	js, err := jetstream.New(nc)
 	require.NoError(t, err)
 
-	// Ensure streams exist
-	for _, cfg := range []jetstream.StreamConfig{
-		{Name: "AIOX_TASKS", Subjects: []string{"aiox.tasks.>"}, Retention: jetstream.WorkQueuePolicy, MaxAge: time.Hour},
-		{Name: "AIOX_MESSAGES", Subjects: []string{"aiox.messages.>"}, Retention: jetstream.WorkQueuePolicy, MaxAge: 24 * time.Hour},
-		{Name: "AIOX_EVENTS", Subjects: []string{"aiox.events.>"}, Retention: jetstream.LimitsPolicy, MaxAge: 7 * 24 * time.Hour},
-	} {
-		_, err = js.CreateOrUpdateStream(ctx, cfg)
-		require.NoError(t, err)
-	}
+	require.NoError(t, inats.EnsureStreams(ctx, js, config.NATSStreamsConfig{}))
 
 	publisher := inats.NewPublisher(js)
 	consumerMgr := inats.NewConsumerManager(js)
@@ -121,7 +114,7 @@ func TestWorkerEndToEnd(t *testing.T) {
 	assert.Equal(t, 1, pool.ConnectedCount())
 
 	// Send a task request directly through the pool worker
-	testWorker := pool.SelectWorker()
+	testWorker := pool.SelectWorkerForProvider("", "")
 	require.NotNil(t, testWorker)
 
 	requestID := uuid.New().String()
@@ -215,14 +208,7 @@ func TestPublishAndConsumeTask(t *testing.T) {
 	js, err := jetstream.New(nc)
 	require.NoError(t, err)
 
-	// Ensure stream
-	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
-		Name:      "AIOX_TASKS",
-		Subjects:  []string{"aiox.tasks.>"},
-		Retention: jetstream.WorkQueuePolicy,
-		MaxAge:    time.Hour,
-	})
-	require.NoError(t, err)
+	require.NoError(t, inats.EnsureStreams(ctx, js, config.NATSStreamsConfig{}))
 
 	publisher := inats.NewPublisher(js)
 	consumerMgr := inats.NewConsumerManager(js)
@@ -242,7 +228,7 @@ func TestPublishAndConsumeTask(t *testing.T) {
 	require.NoError(t, err)
 
 	// Consume the task
-	consumer, err := consumerMgr.EnsureConsumer(ctx, "AIOX_TASKS", "test-consumer-"+uuid.New().String(), "aiox.tasks.>")
+	consumer, err := consumerMgr.EnsureConsumer(ctx, inats.StreamTasks, "test-consumer-"+uuid.New().String(), inats.SubjectTasksAll)
 	require.NoError(t, err)
 
 	msgs, err := consumer.Fetch(1, jetstream.FetchMaxWait(5*time.Second))
@@ -100,8 +100,10 @@ func setupSecurityTestEnv(t *testing.T) *testEnv {
 	userSvc := users.NewService(userRepo)
 	authHandler := auth.NewHandler(authSvc, userSvc)
 
+	encryptor, err := auth.NewEncryptor(encKey)
+	require.NoError(t, err)
 	agentRepo := agents.NewRepository(pool)
-	agentSvc := agents.NewService(agentRepo, encKey, "security.test")
+	agentSvc := agents.NewService(agentRepo, agents.NewTemplateRepository(pool), encryptor, "security.test")
 	agentHandler := agents.NewHandler(agentSvc)
 
 	router := api.NewRouter(pool, nil, api.HandlerSet{
@@ -115,7 +117,8 @@ func setupSecurityTestEnv(t *testing.T) *testEnv {
 		UpdateAgent:         agentHandler.Update,
 		DeleteAgent:         agentHandler.Delete,
 		OwnershipMiddleware: agentHandler.OwnershipMiddleware,
-		AuthMiddleware:      auth.Middleware(authSvc),
+		AuthMiddleware:      auth.Middleware(authSvc, nil),
+		RequireSession:      auth.RequireSession,
 	})
 
 	server := httptest.NewServer(router)
@@ -162,7 +165,7 @@ func parseResp(t *testing.T, resp *http.Response) map[string]any {
 
 func register(t *testing.T, env *testEnv, email string) string {
 	t.Helper()
-	resp := doReq(t, env, "POST", "/api/v1/auth/register", map[string]string{"email": email, "password": "password123"}, "")
+	resp := doReq(t, env, "POST", "/api/v1/auth/register", map[string]string{"email": email, "password": "tangerine-kettle-42"}, "")
 	require.Equal(t, http.StatusCreated, resp.StatusCode)
 	r := parseResp(t, resp)
 	return r["data"].(map[string]any)["access_token"].(string)
@@ -19,6 +19,62 @@ from . import worker_pb2_grpc
 
 logger = logging.getLogger(__name__)
 
+SUMMARY_PROMPT = (
+    "Summarize the following conversation between a user and an assistant for "
+    "long-term memory. Keep facts, preferences, decisions, and open questions "
+    "about the user; drop greetings and filler. Write a few concise sentences."
+)
+
+
+def with_attachments(user_message: str, attachments_json: str) -> str:
+    """Append the task's attachment links to the user message for the LLM."""
+    try:
+        attachments = json.loads(attachments_json) if attachments_json else []
+    except json.JSONDecodeError:
+        attachments = []
+    if not attachments:
+        return user_message
+
+    lines = []
+    for a in attachments:
+        line = f"- {a.get('url', '')}"
+        details = [d for d in (a.get("description"), a.get("mime_type")) if d]
+        if details:
+            line += f" ({', '.join(details)})"
+        lines.append(line)
+    return f"{user_message}\n\nAttachments:\n" + "\n".join(lines)
+
+
+def enabled_tools(tools_json: str) -> list[dict]:
+    """Parse the tools the agent's LLM may call: a list of {"name", "config"}."""
+    try:
+        tools = json.loads(tools_json) if tools_json else []
+    except json.JSONDecodeError:
+        return []
+    return [t for t in tools if isinstance(t, dict) and t.get("name")]
+
+
+def with_response_schema(system_prompt: str, response_schema_json: str) -> str:
+    """Ask the LLM to reply only with JSON matching the agent's response schema."""
+    if not response_schema_json:
+        return system_prompt
+    return (
+        f"{system_prompt}\n\nRespond only with a JSON document, without any other text, "
+        f"that matches this JSON Schema:\n{response_schema_json}"
+    )
+
+
+def structured_output(text: str) -> str:
+    """Extract the JSON document from an LLM reply, allowing a Markdown code fence."""
+    body = text.strip()
+    if body.startswith("```"):
+        body = body.split("\n", 1)[1] if "\n" in body else ""
+        body = body.rsplit("```", 1)[0]
+    try:
+        return json.dumps(json.loads(body))
+    except json.JSONDecodeError:
+        return ""
+
 
 class WorkerClient:
     """gRPC client that connects to the AIOX server, receives tasks, and returns results."""
@@ -71,6 +127,8 @@ class WorkerClient:
                     worker_id=self.config.worker_id,
                     max_concurrent=self.config.max_concurrent,
                     supported_providers=self.config.supported_providers,
+                    supported_models=self.config.supported_models,
+                    max_context_tokens=self.config.max_context_tokens,
                 )
             )
             await stream.write(register_msg)
@@ -103,10 +161,14 @@ class WorkerClient:
                     if server_msg == grpc.aio.EOF:
                         logger.info("Server closed stream (EOF)")
                         break
-                    task_req = server_msg.task_request
-                    if task_req and task_req.request_id:
+                    kind = server_msg.WhichOneof("payload")
+                    if kind == "task_request" and server_msg.task_request.request_id:
+                        asyncio.create_task(
+                            self._process_task(stream, server_msg.task_request)
+                        )
+                    elif kind == "summarize_request" and server_msg.summarize_request.request_id:
                         asyncio.create_task(
-                            self._process_task(stream, task_req)
+                            self._process_summary(stream, server_msg.summarize_request)
                         )
             finally:
                 heartbeat_task.cancel()
@@ -130,21 +192,49 @@ class WorkerClient:
             # Parse memory context and config
             mem_config = MemoryConfig.from_json(task_req.memory_config_json)
             mem_context = MemoryContext.from_json(task_req.memory_context_json)
+            if mem_context.truncation:
+                logger.info(
+                    "Task %s memory context trimmed to %s tokens: dropped %s messages, %s memories",
+                    task_req.request_id,
+                    mem_context.truncation.get("max_tokens"),
+                    mem_context.truncation.get("dropped_messages", 0),
+                    mem_context.truncation.get("dropped_memories", 0),
+                )
+
+            user_message = with_attachments(task_req.user_message, task_req.attachments_json)
+            tools = enabled_tools(task_req.tools_json)
+            if tools:
+                logger.info(
+                    "Task %s enables tools: %s",
+                    task_req.request_id,
+                    ", ".join(t["name"] for t in tools),
+                )
+
+            system_prompt = with_response_schema(
+                task_req.system_prompt, task_req.response_schema_json
+            )
 
             # Build messages array with memory context if enabled
             messages = None
             if mem_config.enabled and (mem_context.recent_messages or mem_context.relevant_memories):
-                messages = mem_context.build_messages_for_llm(
-                    task_req.system_prompt, task_req.user_message
-                )
+                messages = mem_context.build_messages_for_llm(system_prompt, user_message)
+
+            response = await self._call_llm(
+                task_req, messages=messages, user_message=user_message, system_prompt=system_prompt
+            )
 
-            response = await self._call_llm(task_req, messages=messages)
+            # The server validates the document against the schema
+            structured_json = ""
+            if task_req.response_schema_json and not response.error:
+                structured_json = structured_output(response.text)
 
             # Generate embedding for user message if long-term memory is enabled
             new_memories = []
             if mem_config.enabled and mem_config.long_term_enabled and not response.error:
                 try:
-                    embedding = self.embedding_svc.embed(task_req.user_message)
+                    embedding = self.embedding_svc.embed(
+                        task_req.user_message, mem_config.embedding_model
+                    )
                     new_memories.append(
                         worker_pb2.MemoryEntry(
                             content=task_req.user_message,
@@ -170,6 +260,7 @@ class WorkerClient:
                     model_used=response.model_used,
                     error_message=response.error,
                     new_memories=new_memories,
+                    structured_json=structured_json,
                 )
             )
             await stream.write(result_msg)
@@ -182,8 +273,87 @@ class WorkerClient:
                 len(new_memories),
             )
 
+    async def _process_summary(self, stream, sum_req):
+        """Condense conversation turns into a long-term memory with its embedding."""
+        async with self.semaphore:
+            logger.info(
+                "Summarizing conversation %s for agent %s",
+                sum_req.request_id,
+                sum_req.agent_id,
+            )
+
+            try:
+                turns = json.loads(sum_req.conversation_json) if sum_req.conversation_json else []
+            except json.JSONDecodeError:
+                turns = []
+            transcript = "\n".join(
+                f"{t.get('role', 'user')}: {t.get('content', '')}" for t in turns
+            )
+
+            try:
+                llm_config = json.loads(sum_req.llm_config_json) if sum_req.llm_config_json else {}
+            except json.JSONDecodeError:
+                llm_config = {}
+
+            provider_name = llm_config.get("provider", "openai")
+            provider = self._get_provider(provider_name)
+            summary = None
+            if not transcript:
+                response = LLMResponse(
+                    text="", tokens_used=0, model_used="", duration_ms=0,
+                    error="no conversation to summarize",
+                )
+            elif provider is None:
+                response = LLMResponse(
+                    text="", tokens_used=0, model_used="", duration_ms=0,
+                    error=f"LLM provider '{provider_name}' not configured on this worker",
+                )
+            else:
+                response = await provider.generate(
+                    system_prompt=SUMMARY_PROMPT,
+                    user_message=transcript,
+                    model=llm_config.get("model", ""),
+                    temperature=0.2,
+                    max_tokens=512,
+                )
+
+            if not response.error:
+                try:
+                    summary = worker_pb2.MemoryEntry(
+                        content=response.text,
+                        embedding=self.embedding_svc.embed(
+                            response.text, sum_req.embedding_model
+                        ),
+                        memory_type="summary",
+                    )
+                except Exception as e:
+                    response.error = f"failed to generate embedding: {e}"
+
+            result_msg = worker_pb2.WorkerMessage(
+                summarize_response=worker_pb2.SummarizeResponse(
+                    request_id=sum_req.request_id,
+                    worker_id=self.config.worker_id,
+                    summary=summary,
+                    tokens_used=response.tokens_used,
+                    model_used=response.model_used,
+                    error_message=response.error,
+                )
+            )
+            await stream.write(result_msg)
+
+            logger.info(
+                "Summary %s completed: %d turns, %d tokens",
+                sum_req.request_id,
+                len(turns),
+                response.tokens_used,
+            )
+
     async def _call_llm(
-        self, task_req, messages: list[dict] | None = None
+        self,
+        task_req,
+        messages: list[dict] | None = None,
+        user_message: str | None = None,
+        system_prompt: str | None = None,
     ) -> LLMResponse:
         """Call the appropriate LLM provider based on agent's llm_config."""
         try:
@@ -207,8 +377,8 @@ class WorkerClient:
             )
 
         return await provider.generate(
-            system_prompt=task_req.system_prompt,
-            user_message=task_req.user_message,
+            system_prompt=system_prompt if system_prompt is not None else task_req.system_prompt,
+            user_message=user_message if user_message is not None else task_req.user_message,
             model=model,
             temperature=temperature,
             max_tokens=max_tokens,
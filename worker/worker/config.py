@@ -13,6 +13,12 @@ class Config:
         self.heartbeat_interval = int(os.getenv("HEARTBEAT_INTERVAL", "30"))
         self.reconnect_delay = int(os.getenv("RECONNECT_DELAY", "5"))
 
+        # Advertised capabilities; no models means any model of the providers
+        self.supported_models = [
+            m.strip() for m in os.getenv("SUPPORTED_MODELS", "").split(",") if m.strip()
+        ]
+        self.max_context_tokens = int(os.getenv("MAX_CONTEXT_TOKENS", "0"))
+
         # LLM API keys
         self.openai_api_key = os.getenv("OPENAI_API_KEY", "")
         self.anthropic_api_key = os.getenv("ANTHROPIC_API_KEY", "")
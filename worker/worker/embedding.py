@@ -8,19 +8,30 @@ MODEL_NAME = "sentence-transformers/all-MiniLM-L6-v2"
 
 
 class EmbeddingService:
-    """Generates 384-dim embeddings using sentence-transformers."""
+    """Generates embeddings using sentence-transformers models.
 
-    def __init__(self):
-        logger.info("Loading embedding model: %s", MODEL_NAME)
-        self.model = SentenceTransformer(MODEL_NAME)
-        logger.info("Embedding model loaded")
+    The default model (384-dim) is loaded up front; other models named by an
+    agent's memory_config.embedding_model are loaded on first use and cached.
+    """
 
-    def embed(self, text: str) -> list[float]:
-        """Generate a 384-dim embedding for a single text."""
-        embedding = self.model.encode(text, normalize_embeddings=True)
+    def __init__(self):
+        self.models: dict[str, SentenceTransformer] = {}
+        self._model(MODEL_NAME)
+
+    def _model(self, name: str | None) -> SentenceTransformer:
+        name = name or MODEL_NAME
+        if name not in self.models:
+            logger.info("Loading embedding model: %s", name)
+            self.models[name] = SentenceTransformer(name)
+            logger.info("Embedding model loaded: %s", name)
+        return self.models[name]
+
+    def embed(self, text: str, model_name: str | None = None) -> list[float]:
+        """Generate an embedding for a single text."""
+        embedding = self._model(model_name).encode(text, normalize_embeddings=True)
         return embedding.tolist()
 
-    def embed_batch(self, texts: list[str]) -> list[list[float]]:
+    def embed_batch(self, texts: list[str], model_name: str | None = None) -> list[list[float]]:
         """Generate embeddings for multiple texts."""
-        embeddings = self.model.encode(texts, normalize_embeddings=True)
+        embeddings = self._model(model_name).encode(texts, normalize_embeddings=True)
         return [e.tolist() for e in embeddings]
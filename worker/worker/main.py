@@ -18,6 +18,7 @@ def main():
     logger.info("Starting AIOX worker: %s", config.worker_id)
     logger.info("gRPC target: %s", config.grpc_target)
     logger.info("Supported providers: %s", config.supported_providers)
+    logger.info("Supported models: %s", config.supported_models or "any")
     logger.info("Max concurrent tasks: %d", config.max_concurrent)
 
     client = WorkerClient(config)
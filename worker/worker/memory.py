@@ -23,6 +23,8 @@ class RelevantMemory:
 class MemoryContext:
     recent_messages: list[ConversationEntry] = field(default_factory=list)
     relevant_memories: list[RelevantMemory] = field(default_factory=list)
+    # Set when the dispatcher dropped entries to fit max_context_tokens.
+    truncation: dict | None = None
 
     @classmethod
     def from_json(cls, data: str) -> "MemoryContext":
@@ -51,7 +53,11 @@ class MemoryContext:
                 similarity=mem.get("similarity", 0.0),
             ))
 
-        return cls(recent_messages=recent, relevant_memories=memories)
+        return cls(
+            recent_messages=recent,
+            relevant_memories=memories,
+            truncation=raw.get("truncation"),
+        )
 
     def build_messages_for_llm(
         self, system_prompt: str, user_message: str
@@ -92,6 +98,7 @@ class MemoryConfig:
     short_term_ttl_sec: int = 3600
     max_long_term_results: int = 5
     similarity_threshold: float = 0.7
+    embedding_model: str = ""
 
     @classmethod
     def from_json(cls, data: str) -> "MemoryConfig":
@@ -110,4 +117,5 @@ class MemoryConfig:
             short_term_ttl_sec=raw.get("short_term_ttl_sec", 3600),
             max_long_term_results=raw.get("max_long_term_results", 5),
             similarity_threshold=raw.get("similarity_threshold", 0.7),
+            embedding_model=raw.get("embedding_model", ""),
         )